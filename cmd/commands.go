@@ -0,0 +1,435 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/handlers"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/oidc"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"gorm.io/gorm"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const usage = `Usage: go-booking-system [flags] <command> [arguments]
+
+Commands:
+  serve                                   start HTTP server (default)
+  migrate up | down [steps] | status      apply, roll back or list migrations
+  seed                                    fill DB with demo data
+
+  user create --name --email --telephone --username --password [--role] [--organization] [--time-zone]
+  user reset-password --username [--password]
+                                          new password is generated and printed if it is not passed
+  user set-role --username --role
+  user reset-mfa --username               disable two-factor authentication of the user who has lost authenticator and recovery codes
+
+  role grant --role --route [--scope] [--location]
+  role revoke --role --route
+
+  token issue --username --ip [--user-agent] [--device]
+                                          issue tokens of service account, bound to the caller (auth.tokenBinding)
+  bookings purge --before                 permanently remove bookings ended before the date (YYYY-MM-DD or RFC 3339)
+  sso mock-provider [--address] [--client-id] [--username] [--email] [--groups]
+                                          local OIDC provider for development: logs in the user without login page
+  ldap sync                               synchronize users with LDAP / Active Directory now
+`
+
+// generatedPasswordSize bytes of random password - printed as hex
+const generatedPasswordSize = 12
+
+// RunCommand runs admin command through services - the same checks as in HTTP API are applied
+func RunCommand(ctx context.Context, out io.Writer, conn *gorm.DB, service *services.Service, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return migrate(ctx, out, conn, args[1:])
+	case "seed":
+		return seed(ctx, out, conn)
+	case "user":
+		return userCommand(ctx, out, service, args[1:])
+	case "role":
+		return roleCommand(ctx, out, service, args[1:])
+	case "token":
+		return tokenCommand(ctx, out, service, args[1:])
+	case "bookings":
+		return bookingsCommand(ctx, out, service, args[1:])
+	case "sso":
+		return ssoCommand(ctx, out, args[1:])
+	case "ldap":
+		return ldapCommand(ctx, out, service, args[1:])
+	}
+
+	return fmt.Errorf("unknown command %q. Run `help` to see available commands", args[0])
+}
+
+func migrate(ctx context.Context, out io.Writer, conn *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New("migrate command requires action: up, down [steps] or status")
+	}
+
+	migrator, err := database.NewMigrator(conn)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		migrations, err := migrator.Up(ctx)
+		fmt.Fprintf(out, "migrations applied: %d\n", len(migrations))
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("number of steps should be an integer. Passed data: %s", args[1])
+			}
+		}
+		migrations, err := migrator.Down(ctx, steps)
+		fmt.Fprintf(out, "migrations rolled back: %d\n", len(migrations))
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(out, "%04d_%-20s %s\n", status.Version, status.Name, appliedAt)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown migrate action %q. Available actions: up, down [steps], status", args[0])
+}
+
+func seed(ctx context.Context, out io.Writer, conn *gorm.DB) error {
+	migrator, err := database.NewMigrator(conn)
+	if err != nil {
+		return err
+	}
+
+	seeds, err := migrator.Seed(ctx)
+	fmt.Fprintf(out, "seeds applied: %d\n", len(seeds))
+	return err
+}
+
+func userCommand(ctx context.Context, out io.Writer, service *services.Service, args []string) error {
+	if len(args) == 0 {
+		return errors.New("user command requires action: create, reset-password, set-role or reset-mfa")
+	}
+
+	flags := flag.NewFlagSet("user "+args[0], flag.ContinueOnError)
+	flags.SetOutput(out)
+	username := flags.String("username", "", "username of the user")
+
+	switch args[0] {
+	case "create":
+		name := flags.String("name", "", "name of the user")
+		email := flags.String("email", "", "email of the user")
+		telephone := flags.String("telephone", "", "telephone of the user")
+		password := flags.String("password", "", "password of the user")
+		roleId := flags.Int("role", services.DefaultRoleId, "role_id of the user")
+		organizationId := flags.Int("organization", services.HostOrganizationId, "organization_id of the user")
+		timeZone := flags.String("time-zone", "", "IANA time zone of the user")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		user := models.User{Name: *name, Email: *email, Telephone: *telephone, UserName: *username, Password: *password,
+			RoleId: *roleId, OrganizationId: *organizationId, TimeZone: *timeZone, EmailVerified: true, Active: true}
+		if validator := handlers.NewUserValidator(&user); !validator.AllUserFieldsValid {
+			return fmt.Errorf("user is not valid: %v", validator.ValidationErrors)
+		}
+		if user.UserName == "" || user.Password == "" {
+			return errors.New("username and password are required")
+		}
+
+		// role should be a system one or belong to the organization of the user
+		createdUser, err := service.ForOrganization(user.OrganizationId).AuthService.Create(ctx, services.SystemUserId, user)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "user created: user_id=%d username=%s\n", createdUser.UserId, createdUser.UserName)
+		return nil
+
+	case "reset-password":
+		password := flags.String("password", "", "new password. Generated if empty")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		user, err := findUser(ctx, service, *username)
+		if err != nil {
+			return err
+		}
+
+		newPassword := *password
+		if newPassword == "" {
+			if newPassword, err = pkg.GenerateRandomToken(generatedPasswordSize); err != nil {
+				return err
+			}
+		}
+		if _, err := service.AuthService.UpdatePassword(ctx, user.UserId, newPassword); err != nil {
+			return err
+		}
+
+		if *password == "" {
+			fmt.Fprintf(out, "password of %s is reset. New password: %s\n", user.UserName, newPassword)
+		} else {
+			fmt.Fprintf(out, "password of %s is reset\n", user.UserName)
+		}
+		return nil
+
+	case "set-role":
+		roleId := flags.Int("role", 0, "new role_id of the user")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		user, err := findUser(ctx, service, *username)
+		if err != nil {
+			return err
+		}
+
+		if _, err := service.ForOrganization(user.OrganizationId).AuthService.UpdateRole(ctx, user.UserId, *roleId); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "role of %s is changed: role_id=%d\n", user.UserName, *roleId)
+		return nil
+
+	case "reset-mfa":
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		user, err := findUser(ctx, service, *username)
+		if err != nil {
+			return err
+		}
+
+		if err := service.MFAService.Reset(ctx, user.UserId); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "two-factor authentication of %s is disabled\n", user.UserName)
+		return nil
+	}
+
+	return fmt.Errorf("unknown user action %q. Available actions: create, reset-password, set-role, reset-mfa", args[0])
+}
+
+func roleCommand(ctx context.Context, out io.Writer, service *services.Service, args []string) error {
+	if len(args) == 0 {
+		return errors.New("role command requires action: grant or revoke")
+	}
+
+	flags := flag.NewFlagSet("role "+args[0], flag.ContinueOnError)
+	flags.SetOutput(out)
+	roleId := flags.Int("role", 0, "role_id")
+	url := flags.String("route", "", "url of the route, e.g. /booking/create")
+	scopeId := flags.Int("scope", services.AllScopeId, "scope_id: 1 - ALL, 2 - OWNER, 3 - ATTENDEE")
+	locationId := flags.Int("location", 0, "location_id to limit permission to records of one location")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	role, err := service.RoleService.GetRoleById(ctx, *roleId)
+	if err != nil {
+		return err
+	}
+	if role.RoleId == 0 {
+		return fmt.Errorf("role is not found. Passed data: role_id=%d", *roleId)
+	}
+
+	route, err := service.RouteService.GetRouteByURL(ctx, *url)
+	if err != nil {
+		return err
+	}
+	if route.RouteId == 0 {
+		return fmt.Errorf("route is not found. Passed data: %s", *url)
+	}
+
+	switch args[0] {
+	case "grant":
+		scope, err := service.ScopeService.GetScopeById(ctx, *scopeId)
+		if err != nil {
+			return err
+		}
+		if scope.ScopeId == 0 {
+			return fmt.Errorf("scope is not found. Passed data: scope_id=%d", *scopeId)
+		}
+
+		permissions, err := service.PermissionService.GetPermissionsByRoleIdAndRouteId(ctx, role.RoleId, route.RouteId)
+		if err != nil {
+			return err
+		}
+		for _, permission := range permissions {
+			if permission.ScopeId == scope.ScopeId && permission.LocationId == *locationId {
+				return fmt.Errorf("role %d already has permission to %s", role.RoleId, route.URL)
+			}
+		}
+
+		permission := models.Permission{RoleId: role.RoleId, RouteId: route.RouteId, ScopeId: scope.ScopeId, LocationId: *locationId, Active: true}
+		if _, err := service.PermissionService.Create(ctx, permission); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "permission granted: role_id=%d route=%s scope=%s\n", role.RoleId, route.URL, scope.Name)
+		return nil
+
+	case "revoke":
+		if _, err := service.PermissionService.Delete(ctx, role.RoleId, route.RouteId); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "permission revoked: role_id=%d route=%s\n", role.RoleId, route.URL)
+		return nil
+	}
+
+	return fmt.Errorf("unknown role action %q. Available actions: grant, revoke", args[0])
+}
+
+func tokenCommand(ctx context.Context, out io.Writer, service *services.Service, args []string) error {
+	if len(args) == 0 || args[0] != "issue" {
+		return errors.New("token command requires action: issue")
+	}
+
+	flags := flag.NewFlagSet("token issue", flag.ContinueOnError)
+	flags.SetOutput(out)
+	username := flags.String("username", "", "username of the service account")
+	ip := flags.String("ip", "", "IP address requests of the service account come from - tokens are bound to it")
+	userAgent := flags.String("user-agent", "", "User-Agent header of the service account's program (fingerprint token binding)")
+	device := flags.String("device", "", "X-Device-Fingerprint header of the service account's program (fingerprint token binding)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *ip == "" {
+		return errors.New("IP address of the service account is required")
+	}
+
+	user, err := findUser(ctx, service, *username)
+	if err != nil {
+		return err
+	}
+
+	// issued by administrator - no authentication methods in `amr` claim, routes requiring MFA are not available
+	accessToken, refreshToken := service.AuthService.GenerateTokens(ctx, user, pkg.IPAddressIdentity{IP: *ip, Fingerprint: pkg.DeviceFingerprint(*userAgent, *device)}, nil)
+	if accessToken == "" || refreshToken == "" {
+		return errors.New("error occured during token generation")
+	}
+
+	fmt.Fprintf(out, "access_token: %s\nrefresh_token: %s\n", accessToken, refreshToken)
+	return nil
+}
+
+func bookingsCommand(ctx context.Context, out io.Writer, service *services.Service, args []string) error {
+	if len(args) == 0 || args[0] != "purge" {
+		return errors.New("bookings command requires action: purge")
+	}
+
+	flags := flag.NewFlagSet("bookings purge", flag.ContinueOnError)
+	flags.SetOutput(out)
+	beforeStr := flags.String("before", "", "bookings ended before the date are removed: YYYY-MM-DD or RFC 3339")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	before, err := time.Parse(time.DateOnly, *beforeStr)
+	if err != nil {
+		if before, err = time.Parse(time.RFC3339, *beforeStr); err != nil {
+			return fmt.Errorf("--before should be a date (YYYY-MM-DD) or RFC 3339 time. Passed data: %s", *beforeStr)
+		}
+	}
+
+	purged, err := service.BookingService.Purge(ctx, before)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "bookings purged: %d\n", purged)
+	return nil
+}
+
+// findUser returns active user by username
+func ssoCommand(ctx context.Context, out io.Writer, args []string) error {
+	if len(args) == 0 || args[0] != "mock-provider" {
+		return errors.New("sso command requires action: mock-provider")
+	}
+
+	flags := flag.NewFlagSet("sso mock-provider", flag.ContinueOnError)
+	flags.SetOutput(out)
+	address := flags.String("address", "localhost:9000", "address the provider listens on")
+	clientId := flags.String("client-id", "go-booking-system", "client_id of the application")
+	username := flags.String("username", "jane.doe", "preferred_username of the logged in user")
+	email := flags.String("email", "jane.doe@example.com", "email of the logged in user")
+	groups := flags.String("groups", "staff", "comma-separated groups of the logged in user")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	provider, err := oidc.NewMockProvider(*clientId, oidc.MockUser{
+		Subject:           "mock-" + *username,
+		Email:             *email,
+		Name:              *username,
+		PreferredUsername: *username,
+		Groups:            strings.Split(*groups, ","),
+	})
+	if err != nil {
+		return err
+	}
+	provider.Issuer = "http://" + *address
+
+	fmt.Fprintf(out, "mock OIDC provider: set auth.sso.issuer=%s, auth.sso.clientId=%s and open /auth/oidc/login\n", provider.Issuer, *clientId)
+	server := &http.Server{Addr: *address, Handler: provider, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func findUser(ctx context.Context, service *services.Service, username string) (models.User, error) {
+	if username == "" {
+		return models.User{}, errors.New("username is required")
+	}
+
+	user, err := service.UserService.GetUserByUsername(ctx, username)
+	if err != nil {
+		return models.User{}, err
+	}
+	if user.UserId == 0 || !user.Active {
+		return models.User{}, fmt.Errorf("active user is not found. Passed data: %s", username)
+	}
+
+	return user, nil
+}
+
+func ldapCommand(ctx context.Context, out io.Writer, service *services.Service, args []string) error {
+	if len(args) == 0 || args[0] != "sync" {
+		return errors.New("ldap command requires action: sync")
+	}
+
+	organizationId := service.DirectorySyncService.OrganizationId()
+	if organizationId == 0 {
+		return errors.New("LDAP is not enabled - set auth.ldap.enabled")
+	}
+	result, err := service.ForOrganization(organizationId).DirectorySyncService.Sync(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "users created: %d, updated: %d, reactivated: %d, deactivated: %d, skipped: %d, failed: %d\n",
+		result.Created, result.Updated, result.Reactivated, result.Deactivated, result.Skipped, result.Failed)
+	return nil
+}
@@ -1,14 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/joho/godotenv"
-	"github.com/spf13/viper"
 	_ "go-booking-system/cmd/docs"
+	"go-booking-system/internal/configs"
 	"go-booking-system/internal/database"
 	"go-booking-system/internal/handlers"
+	"go-booking-system/internal/notify"
+	"go-booking-system/internal/ratelimit"
 	"go-booking-system/internal/server"
 	"go-booking-system/internal/services"
-	"log"
+	"go-booking-system/internal/tracing"
+	"go-booking-system/pkg"
+	"gorm.io/gorm"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 // @title go-booking-system
@@ -19,29 +33,186 @@ import (
 // @contact.url https://github.com/MKhiriev/go-booking-system
 // @contact.email khiriev.rasul@inbox.ru
 func main() {
-	if err := InitConfig(); err != nil {
-		log.Fatal("InitConfig(): error loading config.yaml", err)
+	os.Exit(run(os.Args[1:]))
+}
+
+// configFlags command line flags overriding keys of config
+var configFlags = map[string]string{
+	"address":      "server.address",
+	"db-host":      "db.host",
+	"db-port":      "db.port",
+	"auto-migrate": "db.autoMigrate",
+	"log-level":    "log.level",
+	"log-format":   "log.format",
+}
+
+// run executes command of the binary and returns exit code. Without arguments server is started
+func run(args []string) int {
+	flags := flag.NewFlagSet("go-booking-system", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage+"\nFlags (before command):\n")
+		flags.PrintDefaults()
+	}
+	configPath := flags.String("config", configs.DefaultPath, "path to config file")
+	flags.String("address", "", "host:port to listen on")
+	flags.String("db-host", "", "host of DB")
+	flags.String("db-port", "", "port of DB")
+	flags.Bool("auto-migrate", false, "apply pending migrations at startup")
+	flags.String("log-level", "", "debug, info, warn, error")
+	flags.String("log-format", "", "text, json")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+	if args[0] == "help" {
+		flags.Usage()
+		return 0
+	}
+
+	// environment variables of .env file - in production they are set by environment itself
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Error("error loading .env file", "error", err)
+		return 1
+	}
+
+	overrides := make(map[string]any)
+	flags.Visit(func(f *flag.Flag) {
+		if key, ok := configFlags[f.Name]; ok {
+			overrides[key] = f.Value.String()
+		}
+	})
+	config, err := configs.Load(*configPath, overrides)
+	if err != nil {
+		slog.Error("configs.Load(): config is not valid", "path", *configPath, "error", err)
+		return 1
+	}
+
+	if err := InitLogger(config.Log); err != nil {
+		slog.Error("InitLogger(): error configuring logger", "error", err)
+		return 1
+	}
+	shutdownTracing, err := tracing.Init(context.Background(), config.Tracing)
+	if err != nil {
+		slog.Error("tracing.Init(): error configuring tracing", "error", err)
+		return 1
+	}
+	defer shutdownTracing(context.Background())
+
+	notifier, err := notify.NewNotifier(config.Notify)
+	if err != nil {
+		slog.Error("notify.NewNotifier(): error configuring notifier", "error", err)
+		return 1
 	}
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("error loading .env file", err)
+
+	// application does not start without DB
+	conn, err := database.NewConnectPostgres(config.DB)
+	if err != nil {
+		slog.Error("NewConnectPostgres(): error connecting to db", "error", err)
+		return 1
 	}
-	conn := database.NewConnectPostgres()
 	repository := database.NewDatabase(conn)
-	service := services.NewService(repository)
-	handler := handlers.NewHandler(service)
-	myServer := new(server.Server)
-	err := myServer.ServerRun(handler.Init(), viper.GetString("server.port"))
-	check(err)
+	defer repository.Close()
+
+	if args[0] == "serve" {
+		if err := serve(config, conn, repository, notifier); err != nil {
+			slog.Error("serve(): server stopped with error", "error", err)
+			return 1
+		}
+		return 0
+	}
+
+	// admin commands work with data of all organizations
+	if err := RunCommand(context.Background(), os.Stdout, conn, services.NewService(repository, config.Auth, notifier), args); err != nil {
+		slog.Error("RunCommand(): command failed", "command", args[0], "error", err)
+		return 1
+	}
+	return 0
+}
+
+// serve starts HTTP server and blocks until SIGTERM/SIGINT or server error
+func serve(config configs.Config, conn *gorm.DB, repository *database.Database, notifier notify.Notifier) error {
+	if config.DB.AutoMigrate {
+		if err := migrate(context.Background(), os.Stdout, conn, []string{"up"}); err != nil {
+			return fmt.Errorf("migration at startup: %w", err)
+		}
+	}
+
+	service := services.NewService(repository, config.Auth, notifier)
+	handler := handlers.NewHandler(service, handlers.Config{
+		CORS:                config.CORS,
+		TrustedProxies:      config.Server.TrustedProxies,
+		SSOEnabled:          config.Auth.SSO.Enabled,
+		RegistrationEnabled: config.Features.Registration,
+		MetricsEnabled:      config.Features.Metrics,
+		MetricsToken:        config.Metrics.Token,
+		SwaggerEnabled:      config.Features.Swagger,
+		SwaggerURL:          config.Features.SwaggerURL,
+	}, ratelimit.NewLimiter(ratelimit.NewMemoryStore(), config.RateLimit))
+	myServer := server.NewServer(config.Server)
+
+	// SIGTERM (docker stop, kubernetes) or Ctrl+C - stop accepting requests, drain in-flight ones and stop workers
+	signalContext, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stopSignals()
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- myServer.ServerRun(handler.Init())
+	}()
+	if config.Auth.LDAP.Enabled && config.Auth.LDAP.SyncInterval > 0 {
+		directoryService := service.ForOrganization(config.Auth.LDAP.OrganizationId)
+		myServer.RunWorker("ldap-sync", func(ctx context.Context) {
+			syncDirectory(ctx, directoryService.DirectorySyncService, config.Auth.LDAP.SyncInterval)
+		})
+	}
+
+	select {
+	case err := <-serverErrors:
+		return err
+	case <-signalContext.Done():
+		slog.Info("shutdown started")
+		handler.StartShutdown()
+		if err := myServer.Shutdown(); err != nil {
+			slog.Error("Server.Shutdown(): error occured during graceful shutdown", "error", err)
+		}
+		slog.Info("server stopped")
+	}
+
+	return nil
 }
 
-func InitConfig() error {
-	viper.AddConfigPath("internal/configs")
-	viper.SetConfigName("config")
-	return viper.ReadInConfig()
+// syncDirectory synchronizes users with LDAP / Active Directory at start and then every interval until ctx is cancelled.
+// Failed sync is retried at the next tick
+func syncDirectory(ctx context.Context, syncService services.DirectorySyncServiceInterface, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := syncService.Sync(ctx); err != nil {
+			slog.ErrorContext(ctx, "syncDirectory(): error occured during sync of directory users", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
-func check(err error) {
+// InitLogger sets default logger. Level and format are taken from `log` section of config
+func InitLogger(config configs.LogConfig) error {
+	logger, err := pkg.NewLogger(os.Stdout, config.Level, config.Format)
 	if err != nil {
-		log.Println(err)
+		return err
 	}
+
+	slog.SetDefault(logger)
+	return nil
 }
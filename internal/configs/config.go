@@ -0,0 +1,377 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/handlers"
+	"go-booking-system/internal/notify"
+	"go-booking-system/internal/oidc"
+	"go-booking-system/internal/ratelimit"
+	"go-booking-system/internal/server"
+	"go-booking-system/internal/services"
+	"go-booking-system/internal/tracing"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPath config file used if `--config` flag is not passed
+	DefaultPath = "internal/configs/config.yaml"
+
+	// EnvPrefix environment variables override values of config file: BOOKING_<SECTION>_<KEY>, e.g. BOOKING_SERVER_ADDRESS
+	EnvPrefix = "BOOKING"
+)
+
+// Config of the application. Values are taken from (in order of priority): command line flags, environment variables,
+// config file, defaults
+type Config struct {
+	Server    server.Config
+	DB        database.Config
+	Auth      services.AuthConfig
+	CORS      handlers.CORSConfig
+	RateLimit ratelimit.Config
+	Notify    notify.Config
+	Features  FeaturesConfig
+	Metrics   MetricsConfig
+	Log       LogConfig
+	Tracing   tracing.Config
+}
+
+// FeaturesConfig optional endpoints
+type FeaturesConfig struct {
+	Registration bool
+	Swagger      bool
+	SwaggerURL   string
+	Metrics      bool
+}
+
+type MetricsConfig struct {
+	// Token protects `/metrics` endpoint. Secret - set by environment variable
+	Token string
+}
+
+type LogConfig struct {
+	Level  string // debug, info, warn, error
+	Format string // text, json
+}
+
+// legacyEnv environment variables used before unified config - still supported
+var legacyEnv = map[string]string{
+	"db.password":   "DB_PASSWORD",
+	"metrics.token": "METRICS_TOKEN",
+}
+
+// Load reads config file, applies environment variables and overrides (values of command line flags, keys like `server.address`)
+// and validates the result
+func Load(path string, overrides map[string]any) (Config, error) {
+	v := viper.New()
+	setDefaults(v)
+
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return Config{}, fmt.Errorf("cannot read config file %s: %w", path, err)
+	}
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	for key, legacyName := range legacyEnv {
+		if err := v.BindEnv(key, EnvPrefix+"_"+strings.ToUpper(strings.ReplaceAll(key, ".", "_")), legacyName); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for key, value := range overrides {
+		v.Set(key, value)
+	}
+
+	var config Config
+	if err := v.Unmarshal(&config); err != nil {
+		return Config{}, fmt.Errorf("cannot parse config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+// setDefaults every key should have a default - otherwise it cannot be set by environment variable
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.address", "localhost:8080")
+	v.SetDefault("server.readTimeout", 10*time.Second)
+	v.SetDefault("server.writeTimeout", 10*time.Second)
+	v.SetDefault("server.idleTimeout", 60*time.Second)
+	v.SetDefault("server.shutdownTimeout", 30*time.Second)
+	v.SetDefault("server.trustedProxies", []string{})
+
+	v.SetDefault("db.host", "localhost")
+	v.SetDefault("db.port", 5432)
+	v.SetDefault("db.username", "postgres")
+	v.SetDefault("db.password", "")
+	v.SetDefault("db.dbName", "humo_booking")
+	v.SetDefault("db.sslMode", "disable")
+	v.SetDefault("db.connectTimeout", 5*time.Second)
+	v.SetDefault("db.autoMigrate", false)
+	v.SetDefault("db.maxOpenConns", 20)
+	v.SetDefault("db.maxIdleConns", 10)
+	v.SetDefault("db.connMaxLifetime", 30*time.Minute)
+	v.SetDefault("db.connMaxIdleTime", 5*time.Minute)
+
+	v.SetDefault("auth.accessTokenKey", "")
+	v.SetDefault("auth.refreshTokenKey", "")
+	// salt of existing password hashes - changing it requires reset of all passwords
+	v.SetDefault("auth.passwordSalt", "eyJhbGciOiJIUzI1NiIsInR5ed217a32a94ba416f88e16122278cCI6IkpXVCJ9")
+	v.SetDefault("auth.accessTokenTTL", time.Hour)
+	v.SetDefault("auth.refreshTokenTTL", 3*time.Hour)
+	v.SetDefault("auth.mfaChallengeTTL", 5*time.Minute)
+	v.SetDefault("auth.passwordResetTTL", time.Hour)
+	v.SetDefault("auth.emailVerificationTTL", 48*time.Hour)
+	v.SetDefault("auth.requireEmailVerification", true)
+	v.SetDefault("auth.publicURL", "http://localhost:8080")
+	v.SetDefault("auth.apiKeyMaxTTL", 365*24*time.Hour)
+	v.SetDefault("auth.tokenBinding.mode", services.TokenBindingIP)
+	v.SetDefault("auth.tokenBinding.ipv4PrefixLength", 24)
+	v.SetDefault("auth.tokenBinding.ipv6PrefixLength", 64)
+	v.SetDefault("auth.sso.enabled", false)
+	v.SetDefault("auth.sso.issuer", "")
+	v.SetDefault("auth.sso.clientId", "")
+	v.SetDefault("auth.sso.clientSecret", "")
+	v.SetDefault("auth.sso.redirectURL", "http://localhost:8080/auth/oidc/callback")
+	v.SetDefault("auth.sso.scopes", []string{"openid", "profile", "email"})
+	v.SetDefault("auth.sso.groupsClaim", "groups")
+	v.SetDefault("auth.sso.groupRoles", []oidc.GroupRole{})
+	v.SetDefault("auth.sso.defaultRoleId", 0)
+	v.SetDefault("auth.sso.organizationId", services.HostOrganizationId)
+	v.SetDefault("auth.sso.linkByEmail", false)
+	v.SetDefault("auth.sso.flowTTL", 10*time.Minute)
+	v.SetDefault("auth.ldap.enabled", false)
+	v.SetDefault("auth.ldap.url", "")
+	v.SetDefault("auth.ldap.startTLS", false)
+	v.SetDefault("auth.ldap.bindDN", "")
+	v.SetDefault("auth.ldap.bindPassword", "")
+	v.SetDefault("auth.ldap.baseDN", "")
+	v.SetDefault("auth.ldap.userFilter", "(&(objectClass=user)(objectCategory=person))")
+	v.SetDefault("auth.ldap.idAttribute", "objectGUID")
+	v.SetDefault("auth.ldap.usernameAttribute", "sAMAccountName")
+	v.SetDefault("auth.ldap.nameAttribute", "displayName")
+	v.SetDefault("auth.ldap.emailAttribute", "mail")
+	v.SetDefault("auth.ldap.telephoneAttribute", "telephoneNumber")
+	v.SetDefault("auth.ldap.groupsAttribute", "memberOf")
+	v.SetDefault("auth.ldap.groupRoles", []oidc.GroupRole{})
+	v.SetDefault("auth.ldap.defaultRoleId", 0)
+	v.SetDefault("auth.ldap.organizationId", services.HostOrganizationId)
+	v.SetDefault("auth.ldap.syncInterval", time.Hour)
+	v.SetDefault("auth.ldap.timeout", 10*time.Second)
+	v.SetDefault("auth.ldap.pageSize", 500)
+
+	v.SetDefault("cors.allowedOrigins", []string{})
+	v.SetDefault("cors.allowedHeaders", []string{"Authorization", "Content-Type", "X-Request-ID", "traceparent", "tracestate"})
+	v.SetDefault("cors.exposedHeaders", []string{"X-Request-ID", "traceparent", "Content-Disposition"})
+	v.SetDefault("cors.allowCredentials", false)
+	v.SetDefault("cors.maxAge", 10*time.Minute)
+
+	v.SetDefault("rateLimit.enabled", true)
+	v.SetDefault("rateLimit.ip.burst", 20)
+	v.SetDefault("rateLimit.ip.interval", 3*time.Second)
+	v.SetDefault("rateLimit.username.burst", 10)
+	v.SetDefault("rateLimit.username.interval", 30*time.Second)
+	v.SetDefault("rateLimit.registration.burst", 5)
+	v.SetDefault("rateLimit.registration.interval", time.Minute)
+	v.SetDefault("rateLimit.passwordReset.burst", 3)
+	v.SetDefault("rateLimit.passwordReset.interval", 5*time.Minute)
+	v.SetDefault("rateLimit.maxFailures", 5)
+	v.SetDefault("rateLimit.failureWindow", 15*time.Minute)
+	v.SetDefault("rateLimit.lockoutDuration", 15*time.Minute)
+	v.SetDefault("rateLimit.failureDelay", 250*time.Millisecond)
+	v.SetDefault("rateLimit.maxFailureDelay", 4*time.Second)
+
+	v.SetDefault("notify.provider", notify.ProviderLog)
+	v.SetDefault("notify.smtp.host", "")
+	v.SetDefault("notify.smtp.port", 587)
+	v.SetDefault("notify.smtp.username", "")
+	v.SetDefault("notify.smtp.password", "")
+	v.SetDefault("notify.smtp.from", "")
+
+	v.SetDefault("features.registration", true)
+	v.SetDefault("features.swagger", true)
+	v.SetDefault("features.swaggerURL", "/swagger/doc.json")
+	v.SetDefault("features.metrics", true)
+
+	v.SetDefault("metrics.token", "")
+
+	v.SetDefault("log.level", "info")
+	v.SetDefault("log.format", "text")
+
+	v.SetDefault("tracing.exporter", tracing.ExporterNone)
+	v.SetDefault("tracing.file", "traces.jsonl")
+	v.SetDefault("tracing.endpoint", "localhost:4318")
+	v.SetDefault("tracing.sampleRatio", 1)
+}
+
+// Validate returns all found errors at once
+func (c Config) Validate() error {
+	var errs []error
+	check := func(isValid bool, format string, args ...any) {
+		if !isValid {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	// server
+	_, _, addressError := net.SplitHostPort(c.Server.Address)
+	check(addressError == nil, "server.address should be host:port. Passed data: %q", c.Server.Address)
+	check(c.Server.ReadTimeout > 0, "server.readTimeout should be positive")
+	check(c.Server.WriteTimeout > 0, "server.writeTimeout should be positive")
+	check(c.Server.IdleTimeout > 0, "server.idleTimeout should be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdownTimeout should be positive")
+	for _, proxy := range c.Server.TrustedProxies {
+		_, proxyError := handlers.ParseTrustedProxy(proxy)
+		check(proxyError == nil, "server.trustedProxies should contain IP addresses or networks (CIDR). Passed data: %q", proxy)
+	}
+
+	// db
+	check(c.DB.Host != "", "db.host should not be empty")
+	check(c.DB.Port != 0, "db.port should not be 0")
+	check(c.DB.Username != "", "db.username should not be empty")
+	check(c.DB.DBName != "", "db.dbName should not be empty")
+	check(c.DB.ConnectTimeout > 0, "db.connectTimeout should be positive")
+	check(c.DB.MaxOpenConns >= 0, "db.maxOpenConns should not be negative")
+	check(c.DB.MaxIdleConns >= 0, "db.maxIdleConns should not be negative")
+	check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns, "db.maxIdleConns should not be greater than db.maxOpenConns")
+	check(c.DB.ConnMaxLifetime >= 0, "db.connMaxLifetime should not be negative")
+	check(c.DB.ConnMaxIdleTime >= 0, "db.connMaxIdleTime should not be negative")
+
+	// auth
+	check(len(c.Auth.AccessTokenKey) >= services.MinTokenKeyLength, "auth.accessTokenKey should contain at least %d characters (set %s_AUTH_ACCESSTOKENKEY)", services.MinTokenKeyLength, EnvPrefix)
+	check(len(c.Auth.RefreshTokenKey) >= services.MinTokenKeyLength, "auth.refreshTokenKey should contain at least %d characters (set %s_AUTH_REFRESHTOKENKEY)", services.MinTokenKeyLength, EnvPrefix)
+	check(c.Auth.AccessTokenKey != c.Auth.RefreshTokenKey, "auth.accessTokenKey and auth.refreshTokenKey should be different")
+	check(c.Auth.PasswordSalt != "", "auth.passwordSalt should not be empty")
+	check(c.Auth.AccessTokenTTL > 0, "auth.accessTokenTTL should be positive")
+	check(c.Auth.RefreshTokenTTL >= c.Auth.AccessTokenTTL, "auth.refreshTokenTTL should not be shorter than auth.accessTokenTTL")
+	check(c.Auth.MFAChallengeTTL > 0, "auth.mfaChallengeTTL should be positive")
+	check(c.Auth.PasswordResetTTL > 0, "auth.passwordResetTTL should be positive")
+	check(c.Auth.EmailVerificationTTL > 0, "auth.emailVerificationTTL should be positive")
+	check(isAbsoluteURL(c.Auth.PublicURL), "auth.publicURL should be URL of the application. Passed data: %q", c.Auth.PublicURL)
+	check(c.Auth.APIKeyMaxTTL > 0, "auth.apiKeyMaxTTL should be positive")
+	switch c.Auth.TokenBinding.Mode {
+	case services.TokenBindingNone, services.TokenBindingIP, services.TokenBindingFingerprint:
+	case services.TokenBindingSubnet:
+		check(c.Auth.TokenBinding.IPv4PrefixLength >= 0 && c.Auth.TokenBinding.IPv4PrefixLength <= 32, "auth.tokenBinding.ipv4PrefixLength should be between 0 and 32")
+		check(c.Auth.TokenBinding.IPv6PrefixLength >= 0 && c.Auth.TokenBinding.IPv6PrefixLength <= 128, "auth.tokenBinding.ipv6PrefixLength should be between 0 and 128")
+	default:
+		check(false, "auth.tokenBinding.mode should be none, ip, subnet or fingerprint. Passed data: %q", c.Auth.TokenBinding.Mode)
+	}
+	if c.Auth.SSO.Enabled {
+		check(isAbsoluteURL(c.Auth.SSO.Issuer), "auth.sso.issuer should be URL of identity provider. Passed data: %q", c.Auth.SSO.Issuer)
+		check(c.Auth.SSO.ClientId != "", "auth.sso.clientId should not be empty")
+		check(isAbsoluteURL(c.Auth.SSO.RedirectURL), "auth.sso.redirectURL should be URL of /auth/oidc/callback. Passed data: %q", c.Auth.SSO.RedirectURL)
+		check(slices.Contains(c.Auth.SSO.Scopes, "openid"), "auth.sso.scopes should contain openid")
+		for _, groupRole := range c.Auth.SSO.GroupRoles {
+			check(groupRole.Group != "" && groupRole.RoleId > 0, "auth.sso.groupRoles should contain group and positive roleId. Passed data: %+v", groupRole)
+		}
+		check(len(c.Auth.SSO.GroupRoles) == 0 || c.Auth.SSO.GroupsClaim != "", "auth.sso.groupsClaim should not be empty if auth.sso.groupRoles is set")
+		check(c.Auth.SSO.DefaultRoleId >= 0, "auth.sso.defaultRoleId should not be negative")
+		check(c.Auth.SSO.OrganizationId > 0, "auth.sso.organizationId should be positive")
+		check(c.Auth.SSO.FlowTTL > 0, "auth.sso.flowTTL should be positive")
+	}
+	if c.Auth.LDAP.Enabled {
+		check(isLDAPURL(c.Auth.LDAP.URL), "auth.ldap.url should be ldap:// or ldaps:// URL of directory. Passed data: %q", c.Auth.LDAP.URL)
+		check(!c.Auth.LDAP.StartTLS || strings.HasPrefix(c.Auth.LDAP.URL, "ldap://"), "auth.ldap.startTLS can be used only with ldap:// URL")
+		check(c.Auth.LDAP.BindDN != "", "auth.ldap.bindDN should not be empty")
+		check(c.Auth.LDAP.BaseDN != "", "auth.ldap.baseDN should not be empty")
+		check(c.Auth.LDAP.IdAttribute != "" && c.Auth.LDAP.UsernameAttribute != "", "auth.ldap.idAttribute and auth.ldap.usernameAttribute should not be empty")
+		for _, groupRole := range c.Auth.LDAP.GroupRoles {
+			check(groupRole.Group != "" && groupRole.RoleId > 0, "auth.ldap.groupRoles should contain group and positive roleId. Passed data: %+v", groupRole)
+		}
+		check(len(c.Auth.LDAP.GroupRoles) == 0 || c.Auth.LDAP.GroupsAttribute != "", "auth.ldap.groupsAttribute should not be empty if auth.ldap.groupRoles is set")
+		check(c.Auth.LDAP.DefaultRoleId >= 0, "auth.ldap.defaultRoleId should not be negative")
+		check(c.Auth.LDAP.OrganizationId > 0, "auth.ldap.organizationId should be positive")
+		check(c.Auth.LDAP.SyncInterval >= 0, "auth.ldap.syncInterval should not be negative")
+		check(c.Auth.LDAP.Timeout > 0, "auth.ldap.timeout should be positive")
+		check(c.Auth.LDAP.PageSize > 0, "auth.ldap.pageSize should be positive")
+	}
+
+	// cors
+	for _, origin := range c.CORS.AllowedOrigins {
+		check(origin == "*" || isOrigin(origin), "cors.allowedOrigins should contain scheme://host[:port] or * (host may start with *. for subdomains). Passed data: %q", origin)
+	}
+	check(!c.CORS.AllowCredentials || !slices.Contains(c.CORS.AllowedOrigins, "*"), "cors.allowCredentials cannot be used with * origin")
+	check(c.CORS.MaxAge >= 0, "cors.maxAge should not be negative")
+
+	// rate limit
+	if c.RateLimit.Enabled {
+		names := []string{"ip", "username", "registration", "passwordReset"}
+		for i, limit := range []ratelimit.Limit{c.RateLimit.IP, c.RateLimit.Username, c.RateLimit.Registration, c.RateLimit.PasswordReset} {
+			check(limit.Burst >= 0, "rateLimit.%s.burst should not be negative", names[i])
+			check(limit.Burst == 0 || limit.Interval > 0, "rateLimit.%s.interval should be positive", names[i])
+		}
+		check(c.RateLimit.MaxFailures >= 0, "rateLimit.maxFailures should not be negative")
+		check(c.RateLimit.MaxFailures == 0 || c.RateLimit.FailureWindow > 0 && c.RateLimit.LockoutDuration > 0,
+			"rateLimit.failureWindow and rateLimit.lockoutDuration should be positive if rateLimit.maxFailures is set")
+		check(c.RateLimit.FailureDelay >= 0 && c.RateLimit.MaxFailureDelay >= c.RateLimit.FailureDelay,
+			"rateLimit.maxFailureDelay should not be shorter than rateLimit.failureDelay")
+	}
+
+	// notify
+	switch c.Notify.Provider {
+	case notify.ProviderLog:
+	case notify.ProviderSMTP:
+		check(c.Notify.SMTP.Host != "", "notify.smtp.host should not be empty")
+		check(c.Notify.SMTP.Port > 0, "notify.smtp.port should be positive")
+		check(c.Notify.SMTP.From != "", "notify.smtp.from should not be empty")
+	default:
+		check(false, "notify.provider should be log or smtp. Passed data: %q", c.Notify.Provider)
+	}
+
+	// features
+	check(!c.Features.Swagger || c.Features.SwaggerURL != "", "features.swaggerURL should not be empty if swagger is enabled")
+
+	// log
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log.level should be debug, info, warn or error. Passed data: %q", c.Log.Level)
+	check(c.Log.Format == pkg.LogFormatText || c.Log.Format == pkg.LogFormatJSON, "log.format should be text or json. Passed data: %q", c.Log.Format)
+
+	// tracing
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterFile, tracing.ExporterOTLP:
+	default:
+		check(false, "tracing.exporter should be none, stdout, file or otlp. Passed data: %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio should be between 0 and 1")
+
+	return errors.Join(errs...)
+}
+
+// isAbsoluteURL http(s) URL with host
+func isAbsoluteURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// isLDAPURL ldap(s) URL with host
+func isLDAPURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+
+	return err == nil && (parsed.Scheme == "ldap" || parsed.Scheme == "ldaps") && parsed.Host != ""
+}
+
+// isOrigin origin has scheme and host only - no path, query or trailing slash. Host may start with `*.` - any subdomain
+func isOrigin(origin string) bool {
+	parsed, err := url.Parse(strings.Replace(origin, "://*.", "://", 1))
+	if err != nil {
+		return false
+	}
+
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "" && !strings.Contains(parsed.Host, "*") &&
+		parsed.Path == "" && parsed.RawQuery == ""
+}
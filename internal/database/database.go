@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"go-booking-system/internal/database/repositories"
 	"go-booking-system/internal/models"
 	"gorm.io/gorm"
@@ -15,81 +16,265 @@ type Database struct {
 	RouteRepository
 	ScopeRepository
 	PermissionRepository
+	DeviceRepository
+	LocationRepository
+	BuildingRepository
+	FloorRepository
+	OpeningHoursRepository
+	OrganizationRepository
+	RoomShareRepository
+	AttendeeRepository
+	ReportRepository
+	RecoveryCodeRepository
+	MFAPolicyRepository
+	UserTokenRepository
+	APIKeyRepository
+	PolicyRepository
+
+	connection *gorm.DB
 }
 
 func NewDatabase(conn *gorm.DB) *Database {
+	return newDatabase(conn, repositories.SystemOrganizationId)
+}
+
+// ForOrganization returns Database which repositories of tenant data (users, rooms, roles, permissions, bookings, devices, locations, buildings,
+// floors, opening hours, room shares, API keys, access policies, attendees, reports) read and write only records of the organization
+func (d *Database) ForOrganization(organizationId int) *Database {
+	return newDatabase(d.connection, organizationId)
+}
+
+// Ping checks that DB is reachable
+func (d *Database) Ping(ctx context.Context) error {
+	sqlDB, err := d.connection.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
+
+// Close closes connection pool. Queries in progress are finished first
+func (d *Database) Close() error {
+	sqlDB, err := d.connection.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Close()
+}
+
+func newDatabase(conn *gorm.DB, organizationId int) *Database {
 	return &Database{
-		BookingRepository:    repositories.NewBookingRepositoryPostgres(conn),
-		RoomRepository:       repositories.NewRoomRepositoryPostgres(conn),
-		UserRepository:       repositories.NewUserRepositoryPostgres(conn),
-		RoleRepository:       repositories.NewRoleRepositoryPostgres(conn),
-		RouteRepository:      repositories.NewRouteRepositoryPostgres(conn),
-		ScopeRepository:      repositories.NewScopeRepositoryPostgres(conn),
-		PermissionRepository: repositories.NewPermissionRepositoryPostgres(conn),
+		BookingRepository:      repositories.NewBookingRepositoryPostgres(conn).ForOrganization(organizationId),
+		RoomRepository:         repositories.NewRoomRepositoryPostgres(conn).ForOrganization(organizationId),
+		UserRepository:         repositories.NewUserRepositoryPostgres(conn).ForOrganization(organizationId),
+		RoleRepository:         repositories.NewRoleRepositoryPostgres(conn).ForOrganization(organizationId),
+		RouteRepository:        repositories.NewRouteRepositoryPostgres(conn),
+		ScopeRepository:        repositories.NewScopeRepositoryPostgres(conn),
+		PermissionRepository:   repositories.NewPermissionRepositoryPostgres(conn).ForOrganization(organizationId),
+		DeviceRepository:       repositories.NewDeviceRepositoryPostgres(conn).ForOrganization(organizationId),
+		LocationRepository:     repositories.NewLocationRepositoryPostgres(conn).ForOrganization(organizationId),
+		BuildingRepository:     repositories.NewBuildingRepositoryPostgres(conn).ForOrganization(organizationId),
+		FloorRepository:        repositories.NewFloorRepositoryPostgres(conn).ForOrganization(organizationId),
+		OpeningHoursRepository: repositories.NewOpeningHoursRepositoryPostgres(conn).ForOrganization(organizationId),
+		OrganizationRepository: repositories.NewOrganizationRepositoryPostgres(conn),
+		RoomShareRepository:    repositories.NewRoomShareRepositoryPostgres(conn).ForOrganization(organizationId),
+		AttendeeRepository:     repositories.NewAttendeeRepositoryPostgres(conn).ForOrganization(organizationId),
+		ReportRepository:       repositories.NewReportRepositoryPostgres(conn).ForOrganization(organizationId),
+		RecoveryCodeRepository: repositories.NewRecoveryCodeRepositoryPostgres(conn),
+		MFAPolicyRepository:    repositories.NewMFAPolicyRepositoryPostgres(conn).ForOrganization(organizationId),
+		UserTokenRepository:    repositories.NewUserTokenRepositoryPostgres(conn),
+		APIKeyRepository:       repositories.NewAPIKeyRepositoryPostgres(conn).ForOrganization(organizationId),
+		PolicyRepository:       repositories.NewPolicyRepositoryPostgres(conn).ForOrganization(organizationId),
+
+		connection: conn,
 	}
 }
 
 type BookingRepository interface {
-	Create(booking models.Booking) (models.Booking, error)
-	GetAll() []models.Booking
-	GetBookingById(bookingId int) (models.Booking, error)
-	GetBookingsByRoomId(roomId int) ([]models.Booking, error)
-	GetBookingsByRoomIdAndBookingTime(roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Booking, error)
-	Update(booking models.Booking) (models.Booking, error)
-	Delete(bookingId int) (bool, error)
+	Create(ctx context.Context, booking models.Booking) (models.Booking, error)
+	GetAll(ctx context.Context) []models.Booking
+	GetBookingById(ctx context.Context, bookingId int) (models.Booking, error)
+	GetBookingsByRoomId(ctx context.Context, roomId int) ([]models.Booking, error)
+	GetBookingsByRoomIdAndBookingTime(ctx context.Context, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Booking, error)
+	GetUpcomingBookingsByRoomId(ctx context.Context, roomId int, from time.Time, limit int) ([]models.Booking, error)
+	Update(ctx context.Context, booking models.Booking) (models.Booking, error)
+	CheckIn(ctx context.Context, bookingId int, checkedInAt time.Time) (models.Booking, error)
+	Delete(ctx context.Context, bookingId int) (bool, error)
+	DeleteEndedBefore(ctx context.Context, before time.Time) (int, error)
 }
 
 type UserRepository interface {
-	Create(user models.User) (models.User, error)
-	GetAll() []models.User
-	GetUserById(userId int) (models.User, error)
-	Update(user models.User) (models.User, error)
-	Delete(userId int) (bool, error)
-	UpdatePassword(user models.User) (models.User, error)
-	UpdateUsername(user models.User) (models.User, error)
-	UpdateUserRole(user models.User) (models.User, error)
-	GetUserByUsername(username string) (models.User, error)
+	Create(ctx context.Context, user models.User) (models.User, error)
+	GetAll(ctx context.Context) []models.User
+	GetUserById(ctx context.Context, userId int) (models.User, error)
+	Update(ctx context.Context, user models.User) (models.User, error)
+	Delete(ctx context.Context, userId int) (bool, error)
+	UpdatePassword(ctx context.Context, user models.User) (models.User, error)
+	UpdateUsername(ctx context.Context, user models.User) (models.User, error)
+	UpdateUserRole(ctx context.Context, user models.User) (models.User, error)
+	UpdateTOTP(ctx context.Context, user models.User) (models.User, error)
+	UpdateTOTPLastStep(ctx context.Context, userId int, step int64) (bool, error)
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+	UpdateSSOSubject(ctx context.Context, user models.User) (models.User, error)
+	GetUserBySSOSubject(ctx context.Context, issuer string, subject string) (models.User, error)
+	UpdateActive(ctx context.Context, userId int, active bool) (bool, error)
+	GetUsersByAuthProvider(ctx context.Context, authProvider string) ([]models.User, error)
+	UpdateEmailVerified(ctx context.Context, userId int, emailVerified bool) (bool, error)
+}
+
+type UserTokenRepository interface {
+	ReplaceUserToken(ctx context.Context, userToken models.UserToken) (models.UserToken, error)
+	UseUserToken(ctx context.Context, purpose string, tokenHash string) (models.UserToken, error)
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, apiKey models.APIKey) (models.APIKey, error)
+	GetAll(ctx context.Context) []models.APIKey
+	GetAPIKeyById(ctx context.Context, apiKeyId int) (models.APIKey, error)
+	UpdateLastUsed(ctx context.Context, apiKeyId int, lastUsedAt time.Time) error
+	Delete(ctx context.Context, apiKeyId int) (bool, error)
+}
+
+type PolicyRepository interface {
+	Create(ctx context.Context, policy models.Policy) (models.Policy, error)
+	GetAll(ctx context.Context) []models.Policy
+	GetPolicyById(ctx context.Context, policyId int) (models.Policy, error)
+	GetPoliciesByRoleIdAndRouteId(ctx context.Context, roleId int, routeId int) ([]models.Policy, error)
+	Update(ctx context.Context, policy models.Policy) (models.Policy, error)
+	Delete(ctx context.Context, policyId int) (bool, error)
 }
 
 type RoomRepository interface {
-	Create(room models.Room) (models.Room, error)
-	GetAll() []models.Room
-	GetRoomById(roomId int) (models.Room, error)
-	Update(room models.Room) (models.Room, error)
-	Delete(roomId int) (bool, error)
+	Create(ctx context.Context, room models.Room) (models.Room, error)
+	GetAll(ctx context.Context) []models.Room
+	GetRoomById(ctx context.Context, roomId int) (models.Room, error)
+	GetRoomByNumber(ctx context.Context, number string) (models.Room, error)
+	GetRoomsByLocationId(ctx context.Context, locationId int) ([]models.Room, error)
+	GetAvailableRoomsByLocationId(ctx context.Context, locationId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Room, error)
+	Update(ctx context.Context, room models.Room) (models.Room, error)
+	Delete(ctx context.Context, roomId int) (bool, error)
 }
 
 type RoleRepository interface {
-	Create(role models.Role) (models.Role, error)
-	GetAll() []models.Role
-	GetRoleById(roleId int) (models.Role, error)
-	Update(role models.Role) (models.Role, error)
-	Delete(roleId int) (bool, error)
+	Create(ctx context.Context, role models.Role) (models.Role, error)
+	GetAll(ctx context.Context) []models.Role
+	GetRoleById(ctx context.Context, roleId int) (models.Role, error)
+	Update(ctx context.Context, role models.Role) (models.Role, error)
+	Delete(ctx context.Context, roleId int) (bool, error)
 }
 
 type RouteRepository interface {
-	Create(route models.Route) (models.Route, error)
-	GetAll() []models.Route
-	GetRouteById(routeId int) (models.Route, error)
-	GetRouteByURL(url string) (models.Route, error)
-	Update(route models.Route) (models.Route, error)
-	Delete(routeId int) (bool, error)
+	Create(ctx context.Context, route models.Route) (models.Route, error)
+	GetAll(ctx context.Context) []models.Route
+	GetRouteById(ctx context.Context, routeId int) (models.Route, error)
+	GetRouteByURL(ctx context.Context, url string) (models.Route, error)
+	Update(ctx context.Context, route models.Route) (models.Route, error)
+	Delete(ctx context.Context, routeId int) (bool, error)
 }
 
 type ScopeRepository interface {
-	Create(scope models.Scope) (models.Scope, error)
-	GetAll() []models.Scope
-	GetScopeById(scopeId int) (models.Scope, error)
-	Update(scope models.Scope) (models.Scope, error)
-	Delete(scopeId int) (bool, error)
+	Create(ctx context.Context, scope models.Scope) (models.Scope, error)
+	GetAll(ctx context.Context) []models.Scope
+	GetScopeById(ctx context.Context, scopeId int) (models.Scope, error)
+	Update(ctx context.Context, scope models.Scope) (models.Scope, error)
+	Delete(ctx context.Context, scopeId int) (bool, error)
 }
 
 type PermissionRepository interface {
-	Create(permission models.Permission) (models.Permission, error)
-	GetAll() []models.Permission
-	GetPermissionsByRoleId(roleId int) ([]models.Permission, error)
-	GetPermissionsByRouteId(routeId int) ([]models.Permission, error)
-	GetPermissionsByRoleIdAndRouteId(roleId int, routeId int) ([]models.Permission, error)
-	Update(permission models.Permission) (models.Permission, error)
-	Delete(roleId int, routeId int) (bool, error)
+	Create(ctx context.Context, permission models.Permission) (models.Permission, error)
+	GetAll(ctx context.Context) []models.Permission
+	GetPermissionsByRoleId(ctx context.Context, roleId int) ([]models.Permission, error)
+	GetPermissionsByRouteId(ctx context.Context, routeId int) ([]models.Permission, error)
+	GetPermissionsByRoleIdAndRouteId(ctx context.Context, roleId int, routeId int) ([]models.Permission, error)
+	Update(ctx context.Context, permission models.Permission) (models.Permission, error)
+	Delete(ctx context.Context, roleId int, routeId int) (bool, error)
+}
+
+type DeviceRepository interface {
+	Create(ctx context.Context, device models.Device) (models.Device, error)
+	GetAll(ctx context.Context) []models.Device
+	GetDeviceById(ctx context.Context, deviceId int) (models.Device, error)
+	Delete(ctx context.Context, deviceId int) (bool, error)
+}
+
+type LocationRepository interface {
+	Create(ctx context.Context, location models.Location) (models.Location, error)
+	GetAll(ctx context.Context) []models.Location
+	GetLocationById(ctx context.Context, locationId int) (models.Location, error)
+	GetLocationByRoomId(ctx context.Context, roomId int) (models.Location, error)
+	Update(ctx context.Context, location models.Location) (models.Location, error)
+	Delete(ctx context.Context, locationId int) (bool, error)
+}
+
+type BuildingRepository interface {
+	Create(ctx context.Context, building models.Building) (models.Building, error)
+	GetAll(ctx context.Context) []models.Building
+	GetBuildingById(ctx context.Context, buildingId int) (models.Building, error)
+	GetBuildingsByLocationId(ctx context.Context, locationId int) ([]models.Building, error)
+	Update(ctx context.Context, building models.Building) (models.Building, error)
+	Delete(ctx context.Context, buildingId int) (bool, error)
+}
+
+type FloorRepository interface {
+	Create(ctx context.Context, floor models.Floor) (models.Floor, error)
+	GetAll(ctx context.Context) []models.Floor
+	GetFloorById(ctx context.Context, floorId int) (models.Floor, error)
+	GetFloorsByBuildingId(ctx context.Context, buildingId int) ([]models.Floor, error)
+	Update(ctx context.Context, floor models.Floor) (models.Floor, error)
+	Delete(ctx context.Context, floorId int) (bool, error)
+}
+
+type OpeningHoursRepository interface {
+	GetOpeningHoursByLocationId(ctx context.Context, locationId int) ([]models.OpeningHours, error)
+	ReplaceOpeningHours(ctx context.Context, locationId int, openingHours []models.OpeningHours) ([]models.OpeningHours, error)
+}
+
+type OrganizationRepository interface {
+	Create(ctx context.Context, organization models.Organization) (models.Organization, error)
+	GetAll(ctx context.Context) []models.Organization
+	GetOrganizationById(ctx context.Context, organizationId int) (models.Organization, error)
+	Update(ctx context.Context, organization models.Organization) (models.Organization, error)
+	Delete(ctx context.Context, organizationId int) (bool, error)
+}
+
+type RoomShareRepository interface {
+	Create(ctx context.Context, roomShare models.RoomShare) (models.RoomShare, error)
+	GetRoomSharesByRoomId(ctx context.Context, roomId int) ([]models.RoomShare, error)
+	GetRoomShare(ctx context.Context, roomId int, organizationId int) (models.RoomShare, error)
+	GetBookedMinutes(ctx context.Context, roomId int, organizationId int, from time.Time, to time.Time) (int, error)
+	Delete(ctx context.Context, roomId int, organizationId int) (bool, error)
+}
+
+type AttendeeRepository interface {
+	CreateAttendees(ctx context.Context, attendees []models.Attendee) ([]models.Attendee, error)
+	GetAttendeeById(ctx context.Context, attendeeId int) (models.Attendee, error)
+	GetAttendeesByBookingId(ctx context.Context, bookingId int) ([]models.Attendee, error)
+	GetAttendeeByBookingIdAndUserId(ctx context.Context, bookingId int, userId int) (models.Attendee, error)
+	CountAttendingByBookingId(ctx context.Context, bookingId int) (int, error)
+	GetBookingsByAttendeeUserId(ctx context.Context, userId int) ([]models.Booking, error)
+	UpdateStatus(ctx context.Context, attendee models.Attendee) (models.Attendee, error)
+	Delete(ctx context.Context, attendeeId int) (bool, error)
+	GetVisitorPasses(ctx context.Context, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.VisitorPass, error)
+	GetVisitorPassByCode(ctx context.Context, passCode string) (models.VisitorPass, error)
+}
+
+type ReportRepository interface {
+	GetUtilization(ctx context.Context, filter models.ReportFilter) ([]models.RoomUtilization, error)
+	GetPeakHours(ctx context.Context, filter models.ReportFilter) ([]models.PeakHour, error)
+	GetBookingStats(ctx context.Context, filter models.ReportFilter, now time.Time) ([]models.RoomBookingStats, error)
+	GetTopBookers(ctx context.Context, filter models.ReportFilter) ([]models.TopBooker, error)
+}
+
+type RecoveryCodeRepository interface {
+	ReplaceRecoveryCodes(ctx context.Context, userId int, recoveryCodes []models.RecoveryCode) error
+	UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error)
+}
+
+type MFAPolicyRepository interface {
+	GetMFAPolicies(ctx context.Context) ([]models.MFAPolicy, error)
+	IsMFARequired(ctx context.Context, roleId int) (bool, error)
+	ReplaceMFAPolicies(ctx context.Context, policies []models.MFAPolicy) ([]models.MFAPolicy, error)
 }
@@ -1,7 +1,7 @@
+DROP TABLE permissions CASCADE;
+DROP TABLE routes CASCADE;
+DROP TABLE scopes CASCADE;
+DROP TABLE bookings CASCADE;
 DROP TABLE rooms CASCADE;
-DROP TABLE roles CASCADE;
 DROP TABLE users CASCADE;
-DROP TABLE bookings CASCADE;
-DROP TABLE scopes CASCADE;
-DROP TABLE routes CASCADE;
-DROP TABLE permissions CASCADE;
\ No newline at end of file
+DROP TABLE roles CASCADE;
@@ -0,0 +1,254 @@
+CREATE TABLE roles (
+    role_id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+
+    active BOOL DEFAULT true,
+    created_by BIGINT, -- NULL - created by migration. References users - added below
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE users (
+    user_id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    role_id INT REFERENCES roles,
+    email TEXT NOT NULL UNIQUE,
+    telephone TEXT NOT NULL UNIQUE,
+
+    username TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+
+    active BOOL DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE rooms (
+    room_id SERIAL PRIMARY KEY,
+    number TEXT NOT NULL UNIQUE,
+    capacity INT NOT NULL,
+
+    active BOOL DEFAULT true,
+    created_by BIGSERIAL NOT NULL REFERENCES users,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE bookings (
+    booking_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users,
+    room_id INT NOT NULL REFERENCES rooms,
+    datetime_start TIMESTAMPTZ NOT NULL,
+    datetime_end TIMESTAMPTZ NOT NULL CHECK ( datetime_start < datetime_end ),
+
+    active BOOL DEFAULT true,
+    created_by BIGSERIAL NOT NULL REFERENCES users,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+ALTER TABLE roles
+    ADD FOREIGN KEY (created_by) REFERENCES users;
+
+CREATE TABLE scopes
+(
+    scope_id   BIGSERIAL PRIMARY KEY,
+    name        TEXT NOT NULL UNIQUE,
+    description TEXT DEFAULT '',
+
+    active BOOL DEFAULT true,
+    created_by BIGINT REFERENCES users, -- NULL - created by migration
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE routes
+(
+    route_id    BIGSERIAL PRIMARY KEY,
+    url         TEXT NOT NULL,
+    description TEXT DEFAULT '',
+
+    active BOOL DEFAULT true,
+    created_by BIGINT REFERENCES users, -- NULL - created by migration
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE permissions
+(
+    role_id  BIGINT REFERENCES roles, -- for each role we specify chosen routes
+    route_id BIGINT REFERENCES routes, -- one-to-many relationship
+    scope_id INT REFERENCES scopes,
+
+    active BOOL DEFAULT true,
+    created_by BIGINT REFERENCES users, -- NULL - created by migration
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+-- reference data: roles, scopes, routes and permissions are required by authorization
+INSERT INTO roles (role_id, name, description)
+VALUES (1, 'Super Admin', 'Super Admin: all rights to all Entities'),
+       (2, 'Content Manager', 'All rights over Rooms and Bookings'),
+       (3, 'HR', 'Human Resources - all rights over Users'),
+       (4, 'Event Planner', 'Schedules all bookings. Has all rights over all bookings'),
+       (5, 'User', 'Regular user - create booking and change it''s own bookings');
+SELECT setval('roles_role_id_seq', (SELECT max(role_id) FROM roles));
+
+INSERT INTO scopes (scope_id, name, description)
+VALUES (1, 'ALL', 'Read/Update/Delete All records'),
+       (2, 'OWNER', 'Read/Update/Delete Only owned records by user');
+SELECT setval('scopes_scope_id_seq', (SELECT max(scope_id) FROM scopes));
+
+INSERT INTO routes (route_id, url, description)
+VALUES (1, '/auth/register', 'Register new User. All unathorized users can do that.'),
+       (2, '/auth/login', 'Log in as a registered User. All unathorized users can do that.'),
+       (3, '/auth/refresh', 'Refresh access&refresh token.'),
+
+       (4, '/user/', 'Get User by id'),
+       (5, '/user/all', 'Get all Users'),
+       (6, '/user/update', 'Update User by Id'),
+       (7, '/user/drop', 'Delete User by Id'),
+
+       (8, '/room/', 'Get Room by id'),
+       (9, '/room/create', 'Create Room'),
+       (10, '/room/all', 'Get all Rooms'),
+       (11, '/room/update', 'Update Room by Id'),
+       (12, '/room/drop', 'Delete Room by Id'),
+
+       (13, '/booking/', 'Get Booking by id'),
+       (14, '/booking/all', 'Get all Bookings'),
+       (15, '/booking/room', 'Get Bookings by RoomId'),
+       (16, '/booking/room_time', 'Get Bookings by RoomId and Booking time range'),
+       (17, '/booking/drop', 'Delete booking by id'),
+       (18, '/booking/available/room', 'Check if room available for Booking by RoomId and Booking time range'),
+       (19, '/booking/create', 'Book Room (create booking)'),
+       (20, '/booking/overlapping', 'Get overlapping Bookings'),
+       (21, '/booking/update', 'Update Booking by Id');
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
+
+INSERT INTO permissions (role_id, route_id, scope_id)
+VALUES
+--     SUPER ADMIN - all permissions
+    -- AUTH
+    (1, 1, 1), -- Register new User
+    (1, 2, 1), -- Log in as a registered User
+    (1, 3, 1), -- Refresh access&refresh token
+    -- USERS
+    (1, 4, 1), -- Get User by id
+    (1, 5, 1), -- Get all Users
+    (1, 6, 1), -- Update User by Id
+    (1, 7, 1), -- Delete User by Id
+    -- ROOMS
+    (1, 8, 1), -- Get Room by id
+    (1, 9, 1), -- Create Room
+    (1, 10, 1), -- Get all Rooms
+    (1, 11, 1), -- Update Room by Id
+    (1, 12, 1), -- Delete Room by Id
+    -- BOOKINGS
+    (1, 13, 1), -- Get Booking by id
+    (1, 14, 1), -- Get all Bookings
+    (1, 15, 1), -- Get Bookings by RoomId
+    (1, 16, 1), -- Get Bookings by RoomId and Booking time range
+    (1, 17, 1), -- Delete booking by id
+    (1, 18, 1), -- Check if room available for Booking by RoomId and Booking time range
+    (1, 19, 1), -- Book Room (create booking)
+    (1, 20, 1), -- Get overlapping Bookings
+    (1, 21, 1), -- Update Booking by Id
+
+--      CONTENT MANAGER
+    -- AUTH
+    (2, 3, 1), -- Refresh access&refresh token
+    -- USERS
+    (2, 4, 1), -- Get User by id
+    (2, 5, 1), -- Get all Users
+    (2, 6, 2), -- Update User by Id (only himself)
+    -- ROOMS - ADMIN
+    (2, 8, 1), -- Get Room by id
+    (2, 9, 1), -- Create Room
+    (2, 10, 1), -- Get all Rooms
+    (2, 11, 1), -- Update Room by Id
+    (2, 12, 1), -- Delete Room by Id
+    -- BOOKINGS - ADMIN
+    (2, 13, 1), -- Get Booking by id
+    (2, 14, 1), -- Get all Bookings
+    (2, 15, 1), -- Get Bookings by RoomId
+    (2, 16, 1), -- Get Bookings by RoomId and Booking time range
+    (2, 17, 1), -- Delete booking by id
+    (2, 18, 1), -- Check if room available for Booking by RoomId and Booking time range
+    (2, 19, 1), -- Book Room (create booking)
+    (2, 20, 1), -- Get overlapping Bookings
+    (2, 21, 1), -- Update Booking by Id
+
+--      HR - Human Resources
+    -- AUTH
+    (3, 1, 1), -- Register new User
+    (3, 3, 1), -- Refresh access&refresh token
+    -- USERS - ADMIN
+    (3, 4, 1), -- Get User by id
+    (3, 5, 1), -- Get all Users
+    (3, 6, 1), -- Update User by Id
+    (3, 7, 1), -- Delete User by Id
+    -- ROOMS
+    (3, 8, 1), -- get room by id
+    (3, 10, 1), -- get all rooms
+    -- BOOKINGS
+    (3, 13, 1), -- Get Booking by id
+    (3, 14, 1), -- Get all Bookings
+    (3, 15, 1), -- Get Bookings by RoomId
+    (3, 16, 1), -- Get Bookings by RoomId and Booking time range
+    (3, 17, 2), -- Delete booking by id (only created by himself)
+    (3, 18, 1), -- Check if room available for Booking by RoomId and Booking time range
+    (3, 19, 1), -- Book Room (create booking)
+    (3, 20, 1), -- Get overlapping Bookings
+    (3, 21, 2), -- Update Booking by Id (only created by himself)
+
+--     EVENT PLANNER
+    -- AUTH
+    (4, 3, 1), -- Refresh access&refresh token
+    -- USERS
+    (4, 4, 1), -- Get User by id
+    (4, 5, 1), -- Get all Users
+    (4, 6, 2), -- Update User by Id (only created by himself)
+    -- ROOMS
+    (4, 8, 1), -- Get Room by id
+    (4, 10, 1), -- Get all Rooms
+    -- BOOKINGS - ADMIN
+    (4, 13, 1), -- Get Booking by id
+    (4, 14, 1), -- Get all Bookings
+    (4, 15, 1), -- Get Bookings by RoomId
+    (4, 16, 1), -- Get Bookings by RoomId and Booking time range
+    (4, 17, 1), -- Delete booking by id
+    (4, 18, 1), -- Check if room available for Booking by RoomId and Booking time range
+    (4, 19, 1), -- Book Room (create booking)
+    (4, 20, 1), -- Get overlapping Bookings
+    (4, 21, 1), -- Update Booking by Id
+
+--     USER
+    -- AUTH
+    (5, 3, 1), -- Refresh access&refresh token
+    -- USERS
+    (5, 4, 1), -- Get User by id
+    (5, 5, 1), -- Get all Users
+    (5, 6, 2), -- Update User by Id (only himself)
+    -- ROOMS
+    (5, 8, 1), -- Get Room by id
+    (5, 10, 1), -- Get all Rooms
+    -- BOOKINGS
+    (5, 13, 1), -- Get Booking by id
+    (5, 14, 1), -- Get all Bookings
+    (5, 15, 1), -- Get Bookings by RoomId
+    (5, 16, 1), -- Get Bookings by RoomId and Booking time range
+    (5, 17, 2), -- Delete booking by id  (only created by himself)
+    (5, 18, 1), -- Check if room available for Booking by RoomId and Booking time range
+    (5, 19, 1), -- Book Room (create booking)
+    (5, 20, 1), -- Get overlapping Bookings
+    (5, 21, 2); -- Update Booking by Id (only created by himself)
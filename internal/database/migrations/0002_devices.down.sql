@@ -0,0 +1,8 @@
+DROP TABLE devices CASCADE;
+
+ALTER TABLE bookings
+    DROP COLUMN checked_in_at;
+
+DELETE FROM permissions WHERE route_id BETWEEN 22 AND 27 OR role_id = 6;
+DELETE FROM routes WHERE route_id BETWEEN 22 AND 27;
+DELETE FROM roles WHERE role_id = 6;
@@ -0,0 +1,50 @@
+CREATE TABLE devices (
+    device_id SERIAL PRIMARY KEY,
+    room_id INT NOT NULL REFERENCES rooms,
+    role_id INT NOT NULL REFERENCES roles,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL,
+
+    active BOOL DEFAULT true,
+    created_by BIGINT NOT NULL REFERENCES users,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+ALTER TABLE bookings
+    ADD COLUMN checked_in_at TIMESTAMPTZ;
+
+INSERT INTO roles (role_id, name, description)
+VALUES (6, 'Display Device', 'Room display (tablet) - sees room schedule, books room now and checks in');
+
+INSERT INTO routes (route_id, url, description)
+VALUES (22, '/device/register', 'Register room display device'),
+       (23, '/device/all', 'Get all devices'),
+       (24, '/device/drop', 'Revoke device by id'),
+
+       (25, '/display/{room_id}', 'Get current and next bookings of the room'),
+       (26, '/display/{room_id}/book-now', 'Book room starting from now'),
+       (27, '/display/{room_id}/check-in', 'Check in current booking of the room');
+
+INSERT INTO permissions (role_id, route_id, scope_id)
+VALUES
+--     SUPER ADMIN
+    (1, 22, 1), -- Register room display device
+    (1, 23, 1), -- Get all devices
+    (1, 24, 1), -- Revoke device by id
+    (1, 25, 1), -- Get current and next bookings of the room
+
+--     CONTENT MANAGER
+    (2, 22, 1), -- Register room display device
+    (2, 23, 1), -- Get all devices
+    (2, 24, 1), -- Revoke device by id
+    (2, 25, 1), -- Get current and next bookings of the room
+
+--     DISPLAY DEVICE - only display of the room it is registered for
+    (6, 25, 1), -- Get current and next bookings of the room
+    (6, 26, 1), -- Book room starting from now
+    (6, 27, 1); -- Check in current booking of the room
+
+SELECT setval('roles_role_id_seq', (SELECT max(role_id) FROM roles));
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
@@ -0,0 +1,12 @@
+DELETE FROM permissions WHERE route_id BETWEEN 28 AND 44;
+DELETE FROM routes WHERE route_id BETWEEN 28 AND 44;
+
+ALTER TABLE permissions
+    DROP COLUMN location_id;
+
+ALTER TABLE rooms
+    DROP COLUMN floor_id;
+
+DROP TABLE floors CASCADE;
+DROP TABLE buildings CASCADE;
+DROP TABLE locations CASCADE;
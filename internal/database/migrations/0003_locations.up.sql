@@ -0,0 +1,102 @@
+CREATE TABLE locations (
+    location_id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    address TEXT DEFAULT '',
+    time_zone TEXT NOT NULL, -- IANA time zone name
+
+    active BOOL DEFAULT true,
+    created_by BIGINT NOT NULL REFERENCES users,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE buildings (
+    building_id SERIAL PRIMARY KEY,
+    location_id INT NOT NULL REFERENCES locations,
+    name TEXT NOT NULL,
+    address TEXT DEFAULT '',
+
+    active BOOL DEFAULT true,
+    created_by BIGINT NOT NULL REFERENCES users,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE floors (
+    floor_id SERIAL PRIMARY KEY,
+    building_id INT NOT NULL REFERENCES buildings,
+    name TEXT NOT NULL,
+    level INT NOT NULL DEFAULT 0,
+
+    active BOOL DEFAULT true,
+    created_by BIGINT NOT NULL REFERENCES users,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+ALTER TABLE rooms
+    ADD COLUMN floor_id INT REFERENCES floors;
+
+-- rooms created before locations are moved to a default floor, otherwise they have no location, time zone and opening
+-- hours. Databases without rooms (new installations - seeds create their own locations) get nothing
+WITH default_location AS (
+    INSERT INTO locations (name, time_zone, created_by)
+    SELECT 'Default location', 'UTC', min(created_by) FROM rooms WHERE floor_id IS NULL HAVING count(*) > 0
+    RETURNING location_id, created_by
+), default_building AS (
+    INSERT INTO buildings (location_id, name, created_by)
+    SELECT location_id, 'Default building', created_by FROM default_location
+    RETURNING building_id, created_by
+), default_floor AS (
+    INSERT INTO floors (building_id, name, level, created_by)
+    SELECT building_id, 'Default floor', 0, created_by FROM default_building
+    RETURNING floor_id
+)
+UPDATE rooms SET floor_id = (SELECT floor_id FROM default_floor)
+WHERE floor_id IS NULL;
+
+ALTER TABLE permissions
+    ADD COLUMN location_id INT REFERENCES locations; -- NULL - permission is valid in all locations
+
+INSERT INTO routes (route_id, url, description)
+VALUES (28, '/location/create', 'Create Location'),
+       (29, '/location/all', 'Get all Locations'),
+       (30, '/location/', 'Get Location by id'),
+       (31, '/location/update', 'Update Location by Id'),
+       (32, '/location/drop', 'Delete Location by Id'),
+       (33, '/location/rooms', 'Get Rooms by LocationId'),
+       (34, '/location/available', 'Get Rooms of Location available in Booking time range'),
+
+       (35, '/building/create', 'Create Building'),
+       (36, '/building/all', 'Get all Buildings'),
+       (37, '/building/', 'Get Building by id'),
+       (38, '/building/update', 'Update Building by Id'),
+       (39, '/building/drop', 'Delete Building by Id'),
+
+       (40, '/floor/create', 'Create Floor'),
+       (41, '/floor/all', 'Get all Floors'),
+       (42, '/floor/', 'Get Floor by id'),
+       (43, '/floor/update', 'Update Floor by Id'),
+       (44, '/floor/drop', 'Delete Floor by Id');
+
+INSERT INTO permissions (role_id, route_id, scope_id)
+VALUES
+--     SUPER ADMIN and CONTENT MANAGER - all rights over locations, buildings and floors
+    (1, 28, 1), (1, 29, 1), (1, 30, 1), (1, 31, 1), (1, 32, 1), (1, 33, 1), (1, 34, 1),
+    (1, 35, 1), (1, 36, 1), (1, 37, 1), (1, 38, 1), (1, 39, 1),
+    (1, 40, 1), (1, 41, 1), (1, 42, 1), (1, 43, 1), (1, 44, 1),
+    (2, 28, 1), (2, 29, 1), (2, 30, 1), (2, 31, 1), (2, 32, 1), (2, 33, 1), (2, 34, 1),
+    (2, 35, 1), (2, 36, 1), (2, 37, 1), (2, 38, 1), (2, 39, 1),
+    (2, 40, 1), (2, 41, 1), (2, 42, 1), (2, 43, 1), (2, 44, 1),
+--     HR, EVENT PLANNER, USER - read only
+    (3, 29, 1), (3, 30, 1), (3, 33, 1), (3, 34, 1), (3, 36, 1), (3, 37, 1), (3, 41, 1), (3, 42, 1),
+    (4, 29, 1), (4, 30, 1), (4, 33, 1), (4, 34, 1), (4, 36, 1), (4, 37, 1), (4, 41, 1), (4, 42, 1),
+    (5, 29, 1), (5, 30, 1), (5, 33, 1), (5, 34, 1), (5, 36, 1), (5, 37, 1), (5, 41, 1), (5, 42, 1);
+
+-- Example of location-scoped permission: Event Planner of the second office can update bookings only of its rooms
+-- INSERT INTO permissions (role_id, route_id, scope_id, location_id) VALUES (4, 21, 1, 2);
+
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
@@ -0,0 +1,7 @@
+DELETE FROM permissions WHERE route_id IN (45, 46);
+DELETE FROM routes WHERE route_id IN (45, 46);
+
+DROP TABLE opening_hours CASCADE;
+
+ALTER TABLE users
+    DROP COLUMN time_zone;
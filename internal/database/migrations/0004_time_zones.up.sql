@@ -0,0 +1,23 @@
+ALTER TABLE users
+    ADD COLUMN time_zone TEXT NOT NULL DEFAULT ''; -- empty - time zone of the room is used
+
+CREATE TABLE opening_hours (
+    location_id INT NOT NULL REFERENCES locations,
+    weekday INT NOT NULL CHECK ( weekday BETWEEN 0 AND 6 ), -- 0 - Sunday
+    opens_at TEXT NOT NULL CHECK ( opens_at ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$' ), -- local time of the location
+    closes_at TEXT NOT NULL CHECK ( closes_at ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$' OR closes_at = '24:00' ),
+    CHECK ( opens_at < closes_at )
+);
+
+INSERT INTO routes (route_id, url, description)
+VALUES (45, '/location/opening-hours', 'Get opening hours of Location'),
+       (46, '/location/opening-hours/update', 'Replace opening hours of Location');
+
+INSERT INTO permissions (role_id, route_id, scope_id)
+VALUES (1, 45, 1), (1, 46, 1), -- SUPER ADMIN
+       (2, 45, 1), (2, 46, 1), -- CONTENT MANAGER
+       (3, 45, 1), -- HR
+       (4, 45, 1), -- EVENT PLANNER
+       (5, 45, 1); -- USER
+
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
@@ -0,0 +1,27 @@
+DELETE FROM permissions WHERE route_id BETWEEN 47 AND 54;
+DELETE FROM routes WHERE route_id BETWEEN 47 AND 54;
+
+DROP TABLE room_shares CASCADE;
+
+DROP INDEX locations_organization_id_name_idx;
+ALTER TABLE locations ADD CONSTRAINT locations_name_key UNIQUE (name);
+
+ALTER TABLE floors
+    DROP COLUMN organization_id;
+ALTER TABLE buildings
+    DROP COLUMN organization_id;
+ALTER TABLE locations
+    DROP COLUMN organization_id;
+
+ALTER TABLE roles
+    DROP COLUMN organization_id;
+ALTER TABLE devices
+    DROP COLUMN organization_id;
+ALTER TABLE bookings
+    DROP COLUMN organization_id;
+ALTER TABLE rooms
+    DROP COLUMN organization_id;
+ALTER TABLE users
+    DROP COLUMN organization_id;
+
+DROP TABLE organizations CASCADE;
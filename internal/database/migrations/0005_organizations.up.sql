@@ -0,0 +1,89 @@
+CREATE TABLE organizations (
+    organization_id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+
+    active BOOL DEFAULT true,
+    created_by BIGINT NOT NULL, -- no reference: users belong to organizations
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+-- host organization (business center) - all existing records are moved to it. created_by = 0 - created by migration
+INSERT INTO organizations (organization_id, name, created_by)
+VALUES (1, 'Host organization', 0);
+SELECT setval('organizations_organization_id_seq', (SELECT max(organization_id) FROM organizations));
+
+ALTER TABLE users
+    ADD COLUMN organization_id INT NOT NULL DEFAULT 1 REFERENCES organizations;
+ALTER TABLE rooms
+    ADD COLUMN organization_id INT NOT NULL DEFAULT 1 REFERENCES organizations; -- owner of the room
+ALTER TABLE bookings
+    ADD COLUMN organization_id INT NOT NULL DEFAULT 1 REFERENCES organizations; -- organization of the booker
+ALTER TABLE devices
+    ADD COLUMN organization_id INT NOT NULL DEFAULT 1 REFERENCES organizations;
+ALTER TABLE roles
+    ADD COLUMN organization_id INT REFERENCES organizations; -- NULL - system role available in all organizations
+ALTER TABLE locations
+    ADD COLUMN organization_id INT NOT NULL DEFAULT 1 REFERENCES organizations; -- buildings and floors follow their location
+ALTER TABLE buildings
+    ADD COLUMN organization_id INT NOT NULL DEFAULT 1 REFERENCES organizations;
+ALTER TABLE floors
+    ADD COLUMN organization_id INT NOT NULL DEFAULT 1 REFERENCES organizations;
+
+-- organization is always set by the application
+ALTER TABLE users ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE rooms ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE bookings ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE devices ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE locations ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE buildings ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE floors ALTER COLUMN organization_id DROP DEFAULT;
+
+CREATE INDEX users_organization_id_idx ON users (organization_id);
+CREATE INDEX rooms_organization_id_idx ON rooms (organization_id);
+CREATE INDEX bookings_organization_id_idx ON bookings (organization_id);
+CREATE INDEX locations_organization_id_idx ON locations (organization_id);
+CREATE INDEX buildings_organization_id_idx ON buildings (organization_id);
+CREATE INDEX floors_organization_id_idx ON floors (organization_id);
+
+-- names of locations are unique within the organization
+ALTER TABLE locations DROP CONSTRAINT locations_name_key;
+CREATE UNIQUE INDEX locations_organization_id_name_idx ON locations (organization_id, name);
+
+CREATE TABLE room_shares (
+    room_id INT NOT NULL REFERENCES rooms,
+    organization_id INT NOT NULL REFERENCES organizations, -- organization room is shared with
+    quota_minutes INT NOT NULL DEFAULT 0 CHECK ( quota_minutes >= 0 ), -- per calendar month, 0 - unlimited
+
+    active BOOL DEFAULT true,
+    created_by BIGINT NOT NULL REFERENCES users,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+-- only one active share of the room per organization
+CREATE UNIQUE INDEX room_shares_active_idx ON room_shares (room_id, organization_id) WHERE active;
+
+INSERT INTO routes (route_id, url, description)
+VALUES (47, '/organization/create', 'Create Organization'),
+       (48, '/organization/all', 'Get all Organizations'),
+       (49, '/organization/', 'Get Organization by id'),
+       (50, '/organization/update', 'Update Organization by Id'),
+       (51, '/organization/drop', 'Delete Organization by Id'),
+
+       (52, '/room/share', 'Share Room with another Organization'),
+       (53, '/room/shares', 'Get Organizations Room is shared with'),
+       (54, '/room/unshare', 'Stop sharing Room with Organization');
+
+-- only Super Admins of the host organization manage other organizations - enforced by OrganizationService
+INSERT INTO permissions (role_id, route_id, scope_id)
+VALUES (1, 47, 1), (1, 48, 1), (1, 49, 1), (1, 50, 1), (1, 51, 1), -- SUPER ADMIN
+       (1, 52, 1), (1, 53, 1), (1, 54, 1),
+       (2, 49, 1), (2, 52, 1), (2, 53, 1), (2, 54, 1), -- CONTENT MANAGER
+       (3, 49, 1), -- HR
+       (4, 49, 1), -- EVENT PLANNER
+       (5, 49, 1); -- USER
+
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
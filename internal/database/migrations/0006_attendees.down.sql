@@ -0,0 +1,6 @@
+DELETE FROM permissions WHERE route_id BETWEEN 55 AND 61;
+DELETE FROM routes WHERE route_id BETWEEN 55 AND 61;
+DELETE FROM permissions WHERE scope_id = 3;
+DELETE FROM scopes WHERE scope_id = 3;
+
+DROP TABLE attendees CASCADE;
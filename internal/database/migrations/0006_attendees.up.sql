@@ -0,0 +1,45 @@
+CREATE TABLE attendees (
+    attendee_id SERIAL PRIMARY KEY,
+    organization_id INT NOT NULL REFERENCES organizations,
+    booking_id INT NOT NULL REFERENCES bookings,
+    user_id BIGINT REFERENCES users, -- NULL - external guest
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK ( status IN ('pending', 'accepted', 'declined') ),
+    pass_code TEXT UNIQUE, -- visitor pass of external guest
+    responded_at TIMESTAMPTZ,
+
+    active BOOL DEFAULT true,
+    created_by BIGINT NOT NULL REFERENCES users,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+
+    CHECK ( user_id IS NOT NULL OR pass_code IS NOT NULL )
+);
+
+CREATE INDEX attendees_booking_id_idx ON attendees (booking_id);
+CREATE INDEX attendees_user_id_idx ON attendees (user_id);
+
+INSERT INTO scopes (scope_id, name, description)
+VALUES (3, 'ATTENDEE', 'Read only records owned by user and bookings user is invited to');
+
+INSERT INTO routes (route_id, url, description)
+VALUES (55, '/booking/attendees', 'Get Attendees of Booking'),
+       (56, '/booking/attendees/add', 'Invite users and external guests to Booking'),
+       (57, '/booking/attendees/drop', 'Remove Attendee from Booking'),
+       (58, '/booking/respond', 'Accept or decline invitation to Booking'),
+       (59, '/booking/invitations', 'Get Bookings current User is invited to'),
+
+       (60, '/reception/passes', 'Get visitor passes of guests expected in time range'),
+       (61, '/reception/pass', 'Get visitor pass by pass code');
+
+INSERT INTO permissions (role_id, route_id, scope_id)
+VALUES (1, 55, 1), (1, 56, 1), (1, 57, 1), (1, 58, 3), (1, 59, 1), (1, 60, 1), (1, 61, 1), -- SUPER ADMIN
+       (2, 55, 1), (2, 56, 1), (2, 57, 1), (2, 58, 3), (2, 59, 1), (2, 60, 1), (2, 61, 1), -- CONTENT MANAGER
+       (3, 55, 1), (3, 56, 2), (3, 57, 2), (3, 58, 3), (3, 59, 1), (3, 60, 1), (3, 61, 1), -- HR (reception)
+       (4, 55, 1), (4, 56, 1), (4, 57, 1), (4, 58, 3), (4, 59, 1), -- EVENT PLANNER
+       (5, 55, 3), (5, 56, 2), (5, 57, 2), (5, 58, 3), (5, 59, 1); -- USER
+
+SELECT setval('scopes_scope_id_seq', (SELECT max(scope_id) FROM scopes));
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
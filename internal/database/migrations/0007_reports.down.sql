@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE route_id BETWEEN 62 AND 65;
+DELETE FROM routes WHERE route_id BETWEEN 62 AND 65;
@@ -0,0 +1,12 @@
+INSERT INTO routes (route_id, url, description)
+VALUES (62, '/report/utilization', 'Utilization of rooms within opening hours per day/week/month'),
+       (63, '/report/peak-hours', 'Peak hours heatmap of bookings'),
+       (64, '/report/bookings', 'Average duration, cancellation and no-show rates of bookings'),
+       (65, '/report/top-bookers', 'Users who book rooms the most');
+
+INSERT INTO permissions (role_id, route_id, scope_id)
+VALUES (1, 62, 1), (1, 63, 1), (1, 64, 1), (1, 65, 1), -- SUPER ADMIN
+       (2, 62, 1), (2, 63, 1), (2, 64, 1), (2, 65, 1), -- CONTENT MANAGER (facilities)
+       (4, 62, 1), (4, 63, 1); -- EVENT PLANNER
+
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE route_id BETWEEN 66 AND 69;
+DELETE FROM routes WHERE route_id BETWEEN 66 AND 69;
@@ -0,0 +1,12 @@
+INSERT INTO routes (route_id, url, description)
+VALUES (66, '/user/import', 'Import Users from CSV/XLSX file'),
+       (67, '/user/export', 'Export Users to CSV/XLSX file'),
+       (68, '/room/import', 'Import Rooms from CSV/XLSX file'),
+       (69, '/room/export', 'Export Rooms to CSV/XLSX file');
+
+INSERT INTO permissions (role_id, route_id, scope_id)
+VALUES (1, 66, 1), (1, 67, 1), (1, 68, 1), (1, 69, 1), -- SUPER ADMIN
+       (2, 68, 1), (2, 69, 1), -- CONTENT MANAGER
+       (3, 66, 1), (3, 67, 1); -- HR
+
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
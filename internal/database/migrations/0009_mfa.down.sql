@@ -0,0 +1,13 @@
+DELETE FROM permissions WHERE route_id BETWEEN 70 AND 76;
+DELETE FROM routes WHERE route_id BETWEEN 70 AND 76;
+
+ALTER TABLE routes
+    DROP COLUMN mfa_required;
+
+DROP TABLE mfa_policies;
+DROP TABLE recovery_codes;
+
+ALTER TABLE users
+    DROP COLUMN totp_secret,
+    DROP COLUMN totp_enabled,
+    DROP COLUMN totp_last_step;
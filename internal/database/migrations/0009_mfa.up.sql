@@ -0,0 +1,47 @@
+-- TOTP (RFC 6238) second factor. Secret is set at enrollment and enabled after the first valid code
+ALTER TABLE users
+    ADD COLUMN totp_secret TEXT NOT NULL DEFAULT '',
+    ADD COLUMN totp_enabled BOOL NOT NULL DEFAULT false,
+    ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0; -- time step of the last accepted code - codes cannot be reused
+
+CREATE TABLE recovery_codes (
+    recovery_code_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp
+);
+
+CREATE INDEX recovery_codes_user_id_idx ON recovery_codes (user_id);
+
+-- roles which users of the organization should use two-factor authentication
+CREATE TABLE mfa_policies (
+    organization_id INT NOT NULL REFERENCES organizations,
+    role_id INT NOT NULL REFERENCES roles,
+
+    created_by BIGINT NOT NULL REFERENCES users,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    PRIMARY KEY (organization_id, role_id)
+);
+
+-- sensitive routes are available only with tokens issued after two-factor authentication
+ALTER TABLE routes
+    ADD COLUMN mfa_required BOOL NOT NULL DEFAULT false;
+
+INSERT INTO routes (route_id, url, description, mfa_required)
+VALUES (70, '/auth/login/mfa', 'Second step of log in: TOTP or recovery code', false),
+       (71, '/auth/mfa/enroll', 'Start TOTP enrollment: secret and otpauth URI', false),
+       (72, '/auth/mfa/confirm', 'Enable TOTP by the first code, get recovery codes', false),
+       (73, '/auth/mfa/disable', 'Disable TOTP', false),
+       (74, '/auth/mfa/recovery-codes', 'Replace recovery codes', false),
+       (75, '/organization/mfa-policy', 'Get roles required to use two-factor authentication', false),
+       (76, '/organization/mfa-policy/update', 'Replace roles required to use two-factor authentication', true);
+
+INSERT INTO permissions (role_id, route_id, scope_id)
+VALUES (1, 71, 1), (1, 72, 1), (1, 73, 1), (1, 74, 1), (1, 75, 1), (1, 76, 1), -- SUPER ADMIN
+       (2, 71, 1), (2, 72, 1), (2, 73, 1), (2, 74, 1), -- CONTENT MANAGER
+       (3, 71, 1), (3, 72, 1), (3, 73, 1), (3, 74, 1), (3, 75, 1), -- HR
+       (4, 71, 1), (4, 72, 1), (4, 73, 1), (4, 74, 1), -- EVENT PLANNER
+       (5, 71, 1), (5, 72, 1), (5, 73, 1), (5, 74, 1); -- USER
+
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
@@ -0,0 +1,12 @@
+DELETE FROM routes WHERE route_id BETWEEN 77 AND 78;
+
+DROP INDEX users_telephone_idx;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_telephone_key UNIQUE (telephone);
+
+DROP INDEX users_sso_subject_idx;
+
+ALTER TABLE users
+    DROP COLUMN sso_issuer,
+    DROP COLUMN sso_subject;
@@ -0,0 +1,18 @@
+-- users of the identity provider (OIDC single sign-on) are linked by issuer and `sub` claim
+ALTER TABLE users
+    ADD COLUMN sso_issuer TEXT NOT NULL DEFAULT '',
+    ADD COLUMN sso_subject TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX users_sso_subject_idx ON users (sso_issuer, sso_subject) WHERE sso_subject <> '';
+
+-- users provisioned by single sign-on may have no telephone
+ALTER TABLE users
+    DROP CONSTRAINT users_telephone_key;
+
+CREATE UNIQUE INDEX users_telephone_idx ON users (telephone) WHERE telephone <> '';
+
+INSERT INTO routes (route_id, url, description)
+VALUES (77, '/auth/oidc/login', 'Log in by identity provider (OIDC). All unathorized users can do that.'),
+       (78, '/auth/oidc/callback', 'Code of identity provider is exchanged for Access&Refresh tokens.');
+
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
@@ -0,0 +1,5 @@
+DROP INDEX users_directory_id_idx;
+
+ALTER TABLE users
+    DROP COLUMN auth_provider,
+    DROP COLUMN directory_id;
@@ -0,0 +1,6 @@
+-- users of LDAP / Active Directory: password is checked by the directory, the account is matched by stable id of the entry
+ALTER TABLE users
+    ADD COLUMN auth_provider TEXT NOT NULL DEFAULT 'local',
+    ADD COLUMN directory_id TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX users_directory_id_idx ON users (auth_provider, directory_id) WHERE directory_id <> '';
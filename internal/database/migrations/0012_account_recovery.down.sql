@@ -0,0 +1,6 @@
+DELETE FROM routes WHERE route_id BETWEEN 79 AND 82;
+
+DROP TABLE user_tokens;
+
+ALTER TABLE users
+    DROP COLUMN email_verified;
@@ -0,0 +1,24 @@
+-- existing users and users created by administrators are trusted, self-registered ones verify email by link
+ALTER TABLE users
+    ADD COLUMN email_verified BOOL NOT NULL DEFAULT true;
+
+-- single-use secrets sent by email: password reset and email verification. Only hashes are stored
+CREATE TABLE user_tokens (
+    user_token_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users,
+    purpose TEXT NOT NULL, -- password_reset, email_verification
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp
+);
+
+CREATE INDEX user_tokens_user_id_idx ON user_tokens (user_id, purpose);
+
+INSERT INTO routes (route_id, url, description)
+VALUES (79, '/auth/forgot-password', 'Send link of password reset to email. All unathorized users can do that.'),
+       (80, '/auth/reset-password', 'Set new password by token of the link. All unathorized users can do that.'),
+       (81, '/auth/verify-email', 'Confirm email by token of the link. All unathorized users can do that.'),
+       (82, '/auth/verify-email/resend', 'Send link of email verification again. All unathorized users can do that.');
+
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE route_id BETWEEN 83 AND 87;
+DELETE FROM routes WHERE route_id BETWEEN 83 AND 87;
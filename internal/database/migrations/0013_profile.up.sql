@@ -0,0 +1,15 @@
+INSERT INTO routes (route_id, url, description)
+VALUES (83, '/me', 'Get profile of the caller'),
+       (84, '/me/update', 'Update name, email, telephone and time zone of the caller'),
+       (85, '/me/password', 'Change password of the caller. Current password is required'),
+       (86, '/me/username', 'Change username of the caller. Password is required'),
+       (87, '/user/role', 'Change role of the user. Only administrators can do that');
+
+INSERT INTO permissions (role_id, route_id, scope_id)
+VALUES (1, 83, 1), (1, 84, 1), (1, 85, 1), (1, 86, 1), (1, 87, 1), -- SUPER ADMIN
+       (2, 83, 1), (2, 84, 1), (2, 85, 1), (2, 86, 1), -- CONTENT MANAGER
+       (3, 83, 1), (3, 84, 1), (3, 85, 1), (3, 86, 1), -- HR
+       (4, 83, 1), (4, 84, 1), (4, 85, 1), (4, 86, 1), -- EVENT PLANNER
+       (5, 83, 1), (5, 84, 1), (5, 85, 1), (5, 86, 1); -- USER
+
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
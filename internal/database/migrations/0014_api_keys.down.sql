@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE route_id BETWEEN 88 AND 92;
+DELETE FROM routes WHERE route_id BETWEEN 88 AND 92;
+
+DROP TABLE api_key_permissions;
+DROP TABLE api_keys;
@@ -0,0 +1,37 @@
+-- long-lived credentials of service accounts (users with auth_provider 'service_account'). Only hashes are stored
+CREATE TABLE api_keys (
+    api_key_id BIGSERIAL PRIMARY KEY,
+    organization_id INT NOT NULL REFERENCES organizations,
+    user_id BIGINT NOT NULL REFERENCES users,
+    name TEXT NOT NULL,
+    key_hash TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+
+    active BOOL DEFAULT true,
+    created_by BIGINT NOT NULL REFERENCES users,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX api_keys_user_id_idx ON api_keys (user_id);
+
+-- routes the key may call - the role of the service account should allow them too
+CREATE TABLE api_key_permissions (
+    api_key_id BIGINT NOT NULL REFERENCES api_keys ON DELETE CASCADE,
+    route_id INT NOT NULL REFERENCES routes,
+    scope_id INT NOT NULL REFERENCES scopes,
+    PRIMARY KEY (api_key_id, route_id)
+);
+
+INSERT INTO routes (route_id, url, description)
+VALUES (88, '/service-account/create', 'Create service account of integration. It logs in only by API keys'),
+       (89, '/service-account/all', 'Get all service accounts'),
+       (90, '/api-key/create', 'Issue API key of service account'),
+       (91, '/api-key/all', 'Get all API keys'),
+       (92, '/api-key/drop', 'Revoke API key by id');
+
+INSERT INTO permissions (role_id, route_id, scope_id)
+VALUES (1, 88, 1), (1, 89, 1), (1, 90, 1), (1, 91, 1), (1, 92, 1); -- SUPER ADMIN
+
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
@@ -0,0 +1,6 @@
+DELETE FROM permissions WHERE route_id BETWEEN 93 AND 97;
+DELETE FROM routes WHERE route_id BETWEEN 93 AND 97;
+
+DROP TABLE policies;
+
+ALTER TABLE users DROP COLUMN floor_id;
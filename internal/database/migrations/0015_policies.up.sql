@@ -0,0 +1,35 @@
+-- floor the user works at - attribute `subject.floor_id` of access policies
+ALTER TABLE users ADD COLUMN floor_id INT REFERENCES floors;
+
+-- attribute-based rules of access applied after permissions: `allow` grants access permissions do not grant,
+-- `deny` takes it away. Conditions are JSON array of {attribute, operator, value | value_attribute}
+CREATE TABLE policies (
+    policy_id SERIAL PRIMARY KEY,
+    organization_id INT NOT NULL REFERENCES organizations,
+    name TEXT NOT NULL,
+    description TEXT,
+    role_id INT REFERENCES roles, -- null - every role
+    route_id INT NOT NULL REFERENCES routes,
+    effect TEXT NOT NULL CHECK (effect IN ('allow', 'deny')),
+    conditions JSONB NOT NULL DEFAULT '[]',
+
+    active BOOL DEFAULT true,
+    created_by BIGINT NOT NULL REFERENCES users,
+    created_at TIMESTAMPTZ DEFAULT current_timestamp,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX policies_route_id_idx ON policies (route_id) WHERE active;
+
+INSERT INTO routes (route_id, url, description)
+VALUES (93, '/policy/create', 'Create attribute-based access policy of the route'),
+       (94, '/policy/all', 'Get all access policies'),
+       (95, '/policy/update', 'Update access policy by id'),
+       (96, '/policy/drop', 'Delete access policy by id'),
+       (97, '/policy/explain', 'Explain access of the user to the route - dry run of permissions and policies');
+
+INSERT INTO permissions (role_id, route_id, scope_id)
+VALUES (1, 93, 1), (1, 94, 1), (1, 95, 1), (1, 96, 1), (1, 97, 1); -- SUPER ADMIN
+
+SELECT setval('routes_route_id_seq', (SELECT max(route_id) FROM routes));
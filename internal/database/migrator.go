@@ -0,0 +1,332 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// migrationFiles schema changes and reference data (roles, scopes, routes, permissions): NNNN_name.up.sql and NNNN_name.down.sql
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// seedFiles demo data (users, offices, rooms, bookings): NNNN_name.sql. Applied on the latest schema only
+//
+//go:embed seeds/*.sql
+var seedFiles embed.FS
+
+// migrationLockId key of postgres advisory lock - only one instance applies migrations at a time
+const migrationLockId = 1_958_317
+
+var (
+	migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	seedFileName      = regexp.MustCompile(`^(\d+)_(\w+)\.sql$`)
+)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string // empty for seeds
+}
+
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Migrator applies embedded migrations and seeds. Applied versions are stored in `schema_migrations` and `schema_seeds` tables
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	seeds      []Migration
+}
+
+func NewMigrator(conn *gorm.DB) (*Migrator, error) {
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	seeds, err := LoadSeeds(seedFiles, "seeds")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: sqlDB, migrations: migrations, seeds: seeds}, nil
+}
+
+// LoadMigrations reads migrations of the directory ordered by version. Every migration should have up and down files
+func LoadMigrations(files fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrationsByVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file name should be NNNN_name.up.sql or NNNN_name.down.sql. Passed data: %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+
+		script, err := fs.ReadFile(files, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := migrationsByVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			migrationsByVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files with different names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(script)
+		} else {
+			migration.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(migrationsByVersion))
+	for _, migration := range migrationsByVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s should have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// LoadSeeds reads seeds of the directory ordered by version. Seeds cannot be rolled back
+func LoadSeeds(files fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	seeds := make([]Migration, 0, len(entries))
+	versions := make(map[int]bool, len(entries))
+	for _, entry := range entries {
+		match := seedFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("seed file name should be NNNN_name.sql. Passed data: %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if versions[version] {
+			return nil, fmt.Errorf("seed version %d is used twice", version)
+		}
+		versions[version] = true
+
+		script, err := fs.ReadFile(files, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		seeds = append(seeds, Migration{Version: version, Name: match[2], Up: string(script)})
+	}
+	sort.Slice(seeds, func(i, j int) bool { return seeds[i].Version < seeds[j].Version })
+
+	return seeds, nil
+}
+
+// Migrations embedded migrations ordered by version
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Up applies all pending migrations. Returns applied migrations
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.appliedVersions(ctx, "schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	appliedNow := make([]Migration, 0)
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := m.apply(ctx, "schema_migrations", migration, migration.Up, true); err != nil {
+			slog.ErrorContext(ctx, "Migrator.Up(): error occured during migration", "version", migration.Version, "name", migration.Name, "error", err)
+			return appliedNow, fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		slog.InfoContext(ctx, "Migrator.Up(): migration applied", "version", migration.Version, "name", migration.Name)
+		appliedNow = append(appliedNow, migration)
+	}
+
+	return appliedNow, nil
+}
+
+// Down rolls back last `steps` applied migrations. Returns rolled back migrations
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, errors.New("number of migrations to roll back should be positive")
+	}
+
+	applied, err := m.appliedVersions(ctx, "schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	rolledBack := make([]Migration, 0, steps)
+	for i := len(m.migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if err := m.apply(ctx, "schema_migrations", migration, migration.Down, false); err != nil {
+			slog.ErrorContext(ctx, "Migrator.Down(): error occured during rollback of migration", "version", migration.Version, "name", migration.Name, "error", err)
+			return rolledBack, fmt.Errorf("rollback of migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		slog.InfoContext(ctx, "Migrator.Down(): migration rolled back", "version", migration.Version, "name", migration.Name)
+		rolledBack = append(rolledBack, migration)
+	}
+
+	return rolledBack, nil
+}
+
+// Status every embedded migration with time it was applied at
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.appliedVersions(ctx, "schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.Applied, status.AppliedAt = true, &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Seed applies seeds which were not applied yet. Schema should be migrated to the latest version first
+func (m *Migrator) Seed(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if !status.Applied {
+			return nil, fmt.Errorf("migration %04d_%s is not applied - run `migrate up` first", status.Version, status.Name)
+		}
+	}
+
+	applied, err := m.appliedVersions(ctx, "schema_seeds")
+	if err != nil {
+		return nil, err
+	}
+
+	appliedNow := make([]Migration, 0)
+	for _, seed := range m.seeds {
+		if _, ok := applied[seed.Version]; ok {
+			continue
+		}
+
+		if err := m.apply(ctx, "schema_seeds", seed, seed.Up, true); err != nil {
+			slog.ErrorContext(ctx, "Migrator.Seed(): error occured during seeding", "version", seed.Version, "name", seed.Name, "error", err)
+			return appliedNow, fmt.Errorf("seed %04d_%s: %w", seed.Version, seed.Name, err)
+		}
+		slog.InfoContext(ctx, "Migrator.Seed(): seed applied", "version", seed.Version, "name", seed.Name)
+		appliedNow = append(appliedNow, seed)
+	}
+
+	return appliedNow, nil
+}
+
+// appliedVersions creates table of versions if it does not exist and returns applied versions with their time
+func (m *Migrator) appliedVersions(ctx context.Context, table string) (map[int]time.Time, error) {
+	_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+table+` (
+    version INT PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+)`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT version, applied_at FROM `+table+` ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// apply runs script and records (up) or removes (down) version in one transaction. Advisory lock makes concurrent
+// instances wait for each other, so version is checked again after the lock is taken
+func (m *Migrator) apply(ctx context.Context, table string, migration Migration, script string, up bool) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockId); err != nil {
+		return err
+	}
+
+	var isApplied bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE version = $1)`, migration.Version).Scan(&isApplied); err != nil {
+		return err
+	}
+	if isApplied == up {
+		// another instance has already done it
+		return nil
+	}
+
+	// script without arguments is sent with simple protocol - it may contain several statements
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, `INSERT INTO `+table+` (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE version = $1`, migration.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
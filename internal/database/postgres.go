@@ -1,34 +1,87 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"github.com/spf13/viper"
+	"go-booking-system/internal/metrics"
+	"go-booking-system/internal/tracing"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
-	"log"
-	"os"
+	"log/slog"
+	"strings"
+	"time"
 )
 
-func NewConnectPostgres() *gorm.DB {
-	host := viper.GetString("db.host")
-	port := viper.GetUint16("db.port")
-	username := viper.GetString("db.username")
-	password := os.Getenv("DB_PASSWORD")
-	dbName := viper.GetString("db.DBName")
+// Config of DB connection and connection pool. Password is set by environment variable
+type Config struct {
+	Host           string
+	Port           uint16
+	Username       string
+	Password       string
+	DBName         string
+	SSLMode        string
+	ConnectTimeout time.Duration
+	// AutoMigrate applies pending migrations at startup
+	AutoMigrate bool
 
-	dbParams := fmt.Sprintf("host=%s password=%s user=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Dushanbe",
-		host, password, username, dbName, port)
+	MaxOpenConns    int // 0 - unlimited
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration // 0 - connections are reused forever
+	ConnMaxIdleTime time.Duration
+}
+
+// NewConnectPostgres opens connection pool and checks that DB is reachable - application should not start without DB
+func NewConnectPostgres(config Config) (*gorm.DB, error) {
+	// session works in UTC - local time zones are taken from locations of rooms
+	dbParams := fmt.Sprintf("host=%s password=%s user=%s dbname=%s port=%d sslmode=%s TimeZone=UTC",
+		quoteParam(config.Host), quoteParam(config.Password), quoteParam(config.Username), quoteParam(config.DBName), config.Port, quoteParam(config.SSLMode))
 
 	postgresDialector := postgres.Open(dbParams)
 	connection, err := gorm.Open(postgresDialector, &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent)})
+		Logger:               logger.Default.LogMode(logger.Silent),
+		DisableAutomaticPing: true})
 
 	if err != nil {
-		log.Println("NewConnectPostgres(): error occurred")
-		return nil
+		slog.Error("NewConnectPostgres(): error occurred", "error", err)
+		return nil, err
+	}
+
+	sqlDB, err := connection.DB()
+	if err != nil {
+		slog.Error("NewConnectPostgres(): error occurred during getting connection pool", "error", err)
+		return nil, err
+	}
+
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.ConnectTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		slog.Error("NewConnectPostgres(): db is unreachable", "host", config.Host, "db_name", config.DBName, "error", err)
+		sqlDB.Close()
+		return nil, fmt.Errorf("db is unreachable: %w", err)
+	}
+
+	// query timings and connection pool statistics are exported to /metrics, every query gets its span
+	if err := connection.Use(metrics.GormPlugin{}); err != nil {
+		slog.Error("NewConnectPostgres(): error occurred during registration of metrics plugin", "error", err)
+	}
+	if err := connection.Use(tracing.GormPlugin{}); err != nil {
+		slog.Error("NewConnectPostgres(): error occurred during registration of tracing plugin", "error", err)
 	}
+	if err := metrics.RegisterDBStats(sqlDB); err != nil {
+		slog.Error("NewConnectPostgres(): error occurred during registration of DB statistics", "error", err)
+	}
+
+	slog.Info("NewConnectPostgres(): successful connection to db", "host", config.Host, "db_name", config.DBName)
+	return connection, nil
+}
 
-	log.Println("NewConnectPostgres(): successful connection to db")
-	return connection
+// quoteParam values of connection string may contain spaces and quotes (e.g. passwords)
+func quoteParam(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+type APIKeyRepository struct {
+	connection     *gorm.DB
+	organizationId int
+}
+
+func NewAPIKeyRepositoryPostgres(connection *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{connection: connection}
+}
+
+// ForOrganization returns repository which reads and writes only records of the organization
+func (a *APIKeyRepository) ForOrganization(organizationId int) *APIKeyRepository {
+	return &APIKeyRepository{connection: a.connection, organizationId: organizationId}
+}
+
+func (a *APIKeyRepository) scoped(ctx context.Context) *gorm.DB {
+	return a.connection.WithContext(ctx).Scopes(byOrganization("api_keys", a.organizationId))
+}
+
+// Create creates the key and its permissions in one transaction
+func (a *APIKeyRepository) Create(ctx context.Context, apiKey models.APIKey) (models.APIKey, error) {
+	if a.organizationId != SystemOrganizationId {
+		apiKey.OrganizationId = a.organizationId
+	}
+
+	err := a.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("organization_id", "user_id", "name", "key_hash", "expires_at", "active", "created_by").Create(&apiKey).Error; err != nil {
+			return err
+		}
+
+		for i := range apiKey.Permissions {
+			apiKey.Permissions[i].APIKeyId = apiKey.APIKeyId
+		}
+		if len(apiKey.Permissions) == 0 {
+			return nil
+		}
+
+		return tx.Create(&apiKey.Permissions).Error
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "APIKeyRepository.Create(): error occured during API Key creation", "user_id", apiKey.UserId, "name", apiKey.Name, "error", err)
+		return models.APIKey{}, err
+	}
+
+	return apiKey, nil
+}
+
+func (a *APIKeyRepository) GetAll(ctx context.Context) []models.APIKey {
+	var allAPIKeys []models.APIKey
+
+	a.scoped(ctx).Preload("Permissions").Order("api_key_id").Find(&allAPIKeys)
+
+	return allAPIKeys
+}
+
+func (a *APIKeyRepository) GetAPIKeyById(ctx context.Context, apiKeyId int) (models.APIKey, error) {
+	var foundAPIKey models.APIKey
+
+	result := a.scoped(ctx).Preload("Permissions").Find(&foundAPIKey, "api_key_id", apiKeyId)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "APIKeyRepository.GetAPIKeyById(): error occured during API Key search", "passed_data", apiKeyId, "error", err)
+		return models.APIKey{}, err
+	}
+
+	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
+		slog.WarnContext(ctx, "APIKeyRepository.GetAPIKeyById(): no API Keys were found", "passed_data", apiKeyId)
+		return models.APIKey{}, errors.New("no API Keys were found")
+	}
+
+	return foundAPIKey, nil
+}
+
+// UpdateLastUsed sets time of the last request made with the key
+func (a *APIKeyRepository) UpdateLastUsed(ctx context.Context, apiKeyId int, lastUsedAt time.Time) error {
+	result := a.scoped(ctx).
+		Model(&models.APIKey{}).
+		Where("api_key_id = ?", apiKeyId).
+		UpdateColumn("last_used_at", lastUsedAt)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "APIKeyRepository.UpdateLastUsed(): error occured during API Key update", "passed_data", apiKeyId, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Delete revokes the key
+func (a *APIKeyRepository) Delete(ctx context.Context, apiKeyId int) (bool, error) {
+	result := a.scoped(ctx).
+		Model(&models.APIKey{}).
+		Where(`api_key_id = ? AND "active"=?`, apiKeyId, true).
+		UpdateColumns(map[string]interface{}{"active": false, "deleted_at": time.Now()})
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "APIKeyRepository.Delete(): error occured during API Key revocation", "passed_data", apiKeyId, "error", err)
+		return false, err
+	}
+
+	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
+		slog.WarnContext(ctx, "APIKeyRepository.Delete(): no API Keys were revoked. Reason: API Key to revoke not found", "passed_data", apiKeyId)
+		return false, errors.New("no API Keys were revoked")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,239 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+type AttendeeRepository struct {
+	connection     *gorm.DB
+	organizationId int
+}
+
+func NewAttendeeRepositoryPostgres(connection *gorm.DB) *AttendeeRepository {
+	return &AttendeeRepository{connection: connection}
+}
+
+// ForOrganization returns repository which reads and writes only records of the organization
+func (a *AttendeeRepository) ForOrganization(organizationId int) *AttendeeRepository {
+	return &AttendeeRepository{connection: a.connection, organizationId: organizationId}
+}
+
+func (a *AttendeeRepository) scoped(ctx context.Context) *gorm.DB {
+	return a.connection.WithContext(ctx).Scopes(byOrganization("attendees", a.organizationId))
+}
+
+// CreateAttendees inserts all attendees in one statement - either all of them are invited or none
+func (a *AttendeeRepository) CreateAttendees(ctx context.Context, attendees []models.Attendee) ([]models.Attendee, error) {
+	for i := range attendees {
+		if a.organizationId != SystemOrganizationId {
+			attendees[i].OrganizationId = a.organizationId
+		}
+	}
+
+	result := a.connection.WithContext(ctx).
+		Omit("attendee_id", "updated_at", "deleted_at").
+		Create(&attendees)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "AttendeeRepository.CreateAttendees(): error occured during Attendees creation", "passed_data", len(attendees), "error", err)
+		return nil, err
+	}
+
+	return attendees, nil
+}
+
+func (a *AttendeeRepository) GetAttendeeById(ctx context.Context, attendeeId int) (models.Attendee, error) {
+	var foundAttendee models.Attendee
+
+	result := a.scoped(ctx).Where(`"active"=?`, true).Find(&foundAttendee, "attendee_id", attendeeId)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "AttendeeRepository.GetAttendeeById(): error occured during Attendee search", "passed_data", attendeeId, "error", err)
+		return models.Attendee{}, err
+	}
+
+	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
+		slog.WarnContext(ctx, "AttendeeRepository.GetAttendeeById(): no Attendees were found", "passed_data", attendeeId)
+		return models.Attendee{}, errors.New("no Attendees were found")
+	}
+
+	return foundAttendee, nil
+}
+
+func (a *AttendeeRepository) GetAttendeesByBookingId(ctx context.Context, bookingId int) ([]models.Attendee, error) {
+	var foundAttendees []models.Attendee
+
+	result := a.scoped(ctx).Where(`"active"=?`, true).Order("attendee_id").Find(&foundAttendees, "booking_id", bookingId)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "AttendeeRepository.GetAttendeesByBookingId(): error occured during Attendees search by BookingId", "passed_data", bookingId, "error", err)
+		return nil, err
+	}
+
+	return foundAttendees, nil
+}
+
+// ErrAttendeeNotFound the user is not invited to the booking (or the invitation is removed)
+var ErrAttendeeNotFound = errors.New("no Attendees were found")
+
+func (a *AttendeeRepository) GetAttendeeByBookingIdAndUserId(ctx context.Context, bookingId int, userId int) (models.Attendee, error) {
+	var foundAttendee models.Attendee
+
+	result := a.scoped(ctx).
+		Where(`"active"=? AND "booking_id"=? AND "user_id"=?`, true, bookingId, userId).
+		Find(&foundAttendee)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "AttendeeRepository.GetAttendeeByBookingIdAndUserId(): error occured during Attendee search", "booking_id", bookingId, "user_id", userId, "error", err)
+		return models.Attendee{}, err
+	}
+
+	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
+		slog.WarnContext(ctx, "AttendeeRepository.GetAttendeeByBookingIdAndUserId(): no Attendees were found", "booking_id", bookingId, "user_id", userId)
+		return models.Attendee{}, ErrAttendeeNotFound
+	}
+
+	return foundAttendee, nil
+}
+
+// CountAttendingByBookingId returns number of active attendees who haven't declined invitation
+func (a *AttendeeRepository) CountAttendingByBookingId(ctx context.Context, bookingId int) (int, error) {
+	var attendingCount int64
+
+	result := a.connection.WithContext(ctx).
+		Model(&models.Attendee{}).
+		Where(`"active"=? AND "booking_id"=? AND "status"<>?`, true, bookingId, models.AttendeeStatusDeclined).
+		Count(&attendingCount)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "AttendeeRepository.CountAttendingByBookingId(): error occured during Attendees count", "passed_data", bookingId, "error", err)
+		return 0, err
+	}
+
+	return int(attendingCount), nil
+}
+
+// GetBookingsByAttendeeUserId returns active bookings user is invited to
+func (a *AttendeeRepository) GetBookingsByAttendeeUserId(ctx context.Context, userId int) ([]models.Booking, error) {
+	var foundBookings []models.Booking
+
+	result := a.connection.WithContext(ctx).
+		Select("bookings.*").
+		Joins("JOIN attendees ON attendees.booking_id = bookings.booking_id").
+		Scopes(byOrganization("attendees", a.organizationId)).
+		Where("attendees.user_id = ? AND attendees.active = true AND bookings.active = true", userId).
+		Order("bookings.datetime_start").
+		Find(&foundBookings)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "AttendeeRepository.GetBookingsByAttendeeUserId(): error occured during Bookings search by attendee", "passed_data", userId, "error", err)
+		return nil, err
+	}
+
+	return foundBookings, nil
+}
+
+func (a *AttendeeRepository) UpdateStatus(ctx context.Context, attendee models.Attendee) (models.Attendee, error) {
+	result := a.scoped(ctx).
+		Model(&attendee).
+		Select("status", "responded_at", "updated_at").
+		Updates(&attendee)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "AttendeeRepository.UpdateStatus(): error occured during Attendee status update", "attendee_id", attendee.AttendeeId, "status", attendee.Status, "error", err)
+		return models.Attendee{}, err
+	}
+
+	if rowsUpdated := result.RowsAffected; rowsUpdated == 0 {
+		slog.WarnContext(ctx, "AttendeeRepository.UpdateStatus(): no Attendees were updated. Reason: Attendee to update not found", "passed_data", attendee.AttendeeId)
+		return models.Attendee{}, errors.New("no Attendees were updated")
+	}
+
+	return attendee, nil
+}
+
+func (a *AttendeeRepository) Delete(ctx context.Context, attendeeId int) (bool, error) {
+	attendeeToDelete := models.Attendee{
+		AttendeeId: attendeeId,
+		Active:     false,
+		DeletedAt:  time.Now(),
+	}
+
+	result := a.scoped(ctx).
+		Model(&attendeeToDelete).
+		Where(`"active"=?`, true).
+		Select("active", "deleted_at").
+		Updates(&attendeeToDelete)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "AttendeeRepository.Delete(): error occured during Attendee deletion", "passed_data", attendeeId, "error", err)
+		return false, err
+	}
+
+	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
+		slog.WarnContext(ctx, "AttendeeRepository.Delete(): no Attendees were deleted. Reason: Attendee to delete not found", "passed_data", attendeeId)
+		return false, errors.New("no Attendees were deleted")
+	}
+
+	return true, nil
+}
+
+// GetVisitorPasses returns passes of external guests of active bookings overlapping [start; end].
+// Reception of the organization sees guests of its bookings and of all bookings in its rooms (shared ones too)
+func (a *AttendeeRepository) GetVisitorPasses(ctx context.Context, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.VisitorPass, error) {
+	var visitorPasses []models.VisitorPass
+
+	result := a.visitorPassesQuery(ctx).
+		Where("bookings.datetime_start < @datetime_end AND bookings.datetime_end > @datetime_start",
+			sql.Named("datetime_start", dateTimeStart),
+			sql.Named("datetime_end", dateTimeEnd)).
+		Order("bookings.datetime_start").
+		Scan(&visitorPasses)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "AttendeeRepository.GetVisitorPasses(): error occured during visitor passes search", "date_time_start", dateTimeStart, "date_time_end", dateTimeEnd, "error", err)
+		return nil, err
+	}
+
+	return visitorPasses, nil
+}
+
+func (a *AttendeeRepository) GetVisitorPassByCode(ctx context.Context, passCode string) (models.VisitorPass, error) {
+	var visitorPasses []models.VisitorPass
+
+	result := a.visitorPassesQuery(ctx).
+		Where("attendees.pass_code = ?", passCode).
+		Scan(&visitorPasses)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "AttendeeRepository.GetVisitorPassByCode(): error occured during visitor pass search", "passed_data", passCode, "error", err)
+		return models.VisitorPass{}, err
+	}
+
+	if len(visitorPasses) == 0 {
+		slog.WarnContext(ctx, "AttendeeRepository.GetVisitorPassByCode(): no visitor passes were found", "passed_data", passCode)
+		return models.VisitorPass{}, errors.New("no visitor passes were found")
+	}
+
+	return visitorPasses[0], nil
+}
+
+func (a *AttendeeRepository) visitorPassesQuery(ctx context.Context) *gorm.DB {
+	query := a.connection.WithContext(ctx).
+		Table("attendees").
+		Select(`attendees.pass_code, attendees.attendee_id, attendees.name AS guest_name, attendees.email AS guest_email, attendees.status,
+		    bookings.booking_id, bookings.user_id AS host_user_id, rooms.room_id, rooms.number AS room_number,
+		    bookings.datetime_start AS valid_from, bookings.datetime_end AS valid_until`).
+		Joins("JOIN bookings ON bookings.booking_id = attendees.booking_id").
+		Joins("JOIN rooms ON rooms.room_id = bookings.room_id").
+		Where("attendees.user_id IS NULL AND attendees.active = true AND bookings.active = true AND attendees.status <> ?", models.AttendeeStatusDeclined)
+
+	if a.organizationId != SystemOrganizationId {
+		query = query.Where("(bookings.organization_id = @organization_id OR rooms.organization_id = @organization_id)",
+			sql.Named("organization_id", a.organizationId))
+	}
+
+	return query
+}
@@ -1,128 +1,168 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"go-booking-system/internal/models"
 	"gorm.io/gorm"
-	"log"
+	"log/slog"
 	"time"
 )
 
 // BookingRepository TODO create custom errors
 type BookingRepository struct {
-	connection *gorm.DB
+	connection     *gorm.DB
+	organizationId int
 }
 
-func (b *BookingRepository) Update(booking models.Booking) (models.Booking, error) {
-	result := b.connection.
-		Omit("active", "created_at", "deleted_at"). // `active` is changed only at DELETION
+func (b *BookingRepository) Update(ctx context.Context, booking models.Booking) (models.Booking, error) {
+	result := b.scoped(ctx).
+		Omit("organization_id", "active", "created_at", "deleted_at"). // `active` is changed only at DELETION
 		Model(&booking).
 		Updates(&booking)
 
 	if err := result.Error; err != nil {
-		log.Println("BookingRepository.Update(): error occured during Booking update. Passed data: ", booking)
-		log.Println(err)
+		slog.ErrorContext(ctx, "BookingRepository.Update(): error occured during Booking update", "passed_data", booking, "error", err)
 		return models.Booking{}, err
 	}
 
 	if rowsUpdated := result.RowsAffected; rowsUpdated == 0 {
-		log.Println("BookingRepository.Update(): no Bookings were updated. Reason: Booking to update not found. Passed data: ", booking)
+		slog.WarnContext(ctx, "BookingRepository.Update(): no Bookings were updated. Reason: Booking to update not found", "passed_data", booking)
 		return booking, errors.New("no Bookings were updated")
 	}
 
 	return booking, nil
 }
 
-func (b *BookingRepository) Delete(bookingId int) (bool, error) {
+func (b *BookingRepository) Delete(ctx context.Context, bookingId int) (bool, error) {
 	bookingToDelete := models.Booking{
 		BookingId: bookingId,
 		Active:    false,
 		DeletedAt: time.Now(),
 	}
 
-	result := b.connection.
+	result := b.scoped(ctx).
 		Select("*").
 		Where(`"active"=?`, true).
-		Omit("created_by", "created_at", "updated_at", "room_id", "user_id", "datetime_start", "datetime_end").
+		Omit("organization_id", "created_by", "created_at", "updated_at", "room_id", "user_id", "datetime_start", "datetime_end", "checked_in_at").
 		Model(&bookingToDelete).
 		Updates(&bookingToDelete)
 
 	if err := result.Error; err != nil {
-		log.Println("BookingRepository.Delete(): error occured during Booking deletion. Passed data: ", bookingId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "BookingRepository.Delete(): error occured during Booking deletion", "passed_data", bookingId, "error", err)
 		return false, err
 	}
 
 	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
-		log.Println("BookingRepository.Delete(): no Bookings were deleted. Reason: Booking to delete not found. Passed data: ", bookingId)
+		slog.WarnContext(ctx, "BookingRepository.Delete(): no Bookings were deleted. Reason: Booking to delete not found", "passed_data", bookingId)
 		return false, errors.New("no Bookings were deleted")
 	}
 
 	return true, nil
 }
 
+// DeleteEndedBefore removes bookings which ended before the time together with their attendees. Returns number of removed bookings
+func (b *BookingRepository) DeleteEndedBefore(ctx context.Context, before time.Time) (int, error) {
+	var bookingsDeleted int64
+
+	err := b.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		endedBookings := tx.Model(&models.Booking{}).
+			Scopes(byOrganization("bookings", b.organizationId)).
+			Select("booking_id").
+			Where("datetime_end < ?", before)
+
+		if err := tx.Where("booking_id IN (?)", endedBookings).Delete(&models.Attendee{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Scopes(byOrganization("bookings", b.organizationId)).
+			Where("datetime_end < ?", before).
+			Delete(&models.Booking{})
+		bookingsDeleted = result.RowsAffected
+
+		return result.Error
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "BookingRepository.DeleteEndedBefore(): error occured during Bookings deletion", "passed_data", before, "error", err)
+		return 0, err
+	}
+
+	return int(bookingsDeleted), nil
+}
+
 func NewBookingRepositoryPostgres(connection *gorm.DB) *BookingRepository {
 	return &BookingRepository{connection: connection}
 }
 
-func (b *BookingRepository) Create(booking models.Booking) (models.Booking, error) {
-	result := b.connection.
-		Omit("updated_at", "deleted_at", "active").
+// ForOrganization returns repository which reads and writes only records of the organization
+func (b *BookingRepository) ForOrganization(organizationId int) *BookingRepository {
+	return &BookingRepository{connection: b.connection, organizationId: organizationId}
+}
+
+func (b *BookingRepository) scoped(ctx context.Context) *gorm.DB {
+	return b.connection.WithContext(ctx).Scopes(byOrganization("bookings", b.organizationId))
+}
+
+func (b *BookingRepository) Create(ctx context.Context, booking models.Booking) (models.Booking, error) {
+	if b.organizationId != SystemOrganizationId {
+		booking.OrganizationId = b.organizationId
+	}
+
+	result := b.connection.WithContext(ctx).
+		Omit("updated_at", "deleted_at", "active", "checked_in_at").
 		Create(&booking)
 
 	if err := result.Error; err != nil {
-		log.Println("BookingRepository.Create(): error occured during Booking creation. Passed data: ", booking)
-		log.Println(err)
+		slog.ErrorContext(ctx, "BookingRepository.Create(): error occured during Booking creation", "passed_data", booking, "error", err)
 		return models.Booking{}, err
 	}
 
 	return booking, nil
 }
 
-func (b *BookingRepository) GetAll() []models.Booking {
+func (b *BookingRepository) GetAll(ctx context.Context) []models.Booking {
 	var allBookings []models.Booking
 
-	b.connection.Find(&allBookings)
+	b.scoped(ctx).Find(&allBookings)
 
 	return allBookings
 }
 
-func (b *BookingRepository) GetBookingById(bookingId int) (models.Booking, error) {
+func (b *BookingRepository) GetBookingById(ctx context.Context, bookingId int) (models.Booking, error) {
 	var foundBooking models.Booking
 
-	result := b.connection.Find(&foundBooking, "booking_id", bookingId)
+	result := b.scoped(ctx).Find(&foundBooking, "booking_id", bookingId)
 	if err := result.Error; err != nil {
-		log.Println("BookingRepository.GetBookingById(): error occured during Booking search. Passed data: ", bookingId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "BookingRepository.GetBookingById(): error occured during Booking search", "passed_data", bookingId, "error", err)
 		return models.Booking{}, err
 	}
 
 	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
-		log.Println("BookingRepository.GetBookingById(): no Rooms were found. Passed data: ", bookingId)
+		slog.WarnContext(ctx, "BookingRepository.GetBookingById(): no Rooms were found", "passed_data", bookingId)
 		return models.Booking{}, errors.New("no Rooms were found")
 	}
 
 	return foundBooking, nil
 }
 
-func (b *BookingRepository) GetBookingsByRoomId(roomId int) ([]models.Booking, error) {
+func (b *BookingRepository) GetBookingsByRoomId(ctx context.Context, roomId int) ([]models.Booking, error) {
 	var foundBookings []models.Booking
 
-	result := b.connection.Find(&foundBookings, "room_id", roomId)
+	result := b.scoped(ctx).Find(&foundBookings, "room_id", roomId)
 	if err := result.Error; err != nil {
-		log.Println("BookingRepository.GetBookingsByRoomId(): error occured during Bookings search by RoomId. Passed data: ", roomId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "BookingRepository.GetBookingsByRoomId(): error occured during Bookings search by RoomId", "passed_data", roomId, "error", err)
 		return nil, err
 	}
 
 	return foundBookings, nil
 }
 
-func (b *BookingRepository) GetBookingsByRoomIdAndBookingTime(roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Booking, error) {
+func (b *BookingRepository) GetBookingsByRoomIdAndBookingTime(ctx context.Context, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Booking, error) {
 	var overlapingBookings []models.Booking
 
-	result := b.connection.
+	result := b.connection.WithContext(ctx).
 		Where("room_id = @room_id", sql.Named("room_id", roomId)).
 		Where(`(
 		    (@datetime_start BETWEEN datetime_start AND datetime_end)
@@ -134,16 +174,56 @@ func (b *BookingRepository) GetBookingsByRoomIdAndBookingTime(roomId int, dateTi
 		Find(&overlapingBookings)
 
 	if err := result.Error; err != nil {
-		log.Println("BookingRepository.GetBookingsByRoomIdAndBookingTime(): error occured during overlaping Booking search. Passed data: ", roomId, dateTimeStart, dateTimeEnd)
-		log.Println(err)
+		slog.ErrorContext(ctx, "BookingRepository.GetBookingsByRoomIdAndBookingTime(): error occured during overlaping Booking search", "room_id", roomId, "date_time_start", dateTimeStart, "date_time_end", dateTimeEnd, "error", err)
 		return nil, err
 	}
 
 	return overlapingBookings, nil
 }
 
+// GetUpcomingBookingsByRoomId returns active bookings of the room which are not finished at `from` sorted by start time
+func (b *BookingRepository) GetUpcomingBookingsByRoomId(ctx context.Context, roomId int, from time.Time, limit int) ([]models.Booking, error) {
+	var upcomingBookings []models.Booking
+
+	result := b.connection.WithContext(ctx).
+		Where("room_id = @room_id AND active = true AND datetime_end > @from",
+			sql.Named("room_id", roomId),
+			sql.Named("from", from)).
+		Order("datetime_start").
+		Limit(limit).
+		Find(&upcomingBookings)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "BookingRepository.GetUpcomingBookingsByRoomId(): error occured during upcoming Bookings search", "room_id", roomId, "from", from, "limit", limit, "error", err)
+		return nil, err
+	}
+
+	return upcomingBookings, nil
+}
+
+func (b *BookingRepository) CheckIn(ctx context.Context, bookingId int, checkedInAt time.Time) (models.Booking, error) {
+	bookingToCheckIn := models.Booking{BookingId: bookingId}
+
+	result := b.scoped(ctx).
+		Model(&bookingToCheckIn).
+		Where(`"active"=?`, true).
+		Update("checked_in_at", checkedInAt)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "BookingRepository.CheckIn(): error occured during Booking check-in", "booking_id", bookingId, "checked_in_at", checkedInAt, "error", err)
+		return models.Booking{}, err
+	}
+
+	if rowsUpdated := result.RowsAffected; rowsUpdated == 0 {
+		slog.WarnContext(ctx, "BookingRepository.CheckIn(): no Bookings were checked in. Reason: Booking not found", "passed_data", bookingId)
+		return models.Booking{}, errors.New("no Bookings were checked in")
+	}
+
+	return b.GetBookingById(ctx, bookingId)
+}
+
 // BookRoom Probably Service level...
-func (b *BookingRepository) BookRoom(userId int, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) (models.Booking, error) {
+func (b *BookingRepository) BookRoom(ctx context.Context, userId int, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) (models.Booking, error) {
 	requestedBooking := models.Booking{
 		UserId:        userId,
 		RoomId:        roomId,
@@ -151,10 +231,9 @@ func (b *BookingRepository) BookRoom(userId int, roomId int, dateTimeStart time.
 		DateTimeEnd:   dateTimeEnd,
 	}
 
-	booking, err := b.Create(requestedBooking)
+	booking, err := b.Create(ctx, requestedBooking)
 	if err != nil {
-		log.Println("BookingRepository.BookRoom(): error occured during Bookinging process. Passed data: ", booking)
-		log.Println(err)
+		slog.ErrorContext(ctx, "BookingRepository.BookRoom(): error occured during Bookinging process", "passed_data", booking, "error", err)
 		return models.Booking{}, err
 	}
 
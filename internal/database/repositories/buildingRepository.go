@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+type BuildingRepository struct {
+	connection     *gorm.DB
+	organizationId int
+}
+
+func NewBuildingRepositoryPostgres(connection *gorm.DB) *BuildingRepository {
+	return &BuildingRepository{connection: connection}
+}
+
+// ForOrganization returns repository which writes only buildings of the organization and reads also buildings holding rooms
+// shared with it
+func (b *BuildingRepository) ForOrganization(organizationId int) *BuildingRepository {
+	return &BuildingRepository{connection: b.connection, organizationId: organizationId}
+}
+
+func (b *BuildingRepository) scoped(ctx context.Context) *gorm.DB {
+	return b.connection.WithContext(ctx).Scopes(placesVisibleToOrganization("buildings", b.organizationId))
+}
+
+func (b *BuildingRepository) owned(ctx context.Context) *gorm.DB {
+	return b.connection.WithContext(ctx).Scopes(byOrganization("buildings", b.organizationId))
+}
+
+func (b *BuildingRepository) Create(ctx context.Context, building models.Building) (models.Building, error) {
+	if b.organizationId != SystemOrganizationId {
+		building.OrganizationId = b.organizationId
+	}
+	if err := b.checkLocation(ctx, building.LocationId); err != nil {
+		return models.Building{}, err
+	}
+
+	result := b.connection.WithContext(ctx).
+		Omit("building_id", "updated_at", "deleted_at").
+		Select("organization_id", "location_id", "name", "address", "created_by").
+		Create(&building)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "BuildingRepository.Create(): error occured during Building creation", "passed_data", building, "error", err)
+		return models.Building{}, err
+	}
+
+	return building, nil
+}
+
+func (b *BuildingRepository) GetAll(ctx context.Context) []models.Building {
+	var allBuildings []models.Building
+
+	b.scoped(ctx).Find(&allBuildings)
+
+	return allBuildings
+}
+
+func (b *BuildingRepository) GetBuildingById(ctx context.Context, buildingId int) (models.Building, error) {
+	var foundBuilding models.Building
+
+	result := b.scoped(ctx).Find(&foundBuilding, "building_id", buildingId)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "BuildingRepository.GetBuildingById(): error occured during Building search", "passed_data", buildingId, "error", err)
+		return models.Building{}, err
+	}
+
+	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
+		slog.WarnContext(ctx, "BuildingRepository.GetBuildingById(): no Buildings were found", "passed_data", buildingId)
+		return models.Building{}, errors.New("no Buildings were found")
+	}
+
+	return foundBuilding, nil
+}
+
+func (b *BuildingRepository) GetBuildingsByLocationId(ctx context.Context, locationId int) ([]models.Building, error) {
+	var foundBuildings []models.Building
+
+	result := b.scoped(ctx).Find(&foundBuildings, "location_id", locationId)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "BuildingRepository.GetBuildingsByLocationId(): error occured during Buildings search by LocationId", "passed_data", locationId, "error", err)
+		return nil, err
+	}
+
+	return foundBuildings, nil
+}
+
+func (b *BuildingRepository) Update(ctx context.Context, building models.Building) (models.Building, error) {
+	if building.LocationId != 0 {
+		if err := b.checkLocation(ctx, building.LocationId); err != nil {
+			return building, err
+		}
+	}
+
+	result := b.owned(ctx).
+		Omit("organization_id", "active", "created_at", "deleted_at").
+		Model(&building).
+		Updates(&building)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "BuildingRepository.Update(): error occured during Building update", "passed_data", building, "error", err)
+		return building, err
+	}
+
+	if rowsUpdated := result.RowsAffected; rowsUpdated == 0 {
+		slog.WarnContext(ctx, "BuildingRepository.Update(): no Buildings were updated. Reason: Building to update not found", "passed_data", building)
+		return building, errors.New("no Buildings were updated")
+	}
+
+	return building, nil
+}
+
+func (b *BuildingRepository) Delete(ctx context.Context, buildingId int) (bool, error) {
+	buildingToDelete := models.Building{
+		BuildingId: buildingId,
+		Active:     false,
+		DeletedAt:  time.Now(),
+	}
+
+	result := b.owned(ctx).
+		Select("*").
+		Where(`"active"=?`, true).
+		Omit("organization_id", "location_id", "name", "address", "created_by", "created_at", "updated_at").
+		Model(&buildingToDelete).
+		Updates(&buildingToDelete)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "BuildingRepository.Delete(): error occured during Building deletion", "passed_data", buildingId, "error", err)
+		return false, err
+	}
+
+	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
+		slog.WarnContext(ctx, "BuildingRepository.Delete(): no Buildings were deleted. Reason: Building to delete not found", "passed_data", buildingId)
+		return false, errors.New("no Buildings were deleted")
+	}
+
+	return true, nil
+}
+
+// checkLocation buildings are placed only in locations of the organization
+func (b *BuildingRepository) checkLocation(ctx context.Context, locationId int) error {
+	isOwned, err := isOwnedByOrganization(ctx, b.connection, "locations", "location_id", locationId, b.organizationId)
+	if err != nil {
+		slog.ErrorContext(ctx, "BuildingRepository.checkLocation(): error occured during Location search", "passed_data", locationId, "error", err)
+		return err
+	}
+	if !isOwned {
+		slog.WarnContext(ctx, "BuildingRepository.checkLocation(): no Locations were found", "passed_data", locationId)
+		return errors.New("no Locations were found")
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+type DeviceRepository struct {
+	connection     *gorm.DB
+	organizationId int
+}
+
+func NewDeviceRepositoryPostgres(connection *gorm.DB) *DeviceRepository {
+	return &DeviceRepository{connection: connection}
+}
+
+// ForOrganization returns repository which reads and writes only records of the organization
+func (d *DeviceRepository) ForOrganization(organizationId int) *DeviceRepository {
+	return &DeviceRepository{connection: d.connection, organizationId: organizationId}
+}
+
+func (d *DeviceRepository) scoped(ctx context.Context) *gorm.DB {
+	return d.connection.WithContext(ctx).Scopes(byOrganization("devices", d.organizationId))
+}
+
+func (d *DeviceRepository) Create(ctx context.Context, device models.Device) (models.Device, error) {
+	if d.organizationId != SystemOrganizationId {
+		device.OrganizationId = d.organizationId
+	}
+
+	result := d.connection.WithContext(ctx).
+		Omit("device_id", "updated_at", "deleted_at").
+		Select("organization_id", "room_id", "role_id", "name", "token_hash", "active", "created_by").
+		Create(&device)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "DeviceRepository.Create(): error occured during Device creation", "room_id", device.RoomId, "name", device.Name, "error", err)
+		return models.Device{}, err
+	}
+
+	return device, nil
+}
+
+func (d *DeviceRepository) GetAll(ctx context.Context) []models.Device {
+	var allDevices []models.Device
+
+	d.scoped(ctx).Find(&allDevices)
+
+	return allDevices
+}
+
+func (d *DeviceRepository) GetDeviceById(ctx context.Context, deviceId int) (models.Device, error) {
+	var foundDevice models.Device
+
+	result := d.scoped(ctx).Find(&foundDevice, "device_id", deviceId)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "DeviceRepository.GetDeviceById(): error occured during Device search", "passed_data", deviceId, "error", err)
+		return models.Device{}, err
+	}
+
+	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
+		slog.WarnContext(ctx, "DeviceRepository.GetDeviceById(): no Devices were found", "passed_data", deviceId)
+		return models.Device{}, errors.New("no Devices were found")
+	}
+
+	return foundDevice, nil
+}
+
+// Delete revokes device credentials
+func (d *DeviceRepository) Delete(ctx context.Context, deviceId int) (bool, error) {
+	deviceToDelete := models.Device{
+		DeviceId:  deviceId,
+		Active:    false,
+		DeletedAt: time.Now(),
+	}
+
+	result := d.scoped(ctx).
+		Select("*").
+		Where(`"active"=?`, true).
+		Omit("organization_id", "room_id", "role_id", "name", "token_hash", "created_by", "created_at", "updated_at").
+		Model(&deviceToDelete).
+		Updates(&deviceToDelete)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "DeviceRepository.Delete(): error occured during Device deletion", "passed_data", deviceId, "error", err)
+		return false, err
+	}
+
+	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
+		slog.WarnContext(ctx, "DeviceRepository.Delete(): no Devices were deleted. Reason: Device to delete not found", "passed_data", deviceId)
+		return false, errors.New("no Devices were deleted")
+	}
+
+	return true, nil
+}
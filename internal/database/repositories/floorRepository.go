@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+type FloorRepository struct {
+	connection     *gorm.DB
+	organizationId int
+}
+
+func NewFloorRepositoryPostgres(connection *gorm.DB) *FloorRepository {
+	return &FloorRepository{connection: connection}
+}
+
+// ForOrganization returns repository which writes only floors of the organization and reads also floors holding rooms
+// shared with it
+func (f *FloorRepository) ForOrganization(organizationId int) *FloorRepository {
+	return &FloorRepository{connection: f.connection, organizationId: organizationId}
+}
+
+func (f *FloorRepository) scoped(ctx context.Context) *gorm.DB {
+	return f.connection.WithContext(ctx).Scopes(placesVisibleToOrganization("floors", f.organizationId))
+}
+
+func (f *FloorRepository) owned(ctx context.Context) *gorm.DB {
+	return f.connection.WithContext(ctx).Scopes(byOrganization("floors", f.organizationId))
+}
+
+func (f *FloorRepository) Create(ctx context.Context, floor models.Floor) (models.Floor, error) {
+	if f.organizationId != SystemOrganizationId {
+		floor.OrganizationId = f.organizationId
+	}
+	if err := f.checkBuilding(ctx, floor.BuildingId); err != nil {
+		return models.Floor{}, err
+	}
+
+	result := f.connection.WithContext(ctx).
+		Omit("floor_id", "updated_at", "deleted_at").
+		Select("organization_id", "building_id", "name", "level", "created_by").
+		Create(&floor)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "FloorRepository.Create(): error occured during Floor creation", "passed_data", floor, "error", err)
+		return models.Floor{}, err
+	}
+
+	return floor, nil
+}
+
+func (f *FloorRepository) GetAll(ctx context.Context) []models.Floor {
+	var allFloors []models.Floor
+
+	f.scoped(ctx).Find(&allFloors)
+
+	return allFloors
+}
+
+func (f *FloorRepository) GetFloorById(ctx context.Context, floorId int) (models.Floor, error) {
+	var foundFloor models.Floor
+
+	result := f.scoped(ctx).Find(&foundFloor, "floor_id", floorId)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "FloorRepository.GetFloorById(): error occured during Floor search", "passed_data", floorId, "error", err)
+		return models.Floor{}, err
+	}
+
+	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
+		slog.WarnContext(ctx, "FloorRepository.GetFloorById(): no Floors were found", "passed_data", floorId)
+		return models.Floor{}, errors.New("no Floors were found")
+	}
+
+	return foundFloor, nil
+}
+
+func (f *FloorRepository) GetFloorsByBuildingId(ctx context.Context, buildingId int) ([]models.Floor, error) {
+	var foundFloors []models.Floor
+
+	result := f.scoped(ctx).Find(&foundFloors, "building_id", buildingId)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "FloorRepository.GetFloorsByBuildingId(): error occured during Floors search by BuildingId", "passed_data", buildingId, "error", err)
+		return nil, err
+	}
+
+	return foundFloors, nil
+}
+
+func (f *FloorRepository) Update(ctx context.Context, floor models.Floor) (models.Floor, error) {
+	if floor.BuildingId != 0 {
+		if err := f.checkBuilding(ctx, floor.BuildingId); err != nil {
+			return floor, err
+		}
+	}
+
+	result := f.owned(ctx).
+		Omit("organization_id", "active", "created_at", "deleted_at").
+		Model(&floor).
+		Updates(&floor)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "FloorRepository.Update(): error occured during Floor update", "passed_data", floor, "error", err)
+		return floor, err
+	}
+
+	if rowsUpdated := result.RowsAffected; rowsUpdated == 0 {
+		slog.WarnContext(ctx, "FloorRepository.Update(): no Floors were updated. Reason: Floor to update not found", "passed_data", floor)
+		return floor, errors.New("no Floors were updated")
+	}
+
+	return floor, nil
+}
+
+func (f *FloorRepository) Delete(ctx context.Context, floorId int) (bool, error) {
+	floorToDelete := models.Floor{
+		FloorId:   floorId,
+		Active:    false,
+		DeletedAt: time.Now(),
+	}
+
+	result := f.owned(ctx).
+		Select("*").
+		Where(`"active"=?`, true).
+		Omit("organization_id", "building_id", "name", "level", "created_by", "created_at", "updated_at").
+		Model(&floorToDelete).
+		Updates(&floorToDelete)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "FloorRepository.Delete(): error occured during Floor deletion", "passed_data", floorId, "error", err)
+		return false, err
+	}
+
+	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
+		slog.WarnContext(ctx, "FloorRepository.Delete(): no Floors were deleted. Reason: Floor to delete not found", "passed_data", floorId)
+		return false, errors.New("no Floors were deleted")
+	}
+
+	return true, nil
+}
+
+// checkBuilding floors are placed only in buildings of the organization
+func (f *FloorRepository) checkBuilding(ctx context.Context, buildingId int) error {
+	isOwned, err := isOwnedByOrganization(ctx, f.connection, "buildings", "building_id", buildingId, f.organizationId)
+	if err != nil {
+		slog.ErrorContext(ctx, "FloorRepository.checkBuilding(): error occured during Building search", "passed_data", buildingId, "error", err)
+		return err
+	}
+	if !isOwned {
+		slog.WarnContext(ctx, "FloorRepository.checkBuilding(): no Buildings were found", "passed_data", buildingId)
+		return errors.New("no Buildings were found")
+	}
+
+	return nil
+}
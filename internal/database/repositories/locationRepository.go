@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+type LocationRepository struct {
+	connection     *gorm.DB
+	organizationId int
+}
+
+func NewLocationRepositoryPostgres(connection *gorm.DB) *LocationRepository {
+	return &LocationRepository{connection: connection}
+}
+
+// ForOrganization returns repository which writes only locations of the organization and reads also locations holding rooms
+// shared with it
+func (l *LocationRepository) ForOrganization(organizationId int) *LocationRepository {
+	return &LocationRepository{connection: l.connection, organizationId: organizationId}
+}
+
+func (l *LocationRepository) scoped(ctx context.Context) *gorm.DB {
+	return l.connection.WithContext(ctx).Scopes(placesVisibleToOrganization("locations", l.organizationId))
+}
+
+func (l *LocationRepository) owned(ctx context.Context) *gorm.DB {
+	return l.connection.WithContext(ctx).Scopes(byOrganization("locations", l.organizationId))
+}
+
+func (l *LocationRepository) Create(ctx context.Context, location models.Location) (models.Location, error) {
+	if l.organizationId != SystemOrganizationId {
+		location.OrganizationId = l.organizationId
+	}
+
+	result := l.connection.WithContext(ctx).
+		Omit("location_id", "updated_at", "deleted_at").
+		Select("organization_id", "name", "address", "time_zone", "created_by").
+		Create(&location)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "LocationRepository.Create(): error occured during Location creation", "passed_data", location, "error", err)
+		return models.Location{}, err
+	}
+
+	return location, nil
+}
+
+func (l *LocationRepository) GetAll(ctx context.Context) []models.Location {
+	var allLocations []models.Location
+
+	l.scoped(ctx).Find(&allLocations)
+
+	return allLocations
+}
+
+func (l *LocationRepository) GetLocationById(ctx context.Context, locationId int) (models.Location, error) {
+	var foundLocation models.Location
+
+	result := l.scoped(ctx).Find(&foundLocation, "location_id", locationId)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "LocationRepository.GetLocationById(): error occured during Location search", "passed_data", locationId, "error", err)
+		return models.Location{}, err
+	}
+
+	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
+		slog.WarnContext(ctx, "LocationRepository.GetLocationById(): no Locations were found", "passed_data", locationId)
+		return models.Location{}, errors.New("no Locations were found")
+	}
+
+	return foundLocation, nil
+}
+
+// ErrRoomWithoutLocation room is not placed on any floor - it has no location, time zone and opening hours
+var ErrRoomWithoutLocation = errors.New("no Locations were found")
+
+// GetLocationByRoomId finds location of the room: Room -> Floor -> Building -> Location. Location of any room visible to
+// the organization is found, including rooms shared with it
+func (l *LocationRepository) GetLocationByRoomId(ctx context.Context, roomId int) (models.Location, error) {
+	var foundLocation models.Location
+
+	result := l.connection.WithContext(ctx).
+		Scopes(roomsVisibleToOrganization(l.organizationId)).
+		Select("locations.*").
+		Joins("JOIN buildings ON buildings.location_id = locations.location_id").
+		Joins("JOIN floors ON floors.building_id = buildings.building_id").
+		Joins("JOIN rooms ON rooms.floor_id = floors.floor_id").
+		Where("rooms.room_id = ?", roomId).
+		Find(&foundLocation)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "LocationRepository.GetLocationByRoomId(): error occured during Location search", "passed_data", roomId, "error", err)
+		return models.Location{}, err
+	}
+
+	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
+		slog.WarnContext(ctx, "LocationRepository.GetLocationByRoomId(): no Locations were found", "passed_data", roomId)
+		return models.Location{}, ErrRoomWithoutLocation
+	}
+
+	return foundLocation, nil
+}
+
+func (l *LocationRepository) Update(ctx context.Context, location models.Location) (models.Location, error) {
+	result := l.owned(ctx).
+		Omit("organization_id", "active", "created_at", "deleted_at").
+		Model(&location).
+		Updates(&location)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "LocationRepository.Update(): error occured during Location update", "passed_data", location, "error", err)
+		return location, err
+	}
+
+	if rowsUpdated := result.RowsAffected; rowsUpdated == 0 {
+		slog.WarnContext(ctx, "LocationRepository.Update(): no Locations were updated. Reason: Location to update not found", "passed_data", location)
+		return location, errors.New("no Locations were updated")
+	}
+
+	return location, nil
+}
+
+func (l *LocationRepository) Delete(ctx context.Context, locationId int) (bool, error) {
+	locationToDelete := models.Location{
+		LocationId: locationId,
+		Active:     false,
+		DeletedAt:  time.Now(),
+	}
+
+	result := l.owned(ctx).
+		Select("*").
+		Where(`"active"=?`, true).
+		Omit("organization_id", "name", "address", "time_zone", "created_by", "created_at", "updated_at").
+		Model(&locationToDelete).
+		Updates(&locationToDelete)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "LocationRepository.Delete(): error occured during Location deletion", "passed_data", locationId, "error", err)
+		return false, err
+	}
+
+	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
+		slog.WarnContext(ctx, "LocationRepository.Delete(): no Locations were deleted. Reason: Location to delete not found", "passed_data", locationId)
+		return false, errors.New("no Locations were deleted")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+type MFAPolicyRepository struct {
+	connection     *gorm.DB
+	organizationId int
+}
+
+func NewMFAPolicyRepositoryPostgres(connection *gorm.DB) *MFAPolicyRepository {
+	return &MFAPolicyRepository{connection: connection}
+}
+
+// ForOrganization returns repository which reads and writes only policies of the organization
+func (m *MFAPolicyRepository) ForOrganization(organizationId int) *MFAPolicyRepository {
+	return &MFAPolicyRepository{connection: m.connection, organizationId: organizationId}
+}
+
+func (m *MFAPolicyRepository) scoped(ctx context.Context) *gorm.DB {
+	return m.connection.WithContext(ctx).Scopes(byOrganization("mfa_policies", m.organizationId))
+}
+
+func (m *MFAPolicyRepository) GetMFAPolicies(ctx context.Context) ([]models.MFAPolicy, error) {
+	var foundPolicies []models.MFAPolicy
+
+	result := m.scoped(ctx).Order("role_id").Find(&foundPolicies)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "MFAPolicyRepository.GetMFAPolicies(): error occured during MFA Policies search", "organization_id", m.organizationId, "error", err)
+		return nil, err
+	}
+
+	return foundPolicies, nil
+}
+
+func (m *MFAPolicyRepository) IsMFARequired(ctx context.Context, roleId int) (bool, error) {
+	var count int64
+
+	result := m.scoped(ctx).Model(&models.MFAPolicy{}).Where("role_id = ?", roleId).Count(&count)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "MFAPolicyRepository.IsMFARequired(): error occured during MFA Policy search", "role_id", roleId, "error", err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// ReplaceMFAPolicies deletes all policies of the organization and creates passed ones in one transaction
+func (m *MFAPolicyRepository) ReplaceMFAPolicies(ctx context.Context, policies []models.MFAPolicy) ([]models.MFAPolicy, error) {
+	if m.organizationId == SystemOrganizationId {
+		return nil, errors.New("MFA policies can be replaced only within an organization")
+	}
+	for i := range policies {
+		policies[i].OrganizationId = m.organizationId
+	}
+
+	err := m.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("organization_id = ?", m.organizationId).Delete(&models.MFAPolicy{}).Error; err != nil {
+			return err
+		}
+		if len(policies) == 0 {
+			return nil
+		}
+
+		return tx.Omit("created_at").Create(&policies).Error
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "MFAPolicyRepository.ReplaceMFAPolicies(): error occured during MFA Policies replacement", "organization_id", m.organizationId, "policies", policies, "error", err)
+		return nil, err
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+type OpeningHoursRepository struct {
+	connection     *gorm.DB
+	organizationId int
+}
+
+func NewOpeningHoursRepositoryPostgres(connection *gorm.DB) *OpeningHoursRepository {
+	return &OpeningHoursRepository{connection: connection}
+}
+
+// ForOrganization returns repository which replaces only opening hours of locations of the organization and reads also
+// opening hours of locations holding rooms shared with it
+func (o *OpeningHoursRepository) ForOrganization(organizationId int) *OpeningHoursRepository {
+	return &OpeningHoursRepository{connection: o.connection, organizationId: organizationId}
+}
+
+func (o *OpeningHoursRepository) scoped(ctx context.Context) *gorm.DB {
+	if o.organizationId == SystemOrganizationId {
+		return o.connection.WithContext(ctx)
+	}
+
+	visibleLocations := o.connection.
+		Model(&models.Location{}).
+		Select("location_id").
+		Scopes(placesVisibleToOrganization("locations", o.organizationId))
+
+	return o.connection.WithContext(ctx).Where("opening_hours.location_id IN (?)", visibleLocations)
+}
+
+func (o *OpeningHoursRepository) GetOpeningHoursByLocationId(ctx context.Context, locationId int) ([]models.OpeningHours, error) {
+	var foundOpeningHours []models.OpeningHours
+
+	result := o.scoped(ctx).
+		Order("weekday").
+		Order("opens_at").
+		Find(&foundOpeningHours, "location_id", locationId)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "OpeningHoursRepository.GetOpeningHoursByLocationId(): error occured during Opening Hours search", "passed_data", locationId, "error", err)
+		return nil, err
+	}
+
+	return foundOpeningHours, nil
+}
+
+// ReplaceOpeningHours deletes all rules of the location and creates passed ones in one transaction
+func (o *OpeningHoursRepository) ReplaceOpeningHours(ctx context.Context, locationId int, openingHours []models.OpeningHours) ([]models.OpeningHours, error) {
+	err := o.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		isOwned, err := isOwnedByOrganization(ctx, tx, "locations", "location_id", locationId, o.organizationId)
+		if err != nil {
+			return err
+		}
+		if !isOwned {
+			return errors.New("no Locations were found")
+		}
+
+		if err := tx.Where("location_id = ?", locationId).Delete(&models.OpeningHours{}).Error; err != nil {
+			return err
+		}
+		if len(openingHours) == 0 {
+			return nil
+		}
+
+		return tx.Create(&openingHours).Error
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "OpeningHoursRepository.ReplaceOpeningHours(): error occured during Opening Hours replacement", "location_id", locationId, "opening_hours", openingHours, "error", err)
+		return nil, err
+	}
+
+	return openingHours, nil
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+type OrganizationRepository struct {
+	connection *gorm.DB
+}
+
+func NewOrganizationRepositoryPostgres(connection *gorm.DB) *OrganizationRepository {
+	return &OrganizationRepository{connection: connection}
+}
+
+func (o *OrganizationRepository) Create(ctx context.Context, organization models.Organization) (models.Organization, error) {
+	result := o.connection.WithContext(ctx).
+		Omit("organization_id", "updated_at", "deleted_at").
+		Select("name", "created_by").
+		Create(&organization)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "OrganizationRepository.Create(): error occured during Organization creation", "passed_data", organization, "error", err)
+		return models.Organization{}, err
+	}
+
+	return organization, nil
+}
+
+func (o *OrganizationRepository) GetAll(ctx context.Context) []models.Organization {
+	var allOrganizations []models.Organization
+
+	o.connection.WithContext(ctx).Find(&allOrganizations)
+
+	return allOrganizations
+}
+
+func (o *OrganizationRepository) GetOrganizationById(ctx context.Context, organizationId int) (models.Organization, error) {
+	var foundOrganization models.Organization
+
+	result := o.connection.WithContext(ctx).Find(&foundOrganization, "organization_id", organizationId)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "OrganizationRepository.GetOrganizationById(): error occured during Organization search", "passed_data", organizationId, "error", err)
+		return models.Organization{}, err
+	}
+
+	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
+		slog.WarnContext(ctx, "OrganizationRepository.GetOrganizationById(): no Organizations were found", "passed_data", organizationId)
+		return models.Organization{}, errors.New("no Organizations were found")
+	}
+
+	return foundOrganization, nil
+}
+
+func (o *OrganizationRepository) Update(ctx context.Context, organization models.Organization) (models.Organization, error) {
+	result := o.connection.WithContext(ctx).
+		Omit("active", "created_at", "deleted_at").
+		Model(&organization).
+		Updates(&organization)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "OrganizationRepository.Update(): error occured during Organization update", "passed_data", organization, "error", err)
+		return organization, err
+	}
+
+	if rowsUpdated := result.RowsAffected; rowsUpdated == 0 {
+		slog.WarnContext(ctx, "OrganizationRepository.Update(): no Organizations were updated. Reason: Organization to update not found", "passed_data", organization)
+		return organization, errors.New("no Organizations were updated")
+	}
+
+	return organization, nil
+}
+
+func (o *OrganizationRepository) Delete(ctx context.Context, organizationId int) (bool, error) {
+	organizationToDelete := models.Organization{
+		OrganizationId: organizationId,
+		Active:         false,
+		DeletedAt:      time.Now(),
+	}
+
+	result := o.connection.WithContext(ctx).
+		Select("*").
+		Where(`"active"=?`, true).
+		Omit("name", "created_by", "created_at", "updated_at").
+		Model(&organizationToDelete).
+		Updates(&organizationToDelete)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "OrganizationRepository.Delete(): error occured during Organization deletion", "passed_data", organizationId, "error", err)
+		return false, err
+	}
+
+	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
+		slog.WarnContext(ctx, "OrganizationRepository.Delete(): no Organizations were deleted. Reason: Organization to delete not found", "passed_data", organizationId)
+		return false, errors.New("no Organizations were deleted")
+	}
+
+	return true, nil
+}
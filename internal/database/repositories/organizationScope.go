@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"gorm.io/gorm"
+)
+
+// SystemOrganizationId is used by repositories which are not bound to any organization (login, CLI, background jobs).
+// Queries of such repositories are not tenant-scoped
+const SystemOrganizationId = 0
+
+// byOrganization limits query to records of the table belonging to the organization
+func byOrganization(table string, organizationId int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if organizationId == SystemOrganizationId {
+			return db
+		}
+
+		return db.Where(table+".organization_id = ?", organizationId)
+	}
+}
+
+// byOrganizationOrSystem limits query to records of the organization and records without organization (shared by all organizations)
+func byOrganizationOrSystem(table string, organizationId int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if organizationId == SystemOrganizationId {
+			return db
+		}
+
+		return db.Where("("+table+".organization_id = ? OR "+table+".organization_id IS NULL)", organizationId)
+	}
+}
+
+// roomsVisibleToOrganization limits query to rooms owned by the organization and rooms shared with it
+func roomsVisibleToOrganization(organizationId int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if organizationId == SystemOrganizationId {
+			return db
+		}
+
+		return db.Where(`(rooms.organization_id = @organization_id OR EXISTS (
+		    SELECT 1 FROM room_shares
+		    WHERE room_shares.room_id = rooms.room_id
+		      AND room_shares.organization_id = @organization_id
+		      AND room_shares.active = true))`,
+			sql.Named("organization_id", organizationId))
+	}
+}
+
+// roomsSharedWithOrganizationIn rooms shared with the organization which are placed in the location, building or floor
+var roomsSharedWithOrganizationIn = map[string]string{
+	"locations": `SELECT 1 FROM room_shares
+		    JOIN rooms ON rooms.room_id = room_shares.room_id
+		    JOIN floors ON floors.floor_id = rooms.floor_id
+		    JOIN buildings ON buildings.building_id = floors.building_id
+		    WHERE buildings.location_id = locations.location_id`,
+	"buildings": `SELECT 1 FROM room_shares
+		    JOIN rooms ON rooms.room_id = room_shares.room_id
+		    JOIN floors ON floors.floor_id = rooms.floor_id
+		    WHERE floors.building_id = buildings.building_id`,
+	"floors": `SELECT 1 FROM room_shares
+		    JOIN rooms ON rooms.room_id = room_shares.room_id
+		    WHERE rooms.floor_id = floors.floor_id`,
+}
+
+// placesVisibleToOrganization limits query to locations, buildings or floors of the organization and to those holding
+// rooms shared with it - shared rooms keep their time zone, opening hours and place in access rules
+func placesVisibleToOrganization(table string, organizationId int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if organizationId == SystemOrganizationId {
+			return db
+		}
+
+		return db.Where(`(`+table+`.organization_id = @organization_id OR EXISTS (
+		    `+roomsSharedWithOrganizationIn[table]+`
+		      AND room_shares.organization_id = @organization_id
+		      AND room_shares.active = true))`,
+			sql.Named("organization_id", organizationId))
+	}
+}
+
+// isOwnedByOrganization checks that the record referenced by created or updated record (e.g. location of the building)
+// belongs to the organization
+func isOwnedByOrganization(ctx context.Context, connection *gorm.DB, table string, idColumn string, id int, organizationId int) (bool, error) {
+	if organizationId == SystemOrganizationId {
+		return true, nil
+	}
+
+	var count int64
+	result := connection.WithContext(ctx).
+		Table(table).
+		Scopes(byOrganization(table, organizationId)).
+		Where(table+"."+idColumn+" = ?", id).
+		Count(&count)
+
+	return count != 0, result.Error
+}
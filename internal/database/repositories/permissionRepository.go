@@ -1,102 +1,154 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"go-booking-system/internal/models"
 	"gorm.io/gorm"
-	"log"
+	"log/slog"
 	"time"
 )
 
 type PermissionRepository struct {
-	connection *gorm.DB
+	connection     *gorm.DB
+	organizationId int
 }
 
 func NewPermissionRepositoryPostgres(connection *gorm.DB) *PermissionRepository {
 	return &PermissionRepository{connection: connection}
 }
 
-func (r *PermissionRepository) Create(permission models.Permission) (models.Permission, error) {
-	result := r.connection.
+// ForOrganization returns repository which reads permissions of roles available to the organization (its own and system
+// ones) and writes only permissions of its own roles - permissions of system roles are shared by all organizations
+func (r *PermissionRepository) ForOrganization(organizationId int) *PermissionRepository {
+	return &PermissionRepository{connection: r.connection, organizationId: organizationId}
+}
+
+func (r *PermissionRepository) scoped(ctx context.Context) *gorm.DB {
+	return r.byRoles(ctx, byOrganizationOrSystem)
+}
+
+func (r *PermissionRepository) owned(ctx context.Context) *gorm.DB {
+	return r.byRoles(ctx, byOrganization)
+}
+
+func (r *PermissionRepository) byRoles(ctx context.Context, rolesScope func(table string, organizationId int) func(*gorm.DB) *gorm.DB) *gorm.DB {
+	if r.organizationId == SystemOrganizationId {
+		return r.connection.WithContext(ctx)
+	}
+
+	roles := r.connection.
+		Model(&models.Role{}).
+		Select("role_id").
+		Scopes(rolesScope("roles", r.organizationId))
+
+	return r.connection.WithContext(ctx).Where("permissions.role_id IN (?)", roles)
+}
+
+// checkOwnership permission is granted only to roles of the organization and limited only to its locations
+func (r *PermissionRepository) checkOwnership(ctx context.Context, permission models.Permission) error {
+	isOwned, err := isOwnedByOrganization(ctx, r.connection, "roles", "role_id", permission.RoleId, r.organizationId)
+	if err == nil && isOwned && permission.LocationId != 0 {
+		isOwned, err = isOwnedByOrganization(ctx, r.connection, "locations", "location_id", permission.LocationId, r.organizationId)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "PermissionRepository.checkOwnership(): error occured during Role and Location search", "passed_data", permission, "error", err)
+		return err
+	}
+	if !isOwned {
+		slog.WarnContext(ctx, "PermissionRepository.checkOwnership(): role or location belongs to another organization", "passed_data", permission)
+		return errors.New("role or location of the permission belongs to another organization")
+	}
+
+	return nil
+}
+
+func (r *PermissionRepository) Create(ctx context.Context, permission models.Permission) (models.Permission, error) {
+	if err := r.checkOwnership(ctx, permission); err != nil {
+		return models.Permission{}, err
+	}
+
+	result := r.connection.WithContext(ctx).
 		Omit("updated_at", "deleted_at").
 		Create(&permission)
 
 	if err := result.Error; err != nil {
-		log.Println("PermissionRepository.Create(): error occured during Permission creation. Passed data: ", permission)
-		log.Println(err)
+		slog.ErrorContext(ctx, "PermissionRepository.Create(): error occured during Permission creation", "passed_data", permission, "error", err)
 		return models.Permission{}, err
 	}
 
 	return permission, nil
 }
 
-func (r *PermissionRepository) GetAll() []models.Permission {
+func (r *PermissionRepository) GetAll(ctx context.Context) []models.Permission {
 	var allPermissions []models.Permission
 
-	r.connection.Find(&allPermissions)
+	r.scoped(ctx).Find(&allPermissions)
 
 	return allPermissions
 }
 
-func (r *PermissionRepository) GetPermissionsByRoleId(roleId int) ([]models.Permission, error) {
+func (r *PermissionRepository) GetPermissionsByRoleId(ctx context.Context, roleId int) ([]models.Permission, error) {
 	var foundPermissions []models.Permission
 
-	result := r.connection.Find(&foundPermissions, "role_id", roleId)
+	result := r.scoped(ctx).Find(&foundPermissions, "role_id", roleId)
 	if err := result.Error; err != nil {
-		log.Println("PermissionRepository.GetPermissionByRoleId(): error occured during Permissions search. Passed data: ", roleId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "PermissionRepository.GetPermissionByRoleId(): error occured during Permissions search", "passed_data", roleId, "error", err)
 		return []models.Permission{}, err
 	}
 
 	return foundPermissions, nil
 }
 
-func (r *PermissionRepository) GetPermissionsByRouteId(routeId int) ([]models.Permission, error) {
+func (r *PermissionRepository) GetPermissionsByRouteId(ctx context.Context, routeId int) ([]models.Permission, error) {
 	var foundPermissions []models.Permission
 
-	result := r.connection.Find(&foundPermissions, "route_id", routeId)
+	result := r.scoped(ctx).Find(&foundPermissions, "route_id", routeId)
 	if err := result.Error; err != nil {
-		log.Println("PermissionRepository.GetPermissionsByRouteId(): error occured during Permissions search. Passed data: ", routeId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "PermissionRepository.GetPermissionsByRouteId(): error occured during Permissions search", "passed_data", routeId, "error", err)
 		return []models.Permission{}, err
 	}
 
 	return foundPermissions, nil
 }
 
-func (r *PermissionRepository) GetPermissionsByRoleIdAndRouteId(roleId int, routeId int) ([]models.Permission, error) {
+func (r *PermissionRepository) GetPermissionsByRoleIdAndRouteId(ctx context.Context, roleId int, routeId int) ([]models.Permission, error) {
 	var foundPermissions []models.Permission
 
-	result := r.connection.
-		Where("role_id = @role_id AND route_id = @route_id",
+	result := r.scoped(ctx).
+		Where("role_id = @role_id AND route_id = @route_id AND active = true",
 			sql.Named("role_id", roleId),
 			sql.Named("route_id", routeId)).
 		Find(&foundPermissions)
 
 	if err := result.Error; err != nil {
-		log.Printf("PermissionRepository.GetPermissionsByRoleIdAndRouteId(): error occured during Permission deletion. Passed data: RoleId=%d RouteId=%d", roleId, routeId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "PermissionRepository.GetPermissionsByRoleIdAndRouteId(): error occured during Permission search", "role_id", roleId, "route_id", routeId, "error", err)
 		return []models.Permission{}, err
 	}
 
 	return foundPermissions, nil
 }
 
-func (r *PermissionRepository) Update(permission models.Permission) (models.Permission, error) {
-	result := r.connection.
+func (r *PermissionRepository) Update(ctx context.Context, permission models.Permission) (models.Permission, error) {
+	if err := r.checkOwnership(ctx, permission); err != nil {
+		return permission, err
+	}
+
+	result := r.owned(ctx).
 		Omit("active", "created_at", "deleted_at").
 		Model(&permission).
 		Updates(&permission)
 
 	if err := result.Error; err != nil {
-		log.Println("PermissionRepository.Update(): error occured during Permission update. Passed data: ", permission)
+		slog.ErrorContext(ctx, "PermissionRepository.Update(): error occured during Permission update", "passed_data", permission, "error", err)
 		return permission, err
 	}
 
 	return permission, nil
 }
 
-func (r *PermissionRepository) Delete(roleId int, routeId int) (bool, error) {
+func (r *PermissionRepository) Delete(ctx context.Context, roleId int, routeId int) (bool, error) {
 	permissionToDelete := models.Permission{
 		RoleId:    roleId,
 		RouteId:   routeId,
@@ -104,17 +156,22 @@ func (r *PermissionRepository) Delete(roleId int, routeId int) (bool, error) {
 		DeletedAt: time.Now(),
 	}
 
-	result := r.connection.
-		Select("*").
-		Omit("created_at", "updated_at", "name", "description").
-		Model(&permissionToDelete).
+	// permissions have no primary key - all active permissions of the role to the route are revoked
+	result := r.owned(ctx).
+		Model(&models.Permission{}).
+		Where("role_id = ? AND route_id = ? AND active = true", roleId, routeId).
+		Select("active", "deleted_at").
 		Updates(&permissionToDelete)
 
 	if err := result.Error; err != nil {
-		log.Printf("PermissionRepository.Delete(): error occured during Permission deletion. Passed data: RoleId=%d RouteId=%d", roleId, roleId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "PermissionRepository.Delete(): error occured during Permission deletion", "role_id", roleId, "route_id", routeId, "error", err)
 		return false, err
 	}
 
+	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
+		slog.WarnContext(ctx, "PermissionRepository.Delete(): no Permissions were deleted. Reason: Permission to delete not found", "role_id", roleId, "route_id", routeId)
+		return false, errors.New("no Permissions were deleted")
+	}
+
 	return true, nil
 }
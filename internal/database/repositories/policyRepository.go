@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+type PolicyRepository struct {
+	connection     *gorm.DB
+	organizationId int
+}
+
+func NewPolicyRepositoryPostgres(connection *gorm.DB) *PolicyRepository {
+	return &PolicyRepository{connection: connection}
+}
+
+// ForOrganization returns repository which reads and writes only policies of the organization
+func (p *PolicyRepository) ForOrganization(organizationId int) *PolicyRepository {
+	return &PolicyRepository{connection: p.connection, organizationId: organizationId}
+}
+
+func (p *PolicyRepository) scoped(ctx context.Context) *gorm.DB {
+	return p.connection.WithContext(ctx).Scopes(byOrganization("policies", p.organizationId))
+}
+
+func (p *PolicyRepository) Create(ctx context.Context, policy models.Policy) (models.Policy, error) {
+	if p.organizationId != SystemOrganizationId {
+		policy.OrganizationId = p.organizationId
+	}
+
+	// policy without role is stored with null role - it is applied to every role
+	columns := []string{"organization_id", "name", "description", "route_id", "effect", "conditions", "active", "created_by"}
+	if policy.RoleId != 0 {
+		columns = append(columns, "role_id")
+	}
+
+	result := p.connection.WithContext(ctx).
+		Select(columns).
+		Create(&policy)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "PolicyRepository.Create(): error occured during Policy creation", "passed_data", policy, "error", err)
+		return models.Policy{}, err
+	}
+
+	return policy, nil
+}
+
+func (p *PolicyRepository) GetAll(ctx context.Context) []models.Policy {
+	var allPolicies []models.Policy
+
+	p.scoped(ctx).Where(`"active"=?`, true).Order("policy_id").Find(&allPolicies)
+
+	return allPolicies
+}
+
+func (p *PolicyRepository) GetPolicyById(ctx context.Context, policyId int) (models.Policy, error) {
+	var foundPolicy models.Policy
+
+	result := p.scoped(ctx).Where(`"active"=?`, true).Find(&foundPolicy, "policy_id", policyId)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "PolicyRepository.GetPolicyById(): error occured during Policy search", "passed_data", policyId, "error", err)
+		return models.Policy{}, err
+	}
+
+	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
+		slog.WarnContext(ctx, "PolicyRepository.GetPolicyById(): no Policies were found", "passed_data", policyId)
+		return models.Policy{}, errors.New("no Policies were found")
+	}
+
+	return foundPolicy, nil
+}
+
+// GetPoliciesByRoleIdAndRouteId active policies of the route for the role and for every role
+func (p *PolicyRepository) GetPoliciesByRoleIdAndRouteId(ctx context.Context, roleId int, routeId int) ([]models.Policy, error) {
+	var foundPolicies []models.Policy
+
+	result := p.scoped(ctx).
+		Where(`route_id = ? AND (role_id = ? OR role_id IS NULL) AND "active"=?`, routeId, roleId, true).
+		Order("policy_id").
+		Find(&foundPolicies)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "PolicyRepository.GetPoliciesByRoleIdAndRouteId(): error occured during Policies search", "role_id", roleId, "route_id", routeId, "error", err)
+		return nil, err
+	}
+
+	return foundPolicies, nil
+}
+
+// Update replaces rule of the policy. Role is set to null (every role) if it is not passed
+func (p *PolicyRepository) Update(ctx context.Context, policy models.Policy) (models.Policy, error) {
+	roleId := any(nil)
+	if policy.RoleId != 0 {
+		roleId = policy.RoleId
+	}
+
+	result := p.scoped(ctx).
+		Model(&models.Policy{}).
+		Where(`policy_id = ? AND "active"=?`, policy.PolicyId, true).
+		Updates(map[string]interface{}{
+			"name":        policy.Name,
+			"description": policy.Description,
+			"role_id":     roleId,
+			"route_id":    policy.RouteId,
+			"effect":      policy.Effect,
+			"conditions":  policy.Conditions,
+			"updated_at":  time.Now(),
+		})
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "PolicyRepository.Update(): error occured during Policy update", "passed_data", policy, "error", err)
+		return models.Policy{}, err
+	}
+
+	if rowsUpdated := result.RowsAffected; rowsUpdated == 0 {
+		slog.WarnContext(ctx, "PolicyRepository.Update(): no Policies were updated. Reason: Policy to update not found", "passed_data", policy.PolicyId)
+		return models.Policy{}, errors.New("no Policies were updated")
+	}
+
+	return policy, nil
+}
+
+func (p *PolicyRepository) Delete(ctx context.Context, policyId int) (bool, error) {
+	result := p.scoped(ctx).
+		Model(&models.Policy{}).
+		Where(`policy_id = ? AND "active"=?`, policyId, true).
+		UpdateColumns(map[string]interface{}{"active": false, "deleted_at": time.Now()})
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "PolicyRepository.Delete(): error occured during Policy deletion", "passed_data", policyId, "error", err)
+		return false, err
+	}
+
+	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
+		slog.WarnContext(ctx, "PolicyRepository.Delete(): no Policies were deleted. Reason: Policy to delete not found", "passed_data", policyId)
+		return false, errors.New("no Policies were deleted")
+	}
+
+	return true, nil
+}
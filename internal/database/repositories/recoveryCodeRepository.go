@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+// RecoveryCodeRepository codes belong to users - users are checked by tenant-scoped UserRepository first
+type RecoveryCodeRepository struct {
+	connection *gorm.DB
+}
+
+func NewRecoveryCodeRepositoryPostgres(connection *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{connection: connection}
+}
+
+// ReplaceRecoveryCodes deletes all codes of the user and creates passed ones in one transaction
+func (r *RecoveryCodeRepository) ReplaceRecoveryCodes(ctx context.Context, userId int, recoveryCodes []models.RecoveryCode) error {
+	err := r.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(recoveryCodes) == 0 {
+			return nil
+		}
+
+		return tx.Omit("used_at", "created_at").Create(&recoveryCodes).Error
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "RecoveryCodeRepository.ReplaceRecoveryCodes(): error occured during Recovery Codes replacement", "user_id", userId, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks unused code as used. `false` - code is not found or was already used
+func (r *RecoveryCodeRepository) UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error) {
+	result := r.connection.WithContext(ctx).
+		Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", time.Now())
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "RecoveryCodeRepository.UseRecoveryCode(): error occured during Recovery Code use", "user_id", userId, "error", err)
+		return false, err
+	}
+
+	return result.RowsAffected == 1, nil
+}
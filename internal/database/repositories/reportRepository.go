@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+// ReportRepository analytics over bookings. All aggregation is done by the database.
+// For organization only its own rooms are reported (bookings of other organizations in shared rooms are included)
+type ReportRepository struct {
+	connection     *gorm.DB
+	organizationId int
+}
+
+func NewReportRepositoryPostgres(connection *gorm.DB) *ReportRepository {
+	return &ReportRepository{connection: connection}
+}
+
+// ForOrganization returns repository which reports only rooms of the organization
+func (r *ReportRepository) ForOrganization(organizationId int) *ReportRepository {
+	return &ReportRepository{connection: r.connection, organizationId: organizationId}
+}
+
+// reportRoomsQuery active rooms of the report with location and time zone (rooms without floor are treated as UTC)
+const reportRoomsQuery = `
+WITH report_rooms AS (
+    SELECT rooms.room_id, rooms.number, locations.location_id, COALESCE(locations.time_zone, 'UTC') AS time_zone
+    FROM rooms
+    LEFT JOIN floors ON floors.floor_id = rooms.floor_id
+    LEFT JOIN buildings ON buildings.building_id = floors.building_id
+    LEFT JOIN locations ON locations.location_id = buildings.location_id
+    WHERE rooms.active = true
+      AND (@room_id = 0 OR rooms.room_id = @room_id)
+      AND (@organization_id = 0 OR rooms.organization_id = @organization_id)
+)`
+
+// bookedByRole booker of the booking has the role of the filter
+const bookedByRole = `(@role_id = 0 OR EXISTS (SELECT 1 FROM users WHERE users.user_id = bookings.user_id AND users.role_id = @role_id))`
+
+// utilizationQuery opening hours of every local day of the range are turned into UTC intervals (DST is resolved by
+// AT TIME ZONE for the very day), bookings are clipped to them. Location without rules is open all day long
+const utilizationQuery = reportRoomsQuery + `,
+report_days AS (
+    SELECT report_rooms.room_id, report_rooms.location_id, report_rooms.time_zone, report_day::date AS day
+    FROM report_rooms,
+         generate_series((CAST(@from AS timestamptz) AT TIME ZONE report_rooms.time_zone)::date,
+                         (CAST(@to AS timestamptz) AT TIME ZONE report_rooms.time_zone)::date,
+                         interval '1 day') AS report_day
+),
+opening_intervals AS (
+    SELECT report_days.room_id, report_days.day,
+           (report_days.day + opening_hours.opens_at::time) AT TIME ZONE report_days.time_zone AS opens_at,
+           (report_days.day + opening_hours.closes_at::time) AT TIME ZONE report_days.time_zone AS closes_at
+    FROM report_days
+    JOIN opening_hours ON opening_hours.location_id = report_days.location_id
+                      AND opening_hours.weekday = EXTRACT(DOW FROM report_days.day)
+    UNION ALL
+    SELECT report_days.room_id, report_days.day,
+           report_days.day::timestamp AT TIME ZONE report_days.time_zone,
+           (report_days.day + 1)::timestamp AT TIME ZONE report_days.time_zone
+    FROM report_days
+    WHERE NOT EXISTS (SELECT 1 FROM opening_hours WHERE opening_hours.location_id = report_days.location_id)
+),
+open_intervals AS (
+    SELECT room_id, day, GREATEST(opens_at, @from) AS opens_at, LEAST(closes_at, @to) AS closes_at
+    FROM opening_intervals
+    WHERE opens_at < @to AND closes_at > @from
+)
+SELECT report_rooms.room_id, report_rooms.number AS room_number,
+       to_char(date_trunc(@period, open_intervals.day::timestamp), 'YYYY-MM-DD') AS period_start,
+       (SUM(EXTRACT(EPOCH FROM (open_intervals.closes_at - open_intervals.opens_at))) / 60)::int AS open_minutes,
+       (COALESCE(SUM(booked.seconds), 0) / 60)::int AS booked_minutes
+FROM open_intervals
+JOIN report_rooms ON report_rooms.room_id = open_intervals.room_id
+LEFT JOIN LATERAL (
+    SELECT SUM(EXTRACT(EPOCH FROM (LEAST(bookings.datetime_end, open_intervals.closes_at) - GREATEST(bookings.datetime_start, open_intervals.opens_at)))) AS seconds
+    FROM bookings
+    WHERE bookings.room_id = open_intervals.room_id AND bookings.active = true
+      AND bookings.datetime_start < open_intervals.closes_at AND bookings.datetime_end > open_intervals.opens_at
+      AND ` + bookedByRole + `
+) AS booked ON true
+GROUP BY report_rooms.room_id, report_rooms.number, period_start
+ORDER BY report_rooms.room_id, period_start`
+
+// peakHoursQuery every hour of the week (local time of the room) a booking occupies the room counts once
+const peakHoursQuery = reportRoomsQuery + `
+SELECT EXTRACT(DOW FROM occupied.started_at AT TIME ZONE report_rooms.time_zone)::int AS weekday,
+       EXTRACT(HOUR FROM occupied.started_at AT TIME ZONE report_rooms.time_zone)::int AS hour,
+       COUNT(*) AS bookings
+FROM bookings
+JOIN report_rooms ON report_rooms.room_id = bookings.room_id
+CROSS JOIN LATERAL generate_series(date_trunc('hour', GREATEST(bookings.datetime_start, @from)),
+                                   LEAST(bookings.datetime_end, @to) - interval '1 second',
+                                   interval '1 hour') AS occupied(started_at)
+WHERE bookings.active = true AND bookings.datetime_start < @to AND bookings.datetime_end > @from
+  AND ` + bookedByRole + `
+GROUP BY weekday, hour
+ORDER BY weekday, hour`
+
+// bookingStatsQuery bookings starting within the range. No-show - booking is over and nobody checked in on the room display
+const bookingStatsQuery = reportRoomsQuery + `
+SELECT report_rooms.room_id, report_rooms.number AS room_number,
+       COUNT(*) AS total_bookings,
+       COUNT(*) FILTER (WHERE bookings.active = false) AS cancelled_bookings,
+       COUNT(*) FILTER (WHERE bookings.active = true AND bookings.datetime_end <= @now AND bookings.checked_in_at IS NULL) AS no_show_bookings,
+       COALESCE(AVG(EXTRACT(EPOCH FROM (bookings.datetime_end - bookings.datetime_start)) / 60) FILTER (WHERE bookings.active = true), 0) AS average_duration_minutes
+FROM bookings
+JOIN report_rooms ON report_rooms.room_id = bookings.room_id
+WHERE bookings.datetime_start >= @from AND bookings.datetime_start < @to
+  AND ` + bookedByRole + `
+GROUP BY report_rooms.room_id, report_rooms.number
+ORDER BY report_rooms.room_id`
+
+// topBookersQuery only users of the organization are listed
+const topBookersQuery = reportRoomsQuery + `
+SELECT users.user_id, users.name, users.email,
+       COUNT(*) AS bookings,
+       (SUM(EXTRACT(EPOCH FROM (bookings.datetime_end - bookings.datetime_start))) / 60)::int AS booked_minutes
+FROM bookings
+JOIN report_rooms ON report_rooms.room_id = bookings.room_id
+JOIN users ON users.user_id = bookings.user_id
+WHERE bookings.active = true AND bookings.datetime_start >= @from AND bookings.datetime_start < @to
+  AND (@role_id = 0 OR users.role_id = @role_id)
+  AND (@organization_id = 0 OR users.organization_id = @organization_id)
+GROUP BY users.user_id, users.name, users.email
+ORDER BY booked_minutes DESC, bookings DESC, users.user_id
+LIMIT @limit`
+
+func (r *ReportRepository) GetUtilization(ctx context.Context, filter models.ReportFilter) ([]models.RoomUtilization, error) {
+	var utilization []models.RoomUtilization
+
+	result := r.connection.WithContext(ctx).Raw(utilizationQuery, r.namedArgs(filter)...).Scan(&utilization)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "ReportRepository.GetUtilization(): error occured during utilization calculation", "passed_data", filter, "error", err)
+		return nil, err
+	}
+
+	return utilization, nil
+}
+
+func (r *ReportRepository) GetPeakHours(ctx context.Context, filter models.ReportFilter) ([]models.PeakHour, error) {
+	var peakHours []models.PeakHour
+
+	result := r.connection.WithContext(ctx).Raw(peakHoursQuery, r.namedArgs(filter)...).Scan(&peakHours)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "ReportRepository.GetPeakHours(): error occured during peak hours calculation", "passed_data", filter, "error", err)
+		return nil, err
+	}
+
+	return peakHours, nil
+}
+
+func (r *ReportRepository) GetBookingStats(ctx context.Context, filter models.ReportFilter, now time.Time) ([]models.RoomBookingStats, error) {
+	var bookingStats []models.RoomBookingStats
+
+	result := r.connection.WithContext(ctx).Raw(bookingStatsQuery, append(r.namedArgs(filter), sql.Named("now", now))...).Scan(&bookingStats)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "ReportRepository.GetBookingStats(): error occured during booking statistics calculation", "passed_data", filter, "now", now, "error", err)
+		return nil, err
+	}
+
+	return bookingStats, nil
+}
+
+func (r *ReportRepository) GetTopBookers(ctx context.Context, filter models.ReportFilter) ([]models.TopBooker, error) {
+	var topBookers []models.TopBooker
+
+	result := r.connection.WithContext(ctx).Raw(topBookersQuery, r.namedArgs(filter)...).Scan(&topBookers)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "ReportRepository.GetTopBookers(): error occured during top bookers calculation", "passed_data", filter, "error", err)
+		return nil, err
+	}
+
+	return topBookers, nil
+}
+
+func (r *ReportRepository) namedArgs(filter models.ReportFilter) []interface{} {
+	return []interface{}{
+		sql.Named("from", filter.DateTimeStart),
+		sql.Named("to", filter.DateTimeEnd),
+		sql.Named("room_id", filter.RoomId),
+		sql.Named("role_id", filter.RoleId),
+		sql.Named("organization_id", r.organizationId),
+		sql.Named("period", filter.Period),
+		sql.Named("limit", filter.Limit),
+	}
+}
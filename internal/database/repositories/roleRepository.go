@@ -1,85 +1,99 @@
 package repositories
 
 import (
+	"context"
 	"go-booking-system/internal/models"
 	"gorm.io/gorm"
-	"log"
+	"log/slog"
 	"time"
 )
 
 type RoleRepository struct {
-	connection *gorm.DB
+	connection     *gorm.DB
+	organizationId int
 }
 
 func NewRoleRepositoryPostgres(connection *gorm.DB) *RoleRepository {
 	return &RoleRepository{connection: connection}
 }
 
-func (r *RoleRepository) Create(role models.Role) (models.Role, error) {
-	result := r.connection.
+// ForOrganization returns repository which reads and writes only records of the organization
+func (r *RoleRepository) ForOrganization(organizationId int) *RoleRepository {
+	return &RoleRepository{connection: r.connection, organizationId: organizationId}
+}
+
+func (r *RoleRepository) scoped(ctx context.Context) *gorm.DB {
+	return r.connection.WithContext(ctx).Scopes(byOrganizationOrSystem("roles", r.organizationId))
+}
+
+func (r *RoleRepository) Create(ctx context.Context, role models.Role) (models.Role, error) {
+	if r.organizationId != SystemOrganizationId {
+		role.OrganizationId = r.organizationId
+	}
+
+	result := r.connection.WithContext(ctx).
 		Omit("updated_at", "deleted_at").
 		Create(&role)
 
 	if err := result.Error; err != nil {
-		log.Println("RoleRepository.Create(): error occured during Role creation. Passed data: ", role)
-		log.Println(err)
+		slog.ErrorContext(ctx, "RoleRepository.Create(): error occured during Role creation", "passed_data", role, "error", err)
 		return models.Role{}, err
 	}
 
 	return role, nil
 }
 
-func (r *RoleRepository) GetAll() []models.Role {
+func (r *RoleRepository) GetAll(ctx context.Context) []models.Role {
 	var allRoles []models.Role
 
-	r.connection.Find(&allRoles)
+	r.scoped(ctx).Find(&allRoles)
 
 	return allRoles
 }
 
-func (r *RoleRepository) GetRoleById(roleId int) (models.Role, error) {
+func (r *RoleRepository) GetRoleById(ctx context.Context, roleId int) (models.Role, error) {
 	var foundRole models.Role
 
-	result := r.connection.Find(&foundRole, "role_id", roleId)
+	result := r.scoped(ctx).Find(&foundRole, "role_id", roleId)
 	if err := result.Error; err != nil {
-		log.Println("RoleRepository.GetRoleById(): error occured during Role search. Passed data: ", roleId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "RoleRepository.GetRoleById(): error occured during Role search", "passed_data", roleId, "error", err)
 		return models.Role{}, err
 	}
 
 	return foundRole, nil
 }
 
-func (r *RoleRepository) Update(role models.Role) (models.Role, error) {
-	result := r.connection.
-		Omit("active", "created_at", "deleted_at").
+func (r *RoleRepository) Update(ctx context.Context, role models.Role) (models.Role, error) {
+	result := r.connection.WithContext(ctx).
+		Scopes(byOrganization("roles", r.organizationId)).
+		Omit("organization_id", "active", "created_at", "deleted_at").
 		Model(&role).
 		Updates(&role)
 
 	if err := result.Error; err != nil {
-		log.Println("RoleRepository.Update(): error occured during Role update. Passed data: ", role)
+		slog.ErrorContext(ctx, "RoleRepository.Update(): error occured during Role update", "passed_data", role, "error", err)
 		return role, err
 	}
 
 	return role, nil
 }
 
-func (r *RoleRepository) Delete(roleId int) (bool, error) {
+func (r *RoleRepository) Delete(ctx context.Context, roleId int) (bool, error) {
 	roleToDelete := models.Role{
 		RoleId:    roleId,
 		Active:    false,
 		DeletedAt: time.Now(),
 	}
 
-	result := r.connection.
+	result := r.connection.WithContext(ctx).
+		Scopes(byOrganization("roles", r.organizationId)).
 		Select("*").
-		Omit("created_at", "updated_at", "name", "description").
+		Omit("organization_id", "created_at", "updated_at", "name", "description").
 		Model(&roleToDelete).
 		Updates(&roleToDelete)
 
 	if err := result.Error; err != nil {
-		log.Println("RoleRepository.Delete(): error occured during Role deletion. Passed data: ", roleId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "RoleRepository.Delete(): error occured during Role deletion", "passed_data", roleId, "error", err)
 		return false, err
 	}
 
@@ -1,103 +1,181 @@
 package repositories
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"go-booking-system/internal/models"
 	"gorm.io/gorm"
-	"log"
+	"log/slog"
 	"time"
 )
 
 type RoomRepository struct {
-	connection *gorm.DB
+	connection     *gorm.DB
+	organizationId int
 }
 
 func NewRoomRepositoryPostgres(connection *gorm.DB) *RoomRepository {
 	return &RoomRepository{connection: connection}
 }
 
-func (r *RoomRepository) Create(room models.Room) (models.Room, error) {
-	result := r.connection.
+// ForOrganization returns repository which reads and writes only records of the organization
+func (r *RoomRepository) ForOrganization(organizationId int) *RoomRepository {
+	return &RoomRepository{connection: r.connection, organizationId: organizationId}
+}
+
+func (r *RoomRepository) scoped(ctx context.Context) *gorm.DB {
+	return r.connection.WithContext(ctx).Scopes(roomsVisibleToOrganization(r.organizationId))
+}
+
+func (r *RoomRepository) Create(ctx context.Context, room models.Room) (models.Room, error) {
+	if r.organizationId != SystemOrganizationId {
+		room.OrganizationId = r.organizationId
+	}
+
+	result := r.connection.WithContext(ctx).
 		Omit("room_id", "updated_at", "deleted_at").
-		Select("number", "capacity", "created_by").
+		Select("organization_id", "number", "capacity", "floor_id", "created_by").
 		Create(&room)
 
 	if err := result.Error; err != nil {
-		log.Println("RoomRepository.Create(): error occured during Room creation. Passed data: ", room)
-		log.Println(err)
+		slog.ErrorContext(ctx, "RoomRepository.Create(): error occured during Room creation", "passed_data", room, "error", err)
 		return models.Room{}, err
 	}
 
 	return room, nil
 }
 
-func (r *RoomRepository) GetAll() []models.Room {
+func (r *RoomRepository) GetAll(ctx context.Context) []models.Room {
 	var allRooms []models.Room
 
-	r.connection.Find(&allRooms)
+	r.scoped(ctx).Find(&allRooms)
 
 	return allRooms
 }
 
-func (r *RoomRepository) GetRoomById(roomId int) (models.Room, error) {
+func (r *RoomRepository) GetRoomById(ctx context.Context, roomId int) (models.Room, error) {
 	var foundRoom models.Room
 
-	result := r.connection.Find(&foundRoom, "room_id", roomId)
+	result := r.scoped(ctx).Find(&foundRoom, "room_id", roomId)
 	if err := result.Error; err != nil {
-		log.Println("RoomRepository.GetRoomById(): error occured during Room search. Passed data: ", roomId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "RoomRepository.GetRoomById(): error occured during Room search", "passed_data", roomId, "error", err)
 		return models.Room{}, err
 	}
 
 	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
-		log.Println("RoomRepository.GetRoomById(): no Rooms were found. Passed data: ", roomId)
+		slog.WarnContext(ctx, "RoomRepository.GetRoomById(): no Rooms were found", "passed_data", roomId)
 		return models.Room{}, errors.New("no Rooms were found")
 	}
 
 	return foundRoom, nil
 }
 
-func (r *RoomRepository) Update(room models.Room) (models.Room, error) {
-	result := r.connection.
-		Omit("active", "created_at", "deleted_at").
+// GetRoomsByLocationId returns rooms of all floors of all buildings of the location
+func (r *RoomRepository) GetRoomsByLocationId(ctx context.Context, locationId int) ([]models.Room, error) {
+	var foundRooms []models.Room
+
+	result := r.scoped(ctx).
+		Select("rooms.*").
+		Joins("JOIN floors ON floors.floor_id = rooms.floor_id").
+		Joins("JOIN buildings ON buildings.building_id = floors.building_id").
+		Where("buildings.location_id = ?", locationId).
+		Find(&foundRooms)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "RoomRepository.GetRoomsByLocationId(): error occured during Rooms search by LocationId", "passed_data", locationId, "error", err)
+		return nil, err
+	}
+
+	return foundRooms, nil
+}
+
+// GetAvailableRoomsByLocationId returns active rooms of the location without active bookings overlapping [start; end]
+func (r *RoomRepository) GetAvailableRoomsByLocationId(ctx context.Context, locationId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Room, error) {
+	var availableRooms []models.Room
+
+	result := r.scoped(ctx).
+		Select("rooms.*").
+		Joins("JOIN floors ON floors.floor_id = rooms.floor_id").
+		Joins("JOIN buildings ON buildings.building_id = floors.building_id").
+		Where("buildings.location_id = @location_id AND rooms.active = true", sql.Named("location_id", locationId)).
+		Where(`NOT EXISTS (
+		    SELECT 1 FROM bookings
+		    WHERE bookings.room_id = rooms.room_id
+		      AND bookings.active = true
+		      AND bookings.datetime_start < @datetime_end
+		      AND bookings.datetime_end > @datetime_start)`,
+			sql.Named("datetime_start", dateTimeStart),
+			sql.Named("datetime_end", dateTimeEnd)).
+		Find(&availableRooms)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "RoomRepository.GetAvailableRoomsByLocationId(): error occured during available Rooms search", "location_id", locationId, "date_time_start", dateTimeStart, "date_time_end", dateTimeEnd, "error", err)
+		return nil, err
+	}
+
+	return availableRooms, nil
+}
+
+// GetRoomByNumber searches only among rooms owned by the organization - shared rooms are not matched
+func (r *RoomRepository) GetRoomByNumber(ctx context.Context, number string) (models.Room, error) {
+	var foundRoom models.Room
+
+	result := r.connection.WithContext(ctx).
+		Scopes(byOrganization("rooms", r.organizationId)).
+		Where(`"active"=?`, true).
+		Find(&foundRoom, "number", number)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "RoomRepository.GetRoomByNumber(): error occured during Room search", "passed_data", number, "error", err)
+		return models.Room{}, err
+	}
+
+	return foundRoom, nil
+}
+
+func (r *RoomRepository) Update(ctx context.Context, room models.Room) (models.Room, error) {
+	result := r.connection.WithContext(ctx).
+		Scopes(byOrganization("rooms", r.organizationId)).
+		Omit("organization_id", "active", "created_at", "deleted_at").
 		Model(&room).
 		Updates(&room)
 
 	if err := result.Error; err != nil {
-		log.Println("RoomRepository.Update(): error occured during Room update. Passed data: ", room)
+		slog.ErrorContext(ctx, "RoomRepository.Update(): error occured during Room update", "passed_data", room, "error", err)
 		return room, err
 	}
 
 	if rowsUpdated := result.RowsAffected; rowsUpdated == 0 {
-		log.Println("RoomRepository.Update(): no Rooms were updated. Reason: Room to update not found. Passed data: ", room)
+		slog.WarnContext(ctx, "RoomRepository.Update(): no Rooms were updated. Reason: Room to update not found", "passed_data", room)
 		return room, errors.New("no Rooms were updated")
 	}
 
 	return room, nil
 }
 
-func (r *RoomRepository) Delete(roomId int) (bool, error) {
+func (r *RoomRepository) Delete(ctx context.Context, roomId int) (bool, error) {
 	roomToDelete := models.Room{
 		RoomId:    roomId,
 		Active:    false,
 		DeletedAt: time.Now(),
 	}
 
-	result := r.connection.
+	result := r.connection.WithContext(ctx).
+		Scopes(byOrganization("rooms", r.organizationId)).
 		Select("*").
 		Where(`"active"=?`, true).
-		Omit("number", "capacity", "created_by", "created_at", "updated_at").
+		Omit("organization_id", "number", "capacity", "floor_id", "created_by", "created_at", "updated_at").
 		Model(&roomToDelete).
 		Updates(&roomToDelete)
 
 	if err := result.Error; err != nil {
-		log.Println("RoomRepository.Delete(): error occured during Room deletion. Passed data: ", roomId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "RoomRepository.Delete(): error occured during Room deletion", "passed_data", roomId, "error", err)
 		return false, err
 	}
 
 	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
-		log.Println("RoomRepository.Delete(): no Rooms were deleted. Reason: Room to delete not found. Passed data: ", roomId)
+		slog.WarnContext(ctx, "RoomRepository.Delete(): no Rooms were deleted. Reason: Room to delete not found", "passed_data", roomId)
 		return false, errors.New("no Rooms were deleted")
 	}
 
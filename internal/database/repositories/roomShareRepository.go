@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+type RoomShareRepository struct {
+	connection     *gorm.DB
+	organizationId int
+}
+
+func NewRoomShareRepositoryPostgres(connection *gorm.DB) *RoomShareRepository {
+	return &RoomShareRepository{connection: connection}
+}
+
+// ForOrganization returns repository which reads shares of rooms owned by the organization and shares with it, and
+// writes only shares of rooms owned by the organization
+func (r *RoomShareRepository) ForOrganization(organizationId int) *RoomShareRepository {
+	return &RoomShareRepository{connection: r.connection, organizationId: organizationId}
+}
+
+func (r *RoomShareRepository) scoped(ctx context.Context) *gorm.DB {
+	if r.organizationId == SystemOrganizationId {
+		return r.connection.WithContext(ctx)
+	}
+
+	return r.connection.WithContext(ctx).
+		Where(`(room_shares.organization_id = @organization_id OR EXISTS (
+		    SELECT 1 FROM rooms
+		    WHERE rooms.room_id = room_shares.room_id
+		      AND rooms.organization_id = @organization_id))`,
+			sql.Named("organization_id", r.organizationId))
+}
+
+func (r *RoomShareRepository) owned(ctx context.Context) *gorm.DB {
+	if r.organizationId == SystemOrganizationId {
+		return r.connection.WithContext(ctx)
+	}
+
+	return r.connection.WithContext(ctx).
+		Where(`EXISTS (
+		    SELECT 1 FROM rooms
+		    WHERE rooms.room_id = room_shares.room_id
+		      AND rooms.organization_id = @organization_id)`,
+			sql.Named("organization_id", r.organizationId))
+}
+
+func (r *RoomShareRepository) Create(ctx context.Context, roomShare models.RoomShare) (models.RoomShare, error) {
+	isOwned, err := isOwnedByOrganization(ctx, r.connection, "rooms", "room_id", roomShare.RoomId, r.organizationId)
+	if err != nil {
+		slog.ErrorContext(ctx, "RoomShareRepository.Create(): error occured during Room search", "passed_data", roomShare, "error", err)
+		return models.RoomShare{}, err
+	}
+	if !isOwned {
+		slog.WarnContext(ctx, "RoomShareRepository.Create(): room belongs to another organization", "passed_data", roomShare)
+		return models.RoomShare{}, errors.New("room belongs to another organization")
+	}
+
+	result := r.connection.WithContext(ctx).
+		Omit("updated_at", "deleted_at").
+		Select("room_id", "organization_id", "quota_minutes", "active", "created_by").
+		Create(&roomShare)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "RoomShareRepository.Create(): error occured during RoomShare creation", "passed_data", roomShare, "error", err)
+		return models.RoomShare{}, err
+	}
+
+	return roomShare, nil
+}
+
+func (r *RoomShareRepository) GetRoomSharesByRoomId(ctx context.Context, roomId int) ([]models.RoomShare, error) {
+	var foundRoomShares []models.RoomShare
+
+	result := r.scoped(ctx).Where(`"active"=?`, true).Find(&foundRoomShares, "room_id", roomId)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "RoomShareRepository.GetRoomSharesByRoomId(): error occured during RoomShares search by RoomId", "passed_data", roomId, "error", err)
+		return nil, err
+	}
+
+	return foundRoomShares, nil
+}
+
+func (r *RoomShareRepository) GetRoomShare(ctx context.Context, roomId int, organizationId int) (models.RoomShare, error) {
+	var foundRoomShare models.RoomShare
+
+	result := r.scoped(ctx).
+		Where(`"active"=? AND "room_id"=? AND "organization_id"=?`, true, roomId, organizationId).
+		Find(&foundRoomShare)
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "RoomShareRepository.GetRoomShare(): error occured during RoomShare search", "room_id", roomId, "organization_id", organizationId, "error", err)
+		return models.RoomShare{}, err
+	}
+
+	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
+		slog.WarnContext(ctx, "RoomShareRepository.GetRoomShare(): no RoomShares were found", "room_id", roomId, "organization_id", organizationId)
+		return models.RoomShare{}, errors.New("no RoomShares were found")
+	}
+
+	return foundRoomShare, nil
+}
+
+// GetBookedMinutes returns total duration of active bookings of the organization in the room within [from; to)
+func (r *RoomShareRepository) GetBookedMinutes(ctx context.Context, roomId int, organizationId int, from time.Time, to time.Time) (int, error) {
+	var bookedMinutes int
+
+	result := r.connection.WithContext(ctx).
+		Table("bookings").
+		Select("COALESCE(SUM(EXTRACT(EPOCH FROM (datetime_end - datetime_start)) / 60), 0)::int").
+		Where(`room_id = @room_id AND organization_id = @organization_id AND active = true
+		    AND datetime_start >= @from AND datetime_start < @to`,
+			sql.Named("room_id", roomId),
+			sql.Named("organization_id", organizationId),
+			sql.Named("from", from),
+			sql.Named("to", to)).
+		Scan(&bookedMinutes)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "RoomShareRepository.GetBookedMinutes(): error occured during booked minutes calculation", "room_id", roomId, "organization_id", organizationId, "from", from, "to", to, "error", err)
+		return 0, err
+	}
+
+	return bookedMinutes, nil
+}
+
+func (r *RoomShareRepository) Delete(ctx context.Context, roomId int, organizationId int) (bool, error) {
+	roomShareToDelete := models.RoomShare{
+		Active:    false,
+		DeletedAt: time.Now(),
+	}
+
+	result := r.owned(ctx).
+		Model(&models.RoomShare{}).
+		Where(`"active"=? AND "room_id"=? AND "organization_id"=?`, true, roomId, organizationId).
+		Select("active", "deleted_at").
+		Updates(&roomShareToDelete)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "RoomShareRepository.Delete(): error occured during RoomShare deletion", "room_id", roomId, "organization_id", organizationId, "error", err)
+		return false, err
+	}
+
+	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
+		slog.WarnContext(ctx, "RoomShareRepository.Delete(): no RoomShares were deleted. Reason: RoomShare to delete not found", "room_id", roomId, "organization_id", organizationId)
+		return false, errors.New("no RoomShares were deleted")
+	}
+
+	return true, nil
+}
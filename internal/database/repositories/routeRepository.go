@@ -1,9 +1,10 @@
 package repositories
 
 import (
+	"context"
 	"go-booking-system/internal/models"
 	"gorm.io/gorm"
-	"log"
+	"log/slog"
 	"time"
 )
 
@@ -15,84 +16,80 @@ func NewRouteRepositoryPostgres(connection *gorm.DB) *RouteRepository {
 	return &RouteRepository{connection: connection}
 }
 
-func (r *RouteRepository) Create(route models.Route) (models.Route, error) {
-	result := r.connection.
+func (r *RouteRepository) Create(ctx context.Context, route models.Route) (models.Route, error) {
+	result := r.connection.WithContext(ctx).
 		Omit("updated_at", "deleted_at").
 		Create(&route)
 
 	if err := result.Error; err != nil {
-		log.Println("RouteRepository.Create(): error occured during Route creation. Passed data: ", route)
-		log.Println(err)
+		slog.ErrorContext(ctx, "RouteRepository.Create(): error occured during Route creation", "passed_data", route, "error", err)
 		return models.Route{}, err
 	}
 
 	return route, nil
 }
 
-func (r *RouteRepository) GetAll() []models.Route {
+func (r *RouteRepository) GetAll(ctx context.Context) []models.Route {
 	var allRoutes []models.Route
 
-	r.connection.Find(&allRoutes)
+	r.connection.WithContext(ctx).Find(&allRoutes)
 
 	return allRoutes
 }
 
-func (r *RouteRepository) GetRouteById(routeId int) (models.Route, error) {
+func (r *RouteRepository) GetRouteById(ctx context.Context, routeId int) (models.Route, error) {
 	var foundRoute models.Route
 
-	result := r.connection.Find(&foundRoute, "route_id", routeId)
+	result := r.connection.WithContext(ctx).Find(&foundRoute, "route_id", routeId)
 	if err := result.Error; err != nil {
-		log.Println("RouteRepository.GetRouteById(): error occured during Route search. Passed data: ", routeId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "RouteRepository.GetRouteById(): error occured during Route search", "passed_data", routeId, "error", err)
 		return models.Route{}, err
 	}
 
 	return foundRoute, nil
 }
 
-func (r *RouteRepository) GetRouteByURL(url string) (models.Route, error) {
+func (r *RouteRepository) GetRouteByURL(ctx context.Context, url string) (models.Route, error) {
 	var foundRoute models.Route
 
-	result := r.connection.Find(&foundRoute, "url", url)
+	result := r.connection.WithContext(ctx).Find(&foundRoute, "url", url)
 	if err := result.Error; err != nil {
-		log.Println("RouteRepository.GetRouteByURL(): error occured during Route search. Passed data: ", url)
-		log.Println(err)
+		slog.ErrorContext(ctx, "RouteRepository.GetRouteByURL(): error occured during Route search", "passed_data", url, "error", err)
 		return models.Route{}, err
 	}
 
 	return foundRoute, nil
 }
 
-func (r *RouteRepository) Update(route models.Route) (models.Route, error) {
-	result := r.connection.
+func (r *RouteRepository) Update(ctx context.Context, route models.Route) (models.Route, error) {
+	result := r.connection.WithContext(ctx).
 		Omit("active", "created_at", "deleted_at").
 		Model(&route).
 		Updates(&route)
 
 	if err := result.Error; err != nil {
-		log.Println("RouteRepository.Update(): error occured during Route update. Passed data: ", route)
+		slog.ErrorContext(ctx, "RouteRepository.Update(): error occured during Route update", "passed_data", route, "error", err)
 		return route, err
 	}
 
 	return route, nil
 }
 
-func (r *RouteRepository) Delete(routeId int) (bool, error) {
+func (r *RouteRepository) Delete(ctx context.Context, routeId int) (bool, error) {
 	routeToDelete := models.Route{
 		RouteId:   routeId,
 		Active:    false,
 		DeletedAt: time.Now(),
 	}
 
-	result := r.connection.
+	result := r.connection.WithContext(ctx).
 		Select("*").
 		Omit("created_at", "updated_at", "url", "description").
 		Model(&routeToDelete).
 		Updates(&routeToDelete)
 
 	if err := result.Error; err != nil {
-		log.Println("RouteRepository.Delete(): error occured during Route deletion. Passed data: ", routeId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "RouteRepository.Delete(): error occured during Route deletion", "passed_data", routeId, "error", err)
 		return false, err
 	}
 
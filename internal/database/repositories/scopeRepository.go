@@ -1,9 +1,10 @@
 package repositories
 
 import (
+	"context"
 	"go-booking-system/internal/models"
 	"gorm.io/gorm"
-	"log"
+	"log/slog"
 	"time"
 )
 
@@ -15,71 +16,68 @@ func NewScopeRepositoryPostgres(connection *gorm.DB) *ScopeRepository {
 	return &ScopeRepository{connection: connection}
 }
 
-func (r *ScopeRepository) Create(scope models.Scope) (models.Scope, error) {
-	result := r.connection.
+func (r *ScopeRepository) Create(ctx context.Context, scope models.Scope) (models.Scope, error) {
+	result := r.connection.WithContext(ctx).
 		Omit("updated_at", "deleted_at").
 		Create(&scope)
 
 	if err := result.Error; err != nil {
-		log.Println("ScopeRepository.Create(): error occured during Scope creation. Passed data: ", scope)
-		log.Println(err)
+		slog.ErrorContext(ctx, "ScopeRepository.Create(): error occured during Scope creation", "passed_data", scope, "error", err)
 		return models.Scope{}, err
 	}
 
 	return scope, nil
 }
 
-func (r *ScopeRepository) GetAll() []models.Scope {
+func (r *ScopeRepository) GetAll(ctx context.Context) []models.Scope {
 	var allScopes []models.Scope
 
-	r.connection.Find(&allScopes)
+	r.connection.WithContext(ctx).Find(&allScopes)
 
 	return allScopes
 }
 
-func (r *ScopeRepository) GetScopeById(scopeId int) (models.Scope, error) {
+func (r *ScopeRepository) GetScopeById(ctx context.Context, scopeId int) (models.Scope, error) {
 	var foundScope models.Scope
 
-	result := r.connection.Find(&foundScope, "scope_id", scopeId)
+	result := r.connection.WithContext(ctx).Find(&foundScope, "scope_id", scopeId)
 	if err := result.Error; err != nil {
-		log.Println("ScopeRepository.GetRoomById(): error occured during Scope search. Passed data: ", scopeId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "ScopeRepository.GetRoomById(): error occured during Scope search", "passed_data", scopeId, "error", err)
 		return models.Scope{}, err
 	}
 
 	return foundScope, nil
 }
 
-func (r *ScopeRepository) Update(scope models.Scope) (models.Scope, error) {
-	result := r.connection.
+func (r *ScopeRepository) Update(ctx context.Context, scope models.Scope) (models.Scope, error) {
+	result := r.connection.WithContext(ctx).
 		Omit("active", "created_at", "deleted_at").
 		Model(&scope).
 		Updates(&scope)
 
 	if err := result.Error; err != nil {
-		log.Println("ScopeRepository.Update(): error occured during Scope update. Passed data: ", scope)
+		slog.ErrorContext(ctx, "ScopeRepository.Update(): error occured during Scope update", "passed_data", scope, "error", err)
 		return scope, err
 	}
 
 	return scope, nil
 }
 
-func (r *ScopeRepository) Delete(roleId int) (bool, error) {
+func (r *ScopeRepository) Delete(ctx context.Context, roleId int) (bool, error) {
 	scopeToDelete := models.Scope{
 		ScopeId:   roleId,
 		Active:    false,
 		DeletedAt: time.Now(),
 	}
 
-	result := r.connection.
+	result := r.connection.WithContext(ctx).
 		Select("*").
 		Omit("created_at", "updated_at", "name", "description").
 		Model(&scopeToDelete).
 		Updates(&scopeToDelete)
 
 	if err := result.Error; err != nil {
-		log.Println("ScopeRepository.Delete(): error occured during Scope deletion. Passed data: ", roleId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "ScopeRepository.Delete(): error occured during Scope deletion", "passed_data", roleId, "error", err)
 		return false, err
 	}
 
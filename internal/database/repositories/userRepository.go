@@ -1,164 +1,301 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"go-booking-system/internal/models"
 	"gorm.io/gorm"
-	"log"
+	"log/slog"
 	"time"
 )
 
 type UserRepository struct {
-	connection *gorm.DB
+	connection     *gorm.DB
+	organizationId int
 }
 
-func (u *UserRepository) Create(user models.User) (models.User, error) {
-	result := u.connection.
+func (u *UserRepository) Create(ctx context.Context, user models.User) (models.User, error) {
+	if u.organizationId != SystemOrganizationId {
+		user.OrganizationId = u.organizationId
+	}
+
+	result := u.connection.WithContext(ctx).
 		Omit("updated_at", "deleted_at").
-		Select("name", "email", "telephone", "role_id", "username", "password_hash", "active").
+		Select("organization_id", "name", "email", "telephone", "role_id", "username", "password_hash", "active", "sso_issuer", "sso_subject", "auth_provider", "directory_id", "email_verified").
 		Create(&user)
 
 	if err := result.Error; err != nil {
-		log.Println("UserRepository.Create(): error occured during User creation. Passed data: ", user)
-		log.Println(err)
+		slog.ErrorContext(ctx, "UserRepository.Create(): error occured during User creation", "passed_data", user, "error", err)
 		return user, err
 	}
 
 	return user, nil
 }
 
-func (u *UserRepository) GetAll() []models.User {
+func (u *UserRepository) GetAll(ctx context.Context) []models.User {
 	var allUsers []models.User
 
-	u.connection.Find(&allUsers)
+	u.scoped(ctx).Find(&allUsers)
 
 	return allUsers
 }
 
-func (u *UserRepository) GetUserById(userId int) (models.User, error) {
+func (u *UserRepository) GetUserById(ctx context.Context, userId int) (models.User, error) {
 	var foundUser models.User
-	result := u.connection.Find(&foundUser, "user_id", userId)
+	result := u.scoped(ctx).Find(&foundUser, "user_id", userId)
 
 	if err := result.Error; err != nil {
-		log.Println("UserRepository.GetUserById(): error occured during User search. Passed data: ", userId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "UserRepository.GetUserById(): error occured during User search", "passed_data", userId, "error", err)
 		return models.User{}, err
 	}
 
 	if rowsReturned := result.RowsAffected; rowsReturned == 0 {
-		log.Println("UserRepository.GetUserById(): no Users were found. Passed data: ", userId)
+		slog.WarnContext(ctx, "UserRepository.GetUserById(): no Users were found", "passed_data", userId)
 		return models.User{}, errors.New("no Users were found")
 	}
 
 	return foundUser, nil
 }
 
-func (u *UserRepository) Update(user models.User) (models.User, error) {
-	result := u.connection.
-		Omit("active", "created_at", "deleted_at"). // `active` is changed only at DELETION
+func (u *UserRepository) Update(ctx context.Context, user models.User) (models.User, error) {
+	result := u.scoped(ctx).
+		Omit("organization_id", "active", "created_at", "deleted_at", "totp_secret", "totp_enabled", "totp_last_step", "sso_issuer", "sso_subject", "auth_provider", "directory_id", "email_verified"). // `active` is changed only at DELETION
 		Model(&user).
 		Updates(&user)
 
 	if err := result.Error; err != nil {
-		log.Println("UserRepository.Update(): error occured during User update. Passed data: ", user)
-		log.Println(err)
+		slog.ErrorContext(ctx, "UserRepository.Update(): error occured during User update", "passed_data", user, "error", err)
 		return user, err
 	}
 
 	if rowsUpdated := result.RowsAffected; rowsUpdated == 0 {
-		log.Println("UserRepository.Update(): no Users were updated. Reason: User to update not found. Passed data: ", user)
+		slog.WarnContext(ctx, "UserRepository.Update(): no Users were updated. Reason: User to update not found", "passed_data", user)
 		return user, errors.New("no Users were updated")
 	}
 
 	return user, nil
 }
 
-func (u *UserRepository) Delete(userId int) (bool, error) {
+func (u *UserRepository) Delete(ctx context.Context, userId int) (bool, error) {
 	userToDelete := models.User{
 		UserId:    userId,
 		Active:    false,
 		DeletedAt: time.Now(),
 	}
 
-	result := u.connection.
+	result := u.scoped(ctx).
 		Select("*").
 		Where(`"active"=?`, true).
-		Omit("created_at", "updated_at", "role_id", "name", "email", "telephone", "username", "password_hash").
+		Omit("organization_id", "created_at", "updated_at", "role_id", "time_zone", "floor_id", "name", "email", "telephone", "username", "password_hash", "totp_secret", "totp_enabled", "totp_last_step", "sso_issuer", "sso_subject", "auth_provider", "directory_id", "email_verified").
 		Model(&userToDelete).
 		Updates(&userToDelete)
 
 	if err := result.Error; err != nil {
-		log.Println("UserRepository.Delete(): error occured during User deletion. Passed data: ", userId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "UserRepository.Delete(): error occured during User deletion", "passed_data", userId, "error", err)
 		return false, err
 	}
 
 	if rowsDeleted := result.RowsAffected; rowsDeleted == 0 {
-		log.Println("UserRepository.Update(): no Users were deleted. Reason: User to update not found. Passed data: ", userId)
+		slog.WarnContext(ctx, "UserRepository.Update(): no Users were deleted. Reason: User to update not found", "passed_data", userId)
 		return false, errors.New("no Users were deleted")
 	}
 
 	return true, nil
 }
 
-func (u *UserRepository) UpdatePassword(user models.User) (models.User, error) {
-	result := u.connection.
-		Omit("name", "email", "telephone", "role_id", "username", "active", "created_at", "deleted_at").
+func (u *UserRepository) UpdatePassword(ctx context.Context, user models.User) (models.User, error) {
+	result := u.scoped(ctx).
+		Omit("organization_id", "name", "email", "telephone", "role_id", "time_zone", "floor_id", "username", "active", "created_at", "deleted_at", "totp_secret", "totp_enabled", "totp_last_step", "sso_issuer", "sso_subject", "auth_provider", "directory_id", "email_verified").
+		Model(&user).
+		Updates(&user)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "UserRepository.UpdatePassword(): error occured during password change", "user_id", user.UserId, "error", err)
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (u *UserRepository) UpdateUsername(ctx context.Context, user models.User) (models.User, error) {
+	result := u.scoped(ctx).
+		Omit("organization_id", "name", "email", "telephone", "role_id", "time_zone", "floor_id", "password_hash", "active", "created_at", "deleted_at", "totp_secret", "totp_enabled", "totp_last_step", "sso_issuer", "sso_subject", "auth_provider", "directory_id", "email_verified").
 		Model(&user).
 		Updates(&user)
 
 	if err := result.Error; err != nil {
-		log.Println("UserRepository.UpdatePassword(): error occured during password change. Passed data: ", user.UserId, user.Password)
-		log.Println(err)
+		slog.ErrorContext(ctx, "UserRepository.UpdateUsername(): error occured during username change", "user_id", user.UserId, "user_name", user.UserName, "error", err)
 		return models.User{}, err
 	}
 
 	return user, nil
 }
 
-func (u *UserRepository) UpdateUsername(user models.User) (models.User, error) {
-	result := u.connection.
-		Omit("name", "email", "telephone", "role_id", "password_hash", "active", "created_at", "deleted_at").
+func (u *UserRepository) UpdateUserRole(ctx context.Context, user models.User) (models.User, error) {
+	result := u.scoped(ctx).
+		Omit("organization_id", "name", "email", "telephone", "time_zone", "floor_id", "username", "password_hash", "active", "created_at", "deleted_at", "totp_secret", "totp_enabled", "totp_last_step", "sso_issuer", "sso_subject", "auth_provider", "directory_id", "email_verified").
 		Model(&user).
 		Updates(&user)
 
 	if err := result.Error; err != nil {
-		log.Println("UserRepository.UpdateUsername(): error occured during username change. Passed data: ", user.UserId, user.UserName)
-		log.Println(err)
+		slog.ErrorContext(ctx, "UserRepository.UpdateUserRole(): error occured during user's role change", "user_id", user.UserId, "role_id", user.RoleId, "error", err)
 		return models.User{}, err
 	}
 
 	return user, nil
 }
 
-func (u *UserRepository) UpdateUserRole(user models.User) (models.User, error) {
-	result := u.connection.
-		Omit("name", "email", "telephone", "username", "password_hash", "active", "created_at", "deleted_at").
+// UpdateTOTP sets secret, state and last step of TOTP. Empty secret and `false` are written too - TOTP is disabled
+func (u *UserRepository) UpdateTOTP(ctx context.Context, user models.User) (models.User, error) {
+	result := u.scoped(ctx).
 		Model(&user).
+		Select("totp_secret", "totp_enabled", "totp_last_step").
 		Updates(&user)
 
 	if err := result.Error; err != nil {
-		log.Println("UserRepository.UpdateUserRole(): error occured during user's role change. Passed data: ", user.UserId, user.RoleId)
-		log.Println(err)
+		slog.ErrorContext(ctx, "UserRepository.UpdateTOTP(): error occured during TOTP change", "user_id", user.UserId, "error", err)
 		return models.User{}, err
 	}
 
+	if rowsUpdated := result.RowsAffected; rowsUpdated == 0 {
+		slog.WarnContext(ctx, "UserRepository.UpdateTOTP(): no Users were updated. Reason: User to update not found", "user_id", user.UserId)
+		return models.User{}, errors.New("no Users were updated")
+	}
+
 	return user, nil
 }
 
-func (u *UserRepository) GetUserByUsername(username string) (models.User, error) {
+// UpdateTOTPLastStep stores step of accepted code only if it is newer than the stored one. `false` - the code (or an older
+// one) was already used, e.g. by a concurrent request
+func (u *UserRepository) UpdateTOTPLastStep(ctx context.Context, userId int, step int64) (bool, error) {
+	result := u.scoped(ctx).
+		Model(&models.User{}).
+		Where("user_id = ? AND totp_last_step < ?", userId, step).
+		Update("totp_last_step", step)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "UserRepository.UpdateTOTPLastStep(): error occured during TOTP step update", "user_id", userId, "error", err)
+		return false, err
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (u *UserRepository) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
 	var foundUserByUsername models.User
-	result := u.connection.Find(&foundUserByUsername, "username", username)
+	result := u.scoped(ctx).Find(&foundUserByUsername, "username", username)
 
 	if err := result.Error; err != nil {
-		log.Println("UserRepository.GetUserByUsername(): error occured during User search. Passed data: ", username)
-		log.Println(err)
+		slog.ErrorContext(ctx, "UserRepository.GetUserByUsername(): error occured during User search", "passed_data", username, "error", err)
 		return models.User{}, err
 	}
 
 	return foundUserByUsername, nil
 }
 
+func (u *UserRepository) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	var foundUserByEmail models.User
+	result := u.scoped(ctx).Where(`"active"=?`, true).Find(&foundUserByEmail, "email", email)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "UserRepository.GetUserByEmail(): error occured during User search", "passed_data", email, "error", err)
+		return models.User{}, err
+	}
+
+	return foundUserByEmail, nil
+}
+
+// UpdateSSOSubject links the user to the user of identity provider
+func (u *UserRepository) UpdateSSOSubject(ctx context.Context, user models.User) (models.User, error) {
+	result := u.scoped(ctx).
+		Model(&user).
+		Select("sso_issuer", "sso_subject").
+		Updates(&user)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "UserRepository.UpdateSSOSubject(): error occured during link to identity provider", "user_id", user.UserId, "error", err)
+		return models.User{}, err
+	}
+
+	if rowsUpdated := result.RowsAffected; rowsUpdated == 0 {
+		slog.WarnContext(ctx, "UserRepository.UpdateSSOSubject(): no Users were updated. Reason: User to update not found", "user_id", user.UserId)
+		return models.User{}, errors.New("no Users were updated")
+	}
+
+	return user, nil
+}
+
+// GetUserBySSOSubject returns user linked to the user of identity provider. Empty user - not linked yet
+func (u *UserRepository) GetUserBySSOSubject(ctx context.Context, issuer string, subject string) (models.User, error) {
+	var foundUser models.User
+	result := u.scoped(ctx).Where("sso_issuer = ? AND sso_subject = ?", issuer, subject).Find(&foundUser)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "UserRepository.GetUserBySSOSubject(): error occured during User search", "passed_data", subject, "error", err)
+		return models.User{}, err
+	}
+
+	return foundUser, nil
+}
+
+// UpdateActive activates or deactivates the user - users of the directory follow state of their accounts
+func (u *UserRepository) UpdateActive(ctx context.Context, userId int, active bool) (bool, error) {
+	var deletedAt *time.Time
+	if !active {
+		now := time.Now()
+		deletedAt = &now
+	}
+
+	result := u.scoped(ctx).
+		Model(&models.User{}).
+		Where("user_id = ? AND active <> ?", userId, active).
+		Updates(map[string]any{"active": active, "deleted_at": deletedAt})
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "UserRepository.UpdateActive(): error occured during change of User state", "user_id", userId, "active", active, "error", err)
+		return false, err
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// UpdateEmailVerified `false` - the user is not found
+func (u *UserRepository) UpdateEmailVerified(ctx context.Context, userId int, emailVerified bool) (bool, error) {
+	result := u.scoped(ctx).
+		Model(&models.User{}).
+		Where("user_id = ?", userId).
+		Update("email_verified", emailVerified)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "UserRepository.UpdateEmailVerified(): error occured during change of email verification", "user_id", userId, "error", err)
+		return false, err
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// GetUsersByAuthProvider returns active and inactive users whose password is checked by the provider
+func (u *UserRepository) GetUsersByAuthProvider(ctx context.Context, authProvider string) ([]models.User, error) {
+	var foundUsers []models.User
+	result := u.scoped(ctx).Where("auth_provider = ?", authProvider).Find(&foundUsers)
+
+	if err := result.Error; err != nil {
+		slog.ErrorContext(ctx, "UserRepository.GetUsersByAuthProvider(): error occured during User search", "passed_data", authProvider, "error", err)
+		return nil, err
+	}
+
+	return foundUsers, nil
+}
+
 func NewUserRepositoryPostgres(connection *gorm.DB) *UserRepository {
 	return &UserRepository{connection: connection}
 }
+
+// ForOrganization returns repository which reads and writes only records of the organization
+func (u *UserRepository) ForOrganization(organizationId int) *UserRepository {
+	return &UserRepository{connection: u.connection, organizationId: organizationId}
+}
+
+func (u *UserRepository) scoped(ctx context.Context) *gorm.DB {
+	return u.connection.WithContext(ctx).Scopes(byOrganization("users", u.organizationId))
+}
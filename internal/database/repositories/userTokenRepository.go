@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"go-booking-system/internal/models"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+// UserTokenRepository tokens are found by hash of the secret sent to the user - not limited to organization
+type UserTokenRepository struct {
+	connection *gorm.DB
+}
+
+func NewUserTokenRepositoryPostgres(connection *gorm.DB) *UserTokenRepository {
+	return &UserTokenRepository{connection: connection}
+}
+
+// ReplaceUserToken deletes unused tokens of the user with the same purpose and creates passed one in one transaction -
+// only the last sent link works
+func (u *UserTokenRepository) ReplaceUserToken(ctx context.Context, userToken models.UserToken) (models.UserToken, error) {
+	err := u.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", userToken.UserId, userToken.Purpose).Delete(&models.UserToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Omit("used_at", "created_at").Create(&userToken).Error
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "UserTokenRepository.ReplaceUserToken(): error occured during User Token creation", "user_id", userToken.UserId, "purpose", userToken.Purpose, "error", err)
+		return models.UserToken{}, err
+	}
+
+	return userToken, nil
+}
+
+// UseUserToken marks unused and not expired token as used and returns it. Empty token - not found, expired or already used
+// (e.g. by a concurrent request)
+func (u *UserTokenRepository) UseUserToken(ctx context.Context, purpose string, tokenHash string) (models.UserToken, error) {
+	var userToken models.UserToken
+	now := time.Now()
+
+	err := u.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("purpose = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", purpose, tokenHash, now).Find(&userToken)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		result = tx.Model(&models.UserToken{}).
+			Where("user_token_id = ? AND used_at IS NULL", userToken.UserTokenId).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			userToken = models.UserToken{}
+			return nil
+		}
+
+		userToken.UsedAt = &now
+		return nil
+	})
+
+	if err != nil {
+		slog.ErrorContext(ctx, "UserTokenRepository.UseUserToken(): error occured during User Token use", "purpose", purpose, "error", err)
+		return models.UserToken{}, err
+	}
+
+	return userToken, nil
+}
@@ -0,0 +1,7 @@
+-- demo users of the host organization
+INSERT INTO users (name, role_id, email, telephone, username, password_hash, organization_id)
+VALUES ('Admin', 1, 'admin@booking.app', '+992989991788', 'admin', '65794a68624763694f694a49557a49314e694973496e52356564323137613332613934626134313666383865313631323232373863434936496b705856434a390161e13f3124ae3455747b1a9ed78aa231253ae5c543cd28b9a6605835148299', 1),  -- Password: `AdminPass`
+       ('Content Manager', 2, 'content_manager@booking.app', '+992989991789', 'content_manager', '65794a68624763694f694a49557a49314e694973496e52356564323137613332613934626134313666383865313631323232373863434936496b705856434a39bc004791aa25b3b15386c1ae71c6034d58a0cb4385287f90d1ceb45b9ce6a197', 1), -- Password: `ContentCop666`
+       ('Cadden Jones', 3, 'jonesCadden@booking.app', '+992989991790', 'jones_cadden', '65794a68624763694f694a49557a49314e694973496e52356564323137613332613934626134313666383865313631323232373863434936496b705856434a390844eba7ecb1d12e63ef7cbde4a54cdd800a7579d1666d36ceeb088973a2d97b', 1), -- Password: `HRFromECorp`
+       ('Event Planner', 4, 'events@booking.app', '+992989991791', 'events_admin', '65794a68624763694f694a49557a49314e694973496e52356564323137613332613934626134313666383865313631323232373863434936496b705856434a3966b532b8105c587bc6dd4a3099d0d92ebb5121cfcca6b1612735b3acc529215d', 1), -- Password: `VerySecurePassword`
+       ('Sam Sepiol', 5, 'mr.robot@booking.app', '+992989991792', 'sam.sepiol', '65794a68624763694f694a49557a49314e694973496e52356564323137613332613934626134313666383865313631323232373863434936496b705856434a398c3a9c7c99ea7969eb77cf65438d3e7755e18974af188234576b4d2d90e0d089', 1); -- Password: `IAmMrRobot`
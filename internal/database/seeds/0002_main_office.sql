@@ -0,0 +1,19 @@
+INSERT INTO locations (location_id, name, address, time_zone, organization_id, created_by)
+VALUES (1, 'Main office', 'Dushanbe', 'Asia/Dushanbe', 1, 1);
+SELECT setval('locations_location_id_seq', (SELECT max(location_id) FROM locations));
+
+INSERT INTO buildings (building_id, location_id, name, organization_id, created_by)
+VALUES (1, 1, 'Main building', 1, 1);
+SELECT setval('buildings_building_id_seq', (SELECT max(building_id) FROM buildings));
+
+INSERT INTO floors (floor_id, building_id, name, level, organization_id, created_by)
+VALUES (1, 1, 'Ground floor', 0, 1, 1);
+SELECT setval('floors_floor_id_seq', (SELECT max(floor_id) FROM floors));
+
+-- Main office works on weekdays from 08:00 till 20:00 (Asia/Dushanbe)
+INSERT INTO opening_hours (location_id, weekday, opens_at, closes_at)
+VALUES (1, 1, '08:00', '20:00'),
+       (1, 2, '08:00', '20:00'),
+       (1, 3, '08:00', '20:00'),
+       (1, 4, '08:00', '20:00'),
+       (1, 5, '08:00', '20:00');
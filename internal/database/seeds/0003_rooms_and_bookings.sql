@@ -0,0 +1,12 @@
+-- rooms of the main office
+INSERT INTO rooms (number, capacity, floor_id, organization_id, created_by)
+VALUES ('Conference room #1', 20, 1, 1, 1),
+       ('Conference room #2', 10, 1, 1, 1),
+       ('Conference room #3', 5, 1, 1, 1),
+       ('Interrogation room', 2, 1, 1, 1),
+       ('Sauna', 8, 1, 1, 1);
+
+INSERT INTO bookings (user_id, room_id, datetime_start, datetime_end, organization_id, created_by)
+VALUES (3, 1, '2025-04-23 13:00:00.00'::timestamp with time zone, '2025-04-23 14:00:00.00'::timestamp with time zone, 1, 3), -- booking of room 'Conference room #1' by `HR` 13:00-14:00
+       (5, 1, '2025-04-23 15:00:00.00'::timestamp with time zone, '2025-04-23 16:00:00.00'::timestamp with time zone, 1, 5), -- booking of room 'Conference room #1' by `Sam Sepiol` 15:00-16:00
+       (5, 5, '2025-04-23 10:00:00.00'::timestamp with time zone, '2025-04-23 13:00:00.00'::timestamp with time zone, 1, 5); -- booking of room 'Sauna' by `Sam Sepiol` 10:00-13:00
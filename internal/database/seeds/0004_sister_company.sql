@@ -0,0 +1,7 @@
+-- sister company of the business center: can book conference room #1 for 10 hours a month
+INSERT INTO organizations (organization_id, name, created_by)
+VALUES (2, 'Sister company', 1);
+SELECT setval('organizations_organization_id_seq', (SELECT max(organization_id) FROM organizations));
+
+INSERT INTO room_shares (room_id, organization_id, quota_minutes, created_by)
+VALUES (1, 2, 600, 1);
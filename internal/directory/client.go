@@ -0,0 +1,243 @@
+package directory
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"go-booking-system/internal/oidc"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Config of LDAP / Active Directory users are authenticated by and synchronized from
+type Config struct {
+	Enabled bool
+	// URL ldap://host:389 or ldaps://host:636
+	URL string
+	// StartTLS upgrade ldap:// connection to TLS - passwords should not be sent in clear text
+	StartTLS bool
+	// BindDN and BindPassword service account searching users. BindPassword - secret, set by environment variable
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// UserFilter users of the application, e.g. members of a department
+	UserFilter string
+
+	IdAttribute        string // stable id of the entry (objectGUID, entryUUID) - users are matched by it after renames
+	UsernameAttribute  string // sAMAccountName, uid
+	NameAttribute      string
+	EmailAttribute     string
+	TelephoneAttribute string
+	GroupsAttribute    string // memberOf
+
+	// GroupRoles role of the user is taken from the first mapping whose group (CN or full DN) the user is member of
+	GroupRoles []oidc.GroupRole
+	// DefaultRoleId role of users without mapped groups. 0 - they are not synchronized
+	DefaultRoleId int
+	// OrganizationId organization users are synchronized into
+	OrganizationId int
+
+	// SyncInterval period of synchronization of users. 0 - users are synchronized only by CLI
+	SyncInterval time.Duration
+	Timeout      time.Duration
+	PageSize     uint32
+}
+
+// Entry user of the directory
+type Entry struct {
+	Id        string
+	DN        string
+	Username  string
+	Name      string
+	Email     string
+	Telephone string
+	// Groups DNs and CNs of groups the user is member of
+	Groups []string
+	// Disabled account is disabled in Active Directory (userAccountControl)
+	Disabled bool
+}
+
+// ErrInvalidCredentials unknown username, wrong password or disabled account
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+const (
+	// userAccountControlAttribute flags of Active Directory account. Other directories do not have it
+	userAccountControlAttribute = "userAccountControl"
+	accountDisabledFlag         = 0x2
+)
+
+// Client connects to the directory for every operation - operations are rare (log in, periodic sync)
+type Client struct {
+	config Config
+}
+
+func NewClient(config Config) *Client {
+	return &Client{config: config}
+}
+
+func (c *Client) Config() Config {
+	return c.config
+}
+
+// Authenticate finds the user by username and checks the password by bind as the user
+func (c *Client) Authenticate(ctx context.Context, username string, password string) (Entry, error) {
+	// empty password is unauthenticated bind - it succeeds for any DN
+	if password == "" {
+		return Entry{}, ErrInvalidCredentials
+	}
+
+	connection, err := c.connect(ctx)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer connection.Close()
+
+	filter := fmt.Sprintf("(&%s(%s=%s))", c.userFilter(), c.config.UsernameAttribute, ldap.EscapeFilter(username))
+	result, err := connection.Search(c.searchRequest(filter, 2))
+	if err != nil {
+		slog.ErrorContext(ctx, "Client.Authenticate(): error occured during user search", "passed_data", username, "error", err)
+		return Entry{}, err
+	}
+	if len(result.Entries) != 1 {
+		slog.WarnContext(ctx, "Client.Authenticate(): user is not found or is not unique in directory", "passed_data", username, "found", len(result.Entries))
+		return Entry{}, ErrInvalidCredentials
+	}
+
+	entry := c.toEntry(result.Entries[0])
+	if entry.Disabled {
+		slog.WarnContext(ctx, "Client.Authenticate(): account is disabled in directory", "passed_data", username)
+		return Entry{}, ErrInvalidCredentials
+	}
+
+	if err := connection.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return Entry{}, ErrInvalidCredentials
+		}
+		slog.ErrorContext(ctx, "Client.Authenticate(): error occured during bind as the user", "passed_data", username, "error", err)
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// Users returns all users of UserFilter
+func (c *Client) Users(ctx context.Context) ([]Entry, error) {
+	connection, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Close()
+
+	result, err := connection.SearchWithPaging(c.searchRequest(c.userFilter(), 0), c.config.PageSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "Client.Users(): error occured during users search", "error", err)
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(result.Entries))
+	for _, ldapEntry := range result.Entries {
+		entry := c.toEntry(ldapEntry)
+		if entry.Id == "" || entry.Username == "" {
+			slog.WarnContext(ctx, "Client.Users(): entry without id or username is skipped", "dn", ldapEntry.DN)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// connect dials the directory and binds as service account
+func (c *Client) connect(ctx context.Context) (*ldap.Conn, error) {
+	dialer := &net.Dialer{Timeout: c.config.Timeout}
+	if deadline, ok := ctx.Deadline(); ok {
+		dialer.Deadline = deadline
+	}
+
+	connection, err := ldap.DialURL(c.config.URL, ldap.DialWithDialer(dialer))
+	if err != nil {
+		slog.ErrorContext(ctx, "Client.connect(): error occured during connection to directory", "url", c.config.URL, "error", err)
+		return nil, fmt.Errorf("directory is not available: %w", err)
+	}
+	connection.SetTimeout(c.config.Timeout)
+
+	if c.config.StartTLS {
+		parsedURL, _ := url.Parse(c.config.URL)
+		if err := connection.StartTLS(&tls.Config{ServerName: parsedURL.Hostname(), MinVersion: tls.VersionTLS12}); err != nil {
+			connection.Close()
+			slog.ErrorContext(ctx, "Client.connect(): error occured during StartTLS", "url", c.config.URL, "error", err)
+			return nil, fmt.Errorf("directory is not available: %w", err)
+		}
+	}
+
+	if err := connection.Bind(c.config.BindDN, c.config.BindPassword); err != nil {
+		connection.Close()
+		slog.ErrorContext(ctx, "Client.connect(): error occured during bind as service account", "bind_dn", c.config.BindDN, "error", err)
+		return nil, fmt.Errorf("bind as service account failed: %w", err)
+	}
+
+	return connection, nil
+}
+
+func (c *Client) searchRequest(filter string, sizeLimit int) *ldap.SearchRequest {
+	attributes := []string{c.config.IdAttribute, c.config.UsernameAttribute, c.config.NameAttribute, c.config.EmailAttribute,
+		c.config.TelephoneAttribute, c.config.GroupsAttribute, userAccountControlAttribute}
+
+	return ldap.NewSearchRequest(c.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, sizeLimit, int(c.config.Timeout.Seconds()), false,
+		filter, attributes, nil)
+}
+
+// userFilter filter in parentheses - it is combined with other conditions
+func (c *Client) userFilter() string {
+	filter := strings.TrimSpace(c.config.UserFilter)
+	if filter == "" {
+		return "(objectClass=*)"
+	}
+	if !strings.HasPrefix(filter, "(") {
+		filter = "(" + filter + ")"
+	}
+
+	return filter
+}
+
+func (c *Client) toEntry(ldapEntry *ldap.Entry) Entry {
+	entry := Entry{
+		Id:        attributeString(ldapEntry.GetEqualFoldRawAttributeValue(c.config.IdAttribute)),
+		DN:        ldapEntry.DN,
+		Username:  ldapEntry.GetEqualFoldAttributeValue(c.config.UsernameAttribute),
+		Name:      ldapEntry.GetEqualFoldAttributeValue(c.config.NameAttribute),
+		Email:     ldapEntry.GetEqualFoldAttributeValue(c.config.EmailAttribute),
+		Telephone: ldapEntry.GetEqualFoldAttributeValue(c.config.TelephoneAttribute),
+	}
+
+	for _, groupDN := range ldapEntry.GetEqualFoldAttributeValues(c.config.GroupsAttribute) {
+		entry.Groups = append(entry.Groups, groupDN)
+		if parsedDN, err := ldap.ParseDN(groupDN); err == nil && len(parsedDN.RDNs) > 0 && len(parsedDN.RDNs[0].Attributes) > 0 {
+			entry.Groups = append(entry.Groups, parsedDN.RDNs[0].Attributes[0].Value)
+		}
+	}
+
+	if flags, err := strconv.Atoi(ldapEntry.GetEqualFoldAttributeValue(userAccountControlAttribute)); err == nil {
+		entry.Disabled = flags&accountDisabledFlag != 0
+	}
+
+	return entry
+}
+
+// attributeString binary values (objectGUID of Active Directory) are hex-encoded
+func attributeString(value []byte) string {
+	if utf8.Valid(value) && !strings.ContainsFunc(string(value), func(r rune) bool { return !unicode.IsPrint(r) }) {
+		return string(value)
+	}
+
+	return hex.EncodeToString(value)
+}
@@ -0,0 +1,292 @@
+package directory
+
+import (
+	"errors"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// TestEntry entry of TestServer. Bind as the entry is possible only if Password is set
+type TestEntry struct {
+	DN         string
+	Password   string
+	Attributes map[string][]string
+}
+
+// TestServer in-process LDAP server for tests and development: simple bind, search with equality, presence and substring
+// filters, unbind. Search requires bind. Not for production use
+type TestServer struct {
+	listener net.Listener
+
+	mutex       sync.Mutex
+	entries     []TestEntry
+	connections sync.WaitGroup
+}
+
+// NewTestServer starts the server on a random port of localhost
+func NewTestServer(entries []TestEntry) (*TestServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	server := &TestServer{listener: listener, entries: entries}
+	go server.serve()
+
+	return server, nil
+}
+
+func (s *TestServer) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+// SetEntries replaces entries of the directory
+func (s *TestServer) SetEntries(entries []TestEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries = entries
+}
+
+func (s *TestServer) Close() error {
+	err := s.listener.Close()
+	s.connections.Wait()
+
+	return err
+}
+
+func (s *TestServer) serve() {
+	for {
+		connection, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.connections.Add(1)
+		go func() {
+			defer s.connections.Done()
+			defer connection.Close()
+			s.handle(connection)
+		}()
+	}
+}
+
+// handle answers requests of one connection until unbind or error
+func (s *TestServer) handle(connection net.Conn) {
+	boundDN := ""
+	for {
+		request, err := ber.ReadPacket(connection)
+		if err != nil || len(request.Children) < 2 {
+			return
+		}
+		messageId, _ := request.Children[0].Value.(int64)
+		operation := request.Children[1]
+
+		var responses []*ber.Packet
+		switch operation.Tag {
+		case ldap.ApplicationBindRequest:
+			var code uint16
+			boundDN, code = s.bind(operation)
+			responses = append(responses, resultPacket(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			if boundDN == "" {
+				responses = append(responses, resultPacket(ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights))
+				break
+			}
+			responses = s.search(operation)
+		case ldap.ApplicationUnbindRequest:
+			return
+		default:
+			responses = append(responses, resultPacket(ldap.ApplicationExtendedResponse, ldap.LDAPResultUnwillingToPerform))
+		}
+
+		for _, response := range responses {
+			envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageId, "Message ID"))
+			envelope.AppendChild(response)
+			if _, err := connection.Write(envelope.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// bind returns DN the connection is bound as. Empty DN - anonymous
+func (s *TestServer) bind(operation *ber.Packet) (string, uint16) {
+	if len(operation.Children) < 3 {
+		return "", ldap.LDAPResultProtocolError
+	}
+	dn, _ := operation.Children[1].Value.(string)
+	password := operation.Children[2].Data.String()
+	if dn == "" {
+		return "", ldap.LDAPResultSuccess
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.DN, dn) && entry.Password != "" && entry.Password == password {
+			return entry.DN, ldap.LDAPResultSuccess
+		}
+	}
+
+	return "", ldap.LDAPResultInvalidCredentials
+}
+
+func (s *TestServer) search(operation *ber.Packet) []*ber.Packet {
+	if len(operation.Children) < 8 {
+		return []*ber.Packet{resultPacket(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError)}
+	}
+	baseDN, _ := operation.Children[0].Value.(string)
+	sizeLimit, _ := operation.Children[3].Value.(int64)
+	filter := operation.Children[6]
+	var attributes []string
+	for _, attribute := range operation.Children[7].Children {
+		name, _ := attribute.Value.(string)
+		attributes = append(attributes, strings.ToLower(name))
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var responses []*ber.Packet
+	for _, entry := range s.entries {
+		if !isUnder(entry.DN, baseDN) {
+			continue
+		}
+		matches, err := entry.matches(filter)
+		if err != nil {
+			return []*ber.Packet{resultPacket(ldap.ApplicationSearchResultDone, ldap.LDAPResultUnwillingToPerform)}
+		}
+		if !matches {
+			continue
+		}
+		if sizeLimit > 0 && int64(len(responses)) == sizeLimit {
+			return append(responses, resultPacket(ldap.ApplicationSearchResultDone, ldap.LDAPResultSizeLimitExceeded))
+		}
+		responses = append(responses, entry.packet(attributes))
+	}
+
+	return append(responses, resultPacket(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+}
+
+func (e TestEntry) values(attribute string) []string {
+	for name, values := range e.Attributes {
+		if strings.EqualFold(name, attribute) {
+			return values
+		}
+	}
+
+	return nil
+}
+
+func (e TestEntry) matches(filter *ber.Packet) (bool, error) {
+	switch filter.Tag {
+	case ldap.FilterAnd, ldap.FilterOr:
+		for _, child := range filter.Children {
+			matches, err := e.matches(child)
+			if err != nil {
+				return false, err
+			}
+			if matches == (filter.Tag == ldap.FilterOr) {
+				return matches, nil
+			}
+		}
+		return filter.Tag == ldap.FilterAnd, nil
+	case ldap.FilterNot:
+		if len(filter.Children) != 1 {
+			return false, errors.New("not filter should have one child")
+		}
+		matches, err := e.matches(filter.Children[0])
+		return !matches, err
+	case ldap.FilterPresent:
+		attribute := filter.Data.String()
+		return strings.EqualFold(attribute, "objectClass") || len(e.values(attribute)) > 0, nil
+	case ldap.FilterEqualityMatch:
+		if len(filter.Children) != 2 {
+			return false, errors.New("equality filter should have attribute and value")
+		}
+		attribute, _ := filter.Children[0].Value.(string)
+		value := filter.Children[1].Data.String()
+		return slices.ContainsFunc(e.values(attribute), func(v string) bool { return strings.EqualFold(v, value) }), nil
+	case ldap.FilterSubstrings:
+		if len(filter.Children) != 2 {
+			return false, errors.New("substrings filter should have attribute and substrings")
+		}
+		attribute, _ := filter.Children[0].Value.(string)
+		return slices.ContainsFunc(e.values(attribute), func(v string) bool { return matchesSubstrings(v, filter.Children[1].Children) }), nil
+	}
+
+	return false, errors.New("filter is not supported by test server")
+}
+
+func matchesSubstrings(value string, substrings []*ber.Packet) bool {
+	value = strings.ToLower(value)
+	for _, substring := range substrings {
+		part := strings.ToLower(substring.Data.String())
+		switch substring.Tag {
+		case ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(value, part) {
+				return false
+			}
+			value = value[len(part):]
+		case ldap.FilterSubstringsAny:
+			index := strings.Index(value, part)
+			if index < 0 {
+				return false
+			}
+			value = value[index+len(part):]
+		case ldap.FilterSubstringsFinal:
+			if !strings.HasSuffix(value, part) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// packet SearchResultEntry with requested attributes (all if none is requested)
+func (e TestEntry) packet(attributes []string) *ber.Packet {
+	entryPacket := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	entryPacket.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.DN, "Object Name"))
+
+	attributesPacket := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range e.Attributes {
+		if len(attributes) > 0 && !slices.Contains(attributes, "*") && !slices.Contains(attributes, strings.ToLower(name)) {
+			continue
+		}
+
+		attributePacket := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attributePacket.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		valuesPacket := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			valuesPacket.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attributePacket.AppendChild(valuesPacket)
+		attributesPacket.AppendChild(attributePacket)
+	}
+	entryPacket.AppendChild(attributesPacket)
+
+	return entryPacket
+}
+
+func resultPacket(application uint8, code uint16) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ber.Tag(application), nil, ldap.ApplicationMap[application])
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, uint64(code), "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, ldap.LDAPResultCodeMap[code], "Diagnostic Message"))
+
+	return result
+}
+
+// isUnder entry is the base or is below it
+func isUnder(dn string, baseDN string) bool {
+	dn, baseDN = strings.ToLower(dn), strings.ToLower(baseDN)
+
+	return baseDN == "" || dn == baseDN || strings.HasSuffix(dn, ","+baseDN)
+}
@@ -2,13 +2,19 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"go-booking-system/internal/metrics"
 	"go-booking-system/internal/models"
+	"go-booking-system/internal/ratelimit"
 	"go-booking-system/internal/services"
 	"go-booking-system/pkg"
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type LoginParams struct {
@@ -16,11 +22,12 @@ type LoginParams struct {
 	Password string `json:"password"`
 }
 
+// RegistrationParams users register themselves into the host organization with the default role - organization and role
+// are never taken from the request
 type RegistrationParams struct {
 	Name      string `json:"name"`
 	Email     string `json:"email"`
 	Telephone string `json:"telephone"`
-	RoleId    int    `json:"role_id"`
 	Username  string `json:"username"`
 	Password  string `json:"password"`
 }
@@ -32,64 +39,149 @@ type EncodedRefreshJWTToken struct {
 type JWTTokens struct {
 	AccessToken  pkg.JWTToken `json:"token"`
 	RefreshToken pkg.JWTToken `json:"refresh_token"`
+	// MFAEnrollmentRequired role of the user requires two-factor authentication - only enrollment routes are available
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
+}
+
+// MFAChallenge response of the first step of log in if the user has enabled TOTP. MFAToken and the code are sent to `/auth/login/mfa`
+type MFAChallenge struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
 }
 
 func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
 	loginParams := LoginParams{}
 	decodingJSONError := json.NewDecoder(r.Body).Decode(&loginParams)
 	if decodingJSONError != nil {
-		log.Println("AuthHandler.Login(): error occured during decoding JSON. Details: ", decodingJSONError.Error())
+		slog.WarnContext(r.Context(), "AuthHandler.Login(): error occured during decoding JSON", "details", decodingJSONError.Error())
 		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", decodingJSONError.Error())
 		return
 	}
 
 	validator := NewLogingParamsValidator(&loginParams)
 	if validator.AllLoginParamsFieldsValid != true {
-		log.Println("AuthHandler.Login(): login data is not valid!", validator.ValidationErrors)
+		slog.WarnContext(r.Context(), "AuthHandler.Login(): login data is not valid!", "details", validator.ValidationErrors)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "login data is not valid!", validator)
 		return
 	}
 
+	// brute-force protection: limits of IP address and username, lock of the account
+	if limitError := h.limiter.AllowLogin(r.Context(), ClientIP(r), loginParams.Username); limitError != nil {
+		tooManyRequestsResponse(w, limitError)
+		return
+	}
+
 	// Identification & Authentication
-	foundUser, loginError := h.service.AuthService.CheckIfUserExistsAndPasswordIsCorrect(loginParams.Username, loginParams.Password)
+	foundUser, loginError := h.service.AuthService.CheckIfUserExistsAndPasswordIsCorrect(r.Context(), loginParams.Username, loginParams.Password)
 	if loginError != nil {
-		log.Println("AuthHandler.Login(): error occured during login")
-		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during login", loginError.Error())
+		if loginError.Error() != services.InvalidCredentialsError && loginError.Error() != services.EmailNotVerifiedError {
+			metrics.LoginsFailedTotal.Inc()
+			slog.ErrorContext(r.Context(), "AuthHandler.Login(): error occured during login", "error", loginError)
+			pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during login")
+			return
+		}
+
+		// the password is correct - it is not a failed login
+		if loginError.Error() == services.EmailNotVerifiedError {
+			slog.WarnContext(r.Context(), "AuthHandler.Login(): email of the user is not verified", "passed_data", loginParams.Username)
+			pkg.ErrorResponse(w, http.StatusForbidden, services.EmailNotVerifiedError)
+			return
+		}
+
+		slog.WarnContext(r.Context(), "AuthHandler.Login(): invalid username or password", "passed_data", loginParams.Username)
+		h.loginFailed(w, r, loginParams.Username, services.InvalidCredentialsError)
 		return
 	}
 
 	// get the identity of who sent the token
-	identity := pkg.IPAddressIdentity{
-		IP: strings.Split(r.RemoteAddr, ":")[0],
+	identity := requestIdentity(r)
+
+	// second step - TOTP or recovery code. Failed logins are not reset until it is passed
+	if foundUser.TOTPEnabled {
+		h.mfaChallengeResponse(w, r, foundUser, identity)
+		return
+	}
+	h.limiter.LoginSucceeded(r.Context(), loginParams.Username)
+
+	h.tokensResponse(w, r, foundUser, identity, []string{services.AuthMethodPassword})
+}
+
+// mfaChallengeResponse first step of log in is passed - tokens are issued for TOTP or recovery code by `/auth/login/mfa`
+func (h *Handlers) mfaChallengeResponse(w http.ResponseWriter, r *http.Request, user models.User, identity pkg.IPAddressIdentity) {
+	challenge, err := h.service.MFAService.IssueChallenge(r.Context(), user, identity)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "AuthHandler.mfaChallengeResponse(): error occured during MFA challenge generation", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during login")
+		return
 	}
 
-	token, refreshToken := h.service.AuthService.GenerateTokens(foundUser, identity)
-	JWTtokens := JWTTokens{AccessToken: token, RefreshToken: refreshToken}
+	pkg.Response(w, MFAChallenge{MFARequired: true, MFAToken: challenge})
+}
+
+// tokensResponse issues tokens of authenticated user. User without second factor whose role requires it gets tokens
+// limited to enrollment routes
+func (h *Handlers) tokensResponse(w http.ResponseWriter, r *http.Request, user models.User, identity pkg.IPAddressIdentity, authMethods []string) {
+	isMFARequired := false
+	if !slices.Contains(authMethods, services.AuthMethodMFA) {
+		var err error
+		isMFARequired, err = h.service.ForOrganization(user.OrganizationId).MFAService.IsMFARequired(r.Context(), user.RoleId)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "AuthHandler.tokensResponse(): error occured during MFA policy check", "error", err)
+			pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during login")
+			return
+		}
+	}
+
+	token, refreshToken := h.service.AuthService.GenerateTokens(r.Context(), user, identity, authMethods)
+	JWTtokens := JWTTokens{AccessToken: token, RefreshToken: refreshToken, MFAEnrollmentRequired: isMFARequired}
 
 	pkg.Response(w, JWTtokens)
 }
 
+// loginFailed counts failed login of the username and delays the response - slows down guessing of passwords and codes.
+// Locked account gets the same response - lock is reported by the next attempt
+func (h *Handlers) loginFailed(w http.ResponseWriter, r *http.Request, username string, message string) {
+	metrics.LoginsFailedTotal.Inc()
+
+	delay, isLocked := h.limiter.LoginFailed(r.Context(), username)
+	if isLocked {
+		metrics.AccountLockoutsTotal.Inc()
+	}
+	select {
+	case <-time.After(delay):
+	case <-r.Context().Done():
+	}
+
+	pkg.ErrorResponse(w, http.StatusUnauthorized, message)
+}
+
 func (h *Handlers) Register(w http.ResponseWriter, r *http.Request) {
+	if limitError := h.limiter.AllowRegistration(r.Context(), ClientIP(r)); limitError != nil {
+		tooManyRequestsResponse(w, limitError)
+		return
+	}
+
 	registrationParams := RegistrationParams{}
 	decodingJSONError := json.NewDecoder(r.Body).Decode(&registrationParams)
 	if decodingJSONError != nil {
-		log.Println("AuthHandler.Login(): error occured during decoding JSON. Details: ", decodingJSONError.Error())
+		slog.WarnContext(r.Context(), "AuthHandler.Login(): error occured during decoding JSON", "details", decodingJSONError.Error())
 		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", decodingJSONError.Error())
 		return
 	}
 
 	userData := models.User{
-		Name:      registrationParams.Name,
-		Email:     registrationParams.Email,
-		Telephone: registrationParams.Telephone,
-		RoleId:    registrationParams.RoleId,
-		UserName:  registrationParams.Username,
-		Password:  registrationParams.Password,
-		Active:    true,
+		OrganizationId: services.HostOrganizationId,
+		Name:           registrationParams.Name,
+		Email:          registrationParams.Email,
+		Telephone:      registrationParams.Telephone,
+		RoleId:         services.DefaultRoleId,
+		UserName:       registrationParams.Username,
+		Password:       registrationParams.Password,
+		Active:         true,
 	}
 	userValidator := NewUserValidator(&userData)
 	if userValidator.AllUserFieldsValid != true {
-		log.Println("AuthHandler.Register(): User data is not valid!", userValidator.ValidationErrors)
+		slog.WarnContext(r.Context(), "AuthHandler.Register(): User data is not valid!", "details", userValidator.ValidationErrors)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "User data is not valid!", userValidator.ValidationErrors)
 		return
 	}
@@ -100,15 +192,16 @@ func (h *Handlers) Register(w http.ResponseWriter, r *http.Request) {
 	}
 	usernameAndPasswordValidator := NewLogingParamsValidator(&usernameAndPasswordParams)
 	if usernameAndPasswordValidator.AllLoginParamsFieldsValid != true {
-		log.Println("AuthHandler.Register(): Username or Password data is not valid!", usernameAndPasswordValidator.ValidationErrors)
+		slog.WarnContext(r.Context(), "AuthHandler.Register(): Username or Password data is not valid!", "details", usernameAndPasswordValidator.ValidationErrors)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "Username or Password data is not valid", usernameAndPasswordValidator.ValidationErrors)
 		return
 	}
 
-	user, err := h.service.AuthService.Create(userData)
+	user, err := h.service.ForOrganization(services.HostOrganizationId).AccountService.Register(r.Context(), userData)
 	if err != nil {
-		log.Println("AuthHandler.Register(): error occured during User creation", err)
-		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during User creation", err.Error())
+		// details are not returned - they would reveal that the username or email is taken
+		slog.ErrorContext(r.Context(), "AuthHandler.Register(): error occured during User creation", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "registration is not possible with passed data")
 		return
 	}
 
@@ -119,14 +212,14 @@ func (h *Handlers) Register(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	authorizationHeader := r.Header.Get("Authorization")
 	if authorizationHeader == "" {
-		log.Println("AuthHandler.RefreshToken(): empty authorization header.")
+		slog.WarnContext(r.Context(), "AuthHandler.RefreshToken(): empty authorization header.")
 		pkg.ErrorResponse(w, http.StatusBadRequest, "empty authorization header")
 		return
 	}
 
 	authorizationHeaderParts := strings.Split(authorizationHeader, " ")
 	if !strings.Contains(authorizationHeader, "Bearer") || len(authorizationHeaderParts) != 2 {
-		log.Println("AuthHandler.RefreshToken(): wrong authorization header format. Reason: no 'Bearer' or no access token. Authorization header: ", authorizationHeader)
+		slog.WarnContext(r.Context(), "AuthHandler.RefreshToken(): wrong authorization header format. Reason: no 'Bearer' or no access token. Authorization header", "passed_data", authorizationHeader)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "wrong authorization header format. Reason: no 'Bearer' or no access token", authorizationHeader)
 		return
 	}
@@ -135,69 +228,94 @@ func (h *Handlers) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	refreshTokenJSON := EncodedRefreshJWTToken{}
 	decodingJSONError := json.NewDecoder(r.Body).Decode(&refreshTokenJSON)
 	if decodingJSONError != nil {
-		log.Println("AuthHandler.RefreshToken(): error occured during decoding JSON. Details: ", decodingJSONError.Error())
+		slog.WarnContext(r.Context(), "AuthHandler.RefreshToken(): error occured during decoding JSON", "details", decodingJSONError.Error())
 		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", decodingJSONError.Error())
 		return
 	}
 
 	// prepare data for token validation
-	ipAddress := strings.Split(r.RemoteAddr, ":")[0]
+	identity := requestIdentity(r)
 	refreshToken := refreshTokenJSON.EncodedRefreshToken
 
 	// Validate access token
-	accessTokenValidator := h.service.AuthService.ValidateAccessToken(accessToken, ipAddress)
+	accessTokenValidator := h.service.AuthService.ValidateAccessToken(r.Context(), accessToken, identity)
 	if accessTokenValidator.ValidationError != nil && accessTokenValidator.ValidationError.Error() != services.TokenIsExpiredError {
-		log.Println("AuthHandler.RefreshToken(): error occured during validation of JWT Access Token. Details: ", accessTokenValidator.ValidationError)
+		slog.WarnContext(r.Context(), "AuthHandler.RefreshToken(): error occured during validation of JWT Access Token", "details", accessTokenValidator.ValidationError)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during validation of JWT Access Token", accessTokenValidator.ValidationError.Error())
 		return
 	}
 
 	// Validate refresh token
-	refreshTokenValidator := h.service.AuthService.ValidateRefreshToken(refreshToken, ipAddress)
+	refreshTokenValidator := h.service.AuthService.ValidateRefreshToken(r.Context(), refreshToken, identity)
 	if refreshTokenValidator.ValidationError != nil {
-		log.Println("AuthHandler.RefreshToken(): error occured during validation of JWT Refresh Token. Details: ", refreshTokenValidator.ValidationError)
+		slog.WarnContext(r.Context(), "AuthHandler.RefreshToken(): error occured during validation of JWT Refresh Token", "details", refreshTokenValidator.ValidationError)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during validation of JWT Refresh Token", refreshTokenValidator.ValidationError.Error())
 		return
 	}
 
 	// check if refresh token is expired
 	if refreshTokenValidator.IsExpired == true {
-		log.Println("AuthHandler.RefreshToken(): JWT Refresh Token is expired. Details: ", refreshTokenValidator.ValidationError)
-		pkg.ErrorResponse(w, http.StatusBadRequest, "JWT Refresh Token is expired", refreshTokenValidator.ValidationError.Error())
+		slog.WarnContext(r.Context(), "AuthHandler.RefreshToken(): JWT Refresh Token is expired", "details", refreshTokenValidator.ValidationError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "JWT Refresh Token is expired")
 		return
 	}
 
 	// if tokens are assigned to different users - deny
-	if accessTokenValidator.AccessTokenClaims.Subject != refreshTokenValidator.RefreshTokenClaims.Subject {
-		log.Println("AuthHandler.RefreshToken(): tokens are assigned to different users")
-		pkg.ErrorResponse(w, http.StatusForbidden, "tokens are assigned to different users", refreshTokenValidator.ValidationError.Error())
+	if accessTokenValidator.AccessTokenClaims.Subject != refreshTokenValidator.RefreshTokenClaims.Subject ||
+		accessTokenValidator.AccessTokenClaims.Organization != refreshTokenValidator.RefreshTokenClaims.Organization {
+		slog.WarnContext(r.Context(), "AuthHandler.RefreshToken(): tokens are assigned to different users")
+		pkg.ErrorResponse(w, http.StatusForbidden, "tokens are assigned to different users")
 		return
 	}
 
 	userId, stringToIntConversionError := strconv.Atoi(refreshTokenValidator.RefreshTokenClaims.Subject)
 	if stringToIntConversionError != nil {
-		log.Println("AuthHandler.RefreshToken(): cannot convert Subject claim to Integer. Details: ", stringToIntConversionError)
+		slog.WarnContext(r.Context(), "AuthHandler.RefreshToken(): cannot convert Subject claim to Integer", "error", stringToIntConversionError)
 		pkg.ErrorResponse(w, http.StatusForbidden, "cannot convert Subject claim to Integer", stringToIntConversionError.Error())
 		return
 	}
 
-	// get User to generate new set of tokens
-	user, userByIdError := h.service.UserService.GetUserById(userId)
+	// get User of the organization to generate new set of tokens
+	tenantService, organizationError := h.organizationService(r.Context(), refreshTokenValidator.RefreshTokenClaims.Organization)
+	if organizationError != nil {
+		slog.WarnContext(r.Context(), "AuthHandler.RefreshToken(): organization of the token is not valid", "error", organizationError)
+		pkg.ErrorResponse(w, http.StatusForbidden, "organization of the token is not valid", organizationError.Error())
+		return
+	}
+	user, userByIdError := tenantService.UserService.GetUserById(r.Context(), userId)
 	if userByIdError != nil {
-		log.Println("AuthHandler.RefreshToken(): error occured during user search by id. Details: ", userByIdError)
+		slog.WarnContext(r.Context(), "AuthHandler.RefreshToken(): error occured during user search by id", "error", userByIdError)
 		pkg.ErrorResponse(w, http.StatusForbidden, "error occured during user search by id", userByIdError.Error())
 		return
 	}
+	// deactivated users cannot prolong their session
+	if user.Active != true {
+		slog.WarnContext(r.Context(), "AuthHandler.RefreshToken(): tokens are not refreshed - user is not active", "user_id", userId)
+		pkg.ErrorResponse(w, http.StatusUnauthorized, "access denied", "user is not active")
+		return
+	}
 
 	// if all tokens are valid - generate a new pair of tokens
-	identity := pkg.IPAddressIdentity{IP: ipAddress}
-
-	newAccessToken, newRefreshToken := h.service.AuthService.GenerateTokens(user, identity)
+	// refreshed tokens keep methods the user has logged in with
+	newAccessToken, newRefreshToken := h.service.AuthService.GenerateTokens(r.Context(), user, identity, refreshTokenValidator.RefreshTokenClaims.AuthMethods)
 	JWTtokens := JWTTokens{AccessToken: newAccessToken, RefreshToken: newRefreshToken}
 
 	pkg.Response(w, JWTtokens)
 }
 
+// tooManyRequestsResponse 429 with `Retry-After` in seconds
+func tooManyRequestsResponse(w http.ResponseWriter, limitError error) {
+	retryAfter := time.Second
+	var rateLimitError *ratelimit.LimitError
+	if errors.As(limitError, &rateLimitError) && rateLimitError.RetryAfter > retryAfter {
+		retryAfter = rateLimitError.RetryAfter
+	}
+
+	metrics.RateLimitedRequestsTotal.Inc()
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	pkg.ErrorResponse(w, http.StatusTooManyRequests, "too many requests")
+}
+
 type LoginParamsValidator struct {
 	LoginParamsToValidate     *LoginParams      `json:"passed_login_params"`
 	ValidationErrors          map[string]string `json:"validation_errors"`
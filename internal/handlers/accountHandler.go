@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type EmailParams struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordParams struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type TokenParams struct {
+	Token string `json:"token"`
+}
+
+type AccountMessage struct {
+	Message string `json:"message"`
+}
+
+// linkIsSentMessage the same response for known and unknown emails
+const linkIsSentMessage = "if the email belongs to an account, a link is sent to it"
+
+// ForgotPassword sends link of password reset to the email
+func (h *Handlers) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	params, ok := h.emailParams(w, r, "AccountHandler.ForgotPassword()")
+	if !ok {
+		return
+	}
+
+	// errors are not returned - they would reveal that the email belongs to an account
+	if err := h.service.AccountService.ForgotPassword(r.Context(), params.Email); err != nil {
+		slog.ErrorContext(r.Context(), "AccountHandler.ForgotPassword(): error occured during sending of password reset link", "passed_data", params.Email, "error", err)
+	}
+
+	pkg.Response(w, AccountMessage{Message: linkIsSentMessage})
+}
+
+// ResetPassword sets new password by token of the link sent by `/auth/forgot-password`
+func (h *Handlers) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	params := ResetPasswordParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		slog.WarnContext(r.Context(), "AccountHandler.ResetPassword(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+	if params.Token == "" || params.Password == "" {
+		slog.WarnContext(r.Context(), "AccountHandler.ResetPassword(): token or password is empty")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "token and password should not be empty")
+		return
+	}
+
+	if err := h.service.AccountService.ResetPassword(r.Context(), params.Token, params.Password); err != nil {
+		if err.Error() == services.InvalidUserTokenError || err.Error() == services.DirectoryPasswordError {
+			slog.WarnContext(r.Context(), "AccountHandler.ResetPassword(): password is not reset", "error", err)
+			pkg.ErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.ErrorContext(r.Context(), "AccountHandler.ResetPassword(): error occured during password reset", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during password reset")
+		return
+	}
+
+	pkg.Response(w, AccountMessage{Message: "password is changed - log in with the new password"})
+}
+
+// VerifyEmail confirms email by token of the link sent after registration
+func (h *Handlers) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	params := TokenParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		slog.WarnContext(r.Context(), "AccountHandler.VerifyEmail(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+
+	user, err := h.service.AccountService.VerifyEmail(r.Context(), params.Token)
+	if err != nil {
+		if err.Error() == services.InvalidUserTokenError {
+			slog.WarnContext(r.Context(), "AccountHandler.VerifyEmail(): email is not verified", "error", err)
+			pkg.ErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.ErrorContext(r.Context(), "AccountHandler.VerifyEmail(): error occured during email verification", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during email verification")
+		return
+	}
+
+	pkg.Response(w, user)
+}
+
+// ResendEmailVerification sends link of email verification again. The user cannot log in before verification, so the
+// request is not authenticated
+func (h *Handlers) ResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	params, ok := h.emailParams(w, r, "AccountHandler.ResendEmailVerification()")
+	if !ok {
+		return
+	}
+
+	if err := h.service.AccountService.ResendEmailVerification(r.Context(), params.Email); err != nil {
+		slog.ErrorContext(r.Context(), "AccountHandler.ResendEmailVerification(): error occured during sending of email verification", "passed_data", params.Email, "error", err)
+	}
+
+	pkg.Response(w, AccountMessage{Message: linkIsSentMessage})
+}
+
+// emailParams decodes email and checks limits of links sent to it. Response is written if `false` is returned
+func (h *Handlers) emailParams(w http.ResponseWriter, r *http.Request, caller string) (EmailParams, bool) {
+	params := EmailParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		slog.WarnContext(r.Context(), caller+": error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return EmailParams{}, false
+	}
+	params.Email = strings.TrimSpace(params.Email)
+	if params.Email == "" {
+		slog.WarnContext(r.Context(), caller+": email is empty")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "email should not be empty")
+		return EmailParams{}, false
+	}
+
+	if limitError := h.limiter.AllowPasswordReset(r.Context(), ClientIP(r), params.Email); limitError != nil {
+		tooManyRequestsResponse(w, limitError)
+		return EmailParams{}, false
+	}
+
+	return params, true
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type AttendeeResponseParams struct {
+	Status string `json:"status"` // accepted or declined
+}
+
+func (h *Handlers) AddAttendees(w http.ResponseWriter, r *http.Request) {
+	subjectStr := r.Header.Get("subject")
+	r.Header.Del("subject")
+	subjectWhoInvites, conversionError := strconv.Atoi(subjectStr)
+	if conversionError != nil {
+		slog.WarnContext(r.Context(), "AttendeeHandler.AddAttendees(): cannot convert `subject`-header to integer", "error", conversionError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", conversionError.Error())
+		return
+	}
+
+	// get booking_id from query path
+	bookingId, ok := bookingIdParam(w, r, "AttendeeHandler.AddAttendees()")
+	if !ok {
+		return
+	}
+
+	var attendeesToAdd []models.Attendee
+	// convert JSON to []models.Attendee type
+	err := json.NewDecoder(r.Body).Decode(&attendeesToAdd)
+	if err != nil {
+		slog.WarnContext(r.Context(), "AttendeeHandler.AddAttendees(): cannot convert JSON to []models.Attendee", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to []models.Attendee", err.Error())
+		return
+	}
+
+	// invite attendees
+	addedAttendees, err := h.tenantService(r).AttendeeService.AddAttendees(r.Context(), bookingId, attendeesToAdd, subjectWhoInvites)
+	if err != nil {
+		slog.WarnContext(r.Context(), "AttendeeHandler.AddAttendees(): error occured during adding attendees", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during adding attendees", err.Error())
+		return
+	}
+
+	// return invited attendees with visitor pass codes of guests
+	pkg.Response(w, addedAttendees)
+}
+
+func (h *Handlers) GetAttendees(w http.ResponseWriter, r *http.Request) {
+	// get booking_id from query path
+	bookingId, ok := bookingIdParam(w, r, "AttendeeHandler.GetAttendees()")
+	if !ok {
+		return
+	}
+
+	// get attendees from services
+	attendees, err := h.tenantService(r).AttendeeService.GetAttendees(r.Context(), bookingId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "AttendeeHandler.GetAttendees(): error occured during getting attendees", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting attendees", err.Error())
+		return
+	}
+
+	// return found attendees
+	pkg.Response(w, attendees)
+}
+
+func (h *Handlers) RemoveAttendee(w http.ResponseWriter, r *http.Request) {
+	// get booking_id from query path
+	bookingId, ok := bookingIdParam(w, r, "AttendeeHandler.RemoveAttendee()")
+	if !ok {
+		return
+	}
+
+	// get attendee_id from query path and convert it to int
+	attendeeId, err := strconv.Atoi(r.URL.Query().Get("attendee_id"))
+	if err != nil {
+		slog.WarnContext(r.Context(), "AttendeeHandler.RemoveAttendee(): attendee_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "attendee_id should be an integer", err.Error())
+		return
+	}
+
+	// remove attendee
+	_, err = h.tenantService(r).AttendeeService.RemoveAttendee(r.Context(), bookingId, attendeeId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "AttendeeHandler.RemoveAttendee(): error occured during attendee removal", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during attendee removal", err.Error())
+		return
+	}
+
+	// return success message
+	pkg.Response(w, "success")
+}
+
+// RespondToInvitation invited user accepts or declines the booking
+func (h *Handlers) RespondToInvitation(w http.ResponseWriter, r *http.Request) {
+	subjectStr := r.Header.Get("subject")
+	r.Header.Del("subject")
+	userId, conversionError := strconv.Atoi(subjectStr)
+	if conversionError != nil {
+		slog.WarnContext(r.Context(), "AttendeeHandler.RespondToInvitation(): cannot convert `subject`-header to integer", "error", conversionError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", conversionError.Error())
+		return
+	}
+
+	// get booking_id from query path
+	bookingId, ok := bookingIdParam(w, r, "AttendeeHandler.RespondToInvitation()")
+	if !ok {
+		return
+	}
+
+	var responseParams AttendeeResponseParams
+	// convert JSON to AttendeeResponseParams type
+	err := json.NewDecoder(r.Body).Decode(&responseParams)
+	if err != nil {
+		slog.WarnContext(r.Context(), "AttendeeHandler.RespondToInvitation(): cannot convert JSON to AttendeeResponseParams struct", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to AttendeeResponseParams struct", err.Error())
+		return
+	}
+
+	// save response
+	attendee, err := h.tenantService(r).AttendeeService.Respond(r.Context(), bookingId, userId, responseParams.Status)
+	if err != nil {
+		slog.WarnContext(r.Context(), "AttendeeHandler.RespondToInvitation(): error occured during saving response", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during saving response", err.Error())
+		return
+	}
+
+	// return updated attendee
+	pkg.Response(w, attendee)
+}
+
+// GetInvitations returns bookings current user is invited to
+func (h *Handlers) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	userId, conversionError := strconv.Atoi(r.Header.Get("subject"))
+	if conversionError != nil {
+		slog.WarnContext(r.Context(), "AttendeeHandler.GetInvitations(): cannot convert `subject`-header to integer", "error", conversionError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", conversionError.Error())
+		return
+	}
+
+	// get bookings from services
+	bookings, err := h.tenantService(r).AttendeeService.GetInvitations(r.Context(), userId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "AttendeeHandler.GetInvitations(): error occured during getting invitations", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting invitations", err.Error())
+		return
+	}
+
+	// render times in requested time zone
+	timeZone, ok := h.responseTimeZone(w, r)
+	if !ok {
+		return
+	}
+
+	// return found bookings
+	pkg.Response(w, models.BookingsIn(bookings, timeZone))
+}
+
+// GetVisitorPasses returns passes of guests expected in [datetime_start; datetime_end] for reception
+func (h *Handlers) GetVisitorPasses(w http.ResponseWriter, r *http.Request) {
+	// validate params
+	validator := NewBookingQueryParamsValidator(r.URL.RawQuery)
+	if validator.AllQueryParamsValid == false {
+		slog.WarnContext(r.Context(), "AttendeeHandler.GetVisitorPasses(): query is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "query is not valid", validator.ValidationErrors)
+		return
+	}
+
+	if validator.DateTimeStart.IsZero() || validator.DateTimeEnd.IsZero() {
+		slog.WarnContext(r.Context(), "AttendeeHandler.GetVisitorPasses(): parameters `datetime_start` and `datetime_end` are required")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameters `datetime_start` and `datetime_end` are required")
+		return
+	}
+
+	// get visitor passes from services
+	visitorPasses, err := h.tenantService(r).AttendeeService.GetVisitorPasses(r.Context(), validator.DateTimeStart, validator.DateTimeEnd)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "AttendeeHandler.GetVisitorPasses(): error occured during getting visitor passes", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting visitor passes", err.Error())
+		return
+	}
+
+	// render times in requested time zone
+	timeZone, ok := h.responseTimeZone(w, r)
+	if !ok {
+		return
+	}
+
+	// return found visitor passes
+	visitorPassesInTimeZone := make([]models.VisitorPass, 0, len(visitorPasses))
+	for _, visitorPass := range visitorPasses {
+		visitorPassesInTimeZone = append(visitorPassesInTimeZone, visitorPass.In(timeZone))
+	}
+	pkg.Response(w, visitorPassesInTimeZone)
+}
+
+func (h *Handlers) GetVisitorPassByCode(w http.ResponseWriter, r *http.Request) {
+	// get pass_code from query path
+	passCode := r.URL.Query().Get("pass_code")
+	if passCode == "" {
+		slog.WarnContext(r.Context(), "AttendeeHandler.GetVisitorPassByCode(): parameter `pass_code` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `pass_code` is empty or not passed")
+		return
+	}
+
+	// get visitor pass from services
+	visitorPass, err := h.tenantService(r).AttendeeService.GetVisitorPassByCode(r.Context(), passCode)
+	if err != nil {
+		slog.WarnContext(r.Context(), "AttendeeHandler.GetVisitorPassByCode(): error occured during getting visitor pass", "error", err)
+		pkg.ErrorResponse(w, http.StatusNotFound, "error occured during getting visitor pass", err.Error())
+		return
+	}
+
+	// render times in requested time zone
+	timeZone, ok := h.responseTimeZone(w, r)
+	if !ok {
+		return
+	}
+
+	// return found visitor pass
+	pkg.Response(w, visitorPass.In(timeZone))
+}
+
+// bookingIdParam reads required `booking_id` query parameter. Error response is written if it is not valid
+func bookingIdParam(w http.ResponseWriter, r *http.Request, caller string) (int, bool) {
+	bookingIdStr := r.URL.Query().Get("booking_id")
+	if bookingIdStr == "" {
+		slog.WarnContext(r.Context(), caller+": parameter `booking_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `booking_id` is empty or not passed")
+		return 0, false
+	}
+
+	// convert booking_id param string to int
+	bookingId, err := strconv.Atoi(bookingIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), caller+": booking_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "booking_id should be an integer", err.Error())
+		return 0, false
+	}
+
+	return bookingId, true
+}
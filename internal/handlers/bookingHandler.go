@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"go-booking-system/internal/models"
 	"go-booking-system/pkg"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,11 +12,16 @@ import (
 )
 
 func (h *Handlers) BookRoom(w http.ResponseWriter, r *http.Request) {
+	timeZone, ok := h.responseTimeZone(w, r)
+	if !ok {
+		return
+	}
+
 	subjectStr := r.Header.Get("subject")
 	r.Header.Del("subject")
 	subjectWhoCreatesBooking, conversionError := strconv.Atoi(subjectStr)
 	if conversionError != nil {
-		log.Println("BookingHandler.BookRoom(): cannot convert `subject`-header to integer. Details: ", conversionError)
+		slog.WarnContext(r.Context(), "BookingHandler.BookRoom(): cannot convert `subject`-header to integer", "error", conversionError)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", conversionError.Error())
 		return
 	}
@@ -25,7 +30,7 @@ func (h *Handlers) BookRoom(w http.ResponseWriter, r *http.Request) {
 	// convert JSON to models.Booking type
 	err := json.NewDecoder(r.Body).Decode(&bookingParamsToCreate)
 	if err != nil {
-		log.Println("BookingHandler.BookRoom(): cannot convert JSON to models.Booking struct. Details: ", err)
+		slog.WarnContext(r.Context(), "BookingHandler.BookRoom(): cannot convert JSON to models.Booking struct", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.Booking struct", err.Error())
 		return
 	}
@@ -33,13 +38,13 @@ func (h *Handlers) BookRoom(w http.ResponseWriter, r *http.Request) {
 	// validate passed booking data
 	validator := NewBookingValidator(&bookingParamsToCreate)
 	if validator.AllBookingFieldsValid != true {
-		log.Println("BookingHandler.BookRoom(): booking data is not valid. Details: ", validator.ValidationErrors)
+		slog.WarnContext(r.Context(), "BookingHandler.BookRoom(): booking data is not valid", "details", validator.ValidationErrors)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "booking data is not valid", validator.ValidationErrors)
 		return
 	}
 
 	// create booking
-	createdBooking, err := h.service.BookingService.BookRoom(
+	createdBooking, err := h.tenantService(r).BookingService.BookRoom(r.Context(),
 		bookingParamsToCreate.UserId,
 		bookingParamsToCreate.RoomId,
 		bookingParamsToCreate.DateTimeStart,
@@ -47,56 +52,68 @@ func (h *Handlers) BookRoom(w http.ResponseWriter, r *http.Request) {
 		subjectWhoCreatesBooking,
 	)
 	if err != nil {
-		log.Println("BookingHandler.BookRoom(): error occured during Room Booking. Details: ", err)
+		slog.ErrorContext(r.Context(), "BookingHandler.BookRoom(): error occured during Room Booking", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during Room Booking", err.Error())
 		return
 	}
 
 	// return created booking
-	pkg.Response(w, createdBooking)
+	pkg.Response(w, createdBooking.In(timeZone))
 }
 
 func (h *Handlers) GetAllBookings(w http.ResponseWriter, r *http.Request) {
 	// get all bookings
-	bookings := h.service.BookingService.GetAll()
+	bookings := h.tenantService(r).BookingService.GetAll(r.Context())
+
+	// render times in requested time zone
+	timeZone, ok := h.responseTimeZone(w, r)
+	if !ok {
+		return
+	}
 
 	// return all bookings
-	pkg.Response(w, bookings)
+	pkg.Response(w, models.BookingsIn(bookings, timeZone))
 }
 
 func (h *Handlers) GetBookingById(w http.ResponseWriter, r *http.Request) {
 	// get booking_id from query path
 	bookingIdStr := r.URL.Query().Get("booking_id")
 	if bookingIdStr == "" {
-		log.Println("BookingHandler.GetBookingById(): parameter `booking_id` is empty or not passed")
+		slog.WarnContext(r.Context(), "BookingHandler.GetBookingById(): parameter `booking_id` is empty or not passed")
 		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `booking_id` is empty or not passed")
 		return
 	}
 	// convert booking_id param string to int
 	bookingId, err := strconv.Atoi(bookingIdStr)
 	if err != nil {
-		log.Println("BookingHandler.GetBookingById(): booking_id should be an integer. Details: ", err)
+		slog.WarnContext(r.Context(), "BookingHandler.GetBookingById(): booking_id should be an integer", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "booking_id should be an integer", err.Error())
 		return
 	}
 
 	// get Booking from services
-	booking, err := h.service.BookingService.GetBookingById(bookingId)
+	booking, err := h.tenantService(r).BookingService.GetBookingById(r.Context(), bookingId)
 	if err != nil {
-		log.Println("BookingHandler.GetBookingById(): error occured during getting booking by id. Details: ", err)
+		slog.ErrorContext(r.Context(), "BookingHandler.GetBookingById(): error occured during getting booking by id", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting booking by id", err.Error())
 		return
 	}
 
+	// render times in requested time zone
+	timeZone, ok := h.responseTimeZone(w, r)
+	if !ok {
+		return
+	}
+
 	// return found booking
-	pkg.Response(w, booking)
+	pkg.Response(w, booking.In(timeZone))
 }
 
 func (h *Handlers) GetBookingsByRoomId(w http.ResponseWriter, r *http.Request) {
 	// get room_id from query path
 	roomIdStr := r.URL.Query().Get("room_id")
 	if roomIdStr == "" {
-		log.Println("BookingHandler.GetBookingsByRoomId(): parameter `room_id` is empty or not passed")
+		slog.WarnContext(r.Context(), "BookingHandler.GetBookingsByRoomId(): parameter `room_id` is empty or not passed")
 		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `room_id` is empty or not passed")
 		return
 	}
@@ -104,21 +121,27 @@ func (h *Handlers) GetBookingsByRoomId(w http.ResponseWriter, r *http.Request) {
 	// convert room_id param string to int
 	roomId, err := strconv.Atoi(roomIdStr)
 	if err != nil {
-		log.Println("BookingHandler.GetBookingsByRoomId(): room_id should be an integer. Details: ", err)
+		slog.WarnContext(r.Context(), "BookingHandler.GetBookingsByRoomId(): room_id should be an integer", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "room_id should be an integer", err.Error())
 		return
 	}
 
 	// get Booking slice from services
-	foundBooking, err := h.service.BookingService.GetBookingsByRoomId(roomId)
+	foundBooking, err := h.tenantService(r).BookingService.GetBookingsByRoomId(r.Context(), roomId)
 	if err != nil {
-		log.Println("BookingHandler.GetBookingsByRoomId(): error occured during getting booking by room id. Details: ", err)
+		slog.ErrorContext(r.Context(), "BookingHandler.GetBookingsByRoomId(): error occured during getting booking by room id", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting booking by room id", err.Error())
 		return
 	}
 
+	// render times in requested time zone
+	timeZone, ok := h.responseTimeZone(w, r)
+	if !ok {
+		return
+	}
+
 	// return found booking
-	pkg.Response(w, foundBooking)
+	pkg.Response(w, models.BookingsIn(foundBooking, timeZone))
 }
 
 func (h *Handlers) GetBookingsByRoomIdAndBookingTime(w http.ResponseWriter, r *http.Request) {
@@ -126,29 +149,35 @@ func (h *Handlers) GetBookingsByRoomIdAndBookingTime(w http.ResponseWriter, r *h
 	validator := NewBookingQueryParamsValidator(r.URL.RawQuery)
 
 	if validator.AllQueryParamsValid == false {
-		log.Println("BookingHandler.GetBookingsByRoomIdAndBookingTime(): booking query is not valid. Details: ", validator.ValidationErrors)
+		slog.WarnContext(r.Context(), "BookingHandler.GetBookingsByRoomIdAndBookingTime(): booking query is not valid", "details", validator.ValidationErrors)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "booking query is not valid", validator.ValidationErrors)
 		return
 	}
 
 	roomId, dateTimeStart, dateTimeEnd := validator.RoomId, validator.DateTimeStart, validator.DateTimeEnd
 	// get Booking slice from services
-	bookings, err := h.service.BookingService.GetBookingsByRoomIdAndBookingTime(roomId, dateTimeStart, dateTimeEnd)
+	bookings, err := h.tenantService(r).BookingService.GetBookingsByRoomIdAndBookingTime(r.Context(), roomId, dateTimeStart, dateTimeEnd)
 	if err != nil {
-		log.Println("BookingHandler.GetBookingsByRoomIdAndBookingTime(): error occured during getting bookings by room id and booking time. Details: ", err)
+		slog.ErrorContext(r.Context(), "BookingHandler.GetBookingsByRoomIdAndBookingTime(): error occured during getting bookings by room id and booking time", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting bookings by room id and booking time", err.Error())
 		return
 	}
 
+	// render times in requested time zone
+	timeZone, ok := h.responseTimeZone(w, r)
+	if !ok {
+		return
+	}
+
 	// return found booking
-	pkg.Response(w, bookings)
+	pkg.Response(w, models.BookingsIn(bookings, timeZone))
 }
 
 func (h *Handlers) UpdateBooking(w http.ResponseWriter, r *http.Request) {
 	// get booking_id from query path
 	bookingIdStr := r.URL.Query().Get("booking_id")
 	if bookingIdStr == "" {
-		log.Println("BookingHandler.UpdateBooking(): parameter `booking_id` is empty or not passed")
+		slog.WarnContext(r.Context(), "BookingHandler.UpdateBooking(): parameter `booking_id` is empty or not passed")
 		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `booking_id` is empty or not passed")
 		return
 	}
@@ -156,7 +185,7 @@ func (h *Handlers) UpdateBooking(w http.ResponseWriter, r *http.Request) {
 	// convert booking_id param string to int
 	bookingId, err := strconv.Atoi(bookingIdStr)
 	if err != nil {
-		log.Println("BookingHandler.UpdateBooking(): booking_id should be an integer. Details: ", err)
+		slog.WarnContext(r.Context(), "BookingHandler.UpdateBooking(): booking_id should be an integer", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "booking_id should be an integer", err.Error())
 		return
 	}
@@ -165,7 +194,7 @@ func (h *Handlers) UpdateBooking(w http.ResponseWriter, r *http.Request) {
 	// convert JSON to models.Booking type
 	jsonConversionErr := json.NewDecoder(r.Body).Decode(&bookingParamsToUpdate)
 	if jsonConversionErr != nil {
-		log.Println("BookingHandler.UpdateBooking(): cannot convert JSON to models.Booking struct. Details: ", jsonConversionErr)
+		slog.WarnContext(r.Context(), "BookingHandler.UpdateBooking(): cannot convert JSON to models.Booking struct", "error", jsonConversionErr)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.Booking struct", jsonConversionErr.Error())
 		return
 	}
@@ -173,7 +202,7 @@ func (h *Handlers) UpdateBooking(w http.ResponseWriter, r *http.Request) {
 	// validate passed booking data
 	validator := NewBookingValidator(&bookingParamsToUpdate)
 	if validator.AllBookingFieldsValid != true {
-		log.Println("BookingHandler.UpdateBooking(): Booking data is not valid. Details: ", validator.ValidationErrors)
+		slog.WarnContext(r.Context(), "BookingHandler.UpdateBooking(): Booking data is not valid", "details", validator.ValidationErrors)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "Booking data is not valid", validator.ValidationErrors)
 		return
 	}
@@ -182,22 +211,28 @@ func (h *Handlers) UpdateBooking(w http.ResponseWriter, r *http.Request) {
 	bookingParamsToUpdate.BookingId = bookingId
 
 	// update booking
-	updatedBooking, err := h.service.BookingService.Update(bookingParamsToUpdate)
+	updatedBooking, err := h.tenantService(r).BookingService.Update(r.Context(), bookingParamsToUpdate)
 	if err != nil {
-		log.Println("BookingHandler.UpdateBooking(): error occured during booking update. Details: ", err)
+		slog.ErrorContext(r.Context(), "BookingHandler.UpdateBooking(): error occured during booking update", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during booking update", err.Error())
 		return
 	}
 
+	// render times in requested time zone
+	timeZone, ok := h.responseTimeZone(w, r)
+	if !ok {
+		return
+	}
+
 	// return updated booking
-	pkg.Response(w, updatedBooking)
+	pkg.Response(w, updatedBooking.In(timeZone))
 }
 
 func (h *Handlers) DeleteBookings(w http.ResponseWriter, r *http.Request) {
 	// get booking_id string from query
 	bookingIdStr := r.URL.Query().Get("booking_id")
 	if bookingIdStr == "" {
-		log.Println("BookingHandler.DeleteBookings(): parameter `booking_id` is empty or not passed")
+		slog.WarnContext(r.Context(), "BookingHandler.DeleteBookings(): parameter `booking_id` is empty or not passed")
 		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `booking_id` is empty or not passed")
 		return
 	}
@@ -205,15 +240,15 @@ func (h *Handlers) DeleteBookings(w http.ResponseWriter, r *http.Request) {
 	// convert booking_id string to int
 	bookingId, err := strconv.Atoi(bookingIdStr)
 	if err != nil {
-		log.Println("BookingHandler.DeleteBookings(): booking data is not valid. Details: ", err)
+		slog.WarnContext(r.Context(), "BookingHandler.DeleteBookings(): booking data is not valid", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "booking data is not valid", err.Error())
 		return
 	}
 
 	// delete Booking
-	_, err = h.service.BookingService.Delete(bookingId)
+	_, err = h.tenantService(r).BookingService.Delete(r.Context(), bookingId)
 	if err != nil {
-		log.Println("BookingHandler.DeleteBookings(): error occured during booking deletion. Details: ", err)
+		slog.ErrorContext(r.Context(), "BookingHandler.DeleteBookings(): error occured during booking deletion", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during booking deletion", err.Error())
 		return
 	}
@@ -226,7 +261,7 @@ func (h *Handlers) CheckIfRoomAvailable(w http.ResponseWriter, r *http.Request)
 	validator := NewBookingQueryParamsValidator(r.URL.RawQuery)
 
 	if validator.AllQueryParamsValid == false {
-		log.Println("BookingHandler.CheckIfRoomAvailable(): booking query is not valid. Details: ", validator.ValidationErrors)
+		slog.WarnContext(r.Context(), "BookingHandler.CheckIfRoomAvailable(): booking query is not valid", "details", validator.ValidationErrors)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "booking query is not valid", validator.ValidationErrors)
 		return
 	}
@@ -235,9 +270,9 @@ func (h *Handlers) CheckIfRoomAvailable(w http.ResponseWriter, r *http.Request)
 	roomId, dateTimeStart, dateTimeEnd := validator.RoomId, validator.DateTimeStart, validator.DateTimeEnd
 
 	// get result is room available during given time frame
-	available, err := h.service.BookingService.CheckIfRoomAvailable(roomId, dateTimeStart, dateTimeEnd)
+	available, err := h.tenantService(r).BookingService.CheckIfRoomAvailable(r.Context(), roomId, dateTimeStart, dateTimeEnd)
 	if err != nil {
-		log.Println("BookingHandler.CheckIfRoomAvailable(): error occured during room availability check. Details: ", err)
+		slog.ErrorContext(r.Context(), "BookingHandler.CheckIfRoomAvailable(): error occured during room availability check", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during room availability check", err.Error())
 		return
 	}
@@ -251,7 +286,7 @@ func (h *Handlers) GetOverlappingBookings(w http.ResponseWriter, r *http.Request
 	validator := NewBookingQueryParamsValidator(r.URL.RawQuery)
 
 	if validator.AllQueryParamsValid == false {
-		log.Println("BookingHandler.GetOverlappingBookings(): booking query is not valid. Details: ", validator.ValidationErrors)
+		slog.WarnContext(r.Context(), "BookingHandler.GetOverlappingBookings(): booking query is not valid", "details", validator.ValidationErrors)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "booking query is not valid", validator.ValidationErrors)
 		return
 	}
@@ -260,15 +295,21 @@ func (h *Handlers) GetOverlappingBookings(w http.ResponseWriter, r *http.Request
 	roomId, dateTimeStart, dateTimeEnd := validator.RoomId, validator.DateTimeStart, validator.DateTimeEnd
 
 	// get Booking slice from services
-	bookings, err := h.service.BookingService.GetBookingsByRoomIdAndBookingTime(roomId, dateTimeStart, dateTimeEnd)
+	bookings, err := h.tenantService(r).BookingService.GetBookingsByRoomIdAndBookingTime(r.Context(), roomId, dateTimeStart, dateTimeEnd)
 	if err != nil {
-		log.Println("BookingHandler.GetOverlappingBookings(): error occured during getting overlapping bookings. Details: ", err)
+		slog.ErrorContext(r.Context(), "BookingHandler.GetOverlappingBookings(): error occured during getting overlapping bookings", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting overlapping bookings", err.Error())
 		return
 	}
 
+	// render times in requested time zone
+	timeZone, ok := h.responseTimeZone(w, r)
+	if !ok {
+		return
+	}
+
 	// return found booking
-	pkg.Response(w, bookings)
+	pkg.Response(w, models.BookingsIn(bookings, timeZone))
 }
 
 type BookingValidator struct {
@@ -335,7 +376,7 @@ type BookingQueryParamsValidator struct {
 }
 
 func NewBookingQueryParamsValidator(rawQueryParamsString string) *BookingQueryParamsValidator {
-	validator := &BookingQueryParamsValidator{RawQueryParamsString: rawQueryParamsString, QueryParams: make(map[string]string), AllQueryParamsValid: false}
+	validator := &BookingQueryParamsValidator{RawQueryParamsString: rawQueryParamsString, QueryParams: make(map[string]string), ValidationErrors: make(map[string]string), AllQueryParamsValid: false}
 	validator.AreParamsValid()
 
 	return validator
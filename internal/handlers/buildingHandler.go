@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handlers) CreateBuilding(w http.ResponseWriter, r *http.Request) {
+	subjectStr := r.Header.Get("subject")
+	r.Header.Del("subject")
+	subjectWhoCreatesBuilding, conversionError := strconv.Atoi(subjectStr)
+	if conversionError != nil {
+		slog.WarnContext(r.Context(), "BuildingHandler.CreateBuilding(): cannot convert `subject`-header to integer", "error", conversionError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", conversionError.Error())
+		return
+	}
+
+	var buildingParams models.Building
+
+	// convert JSON to models.Building type
+	err := json.NewDecoder(r.Body).Decode(&buildingParams)
+	if err != nil {
+		slog.WarnContext(r.Context(), "BuildingHandler.CreateBuilding(): cannot convert JSON to models.Building struct", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.Building struct", err.Error())
+		return
+	}
+
+	// validate passed building data
+	validator := NewBuildingValidator(&buildingParams)
+	if validator.AllBuildingFieldsValid != true {
+		slog.WarnContext(r.Context(), "BuildingHandler.CreateBuilding(): Building data is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "Building data is not valid", validator.ValidationErrors)
+		return
+	}
+
+	buildingParams.CreatedBy = subjectWhoCreatesBuilding
+
+	// create building
+	createdBuilding, err := h.tenantService(r).BuildingService.Create(r.Context(), buildingParams)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "BuildingHandler.CreateBuilding(): error occured during Building creation", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during Building creation", err.Error())
+		return
+	}
+
+	// return created building
+	pkg.Response(w, createdBuilding)
+}
+
+func (h *Handlers) GetAllBuildings(w http.ResponseWriter, r *http.Request) {
+	// get all buildings
+	buildings := h.tenantService(r).BuildingService.GetAll(r.Context())
+
+	// return all buildings
+	pkg.Response(w, buildings)
+}
+
+func (h *Handlers) GetBuildingById(w http.ResponseWriter, r *http.Request) {
+	// get building_id from query path
+	buildingIdStr := r.URL.Query().Get("building_id")
+	if buildingIdStr == "" {
+		slog.WarnContext(r.Context(), "BuildingHandler.GetBuildingById(): parameter `building_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `building_id` is empty or not passed")
+		return
+	}
+
+	// convert building_id param string to int
+	buildingId, err := strconv.Atoi(buildingIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "BuildingHandler.GetBuildingById(): building_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "building_id should be an integer", err.Error())
+		return
+	}
+
+	// get Building from services
+	building, err := h.tenantService(r).BuildingService.GetBuildingById(r.Context(), buildingId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "BuildingHandler.GetBuildingById(): error occured during getting building by id", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting building by id", err.Error())
+		return
+	}
+
+	// return found building
+	pkg.Response(w, building)
+}
+
+func (h *Handlers) UpdateBuilding(w http.ResponseWriter, r *http.Request) {
+	// get building_id from query path
+	buildingIdStr := r.URL.Query().Get("building_id")
+	if buildingIdStr == "" {
+		slog.WarnContext(r.Context(), "BuildingHandler.UpdateBuilding(): parameter `building_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `building_id` is empty or not passed")
+		return
+	}
+
+	// convert building_id param string to int
+	buildingId, err := strconv.Atoi(buildingIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "BuildingHandler.UpdateBuilding(): building_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "building_id should be an integer", err.Error())
+		return
+	}
+
+	var buildingParamsToUpdate models.Building
+	// convert JSON to models.Building type
+	err = json.NewDecoder(r.Body).Decode(&buildingParamsToUpdate)
+	if err != nil {
+		slog.WarnContext(r.Context(), "BuildingHandler.UpdateBuilding(): cannot convert JSON to models.Building struct", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.Building struct", err.Error())
+		return
+	}
+
+	// validate passed building data
+	validator := NewBuildingValidator(&buildingParamsToUpdate)
+	if validator.AllBuildingFieldsValid != true {
+		slog.WarnContext(r.Context(), "BuildingHandler.UpdateBuilding(): Building data is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "Building data is not valid", validator.ValidationErrors)
+		return
+	}
+
+	// to double-check if building id wasn't set
+	buildingParamsToUpdate.BuildingId = buildingId
+
+	// update building
+	updatedBuilding, err := h.tenantService(r).BuildingService.Update(r.Context(), buildingParamsToUpdate)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "BuildingHandler.UpdateBuilding(): error occured during building update", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during building update", err.Error())
+		return
+	}
+
+	// return updated building
+	pkg.Response(w, updatedBuilding)
+}
+
+func (h *Handlers) DeleteBuilding(w http.ResponseWriter, r *http.Request) {
+	// get building_id from query path
+	buildingIdStr := r.URL.Query().Get("building_id")
+	if buildingIdStr == "" {
+		slog.WarnContext(r.Context(), "BuildingHandler.DeleteBuilding(): parameter `building_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `building_id` is empty or not passed")
+		return
+	}
+
+	// convert building_id param string to int
+	buildingId, err := strconv.Atoi(buildingIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "BuildingHandler.DeleteBuilding(): building_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "building_id should be an integer", err.Error())
+		return
+	}
+
+	// delete building
+	_, err = h.tenantService(r).BuildingService.Delete(r.Context(), buildingId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "BuildingHandler.DeleteBuilding(): error occured during building deletion", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during building deletion", err.Error())
+		return
+	}
+
+	// return success message
+	pkg.Response(w, "success")
+}
+
+type BuildingValidator struct {
+	BuildingToValidate     *models.Building  `json:"passed_building"`
+	ValidationErrors       map[string]string `json:"validation_errors"`
+	IsLocationIdValid      bool              `json:"is_location_id_valid"`
+	IsNameValid            bool              `json:"is_name_valid"`
+	AllBuildingFieldsValid bool              `json:"all_building_fields_valid"`
+}
+
+func NewBuildingValidator(building *models.Building) *BuildingValidator {
+	validationErrors := map[string]string{
+		"locationid_error": "Building.LocationId: should not be negative integer or zero",
+		"name_error":       "Building.Name: should not be empty string",
+	}
+
+	validator := &BuildingValidator{BuildingToValidate: building, ValidationErrors: validationErrors, AllBuildingFieldsValid: false}
+	validator.IsBuildingValid()
+
+	return validator
+}
+
+func (b *BuildingValidator) IsBuildingValid() {
+	b.ValidateFields()
+
+	if b.IsLocationIdValid && b.IsNameValid {
+		b.AllBuildingFieldsValid = true
+	}
+}
+
+func (b *BuildingValidator) ValidateFields() {
+	if b.BuildingToValidate.LocationId > 0 {
+		b.IsLocationIdValid = true
+		delete(b.ValidationErrors, "locationid_error")
+	}
+	if b.BuildingToValidate.Name != "" {
+		b.IsNameValid = true
+		delete(b.ValidationErrors, "name_error")
+	}
+}
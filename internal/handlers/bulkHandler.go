@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxImportFileSize limit of uploaded CSV/XLSX file - 10 MB
+const MaxImportFileSize = 10 << 20
+
+var (
+	userImportColumns = []string{"name", "email", "telephone", "role_id", "time_zone", "username", "password"}
+	roomImportColumns = []string{"number", "capacity", "floor_id"}
+)
+
+func (h *Handlers) ImportUsers(w http.ResponseWriter, r *http.Request) {
+	importedBy, ok := subjectUserId(w, r, "BulkHandler.ImportUsers()")
+	if !ok {
+		return
+	}
+
+	rows, columns, ok := readImportTable(w, r, "BulkHandler.ImportUsers()", []string{"name", "email", "telephone", "role_id"})
+	if !ok {
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	importResult := models.ImportResult{DryRun: dryRun, Rows: make([]models.ImportRowResult, 0, len(rows))}
+
+	for i, row := range rows {
+		if isEmptyRow(row) {
+			continue
+		}
+		rowResult := models.ImportRowResult{Row: i + 2, Key: pkg.TableValue(row, columns, "email")}
+		rowResult.Errors = map[string]string{}
+
+		roleIdStr := pkg.TableValue(row, columns, "role_id")
+		roleId, conversionError := strconv.Atoi(roleIdStr)
+		if conversionError != nil {
+			rowResult.Errors["role_id_error"] = "role_id should be an integer. Passed data: " + roleIdStr
+		}
+
+		user := models.User{
+			Name:      pkg.TableValue(row, columns, "name"),
+			Email:     pkg.TableValue(row, columns, "email"),
+			Telephone: pkg.TableValue(row, columns, "telephone"),
+			RoleId:    roleId,
+			TimeZone:  pkg.TableValue(row, columns, "time_zone"),
+			UserName:  pkg.TableValue(row, columns, "username"),
+			Password:  pkg.TableValue(row, columns, "password"),
+			Active:    true,
+		}
+
+		// validate user data of the row
+		validator := NewUserValidator(&user)
+		for field, validationError := range validator.ValidationErrors {
+			rowResult.Errors[field] = validationError
+		}
+		if len(rowResult.Errors) != 0 {
+			rowResult.Action = models.ImportActionError
+			importResult.Add(rowResult)
+			continue
+		}
+
+		// create or update user
+		action, importedUser, err := h.tenantService(r).BulkService.ImportUser(r.Context(), user, importedBy, dryRun)
+		if err != nil {
+			rowResult.Errors["import_error"] = err.Error()
+		}
+		rowResult.Action, rowResult.RecordId = action, importedUser.UserId
+		importResult.Add(rowResult)
+	}
+
+	importResponse(w, r, "users_import_report", importResult)
+}
+
+func (h *Handlers) ExportUsers(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(w, r, "BulkHandler.ExportUsers()")
+	if !ok {
+		return
+	}
+
+	// passwords are never exported - column is kept to use the file as an import template
+	records := [][]string{append([]string{"user_id"}, userImportColumns...)}
+	for _, user := range h.tenantService(r).BulkService.ExportUsers(r.Context()) {
+		records = append(records, []string{strconv.Itoa(user.UserId), user.Name, user.Email, user.Telephone,
+			strconv.Itoa(user.RoleId), user.TimeZone, user.UserName, ""})
+	}
+
+	pkg.TableResponse(w, format, "users", records)
+}
+
+func (h *Handlers) ImportRooms(w http.ResponseWriter, r *http.Request) {
+	subjectStr := r.Header.Get("subject")
+	r.Header.Del("subject")
+	subjectWhoImportsRooms, conversionError := strconv.Atoi(subjectStr)
+	if conversionError != nil {
+		slog.WarnContext(r.Context(), "BulkHandler.ImportRooms(): cannot convert `subject`-header to integer", "error", conversionError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", conversionError.Error())
+		return
+	}
+
+	rows, columns, ok := readImportTable(w, r, "BulkHandler.ImportRooms()", roomImportColumns)
+	if !ok {
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	importResult := models.ImportResult{DryRun: dryRun, Rows: make([]models.ImportRowResult, 0, len(rows))}
+
+	for i, row := range rows {
+		if isEmptyRow(row) {
+			continue
+		}
+		rowResult := models.ImportRowResult{Row: i + 2, Key: pkg.TableValue(row, columns, "number")}
+		rowResult.Errors = map[string]string{}
+
+		capacityStr := pkg.TableValue(row, columns, "capacity")
+		capacity, conversionError := strconv.Atoi(capacityStr)
+		if conversionError != nil {
+			rowResult.Errors["capacity_conversion_error"] = "capacity should be an integer. Passed data: " + capacityStr
+		}
+		floorIdStr := pkg.TableValue(row, columns, "floor_id")
+		floorId, conversionError := strconv.Atoi(floorIdStr)
+		if conversionError != nil {
+			rowResult.Errors["floor_id_conversion_error"] = "floor_id should be an integer. Passed data: " + floorIdStr
+		}
+
+		room := models.Room{
+			Number:    pkg.TableValue(row, columns, "number"),
+			Capacity:  capacity,
+			FloorId:   floorId,
+			CreatedBy: subjectWhoImportsRooms,
+		}
+
+		// validate room data of the row
+		validator := NewRoomValidator(&room)
+		for field, validationError := range validator.ValidationErrors {
+			rowResult.Errors[field] = validationError
+		}
+		if len(rowResult.Errors) != 0 {
+			rowResult.Action = models.ImportActionError
+			importResult.Add(rowResult)
+			continue
+		}
+
+		// create or update room
+		action, importedRoom, err := h.tenantService(r).BulkService.ImportRoom(r.Context(), room, dryRun)
+		if err != nil {
+			rowResult.Errors["import_error"] = err.Error()
+		}
+		rowResult.Action, rowResult.RecordId = action, importedRoom.RoomId
+		importResult.Add(rowResult)
+	}
+
+	importResponse(w, r, "rooms_import_report", importResult)
+}
+
+func (h *Handlers) ExportRooms(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(w, r, "BulkHandler.ExportRooms()")
+	if !ok {
+		return
+	}
+
+	records := [][]string{append([]string{"room_id"}, roomImportColumns...)}
+	for _, room := range h.tenantService(r).BulkService.ExportRooms(r.Context()) {
+		records = append(records, []string{strconv.Itoa(room.RoomId), room.Number, strconv.Itoa(room.Capacity), strconv.Itoa(room.FloorId)})
+	}
+
+	pkg.TableResponse(w, format, "rooms", records)
+}
+
+// readImportTable reads CSV or XLSX file from `file` field of multipart form or from request body.
+// Returns rows without header and indexes of header columns
+func readImportTable(w http.ResponseWriter, r *http.Request, caller string, requiredColumns []string) ([][]string, map[string]int, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxImportFileSize)
+
+	var file io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		formFile, _, err := r.FormFile("file")
+		if err != nil {
+			slog.WarnContext(r.Context(), caller+": cannot read `file` field of the form", "error", err)
+			pkg.ErrorResponse(w, http.StatusBadRequest, "cannot read `file` field of the form", err.Error())
+			return nil, nil, false
+		}
+		defer formFile.Close()
+		file = formFile
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		slog.WarnContext(r.Context(), caller+": cannot read imported file", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot read imported file", err.Error())
+		return nil, nil, false
+	}
+
+	rows, err := pkg.ReadTable(data)
+	if err != nil {
+		slog.WarnContext(r.Context(), caller+": file should be CSV or XLSX", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "file should be CSV or XLSX", err.Error())
+		return nil, nil, false
+	}
+	if len(rows) < 2 {
+		slog.WarnContext(r.Context(), caller+": file should contain header and at least one row")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "file should contain header and at least one row")
+		return nil, nil, false
+	}
+
+	// check header
+	columns := pkg.TableHeader(rows[0])
+	missingColumns := make([]string, 0)
+	for _, column := range requiredColumns {
+		if _, ok := columns[column]; !ok {
+			missingColumns = append(missingColumns, column)
+		}
+	}
+	if len(missingColumns) != 0 {
+		slog.WarnContext(r.Context(), caller+": required columns are missing in the header", "details", missingColumns)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "required columns are missing in the header", missingColumns)
+		return nil, nil, false
+	}
+
+	return rows[1:], columns, true
+}
+
+// importResponse returns import result as JSON or failed rows as downloadable error report (`report=csv` or `report=xlsx`)
+func importResponse(w http.ResponseWriter, r *http.Request, reportName string, importResult models.ImportResult) {
+	reportFormat := r.URL.Query().Get("report")
+	if reportFormat != pkg.TableFormatCSV && reportFormat != pkg.TableFormatXLSX {
+		pkg.Response(w, importResult)
+		return
+	}
+
+	records := [][]string{{"row", "key", "errors"}}
+	for _, row := range importResult.Rows {
+		if row.Action != models.ImportActionError {
+			continue
+		}
+
+		fields := make([]string, 0, len(row.Errors))
+		for field := range row.Errors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		errorMessages := make([]string, 0, len(fields))
+		for _, field := range fields {
+			errorMessages = append(errorMessages, field+": "+row.Errors[field])
+		}
+		records = append(records, []string{strconv.Itoa(row.Row), row.Key, strings.Join(errorMessages, "; ")})
+	}
+
+	pkg.TableResponse(w, reportFormat, reportName, records)
+}
+
+func exportFormat(w http.ResponseWriter, r *http.Request, caller string) (string, bool) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		return pkg.TableFormatCSV, true
+	}
+	if format != pkg.TableFormatCSV && format != pkg.TableFormatXLSX {
+		slog.WarnContext(r.Context(), caller+": format should be `csv` or `xlsx`", "passed_data", format)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "format should be `csv` or `xlsx`", format)
+		return "", false
+	}
+
+	return format, true
+}
+
+func isEmptyRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/mux"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const (
+	ForwardedHeader     = "Forwarded"
+	XForwardedForHeader = "X-Forwarded-For"
+	// DeviceFingerprintHeader stable id of the client installation (e.g. generated by mobile application at first start).
+	// Together with `User-Agent` it makes the fingerprint checked by `fingerprint` token binding
+	DeviceFingerprintHeader = "X-Device-Fingerprint"
+
+	clientIPContextKey contextKey = "client-ip"
+)
+
+// ParseTrustedProxy IP address (`10.0.0.1`, `::1`) or network (`10.0.0.0/8`, `fd00::/8`) of reverse proxy
+func ParseTrustedProxy(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+
+	address, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	address = address.Unmap()
+
+	return netip.PrefixFrom(address, address.BitLen()), nil
+}
+
+// RealIP finds IP address of the client. Behind trusted reverse proxies it is taken from `Forwarded` (RFC 7239) or
+// `X-Forwarded-For` header: addresses are read from right to left, the first one not belonging to trusted proxies is the
+// client. Headers of requests not coming from trusted proxies are ignored - clients cannot spoof the address
+func RealIP(trustedProxies []string) mux.MiddlewareFunc {
+	proxies := make([]netip.Prefix, 0, len(trustedProxies))
+	for _, value := range trustedProxies {
+		proxy, err := ParseTrustedProxy(value)
+		if err != nil {
+			slog.Warn("MiddleWare.RealIP(): trusted proxy is skipped - it is not IP address or network", "passed_data", value, "error", err)
+			continue
+		}
+		proxies = append(proxies, proxy)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientIP := peerIP(r)
+			if address, err := netip.ParseAddr(clientIP); err == nil && isTrustedProxy(proxies, address) {
+				clientIP = forwardedClientIP(r, proxies, address)
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPContextKey, clientIP)))
+		})
+	}
+}
+
+// ClientIP IP address of the client without port. IPv4-mapped IPv6 addresses are returned as IPv4
+func ClientIP(r *http.Request) string {
+	if clientIP, ok := r.Context().Value(clientIPContextKey).(string); ok {
+		return clientIP
+	}
+
+	return peerIP(r)
+}
+
+// requestIdentity identity tokens issued by the request are bound to
+func requestIdentity(r *http.Request) pkg.IPAddressIdentity {
+	return pkg.IPAddressIdentity{IP: ClientIP(r), Fingerprint: pkg.DeviceFingerprint(r.UserAgent(), r.Header.Get(DeviceFingerprintHeader))}
+}
+
+// peerIP address of the host connected to the server (`RemoteAddr` is `1.2.3.4:5678` or `[2001:db8::1]:5678`)
+func peerIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if address, err := netip.ParseAddr(host); err == nil {
+		return address.Unmap().WithZone("").String()
+	}
+
+	return host
+}
+
+// forwardedClientIP the nearest address of forwarding chain which is not a trusted proxy. If every address is trusted or
+// the chain has a value which is not IP address (`unknown`, obfuscated identifier), the last trusted hop is the client
+func forwardedClientIP(r *http.Request, proxies []netip.Prefix, peer netip.Addr) string {
+	var chain []string
+	if forwarded := r.Header.Values(ForwardedHeader); len(forwarded) > 0 {
+		chain = forwardedFor(forwarded)
+	} else {
+		for _, value := range r.Header.Values(XForwardedForHeader) {
+			chain = append(chain, strings.Split(value, ",")...)
+		}
+	}
+
+	clientIP := peer
+	for i := len(chain) - 1; i >= 0; i-- {
+		address, err := parseForwardedAddress(chain[i])
+		if err != nil {
+			slog.WarnContext(r.Context(), "MiddleWare.RealIP(): forwarded address is not valid", "passed_data", chain[i], "error", err)
+			break
+		}
+		clientIP = address
+		if !isTrustedProxy(proxies, address) {
+			break
+		}
+	}
+
+	return clientIP.String()
+}
+
+// forwardedFor values of `for` parameters of `Forwarded` headers in order of proxies
+func forwardedFor(headers []string) []string {
+	var chain []string
+	for _, header := range headers {
+		for _, element := range strings.Split(header, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				name, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if found && strings.EqualFold(name, "for") {
+					chain = append(chain, value)
+				}
+			}
+		}
+	}
+
+	return chain
+}
+
+// parseForwardedAddress `1.2.3.4`, `1.2.3.4:80`, `"[2001:db8::1]:4711"`, `2001:db8::1`
+func parseForwardedAddress(value string) (netip.Addr, error) {
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+
+	address, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("not IP address: %w", err)
+	}
+
+	return address.Unmap().WithZone(""), nil
+}
+
+func isTrustedProxy(proxies []netip.Prefix, address netip.Addr) bool {
+	address = address.Unmap().WithZone("")
+	for _, proxy := range proxies {
+		if proxy.Contains(address) {
+			return true
+		}
+	}
+
+	return false
+}
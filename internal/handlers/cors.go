@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig policy of cross-origin requests of browser applications
+type CORSConfig struct {
+	// AllowedOrigins scheme://host[:port] of browser applications. "https://*.example.com" - any subdomain of example.com,
+	// "*" - any origin. Empty - cross-origin requests are not allowed
+	AllowedOrigins []string
+	// AllowedHeaders request headers the applications may send, compared case-insensitively
+	AllowedHeaders []string
+	// ExposedHeaders response headers readable by the applications
+	ExposedHeaders []string
+	// AllowCredentials cookies and TLS client certificates are sent with requests. Not allowed with "*" origin
+	AllowCredentials bool
+	// MaxAge time browsers cache result of preflight request. 0 - not cached
+	MaxAge time.Duration
+}
+
+// defaultCORSMethods methods of routes registered without method restriction
+var defaultCORSMethods = []string{http.MethodGet, http.MethodHead}
+
+// originPattern allowed origin. Wildcard pattern matches subdomains of host only, not the host itself
+type originPattern struct {
+	scheme   string
+	host     string // with port
+	wildcard bool
+}
+
+func (p originPattern) matches(scheme, host string) bool {
+	if scheme != p.scheme {
+		return false
+	}
+	if p.wildcard {
+		return strings.HasSuffix(host, "."+p.host)
+	}
+
+	return host == p.host
+}
+
+// CORS answers preflight requests and adds CORS headers to responses of allowed origins. Methods allowed for a route
+// are taken from the router, so the middleware should be applied by `router.Use`. Responses of not allowed origins get
+// no CORS headers - the browser blocks them
+func CORS(config CORSConfig) mux.MiddlewareFunc {
+	anyOrigin := slices.Contains(config.AllowedOrigins, "*")
+	patterns := make([]originPattern, 0, len(config.AllowedOrigins))
+	for _, origin := range config.AllowedOrigins {
+		if scheme, host, ok := strings.Cut(strings.ToLower(origin), "://"); ok {
+			wildcardHost, wildcard := strings.CutPrefix(host, "*.")
+			patterns = append(patterns, originPattern{scheme: scheme, host: wildcardHost, wildcard: wildcard})
+		}
+	}
+
+	allowedHeaders := make(map[string]bool, len(config.AllowedHeaders))
+	for _, header := range config.AllowedHeaders {
+		allowedHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+	allowedHeadersValue := strings.Join(config.AllowedHeaders, ", ")
+	exposedHeadersValue := strings.Join(config.ExposedHeaders, ", ")
+	maxAgeValue := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	isAllowedOrigin := func(origin string) bool {
+		if anyOrigin {
+			return true
+		}
+		parsed, err := url.Parse(strings.ToLower(origin))
+		if err != nil || parsed.Host == "" {
+			return false
+		}
+		for _, pattern := range patterns {
+			if pattern.matches(parsed.Scheme, parsed.Host) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			requestedMethod := r.Header.Get("Access-Control-Request-Method")
+			isPreflight := r.Method == http.MethodOptions && requestedMethod != ""
+
+			// response depends on the origin unless any origin gets the same "*"
+			if !anyOrigin || config.AllowCredentials {
+				w.Header().Add("Vary", "Origin")
+			}
+
+			methods := routeMethods(r)
+			if r.Method == http.MethodOptions && !isPreflight {
+				// plain OPTIONS request - not a preflight one
+				w.Header().Set("Allow", strings.Join(methods, ", "))
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if isPreflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if origin == "" || !isAllowedOrigin(origin) {
+				if isPreflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if isPreflight {
+				if !slices.Contains(methods, requestedMethod) || !areHeadersAllowed(r.Header.Get("Access-Control-Request-Headers"), allowedHeaders) {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+			}
+
+			if anyOrigin && !config.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if config.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if isPreflight {
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+				if allowedHeadersValue != "" {
+					w.Header().Set("Access-Control-Allow-Headers", allowedHeadersValue)
+				}
+				if config.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", maxAgeValue)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if exposedHeadersValue != "" {
+				w.Header().Set("Access-Control-Expose-Headers", exposedHeadersValue)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routeMethods methods of the matched route except OPTIONS
+func routeMethods(r *http.Request) []string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return defaultCORSMethods
+	}
+	methods, err := route.GetMethods()
+	if err != nil {
+		return defaultCORSMethods
+	}
+
+	return slices.DeleteFunc(slices.Clone(methods), func(method string) bool { return method == http.MethodOptions })
+}
+
+// areHeadersAllowed checks comma separated list of `Access-Control-Request-Headers`
+func areHeadersAllowed(requestedHeaders string, allowedHeaders map[string]bool) bool {
+	for _, header := range strings.Split(requestedHeaders, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !allowedHeaders[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+
+	return true
+}
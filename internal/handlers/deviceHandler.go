@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type DeviceRegistrationParams struct {
+	RoomId int    `json:"room_id"`
+	Name   string `json:"name"`
+}
+
+type RegisteredDevice struct {
+	Device      models.Device `json:"device"`
+	DeviceToken string        `json:"device_token"` // shown only once
+}
+
+func (h *Handlers) RegisterDevice(w http.ResponseWriter, r *http.Request) {
+	subjectStr := r.Header.Get("subject")
+	r.Header.Del("subject")
+	subjectWhoRegistersDevice, conversionError := strconv.Atoi(subjectStr)
+	if conversionError != nil {
+		slog.WarnContext(r.Context(), "DeviceHandler.RegisterDevice(): cannot convert `subject`-header to integer", "error", conversionError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", conversionError.Error())
+		return
+	}
+
+	var registrationParams DeviceRegistrationParams
+	// convert JSON to DeviceRegistrationParams type
+	err := json.NewDecoder(r.Body).Decode(&registrationParams)
+	if err != nil {
+		slog.WarnContext(r.Context(), "DeviceHandler.RegisterDevice(): cannot convert JSON to DeviceRegistrationParams struct", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to DeviceRegistrationParams struct", err.Error())
+		return
+	}
+
+	deviceParams := models.Device{
+		RoomId:    registrationParams.RoomId,
+		Name:      registrationParams.Name,
+		CreatedBy: subjectWhoRegistersDevice,
+	}
+
+	// validate passed device data
+	validator := NewDeviceValidator(&deviceParams)
+	if validator.AllDeviceFieldsValid != true {
+		slog.WarnContext(r.Context(), "DeviceHandler.RegisterDevice(): Device data is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "Device data is not valid", validator.ValidationErrors)
+		return
+	}
+
+	// register device
+	device, deviceToken, err := h.tenantService(r).DeviceService.Register(r.Context(), deviceParams)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DeviceHandler.RegisterDevice(): error occured during Device registration", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during Device registration", err.Error())
+		return
+	}
+
+	// return registered device with its token
+	pkg.Response(w, RegisteredDevice{Device: device, DeviceToken: deviceToken})
+}
+
+func (h *Handlers) GetAllDevices(w http.ResponseWriter, r *http.Request) {
+	// get all devices
+	devices := h.tenantService(r).DeviceService.GetAll(r.Context())
+
+	// return all devices
+	pkg.Response(w, devices)
+}
+
+func (h *Handlers) RevokeDevice(w http.ResponseWriter, r *http.Request) {
+	// get device_id from query path
+	deviceIdStr := r.URL.Query().Get("device_id")
+	if deviceIdStr == "" {
+		slog.WarnContext(r.Context(), "DeviceHandler.RevokeDevice(): parameter `device_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `device_id` is empty or not passed")
+		return
+	}
+
+	// convert device_id param string to int
+	deviceId, err := strconv.Atoi(deviceIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "DeviceHandler.RevokeDevice(): device_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "device_id should be an integer", err.Error())
+		return
+	}
+
+	// revoke device
+	_, err = h.tenantService(r).DeviceService.Revoke(r.Context(), deviceId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DeviceHandler.RevokeDevice(): error occured during device revocation", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during device revocation", err.Error())
+		return
+	}
+
+	// return success message
+	pkg.Response(w, "success")
+}
+
+type DeviceValidator struct {
+	DeviceToValidate     *models.Device    `json:"passed_device"`
+	ValidationErrors     map[string]string `json:"validation_errors"`
+	IsRoomIdValid        bool              `json:"is_room_id_valid"`
+	IsNameValid          bool              `json:"is_name_valid"`
+	AllDeviceFieldsValid bool              `json:"all_device_fields_valid"`
+}
+
+func NewDeviceValidator(device *models.Device) *DeviceValidator {
+	validationErrors := map[string]string{
+		"roomid_error": "Device.RoomId: should not be negative integer or zero",
+		"name_error":   "Device.Name: should not be empty string",
+	}
+
+	validator := &DeviceValidator{DeviceToValidate: device, ValidationErrors: validationErrors, AllDeviceFieldsValid: false}
+	validator.IsDeviceValid()
+
+	return validator
+}
+
+func (d *DeviceValidator) IsDeviceValid() {
+	d.ValidateFields()
+
+	if d.IsRoomIdValid && d.IsNameValid {
+		d.AllDeviceFieldsValid = true
+	}
+}
+
+func (d *DeviceValidator) ValidateFields() {
+	if d.DeviceToValidate.RoomId > 0 {
+		d.IsRoomIdValid = true
+		delete(d.ValidationErrors, "roomid_error")
+	}
+	if d.DeviceToValidate.Name != "" {
+		d.IsNameValid = true
+		delete(d.ValidationErrors, "name_error")
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type BookNowParams struct {
+	DurationMinutes int `json:"duration_minutes"` // optional - 30 minutes by default
+}
+
+func (h *Handlers) GetRoomDisplay(w http.ResponseWriter, r *http.Request) {
+	// get room_id from path
+	roomId, err := strconv.Atoi(mux.Vars(r)["room_id"])
+	if err != nil {
+		slog.WarnContext(r.Context(), "DisplayHandler.GetRoomDisplay(): room_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "room_id should be an integer", err.Error())
+		return
+	}
+
+	// get current state of the room
+	display, err := h.tenantService(r).DeviceService.GetRoomDisplay(r.Context(), roomId, time.Now())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DisplayHandler.GetRoomDisplay(): error occured during getting room display", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting room display", err.Error())
+		return
+	}
+
+	timeZone, ok := h.displayTimeZone(w, r, roomId)
+	if !ok {
+		return
+	}
+
+	pkg.Response(w, display.In(timeZone))
+}
+
+func (h *Handlers) BookRoomNow(w http.ResponseWriter, r *http.Request) {
+	device, err := h.getRequestDevice(r)
+	if err != nil {
+		slog.WarnContext(r.Context(), "DisplayHandler.BookRoomNow()", "passed_data", err)
+		pkg.ErrorResponse(w, http.StatusForbidden, "only room display devices can book room now", err.Error())
+		return
+	}
+
+	var bookNowParams BookNowParams
+	// body is optional
+	err = json.NewDecoder(r.Body).Decode(&bookNowParams)
+	if err != nil && !errors.Is(err, io.EOF) {
+		slog.WarnContext(r.Context(), "DisplayHandler.BookRoomNow(): cannot convert JSON to BookNowParams struct", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to BookNowParams struct", err.Error())
+		return
+	}
+
+	duration := time.Duration(bookNowParams.DurationMinutes) * time.Minute
+	createdBooking, err := h.tenantService(r).DeviceService.BookNow(r.Context(), device, duration, time.Now())
+	if err != nil {
+		slog.WarnContext(r.Context(), "DisplayHandler.BookRoomNow(): error occured during Room Booking", "error", err)
+		pkg.ErrorResponse(w, http.StatusConflict, "error occured during Room Booking", err.Error())
+		return
+	}
+
+	timeZone, ok := h.displayTimeZone(w, r, device.RoomId)
+	if !ok {
+		return
+	}
+
+	pkg.Response(w, createdBooking.In(timeZone))
+}
+
+func (h *Handlers) CheckInRoom(w http.ResponseWriter, r *http.Request) {
+	device, err := h.getRequestDevice(r)
+	if err != nil {
+		slog.WarnContext(r.Context(), "DisplayHandler.CheckInRoom()", "passed_data", err)
+		pkg.ErrorResponse(w, http.StatusForbidden, "only room display devices can check in", err.Error())
+		return
+	}
+
+	checkedInBooking, err := h.tenantService(r).DeviceService.CheckIn(r.Context(), device, time.Now())
+	if err != nil {
+		slog.WarnContext(r.Context(), "DisplayHandler.CheckInRoom(): error occured during check-in", "error", err)
+		pkg.ErrorResponse(w, http.StatusConflict, "error occured during check-in", err.Error())
+		return
+	}
+
+	timeZone, ok := h.displayTimeZone(w, r, device.RoomId)
+	if !ok {
+		return
+	}
+
+	pkg.Response(w, checkedInBooking.In(timeZone))
+}
+
+// displayTimeZone room displays show local time of the room unless other time zone is requested
+func (h *Handlers) displayTimeZone(w http.ResponseWriter, r *http.Request, roomId int) (*time.Location, bool) {
+	if r.URL.Query().Has(TimeZoneQueryParam) {
+		return h.responseTimeZone(w, r)
+	}
+
+	timeZone, err := h.tenantService(r).LocationService.GetTimeZoneByRoomId(r.Context(), roomId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "DisplayHandler.displayTimeZone(): error occured during getting time zone of the room", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting time zone of the room", err.Error())
+		return nil, false
+	}
+
+	return timeZone, true
+}
+
+// getRequestDevice returns device authenticated by AuthorizationCheck
+func (h *Handlers) getRequestDevice(r *http.Request) (models.Device, error) {
+	deviceIdStr := r.Header.Get("device")
+	r.Header.Del("device")
+	if deviceIdStr == "" {
+		return models.Device{}, errors.New("request is not sent by a device")
+	}
+
+	deviceId, err := strconv.Atoi(deviceIdStr)
+	if err != nil {
+		return models.Device{}, err
+	}
+
+	return h.tenantService(r).DeviceService.GetDeviceById(r.Context(), deviceId)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handlers) CreateFloor(w http.ResponseWriter, r *http.Request) {
+	subjectStr := r.Header.Get("subject")
+	r.Header.Del("subject")
+	subjectWhoCreatesFloor, conversionError := strconv.Atoi(subjectStr)
+	if conversionError != nil {
+		slog.WarnContext(r.Context(), "FloorHandler.CreateFloor(): cannot convert `subject`-header to integer", "error", conversionError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", conversionError.Error())
+		return
+	}
+
+	var floorParams models.Floor
+
+	// convert JSON to models.Floor type
+	err := json.NewDecoder(r.Body).Decode(&floorParams)
+	if err != nil {
+		slog.WarnContext(r.Context(), "FloorHandler.CreateFloor(): cannot convert JSON to models.Floor struct", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.Floor struct", err.Error())
+		return
+	}
+
+	// validate passed floor data
+	validator := NewFloorValidator(&floorParams)
+	if validator.AllFloorFieldsValid != true {
+		slog.WarnContext(r.Context(), "FloorHandler.CreateFloor(): Floor data is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "Floor data is not valid", validator.ValidationErrors)
+		return
+	}
+
+	floorParams.CreatedBy = subjectWhoCreatesFloor
+
+	// create floor
+	createdFloor, err := h.tenantService(r).FloorService.Create(r.Context(), floorParams)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "FloorHandler.CreateFloor(): error occured during Floor creation", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during Floor creation", err.Error())
+		return
+	}
+
+	// return created floor
+	pkg.Response(w, createdFloor)
+}
+
+func (h *Handlers) GetAllFloors(w http.ResponseWriter, r *http.Request) {
+	// get all floors
+	floors := h.tenantService(r).FloorService.GetAll(r.Context())
+
+	// return all floors
+	pkg.Response(w, floors)
+}
+
+func (h *Handlers) GetFloorById(w http.ResponseWriter, r *http.Request) {
+	// get floor_id from query path
+	floorIdStr := r.URL.Query().Get("floor_id")
+	if floorIdStr == "" {
+		slog.WarnContext(r.Context(), "FloorHandler.GetFloorById(): parameter `floor_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `floor_id` is empty or not passed")
+		return
+	}
+
+	// convert floor_id param string to int
+	floorId, err := strconv.Atoi(floorIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "FloorHandler.GetFloorById(): floor_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "floor_id should be an integer", err.Error())
+		return
+	}
+
+	// get Floor from services
+	floor, err := h.tenantService(r).FloorService.GetFloorById(r.Context(), floorId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "FloorHandler.GetFloorById(): error occured during getting floor by id", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting floor by id", err.Error())
+		return
+	}
+
+	// return found floor
+	pkg.Response(w, floor)
+}
+
+func (h *Handlers) UpdateFloor(w http.ResponseWriter, r *http.Request) {
+	// get floor_id from query path
+	floorIdStr := r.URL.Query().Get("floor_id")
+	if floorIdStr == "" {
+		slog.WarnContext(r.Context(), "FloorHandler.UpdateFloor(): parameter `floor_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `floor_id` is empty or not passed")
+		return
+	}
+
+	// convert floor_id param string to int
+	floorId, err := strconv.Atoi(floorIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "FloorHandler.UpdateFloor(): floor_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "floor_id should be an integer", err.Error())
+		return
+	}
+
+	var floorParamsToUpdate models.Floor
+	// convert JSON to models.Floor type
+	err = json.NewDecoder(r.Body).Decode(&floorParamsToUpdate)
+	if err != nil {
+		slog.WarnContext(r.Context(), "FloorHandler.UpdateFloor(): cannot convert JSON to models.Floor struct", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.Floor struct", err.Error())
+		return
+	}
+
+	// validate passed floor data
+	validator := NewFloorValidator(&floorParamsToUpdate)
+	if validator.AllFloorFieldsValid != true {
+		slog.WarnContext(r.Context(), "FloorHandler.UpdateFloor(): Floor data is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "Floor data is not valid", validator.ValidationErrors)
+		return
+	}
+
+	// to double-check if floor id wasn't set
+	floorParamsToUpdate.FloorId = floorId
+
+	// update floor
+	updatedFloor, err := h.tenantService(r).FloorService.Update(r.Context(), floorParamsToUpdate)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "FloorHandler.UpdateFloor(): error occured during floor update", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during floor update", err.Error())
+		return
+	}
+
+	// return updated floor
+	pkg.Response(w, updatedFloor)
+}
+
+func (h *Handlers) DeleteFloor(w http.ResponseWriter, r *http.Request) {
+	// get floor_id from query path
+	floorIdStr := r.URL.Query().Get("floor_id")
+	if floorIdStr == "" {
+		slog.WarnContext(r.Context(), "FloorHandler.DeleteFloor(): parameter `floor_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `floor_id` is empty or not passed")
+		return
+	}
+
+	// convert floor_id param string to int
+	floorId, err := strconv.Atoi(floorIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "FloorHandler.DeleteFloor(): floor_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "floor_id should be an integer", err.Error())
+		return
+	}
+
+	// delete floor
+	_, err = h.tenantService(r).FloorService.Delete(r.Context(), floorId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "FloorHandler.DeleteFloor(): error occured during floor deletion", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during floor deletion", err.Error())
+		return
+	}
+
+	// return success message
+	pkg.Response(w, "success")
+}
+
+type FloorValidator struct {
+	FloorToValidate     *models.Floor     `json:"passed_floor"`
+	ValidationErrors    map[string]string `json:"validation_errors"`
+	IsBuildingIdValid   bool              `json:"is_building_id_valid"`
+	IsNameValid         bool              `json:"is_name_valid"`
+	AllFloorFieldsValid bool              `json:"all_floor_fields_valid"`
+}
+
+func NewFloorValidator(floor *models.Floor) *FloorValidator {
+	validationErrors := map[string]string{
+		"buildingid_error": "Floor.BuildingId: should not be negative integer or zero",
+		"name_error":       "Floor.Name: should not be empty string",
+	}
+
+	validator := &FloorValidator{FloorToValidate: floor, ValidationErrors: validationErrors, AllFloorFieldsValid: false}
+	validator.IsFloorValid()
+
+	return validator
+}
+
+func (f *FloorValidator) IsFloorValid() {
+	f.ValidateFields()
+
+	if f.IsBuildingIdValid && f.IsNameValid {
+		f.AllFloorFieldsValid = true
+	}
+}
+
+func (f *FloorValidator) ValidateFields() {
+	if f.FloorToValidate.BuildingId > 0 {
+		f.IsBuildingIdValid = true
+		delete(f.ValidationErrors, "buildingid_error")
+	}
+	if f.FloorToValidate.Name != "" {
+		f.IsNameValid = true
+		delete(f.ValidationErrors, "name_error")
+	}
+}
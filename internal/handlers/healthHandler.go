@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ReadinessCheckTimeout time given to DB to answer readiness probe
+const ReadinessCheckTimeout = 2 * time.Second
+
+// Healthz liveness probe - process is up and serves requests
+func (h *Handlers) Healthz(w http.ResponseWriter, r *http.Request) {
+	pkg.Response(w, map[string]string{"status": "ok"})
+}
+
+// Readyz readiness probe - DB is reachable and server is not shutting down
+func (h *Handlers) Readyz(w http.ResponseWriter, r *http.Request) {
+	if h.shuttingDown.Load() {
+		pkg.ErrorResponse(w, http.StatusServiceUnavailable, "server is shutting down")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), ReadinessCheckTimeout)
+	defer cancel()
+
+	if err := h.service.HealthService.Ping(ctx); err != nil {
+		slog.ErrorContext(r.Context(), "HealthHandler.Readyz(): db is unreachable", "error", err)
+		pkg.ErrorResponse(w, http.StatusServiceUnavailable, "db is unreachable", err.Error())
+		return
+	}
+
+	pkg.Response(w, map[string]string{"status": "ready"})
+}
+
+// StartShutdown makes readiness probe fail, so load balancer stops sending new requests while in-flight ones are drained
+func (h *Handlers) StartShutdown() {
+	h.shuttingDown.Store(true)
+}
@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handlers) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	subjectStr := r.Header.Get("subject")
+	r.Header.Del("subject")
+	subjectWhoCreatesLocation, conversionError := strconv.Atoi(subjectStr)
+	if conversionError != nil {
+		slog.WarnContext(r.Context(), "LocationHandler.CreateLocation(): cannot convert `subject`-header to integer", "error", conversionError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", conversionError.Error())
+		return
+	}
+
+	var locationParams models.Location
+
+	// convert JSON to models.Location type
+	err := json.NewDecoder(r.Body).Decode(&locationParams)
+	if err != nil {
+		slog.WarnContext(r.Context(), "LocationHandler.CreateLocation(): cannot convert JSON to models.Location struct", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.Location struct", err.Error())
+		return
+	}
+
+	// validate passed location data
+	validator := NewLocationValidator(&locationParams)
+	if validator.AllLocationFieldsValid != true {
+		slog.WarnContext(r.Context(), "LocationHandler.CreateLocation(): Location data is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "Location data is not valid", validator.ValidationErrors)
+		return
+	}
+
+	locationParams.CreatedBy = subjectWhoCreatesLocation
+
+	// create location
+	createdLocation, err := h.tenantService(r).LocationService.Create(r.Context(), locationParams)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "LocationHandler.CreateLocation(): error occured during Location creation", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during Location creation", err.Error())
+		return
+	}
+
+	// return created location
+	pkg.Response(w, createdLocation)
+}
+
+func (h *Handlers) GetAllLocations(w http.ResponseWriter, r *http.Request) {
+	// get all locations
+	locations := h.tenantService(r).LocationService.GetAll(r.Context())
+
+	// return all locations
+	pkg.Response(w, locations)
+}
+
+func (h *Handlers) GetLocationById(w http.ResponseWriter, r *http.Request) {
+	// get location_id from query path
+	locationIdStr := r.URL.Query().Get("location_id")
+	if locationIdStr == "" {
+		slog.WarnContext(r.Context(), "LocationHandler.GetLocationById(): parameter `location_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `location_id` is empty or not passed")
+		return
+	}
+
+	// convert location_id param string to int
+	locationId, err := strconv.Atoi(locationIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "LocationHandler.GetLocationById(): location_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "location_id should be an integer", err.Error())
+		return
+	}
+
+	// get Location from services
+	location, err := h.tenantService(r).LocationService.GetLocationById(r.Context(), locationId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "LocationHandler.GetLocationById(): error occured during getting location by id", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting location by id", err.Error())
+		return
+	}
+
+	// return found location
+	pkg.Response(w, location)
+}
+
+func (h *Handlers) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	// get location_id from query path
+	locationIdStr := r.URL.Query().Get("location_id")
+	if locationIdStr == "" {
+		slog.WarnContext(r.Context(), "LocationHandler.UpdateLocation(): parameter `location_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `location_id` is empty or not passed")
+		return
+	}
+
+	// convert location_id param string to int
+	locationId, err := strconv.Atoi(locationIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "LocationHandler.UpdateLocation(): location_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "location_id should be an integer", err.Error())
+		return
+	}
+
+	var locationParamsToUpdate models.Location
+	// convert JSON to models.Location type
+	err = json.NewDecoder(r.Body).Decode(&locationParamsToUpdate)
+	if err != nil {
+		slog.WarnContext(r.Context(), "LocationHandler.UpdateLocation(): cannot convert JSON to models.Location struct", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.Location struct", err.Error())
+		return
+	}
+
+	// validate passed location data
+	validator := NewLocationValidator(&locationParamsToUpdate)
+	if validator.AllLocationFieldsValid != true {
+		slog.WarnContext(r.Context(), "LocationHandler.UpdateLocation(): Location data is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "Location data is not valid", validator.ValidationErrors)
+		return
+	}
+
+	// to double-check if location id wasn't set
+	locationParamsToUpdate.LocationId = locationId
+
+	// update location
+	updatedLocation, err := h.tenantService(r).LocationService.Update(r.Context(), locationParamsToUpdate)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "LocationHandler.UpdateLocation(): error occured during location update", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during location update", err.Error())
+		return
+	}
+
+	// return updated location
+	pkg.Response(w, updatedLocation)
+}
+
+func (h *Handlers) DeleteLocation(w http.ResponseWriter, r *http.Request) {
+	// get location_id from query path
+	locationIdStr := r.URL.Query().Get("location_id")
+	if locationIdStr == "" {
+		slog.WarnContext(r.Context(), "LocationHandler.DeleteLocation(): parameter `location_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `location_id` is empty or not passed")
+		return
+	}
+
+	// convert location_id param string to int
+	locationId, err := strconv.Atoi(locationIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "LocationHandler.DeleteLocation(): location_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "location_id should be an integer", err.Error())
+		return
+	}
+
+	// delete location
+	_, err = h.tenantService(r).LocationService.Delete(r.Context(), locationId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "LocationHandler.DeleteLocation(): error occured during location deletion", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during location deletion", err.Error())
+		return
+	}
+
+	// return success message
+	pkg.Response(w, "success")
+}
+
+func (h *Handlers) GetRoomsByLocationId(w http.ResponseWriter, r *http.Request) {
+	// get location_id from query path
+	locationIdStr := r.URL.Query().Get("location_id")
+	if locationIdStr == "" {
+		slog.WarnContext(r.Context(), "LocationHandler.GetRoomsByLocationId(): parameter `location_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `location_id` is empty or not passed")
+		return
+	}
+
+	// convert location_id param string to int
+	locationId, err := strconv.Atoi(locationIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "LocationHandler.GetRoomsByLocationId(): location_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "location_id should be an integer", err.Error())
+		return
+	}
+
+	// get Room slice from services
+	rooms, err := h.tenantService(r).RoomService.GetRoomsByLocationId(r.Context(), locationId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "LocationHandler.GetRoomsByLocationId(): error occured during getting rooms by location id", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting rooms by location id", err.Error())
+		return
+	}
+
+	// return found rooms
+	pkg.Response(w, rooms)
+}
+
+func (h *Handlers) GetAvailableRoomsByLocationId(w http.ResponseWriter, r *http.Request) {
+	// validate params
+	validator := NewBookingQueryParamsValidator(r.URL.RawQuery)
+
+	if validator.AllQueryParamsValid == false {
+		slog.WarnContext(r.Context(), "LocationHandler.GetAvailableRoomsByLocationId(): query is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "query is not valid", validator.ValidationErrors)
+		return
+	}
+
+	if validator.DateTimeStart.IsZero() || validator.DateTimeEnd.IsZero() {
+		slog.WarnContext(r.Context(), "LocationHandler.GetAvailableRoomsByLocationId(): parameters `datetime_start` and `datetime_end` are required")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameters `datetime_start` and `datetime_end` are required")
+		return
+	}
+
+	// convert location_id param string to int
+	locationId, err := strconv.Atoi(r.URL.Query().Get("location_id"))
+	if err != nil {
+		slog.WarnContext(r.Context(), "LocationHandler.GetAvailableRoomsByLocationId(): location_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "location_id should be an integer", err.Error())
+		return
+	}
+
+	// get available Room slice from services
+	rooms, err := h.tenantService(r).RoomService.GetAvailableRoomsByLocationId(r.Context(), locationId, validator.DateTimeStart, validator.DateTimeEnd)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "LocationHandler.GetAvailableRoomsByLocationId(): error occured during getting available rooms", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting available rooms", err.Error())
+		return
+	}
+
+	// return found rooms
+	pkg.Response(w, rooms)
+}
+
+func (h *Handlers) GetOpeningHours(w http.ResponseWriter, r *http.Request) {
+	// get location_id from query path
+	locationIdStr := r.URL.Query().Get("location_id")
+	if locationIdStr == "" {
+		slog.WarnContext(r.Context(), "LocationHandler.GetOpeningHours(): parameter `location_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `location_id` is empty or not passed")
+		return
+	}
+
+	// convert location_id param string to int
+	locationId, err := strconv.Atoi(locationIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "LocationHandler.GetOpeningHours(): location_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "location_id should be an integer", err.Error())
+		return
+	}
+
+	// get OpeningHours slice from services
+	openingHours, err := h.tenantService(r).LocationService.GetOpeningHours(r.Context(), locationId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "LocationHandler.GetOpeningHours(): error occured during getting opening hours", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting opening hours", err.Error())
+		return
+	}
+
+	// return found opening hours
+	pkg.Response(w, openingHours)
+}
+
+func (h *Handlers) UpdateOpeningHours(w http.ResponseWriter, r *http.Request) {
+	// get location_id from query path
+	locationIdStr := r.URL.Query().Get("location_id")
+	if locationIdStr == "" {
+		slog.WarnContext(r.Context(), "LocationHandler.UpdateOpeningHours(): parameter `location_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `location_id` is empty or not passed")
+		return
+	}
+
+	// convert location_id param string to int
+	locationId, err := strconv.Atoi(locationIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "LocationHandler.UpdateOpeningHours(): location_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "location_id should be an integer", err.Error())
+		return
+	}
+
+	var openingHours []models.OpeningHours
+	// convert JSON to []models.OpeningHours type
+	err = json.NewDecoder(r.Body).Decode(&openingHours)
+	if err != nil {
+		slog.WarnContext(r.Context(), "LocationHandler.UpdateOpeningHours(): cannot convert JSON to []models.OpeningHours", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to []models.OpeningHours", err.Error())
+		return
+	}
+
+	// replace opening hours of location
+	updatedOpeningHours, err := h.tenantService(r).LocationService.SetOpeningHours(r.Context(), locationId, openingHours)
+	if err != nil {
+		slog.WarnContext(r.Context(), "LocationHandler.UpdateOpeningHours(): error occured during opening hours update", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during opening hours update", err.Error())
+		return
+	}
+
+	// return updated opening hours
+	pkg.Response(w, updatedOpeningHours)
+}
+
+type LocationValidator struct {
+	LocationToValidate     *models.Location  `json:"passed_location"`
+	ValidationErrors       map[string]string `json:"validation_errors"`
+	IsNameValid            bool              `json:"is_name_valid"`
+	IsTimeZoneValid        bool              `json:"is_time_zone_valid"`
+	AllLocationFieldsValid bool              `json:"all_location_fields_valid"`
+}
+
+func NewLocationValidator(location *models.Location) *LocationValidator {
+	validationErrors := map[string]string{
+		"name_error":      "Location.Name: should not be empty string",
+		"time_zone_error": "Location.TimeZone: should be IANA time zone name",
+	}
+
+	validator := &LocationValidator{LocationToValidate: location, ValidationErrors: validationErrors, AllLocationFieldsValid: false}
+	validator.IsLocationValid()
+
+	return validator
+}
+
+func (l *LocationValidator) IsLocationValid() {
+	l.ValidateFields()
+
+	if l.IsNameValid && l.IsTimeZoneValid {
+		l.AllLocationFieldsValid = true
+	}
+}
+
+func (l *LocationValidator) ValidateFields() {
+	if l.LocationToValidate.Name != "" {
+		l.IsNameValid = true
+		delete(l.ValidationErrors, "name_error")
+	}
+	if l.LocationToValidate.TimeZone != "" {
+		l.IsTimeZoneValid = true
+		delete(l.ValidationErrors, "time_zone_error")
+	}
+}
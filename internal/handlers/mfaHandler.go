@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type MFALoginParams struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"` // TOTP code or recovery code
+}
+
+type MFACodeParams struct {
+	Code string `json:"code"` // TOTP code or recovery code
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAPolicyParams struct {
+	RoleIds []int `json:"role_ids"`
+}
+
+// LoginMFA second step of log in: MFA token of the first step and TOTP or recovery code are exchanged for tokens
+func (h *Handlers) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	params := MFALoginParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		slog.WarnContext(r.Context(), "MFAHandler.LoginMFA(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+	if params.MFAToken == "" || params.Code == "" {
+		slog.WarnContext(r.Context(), "MFAHandler.LoginMFA(): mfa_token or code is empty")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "mfa_token and code should not be empty")
+		return
+	}
+
+	identity := requestIdentity(r)
+	claims, err := h.service.MFAService.ValidateChallenge(r.Context(), params.MFAToken, identity)
+	if err != nil {
+		slog.WarnContext(r.Context(), "MFAHandler.LoginMFA(): MFA token is not valid", "error", err)
+		pkg.ErrorResponse(w, http.StatusUnauthorized, "MFA token is not valid", err.Error())
+		return
+	}
+
+	tenantService, organizationError := h.organizationService(r.Context(), claims.Organization)
+	if organizationError != nil {
+		slog.WarnContext(r.Context(), "MFAHandler.LoginMFA(): organization of the MFA token is not valid", "error", organizationError)
+		pkg.ErrorResponse(w, http.StatusUnauthorized, "MFA token is not valid")
+		return
+	}
+	userId, _ := strconv.Atoi(claims.Subject)
+	user, err := tenantService.UserService.GetUserById(r.Context(), userId)
+	if err != nil || !user.Active {
+		slog.WarnContext(r.Context(), "MFAHandler.LoginMFA(): user of the MFA token is not found", "passed_data", claims.Subject)
+		pkg.ErrorResponse(w, http.StatusUnauthorized, "MFA token is not valid")
+		return
+	}
+
+	// codes are guessed the same way as passwords - the same limits and lock of the account
+	if limitError := h.limiter.AllowLogin(r.Context(), ClientIP(r), user.UserName); limitError != nil {
+		tooManyRequestsResponse(w, limitError)
+		return
+	}
+
+	authMethods, err := tenantService.MFAService.Verify(r.Context(), user, params.Code)
+	if err != nil {
+		slog.WarnContext(r.Context(), "MFAHandler.LoginMFA(): second factor is not valid", "user_id", user.UserId, "error", err)
+		h.loginFailed(w, r, user.UserName, services.InvalidMFACodeError)
+		return
+	}
+	h.limiter.LoginSucceeded(r.Context(), user.UserName)
+
+	token, refreshToken := h.service.AuthService.GenerateTokens(r.Context(), user, identity, authMethods)
+	pkg.Response(w, JWTTokens{AccessToken: token, RefreshToken: refreshToken})
+}
+
+// EnrollMFA returns new TOTP secret of the caller and otpauth URI for QR code. TOTP is enabled by ConfirmMFA
+func (h *Handlers) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	userId, ok := subjectUserId(w, r, "MFAHandler.EnrollMFA()")
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.tenantService(r).MFAService.Enroll(r.Context(), userId)
+	if err != nil {
+		slog.WarnContext(r.Context(), "MFAHandler.EnrollMFA(): error occured during TOTP enrollment", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during TOTP enrollment", err.Error())
+		return
+	}
+
+	pkg.Response(w, enrollment)
+}
+
+// ConfirmMFA enables TOTP by the first code from authenticator app. Recovery codes are returned only once
+func (h *Handlers) ConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	userId, params, ok := mfaCodeRequest(w, r, "MFAHandler.ConfirmMFA()")
+	if !ok {
+		return
+	}
+
+	codes, err := h.tenantService(r).MFAService.Confirm(r.Context(), userId, params.Code)
+	if err != nil {
+		slog.WarnContext(r.Context(), "MFAHandler.ConfirmMFA(): error occured during TOTP confirmation", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during TOTP confirmation", err.Error())
+		return
+	}
+
+	pkg.Response(w, RecoveryCodes{RecoveryCodes: codes})
+}
+
+func (h *Handlers) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	userId, params, ok := mfaCodeRequest(w, r, "MFAHandler.DisableMFA()")
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService(r).MFAService.Disable(r.Context(), userId, params.Code); err != nil {
+		slog.WarnContext(r.Context(), "MFAHandler.DisableMFA(): error occured during TOTP disabling", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during TOTP disabling", err.Error())
+		return
+	}
+
+	pkg.Response(w, "two-factor authentication is disabled")
+}
+
+// RegenerateRecoveryCodes replaces recovery codes of the caller - used ones and lost ones become invalid
+func (h *Handlers) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userId, params, ok := mfaCodeRequest(w, r, "MFAHandler.RegenerateRecoveryCodes()")
+	if !ok {
+		return
+	}
+
+	codes, err := h.tenantService(r).MFAService.RegenerateRecoveryCodes(r.Context(), userId, params.Code)
+	if err != nil {
+		slog.WarnContext(r.Context(), "MFAHandler.RegenerateRecoveryCodes(): error occured during recovery codes generation", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during recovery codes generation", err.Error())
+		return
+	}
+
+	pkg.Response(w, RecoveryCodes{RecoveryCodes: codes})
+}
+
+// GetMFAPolicy roles of the caller's organization which should use two-factor authentication
+func (h *Handlers) GetMFAPolicy(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.tenantService(r).MFAService.GetMFAPolicies(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "MFAHandler.GetMFAPolicy(): error occured during MFA policy search", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during MFA policy search", err.Error())
+		return
+	}
+
+	pkg.Response(w, policies)
+}
+
+// UpdateMFAPolicy replaces roles of the caller's organization which should use two-factor authentication
+func (h *Handlers) UpdateMFAPolicy(w http.ResponseWriter, r *http.Request) {
+	userId, ok := subjectUserId(w, r, "MFAHandler.UpdateMFAPolicy()")
+	if !ok {
+		return
+	}
+
+	params := MFAPolicyParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		slog.WarnContext(r.Context(), "MFAHandler.UpdateMFAPolicy(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+
+	policies, err := h.tenantService(r).MFAService.ReplaceMFAPolicies(r.Context(), params.RoleIds, userId)
+	if err != nil {
+		slog.WarnContext(r.Context(), "MFAHandler.UpdateMFAPolicy(): error occured during MFA policy update", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during MFA policy update", err.Error())
+		return
+	}
+
+	pkg.Response(w, policies)
+}
+
+// subjectUserId user_id of the caller written by AuthorizationCheck
+func subjectUserId(w http.ResponseWriter, r *http.Request, caller string) (int, bool) {
+	userId, err := strconv.Atoi(r.Header.Get("subject"))
+	if err != nil {
+		slog.WarnContext(r.Context(), caller+": cannot convert `subject`-header to integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", err.Error())
+		return 0, false
+	}
+
+	return userId, true
+}
+
+func mfaCodeRequest(w http.ResponseWriter, r *http.Request, caller string) (int, MFACodeParams, bool) {
+	userId, ok := subjectUserId(w, r, caller)
+	if !ok {
+		return 0, MFACodeParams{}, false
+	}
+
+	params := MFACodeParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		slog.WarnContext(r.Context(), caller+": error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return 0, MFACodeParams{}, false
+	}
+	if params.Code == "" {
+		slog.WarnContext(r.Context(), caller+": code is empty")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "code should not be empty")
+		return 0, MFACodeParams{}, false
+	}
+
+	return userId, params, true
+}
@@ -1,23 +1,154 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"go-booking-system/internal/metrics"
+	"go-booking-system/internal/services"
+	"go-booking-system/internal/tracing"
 	"go-booking-system/pkg"
-	"log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
+	"strconv"
 	"strings"
+	"time"
 )
 
-func CORS(next http.Handler) http.Handler {
+const DeviceAuthorizationScheme = "Device"
+
+// APIKeyHeader and APIKeyAuthorizationScheme API key of service account: `X-API-Key: <key>` or `Authorization: ApiKey <key>`
+const (
+	APIKeyHeader              = "X-API-Key"
+	APIKeyAuthorizationScheme = "ApiKey"
+)
+
+// RequestIdHeader id of the request - taken from the client or generated, returned in the response
+const RequestIdHeader = "X-Request-ID"
+
+// maxRequestIdLength longer ids passed by clients are replaced by generated ones
+const maxRequestIdLength = 64
+
+// maxPolicyBodySize fields of longer JSON bodies are not attributes of access policies
+const maxPolicyBodySize = 1 << 20
+
+// RequestId adds id of the request to the context of the request, so every record logged with it can be found by id.
+// Logs method, path, status and duration of every request
+func RequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(RequestIdHeader)
+		if !isValidRequestId(requestId) {
+			generatedId, err := pkg.GenerateRandomToken(16)
+			if err != nil {
+				slog.Error("MiddleWare.RequestId(): error occured during request id generation", "error", err)
+			}
+			requestId = generatedId
+		}
+		w.Header().Set(RequestIdHeader, requestId)
+		r = r.WithContext(pkg.WithRequestId(r.Context(), requestId))
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		slog.InfoContext(r.Context(), "request processed", "method", r.Method, "path", r.URL.Path,
+			"status", recorder.status, "duration", time.Since(start))
+	})
+}
+
+// isValidRequestId allows only ids which are safe to write to logs and headers
+func isValidRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+	for _, char := range requestId {
+		isLetterOrDigit := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+		if !isLetterOrDigit && char != '-' && char != '_' && char != '.' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// statusRecorder remembers status code written by handlers
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// tracer starts spans of the requests and of the authorization check
+var tracer = otel.Tracer(tracing.ServiceName)
+
+// Tracing starts server span of the request. Trace of the caller is continued if W3C `traceparent` header is passed,
+// `traceparent` of the request is returned in the response
+func Tracing(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "*")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS, PUT, HEAD, TRACE, CONNECT")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Allow-Headers", "*")
-		w.Header().Set("Access-Control-Expose-Headers", "*")
-		if r.Method == "OPTIONS" {
-			w.Write([]byte("OPTIONS"))
-			w.WriteHeader(http.StatusOK)
+		propagator := otel.GetTextMapPropagator()
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		route := routePath(r)
+		ctx, span := tracer.Start(ctx, r.Method+" "+route, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("http.route", route),
+			attribute.String("request_id", pkg.RequestIdFromContext(ctx)),
+		))
+		defer span.End()
+		propagator.Inject(ctx, propagation.HeaderCarrier(w.Header()))
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
+
+// endSpanBeforeNext ends span of the middleware before the next handler is called, so spans of the handler become
+// children of the parent span and not of the finished middleware span
+func endSpanBeforeNext(span trace.Span, parentSpan trace.Span, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span.End()
+		next.ServeHTTP(w, r.WithContext(trace.ContextWithSpan(r.Context(), parentSpan)))
+	})
+}
+
+// Metrics counts requests and measures their duration by route template (not by path - ids would produce too many series)
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		route, status := routePath(r), strconv.Itoa(recorder.status)
+		metrics.HTTPRequestsTotal.WithLabelValues(route, r.Method, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, r.Method, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// MetricsAuth protects /metrics endpoint by static token (`Authorization: Bearer <token>`). Empty token - endpoint is open
+func MetricsAuth(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			slog.WarnContext(r.Context(), "MiddleWare.MetricsAuth(): access denied - metrics token is not valid")
+			pkg.ErrorResponse(w, http.StatusUnauthorized, "access denied")
 			return
 		}
 		next.ServeHTTP(w, r)
@@ -28,8 +159,7 @@ func RecoverAllPanic(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Println(err)
-				log.Println("Panic is processed!")
+				slog.ErrorContext(r.Context(), "MiddleWare.RecoverAllPanic(): panic is processed", "error", err, "stack", string(debug.Stack()))
 				http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
 				return
 			}
@@ -40,17 +170,33 @@ func RecoverAllPanic(next http.Handler) http.Handler {
 
 func (h *Handlers) AuthorizationCheck(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), "MiddleWare.AuthorizationCheck")
+		defer span.End()
+		next := endSpanBeforeNext(span, trace.SpanFromContext(r.Context()), next)
+		r = r.WithContext(ctx)
+
 		// check destination address
 		destination := r.URL
 		// check if `Authorization` header exists
 		authorizationHeader := r.Header.Get("Authorization")
+		// these headers are filled only by this middleware
+		r.Header.Del("subject")
+		r.Header.Del("device")
+		r.Header.Del("api-key")
 
 		destinationPathIsAuthLogin := destination.Path == "/auth/login"
 		destinationPathIsAuthRegister := destination.Path == "/auth/register"
+		destinationPathIsAuthLoginMFA := destination.Path == "/auth/login/mfa"
+		destinationPathIsAuthSSO := destination.Path == "/auth/oidc/login" || destination.Path == "/auth/oidc/callback"
 		destinationPathIsAuthRefresh := destination.Path == "/auth/refresh"
+		destinationPathIsAuthAccount := destination.Path == "/auth/forgot-password" || destination.Path == "/auth/reset-password" ||
+			destination.Path == "/auth/verify-email" || destination.Path == "/auth/verify-email/resend"
 		destinationPathIsSwagger := strings.HasPrefix(destination.Path, "/swagger")
+		destinationPathIsMetrics := destination.Path == "/metrics"
+		destinationPathIsHealthCheck := destination.Path == "/healthz" || destination.Path == "/readyz"
 
-		if destinationPathIsSwagger {
+		// metrics endpoint is protected by its own token
+		if destinationPathIsSwagger || destinationPathIsMetrics || destinationPathIsHealthCheck {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -61,25 +207,42 @@ func (h *Handlers) AuthorizationCheck(next http.Handler) http.Handler {
 				next.ServeHTTP(w, r)
 				return
 			} else {
-				log.Println("MiddleWare.AuthorizationCheck(`/auth/refresh`): error occured before token refreshment: `Authorization` header is empty")
+				slog.WarnContext(r.Context(), "MiddleWare.AuthorizationCheck(`/auth/refresh`): error occured before token refreshment: `Authorization` header is empty")
 				pkg.ErrorResponse(w, http.StatusBadRequest, "error occured before token refreshment", "`Authorization` header is empty")
 				return
 			}
 		}
 
 		// if user wants to log in or register and header `authorization` should be empty => procceed to next.ServeHTTP(w, r)
-		if destinationPathIsAuthLogin || destinationPathIsAuthRegister {
+		if destinationPathIsAuthLogin || destinationPathIsAuthRegister || destinationPathIsAuthLoginMFA || destinationPathIsAuthSSO || destinationPathIsAuthAccount {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		ipAddress := strings.Split(r.RemoteAddr, ":")[0]
-		encodedAccessToken := strings.Split(authorizationHeader, " ")[1]
+		// room displays are authenticated by device token
+		if strings.HasPrefix(authorizationHeader, DeviceAuthorizationScheme+" ") {
+			h.DeviceAuthorizationCheck(next, w, r)
+			return
+		}
+		// integrations are authenticated by API key of service account
+		if apiKey := apiKeyOf(r); apiKey != "" {
+			h.APIKeyAuthorizationCheck(next, w, r, apiKey)
+			return
+		}
+
+		// else `Authorization: Bearer <access token>` is expected
+		scheme, encodedAccessToken, found := strings.Cut(authorizationHeader, " ")
+		encodedAccessToken = strings.TrimSpace(encodedAccessToken)
+		if !found || !strings.EqualFold(scheme, "Bearer") || encodedAccessToken == "" {
+			slog.WarnContext(r.Context(), "AuthHandler.AuthorizationCheck(): access denied - no access token in `Authorization` header")
+			pkg.ErrorResponse(w, http.StatusUnauthorized, "access denied", "`Authorization` header should be `Bearer <access token>`")
+			return
+		}
 
 		// else check Access Token JWT in `Authorization` header is valid
-		validator := h.service.AuthService.ValidateAccessToken(encodedAccessToken, ipAddress)
+		validator := h.service.AuthService.ValidateAccessToken(r.Context(), encodedAccessToken, requestIdentity(r))
 		if validator.ValidationError != nil {
-			log.Println("AuthHandler.AuthorizationCheck(): validation of Access JWT token failed. Details: ", validator.ValidationError)
+			slog.WarnContext(r.Context(), "AuthHandler.AuthorizationCheck(): validation of Access JWT token failed", "error", validator.ValidationError)
 			pkg.ErrorResponse(w, http.StatusBadRequest, "validation of Access JWT token failed", validator.ValidationError.Error())
 			return
 		}
@@ -87,37 +250,250 @@ func (h *Handlers) AuthorizationCheck(next http.Handler) http.Handler {
 		subjectString := validator.AccessTokenClaims.Subject
 		roleString := validator.AccessTokenClaims.Role
 
-		var recordIdString string
-		if r.URL.Query().Has("booking_id") {
-			recordIdString = r.URL.Query().Get("booking_id")
-		}
-		if r.URL.Query().Has("room_id") {
-			recordIdString = r.URL.Query().Get("room_id")
+		// all further queries of the request are limited to organization of the token
+		tenantService, organizationError := h.organizationService(r.Context(), validator.AccessTokenClaims.Organization)
+		if organizationError != nil {
+			slog.WarnContext(r.Context(), "AuthHandler.AuthorizationCheck(): access denied - organization of the token is not valid", "error", organizationError)
+			pkg.ErrorResponse(w, http.StatusUnauthorized, "access denied", organizationError.Error())
+			return
 		}
-		if r.URL.Query().Has("user_id") {
-			recordIdString = r.URL.Query().Get("user_id")
+
+		// deactivated users lose access at once, not when their access token expires
+		userId, _ := strconv.Atoi(subjectString)
+		tokenUser, userError := tenantService.UserService.GetUserById(r.Context(), userId)
+		if userError != nil || tokenUser.Active != true {
+			slog.WarnContext(r.Context(), "AuthHandler.AuthorizationCheck(): access denied - user of the token is not active", "user_id", subjectString, "error", userError)
+			pkg.ErrorResponse(w, http.StatusUnauthorized, "access denied", "user is not active")
+			return
 		}
 
-		var recordType string
-		recordType = strings.Split(destination.Path, "/")[1]
+		recordType, recordIdString := recordOf(r)
 
 		// check for permission to
-		isAccessGranted, permissionCheckError := h.service.AuthService.CheckPermissions(destination.Path, recordType, recordIdString, subjectString, roleString)
+		isAccessGranted, permissionCheckError := tenantService.AuthService.CheckPermissions(r.Context(), routePath(r), recordType, recordIdString, subjectString, roleString, requestAttributesOf(r))
 		if permissionCheckError != nil {
-			log.Println("AuthHandler.AuthorizationCheck(): error occurred during permission check. Details: ", permissionCheckError)
+			slog.WarnContext(r.Context(), "AuthHandler.AuthorizationCheck(): error occurred during permission check", "error", permissionCheckError)
 			pkg.ErrorResponse(w, http.StatusBadRequest, "error occurred during permission check", permissionCheckError.Error())
 			return
 		}
 
+		span.SetAttributes(attribute.String("role", roleString), attribute.Bool("access_granted", isAccessGranted))
 		if isAccessGranted != true {
-			log.Println("AuthHandler.AuthorizationCheck(): access denied")
+			metrics.PermissionDenialsTotal.WithLabelValues(roleString).Inc()
+			slog.WarnContext(r.Context(), "AuthHandler.AuthorizationCheck(): access denied")
 			pkg.ErrorResponse(w, http.StatusUnauthorized, "access denied")
 			return
 		}
 
+		// routes marked `mfa_required` and roles of the MFA policy need token issued after second factor
+		roleId, _ := strconv.Atoi(roleString)
+		if mfaError := tenantService.MFAService.CheckMFARequirement(r.Context(), routePath(r), roleId, validator.AccessTokenClaims.AuthMethods); mfaError != nil {
+			slog.WarnContext(r.Context(), "AuthHandler.AuthorizationCheck(): access denied - second factor is required", "error", mfaError)
+			pkg.ErrorResponse(w, http.StatusForbidden, "access denied", mfaError.Error())
+			return
+		}
+
 		// write subject(user_id) for filling `CreatedBy` field during record creation
 		r.Header.Add("subject", subjectString)
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, withTenantService(r, tenantService))
 	})
 }
+
+// APIKeyAuthorizationCheck lets service accounts access routes allowed both by their role and by the API key
+func (h *Handlers) APIKeyAuthorizationCheck(next http.Handler, w http.ResponseWriter, r *http.Request, key string) {
+	apiKey, serviceAccount, err := h.service.ServiceAccountService.Authenticate(r.Context(), key)
+	if err != nil {
+		slog.WarnContext(r.Context(), "AuthHandler.APIKeyAuthorizationCheck(): API key authentication failed", "error", err)
+		pkg.ErrorResponse(w, http.StatusUnauthorized, "API key authentication failed", err.Error())
+		return
+	}
+
+	tenantService, organizationError := h.organizationService(r.Context(), strconv.Itoa(apiKey.OrganizationId))
+	if organizationError != nil {
+		slog.WarnContext(r.Context(), "AuthHandler.APIKeyAuthorizationCheck(): access denied - organization of the API key is not valid", "error", organizationError)
+		pkg.ErrorResponse(w, http.StatusUnauthorized, "access denied", organizationError.Error())
+		return
+	}
+
+	subjectString, roleString := strconv.Itoa(serviceAccount.UserId), strconv.Itoa(serviceAccount.RoleId)
+	recordType, recordIdString := recordOf(r)
+	isAccessGranted, permissionCheckError := tenantService.AuthService.CheckPermissions(r.Context(), routePath(r), recordType, recordIdString, subjectString, roleString, requestAttributesOf(r))
+	if permissionCheckError == nil && isAccessGranted {
+		isAccessGranted, permissionCheckError = tenantService.AuthService.CheckAPIKeyPermissions(r.Context(), apiKey, serviceAccount.RoleId, routePath(r), recordType, recordIdString)
+	}
+	if permissionCheckError != nil {
+		slog.WarnContext(r.Context(), "AuthHandler.APIKeyAuthorizationCheck(): error occurred during permission check", "error", permissionCheckError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occurred during permission check", permissionCheckError.Error())
+		return
+	}
+	if isAccessGranted != true {
+		metrics.PermissionDenialsTotal.WithLabelValues(roleString).Inc()
+		slog.WarnContext(r.Context(), "AuthHandler.APIKeyAuthorizationCheck(): access denied", "key_id", apiKey.APIKeyId)
+		pkg.ErrorResponse(w, http.StatusUnauthorized, "access denied")
+		return
+	}
+
+	// API key is not a second factor - routes requiring MFA are not available
+	if mfaError := tenantService.MFAService.CheckMFARequirement(r.Context(), routePath(r), serviceAccount.RoleId, nil); mfaError != nil {
+		slog.WarnContext(r.Context(), "AuthHandler.APIKeyAuthorizationCheck(): access denied - second factor is required", "error", mfaError)
+		pkg.ErrorResponse(w, http.StatusForbidden, "access denied", mfaError.Error())
+		return
+	}
+
+	r.Header.Add("subject", subjectString)
+	r.Header.Add("api-key", strconv.Itoa(apiKey.APIKeyId))
+
+	next.ServeHTTP(w, withTenantService(r, tenantService))
+}
+
+// recordOf returns type (the first segment of the path) and id of the record the request is made over
+func recordOf(r *http.Request) (string, string) {
+	var recordIdString string
+	if r.URL.Query().Has("location_id") {
+		recordIdString = r.URL.Query().Get("location_id")
+	}
+	if r.URL.Query().Has("building_id") {
+		recordIdString = r.URL.Query().Get("building_id")
+	}
+	if r.URL.Query().Has("floor_id") {
+		recordIdString = r.URL.Query().Get("floor_id")
+	}
+	if roomIdString, ok := mux.Vars(r)["room_id"]; ok {
+		recordIdString = roomIdString
+	}
+	if r.URL.Query().Has("booking_id") {
+		recordIdString = r.URL.Query().Get("booking_id")
+	}
+	if r.URL.Query().Has("room_id") {
+		recordIdString = r.URL.Query().Get("room_id")
+	}
+	if r.URL.Query().Has("user_id") {
+		recordIdString = r.URL.Query().Get("user_id")
+	}
+
+	return strings.Split(r.URL.Path, "/")[1], recordIdString
+}
+
+// requestAttributesOf attributes of the request for access policies. They are read only if the route has policies -
+// bodies of requests to other routes are not buffered
+func requestAttributesOf(r *http.Request) services.RequestAttributesFunc {
+	return func() services.RequestAttributes {
+		return readRequestAttributes(r)
+	}
+}
+
+// readRequestAttributes hour and weekday (0 - Sunday) are of server time. Top-level values of JSON body (e.g. `room_id`
+// of the booking to create) are added - the body is put back for the handler
+func readRequestAttributes(r *http.Request) services.RequestAttributes {
+	now := time.Now()
+	attributes := services.RequestAttributes{
+		"method":  r.Method,
+		"path":    r.URL.Path,
+		"ip":      ClientIP(r),
+		"hour":    now.Hour(),
+		"weekday": int(now.Weekday()),
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if r.Body == nil || r.Body == http.NoBody || (contentType != "" && !strings.Contains(contentType, "json")) {
+		return attributes
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPolicyBodySize+1))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err != nil || len(body) > maxPolicyBodySize {
+		return attributes
+	}
+
+	var fields map[string]any
+	if json.Unmarshal(body, &fields) != nil {
+		return attributes
+	}
+	for name, value := range fields {
+		// attributes of the request itself cannot be overridden by the body
+		if _, found := attributes[name]; found {
+			continue
+		}
+		switch value.(type) {
+		case string, float64, bool:
+			attributes[name] = value
+		}
+	}
+
+	return attributes
+}
+
+// DeviceAuthorizationCheck lets room display devices access only routes of the room they are bound to
+func (h *Handlers) DeviceAuthorizationCheck(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	encodedDeviceToken := strings.TrimPrefix(r.Header.Get("Authorization"), DeviceAuthorizationScheme+" ")
+
+	device, err := h.service.DeviceService.Authenticate(r.Context(), encodedDeviceToken)
+	if err != nil {
+		slog.WarnContext(r.Context(), "AuthHandler.DeviceAuthorizationCheck(): device authentication failed", "error", err)
+		pkg.ErrorResponse(w, http.StatusUnauthorized, "device authentication failed", err.Error())
+		return
+	}
+
+	// device is bound to a single room
+	roomIdString := mux.Vars(r)["room_id"]
+	if roomIdString != strconv.Itoa(device.RoomId) {
+		slog.WarnContext(r.Context(), "AuthHandler.DeviceAuthorizationCheck(): access denied - device is registered for another room", "device_id", device.DeviceId, "room_id", roomIdString)
+		pkg.ErrorResponse(w, http.StatusUnauthorized, "access denied")
+		return
+	}
+
+	tenantService, organizationError := h.organizationService(r.Context(), strconv.Itoa(device.OrganizationId))
+	if organizationError != nil {
+		slog.WarnContext(r.Context(), "AuthHandler.DeviceAuthorizationCheck(): access denied - organization of the device is not valid", "error", organizationError)
+		pkg.ErrorResponse(w, http.StatusUnauthorized, "access denied", organizationError.Error())
+		return
+	}
+
+	subjectString := strconv.Itoa(device.CreatedBy)
+	isAccessGranted, permissionCheckError := tenantService.AuthService.CheckPermissions(r.Context(), routePath(r), "display", roomIdString, subjectString, strconv.Itoa(device.RoleId), requestAttributesOf(r))
+	if permissionCheckError != nil {
+		slog.WarnContext(r.Context(), "AuthHandler.DeviceAuthorizationCheck(): error occurred during permission check", "error", permissionCheckError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occurred during permission check", permissionCheckError.Error())
+		return
+	}
+
+	if isAccessGranted != true {
+		slog.WarnContext(r.Context(), "AuthHandler.DeviceAuthorizationCheck(): access denied")
+		pkg.ErrorResponse(w, http.StatusUnauthorized, "access denied")
+		return
+	}
+
+	r.Header.Add("subject", subjectString)
+	r.Header.Add("device", strconv.Itoa(device.DeviceId))
+
+	next.ServeHTTP(w, withTenantService(r, tenantService))
+}
+
+// organizationService returns services limited to the active organization taken from token claims
+func (h *Handlers) organizationService(ctx context.Context, organizationString string) (*services.Service, error) {
+	organizationId, conversionError := strconv.Atoi(organizationString)
+	if conversionError != nil || organizationId <= 0 {
+		return nil, fmt.Errorf("token has no valid organization. Passed data: '%s'", organizationString)
+	}
+
+	organization, err := h.service.OrganizationService.GetOrganizationById(ctx, organizationId)
+	if err != nil {
+		return nil, err
+	}
+	if organization.Active != true {
+		return nil, fmt.Errorf("organization is deactivated. Passed data: %d", organizationId)
+	}
+
+	return h.service.ForOrganization(organizationId), nil
+}
+
+// routePath returns path template of matched route (`/display/{room_id}`) - routes are stored in DB by templates
+func routePath(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if pathTemplate, err := route.GetPathTemplate(); err == nil {
+			return pathTemplate
+		}
+	}
+
+	return r.URL.Path
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handlers) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	subjectStr := r.Header.Get("subject")
+	r.Header.Del("subject")
+	subjectWhoCreatesOrganization, conversionError := strconv.Atoi(subjectStr)
+	if conversionError != nil {
+		slog.WarnContext(r.Context(), "OrganizationHandler.CreateOrganization(): cannot convert `subject`-header to integer", "error", conversionError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", conversionError.Error())
+		return
+	}
+
+	var organizationParams models.Organization
+
+	// convert JSON to models.Organization type
+	err := json.NewDecoder(r.Body).Decode(&organizationParams)
+	if err != nil {
+		slog.WarnContext(r.Context(), "OrganizationHandler.CreateOrganization(): cannot convert JSON to models.Organization struct", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.Organization struct", err.Error())
+		return
+	}
+
+	// validate passed organization data
+	validator := NewOrganizationValidator(&organizationParams)
+	if validator.AllOrganizationFieldsValid != true {
+		slog.WarnContext(r.Context(), "OrganizationHandler.CreateOrganization(): Organization data is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "Organization data is not valid", validator.ValidationErrors)
+		return
+	}
+
+	organizationParams.CreatedBy = subjectWhoCreatesOrganization
+
+	// create organization
+	createdOrganization, err := h.tenantService(r).OrganizationService.Create(r.Context(), organizationParams)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "OrganizationHandler.CreateOrganization(): error occured during Organization creation", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during Organization creation", err.Error())
+		return
+	}
+
+	// return created organization
+	pkg.Response(w, createdOrganization)
+}
+
+func (h *Handlers) GetAllOrganizations(w http.ResponseWriter, r *http.Request) {
+	// get all organizations
+	organizations := h.tenantService(r).OrganizationService.GetAll(r.Context())
+
+	// return all organizations
+	pkg.Response(w, organizations)
+}
+
+func (h *Handlers) GetOrganizationById(w http.ResponseWriter, r *http.Request) {
+	// get organization_id from query path
+	organizationIdStr := r.URL.Query().Get("organization_id")
+	if organizationIdStr == "" {
+		slog.WarnContext(r.Context(), "OrganizationHandler.GetOrganizationById(): parameter `organization_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `organization_id` is empty or not passed")
+		return
+	}
+
+	// convert organization_id param string to int
+	organizationId, err := strconv.Atoi(organizationIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "OrganizationHandler.GetOrganizationById(): organization_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "organization_id should be an integer", err.Error())
+		return
+	}
+
+	// get Organization from services
+	organization, err := h.tenantService(r).OrganizationService.GetOrganizationById(r.Context(), organizationId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "OrganizationHandler.GetOrganizationById(): error occured during getting organization by id", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting organization by id", err.Error())
+		return
+	}
+
+	// return found organization
+	pkg.Response(w, organization)
+}
+
+func (h *Handlers) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	// get organization_id from query path
+	organizationIdStr := r.URL.Query().Get("organization_id")
+	if organizationIdStr == "" {
+		slog.WarnContext(r.Context(), "OrganizationHandler.UpdateOrganization(): parameter `organization_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `organization_id` is empty or not passed")
+		return
+	}
+
+	// convert organization_id param string to int
+	organizationId, err := strconv.Atoi(organizationIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "OrganizationHandler.UpdateOrganization(): organization_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "organization_id should be an integer", err.Error())
+		return
+	}
+
+	var organizationParamsToUpdate models.Organization
+	// convert JSON to models.Organization type
+	err = json.NewDecoder(r.Body).Decode(&organizationParamsToUpdate)
+	if err != nil {
+		slog.WarnContext(r.Context(), "OrganizationHandler.UpdateOrganization(): cannot convert JSON to models.Organization struct", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.Organization struct", err.Error())
+		return
+	}
+
+	// validate passed organization data
+	validator := NewOrganizationValidator(&organizationParamsToUpdate)
+	if validator.AllOrganizationFieldsValid != true {
+		slog.WarnContext(r.Context(), "OrganizationHandler.UpdateOrganization(): Organization data is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "Organization data is not valid", validator.ValidationErrors)
+		return
+	}
+
+	// to double-check if organization id wasn't set
+	organizationParamsToUpdate.OrganizationId = organizationId
+
+	// update organization
+	updatedOrganization, err := h.tenantService(r).OrganizationService.Update(r.Context(), organizationParamsToUpdate)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "OrganizationHandler.UpdateOrganization(): error occured during organization update", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during organization update", err.Error())
+		return
+	}
+
+	// return updated organization
+	pkg.Response(w, updatedOrganization)
+}
+
+func (h *Handlers) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	// get organization_id from query path
+	organizationIdStr := r.URL.Query().Get("organization_id")
+	if organizationIdStr == "" {
+		slog.WarnContext(r.Context(), "OrganizationHandler.DeleteOrganization(): parameter `organization_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `organization_id` is empty or not passed")
+		return
+	}
+
+	// convert organization_id param string to int
+	organizationId, err := strconv.Atoi(organizationIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "OrganizationHandler.DeleteOrganization(): organization_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "organization_id should be an integer", err.Error())
+		return
+	}
+
+	// delete organization
+	_, err = h.tenantService(r).OrganizationService.Delete(r.Context(), organizationId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "OrganizationHandler.DeleteOrganization(): error occured during organization deletion", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during organization deletion", err.Error())
+		return
+	}
+
+	// return success message
+	pkg.Response(w, "success")
+}
+
+type OrganizationValidator struct {
+	OrganizationToValidate     *models.Organization `json:"passed_organization"`
+	ValidationErrors           map[string]string    `json:"validation_errors"`
+	IsNameValid                bool                 `json:"is_name_valid"`
+	AllOrganizationFieldsValid bool                 `json:"all_organization_fields_valid"`
+}
+
+func NewOrganizationValidator(organization *models.Organization) *OrganizationValidator {
+	validationErrors := map[string]string{
+		"name_error": "Organization.Name: should not be empty string",
+	}
+
+	validator := &OrganizationValidator{OrganizationToValidate: organization, ValidationErrors: validationErrors, AllOrganizationFieldsValid: false}
+	validator.IsOrganizationValid()
+
+	return validator
+}
+
+func (o *OrganizationValidator) IsOrganizationValid() {
+	o.ValidateFields()
+
+	if o.IsNameValid {
+		o.AllOrganizationFieldsValid = true
+	}
+}
+
+func (o *OrganizationValidator) ValidateFields() {
+	if o.OrganizationToValidate.Name != "" {
+		o.IsNameValid = true
+		delete(o.ValidationErrors, "name_error")
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// CreatePolicy creates access policy of the route (`route_id` or `url`). Policy without `role_id` is applied to every role
+func (h *Handlers) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	createdBy, ok := subjectUserId(w, r, "PolicyHandler.CreatePolicy()")
+	if !ok {
+		return
+	}
+
+	policy := models.Policy{}
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		slog.WarnContext(r.Context(), "PolicyHandler.CreatePolicy(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+	policy.CreatedBy = createdBy
+
+	createdPolicy, err := h.tenantService(r).PolicyService.CreatePolicy(r.Context(), policy)
+	if err != nil {
+		slog.WarnContext(r.Context(), "PolicyHandler.CreatePolicy(): error occured during policy creation", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during policy creation", err.Error())
+		return
+	}
+
+	pkg.Response(w, createdPolicy)
+}
+
+func (h *Handlers) GetAllPolicies(w http.ResponseWriter, r *http.Request) {
+	pkg.Response(w, h.tenantService(r).PolicyService.GetPolicies(r.Context()))
+}
+
+func (h *Handlers) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	policyId, err := strconv.Atoi(r.URL.Query().Get("policy_id"))
+	if err != nil {
+		slog.WarnContext(r.Context(), "PolicyHandler.UpdatePolicy(): policy_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "policy_id should be an integer", err.Error())
+		return
+	}
+
+	policy := models.Policy{}
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		slog.WarnContext(r.Context(), "PolicyHandler.UpdatePolicy(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+	policy.PolicyId = policyId
+
+	updatedPolicy, err := h.tenantService(r).PolicyService.UpdatePolicy(r.Context(), policy)
+	if err != nil {
+		slog.WarnContext(r.Context(), "PolicyHandler.UpdatePolicy(): error occured during policy update", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during policy update", err.Error())
+		return
+	}
+
+	pkg.Response(w, updatedPolicy)
+}
+
+func (h *Handlers) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	policyId, err := strconv.Atoi(r.URL.Query().Get("policy_id"))
+	if err != nil {
+		slog.WarnContext(r.Context(), "PolicyHandler.DeletePolicy(): policy_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "policy_id should be an integer", err.Error())
+		return
+	}
+
+	if _, err := h.tenantService(r).PolicyService.DeletePolicy(r.Context(), policyId); err != nil {
+		slog.WarnContext(r.Context(), "PolicyHandler.DeletePolicy(): error occured during policy deletion", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during policy deletion", err.Error())
+		return
+	}
+
+	pkg.Response(w, "success")
+}
+
+// ExplainAccess dry run of access check: whether the user may call the route over the record, and which permissions,
+// policies and attributes decide it. Unsaved `policies` are tried together with saved ones
+func (h *Handlers) ExplainAccess(w http.ResponseWriter, r *http.Request) {
+	request := models.AccessExplanationRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.WarnContext(r.Context(), "PolicyHandler.ExplainAccess(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+	if request.UserId <= 0 || request.URL == "" {
+		slog.WarnContext(r.Context(), "PolicyHandler.ExplainAccess(): request data is not valid", "user_id", request.UserId, "url", request.URL)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "user_id should be positive, url should not be empty")
+		return
+	}
+
+	explanation, err := h.tenantService(r).PolicyService.Explain(r.Context(), request)
+	if err != nil {
+		slog.WarnContext(r.Context(), "PolicyHandler.ExplainAccess(): error occured during access explanation", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during access explanation", err.Error())
+		return
+	}
+
+	pkg.Response(w, explanation)
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// ProfileParams fields of the profile the caller changes. Role, username and password have their own endpoints
+type ProfileParams struct {
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Telephone string `json:"telephone"`
+	TimeZone  string `json:"time_zone"`
+}
+
+type ChangePasswordParams struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ChangeUsernameParams struct {
+	Password string `json:"password"`
+	Username string `json:"username"`
+}
+
+// GetProfile returns the caller
+func (h *Handlers) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userId, ok := subjectUserId(w, r, "ProfileHandler.GetProfile()")
+	if !ok {
+		return
+	}
+
+	user, err := h.tenantService(r).AccountService.GetProfile(r.Context(), userId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "ProfileHandler.GetProfile(): error occured during getting profile", "user_id", userId, "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting profile", err.Error())
+		return
+	}
+
+	pkg.Response(w, user)
+}
+
+// UpdateProfile changes name, email, telephone and time zone of the caller
+func (h *Handlers) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userId, ok := subjectUserId(w, r, "ProfileHandler.UpdateProfile()")
+	if !ok {
+		return
+	}
+
+	params := ProfileParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		slog.WarnContext(r.Context(), "ProfileHandler.UpdateProfile(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+
+	profile := models.User{UserId: userId, Name: params.Name, Email: params.Email, Telephone: params.Telephone, TimeZone: params.TimeZone}
+	validator := NewProfileValidator(&profile)
+	if validator.AllUserFieldsValid != true {
+		slog.WarnContext(r.Context(), "ProfileHandler.UpdateProfile(): profile data is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "profile data is not valid", validator.ValidationErrors)
+		return
+	}
+
+	user, err := h.tenantService(r).AccountService.UpdateProfile(r.Context(), profile)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "ProfileHandler.UpdateProfile(): error occured during profile update", "user_id", userId, "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during profile update", err.Error())
+		return
+	}
+
+	pkg.Response(w, user)
+}
+
+// ChangePassword sets new password of the caller. Wrong current password is counted as failed log in
+func (h *Handlers) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userId, ok := subjectUserId(w, r, "ProfileHandler.ChangePassword()")
+	if !ok {
+		return
+	}
+
+	params := ChangePasswordParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		slog.WarnContext(r.Context(), "ProfileHandler.ChangePassword(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+	if params.CurrentPassword == "" || params.NewPassword == "" {
+		slog.WarnContext(r.Context(), "ProfileHandler.ChangePassword(): current_password or new_password is empty")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "current_password and new_password should not be empty")
+		return
+	}
+
+	username, ok := h.allowPasswordCheck(w, r, userId, "ProfileHandler.ChangePassword()")
+	if !ok {
+		return
+	}
+
+	err := h.tenantService(r).AccountService.ChangePassword(r.Context(), userId, params.CurrentPassword, params.NewPassword)
+	if !h.passwordCheckSucceeded(w, r, username, err, "ProfileHandler.ChangePassword()") {
+		return
+	}
+
+	pkg.Response(w, AccountMessage{Message: "password is changed"})
+}
+
+// ChangeUsername sets new username of the caller
+func (h *Handlers) ChangeUsername(w http.ResponseWriter, r *http.Request) {
+	userId, ok := subjectUserId(w, r, "ProfileHandler.ChangeUsername()")
+	if !ok {
+		return
+	}
+
+	params := ChangeUsernameParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		slog.WarnContext(r.Context(), "ProfileHandler.ChangeUsername(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+	params.Username = strings.TrimSpace(params.Username)
+	if params.Password == "" || params.Username == "" {
+		slog.WarnContext(r.Context(), "ProfileHandler.ChangeUsername(): password or username is empty")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "password and username should not be empty")
+		return
+	}
+
+	username, ok := h.allowPasswordCheck(w, r, userId, "ProfileHandler.ChangeUsername()")
+	if !ok {
+		return
+	}
+
+	user, err := h.tenantService(r).AccountService.ChangeUsername(r.Context(), userId, params.Password, params.Username)
+	if !h.passwordCheckSucceeded(w, r, username, err, "ProfileHandler.ChangeUsername()") {
+		return
+	}
+
+	pkg.Response(w, user)
+}
+
+// allowPasswordCheck applies limits of log in to the caller's username - password is not guessed by access token.
+// Response is written if `false` is returned
+func (h *Handlers) allowPasswordCheck(w http.ResponseWriter, r *http.Request, userId int, caller string) (string, bool) {
+	user, err := h.tenantService(r).AccountService.GetProfile(r.Context(), userId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), caller+": error occured during getting profile", "user_id", userId, "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting profile", err.Error())
+		return "", false
+	}
+
+	if limitError := h.limiter.AllowLogin(r.Context(), ClientIP(r), user.UserName); limitError != nil {
+		tooManyRequestsResponse(w, limitError)
+		return "", false
+	}
+
+	return user.UserName, true
+}
+
+// passwordCheckSucceeded writes response of failed change. Response is written if `false` is returned
+func (h *Handlers) passwordCheckSucceeded(w http.ResponseWriter, r *http.Request, username string, err error, caller string) bool {
+	if err == nil {
+		h.limiter.LoginSucceeded(r.Context(), username)
+		return true
+	}
+
+	switch err.Error() {
+	case services.CurrentPasswordError:
+		slog.WarnContext(r.Context(), caller+": current password is not valid", "passed_data", username)
+		h.loginFailed(w, r, username, services.CurrentPasswordError)
+	case services.DirectoryPasswordError, services.DirectoryUsernameError, services.UsernameIsTakenError:
+		slog.WarnContext(r.Context(), caller+": account is not changed", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), caller+": error occured during account change", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during account change", err.Error())
+	}
+
+	return false
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// ReportFormatCSV value of `format` query parameter to download report as CSV file
+const ReportFormatCSV = "csv"
+
+func (h *Handlers) GetUtilizationReport(w http.ResponseWriter, r *http.Request) {
+	filter, ok := reportFilterParams(w, r, "ReportHandler.GetUtilizationReport()")
+	if !ok {
+		return
+	}
+
+	// get utilization of rooms from services
+	utilization, err := h.tenantService(r).ReportService.GetUtilization(r.Context(), filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "ReportHandler.GetUtilizationReport(): error occured during utilization report calculation", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during utilization report calculation", err.Error())
+		return
+	}
+
+	if r.URL.Query().Get("format") == ReportFormatCSV {
+		records := [][]string{{"room_id", "room_number", "period_start", "open_minutes", "booked_minutes", "utilization_pct"}}
+		for _, row := range utilization {
+			records = append(records, []string{strconv.Itoa(row.RoomId), row.RoomNumber, row.PeriodStart, strconv.Itoa(row.OpenMinutes),
+				strconv.Itoa(row.BookedMinutes), formatFloat(row.UtilizationPct)})
+		}
+		pkg.CSVResponse(w, "utilization.csv", records)
+		return
+	}
+
+	// return utilization of rooms
+	pkg.Response(w, utilization)
+}
+
+func (h *Handlers) GetPeakHoursReport(w http.ResponseWriter, r *http.Request) {
+	filter, ok := reportFilterParams(w, r, "ReportHandler.GetPeakHoursReport()")
+	if !ok {
+		return
+	}
+
+	// get peak hours heatmap from services
+	peakHours, err := h.tenantService(r).ReportService.GetPeakHours(r.Context(), filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "ReportHandler.GetPeakHoursReport(): error occured during peak hours report calculation", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during peak hours report calculation", err.Error())
+		return
+	}
+
+	if r.URL.Query().Get("format") == ReportFormatCSV {
+		records := [][]string{{"weekday", "hour", "bookings"}}
+		for _, row := range peakHours {
+			records = append(records, []string{strconv.Itoa(row.Weekday), strconv.Itoa(row.Hour), strconv.Itoa(row.Bookings)})
+		}
+		pkg.CSVResponse(w, "peak_hours.csv", records)
+		return
+	}
+
+	// return peak hours heatmap
+	pkg.Response(w, peakHours)
+}
+
+func (h *Handlers) GetBookingStatsReport(w http.ResponseWriter, r *http.Request) {
+	filter, ok := reportFilterParams(w, r, "ReportHandler.GetBookingStatsReport()")
+	if !ok {
+		return
+	}
+
+	// get booking statistics of rooms from services
+	bookingStats, err := h.tenantService(r).ReportService.GetBookingStats(r.Context(), filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "ReportHandler.GetBookingStatsReport(): error occured during booking statistics report calculation", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during booking statistics report calculation", err.Error())
+		return
+	}
+
+	if r.URL.Query().Get("format") == ReportFormatCSV {
+		records := [][]string{{"room_id", "room_number", "total_bookings", "cancelled_bookings", "no_show_bookings",
+			"average_duration_minutes", "cancellation_rate_pct", "no_show_rate_pct"}}
+		for _, row := range bookingStats {
+			records = append(records, []string{strconv.Itoa(row.RoomId), row.RoomNumber, strconv.Itoa(row.TotalBookings),
+				strconv.Itoa(row.CancelledBookings), strconv.Itoa(row.NoShowBookings), formatFloat(row.AverageDurationMinutes),
+				formatFloat(row.CancellationRatePct), formatFloat(row.NoShowRatePct)})
+		}
+		pkg.CSVResponse(w, "booking_stats.csv", records)
+		return
+	}
+
+	// return booking statistics of rooms
+	pkg.Response(w, bookingStats)
+}
+
+func (h *Handlers) GetTopBookersReport(w http.ResponseWriter, r *http.Request) {
+	filter, ok := reportFilterParams(w, r, "ReportHandler.GetTopBookersReport()")
+	if !ok {
+		return
+	}
+
+	// get top bookers from services
+	topBookers, err := h.tenantService(r).ReportService.GetTopBookers(r.Context(), filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "ReportHandler.GetTopBookersReport(): error occured during top bookers report calculation", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during top bookers report calculation", err.Error())
+		return
+	}
+
+	if r.URL.Query().Get("format") == ReportFormatCSV {
+		records := [][]string{{"user_id", "name", "email", "bookings", "booked_minutes"}}
+		for _, row := range topBookers {
+			records = append(records, []string{strconv.Itoa(row.UserId), row.Name, row.Email, strconv.Itoa(row.Bookings), strconv.Itoa(row.BookedMinutes)})
+		}
+		pkg.CSVResponse(w, "top_bookers.csv", records)
+		return
+	}
+
+	// return top bookers
+	pkg.Response(w, topBookers)
+}
+
+// reportFilterParams reads filter of reports: `datetime_start`, `datetime_end` (required), `room_id`, `role_id`, `period`, `limit`
+func reportFilterParams(w http.ResponseWriter, r *http.Request, caller string) (models.ReportFilter, bool) {
+	// validate room_id and time range
+	validator := NewBookingQueryParamsValidator(r.URL.RawQuery)
+	if validator.AllQueryParamsValid == false {
+		slog.WarnContext(r.Context(), caller+": report query is not valid", "details", validator.ValidationErrors)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "report query is not valid", validator.ValidationErrors)
+		return models.ReportFilter{}, false
+	}
+
+	if validator.DateTimeStart.IsZero() || validator.DateTimeEnd.IsZero() {
+		slog.WarnContext(r.Context(), caller+": parameters `datetime_start` and `datetime_end` are required")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameters `datetime_start` and `datetime_end` are required")
+		return models.ReportFilter{}, false
+	}
+
+	filter := models.ReportFilter{
+		DateTimeStart: validator.DateTimeStart,
+		DateTimeEnd:   validator.DateTimeEnd,
+		RoomId:        validator.RoomId,
+		Period:        r.URL.Query().Get("period"),
+	}
+
+	// convert role_id and limit params string to int
+	if roleIdStr := r.URL.Query().Get("role_id"); roleIdStr != "" {
+		roleId, err := strconv.Atoi(roleIdStr)
+		if err != nil || roleId < 1 {
+			slog.WarnContext(r.Context(), caller+": role_id should be a positive integer", "passed_data", roleIdStr)
+			pkg.ErrorResponse(w, http.StatusBadRequest, "role_id should be a positive integer", roleIdStr)
+			return models.ReportFilter{}, false
+		}
+		filter.RoleId = roleId
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			slog.WarnContext(r.Context(), caller+": limit should be an integer", "error", err)
+			pkg.ErrorResponse(w, http.StatusBadRequest, "limit should be an integer", err.Error())
+			return models.ReportFilter{}, false
+		}
+		filter.Limit = limit
+	}
+
+	return filter, true
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
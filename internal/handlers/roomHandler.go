@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"go-booking-system/internal/models"
 	"go-booking-system/pkg"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 )
@@ -13,7 +13,7 @@ func (h *Handlers) DeleteRoom(w http.ResponseWriter, r *http.Request) { // DONE
 	// get room_id from query path
 	roomIdStr := r.URL.Query().Get("room_id")
 	if roomIdStr == "" {
-		log.Println("RoomHandler.DeleteRoom(): parameter `room_id` is empty or not passed")
+		slog.WarnContext(r.Context(), "RoomHandler.DeleteRoom(): parameter `room_id` is empty or not passed")
 		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `room_id` is empty or not passed")
 		return
 	}
@@ -21,15 +21,15 @@ func (h *Handlers) DeleteRoom(w http.ResponseWriter, r *http.Request) { // DONE
 	// convert room_id param string to int
 	roomId, err := strconv.Atoi(roomIdStr)
 	if err != nil {
-		log.Println("RoomHandler.DeleteRoom(): room_id should be an integer. Details: ", err)
+		slog.WarnContext(r.Context(), "RoomHandler.DeleteRoom(): room_id should be an integer", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "room_id should be an integer")
 		return
 	}
 
 	// delete room
-	_, err = h.service.RoomService.Delete(roomId)
+	_, err = h.tenantService(r).RoomService.Delete(r.Context(), roomId)
 	if err != nil {
-		log.Println("RoomHandler.DeleteRoom(): error occured during room deletion. Details: ", err)
+		slog.ErrorContext(r.Context(), "RoomHandler.DeleteRoom(): error occured during room deletion", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during room deletion", err.Error())
 		return
 	}
@@ -40,7 +40,7 @@ func (h *Handlers) DeleteRoom(w http.ResponseWriter, r *http.Request) { // DONE
 
 func (h *Handlers) GetAllRooms(w http.ResponseWriter, r *http.Request) {
 	// get all rooms
-	rooms := h.service.RoomService.GetAll()
+	rooms := h.tenantService(r).RoomService.GetAll(r.Context())
 
 	// return all rooms
 	pkg.Response(w, rooms)
@@ -51,7 +51,7 @@ func (h *Handlers) CreateRoom(w http.ResponseWriter, r *http.Request) {
 	r.Header.Del("subject")
 	subjectWhoCreatesRoom, conversionError := strconv.Atoi(subjectStr)
 	if conversionError != nil {
-		log.Println("RoomHandler.CreateRoom(): cannot convert `subject`-header to integer. Details: ", conversionError)
+		slog.WarnContext(r.Context(), "RoomHandler.CreateRoom(): cannot convert `subject`-header to integer", "error", conversionError)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", conversionError.Error())
 		return
 	}
@@ -61,7 +61,7 @@ func (h *Handlers) CreateRoom(w http.ResponseWriter, r *http.Request) {
 	// convert JSON to models.Room type
 	err := json.NewDecoder(r.Body).Decode(&roomParams)
 	if err != nil {
-		log.Println("RoomHandler.CreateRoom(): cannot convert JSON to models.Room struct. Details: ", err)
+		slog.WarnContext(r.Context(), "RoomHandler.CreateRoom(): cannot convert JSON to models.Room struct", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.Room struct", err.Error())
 		return
 	}
@@ -69,7 +69,7 @@ func (h *Handlers) CreateRoom(w http.ResponseWriter, r *http.Request) {
 	// validate passed room data
 	validator := NewRoomValidator(&roomParams)
 	if validator.AllRoomFieldsValid != true {
-		log.Println("RoomHandler.CreateRoom(): Room data is not valid. Details: ", validator.ValidationErrors)
+		slog.WarnContext(r.Context(), "RoomHandler.CreateRoom(): Room data is not valid", "details", validator.ValidationErrors)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "Room data is not valid", validator.ValidationErrors)
 		return
 	}
@@ -78,9 +78,9 @@ func (h *Handlers) CreateRoom(w http.ResponseWriter, r *http.Request) {
 	roomParams.CreatedBy = subjectWhoCreatesRoom
 
 	// create room
-	createdRoom, err := h.service.RoomService.Create(roomParams)
+	createdRoom, err := h.tenantService(r).RoomService.Create(r.Context(), roomParams)
 	if err != nil {
-		log.Println("RoomHandler.CreateRoom(): error occured during Room creation. Details: ", err)
+		slog.ErrorContext(r.Context(), "RoomHandler.CreateRoom(): error occured during Room creation", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during Room creation", err.Error())
 		return
 	}
@@ -93,7 +93,7 @@ func (h *Handlers) GetRoomById(w http.ResponseWriter, r *http.Request) {
 	// get room_id from query path
 	roomIdStr := r.URL.Query().Get("room_id")
 	if roomIdStr == "" {
-		log.Println("RoomHandler.GetRoomById(): parameter `room_id` is empty or not passed")
+		slog.WarnContext(r.Context(), "RoomHandler.GetRoomById(): parameter `room_id` is empty or not passed")
 		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `room_id` is empty or not passed")
 		return
 	}
@@ -101,15 +101,15 @@ func (h *Handlers) GetRoomById(w http.ResponseWriter, r *http.Request) {
 	// convert room_id param string to int
 	roomId, err := strconv.Atoi(roomIdStr)
 	if err != nil {
-		log.Println("RoomHandler.GetRoomById(): room_id should be an integer. Details: ", err)
+		slog.WarnContext(r.Context(), "RoomHandler.GetRoomById(): room_id should be an integer", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "room_id should be an integer", err.Error())
 		return
 	}
 
 	// get Room from services
-	room, err := h.service.RoomService.GetRoomById(roomId)
+	room, err := h.tenantService(r).RoomService.GetRoomById(r.Context(), roomId)
 	if err != nil {
-		log.Println("RoomHandler.GetRoomById(): error occured during getting room by id. Details: ", err)
+		slog.ErrorContext(r.Context(), "RoomHandler.GetRoomById(): error occured during getting room by id", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting room by id", err.Error())
 		return
 	}
@@ -122,7 +122,7 @@ func (h *Handlers) UpdateRoom(w http.ResponseWriter, r *http.Request) {
 	// get room_id from query path
 	roomIdStr := r.URL.Query().Get("room_id")
 	if roomIdStr == "" {
-		log.Println("RoomHandler.UpdateRoom(): parameter `room_id` is empty or not passed")
+		slog.WarnContext(r.Context(), "RoomHandler.UpdateRoom(): parameter `room_id` is empty or not passed")
 		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `room_id` is empty or not passed")
 		return
 	}
@@ -130,7 +130,7 @@ func (h *Handlers) UpdateRoom(w http.ResponseWriter, r *http.Request) {
 	// convert room_id param string to int
 	roomId, err := strconv.Atoi(roomIdStr)
 	if err != nil {
-		log.Println("RoomHandler.UpdateRoom(): room_id should be an integer. Details: ", err)
+		slog.WarnContext(r.Context(), "RoomHandler.UpdateRoom(): room_id should be an integer", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "room_id should be an integer", err.Error())
 		return
 	}
@@ -139,7 +139,7 @@ func (h *Handlers) UpdateRoom(w http.ResponseWriter, r *http.Request) {
 	// convert JSON to models.Room type
 	err = json.NewDecoder(r.Body).Decode(&roomParamsToUpdate)
 	if err != nil {
-		log.Println("RoomHandler.UpdateRoom(): cannot convert JSON to models.Room struct. Details: ", err)
+		slog.WarnContext(r.Context(), "RoomHandler.UpdateRoom(): cannot convert JSON to models.Room struct", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.Room struct", err.Error())
 		return
 	}
@@ -147,7 +147,7 @@ func (h *Handlers) UpdateRoom(w http.ResponseWriter, r *http.Request) {
 	// validate passed room data
 	validator := NewRoomValidator(&roomParamsToUpdate)
 	if validator.AllRoomFieldsValid != true {
-		log.Println("RoomHandler.UpdateRoom(): Room data is not valid. Details: ", validator.ValidationErrors)
+		slog.WarnContext(r.Context(), "RoomHandler.UpdateRoom(): Room data is not valid", "details", validator.ValidationErrors)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "Room data is not valid", validator.ValidationErrors)
 		return
 	}
@@ -156,9 +156,9 @@ func (h *Handlers) UpdateRoom(w http.ResponseWriter, r *http.Request) {
 	roomParamsToUpdate.RoomId = roomId
 
 	// update room
-	updatedRoom, err := h.service.RoomService.Update(roomParamsToUpdate)
+	updatedRoom, err := h.tenantService(r).RoomService.Update(r.Context(), roomParamsToUpdate)
 	if err != nil {
-		log.Println("RoomHandler.UpdateRoom(): error occured during room update. Details: ", err)
+		slog.ErrorContext(r.Context(), "RoomHandler.UpdateRoom(): error occured during room update", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during room update", err.Error())
 		return
 	}
@@ -172,6 +172,7 @@ type RoomValidator struct {
 	ValidationErrors   map[string]string `json:"validation_errors"`
 	IsNumberValid      bool              `json:"is_number_valid"`
 	IsCapacityValid    bool              `json:"is_capacity_valid"`
+	IsFloorIdValid     bool              `json:"is_floor_id_valid"`
 	AllRoomFieldsValid bool              `json:"all_room_fields_valid"`
 }
 
@@ -179,6 +180,7 @@ func NewRoomValidator(room *models.Room) *RoomValidator {
 	validationErrors := map[string]string{
 		"number_error":   "Room.Number: should not be empty string",
 		"capacity_error": "Room.Capacity: should not be negative integer or zero",
+		"floorid_error":  "Room.FloorId: should not be negative integer or zero",
 	}
 
 	validator := &RoomValidator{RoomToValidate: room, ValidationErrors: validationErrors, AllRoomFieldsValid: false}
@@ -190,7 +192,7 @@ func NewRoomValidator(room *models.Room) *RoomValidator {
 func (r *RoomValidator) IsRoomValid() {
 	r.ValidateFields()
 
-	if r.IsNumberValid && r.IsCapacityValid {
+	if r.IsNumberValid && r.IsCapacityValid && r.IsFloorIdValid {
 		r.AllRoomFieldsValid = true
 	}
 }
@@ -204,4 +206,8 @@ func (r *RoomValidator) ValidateFields() {
 		r.IsCapacityValid = true
 		delete(r.ValidationErrors, "capacity_error")
 	}
+	if r.RoomToValidate.FloorId > 0 {
+		r.IsFloorIdValid = true
+		delete(r.ValidationErrors, "floorid_error")
+	}
 }
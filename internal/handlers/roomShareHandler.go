@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handlers) ShareRoom(w http.ResponseWriter, r *http.Request) {
+	subjectStr := r.Header.Get("subject")
+	r.Header.Del("subject")
+	subjectWhoSharesRoom, conversionError := strconv.Atoi(subjectStr)
+	if conversionError != nil {
+		slog.WarnContext(r.Context(), "RoomShareHandler.ShareRoom(): cannot convert `subject`-header to integer", "error", conversionError)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert `subject`-header to integer", conversionError.Error())
+		return
+	}
+
+	// get room_id from query path
+	roomIdStr := r.URL.Query().Get("room_id")
+	if roomIdStr == "" {
+		slog.WarnContext(r.Context(), "RoomShareHandler.ShareRoom(): parameter `room_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `room_id` is empty or not passed")
+		return
+	}
+
+	// convert room_id param string to int
+	roomId, err := strconv.Atoi(roomIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "RoomShareHandler.ShareRoom(): room_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "room_id should be an integer", err.Error())
+		return
+	}
+
+	var roomShareParams models.RoomShare
+
+	// convert JSON to models.RoomShare type
+	err = json.NewDecoder(r.Body).Decode(&roomShareParams)
+	if err != nil {
+		slog.WarnContext(r.Context(), "RoomShareHandler.ShareRoom(): cannot convert JSON to models.RoomShare struct", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.RoomShare struct", err.Error())
+		return
+	}
+
+	if roomShareParams.OrganizationId <= 0 {
+		slog.WarnContext(r.Context(), "RoomShareHandler.ShareRoom(): organization_id should be positive integer", "passed_data", roomShareParams.OrganizationId)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "organization_id should be positive integer")
+		return
+	}
+
+	roomShareParams.RoomId = roomId
+	roomShareParams.CreatedBy = subjectWhoSharesRoom
+
+	// share room
+	createdRoomShare, err := h.tenantService(r).RoomService.ShareRoom(r.Context(), roomShareParams)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "RoomShareHandler.ShareRoom(): error occured during Room sharing", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during Room sharing", err.Error())
+		return
+	}
+
+	// return created room share
+	pkg.Response(w, createdRoomShare)
+}
+
+func (h *Handlers) GetRoomShares(w http.ResponseWriter, r *http.Request) {
+	// get room_id from query path
+	roomIdStr := r.URL.Query().Get("room_id")
+	if roomIdStr == "" {
+		slog.WarnContext(r.Context(), "RoomShareHandler.GetRoomShares(): parameter `room_id` is empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `room_id` is empty or not passed")
+		return
+	}
+
+	// convert room_id param string to int
+	roomId, err := strconv.Atoi(roomIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "RoomShareHandler.GetRoomShares(): room_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "room_id should be an integer", err.Error())
+		return
+	}
+
+	// get room shares from services
+	roomShares, err := h.tenantService(r).RoomService.GetRoomShares(r.Context(), roomId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "RoomShareHandler.GetRoomShares(): error occured during getting room shares", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting room shares", err.Error())
+		return
+	}
+
+	// return found room shares
+	pkg.Response(w, roomShares)
+}
+
+func (h *Handlers) UnshareRoom(w http.ResponseWriter, r *http.Request) {
+	// get room_id and organization_id from query path
+	roomIdStr := r.URL.Query().Get("room_id")
+	organizationIdStr := r.URL.Query().Get("organization_id")
+	if roomIdStr == "" || organizationIdStr == "" {
+		slog.WarnContext(r.Context(), "RoomShareHandler.UnshareRoom(): parameters `room_id` and `organization_id` are empty or not passed")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "parameters `room_id` and `organization_id` are empty or not passed")
+		return
+	}
+
+	// convert params strings to int
+	roomId, err := strconv.Atoi(roomIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "RoomShareHandler.UnshareRoom(): room_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "room_id should be an integer", err.Error())
+		return
+	}
+	organizationId, err := strconv.Atoi(organizationIdStr)
+	if err != nil {
+		slog.WarnContext(r.Context(), "RoomShareHandler.UnshareRoom(): organization_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "organization_id should be an integer", err.Error())
+		return
+	}
+
+	// stop sharing room
+	_, err = h.tenantService(r).RoomService.UnshareRoom(r.Context(), roomId, organizationId)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "RoomShareHandler.UnshareRoom(): error occured during stopping Room sharing", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during stopping Room sharing", err.Error())
+		return
+	}
+
+	// return success message
+	pkg.Response(w, "success")
+}
@@ -3,34 +3,91 @@ package handlers
 import (
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"go-booking-system/internal/metrics"
+	"go-booking-system/internal/ratelimit"
 	"go-booking-system/internal/services"
 	"net/http"
+	"sync/atomic"
 )
 
+// Config of HTTP layer: optional endpoints and CORS
+type Config struct {
+	CORS CORSConfig
+	// TrustedProxies IP addresses and networks of reverse proxies - client IP address is taken from their forwarding headers
+	TrustedProxies []string
+
+	// SSOEnabled log in by OpenID Connect identity provider: `/auth/oidc/login` and `/auth/oidc/callback`
+	SSOEnabled bool
+	// RegistrationEnabled self-registration by `/auth/register`. Otherwise users are created by HR or CLI
+	RegistrationEnabled bool
+	MetricsEnabled      bool
+	// MetricsToken bearer token required by `/metrics`. Empty - not protected
+	MetricsToken   string
+	SwaggerEnabled bool
+	// SwaggerURL URL of API definition opened by Swagger UI. Relative URL works behind any host and proxy
+	SwaggerURL string
+}
+
 type Handlers struct {
 	service *services.Service
+	config  Config
+	limiter *ratelimit.Limiter
+
+	// shuttingDown is set on SIGTERM - readiness probe fails
+	shuttingDown atomic.Bool
 }
 
-func NewHandler(s *services.Service) *Handlers {
-	return &Handlers{service: s}
+func NewHandler(s *services.Service, config Config, limiter *ratelimit.Limiter) *Handlers {
+	return &Handlers{service: s, config: config, limiter: limiter}
 }
 
 func (h *Handlers) Init() *mux.Router {
 	router := mux.NewRouter()
-	router.Use(CORS, RecoverAllPanic, h.AuthorizationCheck)
+	router.Use(RequestId, RealIP(h.config.TrustedProxies), Tracing, Metrics, CORS(h.config.CORS), RecoverAllPanic, h.AuthorizationCheck)
 
 	// Auth Handler
 	auth := router.PathPrefix("/auth").Subrouter()
-	auth.HandleFunc("/register", h.Register).Methods(http.MethodPost, http.MethodOptions)
+	if h.config.RegistrationEnabled {
+		auth.HandleFunc("/register", h.Register).Methods(http.MethodPost, http.MethodOptions)
+	}
 	auth.HandleFunc("/login", h.Login).Methods(http.MethodPost, http.MethodOptions)
+	auth.HandleFunc("/login/mfa", h.LoginMFA).Methods(http.MethodPost, http.MethodOptions)
 	auth.HandleFunc("/refresh", h.RefreshToken).Methods(http.MethodPost, http.MethodOptions)
 
+	// Account Handler (password reset and email verification by links sent by email)
+	auth.HandleFunc("/forgot-password", h.ForgotPassword).Methods(http.MethodPost, http.MethodOptions)
+	auth.HandleFunc("/reset-password", h.ResetPassword).Methods(http.MethodPost, http.MethodOptions)
+	auth.HandleFunc("/verify-email", h.VerifyEmail).Methods(http.MethodPost, http.MethodOptions)
+	auth.HandleFunc("/verify-email/resend", h.ResendEmailVerification).Methods(http.MethodPost, http.MethodOptions)
+
+	// SSO Handler (log in by identity provider)
+	if h.config.SSOEnabled {
+		auth.HandleFunc("/oidc/login", h.SSOLogin).Methods(http.MethodGet, http.MethodOptions)
+		auth.HandleFunc("/oidc/callback", h.SSOCallback).Methods(http.MethodGet, http.MethodOptions)
+	}
+
+	// MFA Handler (TOTP of the caller)
+	auth.HandleFunc("/mfa/enroll", h.EnrollMFA).Methods(http.MethodPost, http.MethodOptions)
+	auth.HandleFunc("/mfa/confirm", h.ConfirmMFA).Methods(http.MethodPost, http.MethodOptions)
+	auth.HandleFunc("/mfa/disable", h.DisableMFA).Methods(http.MethodPost, http.MethodOptions)
+	auth.HandleFunc("/mfa/recovery-codes", h.RegenerateRecoveryCodes).Methods(http.MethodPost, http.MethodOptions)
+
 	// User Handler
 	user := router.PathPrefix("/user").Subrouter()
 	user.HandleFunc("/all", h.GetAllUsers).Methods(http.MethodGet, http.MethodOptions)
 	user.HandleFunc("/", h.GetUserById).Methods(http.MethodGet, http.MethodOptions)
 	user.HandleFunc("/update", h.UpdateUser).Methods(http.MethodPost, http.MethodOptions)
+	user.HandleFunc("/role", h.UpdateUserRole).Methods(http.MethodPost, http.MethodOptions)
 	user.HandleFunc("/drop", h.DeleteUser).Methods(http.MethodDelete, http.MethodOptions)
+	user.HandleFunc("/import", h.ImportUsers).Methods(http.MethodPost, http.MethodOptions)
+	user.HandleFunc("/export", h.ExportUsers).Methods(http.MethodGet, http.MethodOptions)
+
+	// Profile Handler (account of the caller)
+	me := router.PathPrefix("/me").Subrouter()
+	me.HandleFunc("", h.GetProfile).Methods(http.MethodGet, http.MethodOptions)
+	me.HandleFunc("/update", h.UpdateProfile).Methods(http.MethodPost, http.MethodOptions)
+	me.HandleFunc("/password", h.ChangePassword).Methods(http.MethodPost, http.MethodOptions)
+	me.HandleFunc("/username", h.ChangeUsername).Methods(http.MethodPost, http.MethodOptions)
 
 	// Room Handler
 	room := router.PathPrefix("/room").Subrouter()
@@ -39,6 +96,11 @@ func (h *Handlers) Init() *mux.Router {
 	room.HandleFunc("/", h.GetRoomById).Methods(http.MethodGet, http.MethodOptions)
 	room.HandleFunc("/update", h.UpdateRoom).Methods(http.MethodPost, http.MethodOptions)
 	room.HandleFunc("/drop", h.DeleteRoom).Methods(http.MethodDelete, http.MethodOptions)
+	room.HandleFunc("/share", h.ShareRoom).Methods(http.MethodPost, http.MethodOptions)
+	room.HandleFunc("/shares", h.GetRoomShares).Methods(http.MethodGet, http.MethodOptions)
+	room.HandleFunc("/unshare", h.UnshareRoom).Methods(http.MethodDelete, http.MethodOptions)
+	room.HandleFunc("/import", h.ImportRooms).Methods(http.MethodPost, http.MethodOptions)
+	room.HandleFunc("/export", h.ExportRooms).Methods(http.MethodGet, http.MethodOptions)
 
 	// Booking Handler
 	booking := router.PathPrefix("/booking").Subrouter()
@@ -51,15 +113,110 @@ func (h *Handlers) Init() *mux.Router {
 	booking.HandleFunc("/create", h.BookRoom).Methods(http.MethodPost, http.MethodOptions)
 	booking.HandleFunc("/overlapping", h.GetOverlappingBookings).Methods(http.MethodGet, http.MethodOptions)
 	booking.HandleFunc("/update", h.UpdateBooking).Methods(http.MethodPatch, http.MethodOptions)
+	booking.HandleFunc("/attendees", h.GetAttendees).Methods(http.MethodGet, http.MethodOptions)
+	booking.HandleFunc("/attendees/add", h.AddAttendees).Methods(http.MethodPost, http.MethodOptions)
+	booking.HandleFunc("/attendees/drop", h.RemoveAttendee).Methods(http.MethodDelete, http.MethodOptions)
+	booking.HandleFunc("/respond", h.RespondToInvitation).Methods(http.MethodPost, http.MethodOptions)
+	booking.HandleFunc("/invitations", h.GetInvitations).Methods(http.MethodGet, http.MethodOptions)
+
+	// Reception Handler (visitor passes of external guests)
+	reception := router.PathPrefix("/reception").Subrouter()
+	reception.HandleFunc("/passes", h.GetVisitorPasses).Methods(http.MethodGet, http.MethodOptions)
+	reception.HandleFunc("/pass", h.GetVisitorPassByCode).Methods(http.MethodGet, http.MethodOptions)
+
+	// Location Handler
+	location := router.PathPrefix("/location").Subrouter()
+	location.HandleFunc("/create", h.CreateLocation).Methods(http.MethodPost, http.MethodOptions)
+	location.HandleFunc("/all", h.GetAllLocations).Methods(http.MethodGet, http.MethodOptions)
+	location.HandleFunc("/", h.GetLocationById).Methods(http.MethodGet, http.MethodOptions)
+	location.HandleFunc("/update", h.UpdateLocation).Methods(http.MethodPost, http.MethodOptions)
+	location.HandleFunc("/drop", h.DeleteLocation).Methods(http.MethodDelete, http.MethodOptions)
+	location.HandleFunc("/rooms", h.GetRoomsByLocationId).Methods(http.MethodGet, http.MethodOptions)
+	location.HandleFunc("/available", h.GetAvailableRoomsByLocationId).Methods(http.MethodGet, http.MethodOptions)
+	location.HandleFunc("/opening-hours", h.GetOpeningHours).Methods(http.MethodGet, http.MethodOptions)
+	location.HandleFunc("/opening-hours/update", h.UpdateOpeningHours).Methods(http.MethodPost, http.MethodOptions)
+
+	// Building Handler
+	building := router.PathPrefix("/building").Subrouter()
+	building.HandleFunc("/create", h.CreateBuilding).Methods(http.MethodPost, http.MethodOptions)
+	building.HandleFunc("/all", h.GetAllBuildings).Methods(http.MethodGet, http.MethodOptions)
+	building.HandleFunc("/", h.GetBuildingById).Methods(http.MethodGet, http.MethodOptions)
+	building.HandleFunc("/update", h.UpdateBuilding).Methods(http.MethodPost, http.MethodOptions)
+	building.HandleFunc("/drop", h.DeleteBuilding).Methods(http.MethodDelete, http.MethodOptions)
+
+	// Floor Handler
+	floor := router.PathPrefix("/floor").Subrouter()
+	floor.HandleFunc("/create", h.CreateFloor).Methods(http.MethodPost, http.MethodOptions)
+	floor.HandleFunc("/all", h.GetAllFloors).Methods(http.MethodGet, http.MethodOptions)
+	floor.HandleFunc("/", h.GetFloorById).Methods(http.MethodGet, http.MethodOptions)
+	floor.HandleFunc("/update", h.UpdateFloor).Methods(http.MethodPost, http.MethodOptions)
+	floor.HandleFunc("/drop", h.DeleteFloor).Methods(http.MethodDelete, http.MethodOptions)
+
+	// Device Handler
+	device := router.PathPrefix("/device").Subrouter()
+	device.HandleFunc("/register", h.RegisterDevice).Methods(http.MethodPost, http.MethodOptions)
+	device.HandleFunc("/all", h.GetAllDevices).Methods(http.MethodGet, http.MethodOptions)
+	device.HandleFunc("/drop", h.RevokeDevice).Methods(http.MethodDelete, http.MethodOptions)
+
+	// Service Account Handler (integrations authenticated by API keys)
+	serviceAccount := router.PathPrefix("/service-account").Subrouter()
+	serviceAccount.HandleFunc("/create", h.CreateServiceAccount).Methods(http.MethodPost, http.MethodOptions)
+	serviceAccount.HandleFunc("/all", h.GetServiceAccounts).Methods(http.MethodGet, http.MethodOptions)
+	apiKey := router.PathPrefix("/api-key").Subrouter()
+	apiKey.HandleFunc("/create", h.CreateAPIKey).Methods(http.MethodPost, http.MethodOptions)
+	apiKey.HandleFunc("/all", h.GetAllAPIKeys).Methods(http.MethodGet, http.MethodOptions)
+	apiKey.HandleFunc("/drop", h.RevokeAPIKey).Methods(http.MethodDelete, http.MethodOptions)
+
+	// Policy Handler (attribute-based access rules)
+	policy := router.PathPrefix("/policy").Subrouter()
+	policy.HandleFunc("/create", h.CreatePolicy).Methods(http.MethodPost, http.MethodOptions)
+	policy.HandleFunc("/all", h.GetAllPolicies).Methods(http.MethodGet, http.MethodOptions)
+	policy.HandleFunc("/update", h.UpdatePolicy).Methods(http.MethodPost, http.MethodOptions)
+	policy.HandleFunc("/drop", h.DeletePolicy).Methods(http.MethodDelete, http.MethodOptions)
+	policy.HandleFunc("/explain", h.ExplainAccess).Methods(http.MethodPost, http.MethodOptions)
+
+	// Display Handler (room displays - kiosk mode)
+	display := router.PathPrefix("/display").Subrouter()
+	display.HandleFunc("/{room_id}", h.GetRoomDisplay).Methods(http.MethodGet, http.MethodOptions)
+	display.HandleFunc("/{room_id}/book-now", h.BookRoomNow).Methods(http.MethodPost, http.MethodOptions)
+	display.HandleFunc("/{room_id}/check-in", h.CheckInRoom).Methods(http.MethodPost, http.MethodOptions)
+
+	// Organization Handler
+	organization := router.PathPrefix("/organization").Subrouter()
+	organization.HandleFunc("/create", h.CreateOrganization).Methods(http.MethodPost, http.MethodOptions)
+	organization.HandleFunc("/all", h.GetAllOrganizations).Methods(http.MethodGet, http.MethodOptions)
+	organization.HandleFunc("/", h.GetOrganizationById).Methods(http.MethodGet, http.MethodOptions)
+	organization.HandleFunc("/update", h.UpdateOrganization).Methods(http.MethodPost, http.MethodOptions)
+	organization.HandleFunc("/drop", h.DeleteOrganization).Methods(http.MethodDelete, http.MethodOptions)
+	organization.HandleFunc("/mfa-policy", h.GetMFAPolicy).Methods(http.MethodGet, http.MethodOptions)
+	organization.HandleFunc("/mfa-policy/update", h.UpdateMFAPolicy).Methods(http.MethodPost, http.MethodOptions)
+
+	// Report Handler (analytics, `format=csv` - download as CSV)
+	report := router.PathPrefix("/report").Subrouter()
+	report.HandleFunc("/utilization", h.GetUtilizationReport).Methods(http.MethodGet, http.MethodOptions)
+	report.HandleFunc("/peak-hours", h.GetPeakHoursReport).Methods(http.MethodGet, http.MethodOptions)
+	report.HandleFunc("/bookings", h.GetBookingStatsReport).Methods(http.MethodGet, http.MethodOptions)
+	report.HandleFunc("/top-bookers", h.GetTopBookersReport).Methods(http.MethodGet, http.MethodOptions)
+
+	// Health checks (not checked by authorization)
+	router.HandleFunc("/healthz", h.Healthz).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", h.Readyz).Methods(http.MethodGet)
+
+	// Metrics in Prometheus text format. Protected by metrics token if it is set
+	if h.config.MetricsEnabled {
+		router.Handle("/metrics", MetricsAuth(h.config.MetricsToken, metrics.Handler())).Methods(http.MethodGet)
+	}
 
 	// Swagger Handler
-	swagger := router.PathPrefix("/swagger")
-	swagger.Handler(httpSwagger.Handler(
-		httpSwagger.URL("http://localhost:8080/swagger/doc.json"), //The url pointing to API definition
-		httpSwagger.DeepLinking(true),
-		httpSwagger.DocExpansion("none"),
-		httpSwagger.DomID("swagger-ui"),
-	)).Methods(http.MethodGet)
+	if h.config.SwaggerEnabled {
+		swagger := router.PathPrefix("/swagger")
+		swagger.Handler(httpSwagger.Handler(
+			httpSwagger.URL(h.config.SwaggerURL), //The url pointing to API definition
+			httpSwagger.DeepLinking(true),
+			httpSwagger.DocExpansion("none"),
+			httpSwagger.DomID("swagger-ui"),
+		)).Methods(http.MethodGet)
+	}
 
 	return router
 }
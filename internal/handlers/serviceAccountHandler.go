@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ServiceAccountParams struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Email    string `json:"email"` // optional - contact of the integration's owners
+	RoleId   int    `json:"role_id"`
+}
+
+type APIKeyParams struct {
+	UserId      int                       `json:"user_id"` // service account
+	Name        string                    `json:"name"`
+	Permissions []models.APIKeyPermission `json:"permissions"`
+	ExpiresAt   time.Time                 `json:"expires_at"` // empty - auth.apiKeyMaxTTL from now
+}
+
+type IssuedAPIKey struct {
+	APIKey models.APIKey `json:"api_key"`
+	Key    string        `json:"key"` // shown only once
+}
+
+func (h *Handlers) CreateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	createdBy, ok := subjectUserId(w, r, "ServiceAccountHandler.CreateServiceAccount()")
+	if !ok {
+		return
+	}
+
+	params := ServiceAccountParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		slog.WarnContext(r.Context(), "ServiceAccountHandler.CreateServiceAccount(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+	params.Username = strings.TrimSpace(params.Username)
+	if params.Name == "" || params.Username == "" || params.RoleId <= 0 {
+		slog.WarnContext(r.Context(), "ServiceAccountHandler.CreateServiceAccount(): service account data is not valid", "passed_data", params)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "name and username should not be empty, role_id should be positive")
+		return
+	}
+
+	serviceAccount, err := h.tenantService(r).ServiceAccountService.CreateServiceAccount(r.Context(), createdBy, models.User{
+		Name:     params.Name,
+		UserName: params.Username,
+		Email:    params.Email,
+		RoleId:   params.RoleId,
+	})
+	if err != nil {
+		slog.WarnContext(r.Context(), "ServiceAccountHandler.CreateServiceAccount(): error occured during service account creation", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during service account creation", err.Error())
+		return
+	}
+
+	pkg.Response(w, serviceAccount)
+}
+
+func (h *Handlers) GetServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	serviceAccounts, err := h.tenantService(r).ServiceAccountService.GetServiceAccounts(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "ServiceAccountHandler.GetServiceAccounts(): error occured during service accounts search", "error", err)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during service accounts search", err.Error())
+		return
+	}
+
+	pkg.Response(w, serviceAccounts)
+}
+
+// CreateAPIKey issues key of the service account. Permissions list routes (`route_id` or `url`) and scopes the key allows
+func (h *Handlers) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	createdBy, ok := subjectUserId(w, r, "ServiceAccountHandler.CreateAPIKey()")
+	if !ok {
+		return
+	}
+
+	params := APIKeyParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		slog.WarnContext(r.Context(), "ServiceAccountHandler.CreateAPIKey(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+	if params.UserId <= 0 || params.Name == "" {
+		slog.WarnContext(r.Context(), "ServiceAccountHandler.CreateAPIKey(): API key data is not valid", "user_id", params.UserId, "name", params.Name)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "user_id should be positive, name should not be empty")
+		return
+	}
+
+	apiKey, key, err := h.tenantService(r).ServiceAccountService.CreateAPIKey(r.Context(), models.APIKey{
+		UserId:      params.UserId,
+		Name:        params.Name,
+		Permissions: params.Permissions,
+		ExpiresAt:   params.ExpiresAt,
+		CreatedBy:   createdBy,
+	})
+	if err != nil {
+		slog.WarnContext(r.Context(), "ServiceAccountHandler.CreateAPIKey(): error occured during API key creation", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during API key creation", err.Error())
+		return
+	}
+
+	pkg.Response(w, IssuedAPIKey{APIKey: apiKey, Key: key})
+}
+
+func (h *Handlers) GetAllAPIKeys(w http.ResponseWriter, r *http.Request) {
+	pkg.Response(w, h.tenantService(r).ServiceAccountService.GetAPIKeys(r.Context()))
+}
+
+func (h *Handlers) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	apiKeyId, err := strconv.Atoi(r.URL.Query().Get("api_key_id"))
+	if err != nil {
+		slog.WarnContext(r.Context(), "ServiceAccountHandler.RevokeAPIKey(): api_key_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "api_key_id should be an integer", err.Error())
+		return
+	}
+
+	if _, err := h.tenantService(r).ServiceAccountService.RevokeAPIKey(r.Context(), apiKeyId); err != nil {
+		slog.WarnContext(r.Context(), "ServiceAccountHandler.RevokeAPIKey(): error occured during API key revocation", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during API key revocation", err.Error())
+		return
+	}
+
+	pkg.Response(w, "success")
+}
+
+// apiKeyOf returns API key of the request: `X-API-Key` header or `Authorization: ApiKey <key>`. Empty - not passed
+func apiKeyOf(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	if key, found := strings.CutPrefix(r.Header.Get("Authorization"), APIKeyAuthorizationScheme+" "); found {
+		return key
+	}
+
+	return ""
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"go-booking-system/internal/metrics"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+const (
+	// ssoFlowCookie keeps state of log in at identity provider between redirect to the provider and callback
+	ssoFlowCookie = "oidc_flow"
+	ssoCookiePath = "/auth/oidc"
+)
+
+// SSOLogin redirects to login page of identity provider (OIDC authorization code flow with PKCE)
+func (h *Handlers) SSOLogin(w http.ResponseWriter, r *http.Request) {
+	identity := requestIdentity(r)
+
+	authURL, flowToken, err := h.service.SSOService.StartLogin(r.Context(), identity)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "SSOHandler.SSOLogin(): error occured during start of log in by identity provider", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadGateway, "identity provider is not available")
+		return
+	}
+
+	// Lax - cookie is sent with top-level redirect back from the provider
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoFlowCookie,
+		Value:    flowToken,
+		Path:     ssoCookiePath,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// SSOCallback identity provider redirects here with `code` - it is exchanged for ID token, the user is provisioned and
+// gets tokens the same way as by `/auth/login`
+func (h *Handlers) SSOCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	// log in is finished - the cookie is not needed anymore
+	http.SetCookie(w, &http.Cookie{Name: ssoFlowCookie, Path: ssoCookiePath, MaxAge: -1, HttpOnly: true, Secure: r.TLS != nil, SameSite: http.SameSiteLaxMode})
+
+	if providerError := query.Get("error"); providerError != "" {
+		metrics.LoginsFailedTotal.Inc()
+		slog.WarnContext(r.Context(), "SSOHandler.SSOCallback(): identity provider returned error", "error", providerError, "details", query.Get("error_description"))
+		pkg.ErrorResponse(w, http.StatusUnauthorized, services.SSOLoginError, providerError)
+		return
+	}
+	flowCookie, err := r.Cookie(ssoFlowCookie)
+	if err != nil || query.Get("code") == "" {
+		slog.WarnContext(r.Context(), "SSOHandler.SSOCallback(): code or log in cookie is missing")
+		pkg.ErrorResponse(w, http.StatusBadRequest, "log in is not started - open /auth/oidc/login")
+		return
+	}
+
+	identity := requestIdentity(r)
+	claims, err := h.service.SSOService.Authenticate(r.Context(), query.Get("code"), query.Get("state"), flowCookie.Value, identity)
+	if err != nil {
+		metrics.LoginsFailedTotal.Inc()
+		slog.WarnContext(r.Context(), "SSOHandler.SSOCallback(): error occured during authentication by identity provider", "error", err)
+		pkg.ErrorResponse(w, http.StatusUnauthorized, services.SSOLoginError, err.Error())
+		return
+	}
+
+	tenantService, organizationError := h.organizationService(r.Context(), strconv.Itoa(h.service.SSOService.OrganizationId()))
+	if organizationError != nil {
+		slog.ErrorContext(r.Context(), "SSOHandler.SSOCallback(): organization of identity provider users is not valid", "error", organizationError)
+		pkg.ErrorResponse(w, http.StatusInternalServerError, services.SSOLoginError)
+		return
+	}
+	user, err := tenantService.SSOService.Provision(r.Context(), claims)
+	if err != nil {
+		metrics.LoginsFailedTotal.Inc()
+		slog.WarnContext(r.Context(), "SSOHandler.SSOCallback(): user of identity provider cannot be provisioned", "subject", claims.Subject, "error", err)
+		pkg.ErrorResponse(w, http.StatusForbidden, services.SSOLoginError, err.Error())
+		return
+	}
+
+	authMethods := services.SSOAuthMethods(claims)
+	// TOTP of the application is asked if identity provider has not checked second factor
+	if user.TOTPEnabled && !slices.Contains(authMethods, services.AuthMethodMFA) {
+		h.mfaChallengeResponse(w, r, user, identity)
+		return
+	}
+
+	h.tokensResponse(w, r, user, identity, authMethods)
+}
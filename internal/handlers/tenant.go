@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"context"
+	"go-booking-system/internal/services"
+	"net/http"
+)
+
+type contextKey string
+
+const tenantServiceContextKey contextKey = "tenant-service"
+
+// tenantService returns services limited to organization of the caller - they are put to request context by AuthorizationCheck.
+// Requests passing without authorization (login, registration) get services which are not limited to any organization
+func (h *Handlers) tenantService(r *http.Request) *services.Service {
+	if service, ok := r.Context().Value(tenantServiceContextKey).(*services.Service); ok {
+		return service
+	}
+
+	return h.service
+}
+
+func withTenantService(r *http.Request, service *services.Service) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), tenantServiceContextKey, service))
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// TimeZoneQueryParam IANA time zone name to render times in response. Example: `?tz=Europe/Berlin`
+const TimeZoneQueryParam = "tz"
+
+// responseTimeZone returns time zone requested by `tz` query parameter or time zone from user's profile.
+// nil - times are rendered as they are stored. Writes error response if requested time zone is unknown
+func (h *Handlers) responseTimeZone(w http.ResponseWriter, r *http.Request) (*time.Location, bool) {
+	timeZoneName := r.URL.Query().Get(TimeZoneQueryParam)
+
+	if timeZoneName == "" {
+		userId, conversionError := strconv.Atoi(r.Header.Get("subject"))
+		if conversionError != nil {
+			return nil, true
+		}
+		user, err := h.tenantService(r).UserService.GetUserById(r.Context(), userId)
+		if err != nil || user.TimeZone == "" {
+			return nil, true
+		}
+		timeZoneName = user.TimeZone
+	}
+
+	timeZone, err := time.LoadLocation(timeZoneName)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Handlers.responseTimeZone(): unknown time zone", "passed_data", timeZoneName)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "unknown time zone", timeZoneName)
+		return nil, false
+	}
+
+	return timeZone, true
+}
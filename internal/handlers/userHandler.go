@@ -3,16 +3,18 @@ package handlers
 import (
 	"encoding/json"
 	"go-booking-system/internal/models"
+	"go-booking-system/internal/services"
 	"go-booking-system/pkg"
-	"log"
+	"log/slog"
 	"net/http"
 	"regexp"
 	"strconv"
+	"time"
 )
 
 func (h *Handlers) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	// get all users
-	users := h.service.UserService.GetAll()
+	users := h.tenantService(r).UserService.GetAll(r.Context())
 
 	// return all users
 	pkg.Response(w, users)
@@ -22,7 +24,7 @@ func (h *Handlers) GetUserById(w http.ResponseWriter, r *http.Request) {
 	// get user_id from query path
 	userIdStr := r.URL.Query().Get("user_id")
 	if userIdStr == "" {
-		log.Println("UserHandler.DeleteRoom(): parameter `user_id` is empty or not passed")
+		slog.WarnContext(r.Context(), "UserHandler.DeleteRoom(): parameter `user_id` is empty or not passed")
 		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `user_id` is empty or not passed")
 		return
 	}
@@ -30,15 +32,15 @@ func (h *Handlers) GetUserById(w http.ResponseWriter, r *http.Request) {
 	// convert user_id param string to int
 	userId, err := strconv.Atoi(userIdStr)
 	if err != nil {
-		log.Println("UserHandler.GetUserById(): user_id should be an integer. Details: ", err)
+		slog.WarnContext(r.Context(), "UserHandler.GetUserById(): user_id should be an integer", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "user_id should be an integer", err.Error())
 		return
 	}
 
 	// get User from services
-	user, err := h.service.UserService.GetUserById(userId)
+	user, err := h.tenantService(r).UserService.GetUserById(r.Context(), userId)
 	if err != nil {
-		log.Println("UserHandler.GetUserById(): error occured during getting user by id. Details: ", err)
+		slog.ErrorContext(r.Context(), "UserHandler.GetUserById(): error occured during getting user by id", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during getting user by id", err.Error())
 		return
 	}
@@ -51,7 +53,7 @@ func (h *Handlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	// get user_id from query path
 	userIdStr := r.URL.Query().Get("user_id")
 	if userIdStr == "" {
-		log.Println("UserHandler.UpdateUser(): parameter `user_id` is empty or not passed")
+		slog.WarnContext(r.Context(), "UserHandler.UpdateUser(): parameter `user_id` is empty or not passed")
 		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `user_id` is empty or not passed")
 		return
 	}
@@ -59,7 +61,7 @@ func (h *Handlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	// convert user_id param string to int
 	userId, err := strconv.Atoi(userIdStr)
 	if err != nil {
-		log.Println("UserHandler.UpdateUser(): user_id should be an integer. Details: ", err)
+		slog.WarnContext(r.Context(), "UserHandler.UpdateUser(): user_id should be an integer", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "user_id should be an integer", err.Error())
 		return
 	}
@@ -68,15 +70,15 @@ func (h *Handlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	// convert JSON to models.User type
 	err = json.NewDecoder(r.Body).Decode(&userParamsToUpdate)
 	if err != nil {
-		log.Println("UserHandler.UpdateUser(): cannot convert JSON to models.User struct. Details: ", err)
+		slog.WarnContext(r.Context(), "UserHandler.UpdateUser(): cannot convert JSON to models.User struct", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "cannot convert JSON to models.User struct", err.Error())
 		return
 	}
 
-	// validate passed user data
-	validator := NewUserValidator(&userParamsToUpdate)
+	// validate passed user data. Role is not changed here - it is checked only if passed
+	validator := NewProfileValidator(&userParamsToUpdate)
 	if validator.AllUserFieldsValid != true {
-		log.Println("UserHandler.UpdateUser(): User data is not valid. Details: ", validator.ValidationErrors)
+		slog.WarnContext(r.Context(), "UserHandler.UpdateUser(): User data is not valid", "details", validator.ValidationErrors)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "User data is not valid", validator.ValidationErrors)
 		return
 	}
@@ -85,9 +87,14 @@ func (h *Handlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	userParamsToUpdate.UserId = userId
 
 	// update user
-	updatedUser, err := h.service.UserService.Update(userParamsToUpdate)
+	updatedUser, err := h.tenantService(r).UserService.Update(r.Context(), userParamsToUpdate)
 	if err != nil {
-		log.Println("UserHandler.UpdateUser(): error occured during user update. Details: ", err)
+		if err.Error() == services.RoleChangeError {
+			slog.WarnContext(r.Context(), "UserHandler.UpdateUser(): role cannot be changed with profile", "error", err)
+			pkg.ErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+		slog.ErrorContext(r.Context(), "UserHandler.UpdateUser(): error occured during user update", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during user update", err.Error())
 		return
 	}
@@ -96,11 +103,57 @@ func (h *Handlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	pkg.Response(w, updatedUser)
 }
 
+type UserRoleParams struct {
+	RoleId int `json:"role_id"`
+}
+
+// UpdateUserRole changes role of the user. Only administrators (roles having permission to the route) do that - the
+// service checks it too
+func (h *Handlers) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	changedBy, ok := subjectUserId(w, r, "UserHandler.UpdateUserRole()")
+	if !ok {
+		return
+	}
+
+	userId, err := strconv.Atoi(r.URL.Query().Get("user_id"))
+	if err != nil {
+		slog.WarnContext(r.Context(), "UserHandler.UpdateUserRole(): user_id should be an integer", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "user_id should be an integer", err.Error())
+		return
+	}
+
+	params := UserRoleParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		slog.WarnContext(r.Context(), "UserHandler.UpdateUserRole(): error occured during decoding JSON", "details", err.Error())
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during decoding JSON", err.Error())
+		return
+	}
+	if params.RoleId <= 0 {
+		slog.WarnContext(r.Context(), "UserHandler.UpdateUserRole(): role_id should be positive", "passed_data", params.RoleId)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "role_id should be positive")
+		return
+	}
+
+	user, err := h.tenantService(r).AuthService.ChangeRole(r.Context(), changedBy, userId, params.RoleId)
+	if err != nil {
+		if err.Error() == services.RoleChangeForbiddenError || err.Error() == services.OwnRoleChangeError {
+			slog.WarnContext(r.Context(), "UserHandler.UpdateUserRole(): role is not changed", "error", err)
+			pkg.ErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+		slog.WarnContext(r.Context(), "UserHandler.UpdateUserRole(): error occured during role change", "error", err)
+		pkg.ErrorResponse(w, http.StatusBadRequest, "error occured during role change", err.Error())
+		return
+	}
+
+	pkg.Response(w, user)
+}
+
 func (h *Handlers) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	// get user_id from query path
 	userIdStr := r.URL.Query().Get("user_id")
 	if userIdStr == "" {
-		log.Println("UserHandler.DeleteUser(): parameter `user_id` is empty or not passed")
+		slog.WarnContext(r.Context(), "UserHandler.DeleteUser(): parameter `user_id` is empty or not passed")
 		pkg.ErrorResponse(w, http.StatusBadRequest, "parameter `user_id` is empty or not passed")
 		return
 	}
@@ -108,15 +161,15 @@ func (h *Handlers) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	// convert user_id param string to int
 	userId, err := strconv.Atoi(userIdStr)
 	if err != nil {
-		log.Println("UserHandler.DeleteUser(): user_id should be an integer. Details: ", err)
+		slog.WarnContext(r.Context(), "UserHandler.DeleteUser(): user_id should be an integer", "error", err)
 		pkg.ErrorResponse(w, http.StatusBadRequest, "user_id should be an integer", err.Error())
 		return
 	}
 
 	// delete user
-	_, err = h.service.UserService.Delete(userId)
+	_, err = h.tenantService(r).UserService.Delete(r.Context(), userId)
 	if err != nil {
-		log.Println("UserHandler.DeleteUser(): error occured during user deletion. Details: ", err)
+		slog.ErrorContext(r.Context(), "UserHandler.DeleteUser(): error occured during user deletion", "error", err)
 		pkg.ErrorResponse(w, http.StatusInternalServerError, "error occured during user deletion", err.Error())
 		return
 	}
@@ -132,6 +185,7 @@ type UserValidator struct {
 	IsRoleIdValid      bool              `json:"is_roleid_valid"`
 	IsEmailValid       bool              `json:"is_email_valid"`
 	IsTelephoneValid   bool              `json:"is_telephone_valid"`
+	IsTimeZoneValid    bool              `json:"is_time_zone_valid"`
 	AllUserFieldsValid bool              `json:"all_user_fields_valid"`
 }
 
@@ -141,6 +195,7 @@ func NewUserValidator(user *models.User) *UserValidator {
 		"roleid_error":    "User.RoleId: should not be negative integer or zero",
 		"email_error":     "User.Email: wrong email format",
 		"telephone_error": "User.Telephone: wrong telephone number",
+		"time_zone_error": "User.TimeZone: should be empty or IANA time zone name",
 	}
 
 	validator := &UserValidator{UserToValidate: user, ValidationErrors: validationErrors, AllUserFieldsValid: false}
@@ -149,10 +204,23 @@ func NewUserValidator(user *models.User) *UserValidator {
 	return validator
 }
 
+// NewProfileValidator validates fields of the profile - role is not required, it is changed by `/user/role`
+func NewProfileValidator(user *models.User) *UserValidator {
+	validator := &UserValidator{UserToValidate: user, IsRoleIdValid: true, AllUserFieldsValid: false, ValidationErrors: map[string]string{
+		"name_error":      "User.Name: should not be empty string",
+		"email_error":     "User.Email: wrong email format",
+		"telephone_error": "User.Telephone: wrong telephone number",
+		"time_zone_error": "User.TimeZone: should be empty or IANA time zone name",
+	}}
+	validator.IsUserValid()
+
+	return validator
+}
+
 func (u *UserValidator) IsUserValid() {
 	u.ValidateFields()
 
-	if u.IsNameValid && u.IsRoleIdValid && u.IsEmailValid && u.IsTelephoneValid {
+	if u.IsNameValid && u.IsRoleIdValid && u.IsEmailValid && u.IsTelephoneValid && u.IsTimeZoneValid {
 		u.AllUserFieldsValid = true
 	}
 }
@@ -177,4 +245,8 @@ func (u *UserValidator) ValidateFields() {
 		u.IsTelephoneValid = true
 		delete(u.ValidationErrors, "telephone_error")
 	}
+	if _, err := time.LoadLocation(u.UserToValidate.TimeZone); err == nil {
+		u.IsTimeZoneValid = true
+		delete(u.ValidationErrors, "time_zone_error")
+	}
 }
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+const queryStartKey = "metrics:query_start"
+
+// GormPlugin measures duration of every query made by gorm
+type GormPlugin struct{}
+
+func (p GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("metrics:before_create", startQuery),
+		callbacks.Create().After("gorm:create").Register("metrics:after_create", finishQuery("create")),
+		callbacks.Query().Before("gorm:query").Register("metrics:before_query", startQuery),
+		callbacks.Query().After("gorm:query").Register("metrics:after_query", finishQuery("query")),
+		callbacks.Update().Before("gorm:update").Register("metrics:before_update", startQuery),
+		callbacks.Update().After("gorm:update").Register("metrics:after_update", finishQuery("update")),
+		callbacks.Delete().Before("gorm:delete").Register("metrics:before_delete", startQuery),
+		callbacks.Delete().After("gorm:delete").Register("metrics:after_delete", finishQuery("delete")),
+		callbacks.Row().Before("gorm:row").Register("metrics:before_row", startQuery),
+		callbacks.Row().After("gorm:row").Register("metrics:after_row", finishQuery("row")),
+		callbacks.Raw().Before("gorm:raw").Register("metrics:before_raw", startQuery),
+		callbacks.Raw().After("gorm:raw").Register("metrics:after_raw", finishQuery("raw")),
+	)
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func finishQuery(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		DBQueryDuration.WithLabelValues(operation, db.Statement.Table).Observe(time.Since(start).Seconds())
+	}
+}
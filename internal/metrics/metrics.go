@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+// Namespace prefix of all metrics of the application
+const Namespace = "booking"
+
+// Registry contains only metrics of the application and Go runtime - default registry of prometheus is not used
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of processed HTTP requests by route template, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests by route template, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of DB queries made by gorm by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	BookingsCreatedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "bookings_created_total",
+		Help:      "Number of created bookings.",
+	})
+
+	BookingConflictsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "booking_conflicts_total",
+		Help:      "Number of bookings rejected because of overlapping bookings.",
+	})
+
+	LoginsFailedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "logins_failed_total",
+		Help:      "Number of failed logins.",
+	})
+
+	AccountLockoutsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "account_lockouts_total",
+		Help:      "Number of accounts temporarily locked after failed logins.",
+	})
+
+	RateLimitedRequestsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Number of login and registration requests rejected by rate limits.",
+	})
+
+	PermissionDenialsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "permission_denials_total",
+		Help:      "Number of requests denied by permission check by role of the user.",
+	}, []string{"role"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		DBQueryDuration,
+		BookingsCreatedTotal,
+		BookingConflictsTotal,
+		LoginsFailedTotal,
+		AccountLockoutsTotal,
+		RateLimitedRequestsTotal,
+		PermissionDenialsTotal,
+	)
+}
+
+// RegisterDBStats exports statistics of DB connection pool (open, in use, idle connections, waits)
+func RegisterDBStats(db *sql.DB) error {
+	err := Registry.Register(collectors.NewDBStatsCollector(db, Namespace))
+
+	var alreadyRegisteredError prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegisteredError) {
+		return nil
+	}
+
+	return err
+}
+
+// Handler writes metrics in Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// APIKey long-lived credential of a service account (integrations: chat bot, dashboards). The key allows only routes of
+// its permissions - on top of permissions of the service account's role
+type APIKey struct {
+	APIKeyId       int    `json:"api_key_id" gorm:"primarykey"`
+	OrganizationId int    `json:"organization_id"`
+	UserId         int    `json:"user_id"` // service account
+	Name           string `json:"name"`
+	KeyHash        string `json:"-"`
+
+	Permissions []APIKeyPermission `json:"permissions" gorm:"foreignKey:APIKeyId;references:APIKeyId"`
+
+	// ExpiresAt the key is not accepted after it. LastUsedAt is updated not more often than once a minute
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+
+	Active    bool      `json:"active"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	DeletedAt time.Time `json:"deleted_at"` // time of revocation
+}
+
+// APIKeyPermission route the key may call and scope of records on it. Route is passed by id or by URL
+type APIKeyPermission struct {
+	APIKeyId int    `json:"-" gorm:"primaryKey"`
+	RouteId  int    `json:"route_id" gorm:"primaryKey"`
+	URL      string `json:"url" gorm:"-"`
+	ScopeId  int    `json:"scope_id"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+const (
+	AttendeeStatusPending  = "pending"
+	AttendeeStatusAccepted = "accepted"
+	AttendeeStatusDeclined = "declined"
+)
+
+// Attendee is invited to the booking: internal user (UserId) or external guest identified by name and email
+type Attendee struct {
+	AttendeeId     int       `json:"attendee_id" gorm:"primarykey"`
+	OrganizationId int       `json:"organization_id"`
+	BookingId      int       `json:"booking_id"`
+	UserId         int       `json:"user_id" gorm:"default:null"` // empty - external guest
+	Name           string    `json:"name"`
+	Email          string    `json:"email"`
+	Status         string    `json:"status"`                        // pending, accepted, declined
+	PassCode       string    `json:"pass_code" gorm:"default:null"` // visitor pass code of external guest shown at reception
+	RespondedAt    time.Time `json:"responded_at" gorm:"default:null"`
+
+	Active    bool      `json:"active"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func (a Attendee) IsGuest() bool {
+	return a.UserId == 0
+}
+
+// VisitorPass is issued for external guest of the booking - reception lets guest into the building by pass code
+type VisitorPass struct {
+	PassCode   string    `json:"pass_code"`
+	AttendeeId int       `json:"attendee_id"`
+	GuestName  string    `json:"guest_name"`
+	GuestEmail string    `json:"guest_email"`
+	Status     string    `json:"status"`
+	BookingId  int       `json:"booking_id"`
+	HostUserId int       `json:"host_user_id"` // user the room is booked for
+	RoomId     int       `json:"room_id"`
+	RoomNumber string    `json:"room_number"`
+	ValidFrom  time.Time `json:"valid_from"`
+	ValidUntil time.Time `json:"valid_until"`
+}
+
+// In returns visitor pass with times converted to the given time zone. nil - times are not converted
+func (v VisitorPass) In(location *time.Location) VisitorPass {
+	if location == nil {
+		return v
+	}
+
+	v.ValidFrom = timeIn(v.ValidFrom, location)
+	v.ValidUntil = timeIn(v.ValidUntil, location)
+
+	return v
+}
@@ -6,11 +6,13 @@ import (
 )
 
 type Booking struct {
-	BookingId     int       `json:"booking_id" gorm:"primarykey"`
-	UserId        int       `json:"user_id"`
-	RoomId        int       `json:"room_id"`
-	DateTimeStart time.Time `json:"datetime_start" gorm:"column:datetime_start"`
-	DateTimeEnd   time.Time `json:"datetime_end" gorm:"column:datetime_end"`
+	BookingId      int       `json:"booking_id" gorm:"primarykey"`
+	OrganizationId int       `json:"organization_id"` // organization of the booker - differs from room's one for shared rooms
+	UserId         int       `json:"user_id"`
+	RoomId         int       `json:"room_id"`
+	DateTimeStart  time.Time `json:"datetime_start" gorm:"column:datetime_start"`
+	DateTimeEnd    time.Time `json:"datetime_end" gorm:"column:datetime_end"`
+	CheckedInAt    time.Time `json:"checked_in_at" gorm:"column:checked_in_at"` // filled when attendee checks in on the room display
 
 	Active    bool      `json:"active"`
 	CreatedBy int       `json:"created_by"`
@@ -19,6 +21,41 @@ type Booking struct {
 	DeletedAt time.Time `json:"deleted_at"`
 }
 
+// String time zone offset is printed explicitly - value can carry any time zone
 func (b Booking) String() string {
-	return fmt.Sprintf("Booking {id: %d | time: %s - %s | date: %s | room_id: %d | user_id: %d}", b.BookingId, b.DateTimeStart.Format("15:04:05"), b.DateTimeEnd.Format("15:04:05"), b.DateTimeStart.Format("2006-01-02"), b.RoomId, b.UserId)
+	return fmt.Sprintf("Booking {id: %d | time: %s - %s | date: %s | room_id: %d | user_id: %d}", b.BookingId, b.DateTimeStart.Format("15:04:05 Z07:00"), b.DateTimeEnd.Format("15:04:05 Z07:00"), b.DateTimeStart.Format("2006-01-02"), b.RoomId, b.UserId)
+}
+
+// In returns booking with all times converted to the given time zone. nil - times are not converted
+func (b Booking) In(location *time.Location) Booking {
+	if location == nil {
+		return b
+	}
+
+	b.DateTimeStart = timeIn(b.DateTimeStart, location)
+	b.DateTimeEnd = timeIn(b.DateTimeEnd, location)
+	b.CheckedInAt = timeIn(b.CheckedInAt, location)
+	b.CreatedAt = timeIn(b.CreatedAt, location)
+	b.UpdatedAt = timeIn(b.UpdatedAt, location)
+	b.DeletedAt = timeIn(b.DeletedAt, location)
+
+	return b
+}
+
+func BookingsIn(bookings []Booking, location *time.Location) []Booking {
+	convertedBookings := make([]Booking, 0, len(bookings))
+	for _, booking := range bookings {
+		convertedBookings = append(convertedBookings, booking.In(location))
+	}
+
+	return convertedBookings
+}
+
+// timeIn zero time stays zero - it is used as `null` in the models
+func timeIn(t time.Time, location *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+
+	return t.In(location)
 }
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Device is a tablet (room display) mounted outside a room. Devices authenticate with long-lived tokens
+// and are bound to a single room.
+type Device struct {
+	DeviceId       int    `json:"device_id" gorm:"primarykey"`
+	OrganizationId int    `json:"organization_id"`
+	RoomId         int    `json:"room_id"`
+	RoleId         int    `json:"role_id"`
+	Name           string `json:"name"`
+	TokenHash      string `json:"-" gorm:"column:token_hash"`
+
+	Active    bool      `json:"active"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// RoomDisplay is a compact state of the room shown on the room display
+type RoomDisplay struct {
+	Room      Room       `json:"room"`
+	Now       *Booking   `json:"now"`
+	Next      *Booking   `json:"next"`
+	IsFree    bool       `json:"is_free"`
+	FreeUntil *time.Time `json:"free_until"` // null - room is free till the end of known schedule
+	At        time.Time  `json:"at"`
+}
+
+// In returns room display with all times converted to the given time zone. nil - times are not converted
+func (r RoomDisplay) In(location *time.Location) RoomDisplay {
+	if location == nil {
+		return r
+	}
+
+	if r.Now != nil {
+		now := r.Now.In(location)
+		r.Now = &now
+	}
+	if r.Next != nil {
+		next := r.Next.In(location)
+		r.Next = &next
+	}
+	if r.FreeUntil != nil {
+		freeUntil := r.FreeUntil.In(location)
+		r.FreeUntil = &freeUntil
+	}
+	r.At = r.At.In(location)
+
+	return r
+}
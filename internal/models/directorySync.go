@@ -0,0 +1,37 @@
+package models
+
+const (
+	DirectorySyncCreate     = "create"
+	DirectorySyncUpdate     = "update"
+	DirectorySyncReactivate = "reactivate"
+	DirectorySyncDeactivate = "deactivate"
+	DirectorySyncSkip       = "skip"
+	DirectorySyncError      = "error"
+)
+
+// DirectorySyncResult counts of users changed by sync with LDAP / Active Directory
+type DirectorySyncResult struct {
+	Created     int `json:"created"`
+	Updated     int `json:"updated"`
+	Reactivated int `json:"reactivated"`
+	Deactivated int `json:"deactivated"`
+	Skipped     int `json:"skipped"`
+	Failed      int `json:"failed"`
+}
+
+func (d *DirectorySyncResult) Add(action string) {
+	switch action {
+	case DirectorySyncCreate:
+		d.Created++
+	case DirectorySyncUpdate:
+		d.Updated++
+	case DirectorySyncReactivate:
+		d.Reactivated++
+	case DirectorySyncDeactivate:
+		d.Deactivated++
+	case DirectorySyncSkip:
+		d.Skipped++
+	default:
+		d.Failed++
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionError  = "error"
+)
+
+// ImportRowResult outcome of one row of imported file
+type ImportRowResult struct {
+	Row      int               `json:"row"` // line of the file, header is line 1
+	Key      string            `json:"key"` // email/username of the user or number of the room
+	Action   string            `json:"action"`
+	RecordId int               `json:"record_id,omitempty"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+// ImportResult in dry-run mode rows are only validated and matched with existing records - nothing is written
+type ImportResult struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+func (i *ImportResult) Add(row ImportRowResult) {
+	i.Total++
+	switch row.Action {
+	case ImportActionCreate:
+		i.Created++
+	case ImportActionUpdate:
+		i.Updated++
+	default:
+		i.Failed++
+	}
+
+	i.Rows = append(i.Rows, row)
+}
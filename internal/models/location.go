@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Location is a site of the company (e.g. city or business center). Rooms are organized as Location -> Building -> Floor -> Room
+type Location struct {
+	LocationId     int    `json:"location_id" gorm:"primarykey"`
+	OrganizationId int    `json:"organization_id"`
+	Name           string `json:"name"`
+	Address        string `json:"address"`
+	TimeZone       string `json:"time_zone"` // IANA time zone name. Example: `Asia/Dushanbe`
+
+	Active    bool      `json:"active"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type Building struct {
+	BuildingId     int    `json:"building_id" gorm:"primarykey"`
+	OrganizationId int    `json:"organization_id"`
+	LocationId     int    `json:"location_id"`
+	Name           string `json:"name"`
+	Address        string `json:"address"`
+
+	Active    bool      `json:"active"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type Floor struct {
+	FloorId        int    `json:"floor_id" gorm:"primarykey"`
+	OrganizationId int    `json:"organization_id"`
+	BuildingId     int    `json:"building_id"`
+	Name           string `json:"name"`
+	Level          int    `json:"level"` // 0 - ground floor, negative - basement
+
+	Active    bool      `json:"active"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
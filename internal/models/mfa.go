@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// RecoveryCode one-time code replacing TOTP if authenticator app is lost. Only hash is stored
+type RecoveryCode struct {
+	RecoveryCodeId int        `json:"recovery_code_id" gorm:"primarykey"`
+	UserId         int        `json:"user_id"`
+	CodeHash       string     `json:"-"`
+	UsedAt         *time.Time `json:"used_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// MFAPolicy users of the role in the organization should use two-factor authentication
+type MFAPolicy struct {
+	OrganizationId int       `json:"organization_id" gorm:"primaryKey;autoIncrement:false"`
+	RoleId         int       `json:"role_id" gorm:"primaryKey;autoIncrement:false"`
+	CreatedBy      int       `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// MFAEnrollment secret of authenticator app. URI is shown as QR code
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
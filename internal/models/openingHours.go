@@ -0,0 +1,9 @@
+package models
+
+// OpeningHours rule of location for one day of week. Time is local time of the location
+type OpeningHours struct {
+	LocationId int    `json:"location_id"`
+	Weekday    int    `json:"weekday"`   // 0 - Sunday, 1 - Monday, ..., 6 - Saturday
+	OpensAt    string `json:"opens_at"`  // `HH:MM`. Example: `09:00`
+	ClosesAt   string `json:"closes_at"` // `HH:MM`. `24:00` - till the end of the day
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Organization is a tenant - company sharing deployment with other companies of the business center.
+// Users, rooms, roles and bookings belong to an organization
+type Organization struct {
+	OrganizationId int    `json:"organization_id" gorm:"primarykey"`
+	Name           string `json:"name"`
+
+	Active    bool      `json:"active"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// RoomShare makes room of one organization available for booking to another organization
+type RoomShare struct {
+	RoomId         int `json:"room_id"`
+	OrganizationId int `json:"organization_id"` // organization room is shared with
+	QuotaMinutes   int `json:"quota_minutes"`   // minutes per calendar month (in room's time zone) organization can book. 0 - unlimited
+
+	Active    bool      `json:"active"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
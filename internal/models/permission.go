@@ -6,9 +6,11 @@ type Permission struct {
 	RoleId  int `json:"role_id"`
 	RouteId int `json:"route_id"`
 	ScopeId int `json:"scope_id"`
+	// LocationId limits permission to records of one location. Empty (null) - permission is valid for all locations
+	LocationId int `json:"location_id" gorm:"default:null"`
 
 	Active    bool      `json:"active"`
-	CreatedBy int       `json:"created_by"`
+	CreatedBy int       `json:"created_by" gorm:"default:null"` // empty (null) - created by migration or CLI
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	DeletedAt time.Time `json:"deleted_at"`
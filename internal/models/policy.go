@@ -0,0 +1,112 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	// PolicyEffectAllow grants the route if conditions hold - even without permission of the role (e.g. beyond OWNER scope)
+	PolicyEffectAllow = "allow"
+	// PolicyEffectDeny denies the route if conditions hold - it overrides permissions and allow policies
+	PolicyEffectDeny = "deny"
+)
+
+// Policy attribute-based rule of access to the route. Conditions are compared with attributes of subject (the caller),
+// resource (record of the request) and request - all of them should hold
+type Policy struct {
+	PolicyId       int              `json:"policy_id" gorm:"primarykey"`
+	OrganizationId int              `json:"organization_id"`
+	Name           string           `json:"name"`
+	Description    string           `json:"description"`
+	RoleId         int              `json:"role_id" gorm:"default:null"` // empty (null) - policy of every role
+	RouteId        int              `json:"route_id"`
+	URL            string           `json:"url" gorm:"-"` // route can be passed by URL instead of id
+	Effect         string           `json:"effect"`
+	Conditions     PolicyConditions `json:"conditions" gorm:"type:jsonb"`
+
+	Active    bool      `json:"active"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// PolicyCondition compares attribute (e.g. `resource.capacity`) with value or with another attribute (e.g.
+// `subject.floor_id`). Operators: eq, ne, lt, lte, gt, gte, in, not_in
+type PolicyCondition struct {
+	Attribute      string `json:"attribute"`
+	Operator       string `json:"operator"`
+	Value          any    `json:"value,omitempty"`
+	ValueAttribute string `json:"value_attribute,omitempty"`
+}
+
+// PolicyConditions are stored as JSON array
+type PolicyConditions []PolicyCondition
+
+func (c PolicyConditions) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(c)
+
+	return string(data), err
+}
+
+func (c *PolicyConditions) Scan(value any) error {
+	switch data := value.(type) {
+	case []byte:
+		return json.Unmarshal(data, c)
+	case string:
+		return json.Unmarshal([]byte(data), c)
+	case nil:
+		*c = nil
+		return nil
+	default:
+		return errors.New("policy conditions should be JSON array")
+	}
+}
+
+// AccessExplanation how access to the route is decided: permissions of the role, then policies
+type AccessExplanation struct {
+	Allowed     bool                    `json:"allowed"`
+	Reason      string                  `json:"reason"`
+	RouteId     int                     `json:"route_id"`
+	RoleId      int                     `json:"role_id"`
+	Permissions []PermissionExplanation `json:"permissions"`
+	Policies    []PolicyExplanation     `json:"policies"`
+	Attributes  map[string]any          `json:"attributes"` // attributes conditions were compared with
+}
+
+type PermissionExplanation struct {
+	ScopeId    int  `json:"scope_id"`
+	LocationId int  `json:"location_id,omitempty"`
+	Granted    bool `json:"granted"`
+}
+
+type PolicyExplanation struct {
+	PolicyId   int                    `json:"policy_id"` // 0 - policy passed for dry run
+	Name       string                 `json:"name"`
+	Effect     string                 `json:"effect"`
+	Matched    bool                   `json:"matched"`
+	Conditions []ConditionExplanation `json:"conditions"`
+}
+
+type ConditionExplanation struct {
+	PolicyCondition
+	Actual  any  `json:"actual"` // value of the attribute. null - the request has no such attribute
+	Matched bool `json:"matched"`
+}
+
+// AccessExplanationRequest body of dry run of access check
+type AccessExplanationRequest struct {
+	UserId     int            `json:"user_id"`
+	RoleId     int            `json:"role_id"` // empty - role of the user
+	URL        string         `json:"url"`
+	RecordType string         `json:"record_type"` // empty - the first segment of URL, as for real requests
+	RecordId   string         `json:"record_id"`
+	Request    map[string]any `json:"request"`  // attributes of the request without `request.` prefix, e.g. {"room_id": 12}
+	Policies   []Policy       `json:"policies"` // unsaved policies of the route evaluated together with saved ones
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+const (
+	ReportPeriodDay   = "day"
+	ReportPeriodWeek  = "week"
+	ReportPeriodMonth = "month"
+)
+
+// ReportFilter bookings taken into account by reports. Zero RoomId/RoleId - not filtered
+type ReportFilter struct {
+	DateTimeStart time.Time `json:"datetime_start"`
+	DateTimeEnd   time.Time `json:"datetime_end"`
+	RoomId        int       `json:"room_id"`
+	RoleId        int       `json:"role_id"` // role of the booker
+	Period        string    `json:"period"`  // ReportPeriodDay, ReportPeriodWeek or ReportPeriodMonth
+	Limit         int       `json:"limit"`
+}
+
+// RoomUtilization booked time of the room within opening hours of its location for one period.
+// Period starts at local midnight of the location (weeks start on Monday)
+type RoomUtilization struct {
+	RoomId         int     `json:"room_id"`
+	RoomNumber     string  `json:"room_number"`
+	PeriodStart    string  `json:"period_start"` // `YYYY-MM-DD`
+	OpenMinutes    int     `json:"open_minutes"`
+	BookedMinutes  int     `json:"booked_minutes"`
+	UtilizationPct float64 `json:"utilization_pct" gorm:"-"`
+}
+
+// PeakHour number of bookings occupying a room during the hour of the week. Local time of room's location
+type PeakHour struct {
+	Weekday  int `json:"weekday"` // 0 - Sunday
+	Hour     int `json:"hour"`
+	Bookings int `json:"bookings"`
+}
+
+// RoomBookingStats bookings starting within the report range
+type RoomBookingStats struct {
+	RoomId                 int     `json:"room_id"`
+	RoomNumber             string  `json:"room_number"`
+	TotalBookings          int     `json:"total_bookings"`
+	CancelledBookings      int     `json:"cancelled_bookings"`
+	NoShowBookings         int     `json:"no_show_bookings"` // finished bookings nobody checked in
+	AverageDurationMinutes float64 `json:"average_duration_minutes"`
+	CancellationRatePct    float64 `json:"cancellation_rate_pct" gorm:"-"`
+	NoShowRatePct          float64 `json:"no_show_rate_pct" gorm:"-"`
+}
+
+type TopBooker struct {
+	UserId        int    `json:"user_id"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	Bookings      int    `json:"bookings"`
+	BookedMinutes int    `json:"booked_minutes"`
+}
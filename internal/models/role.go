@@ -3,9 +3,10 @@ package models
 import "time"
 
 type Role struct {
-	RoleId      int    `json:"role_id" gorm:"primarykey"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	RoleId         int    `json:"role_id" gorm:"primarykey"`
+	OrganizationId int    `json:"organization_id" gorm:"default:null"` // empty (null) - system role available in all organizations
+	Name           string `json:"name"`
+	Description    string `json:"description"`
 
 	Active    bool      `json:"active"`
 	CreatedBy int       `json:"created_by"`
@@ -5,9 +5,11 @@ import (
 )
 
 type Room struct {
-	RoomId   int    `json:"room_id" gorm:"primarykey"`
-	Number   string `json:"number"`
-	Capacity int    `json:"capacity"`
+	RoomId         int    `json:"room_id" gorm:"primarykey"`
+	OrganizationId int    `json:"organization_id"` // owner of the room
+	Number         string `json:"number"`
+	Capacity       int    `json:"capacity"`
+	FloorId        int    `json:"floor_id"`
 
 	Active    bool      `json:"active"`
 	CreatedBy int       `json:"created_by"`
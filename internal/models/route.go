@@ -6,6 +6,8 @@ type Route struct {
 	RouteId     int    `json:"route_id" gorm:"primarykey"`
 	URL         string `json:"url"`
 	Description string `json:"description"`
+	// MFARequired route is available only with tokens issued after two-factor authentication
+	MFARequired bool `json:"mfa_required"`
 
 	Active    bool      `json:"active"`
 	CreatedBy int       `json:"created_by"`
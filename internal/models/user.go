@@ -5,15 +5,35 @@ import (
 )
 
 type User struct {
-	UserId    int    `json:"user_id" gorm:"primarykey"`
-	Name      string `json:"name"`
-	Email     string `json:"email"`
-	Telephone string `json:"telephone"`
-	RoleId    int    `json:"role_id"`
+	UserId         int    `json:"user_id" gorm:"primarykey"`
+	OrganizationId int    `json:"organization_id"`
+	Name           string `json:"name"`
+	Email          string `json:"email"`
+	Telephone      string `json:"telephone"`
+	RoleId         int    `json:"role_id"`
+	TimeZone       string `json:"time_zone"` // IANA time zone name. Empty - time zone of the room is used
+	// FloorId floor the user works at - attribute `subject.floor_id` of access policies. Empty (null) - not set
+	FloorId int `json:"floor_id" gorm:"default:null"`
 
 	UserName string `json:"username" gorm:"column:username"`
 	Password string `json:"-" gorm:"column:password_hash"`
 
+	// TOTPSecret base32 secret of authenticator app. TOTPEnabled is set after the first valid code
+	TOTPSecret   string `json:"-" gorm:"column:totp_secret"`
+	TOTPEnabled  bool   `json:"totp_enabled" gorm:"column:totp_enabled"`
+	TOTPLastStep int64  `json:"-" gorm:"column:totp_last_step"`
+
+	// SSOIssuer and SSOSubject user of identity provider the user logs in by (single sign-on). Empty - local user
+	SSOIssuer  string `json:"sso_issuer,omitempty" gorm:"column:sso_issuer"`
+	SSOSubject string `json:"sso_subject,omitempty" gorm:"column:sso_subject"`
+
+	// AuthProvider checks password of the user: local (password hash) or ldap. DirectoryId stable id of the directory entry
+	AuthProvider string `json:"auth_provider" gorm:"column:auth_provider"`
+	DirectoryId  string `json:"directory_id,omitempty" gorm:"column:directory_id"`
+
+	// EmailVerified the user has opened link sent to the email. Users created by administrators, import and identity providers are trusted
+	EmailVerified bool `json:"email_verified" gorm:"column:email_verified"`
+
 	Active    bool      `json:"active"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+)
+
+// UserToken single-use secret sent to the user by email. Only hash is stored
+type UserToken struct {
+	UserTokenId int        `json:"user_token_id" gorm:"primarykey"`
+	UserId      int        `json:"user_id"`
+	Purpose     string     `json:"purpose"`
+	TokenHash   string     `json:"-"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	UsedAt      *time.Time `json:"used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+const (
+	ProviderLog  = "log"
+	ProviderSMTP = "smtp"
+)
+
+// Config of delivery of messages to users (password reset, email verification)
+type Config struct {
+	// Provider log - messages are written to log (development), smtp - sent by email
+	Provider string
+	SMTP     SMTPConfig
+}
+
+// Message notification of one user
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to users. Error - message is not delivered
+type Notifier interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// NewNotifier returns notifier of configured provider
+func NewNotifier(config Config) (Notifier, error) {
+	switch config.Provider {
+	case ProviderLog:
+		return &LogNotifier{}, nil
+	case ProviderSMTP:
+		return NewSMTPNotifier(config.SMTP), nil
+	}
+
+	return nil, fmt.Errorf("unknown notify provider %q", config.Provider)
+}
+
+// LogNotifier writes messages to log instead of delivery. Messages contain secrets (links with tokens) - only for development
+type LogNotifier struct{}
+
+func (l *LogNotifier) Send(ctx context.Context, message Message) error {
+	slog.InfoContext(ctx, "LogNotifier.Send(): message is not delivered - notify provider is `log`", "to", message.To, "subject", message.Subject, "message", message.Body)
+
+	return nil
+}
+
+// MemoryNotifier keeps messages in memory - tests read them instead of mailbox
+type MemoryNotifier struct {
+	mutex    sync.Mutex
+	messages []Message
+}
+
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+func (m *MemoryNotifier) Send(_ context.Context, message Message) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// Messages returns sent messages in order of sending
+func (m *MemoryNotifier) Messages() []Message {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]Message(nil), m.messages...)
+}
+
+// LastMessage returns the last message sent to the address. `false` - there is none
+func (m *MemoryNotifier) LastMessage(to string) (Message, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if strings.EqualFold(m.messages[i].To, to) {
+			return m.messages[i], true
+		}
+	}
+
+	return Message{}, false
+}
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig mail server. Password - secret, set by environment variable
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From address of sender, e.g. "Booking <no-reply@example.com>"
+	From string
+}
+
+// SMTPNotifier sends messages by email. STARTTLS is used if the server supports it, credentials are sent only over TLS
+type SMTPNotifier struct {
+	config SMTPConfig
+}
+
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{config: config}
+}
+
+func (s *SMTPNotifier) Send(ctx context.Context, message Message) error {
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	address := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	if err := smtp.SendMail(address, auth, envelopeAddress(s.config.From), []string{message.To}, s.compose(message)); err != nil {
+		slog.ErrorContext(ctx, "SMTPNotifier.Send(): error occured during sending of email", "to", message.To, "subject", message.Subject, "error", err)
+		return fmt.Errorf("email is not sent: %w", err)
+	}
+
+	return nil
+}
+
+// compose plain text email (RFC 5322). Header values are stripped of line breaks - they cannot inject headers
+func (s *SMTPNotifier) compose(message Message) []byte {
+	headerValue := strings.NewReplacer("\r", "", "\n", "").Replace
+
+	var builder strings.Builder
+	builder.WriteString("From: " + headerValue(s.config.From) + "\r\n")
+	builder.WriteString("To: " + headerValue(message.To) + "\r\n")
+	builder.WriteString("Subject: " + headerValue(message.Subject) + "\r\n")
+	builder.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	builder.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	return []byte(builder.String())
+}
+
+// envelopeAddress address of "Name <address>" form
+func envelopeAddress(from string) string {
+	if start, end := strings.LastIndex(from, "<"), strings.LastIndex(from, ">"); start >= 0 && end > start {
+		return from[start+1 : end]
+	}
+
+	return from
+}
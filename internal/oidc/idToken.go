@@ -0,0 +1,274 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RS256 signing algorithm of ID tokens every OIDC provider supports
+const RS256 = "RS256"
+
+// clockSkew clocks of the provider and of the application may differ
+const clockSkew = time.Minute
+
+// Claims of verified ID token
+type Claims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        Audience `json:"aud"`
+	AuthorizedParty string   `json:"azp,omitempty"`
+	ExpirationTime  int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	Nonce           string   `json:"nonce,omitempty"`
+	// AuthMethods methods used at the provider (`mfa` - the user has passed second factor there)
+	AuthMethods []string `json:"amr,omitempty"`
+
+	Email             string `json:"email,omitempty"`
+	EmailVerified     bool   `json:"email_verified,omitempty"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	// Groups value of Config.GroupsClaim
+	Groups []string `json:"-"`
+}
+
+// Audience `aud` claim is either a string or an array of strings
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+type joseHeader struct {
+	Algorithm string `json:"alg"`
+	KeyId     string `json:"kid"`
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyId     string `json:"kid"`
+	Use       string `json:"use"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+	Algorithm string `json:"alg"`
+}
+
+// VerifyIDToken checks signature of ID token by keys of the provider and its claims: issuer, audience, expiration and nonce
+// of the log in it was issued for (ref: https://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation)
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (Claims, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return Claims{}, errors.New("ID token is malformed")
+	}
+
+	headerJSON, headerError := base64.RawURLEncoding.DecodeString(parts[0])
+	payload, payloadError := base64.RawURLEncoding.DecodeString(parts[1])
+	signature, signatureError := base64.RawURLEncoding.DecodeString(parts[2])
+	if err := errors.Join(headerError, payloadError, signatureError); err != nil {
+		return Claims{}, fmt.Errorf("ID token is malformed: %w", err)
+	}
+
+	var header joseHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return Claims{}, fmt.Errorf("header of ID token is malformed: %w", err)
+	}
+	// algorithm is fixed - `none` and HMAC with public key as secret are rejected
+	if header.Algorithm != RS256 {
+		slog.WarnContext(ctx, "Provider.VerifyIDToken(): signing algorithm of ID token is not supported", "passed_data", header.Algorithm)
+		return Claims{}, fmt.Errorf("signing algorithm %q of ID token is not supported", header.Algorithm)
+	}
+
+	key, err := p.signingKey(ctx, header.KeyId)
+	if err != nil {
+		return Claims{}, err
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature); err != nil {
+		slog.WarnContext(ctx, "Provider.VerifyIDToken(): signature of ID token is not valid", "kid", header.KeyId)
+		return Claims{}, errors.New("signature of ID token is not valid")
+	}
+
+	claims, err := p.parseClaims(payload)
+	if err != nil {
+		return Claims{}, err
+	}
+	if err := p.validateClaims(claims, nonce, time.Now()); err != nil {
+		slog.WarnContext(ctx, "Provider.VerifyIDToken(): claims of ID token are not valid", "subject", claims.Subject, "error", err)
+		return Claims{}, err
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) parseClaims(payload []byte) (Claims, error) {
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return Claims{}, fmt.Errorf("claims of ID token are malformed: %w", err)
+	}
+
+	if p.config.GroupsClaim == "" {
+		return claims, nil
+	}
+	var allClaims map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &allClaims); err != nil {
+		return Claims{}, fmt.Errorf("claims of ID token are malformed: %w", err)
+	}
+	if groups, ok := allClaims[p.config.GroupsClaim]; ok {
+		// some providers send the only group as a string
+		var group string
+		if err := json.Unmarshal(groups, &claims.Groups); err != nil {
+			if json.Unmarshal(groups, &group) != nil {
+				return Claims{}, fmt.Errorf("claim %q of ID token should be array of strings", p.config.GroupsClaim)
+			}
+			claims.Groups = []string{group}
+		}
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) validateClaims(claims Claims, nonce string, now time.Time) error {
+	if claims.Issuer != p.config.Issuer {
+		return fmt.Errorf("ID token is issued by another issuer: %q", claims.Issuer)
+	}
+	if claims.Subject == "" {
+		return errors.New("ID token has no subject")
+	}
+	if !slices.Contains(claims.Audience, p.config.ClientId) {
+		return errors.New("ID token is issued for another client")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientId {
+		return errors.New("ID token is issued for another authorized party")
+	}
+	if now.Add(-clockSkew).Unix() >= claims.ExpirationTime {
+		return errors.New("ID token is expired")
+	}
+	if claims.IssuedAt > now.Add(clockSkew).Unix() {
+		return errors.New("ID token is issued in the future")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return errors.New("nonce of ID token does not match the log in")
+	}
+
+	return nil
+}
+
+// signingKey returns key of the provider by `kid`. Keys are fetched again if `kid` is unknown - the provider may have rotated them
+func (p *Provider) signingKey(ctx context.Context, keyId string) (*rsa.PublicKey, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if key, ok := p.findKey(keyId); ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < keysRefreshInterval {
+		slog.WarnContext(ctx, "Provider.signingKey(): signing key of ID token is unknown", "kid", keyId)
+		return nil, errors.New("signing key of ID token is unknown")
+	}
+
+	keys, err := p.fetchKeys(ctx, metadata.JWKSURI)
+	if err != nil {
+		slog.ErrorContext(ctx, "Provider.signingKey(): error occured during fetch of signing keys", "error", err)
+		return nil, fmt.Errorf("signing keys of identity provider are not available: %w", err)
+	}
+	p.keys, p.keysFetchedAt = keys, time.Now()
+
+	if key, ok := p.findKey(keyId); ok {
+		return key, nil
+	}
+	slog.WarnContext(ctx, "Provider.signingKey(): signing key of ID token is unknown", "kid", keyId)
+	return nil, errors.New("signing key of ID token is unknown")
+}
+
+// findKey token without `kid` can be verified only if the provider has one key
+func (p *Provider) findKey(keyId string) (*rsa.PublicKey, bool) {
+	if keyId == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[keyId]
+	return key, ok
+}
+
+func (p *Provider) fetchKeys(ctx context.Context, jwksURI string) (map[string]*rsa.PublicKey, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	status, err := p.doJSON(request, &keySet)
+	if err == nil && status != http.StatusOK {
+		err = fmt.Errorf("unexpected status %d", status)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		// encryption keys and keys of other types are skipped
+		if jwk.KeyType != "RSA" || (jwk.Use != "" && jwk.Use != "sig") || (jwk.Algorithm != "" && jwk.Algorithm != RS256) {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			slog.WarnContext(ctx, "Provider.fetchKeys(): key of identity provider is malformed", "kid", jwk.KeyId, "error", err)
+			continue
+		}
+		keys[jwk.KeyId] = key
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(k.Modulus)
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(k.Exponent)
+	if err != nil {
+		return nil, err
+	}
+
+	e := new(big.Int).SetBytes(exponent)
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("exponent of RSA key is not valid")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(e.Int64())}, nil
+}
@@ -0,0 +1,248 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// MockUser user who is logged in at MockProvider - login page is skipped
+type MockUser struct {
+	Subject           string
+	Email             string
+	Name              string
+	PreferredUsername string
+	Groups            []string
+	AuthMethods       []string
+}
+
+// MockProvider local OIDC provider for tests and development: discovery, authorization endpoint which logs in MockUser
+// without login page, token endpoint checking PKCE and JWKS. Not for production use
+type MockProvider struct {
+	// Issuer URL the provider is served at - should be set before the first request
+	Issuer   string
+	ClientId string
+	// ClientSecret empty - public client
+	ClientSecret string
+	User         MockUser
+	// GroupsClaim claim of ID token with groups of the user
+	GroupsClaim string
+	TokenTTL    time.Duration
+
+	key   *rsa.PrivateKey
+	mutex sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+const mockKeyId = "mock"
+
+type mockAuthorization struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	user          MockUser
+}
+
+func NewMockProvider(clientId string, user MockUser) (*MockProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MockProvider{
+		ClientId:    clientId,
+		User:        user,
+		GroupsClaim: "groups",
+		TokenTTL:    5 * time.Minute,
+		key:         key,
+		codes:       map[string]mockAuthorization{},
+	}, nil
+}
+
+func (m *MockProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case discoveryPath:
+		m.discovery(w)
+	case "/authorize":
+		m.authorize(w, r)
+	case "/token":
+		m.token(w, r)
+	case "/jwks":
+		m.jwks(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (m *MockProvider) discovery(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                m.Issuer,
+		"authorization_endpoint":                m.Issuer + "/authorize",
+		"token_endpoint":                        m.Issuer + "/token",
+		"jwks_uri":                              m.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{RS256},
+		"code_challenge_methods_supported":      []string{CodeChallengeMethod},
+	})
+}
+
+// authorize logs in MockUser and redirects back with code. Errors of redirect URI and client are shown, not redirected
+func (m *MockProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() || query.Get("client_id") != m.ClientId {
+		http.Error(w, "client_id or redirect_uri is not valid", http.StatusBadRequest)
+		return
+	}
+
+	callbackQuery := redirectURI.Query()
+	callbackQuery.Set("state", query.Get("state"))
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != CodeChallengeMethod || query.Get("code_challenge") == "" {
+		callbackQuery.Set("error", "invalid_request")
+		callbackQuery.Set("error_description", "response_type=code and S256 code_challenge are required")
+	} else {
+		code := randomHex(16)
+		m.mutex.Lock()
+		m.codes[code] = mockAuthorization{
+			redirectURI:   redirectURI.String(),
+			codeChallenge: query.Get("code_challenge"),
+			nonce:         query.Get("nonce"),
+			user:          m.User,
+		}
+		m.mutex.Unlock()
+		callbackQuery.Set("code", code)
+	}
+
+	redirectURI.RawQuery = callbackQuery.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (m *MockProvider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientId, clientSecret, hasBasicAuth := r.BasicAuth()
+	if hasBasicAuth {
+		clientId, _ = url.QueryUnescape(clientId)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientId, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientId != m.ClientId || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(m.ClientSecret)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	// code is used once
+	m.mutex.Lock()
+	authorization, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mutex.Unlock()
+
+	if !ok || authorization.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "code is not valid"})
+		return
+	}
+	if CodeChallenge(r.PostForm.Get("code_verifier")) != authorization.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "code_verifier does not match code_challenge"})
+		return
+	}
+
+	idToken, err := m.SignIDToken(m.idTokenClaims(authorization))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomHex(16),
+		"token_type":   "Bearer",
+		"expires_in":   int(m.TokenTTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+func (m *MockProvider) idTokenClaims(authorization mockAuthorization) map[string]any {
+	now := time.Now()
+	claims := map[string]any{
+		"iss":                m.Issuer,
+		"sub":                authorization.user.Subject,
+		"aud":                m.ClientId,
+		"exp":                now.Add(m.TokenTTL).Unix(),
+		"iat":                now.Unix(),
+		"nonce":              authorization.nonce,
+		"email":              authorization.user.Email,
+		"email_verified":     authorization.user.Email != "",
+		"name":               authorization.user.Name,
+		"preferred_username": authorization.user.PreferredUsername,
+	}
+	if len(authorization.user.AuthMethods) > 0 {
+		claims["amr"] = authorization.user.AuthMethods
+	}
+	if m.GroupsClaim != "" {
+		claims[m.GroupsClaim] = authorization.user.Groups
+	}
+
+	return claims
+}
+
+// SignIDToken signs any claims by the key of the provider - tests use it for tokens with invalid claims
+func (m *MockProvider) SignIDToken(claims map[string]any) (string, error) {
+	headerJSON, err := json.Marshal(joseHeader{Algorithm: RS256, KeyId: mockKeyId})
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	hash := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, m.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (m *MockProvider) jwks(w http.ResponseWriter) {
+	publicKey := m.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []jsonWebKey{{
+			KeyType:   "RSA",
+			KeyId:     mockKeyId,
+			Use:       "sig",
+			Algorithm: RS256,
+			Modulus:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomHex(size int) string {
+	randomBytes := make([]byte, size)
+	rand.Read(randomBytes)
+
+	return hex.EncodeToString(randomBytes)
+}
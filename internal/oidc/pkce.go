@@ -0,0 +1,31 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// CodeChallengeMethod only S256 is used - `plain` does not protect the code if the request to the provider is seen
+const CodeChallengeMethod = "S256"
+
+// codeVerifierSize 32 random bytes - 43 characters, minimum length of RFC 7636
+const codeVerifierSize = 32
+
+// GenerateCodeVerifier random secret of the log in (PKCE, RFC 7636). It is kept by the client and sent with the code, so
+// intercepted code cannot be exchanged by anyone else
+func GenerateCodeVerifier() (string, error) {
+	verifier := make([]byte, codeVerifierSize)
+	if _, err := rand.Read(verifier); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(verifier), nil
+}
+
+// CodeChallenge S256 challenge of the verifier - sent to authorization endpoint
+func CodeChallenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
@@ -0,0 +1,206 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config of OpenID Connect identity provider used for single sign-on (authorization code flow with PKCE)
+type Config struct {
+	Enabled bool
+	// Issuer URL of the provider - endpoints are read from <Issuer>/.well-known/openid-configuration
+	Issuer   string
+	ClientId string
+	// ClientSecret secret - set by environment variable. Empty - public client, PKCE only
+	ClientSecret string
+	// RedirectURL URL of `/auth/oidc/callback` registered at the provider
+	RedirectURL string
+	Scopes      []string
+
+	// GroupsClaim claim of ID token with groups of the user
+	GroupsClaim string
+	// GroupRoles role of the user is taken from the first mapping whose group the user is member of
+	GroupRoles []GroupRole
+	// DefaultRoleId role of users without mapped groups. 0 - they cannot log in
+	DefaultRoleId int
+	// OrganizationId organization users are provisioned into
+	OrganizationId int
+	// LinkByEmail existing user with the same verified email is linked at the first log in instead of creation of a new one
+	LinkByEmail bool
+	// FlowTTL time given to log in at the provider
+	FlowTTL time.Duration
+}
+
+type GroupRole struct {
+	Group  string
+	RoleId int
+}
+
+// Metadata endpoints of the provider from discovery document
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+
+	// keysRefreshInterval keys are fetched again for unknown `kid` (rotation at the provider), but not more often than this
+	keysRefreshInterval = time.Minute
+	// maxResponseSize responses of the provider are small - bigger ones are not read
+	maxResponseSize = 1 << 20
+)
+
+// Provider client of the identity provider. Metadata and signing keys are fetched at first use and cached,
+// so the application starts even if the provider is unavailable
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mutex         sync.Mutex
+	metadata      *Metadata
+	keys          map[string]*rsa.PublicKey
+	keysFetchedAt time.Time
+}
+
+func NewProvider(config Config, client *http.Client) *Provider {
+	return &Provider{config: config, client: client}
+}
+
+func (p *Provider) Config() Config {
+	return p.config
+}
+
+// AuthCodeURL URL of the provider's login page. Provider redirects back to RedirectURL with `code` and `state`
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientId)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", CodeChallengeMethod)
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange exchanges code for tokens at token endpoint. Returns ID token - it is verified by VerifyIDToken
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.config.ClientId)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		// client_secret_basic - default authentication method of OIDC clients
+		request.SetBasicAuth(url.QueryEscape(p.config.ClientId), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.doJSON(request, &tokens)
+	if err != nil {
+		slog.ErrorContext(ctx, "Provider.Exchange(): error occured during code exchange", "error", err)
+		return "", err
+	}
+	if status != http.StatusOK {
+		slog.WarnContext(ctx, "Provider.Exchange(): code is rejected by the provider", "status", status, "error", tokens.Error, "details", tokens.ErrorDescription)
+		return "", fmt.Errorf("code is rejected by identity provider: %s %s", tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return "", errors.New("identity provider has not returned ID token - `openid` scope is required")
+	}
+
+	return tokens.IDToken, nil
+}
+
+// discover reads metadata of the provider. Failed discovery is not cached - it is repeated by the next log in
+func (p *Provider) discover(ctx context.Context) (Metadata, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.metadata != nil {
+		return *p.metadata, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.config.Issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	var metadata Metadata
+	status, err := p.doJSON(request, &metadata)
+	if err == nil && status != http.StatusOK {
+		err = fmt.Errorf("unexpected status %d", status)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Provider.discover(): error occured during discovery of identity provider", "issuer", p.config.Issuer, "error", err)
+		return Metadata{}, fmt.Errorf("identity provider is not available: %w", err)
+	}
+
+	// ref: https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderConfigurationValidation
+	if metadata.Issuer != p.config.Issuer {
+		slog.ErrorContext(ctx, "Provider.discover(): issuer of discovery document does not match configured one", "issuer", p.config.Issuer, "passed_data", metadata.Issuer)
+		return Metadata{}, errors.New("issuer of identity provider does not match configured one")
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return Metadata{}, errors.New("discovery document of identity provider is not complete")
+	}
+
+	p.metadata = &metadata
+	return metadata, nil
+}
+
+// doJSON sends request and decodes JSON response of any status
+func (p *Provider) doJSON(request *http.Request, target any) (int, error) {
+	response, err := p.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if err := json.NewDecoder(io.LimitReader(response.Body, maxResponseSize)).Decode(target); err != nil {
+		return response.StatusCode, fmt.Errorf("cannot decode response of identity provider (status %d): %w", response.StatusCode, err)
+	}
+
+	return response.StatusCode, nil
+}
@@ -0,0 +1,166 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Config limits of authentication endpoints
+type Config struct {
+	Enabled bool
+	// IP login attempts of one IP address
+	IP Limit
+	// Username login attempts to one account from any IP address
+	Username Limit
+	// Registration registrations from one IP address
+	Registration Limit
+	// PasswordReset requests of links sent by email (password reset, email verification) from one IP address and to one email
+	PasswordReset Limit
+
+	// MaxFailures failed logins in a row before the account is locked
+	MaxFailures int
+	// FailureWindow failures are forgotten after this time without new ones
+	FailureWindow   time.Duration
+	LockoutDuration time.Duration
+	// FailureDelay response to failed login is delayed - doubled for every next failure up to MaxFailureDelay
+	FailureDelay    time.Duration
+	MaxFailureDelay time.Duration
+}
+
+// LimitError request is rejected - it can be repeated after RetryAfter
+type LimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("too many requests, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// Limiter protects login and registration from brute-force. Errors of the store are logged and requests are allowed -
+// unavailable store should not block all logins
+type Limiter struct {
+	store  Store
+	config Config
+}
+
+func NewLimiter(store Store, config Config) *Limiter {
+	return &Limiter{store: store, config: config}
+}
+
+// AllowLogin checks limits of the IP address and of the username and lock of the account. Unknown usernames are
+// limited the same way as existing ones, so responses do not reveal which accounts exist
+func (l *Limiter) AllowLogin(ctx context.Context, ip string, username string) error {
+	if !l.config.Enabled {
+		return nil
+	}
+
+	if err := l.take(ctx, "login:ip:"+ip, l.config.IP); err != nil {
+		return err
+	}
+
+	userKey := usernameKey(username)
+	lockedUntil, err := l.store.LockedUntil(ctx, userKey)
+	if err != nil {
+		slog.ErrorContext(ctx, "Limiter.AllowLogin(): error occured during lock check", "passed_data", username, "error", err)
+	} else if !lockedUntil.IsZero() {
+		slog.WarnContext(ctx, "Limiter.AllowLogin(): account is locked", "passed_data", username, "locked_until", lockedUntil)
+		return &LimitError{RetryAfter: time.Until(lockedUntil)}
+	}
+
+	return l.take(ctx, "login:"+userKey, l.config.Username)
+}
+
+// AllowRegistration checks limit of registrations of the IP address
+func (l *Limiter) AllowRegistration(ctx context.Context, ip string) error {
+	if !l.config.Enabled {
+		return nil
+	}
+
+	return l.take(ctx, "register:ip:"+ip, l.config.Registration)
+}
+
+// AllowPasswordReset checks limits of requests of links sent by email - protects mailboxes from flooding
+func (l *Limiter) AllowPasswordReset(ctx context.Context, ip string, email string) error {
+	if !l.config.Enabled {
+		return nil
+	}
+
+	if err := l.take(ctx, "reset:ip:"+ip, l.config.PasswordReset); err != nil {
+		return err
+	}
+
+	return l.take(ctx, "reset:email:"+strings.ToLower(strings.TrimSpace(email)), l.config.PasswordReset)
+}
+
+// LoginFailed counts failed login and locks the account after MaxFailures in a row. Returns delay of the response
+func (l *Limiter) LoginFailed(ctx context.Context, username string) (time.Duration, bool) {
+	if !l.config.Enabled {
+		return 0, false
+	}
+
+	userKey := usernameKey(username)
+	count, err := l.store.AddFailure(ctx, userKey, l.config.FailureWindow)
+	if err != nil {
+		slog.ErrorContext(ctx, "Limiter.LoginFailed(): error occured during failure count", "passed_data", username, "error", err)
+		return l.config.FailureDelay, false
+	}
+
+	isLocked := l.config.MaxFailures > 0 && count >= l.config.MaxFailures
+	if isLocked {
+		if err := l.store.Lock(ctx, userKey, time.Now().Add(l.config.LockoutDuration)); err != nil {
+			slog.ErrorContext(ctx, "Limiter.LoginFailed(): error occured during account lock", "passed_data", username, "error", err)
+			isLocked = false
+		} else {
+			slog.WarnContext(ctx, "Limiter.LoginFailed(): account is locked after failed logins", "passed_data", username, "failures", count, "duration", l.config.LockoutDuration)
+		}
+	}
+
+	return l.failureDelay(count), isLocked
+}
+
+// LoginSucceeded forgets failed logins of the account
+func (l *Limiter) LoginSucceeded(ctx context.Context, username string) {
+	if !l.config.Enabled {
+		return
+	}
+
+	if err := l.store.Reset(ctx, usernameKey(username)); err != nil {
+		slog.ErrorContext(ctx, "Limiter.LoginSucceeded(): error occured during reset of failures", "passed_data", username, "error", err)
+	}
+}
+
+// failureDelay FailureDelay * 2^(failures - 1), not longer than MaxFailureDelay
+func (l *Limiter) failureDelay(failures int) time.Duration {
+	delay := l.config.FailureDelay
+	for i := 1; i < failures && delay < l.config.MaxFailureDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, l.config.MaxFailureDelay)
+}
+
+func (l *Limiter) take(ctx context.Context, key string, limit Limit) error {
+	if limit.Burst <= 0 {
+		return nil
+	}
+
+	allowed, retryAfter, err := l.store.Take(ctx, key, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "Limiter.take(): error occured during rate limit check", "key", key, "error", err)
+		return nil
+	}
+	if !allowed {
+		slog.WarnContext(ctx, "Limiter.take(): rate limit is exceeded", "key", key, "retry_after", retryAfter)
+		return &LimitError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// usernameKey usernames differing by case are limited together
+func usernameKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval expired buckets, counters and locks are removed not more often than this
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	// fullAt bucket is full again and can be forgotten
+	fullAt time.Time
+}
+
+type failures struct {
+	count     int
+	expiresAt time.Time
+}
+
+// MemoryStore in-memory Store of one instance
+type MemoryStore struct {
+	mutex    sync.Mutex
+	buckets  map[string]*bucket
+	failures map[string]*failures
+	locks    map[string]time.Time
+	sweptAt  time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:  make(map[string]*bucket),
+		failures: make(map[string]*failures),
+		locks:    make(map[string]time.Time),
+	}
+}
+
+func (m *MemoryStore) Take(_ context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		m.buckets[key] = b
+	}
+
+	// refill tokens for the time passed since the last request
+	b.tokens += float64(now.Sub(b.updatedAt)) / float64(limit.Interval)
+	if b.tokens > float64(limit.Burst) {
+		b.tokens = float64(limit.Burst)
+	}
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		retryAfter := time.Duration((1 - b.tokens) * float64(limit.Interval))
+		return false, retryAfter, nil
+	}
+
+	b.tokens--
+	b.fullAt = now.Add(time.Duration((float64(limit.Burst) - b.tokens) * float64(limit.Interval)))
+	return true, 0, nil
+}
+
+func (m *MemoryStore) AddFailure(_ context.Context, key string, window time.Duration) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	f, ok := m.failures[key]
+	if !ok || !now.Before(f.expiresAt) {
+		f = &failures{}
+		m.failures[key] = f
+	}
+	f.count++
+	f.expiresAt = now.Add(window)
+
+	return f.count, nil
+}
+
+func (m *MemoryStore) Lock(_ context.Context, key string, until time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.locks[key] = until
+	return nil
+}
+
+func (m *MemoryStore) LockedUntil(_ context.Context, key string) (time.Time, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	until, ok := m.locks[key]
+	if !ok || !time.Now().Before(until) {
+		return time.Time{}, nil
+	}
+
+	return until, nil
+}
+
+func (m *MemoryStore) Reset(_ context.Context, key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.failures, key)
+	delete(m.locks, key)
+	return nil
+}
+
+// sweep removes full buckets, expired counters and locks, so memory does not grow with number of clients.
+// Should be called with the mutex locked
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.sweptAt) < sweepInterval {
+		return
+	}
+	m.sweptAt = now
+
+	for key, b := range m.buckets {
+		if !now.Before(b.fullAt) {
+			delete(m.buckets, key)
+		}
+	}
+	for key, f := range m.failures {
+		if !now.Before(f.expiresAt) {
+			delete(m.failures, key)
+		}
+	}
+	for key, until := range m.locks {
+		if !now.Before(until) {
+			delete(m.locks, key)
+		}
+	}
+}
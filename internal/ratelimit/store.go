@@ -0,0 +1,28 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit token bucket: `Burst` requests at once, then one request per `Interval`
+type Limit struct {
+	Burst    int
+	Interval time.Duration
+}
+
+// Store keeps buckets, failure counters and locks. MemoryStore works within one instance - instances behind a load
+// balancer need a shared implementation (e.g. Redis) to limit requests together
+type Store interface {
+	// Take removes a token from the bucket of the key. Empty bucket - false and time until the next token
+	Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error)
+	// AddFailure counts failure of the key and returns number of failures in a row. Counter is forgotten after `window`
+	// without new failures
+	AddFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	// Lock blocks the key until the time
+	Lock(ctx context.Context, key string, until time.Time) error
+	// LockedUntil returns zero time if the key is not locked
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	// Reset forgets failures and lock of the key
+	Reset(ctx context.Context, key string) error
+}
@@ -1,21 +1,96 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 )
 
+// Config `server` section of config.yaml
+type Config struct {
+	// Address host:port to listen on. Use ":8080" to listen on all interfaces
+	Address      string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// ShutdownTimeout time given to in-flight requests and background workers to finish after SIGTERM
+	ShutdownTimeout time.Duration
+	// TrustedProxies IP addresses and networks (CIDR) of reverse proxies in front of the server. Client IP address is
+	// taken from `Forwarded` / `X-Forwarded-For` headers only of requests coming from them
+	TrustedProxies []string
+}
+
 type Server struct {
 	server *http.Server
+	config Config
+
+	// background workers are stopped by cancellation of workersContext during shutdown
+	workersContext context.Context
+	stopWorkers    context.CancelFunc
+	workers        sync.WaitGroup
 }
 
-func (s *Server) ServerRun(handler http.Handler, port string) error {
+func NewServer(config Config) *Server {
+	workersContext, stopWorkers := context.WithCancel(context.Background())
+
+	return &Server{config: config, workersContext: workersContext, stopWorkers: stopWorkers}
+}
+
+// ServerRun blocks until the server is shut down. Returns nil after graceful shutdown
+func (s *Server) ServerRun(handler http.Handler) error {
 	s.server = &http.Server{
-		Addr:         "localhost:" + port,
+		Addr:         s.config.Address,
 		Handler:      handler,
-		ReadTimeout:  time.Second * 10, // 10 секунд максимум на чтение
-		WriteTimeout: time.Second * 10, // 10 секунда на отправку запроса
+		ReadTimeout:  s.config.ReadTimeout,
+		WriteTimeout: s.config.WriteTimeout,
+		IdleTimeout:  s.config.IdleTimeout,
+	}
+
+	slog.Info("Server.ServerRun(): server is listening", "address", s.config.Address)
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// RunWorker starts background job. Worker should return when context is cancelled - shutdown waits for it
+func (s *Server) RunWorker(name string, worker func(ctx context.Context)) {
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		slog.Info("Server.RunWorker(): worker started", "worker", name)
+		worker(s.workersContext)
+		slog.Info("Server.RunWorker(): worker stopped", "worker", name)
+	}()
+}
+
+// Shutdown stops accepting new connections, waits for in-flight requests and background workers.
+// Gives up after ShutdownTimeout
+func (s *Server) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+
+	var shutdownError error
+	if s.server != nil {
+		shutdownError = s.server.Shutdown(ctx)
+	}
+
+	s.stopWorkers()
+	workersStopped := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(workersStopped)
+	}()
+
+	select {
+	case <-workersStopped:
+	case <-ctx.Done():
+		return errors.Join(shutdownError, errors.New("background workers did not stop in time"))
 	}
 
-	return s.server.ListenAndServe()
+	return shutdownError
 }
@@ -1,13 +1,17 @@
 package services
 
 import (
+	"context"
 	"crypto/sha256"
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"go-booking-system/internal/database"
+	"go-booking-system/internal/directory"
 	"go-booking-system/internal/models"
+	"go-booking-system/internal/oidc"
 	"go-booking-system/pkg"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
 	"time"
@@ -21,22 +25,63 @@ type AuthService struct {
 	permissionService PermissionServiceInterface
 
 	// to perform IsOwner check
-	bookingService BookingServiceInterface
-	roomService    RoomServiceInterface
+	bookingService  BookingServiceInterface
+	roomService     RoomServiceInterface
+	attendeeService AttendeeServiceInterface
+
+	// to perform location-scoped permission check
+	locationService LocationServiceInterface
+	buildingService BuildingServiceInterface
+	floorService    FloorServiceInterface
+
+	// policyRepository attribute-based rules applied after permissions
+	policyRepository database.PolicyRepository
+
+	// providers check password of users kept outside of the application, by AuthProvider of the user
+	providers map[string]AuthenticationProvider
+	// tokenBinding checks that tokens are sent by the client they are issued to
+	tokenBinding TokenBinding
+
+	config AuthConfig
+}
+
+// AuthConfig keys of JWT tokens and salt of password hashes are secrets - they are set by environment variables, not by config file
+type AuthConfig struct {
+	AccessTokenKey  string
+	RefreshTokenKey string
+	// PasswordSalt changing it makes stored password hashes invalid
+	PasswordSalt    string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// MFAChallengeTTL time to enter TOTP code after password
+	MFAChallengeTTL time.Duration
+	// PasswordResetTTL and EmailVerificationTTL lifetime of links sent by email
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	// RequireEmailVerification self-registered users cannot log in until they verify email
+	RequireEmailVerification bool
+	// PublicURL URL of the application users open - links sent by email lead to it
+	PublicURL string
+	// APIKeyMaxTTL the latest expiry of API keys of service accounts. Keys issued without expiry get it
+	APIKeyMaxTTL time.Duration
+	// TokenBinding how tokens are bound to the client they are issued to
+	TokenBinding TokenBindingConfig
+	// SSO single sign-on by OpenID Connect identity provider
+	SSO oidc.Config
+	// LDAP authentication and sync of users of LDAP / Active Directory
+	LDAP directory.Config
 }
 
 const (
-	salt            = "eyJhbGciOiJIUzI1NiIsInR5ed217a32a94ba416f88e16122278cCI6IkpXVCJ9"
-	accessTokenKey  = "062839dc6e3f934d4ed217a32a94ba416f88e161222785ad95803fe4923dd06b"
-	refreshTokenKey = "0bf4586851bb6b6b15376e7b6bff4ac4d5cee836321f349462f60e3dbb07d7a4"
-	issuer          = "go-booking-system"
-	JWT             = "JWT"
-	HS256           = "HS256"
-	accessTokenTTL  = 1 * time.Hour
-	refreshTokenTTL = 3 * time.Hour
+	issuer = "go-booking-system"
+	JWT    = "JWT"
+	HS256  = "HS256"
+
+	// MinTokenKeyLength keys of HS256 tokens should have at least 256 bits
+	MinTokenKeyLength = 32
 )
 
-func NewAuthService(repository database.UserRepository, roleService RoleServiceInterface, routeService RouteServiceInterface, scopeService ScopeServiceInterface, permissionService PermissionServiceInterface, bookingService BookingServiceInterface, roomService RoomServiceInterface) *AuthService {
+func NewAuthService(config AuthConfig, repository database.UserRepository, roleService RoleServiceInterface, routeService RouteServiceInterface, scopeService ScopeServiceInterface, permissionService PermissionServiceInterface, bookingService BookingServiceInterface, roomService RoomServiceInterface, attendeeService AttendeeServiceInterface, locationService LocationServiceInterface, buildingService BuildingServiceInterface, floorService FloorServiceInterface, policyRepository database.PolicyRepository) *AuthService {
 	return &AuthService{
 		userRepository:    repository,
 		roleService:       roleService,
@@ -45,36 +90,83 @@ func NewAuthService(repository database.UserRepository, roleService RoleServiceI
 		permissionService: permissionService,
 		bookingService:    bookingService,
 		roomService:       roomService,
+		attendeeService:   attendeeService,
+		locationService:   locationService,
+		buildingService:   buildingService,
+		floorService:      floorService,
+		policyRepository:  policyRepository,
+		providers:         map[string]AuthenticationProvider{},
+		tokenBinding:      NewTokenBinding(config.TokenBinding),
+		config:            config,
 	}
 }
 
-func (a *AuthService) CheckIfUserExistsAndPasswordIsCorrect(username string, password string) (models.User, error) {
+// RegisterAuthenticationProvider users with the auth provider are authenticated by it
+func (a *AuthService) RegisterAuthenticationProvider(authProvider string, provider AuthenticationProvider) {
+	a.providers[authProvider] = provider
+}
+
+// CheckIfUserExistsAndPasswordIsCorrect returns the same error for unknown user, wrong password and inactive user, so the
+// response does not reveal which accounts exist. Password hash is computed in every case to keep the same response time
+func (a *AuthService) CheckIfUserExistsAndPasswordIsCorrect(ctx context.Context, username string, password string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.CheckIfUserExistsAndPasswordIsCorrect")
+	defer span.End()
+
 	// Identification
-	foundUser, err := a.userRepository.GetUserByUsername(username)
+	foundUser, err := a.userRepository.GetUserByUsername(ctx, username)
 	if err != nil {
-		log.Println("AuthService.CheckIfUserExistsAndPasswordIsCorrect(): error occured during User search. Passed data: ", username)
+		slog.ErrorContext(ctx, "AuthService.CheckIfUserExistsAndPasswordIsCorrect(): error occured during User search", "passed_data", username, "error", err)
 		return models.User{}, fmt.Errorf(`error occured during User search. Passed data: '%s'`, username)
 	}
 
+	// Authentication
+	passwordHash := a.GeneratePasswordHash(ctx, password)
+	isPasswordCorrect := subtle.ConstantTimeCompare([]byte(strings.ToLower(foundUser.Password)), []byte(passwordHash)) == 1
+
 	emptyUser := models.User{}
 	if foundUser == emptyUser {
-		log.Println("AuthService.CheckIfUserExistsAndPasswordIsCorrect(): user not found! Passed data: ", username)
-		return models.User{}, fmt.Errorf(`user not found. Passed data: '%s'`, username)
+		slog.WarnContext(ctx, "AuthService.CheckIfUserExistsAndPasswordIsCorrect(): user not found", "passed_data", username)
+		return models.User{}, errors.New(InvalidCredentialsError)
 	}
-
-	// Authentication
-	userPasswordHash := foundUser.Password
-	passwordHash := a.GeneratePasswordHash(password)
-	if !strings.EqualFold(userPasswordHash, passwordHash) {
-		log.Println("AuthService.CheckIfUserExistsAndPasswordIsCorrect(): wrong password!")
-		return models.User{}, fmt.Errorf("wrong password")
+	// password of directory users is checked by the directory - their local password hash is random
+	if isExternalUser(foundUser) {
+		isPasswordCorrect = false
+		provider, ok := a.providers[foundUser.AuthProvider]
+		if !ok {
+			slog.WarnContext(ctx, "AuthService.CheckIfUserExistsAndPasswordIsCorrect(): authentication provider of the user is not enabled", "passed_data", username, "auth_provider", foundUser.AuthProvider)
+			return models.User{}, errors.New(InvalidCredentialsError)
+		}
+		// inactive user is not sent to the directory
+		if foundUser.Active {
+			err := provider.Authenticate(ctx, foundUser, password)
+			if err != nil && !isInvalidCredentials(err) {
+				slog.ErrorContext(ctx, "AuthService.CheckIfUserExistsAndPasswordIsCorrect(): error occured during authentication by provider", "passed_data", username, "auth_provider", foundUser.AuthProvider, "error", err)
+				return models.User{}, fmt.Errorf(`authentication provider is not available. Passed data: '%s'`, username)
+			}
+			isPasswordCorrect = err == nil
+		}
+	}
+	if !isPasswordCorrect {
+		slog.WarnContext(ctx, "AuthService.CheckIfUserExistsAndPasswordIsCorrect(): wrong password", "passed_data", username)
+		return models.User{}, errors.New(InvalidCredentialsError)
+	}
+	if !foundUser.Active {
+		slog.WarnContext(ctx, "AuthService.CheckIfUserExistsAndPasswordIsCorrect(): user is not active", "passed_data", username)
+		return models.User{}, errors.New(InvalidCredentialsError)
+	}
+	if a.config.RequireEmailVerification && !foundUser.EmailVerified {
+		slog.WarnContext(ctx, "AuthService.CheckIfUserExistsAndPasswordIsCorrect(): email is not verified", "passed_data", username)
+		return models.User{}, errors.New(EmailNotVerifiedError)
 	}
 
 	return foundUser, nil
 }
 
-// GenerateTokens TODO Add error return value
-func (a *AuthService) GenerateTokens(user models.User, identity pkg.IPAddressIdentity) (accessToken pkg.JWTToken, refreshToken pkg.JWTToken) {
+// GenerateTokens TODO Add error return value. authMethods - `amr` claim: how the user has logged in
+func (a *AuthService) GenerateTokens(ctx context.Context, user models.User, identity pkg.IPAddressIdentity, authMethods []string) (accessToken pkg.JWTToken, refreshToken pkg.JWTToken) {
+	ctx, span := tracer.Start(ctx, "AuthService.GenerateTokens")
+	defer span.End()
+
 	joseHeader := pkg.JOSEHeader{
 		Algorithm: HS256,
 		Type:      JWT,
@@ -85,89 +177,208 @@ func (a *AuthService) GenerateTokens(user models.User, identity pkg.IPAddressIde
 	accessTokenClaims := pkg.AccessTokenClaims{
 		Issuer:              issuer,
 		IssuedAt:            int(now.Unix()),
-		ExpirationTime:      int(now.Add(accessTokenTTL).Unix()),
+		ExpirationTime:      int(now.Add(a.config.AccessTokenTTL).Unix()),
 		Subject:             strconv.FormatInt(int64(user.UserId), 10),
 		Role:                strconv.FormatInt(int64(user.RoleId), 10),
+		Organization:        strconv.FormatInt(int64(user.OrganizationId), 10),
 		OriginatingIdentity: identity,
+		AuthMethods:         authMethods,
 	}
 
 	refreshTokenClaims := pkg.RefreshTokenClaims{
 		Issuer:              issuer,
 		IssuedAt:            int(now.Unix()),
-		ExpirationTime:      int(now.Add(refreshTokenTTL).Unix()),
+		ExpirationTime:      int(now.Add(a.config.RefreshTokenTTL).Unix()),
 		Subject:             strconv.FormatInt(int64(user.UserId), 10),
+		Organization:        strconv.FormatInt(int64(user.OrganizationId), 10),
 		OriginatingIdentity: identity,
+		AuthMethods:         authMethods,
 	}
 
-	accessToken, accessTokenGenerationError := pkg.GenerateJWTAccessToken(joseHeader, accessTokenClaims, accessTokenKey)
+	accessToken, accessTokenGenerationError := pkg.GenerateJWTAccessToken(joseHeader, accessTokenClaims, a.config.AccessTokenKey)
 	if accessTokenGenerationError != nil {
-		log.Println("AuthService.GenerateTokens(): error occured during access token generation.")
-		log.Println(accessTokenGenerationError)
+		slog.ErrorContext(ctx, "AuthService.GenerateTokens(): error occured during access token generation", "error", accessTokenGenerationError)
 		return pkg.JWTToken(""), pkg.JWTToken("")
 	}
 
-	refreshToken, refreshTokenGenerationError := pkg.GenerateJWTRefreshToken(joseHeader, refreshTokenClaims, refreshTokenKey)
+	refreshToken, refreshTokenGenerationError := pkg.GenerateJWTRefreshToken(joseHeader, refreshTokenClaims, a.config.RefreshTokenKey)
 	if refreshTokenGenerationError != nil {
-		log.Println("AuthService.GenerateTokens(): error occured during refresh token generation.")
-		log.Println(refreshTokenGenerationError)
+		slog.ErrorContext(ctx, "AuthService.GenerateTokens(): error occured during refresh token generation", "error", refreshTokenGenerationError)
 		return pkg.JWTToken(""), pkg.JWTToken("")
 	}
 
 	return accessToken, refreshToken
 }
 
-func (a *AuthService) Create(user models.User) (models.User, error) {
-	passwordHash := a.GeneratePasswordHash(user.Password)
+// Create creates user on behalf of createdBy. Roles other than the default one are given only by administrators, as
+// by `/user/role`. SystemUserId - the application itself creates the user (role is not taken from the request)
+func (a *AuthService) Create(ctx context.Context, createdBy int, user models.User) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.Create")
+	defer span.End()
+
+	if err := a.CheckRoleIsAvailable(ctx, user.RoleId); err != nil {
+		return models.User{}, err
+	}
+	if user.RoleId != DefaultRoleId && createdBy != SystemUserId {
+		if err := a.CheckRoleChangeIsAllowed(ctx, createdBy, user.UserId); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	if user.AuthProvider == "" {
+		user.AuthProvider = AuthProviderLocal
+	}
+	passwordHash := a.GeneratePasswordHash(ctx, user.Password)
 	user.Password = passwordHash
 
-	return a.userRepository.Create(user)
+	return a.userRepository.Create(ctx, user)
 }
 
-func (a *AuthService) UpdatePassword(userId int, password string) (models.User, error) {
-	passwordHash := a.GeneratePasswordHash(password)
+func (a *AuthService) UpdatePassword(ctx context.Context, userId int, password string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.UpdatePassword")
+	defer span.End()
 
-	return a.userRepository.UpdatePassword(models.User{UserId: userId, Password: passwordHash})
+	user, err := a.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return models.User{}, err
+	}
+	if isExternalUser(user) {
+		slog.WarnContext(ctx, "AuthService.UpdatePassword(): password of directory user cannot be changed", "user_id", userId, "auth_provider", user.AuthProvider)
+		return models.User{}, errors.New(DirectoryPasswordError)
+	}
+
+	passwordHash := a.GeneratePasswordHash(ctx, password)
+
+	return a.userRepository.UpdatePassword(ctx, models.User{UserId: userId, Password: passwordHash})
 }
 
-func (a *AuthService) UpdateUsername(userId int, username string) (models.User, error) {
-	return a.userRepository.UpdateUsername(models.User{UserId: userId, UserName: username})
+func (a *AuthService) UpdateUsername(ctx context.Context, userId int, username string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.UpdateUsername")
+	defer span.End()
+
+	return a.userRepository.UpdateUsername(ctx, models.User{UserId: userId, UserName: username})
 }
 
-func (a *AuthService) UpdateRole(userId int, roleId int) (models.User, error) {
-	return a.userRepository.UpdateUserRole(models.User{UserId: userId, RoleId: roleId})
+func (a *AuthService) UpdateRole(ctx context.Context, userId int, roleId int) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.UpdateRole")
+	defer span.End()
+
+	if err := a.CheckRoleIsAvailable(ctx, roleId); err != nil {
+		return models.User{}, err
+	}
+
+	return a.userRepository.UpdateUserRole(ctx, models.User{UserId: userId, RoleId: roleId})
+}
+
+// ChangeRole sets role of the user on behalf of administrator. Administrator is the user whose role has permission to
+// `/user/role` - it is checked here too, so the role is not changed by any other caller of the service. Own role is
+// not changed - the last administrator cannot demote the only account which can undo it
+func (a *AuthService) ChangeRole(ctx context.Context, changedBy int, userId int, roleId int) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.ChangeRole")
+	defer span.End()
+
+	if err := a.CheckRoleChangeIsAllowed(ctx, changedBy, userId); err != nil {
+		return models.User{}, err
+	}
+
+	if _, err := a.userRepository.GetUserById(ctx, userId); err != nil {
+		return models.User{}, err
+	}
+	if _, err := a.UpdateRole(ctx, userId, roleId); err != nil {
+		return models.User{}, err
+	}
+	slog.InfoContext(ctx, "AuthService.ChangeRole(): role of the user is changed", "user_id", userId, "role_id", roleId, "changed_by", changedBy)
+
+	return a.userRepository.GetUserById(ctx, userId)
 }
 
-func (a *AuthService) GeneratePasswordHash(password string) string {
+// CheckRoleChangeIsAllowed roles are changed only by administrators (roles having permission to RoleChangeRoute), and
+// nobody changes their own role
+func (a *AuthService) CheckRoleChangeIsAllowed(ctx context.Context, changedBy int, userId int) error {
+	ctx, span := tracer.Start(ctx, "AuthService.CheckRoleChangeIsAllowed")
+	defer span.End()
+
+	if changedBy == userId {
+		slog.WarnContext(ctx, "AuthService.CheckRoleChangeIsAllowed(): own role cannot be changed", "user_id", userId)
+		return errors.New(OwnRoleChangeError)
+	}
+
+	administrator, err := a.userRepository.GetUserById(ctx, changedBy)
+	if err != nil {
+		return err
+	}
+	route, err := a.routeService.GetRouteByURL(ctx, RoleChangeRoute)
+	if err != nil {
+		return err
+	}
+	var permissions []models.Permission
+	if route.RouteId != 0 {
+		if permissions, err = a.permissionService.GetPermissionsByRoleIdAndRouteId(ctx, administrator.RoleId, route.RouteId); err != nil {
+			return err
+		}
+	}
+	if len(permissions) == 0 || !administrator.Active {
+		slog.WarnContext(ctx, "AuthService.CheckRoleChangeIsAllowed(): the user is not an administrator", "changed_by", changedBy, "role_id", administrator.RoleId)
+		return errors.New(RoleChangeForbiddenError)
+	}
+
+	return nil
+}
+
+// CheckRoleIsAvailable checks that role is a system one or belongs to organization of the caller
+func (a *AuthService) CheckRoleIsAvailable(ctx context.Context, roleId int) error {
+	ctx, span := tracer.Start(ctx, "AuthService.CheckRoleIsAvailable")
+	defer span.End()
+
+	role, err := a.roleService.GetRoleById(ctx, roleId)
+	if err != nil {
+		return err
+	}
+	if role.RoleId == 0 {
+		return fmt.Errorf("role is not found in organization. Passed data: role_id=%d", roleId)
+	}
+
+	return nil
+}
+
+func (a *AuthService) GeneratePasswordHash(ctx context.Context, password string) string {
+	_, span := tracer.Start(ctx, "AuthService.GeneratePasswordHash")
+	defer span.End()
+
 	sha256Hasher := sha256.New()
 
 	// hash password
 	sha256Hasher.Write([]byte(password))
 	// add salt to hashed password
-	hashedAndSaltedPassword := sha256Hasher.Sum([]byte(salt))
+	hashedAndSaltedPassword := sha256Hasher.Sum([]byte(a.config.PasswordSalt))
 
 	return fmt.Sprintf("%x", hashedAndSaltedPassword)
 }
 
-func (a *AuthService) ValidateAccessToken(encodedToken string, ipAddress string) *JWTTokenValidator {
-	sentFrom := pkg.IPAddressIdentity{IP: ipAddress}
+// ValidateAccessToken sentFrom - identity of the client which has sent the token, it is checked by token binding
+func (a *AuthService) ValidateAccessToken(ctx context.Context, encodedToken string, sentFrom pkg.IPAddressIdentity) *JWTTokenValidator {
+	ctx, span := tracer.Start(ctx, "AuthService.ValidateAccessToken")
+	defer span.End()
 
-	validator := NewJWTTokenValidator(encodedToken, accessTokenKey, sentFrom)
+	validator := NewJWTTokenValidator(encodedToken, a.config.AccessTokenKey, AccessTokenType, sentFrom, a.tokenBinding)
 
 	if validator.IsEverythingValid != true {
-		log.Println("AuthService.ValidateTokensForRefresh(): error occured during access token validation.")
+		slog.WarnContext(ctx, "AuthService.ValidateAccessToken(): access token is not valid", "error", validator.ValidationError)
 		return validator
 	}
 
 	return validator
 }
 
-func (a *AuthService) ValidateRefreshToken(encodedToken string, ipAddress string) *JWTTokenValidator {
-	sentFrom := pkg.IPAddressIdentity{IP: ipAddress}
+// ValidateRefreshToken sentFrom - identity of the client which has sent the token, it is checked by token binding
+func (a *AuthService) ValidateRefreshToken(ctx context.Context, encodedToken string, sentFrom pkg.IPAddressIdentity) *JWTTokenValidator {
+	ctx, span := tracer.Start(ctx, "AuthService.ValidateRefreshToken")
+	defer span.End()
 
-	validator := NewJWTTokenValidator(encodedToken, refreshTokenKey, sentFrom)
+	validator := NewJWTTokenValidator(encodedToken, a.config.RefreshTokenKey, RefreshTokenType, sentFrom, a.tokenBinding)
 
 	if validator.IsEverythingValid != true {
-		log.Println("AuthService.ValidateTokensForRefresh(): error occured during refresh token validation.")
+		slog.WarnContext(ctx, "AuthService.ValidateRefreshToken(): refresh token is not valid", "error", validator.ValidationError)
 		return validator
 	}
 
@@ -175,112 +386,357 @@ func (a *AuthService) ValidateRefreshToken(encodedToken string, ipAddress string
 }
 
 func (a *AuthService) SignHeaderAndPayload(encodedJOSEHeader string, encodedClaims string) string {
-	return pkg.SignHeaderAndPayload(encodedJOSEHeader, encodedClaims, accessTokenKey)
+	return pkg.SignHeaderAndPayload(encodedJOSEHeader, encodedClaims, a.config.AccessTokenKey)
 }
 
 const (
-	AllScopeId   = 1
-	OwnerScopeId = 2
+	AllScopeId      = 1
+	OwnerScopeId    = 2
+	AttendeeScopeId = 3 // OWNER-style scope: owned records and bookings user is invited to
 )
 
-func (a *AuthService) CheckPermissions(destination string, recordType string, recordString string, subject string, roleString string) (bool, error) {
+// narrowerScope scope giving access to fewer records: ALL is wider than ATTENDEE, ATTENDEE is wider than OWNER
+func narrowerScope(scopeId int, otherScopeId int) int {
+	width := map[int]int{OwnerScopeId: 1, AttendeeScopeId: 2, AllScopeId: 3}
+	if width[otherScopeId] < width[scopeId] {
+		return otherScopeId
+	}
+
+	return scopeId
+}
+
+const (
+	AccessGrantedByPermissionReason = "granted by permission of the role"
+	NoAccessRulesReason             = "no permission or policy of the role for the route"
+	NoAccessGrantedReason           = "no permission or policy grants access to the record"
+)
+
+// CheckPermissions permissions of the role decide access to the route, then policies of the route are applied: allow
+// policy grants access permissions do not grant, deny policy takes away access granted by anything
+func (a *AuthService) CheckPermissions(ctx context.Context, destination string, recordType string, recordString string, subject string, roleString string, request RequestAttributesSource) (bool, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.CheckPermissions")
+	defer span.End()
+
 	roleId, conversionError := strconv.Atoi(roleString)
 	if conversionError != nil {
-		log.Println("AuthService.CheckPermissions(): error occured during conversion from `role` string to `RoleId` integer")
+		slog.WarnContext(ctx, "AuthService.CheckPermissions(): error occured during conversion from `role` string to `RoleId` integer", "passed_data", roleString)
 		return false, conversionError
 	}
 
-	// get routeId by url
-	route, err := a.routeService.GetRouteByURL(destination)
+	userId, conversionError := strconv.Atoi(subject)
+	if conversionError != nil {
+		slog.WarnContext(ctx, "AuthService.CheckPermissions(): error occured during conversion from `subject` string to `UserId` integer", "passed_data", subject)
+		return false, conversionError
+	}
+
+	explanation, err := a.explainAccess(ctx, destination, recordType, recordString, userId, roleId, request, nil)
 	if err != nil {
-		log.Println("AuthService.CheckPermissions(): error occured during getting route by URL. Passed data: ", destination)
 		return false, err
 	}
 
+	// check if no permissions were found
+	if explanation.Reason == NoAccessRulesReason {
+		slog.WarnContext(ctx, "AuthService.CheckPermissions(): no permission has been found by RoleId and RouteId", "role_id", roleId, "route_id", explanation.RouteId)
+		return false, errors.New("no permission has been found - access denied")
+	}
+	if len(explanation.Policies) != 0 {
+		slog.InfoContext(ctx, "AuthService.CheckPermissions(): access is decided with policies", "role_id", roleId, "route_id", explanation.RouteId, "access_granted", explanation.Allowed, "reason", explanation.Reason)
+	}
+
+	return explanation.Allowed, nil
+}
+
+// ExplainAccess the same check as CheckPermissions, but it returns how access is decided. Dry run policies are evaluated
+// together with saved policies of the route
+func (a *AuthService) ExplainAccess(ctx context.Context, destination string, recordType string, recordString string, userId int, roleId int, request RequestAttributes, dryRunPolicies []models.Policy) (models.AccessExplanation, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.ExplainAccess")
+	defer span.End()
+
+	return a.explainAccess(ctx, destination, recordType, recordString, userId, roleId, request, dryRunPolicies)
+}
+
+func (a *AuthService) explainAccess(ctx context.Context, destination string, recordType string, recordString string, userId int, roleId int, request RequestAttributesSource, dryRunPolicies []models.Policy) (models.AccessExplanation, error) {
+	// get routeId by url
+	route, err := a.routeService.GetRouteByURL(ctx, destination)
+	if err != nil {
+		slog.ErrorContext(ctx, "AuthService.CheckPermissions(): error occured during getting route by URL", "passed_data", destination, "error", err)
+		return models.AccessExplanation{}, err
+	}
+
 	// check if no route has been found
 	emptyRoute := models.Route{}
 	if route == emptyRoute {
-		log.Println("AuthService.CheckPermissions(): no route has been found. Passed data: ", destination)
-		return false, fmt.Errorf("no route has been found. Passed data: %s", destination)
+		slog.WarnContext(ctx, "AuthService.CheckPermissions(): no route has been found", "passed_data", destination)
+		return models.AccessExplanation{}, fmt.Errorf("no route has been found. Passed data: %s", destination)
 	}
 
 	// find permissions by roleId and routeId
-	permissions, err := a.permissionService.GetPermissionsByRoleIdAndRouteId(roleId, route.RouteId)
+	permissions, err := a.permissionService.GetPermissionsByRoleIdAndRouteId(ctx, roleId, route.RouteId)
 	if err != nil {
-		log.Printf("AuthService.CheckPermissions(): error occured during getting permissions by RoleId and RouteId. Passed data: RoleId=%d RouteId=%d", roleId, route.RouteId)
-		return false, err
+		slog.ErrorContext(ctx, "AuthService.CheckPermissions(): error occured during getting permissions by RoleId and RouteId", "role_id", roleId, "route_id", route.RouteId, "error", err)
+		return models.AccessExplanation{}, err
 	}
 
-	// check if no permissions were found
-	if len(permissions) == 0 {
-		log.Printf("AuthService.CheckPermissions(): no permission has been found by RoleId and RouteId. Passed data: RoleId=%d RouteId=%d", roleId, route.RouteId)
-		return false, errors.New("no permission has been found - access denied")
+	policies, err := a.policyRepository.GetPoliciesByRoleIdAndRouteId(ctx, roleId, route.RouteId)
+	if err != nil {
+		return models.AccessExplanation{}, err
+	}
+	for _, policy := range dryRunPolicies {
+		if policy.RoleId == 0 || policy.RoleId == roleId {
+			policies = append(policies, policy)
+		}
 	}
 
-	userId, conversionError := strconv.Atoi(subject)
-	if conversionError != nil {
-		log.Println("AuthService.CheckPermissions(): error occured during conversion from `subject` string to `UserId` integer")
-		return false, conversionError
+	explanation := models.AccessExplanation{
+		RouteId:     route.RouteId,
+		RoleId:      roleId,
+		Permissions: []models.PermissionExplanation{},
+		Policies:    []models.PolicyExplanation{},
+	}
+	if len(permissions) == 0 && len(policies) == 0 {
+		explanation.Reason = NoAccessRulesReason
+		return explanation, nil
 	}
 
-	// check if user has right to perform action over chosen record
+	// permissions are checked until one of them grants access
 	for _, permission := range permissions {
-		// check if role has rights over of ALL the records
-		if permission.ScopeId == AllScopeId {
-			return true, nil // then he has rights over all records - everything is ok
+		isGranted, err := a.isPermissionGranted(ctx, permission, userId, recordType, recordString)
+		if err != nil {
+			return models.AccessExplanation{}, err
 		}
-		if permission.ScopeId == OwnerScopeId {
-			recordId, conversionError := strconv.Atoi(recordString)
-			if conversionError != nil {
-				return false, conversionError
-			}
-			isOwner, isOwnerError := a.CheckIfUserIsOwner(userId, recordType, recordId)
-			if isOwnerError != nil {
-				return false, isOwnerError
-			}
-			if isOwner == true {
-				return true, nil
-			}
+		explanation.Permissions = append(explanation.Permissions, models.PermissionExplanation{ScopeId: permission.ScopeId, LocationId: permission.LocationId, Granted: isGranted})
+		if isGranted {
+			explanation.Allowed = true
+			explanation.Reason = AccessGrantedByPermissionReason
+			break
+		}
+	}
+	if len(policies) == 0 {
+		if !explanation.Allowed {
+			explanation.Reason = NoAccessGrantedReason
 		}
+		return explanation, nil
 	}
 
-	return false, nil
+	// attributes are loaded only for routes having policies - most of the routes do not need them
+	attributes, err := a.accessAttributes(ctx, userId, roleId, recordType, recordString, request)
+	if err != nil {
+		return models.AccessExplanation{}, err
+	}
+	explanation.Attributes = attributes
+
+	var allowPolicy, denyPolicy *models.PolicyExplanation
+	for _, policy := range policies {
+		explanation.Policies = append(explanation.Policies, EvaluatePolicy(policy, attributes))
+	}
+	for i := range explanation.Policies {
+		policy := &explanation.Policies[i]
+		if policy.Matched && policy.Effect == models.PolicyEffectAllow && allowPolicy == nil {
+			allowPolicy = policy
+		}
+		if policy.Matched && policy.Effect == models.PolicyEffectDeny && denyPolicy == nil {
+			denyPolicy = policy
+		}
+	}
+
+	switch {
+	case denyPolicy != nil:
+		explanation.Allowed = false
+		explanation.Reason = fmt.Sprintf("denied by policy %q", denyPolicy.Name)
+	case !explanation.Allowed && allowPolicy != nil:
+		explanation.Allowed = true
+		explanation.Reason = fmt.Sprintf("granted by policy %q", allowPolicy.Name)
+	case !explanation.Allowed:
+		explanation.Reason = NoAccessGrantedReason
+	}
+
+	return explanation, nil
 }
 
-func (a *AuthService) CheckIfUserIsOwner(userId int, recordType string, idValue int) (bool, error) {
-	if recordType == "room" {
-		foundRoom, err := a.roomService.GetRoomById(idValue)
-		if err != nil {
-			return false, err
+// CheckAPIKeyPermissions checks permissions of the API key for the route. Key never gives more than role of its service
+// account: only routes allowed to both are accessible, with the narrower of two scopes (and location of the role). Allow
+// policies are not permissions - routes the role gets only by them are denied (such keys are not created)
+func (a *AuthService) CheckAPIKeyPermissions(ctx context.Context, apiKey models.APIKey, roleId int, destination string, recordType string, recordString string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.CheckAPIKeyPermissions")
+	defer span.End()
+
+	route, err := a.routeService.GetRouteByURL(ctx, destination)
+	if err != nil {
+		slog.ErrorContext(ctx, "AuthService.CheckAPIKeyPermissions(): error occured during getting route by URL", "passed_data", destination, "error", err)
+		return false, err
+	}
+
+	var keyPermissions []models.APIKeyPermission
+	for _, permission := range apiKey.Permissions {
+		if route.RouteId != 0 && permission.RouteId == route.RouteId {
+			keyPermissions = append(keyPermissions, permission)
 		}
-		if foundRoom.CreatedBy == userId {
-			return true, nil
+	}
+	if len(keyPermissions) == 0 {
+		slog.WarnContext(ctx, "AuthService.CheckAPIKeyPermissions(): route is not allowed to the API key", "key_id", apiKey.APIKeyId, "passed_data", destination)
+		return false, nil
+	}
+
+	rolePermissions, err := a.permissionService.GetPermissionsByRoleIdAndRouteId(ctx, roleId, route.RouteId)
+	if err != nil {
+		return false, err
+	}
+	var permissions []models.Permission
+	for _, keyPermission := range keyPermissions {
+		for _, rolePermission := range rolePermissions {
+			rolePermission.ScopeId = narrowerScope(rolePermission.ScopeId, keyPermission.ScopeId)
+			permissions = append(permissions, rolePermission)
 		}
+	}
+	if len(permissions) == 0 {
+		slog.WarnContext(ctx, "AuthService.CheckAPIKeyPermissions(): route is not allowed to role of the service account", "key_id", apiKey.APIKeyId, "role_id", roleId, "passed_data", destination)
 		return false, nil
 	}
-	if recordType == "user" {
-		foundUser, err := a.userRepository.GetUserById(idValue)
-		if err != nil {
-			return false, err
+
+	return a.isAnyPermissionGranted(ctx, permissions, apiKey.UserId, recordType, recordString)
+}
+
+// isAnyPermissionGranted checks scope (and location) of every permission against the record - one is enough
+func (a *AuthService) isAnyPermissionGranted(ctx context.Context, permissions []models.Permission, userId int, recordType string, recordString string) (bool, error) {
+	for _, permission := range permissions {
+		isGranted, err := a.isPermissionGranted(ctx, permission, userId, recordType, recordString)
+		if err != nil || isGranted {
+			return isGranted, err
+		}
+	}
+
+	return false, nil
+}
+
+// isPermissionGranted checks if user has right to perform action over chosen record
+func (a *AuthService) isPermissionGranted(ctx context.Context, permission models.Permission, userId int, recordType string, recordString string) (bool, error) {
+	// check if permission is limited to records of one location
+	if permission.LocationId != 0 {
+		isInLocation, isInLocationError := a.CheckIfRecordIsInLocation(ctx, recordType, recordString, permission.LocationId)
+		if isInLocationError != nil {
+			return false, isInLocationError
 		}
-		if foundUser.UserId == userId {
-			return true, nil
+		if isInLocation == false {
+			return false, nil
 		}
-		return false, nil
 	}
-	if recordType == "booking" {
-		foundBooking, err := a.bookingService.GetBookingById(idValue)
-		if err != nil {
-			return false, err
+	// check if role has rights over of ALL the records
+	if permission.ScopeId == AllScopeId {
+		return true, nil // then he has rights over all records - everything is ok
+	}
+	if permission.ScopeId == OwnerScopeId || permission.ScopeId == AttendeeScopeId {
+		recordId, conversionError := strconv.Atoi(recordString)
+		if conversionError != nil {
+			return false, conversionError
 		}
-		if foundBooking.CreatedBy == userId {
-			return true, nil
+		isOwner, isOwnerError := a.CheckIfUserIsOwner(ctx, userId, recordType, recordId)
+		if isOwnerError != nil {
+			return false, isOwnerError
 		}
-		return false, nil
+		if isOwner == true || permission.ScopeId == OwnerScopeId {
+			return isOwner, nil
+		}
+		return a.CheckIfUserIsAttendee(ctx, userId, recordType, recordId)
 	}
+
 	return false, nil
 }
 
+// CheckIfUserIsOwner records of types without owner (see recordResolvers) are not owned by anybody
+func (a *AuthService) CheckIfUserIsOwner(ctx context.Context, userId int, recordType string, idValue int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.CheckIfUserIsOwner")
+	defer span.End()
+
+	resolver := recordResolvers[recordType]
+	if resolver.owner == nil {
+		return false, nil
+	}
+	ownerId, err := resolver.owner(a, ctx, idValue)
+	if err != nil {
+		return false, err
+	}
+
+	return ownerId == userId, nil
+}
+
+// CheckIfUserIsAttendee only bookings have attendees
+func (a *AuthService) CheckIfUserIsAttendee(ctx context.Context, userId int, recordType string, idValue int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.CheckIfUserIsAttendee")
+	defer span.End()
+
+	resolver := recordResolvers[recordType]
+	if resolver.isAttendee == nil {
+		return false, nil
+	}
+
+	return resolver.isAttendee(a, ctx, idValue, userId)
+}
+
+// CheckIfRecordIsInLocation records without id (e.g. lists of all records) are considered to be out of any location
+func (a *AuthService) CheckIfRecordIsInLocation(ctx context.Context, recordType string, recordString string, locationId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.CheckIfRecordIsInLocation")
+	defer span.End()
+
+	if recordString == "" {
+		return false, nil
+	}
+	idValue, conversionError := strconv.Atoi(recordString)
+	if conversionError != nil {
+		return false, conversionError
+	}
+
+	resolver := recordResolvers[recordType]
+	if resolver.location == nil {
+		return false, nil
+	}
+	recordLocationId, err := resolver.location(a, ctx, idValue)
+	if err != nil {
+		return false, err
+	}
+
+	return recordLocationId == locationId, nil
+}
+
+// accessAttributes attributes policies are evaluated against: the user (subject.*), the record of the request
+// (resource.*, by recordResolvers) and the request itself (request.*)
+func (a *AuthService) accessAttributes(ctx context.Context, userId int, roleId int, recordType string, recordString string, request RequestAttributesSource) (map[string]any, error) {
+	subject, err := a.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := map[string]any{
+		"subject.user_id":         subject.UserId,
+		"subject.role_id":         roleId,
+		"subject.organization_id": subject.OrganizationId,
+		"resource.type":           recordType,
+	}
+	if subject.FloorId != 0 {
+		attributes["subject.floor_id"] = subject.FloorId
+	}
+	requestAttributes := request.Attributes()
+	for name, value := range requestAttributes {
+		attributes[RequestAttributePrefix+name] = value
+	}
+
+	var recordId int
+	if recordString != "" {
+		var conversionError error
+		if recordId, conversionError = strconv.Atoi(recordString); conversionError != nil {
+			return nil, conversionError
+		}
+		attributes["resource.id"] = recordId
+	}
+
+	if resolver := recordResolvers[recordType]; resolver.attributes != nil {
+		if err := resolver.attributes(a, ctx, recordId, requestAttributes, attributes); err != nil {
+			return nil, err
+		}
+	}
+
+	return attributes, nil
+}
+
 const (
 	LessThanOnePeriodError              = "JWT token contains less than one period ('.') character"
 	WrongJWTTypeError                   = "JWT token should contain 3 parts: 1. JOSEHeader, 2. AccessTokenClaims, 3. Signature"
@@ -288,6 +744,29 @@ const (
 	IntegrityNotIntactError             = "JWT-Token was changed along the way"
 	SentNotFromOriginatingIdentityError = "tokens are sent from another system&program! Possible fraudulent activity"
 	TokenIsExpiredError                 = "token is expired"
+
+	// InvalidCredentialsError the same for unknown username, wrong password and inactive user
+	InvalidCredentialsError = "invalid username or password"
+	// EmailNotVerifiedError is returned only after the password is checked - it does not reveal which accounts exist
+	EmailNotVerifiedError = "email is not verified - open the link sent to it"
+
+	OwnRoleChangeError       = "own role cannot be changed"
+	RoleChangeForbiddenError = "only administrators change roles of users"
+)
+
+// RoleChangeRoute roles having permission to the route are administrators - they change roles of other users
+const RoleChangeRoute = "/user/role"
+
+// DefaultRoleId role of self-registered users (USER). Other roles are given only by administrators
+const DefaultRoleId = 5
+
+// SystemUserId creator of users created by the application itself: self-registration, SSO and directory sync by role
+// mappings of the configuration, CLI of the operator
+const SystemUserId = 0
+
+const (
+	AccessTokenType  = "access"
+	RefreshTokenType = "refresh"
 )
 
 type JWTTokenValidator struct {
@@ -298,15 +777,19 @@ type JWTTokenValidator struct {
 	RefreshTokenClaims pkg.RefreshTokenClaims
 	Signature          pkg.Signature
 	SentFromIdentity   pkg.IPAddressIdentity
+	binding            TokenBinding
 	tokenKey           string
+	tokenType          string
 
 	ValidationError   error `json:"validation_errors"`
 	IsExpired         bool  `json:"is_expired"`
 	IsEverythingValid bool  `json:"is_everything_valid"`
 }
 
-func NewJWTTokenValidator(jwtTokenString string, tokenKey string, sentFromIdentity pkg.IPAddressIdentity) *JWTTokenValidator {
-	validator := &JWTTokenValidator{JWTTokenString: jwtTokenString, tokenKey: tokenKey, SentFromIdentity: sentFromIdentity, IsEverythingValid: false}
+// NewJWTTokenValidator tokenType: AccessTokenType or RefreshTokenType - defines claims extracted from the token.
+// binding decides whether the token is sent from its originating identity
+func NewJWTTokenValidator(jwtTokenString string, tokenKey string, tokenType string, sentFromIdentity pkg.IPAddressIdentity, binding TokenBinding) *JWTTokenValidator {
+	validator := &JWTTokenValidator{JWTTokenString: jwtTokenString, tokenKey: tokenKey, tokenType: tokenType, SentFromIdentity: sentFromIdentity, binding: binding, IsEverythingValid: false}
 	validator.IsTokenValid()
 
 	return validator
@@ -326,12 +809,12 @@ func (j *JWTTokenValidator) ValidateToken() {
 	tokenParts := strings.Split(j.JWTTokenString, ".")
 	dotsCount := len(tokenParts) - 1
 	if dotsCount < 1 {
-		log.Println("JWTTokenValidator: ", LessThanOnePeriodError, "Passed data: ", j.JWTTokenString)
+		slog.Warn("JWTTokenValidator: "+LessThanOnePeriodError, "token", j.JWTTokenString)
 		j.ValidationError = errors.New(LessThanOnePeriodError)
 		return
 	}
 	if len(tokenParts) != 3 {
-		log.Println("JWTTokenValidator: ", WrongJWTTypeError, "Passed data: ", j.JWTTokenString)
+		slog.Warn("JWTTokenValidator: "+WrongJWTTypeError, "token", j.JWTTokenString)
 		j.ValidationError = errors.New(WrongJWTTypeError)
 		return
 	}
@@ -339,7 +822,7 @@ func (j *JWTTokenValidator) ValidateToken() {
 	encodedJOSEHeader, encodedClaims, encodedSignature := tokenParts[0], tokenParts[1], tokenParts[2]
 	// check if they are not nil strings
 	if encodedJOSEHeader == "" || encodedClaims == "" || encodedSignature == "" {
-		log.Println("JWTTokenValidator: ", EmptyJWTPartsError, " Passed data: ", j.JWTTokenString)
+		slog.Warn("JWTTokenValidator: "+EmptyJWTPartsError, "token", j.JWTTokenString)
 		j.ValidationError = errors.New(EmptyJWTPartsError)
 		return
 	}
@@ -347,7 +830,7 @@ func (j *JWTTokenValidator) ValidateToken() {
 	// verify the JOSE header and Message wasn't changed along the way
 	expectedSignature := pkg.SignHeaderAndPayload(encodedJOSEHeader, encodedClaims, j.tokenKey)
 	if expectedSignature != encodedSignature {
-		log.Println("JWTTokenValidator: ", IntegrityNotIntactError, " Passed data: ", j.JWTTokenString)
+		slog.Warn("JWTTokenValidator: "+IntegrityNotIntactError, "token", j.JWTTokenString)
 		j.ValidationError = errors.New(IntegrityNotIntactError)
 		return
 	}
@@ -355,25 +838,25 @@ func (j *JWTTokenValidator) ValidateToken() {
 	// 2. Let the Encoded JOSE JOSEHeader be the portion of the JWT before the first period ('.') character
 	joseHeader, joseHeaderExtractionError := pkg.ExtractJOSEHeader(encodedJOSEHeader)
 	if joseHeaderExtractionError != nil {
-		log.Println("JWTTokenValidator: error during extraction of JOSE JOSEHeader. Details: ", joseHeaderExtractionError)
+		slog.Warn("JWTTokenValidator: error during extraction of JOSE JOSEHeader", "error", joseHeaderExtractionError)
 		j.ValidationError = joseHeaderExtractionError
 		return
 	}
 	j.JOSEHeader = joseHeader
 
 	// 9.   Otherwise, base64url decode the Message
-	if j.tokenKey == accessTokenKey {
+	if j.tokenType == AccessTokenType {
 		accessTokenClaims, claimsExtractionError := pkg.ExtractAccessTokenClaims(encodedClaims)
 		if claimsExtractionError != nil {
-			log.Println("JWTTokenValidator: ", claimsExtractionError, " Passed data: ", encodedClaims)
+			slog.Warn("JWTTokenValidator: error during extraction of claims", "error", claimsExtractionError, "encoded_claims", encodedClaims)
 			j.ValidationError = claimsExtractionError
 			return
 		}
 		j.AccessTokenClaims = accessTokenClaims
-	} else if j.tokenKey == refreshTokenKey {
+	} else if j.tokenType == RefreshTokenType {
 		refreshTokenClaims, claimsExtractionError := pkg.ExtractRefreshTokenClaims(encodedClaims)
 		if claimsExtractionError != nil {
-			log.Println("JWTTokenValidator: ", claimsExtractionError, " Passed data: ", encodedClaims)
+			slog.Warn("JWTTokenValidator: error during extraction of claims", "error", claimsExtractionError, "encoded_claims", encodedClaims)
 			j.ValidationError = claimsExtractionError
 			return
 		}
@@ -386,19 +869,18 @@ func (j *JWTTokenValidator) ValidateToken() {
 	now := time.Now()
 
 	// security check
-	emptyAccessTokenClaims := pkg.AccessTokenClaims{}
-	if j.AccessTokenClaims != emptyAccessTokenClaims {
+	if j.tokenType == AccessTokenType {
 		// check if not expired
 		accessTokenExpirationTime := time.Unix(int64(j.AccessTokenClaims.ExpirationTime), 0)
 		if accessTokenExpirationTime.Before(now) {
-			log.Println("JWTTokenValidator: ", TokenIsExpiredError, " Passed data: ", j.AccessTokenClaims)
+			slog.Warn("JWTTokenValidator: "+TokenIsExpiredError, "claims", j.AccessTokenClaims)
 			j.IsExpired = true
 			j.ValidationError = errors.New(TokenIsExpiredError)
 			return
 		}
-		// check if token came from original identity (same IP)
-		if j.AccessTokenClaims.OriginatingIdentity.IP != j.SentFromIdentity.IP {
-			log.Println("JWTTokenValidator: ", SentNotFromOriginatingIdentityError, " Passed data: ", j.SentFromIdentity.IP)
+		// check if token came from original identity (IP address, network or client - by token binding)
+		if !j.binding.Matches(j.AccessTokenClaims.OriginatingIdentity, j.SentFromIdentity) {
+			slog.Warn("JWTTokenValidator: "+SentNotFromOriginatingIdentityError, "ip_address", j.SentFromIdentity.IP)
 			j.ValidationError = errors.New(SentNotFromOriginatingIdentityError)
 			return
 		}
@@ -406,14 +888,14 @@ func (j *JWTTokenValidator) ValidateToken() {
 		// check if not expired
 		refreshTokenExpirationTime := time.Unix(int64(j.RefreshTokenClaims.ExpirationTime), 0)
 		if refreshTokenExpirationTime.Before(now) {
-			log.Println("JWTTokenValidator: ", TokenIsExpiredError, " Passed data: ", j.RefreshTokenClaims)
+			slog.Warn("JWTTokenValidator: "+TokenIsExpiredError, "claims", j.RefreshTokenClaims)
 			j.IsExpired = true
 			j.ValidationError = errors.New(TokenIsExpiredError)
 			return
 		}
-		// check if token came from original identity (same IP)
-		if j.RefreshTokenClaims.OriginatingIdentity.IP != j.SentFromIdentity.IP {
-			log.Println("JWTTokenValidator: ", SentNotFromOriginatingIdentityError, " Passed data: ", j.SentFromIdentity.IP)
+		// check if token came from original identity (IP address, network or client - by token binding)
+		if !j.binding.Matches(j.RefreshTokenClaims.OriginatingIdentity, j.SentFromIdentity) {
+			slog.Warn("JWTTokenValidator: "+SentNotFromOriginatingIdentityError, "ip_address", j.SentFromIdentity.IP)
 			j.ValidationError = errors.New(SentNotFromOriginatingIdentityError)
 			return
 		}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/notify"
+	"go-booking-system/pkg"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// userTokenSize bytes of secrets sent by email - printed as hex
+	userTokenSize = 32
+
+	InvalidUserTokenError  = "link is not valid, expired or already used"
+	CurrentPasswordError   = "current password is not valid"
+	UsernameIsTakenError   = "username is taken"
+	DirectoryUsernameError = "username of directory user is changed in the directory"
+)
+
+// AccountService self-service of accounts: registration with email verification, password reset and profile of the
+// caller. Responses of requests by email are the same for unknown and known addresses - they do not reveal which
+// accounts exist
+type AccountService struct {
+	userRepository      database.UserRepository
+	userTokenRepository database.UserTokenRepository
+	authService         AuthServiceInterface
+	notifier            notify.Notifier
+
+	config AuthConfig
+}
+
+func NewAccountService(config AuthConfig, userRepository database.UserRepository, userTokenRepository database.UserTokenRepository, authService AuthServiceInterface, notifier notify.Notifier) *AccountService {
+	return &AccountService{
+		userRepository:      userRepository,
+		userTokenRepository: userTokenRepository,
+		authService:         authService,
+		notifier:            notifier,
+		config:              config,
+	}
+}
+
+// Register creates user with the default role and unverified email and sends link of verification. The user is created
+// even if the link is not sent - it can be requested again
+func (a *AccountService) Register(ctx context.Context, user models.User) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AccountService.Register")
+	defer span.End()
+
+	user.RoleId = DefaultRoleId
+	user.EmailVerified = false
+	createdUser, err := a.authService.Create(ctx, SystemUserId, user)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if err := a.sendEmailVerification(ctx, createdUser); err != nil {
+		slog.ErrorContext(ctx, "AccountService.Register(): error occured during sending of email verification", "user_id", createdUser.UserId, "error", err)
+	}
+
+	return createdUser, nil
+}
+
+// ResendEmailVerification sends new link of verification - previous links stop working
+func (a *AccountService) ResendEmailVerification(ctx context.Context, email string) error {
+	ctx, span := tracer.Start(ctx, "AccountService.ResendEmailVerification")
+	defer span.End()
+
+	user, err := a.userRepository.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user.UserId == 0 || user.EmailVerified {
+		slog.WarnContext(ctx, "AccountService.ResendEmailVerification(): no active user with unverified email", "passed_data", email)
+		return nil
+	}
+
+	return a.sendEmailVerification(ctx, user)
+}
+
+// VerifyEmail marks email of the token's user as verified
+func (a *AccountService) VerifyEmail(ctx context.Context, token string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AccountService.VerifyEmail")
+	defer span.End()
+
+	userToken, err := a.useUserToken(ctx, models.UserTokenEmailVerification, token)
+	if err != nil {
+		return models.User{}, err
+	}
+	if _, err := a.userRepository.UpdateEmailVerified(ctx, userToken.UserId, true); err != nil {
+		return models.User{}, err
+	}
+	slog.InfoContext(ctx, "AccountService.VerifyEmail(): email is verified", "user_id", userToken.UserId)
+
+	return a.userRepository.GetUserById(ctx, userToken.UserId)
+}
+
+// ForgotPassword sends link of password reset. Users of identity provider and directory have no local password - they
+// get nothing
+func (a *AccountService) ForgotPassword(ctx context.Context, email string) error {
+	ctx, span := tracer.Start(ctx, "AccountService.ForgotPassword")
+	defer span.End()
+
+	user, err := a.userRepository.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user.UserId == 0 {
+		slog.WarnContext(ctx, "AccountService.ForgotPassword(): no active user with the email", "passed_data", email)
+		return nil
+	}
+	if isExternalUser(user) || user.SSOSubject != "" {
+		slog.WarnContext(ctx, "AccountService.ForgotPassword(): password of the user is not kept by the application", "user_id", user.UserId)
+		return nil
+	}
+
+	token, err := a.issueUserToken(ctx, user, models.UserTokenPasswordReset, a.config.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return a.notifier.Send(ctx, notify.Message{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf("Hello, %s!\n\nTo set a new password open the link (valid for %s):\n%s\n\nIf you did not request it, ignore this message - the password is not changed.\n",
+			user.Name, a.config.PasswordResetTTL, a.link("reset-password", token)),
+	})
+}
+
+// ResetPassword sets new password by token of the link. The link proves the user owns the email - it becomes verified
+func (a *AccountService) ResetPassword(ctx context.Context, token string, password string) error {
+	ctx, span := tracer.Start(ctx, "AccountService.ResetPassword")
+	defer span.End()
+
+	userToken, err := a.useUserToken(ctx, models.UserTokenPasswordReset, token)
+	if err != nil {
+		return err
+	}
+	if _, err := a.authService.UpdatePassword(ctx, userToken.UserId, password); err != nil {
+		return err
+	}
+	if _, err := a.userRepository.UpdateEmailVerified(ctx, userToken.UserId, true); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "AccountService.ResetPassword(): password is reset", "user_id", userToken.UserId)
+
+	return nil
+}
+
+func (a *AccountService) GetProfile(ctx context.Context, userId int) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AccountService.GetProfile")
+	defer span.End()
+
+	return a.userRepository.GetUserById(ctx, userId)
+}
+
+// UpdateProfile changes name, email, telephone and time zone of the caller. Changed email is not verified until the
+// link sent to it is opened
+func (a *AccountService) UpdateProfile(ctx context.Context, user models.User) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AccountService.UpdateProfile")
+	defer span.End()
+
+	currentUser, err := a.userRepository.GetUserById(ctx, user.UserId)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	profile := models.User{UserId: user.UserId, Name: user.Name, Email: user.Email, Telephone: user.Telephone, TimeZone: user.TimeZone}
+	if _, err := a.userRepository.Update(ctx, profile); err != nil {
+		return models.User{}, err
+	}
+
+	if !strings.EqualFold(currentUser.Email, user.Email) {
+		if _, err := a.userRepository.UpdateEmailVerified(ctx, user.UserId, false); err != nil {
+			return models.User{}, err
+		}
+		updatedUser, err := a.userRepository.GetUserById(ctx, user.UserId)
+		if err != nil {
+			return models.User{}, err
+		}
+		if err := a.sendEmailVerification(ctx, updatedUser); err != nil {
+			slog.ErrorContext(ctx, "AccountService.UpdateProfile(): error occured during sending of email verification", "user_id", user.UserId, "error", err)
+		}
+	}
+
+	return a.userRepository.GetUserById(ctx, user.UserId)
+}
+
+// ChangePassword sets new password of the caller. A stolen access token is not enough - current password is checked
+func (a *AccountService) ChangePassword(ctx context.Context, userId int, currentPassword string, newPassword string) error {
+	ctx, span := tracer.Start(ctx, "AccountService.ChangePassword")
+	defer span.End()
+
+	user, err := a.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if isExternalUser(user) {
+		return errors.New(DirectoryPasswordError)
+	}
+	if err := a.checkCurrentPassword(ctx, user, currentPassword); err != nil {
+		return err
+	}
+
+	if _, err := a.authService.UpdatePassword(ctx, userId, newPassword); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "AccountService.ChangePassword(): password is changed", "user_id", userId)
+
+	return nil
+}
+
+// ChangeUsername sets new username of the caller after the password is checked
+func (a *AccountService) ChangeUsername(ctx context.Context, userId int, password string, username string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "AccountService.ChangeUsername")
+	defer span.End()
+
+	user, err := a.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return models.User{}, err
+	}
+	if isExternalUser(user) {
+		return models.User{}, errors.New(DirectoryUsernameError)
+	}
+	if err := a.checkCurrentPassword(ctx, user, password); err != nil {
+		return models.User{}, err
+	}
+
+	foundUser, err := a.userRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return models.User{}, err
+	}
+	if foundUser.UserId != 0 && foundUser.UserId != userId {
+		slog.WarnContext(ctx, "AccountService.ChangeUsername(): username is taken", "user_id", userId, "passed_data", username)
+		return models.User{}, errors.New(UsernameIsTakenError)
+	}
+
+	if _, err := a.authService.UpdateUsername(ctx, userId, username); err != nil {
+		return models.User{}, err
+	}
+	slog.InfoContext(ctx, "AccountService.ChangeUsername(): username is changed", "user_id", userId, "user_name", username)
+
+	return a.userRepository.GetUserById(ctx, userId)
+}
+
+// checkCurrentPassword checks password the same way as log in does. Users of identity provider have no password they know
+func (a *AccountService) checkCurrentPassword(ctx context.Context, user models.User, password string) error {
+	checkedUser, err := a.authService.CheckIfUserExistsAndPasswordIsCorrect(ctx, user.UserName, password)
+	if err != nil && err.Error() != EmailNotVerifiedError {
+		if err.Error() == InvalidCredentialsError {
+			return errors.New(CurrentPasswordError)
+		}
+		return err
+	}
+	if err == nil && checkedUser.UserId != user.UserId {
+		return errors.New(CurrentPasswordError)
+	}
+
+	return nil
+}
+
+func (a *AccountService) sendEmailVerification(ctx context.Context, user models.User) error {
+	token, err := a.issueUserToken(ctx, user, models.UserTokenEmailVerification, a.config.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return a.notifier.Send(ctx, notify.Message{
+		To:      user.Email,
+		Subject: "Email verification",
+		Body: fmt.Sprintf("Hello, %s!\n\nTo confirm your email open the link (valid for %s):\n%s\n",
+			user.Name, a.config.EmailVerificationTTL, a.link("verify-email", token)),
+	})
+}
+
+// issueUserToken returns secret of the link. Only its hash is stored, previous unused tokens of the purpose are removed
+func (a *AccountService) issueUserToken(ctx context.Context, user models.User, purpose string, ttl time.Duration) (string, error) {
+	token, err := pkg.GenerateRandomToken(userTokenSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "AccountService.issueUserToken(): error occured during token generation", "error", err)
+		return "", err
+	}
+
+	if _, err := a.userTokenRepository.ReplaceUserToken(ctx, models.UserToken{
+		UserId:    user.UserId,
+		Purpose:   purpose,
+		TokenHash: pkg.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (a *AccountService) useUserToken(ctx context.Context, purpose string, token string) (models.UserToken, error) {
+	if token == "" {
+		return models.UserToken{}, errors.New(InvalidUserTokenError)
+	}
+
+	userToken, err := a.userTokenRepository.UseUserToken(ctx, purpose, pkg.HashToken(token))
+	if err != nil {
+		return models.UserToken{}, err
+	}
+	if userToken.UserTokenId == 0 {
+		slog.WarnContext(ctx, "AccountService.useUserToken(): token is not valid, expired or already used", "purpose", purpose)
+		return models.UserToken{}, errors.New(InvalidUserTokenError)
+	}
+
+	return userToken, nil
+}
+
+// link URL of page of the application the token is sent to
+func (a *AccountService) link(page string, token string) string {
+	return strings.TrimRight(a.config.PublicURL, "/") + "/" + page + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	passCodeSize = 4 // bytes - 8 hex characters
+
+	// VisitorPassEarlyArrival how long before booking start guest is let into the building
+	VisitorPassEarlyArrival = 30 * time.Minute
+)
+
+type AttendeeService struct {
+	repository database.AttendeeRepository
+
+	bookingService BookingServiceInterface
+	userService    UserServiceInterface
+}
+
+func NewAttendeeService(repository database.AttendeeRepository, bookingService BookingServiceInterface, userService UserServiceInterface) *AttendeeService {
+	return &AttendeeService{repository: repository, bookingService: bookingService, userService: userService}
+}
+
+// AddAttendees invites internal users (by user_id) and external guests (by name and email) to the booking.
+// Guests get visitor pass codes. Capacity of the room is checked for all attendees at once
+func (a *AttendeeService) AddAttendees(ctx context.Context, bookingId int, attendees []models.Attendee, createdBy int) ([]models.Attendee, error) {
+	ctx, span := tracer.Start(ctx, "AttendeeService.AddAttendees")
+	defer span.End()
+
+	if len(attendees) == 0 {
+		return nil, errors.New("no attendees were passed")
+	}
+
+	booking, err := a.bookingService.GetBookingById(ctx, bookingId)
+	if err != nil {
+		return nil, err
+	}
+
+	existingAttendees, err := a.repository.GetAttendeesByBookingId(ctx, bookingId)
+	if err != nil {
+		return nil, err
+	}
+
+	attendeesToCreate := make([]models.Attendee, 0, len(attendees))
+	for _, attendee := range attendees {
+		attendee, err = a.prepareAttendee(ctx, booking, attendee, createdBy)
+		if err != nil {
+			return nil, err
+		}
+		if isAlreadyInvited(attendee, existingAttendees, attendeesToCreate) {
+			return nil, fmt.Errorf("attendee is already invited. Passed data: user_id=%d email='%s'", attendee.UserId, attendee.Email)
+		}
+
+		attendeesToCreate = append(attendeesToCreate, attendee)
+	}
+
+	if err = a.bookingService.CheckCapacity(ctx, booking.RoomId, bookingId, len(attendeesToCreate)); err != nil {
+		return nil, err
+	}
+
+	return a.repository.CreateAttendees(ctx, attendeesToCreate)
+}
+
+func (a *AttendeeService) GetAttendees(ctx context.Context, bookingId int) ([]models.Attendee, error) {
+	ctx, span := tracer.Start(ctx, "AttendeeService.GetAttendees")
+	defer span.End()
+
+	return a.repository.GetAttendeesByBookingId(ctx, bookingId)
+}
+
+func (a *AttendeeService) RemoveAttendee(ctx context.Context, bookingId int, attendeeId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "AttendeeService.RemoveAttendee")
+	defer span.End()
+
+	attendee, err := a.repository.GetAttendeeById(ctx, attendeeId)
+	if err != nil {
+		return false, err
+	}
+	if attendee.BookingId != bookingId {
+		return false, fmt.Errorf("attendee is not invited to the booking. Passed data: booking_id=%d attendee_id=%d", bookingId, attendeeId)
+	}
+
+	return a.repository.Delete(ctx, attendeeId)
+}
+
+// Respond sets response of invited user. Seat is taken back only if room still has free places
+func (a *AttendeeService) Respond(ctx context.Context, bookingId int, userId int, status string) (models.Attendee, error) {
+	ctx, span := tracer.Start(ctx, "AttendeeService.Respond")
+	defer span.End()
+
+	if status != models.AttendeeStatusAccepted && status != models.AttendeeStatusDeclined {
+		return models.Attendee{}, fmt.Errorf("status should be '%s' or '%s'. Passed data: '%s'", models.AttendeeStatusAccepted, models.AttendeeStatusDeclined, status)
+	}
+
+	attendee, err := a.repository.GetAttendeeByBookingIdAndUserId(ctx, bookingId, userId)
+	if err != nil {
+		return models.Attendee{}, err
+	}
+
+	if attendee.Status == models.AttendeeStatusDeclined && status == models.AttendeeStatusAccepted {
+		booking, err := a.bookingService.GetBookingById(ctx, bookingId)
+		if err != nil {
+			return models.Attendee{}, err
+		}
+		if err = a.bookingService.CheckCapacity(ctx, booking.RoomId, bookingId, 1); err != nil {
+			return models.Attendee{}, err
+		}
+	}
+
+	attendee.Status = status
+	attendee.RespondedAt = time.Now()
+
+	return a.repository.UpdateStatus(ctx, attendee)
+}
+
+// GetInvitations returns bookings user is invited to
+func (a *AttendeeService) GetInvitations(ctx context.Context, userId int) ([]models.Booking, error) {
+	ctx, span := tracer.Start(ctx, "AttendeeService.GetInvitations")
+	defer span.End()
+
+	return a.repository.GetBookingsByAttendeeUserId(ctx, userId)
+}
+
+// IsAttendee errors other than absence of the invitation (e.g. of the database) are returned - access is not decided
+// without knowing it
+func (a *AttendeeService) IsAttendee(ctx context.Context, bookingId int, userId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "AttendeeService.IsAttendee")
+	defer span.End()
+
+	_, err := a.repository.GetAttendeeByBookingIdAndUserId(ctx, bookingId, userId)
+	if errors.Is(err, repositories.ErrAttendeeNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetVisitorPasses returns passes of guests expected in [start; end] for reception
+func (a *AttendeeService) GetVisitorPasses(ctx context.Context, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.VisitorPass, error) {
+	ctx, span := tracer.Start(ctx, "AttendeeService.GetVisitorPasses")
+	defer span.End()
+
+	visitorPasses, err := a.repository.GetVisitorPasses(ctx, dateTimeStart, dateTimeEnd.Add(VisitorPassEarlyArrival))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range visitorPasses {
+		visitorPasses[i].ValidFrom = visitorPasses[i].ValidFrom.Add(-VisitorPassEarlyArrival)
+	}
+
+	return visitorPasses, nil
+}
+
+func (a *AttendeeService) GetVisitorPassByCode(ctx context.Context, passCode string) (models.VisitorPass, error) {
+	ctx, span := tracer.Start(ctx, "AttendeeService.GetVisitorPassByCode")
+	defer span.End()
+
+	visitorPass, err := a.repository.GetVisitorPassByCode(ctx, strings.ToUpper(passCode))
+	if err != nil {
+		return models.VisitorPass{}, err
+	}
+
+	visitorPass.ValidFrom = visitorPass.ValidFrom.Add(-VisitorPassEarlyArrival)
+	return visitorPass, nil
+}
+
+func (a *AttendeeService) prepareAttendee(ctx context.Context, booking models.Booking, attendee models.Attendee, createdBy int) (models.Attendee, error) {
+	attendee.AttendeeId = 0
+	attendee.BookingId = booking.BookingId
+	attendee.Status = models.AttendeeStatusPending
+	attendee.RespondedAt = time.Time{}
+	attendee.PassCode = ""
+	attendee.Active = true
+	attendee.CreatedBy = createdBy
+
+	// internal user - contacts are taken from profile
+	if !attendee.IsGuest() {
+		if attendee.UserId == booking.UserId {
+			return models.Attendee{}, fmt.Errorf("room is booked for the user - no need to invite. Passed data: user_id=%d", attendee.UserId)
+		}
+
+		user, err := a.userService.GetUserById(ctx, attendee.UserId)
+		if err != nil {
+			return models.Attendee{}, err
+		}
+		attendee.Name = user.Name
+		attendee.Email = user.Email
+
+		return attendee, nil
+	}
+
+	// external guest
+	attendee.Name = strings.TrimSpace(attendee.Name)
+	attendee.Email = strings.ToLower(strings.TrimSpace(attendee.Email))
+	if attendee.Name == "" || !strings.Contains(attendee.Email, "@") {
+		return models.Attendee{}, fmt.Errorf("guest should have name and email. Passed data: name='%s' email='%s'", attendee.Name, attendee.Email)
+	}
+
+	passCode, err := pkg.GenerateRandomToken(passCodeSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "AttendeeService.prepareAttendee(): error occured during pass code generation")
+		return models.Attendee{}, err
+	}
+	attendee.PassCode = strings.ToUpper(passCode)
+
+	return attendee, nil
+}
+
+func isAlreadyInvited(attendee models.Attendee, invitedAttendees ...[]models.Attendee) bool {
+	for _, attendees := range invitedAttendees {
+		for _, invitedAttendee := range attendees {
+			if !attendee.IsGuest() && invitedAttendee.UserId == attendee.UserId {
+				return true
+			}
+			if attendee.IsGuest() && invitedAttendee.IsGuest() && invitedAttendee.Email == attendee.Email {
+				return true
+			}
+		}
+	}
+
+	return false
+}
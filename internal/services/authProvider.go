@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/directory"
+	"go-booking-system/internal/models"
+	"log/slog"
+)
+
+const (
+	// AuthProviderLocal password hash is kept by the application
+	AuthProviderLocal = "local"
+	// AuthProviderLDAP password is checked by bind to LDAP / Active Directory
+	AuthProviderLDAP = "ldap"
+	// AuthProviderServiceAccount user of integration - no provider is registered, it is authenticated only by API keys
+	AuthProviderServiceAccount = "service_account"
+
+	DirectoryPasswordError = "password of directory user is changed in the directory"
+)
+
+// AuthenticationProvider checks password of users whose accounts are kept outside of the application. Wrong password is
+// reported by directory.ErrInvalidCredentials, other errors - provider is not available
+type AuthenticationProvider interface {
+	Authenticate(ctx context.Context, user models.User, password string) error
+}
+
+// LDAPAuthenticationProvider checks password by bind as the user's entry of the directory
+type LDAPAuthenticationProvider struct {
+	client *directory.Client
+}
+
+func NewLDAPAuthenticationProvider(client *directory.Client) *LDAPAuthenticationProvider {
+	return &LDAPAuthenticationProvider{client: client}
+}
+
+func (p *LDAPAuthenticationProvider) Authenticate(ctx context.Context, user models.User, password string) error {
+	ctx, span := tracer.Start(ctx, "LDAPAuthenticationProvider.Authenticate")
+	defer span.End()
+
+	entry, err := p.client.Authenticate(ctx, user.UserName, password)
+	if err != nil {
+		return err
+	}
+	// username was given to another entry of the directory after the last sync
+	if entry.Id != user.DirectoryId {
+		slog.WarnContext(ctx, "LDAPAuthenticationProvider.Authenticate(): entry of the directory is not linked to the user", "user_id", user.UserId, "dn", entry.DN)
+		return directory.ErrInvalidCredentials
+	}
+
+	return nil
+}
+
+// isExternalUser password of the user is checked by authentication provider, not by the application
+func isExternalUser(user models.User) bool {
+	return user.AuthProvider != "" && user.AuthProvider != AuthProviderLocal
+}
+
+// isInvalidCredentials provider has rejected the password, it is not a failure of the provider
+func isInvalidCredentials(err error) bool {
+	return errors.Is(err, directory.ErrInvalidCredentials)
+}
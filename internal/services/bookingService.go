@@ -1,8 +1,12 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"go-booking-system/internal/database"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/metrics"
 	"go-booking-system/internal/models"
 	"sort"
 	"time"
@@ -13,46 +17,104 @@ const (
 	RoomIsAvailable    = true
 	NotOverlapping     = false
 	Overlapping        = true
+
+	// CheckInEarlyWindow how long before booking start attendee can check in
+	CheckInEarlyWindow = 10 * time.Minute
 )
 
 type BookingService struct {
 	repository database.BookingRepository
+
+	// to check opening hours of room's location
+	locationService LocationServiceInterface
+
+	// to check if room is visible to organization and quota of shared rooms
+	roomService         RoomServiceInterface
+	roomShareRepository database.RoomShareRepository
+
+	// to check capacity of the room
+	attendeeRepository database.AttendeeRepository
+
+	// organization of the caller. repositories.SystemOrganizationId - not limited
+	organizationId int
 }
 
-func NewBookingService(repository database.BookingRepository) *BookingService {
-	return &BookingService{repository: repository}
+func NewBookingService(repository database.BookingRepository, locationService LocationServiceInterface, roomService RoomServiceInterface, roomShareRepository database.RoomShareRepository, attendeeRepository database.AttendeeRepository, organizationId int) *BookingService {
+	return &BookingService{
+		repository:          repository,
+		locationService:     locationService,
+		roomService:         roomService,
+		roomShareRepository: roomShareRepository,
+		attendeeRepository:  attendeeRepository,
+		organizationId:      organizationId,
+	}
 }
 
-func (b *BookingService) GetAll() []models.Booking {
-	return b.repository.GetAll()
+func (b *BookingService) GetAll(ctx context.Context) []models.Booking {
+	ctx, span := tracer.Start(ctx, "BookingService.GetAll")
+	defer span.End()
+
+	return b.repository.GetAll(ctx)
 }
 
-func (b *BookingService) GetBookingById(bookingId int) (models.Booking, error) {
-	return b.repository.GetBookingById(bookingId)
+func (b *BookingService) GetBookingById(ctx context.Context, bookingId int) (models.Booking, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.GetBookingById")
+	defer span.End()
+
+	return b.repository.GetBookingById(ctx, bookingId)
 }
 
-func (b *BookingService) GetBookingsByRoomId(roomId int) ([]models.Booking, error) {
-	return b.repository.GetBookingsByRoomId(roomId)
+func (b *BookingService) GetBookingsByRoomId(ctx context.Context, roomId int) ([]models.Booking, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.GetBookingsByRoomId")
+	defer span.End()
+
+	return b.repository.GetBookingsByRoomId(ctx, roomId)
 }
 
-func (b *BookingService) GetBookingsByRoomIdAndBookingTime(roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Booking, error) {
-	return b.repository.GetBookingsByRoomIdAndBookingTime(roomId, dateTimeStart, dateTimeEnd)
+// GetBookingsByRoomIdAndBookingTime returns bookings of all organizations - schedule of a shared room is visible to all organizations it is shared with
+func (b *BookingService) GetBookingsByRoomIdAndBookingTime(ctx context.Context, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Booking, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.GetBookingsByRoomIdAndBookingTime")
+	defer span.End()
+
+	if err := b.CheckRoomAccess(ctx, roomId); err != nil {
+		return nil, err
+	}
+
+	return b.repository.GetBookingsByRoomIdAndBookingTime(ctx, roomId, dateTimeStart, dateTimeEnd)
 }
 
-func (b *BookingService) Update(booking models.Booking) (models.Booking, error) {
+func (b *BookingService) Update(ctx context.Context, booking models.Booking) (models.Booking, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.Update")
+	defer span.End()
+
+	// 0. Check if room is open during [start; end] in local time of the room
+	if err := b.CheckOpeningHours(ctx, booking.RoomId, booking.DateTimeStart, booking.DateTimeEnd); err != nil {
+		return models.Booking{}, err
+	}
+
+	// 0.1 Check quota if room is shared by another organization
+	if err := b.CheckRoomQuota(ctx, booking); err != nil {
+		return models.Booking{}, err
+	}
+
+	// 0.2 Check if all attendees fit into the room (room can be changed)
+	if err := b.CheckCapacity(ctx, booking.RoomId, booking.BookingId, 0); err != nil {
+		return models.Booking{}, err
+	}
+
 	// 1. Check if time slot [start; end] is available
-	isRoomAvailable, err := b.CheckIfRoomAvailable(booking.RoomId, booking.DateTimeStart, booking.DateTimeEnd)
+	isRoomAvailable, err := b.CheckIfRoomAvailable(ctx, booking.RoomId, booking.DateTimeStart, booking.DateTimeEnd)
 	if err != nil {
 		return models.Booking{}, err
 	}
 
 	// 2. If time slot [start; end] is available => update booking
 	if isRoomAvailable {
-		return b.repository.Update(booking)
+		return b.repository.Update(ctx, booking)
 	}
 
 	// 3. check overlapping bookings
-	overlappingBookings, err := b.repository.GetBookingsByRoomIdAndBookingTime(
+	overlappingBookings, err := b.repository.GetBookingsByRoomIdAndBookingTime(ctx,
 		booking.RoomId, booking.DateTimeStart, booking.DateTimeEnd)
 	if err != nil {
 		return models.Booking{}, err
@@ -63,15 +125,34 @@ func (b *BookingService) Update(booking models.Booking) (models.Booking, error)
 		return models.Booking{}, err
 	}
 
+	metrics.BookingConflictsTotal.Inc()
 	return models.Booking{}, NewOverlappingBookingsError("cannot update booking. Overlapping bookings exist.", overlappingBookings)
 }
 
-func (b *BookingService) Delete(bookingId int) (bool, error) {
-	return b.repository.Delete(bookingId)
+func (b *BookingService) Delete(ctx context.Context, bookingId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.Delete")
+	defer span.End()
+
+	return b.repository.Delete(ctx, bookingId)
 }
 
 // CheckIfRoomAvailable true - available; false - not available
-func (b *BookingService) CheckIfRoomAvailable(roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) (bool, error) {
+// Purge permanently removes bookings (with their attendees) which ended before the time. Upcoming bookings cannot be purged
+func (b *BookingService) Purge(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.Purge")
+	defer span.End()
+
+	if before.After(time.Now()) {
+		return 0, fmt.Errorf("bookings can be purged only before the current moment. Passed data: %s", before.Format(time.RFC3339))
+	}
+
+	return b.repository.DeleteEndedBefore(ctx, before)
+}
+
+func (b *BookingService) CheckIfRoomAvailable(ctx context.Context, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) (bool, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.CheckIfRoomAvailable")
+	defer span.End()
+
 	bookingToCheck := models.Booking{
 		RoomId:        roomId,
 		DateTimeStart: dateTimeStart,
@@ -79,7 +160,7 @@ func (b *BookingService) CheckIfRoomAvailable(roomId int, dateTimeStart time.Tim
 	}
 
 	// 1. get overlapping bookings
-	overlappingBookings, err := b.GetOverlappingBookings(roomId, dateTimeStart, dateTimeEnd)
+	overlappingBookings, err := b.GetOverlappingBookings(ctx, roomId, dateTimeStart, dateTimeEnd)
 	if err != nil {
 		return false, err
 	}
@@ -98,7 +179,10 @@ func (b *BookingService) CheckIfRoomAvailable(roomId int, dateTimeStart time.Tim
 	}
 }
 
-func (b *BookingService) BookRoom(userId int, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time, createdBy int) (models.Booking, error) {
+func (b *BookingService) BookRoom(ctx context.Context, userId int, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time, createdBy int) (models.Booking, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.BookRoom")
+	defer span.End()
+
 	bookingToCreate := models.Booking{
 		UserId:        userId,
 		RoomId:        roomId,
@@ -107,21 +191,217 @@ func (b *BookingService) BookRoom(userId int, roomId int, dateTimeStart time.Tim
 		CreatedBy:     createdBy,
 	}
 
+	// 0. Check if room is open during [start; end] in local time of the room
+	if err := b.CheckOpeningHours(ctx, roomId, dateTimeStart, dateTimeEnd); err != nil {
+		return models.Booking{}, err
+	}
+
+	// 0.1 Check quota if room is shared by another organization
+	if err := b.CheckRoomQuota(ctx, bookingToCreate); err != nil {
+		return models.Booking{}, err
+	}
+
 	// 1. Check if it is possible to book Room in the given timeframe [start; end]
-	available, err := b.CheckIfRoomAvailable(roomId, dateTimeStart, dateTimeEnd)
+	available, err := b.CheckIfRoomAvailable(ctx, roomId, dateTimeStart, dateTimeEnd)
 	if err != nil {
+		var overlappingBookingsError *OverlappingBookingsError
+		if errors.As(err, &overlappingBookingsError) {
+			metrics.BookingConflictsTotal.Inc()
+		}
 		return models.Booking{}, err
 	}
 	// 2. if room is available during [start; end]
 	if available {
-		return b.repository.Create(bookingToCreate)
+		createdBooking, err := b.repository.Create(ctx, bookingToCreate)
+		if err == nil {
+			metrics.BookingsCreatedTotal.Inc()
+		}
+		return createdBooking, err
 	}
 
 	return models.Booking{}, fmt.Errorf("room booking ended with an error. Passed data: %v", bookingToCreate)
 }
 
-func (b *BookingService) GetOverlappingBookings(roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Booking, error) {
-	return b.repository.GetBookingsByRoomIdAndBookingTime(roomId, dateTimeStart, dateTimeEnd)
+// GetCurrentAndNextBookings returns booking which is going on in the room at `at` (nil if room is free) and the closest next one
+func (b *BookingService) GetCurrentAndNextBookings(ctx context.Context, roomId int, at time.Time) (current *models.Booking, next *models.Booking, err error) {
+	ctx, span := tracer.Start(ctx, "BookingService.GetCurrentAndNextBookings")
+	defer span.End()
+
+	if err = b.CheckRoomAccess(ctx, roomId); err != nil {
+		return nil, nil, err
+	}
+
+	upcomingBookings, err := b.repository.GetUpcomingBookingsByRoomId(ctx, roomId, at, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range upcomingBookings {
+		booking := upcomingBookings[i]
+		if !booking.DateTimeStart.After(at) && current == nil {
+			current = &booking
+			continue
+		}
+		if next == nil {
+			next = &booking
+		}
+	}
+
+	return current, next, nil
+}
+
+// CheckIn marks booking which is going on (or starts within CheckInEarlyWindow) in the room as checked in
+func (b *BookingService) CheckIn(ctx context.Context, roomId int, at time.Time) (models.Booking, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.CheckIn")
+	defer span.End()
+
+	current, next, err := b.GetCurrentAndNextBookings(ctx, roomId, at)
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	bookingToCheckIn := current
+	if bookingToCheckIn == nil && next != nil && !next.DateTimeStart.After(at.Add(CheckInEarlyWindow)) {
+		bookingToCheckIn = next
+	}
+	if bookingToCheckIn == nil {
+		return models.Booking{}, fmt.Errorf("no booking to check in. Passed data: room_id=%d at=%s", roomId, at.Format(time.RFC3339))
+	}
+	if !bookingToCheckIn.CheckedInAt.IsZero() {
+		return *bookingToCheckIn, nil
+	}
+
+	return b.repository.CheckIn(ctx, bookingToCheckIn.BookingId, at)
+}
+
+func (b *BookingService) CheckOpeningHours(ctx context.Context, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) error {
+	ctx, span := tracer.Start(ctx, "BookingService.CheckOpeningHours")
+	defer span.End()
+
+	isOpen, err := b.locationService.IsRoomOpen(ctx, roomId, dateTimeStart, dateTimeEnd)
+	if err != nil {
+		return err
+	}
+	if isOpen == false {
+		return fmt.Errorf("room is closed during requested time. Passed data: room_id=%d %s - %s", roomId, dateTimeStart.Format(time.RFC3339), dateTimeEnd.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// GetOverlappingBookings looks through bookings of all organizations - room cannot be double-booked by organizations sharing it
+func (b *BookingService) GetOverlappingBookings(ctx context.Context, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Booking, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.GetOverlappingBookings")
+	defer span.End()
+
+	if err := b.CheckRoomAccess(ctx, roomId); err != nil {
+		return nil, err
+	}
+
+	return b.repository.GetBookingsByRoomIdAndBookingTime(ctx, roomId, dateTimeStart, dateTimeEnd)
+}
+
+// CheckCapacity checks if booker, attendees who haven't declined and `additionalAttendees` fit into the room
+func (b *BookingService) CheckCapacity(ctx context.Context, roomId int, bookingId int, additionalAttendees int) error {
+	ctx, span := tracer.Start(ctx, "BookingService.CheckCapacity")
+	defer span.End()
+
+	room, err := b.roomService.GetRoomById(ctx, roomId)
+	if err != nil {
+		return err
+	}
+
+	attendingCount := 0
+	if bookingId != 0 {
+		attendingCount, err = b.attendeeRepository.CountAttendingByBookingId(ctx, bookingId)
+		if err != nil {
+			return err
+		}
+	}
+
+	// booker takes a place too
+	requiredPlaces := 1 + attendingCount + additionalAttendees
+	if requiredPlaces > room.Capacity {
+		return fmt.Errorf("room capacity is exceeded: %d places are required, room has %d. Passed data: room_id=%d", requiredPlaces, room.Capacity, roomId)
+	}
+
+	return nil
+}
+
+// CheckRoomAccess checks if room is owned by or shared with caller's organization
+func (b *BookingService) CheckRoomAccess(ctx context.Context, roomId int) error {
+	ctx, span := tracer.Start(ctx, "BookingService.CheckRoomAccess")
+	defer span.End()
+
+	if b.organizationId == repositories.SystemOrganizationId {
+		return nil
+	}
+
+	if _, err := b.roomService.GetRoomById(ctx, roomId); err != nil {
+		return fmt.Errorf("room is not available for organization. Passed data: room_id=%d", roomId)
+	}
+
+	return nil
+}
+
+// CheckRoomQuota checks if organization has not exceeded its monthly quota of the shared room.
+// Month is taken in local time of the room
+func (b *BookingService) CheckRoomQuota(ctx context.Context, booking models.Booking) error {
+	ctx, span := tracer.Start(ctx, "BookingService.CheckRoomQuota")
+	defer span.End()
+
+	if b.organizationId == repositories.SystemOrganizationId {
+		return nil
+	}
+
+	room, err := b.roomService.GetRoomById(ctx, booking.RoomId)
+	if err != nil {
+		return fmt.Errorf("room is not available for organization. Passed data: room_id=%d", booking.RoomId)
+	}
+	// own rooms are not limited
+	if room.OrganizationId == b.organizationId {
+		return nil
+	}
+
+	roomShare, err := b.roomShareRepository.GetRoomShare(ctx, booking.RoomId, b.organizationId)
+	if err != nil {
+		return fmt.Errorf("room is not shared with organization. Passed data: room_id=%d", booking.RoomId)
+	}
+	if roomShare.QuotaMinutes == 0 {
+		return nil
+	}
+
+	timeZone, err := b.locationService.GetTimeZoneByRoomId(ctx, booking.RoomId)
+	if err != nil {
+		return err
+	}
+	localStart := booking.DateTimeStart.In(timeZone)
+	monthStart := time.Date(localStart.Year(), localStart.Month(), 1, 0, 0, 0, 0, timeZone)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	bookedMinutes, err := b.roomShareRepository.GetBookedMinutes(ctx, booking.RoomId, b.organizationId, monthStart, monthEnd)
+	if err != nil {
+		return err
+	}
+
+	// booking being updated is already counted
+	if booking.BookingId != 0 {
+		existingBooking, err := b.repository.GetBookingById(ctx, booking.BookingId)
+		if err != nil {
+			return err
+		}
+		if existingBooking.RoomId == booking.RoomId && !existingBooking.DateTimeStart.Before(monthStart) && existingBooking.DateTimeStart.Before(monthEnd) {
+			bookedMinutes -= int(existingBooking.DateTimeEnd.Sub(existingBooking.DateTimeStart).Minutes())
+		}
+	}
+
+	requestedMinutes := int(booking.DateTimeEnd.Sub(booking.DateTimeStart).Minutes())
+	if bookedMinutes+requestedMinutes > roomShare.QuotaMinutes {
+		return fmt.Errorf("monthly quota of the shared room is exceeded: %d of %d minutes are booked, %d requested. Passed data: room_id=%d",
+			bookedMinutes, roomShare.QuotaMinutes, requestedMinutes, booking.RoomId)
+	}
+
+	return nil
 }
 
 func (b *BookingService) IsOverlapping(bookingToCheck models.Booking, overlapingBookings ...models.Booking) (bool, error) {
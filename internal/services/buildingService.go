@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/models"
+)
+
+type BuildingService struct {
+	repository database.BuildingRepository
+}
+
+func NewBuildingService(repository database.BuildingRepository) *BuildingService {
+	return &BuildingService{repository: repository}
+}
+
+func (b *BuildingService) Create(ctx context.Context, building models.Building) (models.Building, error) {
+	ctx, span := tracer.Start(ctx, "BuildingService.Create")
+	defer span.End()
+
+	return b.repository.Create(ctx, building)
+}
+
+func (b *BuildingService) GetAll(ctx context.Context) []models.Building {
+	ctx, span := tracer.Start(ctx, "BuildingService.GetAll")
+	defer span.End()
+
+	return b.repository.GetAll(ctx)
+}
+
+func (b *BuildingService) GetBuildingById(ctx context.Context, buildingId int) (models.Building, error) {
+	ctx, span := tracer.Start(ctx, "BuildingService.GetBuildingById")
+	defer span.End()
+
+	return b.repository.GetBuildingById(ctx, buildingId)
+}
+
+func (b *BuildingService) GetBuildingsByLocationId(ctx context.Context, locationId int) ([]models.Building, error) {
+	ctx, span := tracer.Start(ctx, "BuildingService.GetBuildingsByLocationId")
+	defer span.End()
+
+	return b.repository.GetBuildingsByLocationId(ctx, locationId)
+}
+
+func (b *BuildingService) Update(ctx context.Context, building models.Building) (models.Building, error) {
+	ctx, span := tracer.Start(ctx, "BuildingService.Update")
+	defer span.End()
+
+	return b.repository.Update(ctx, building)
+}
+
+func (b *BuildingService) Delete(ctx context.Context, buildingId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "BuildingService.Delete")
+	defer span.End()
+
+	return b.repository.Delete(ctx, buildingId)
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+)
+
+// BulkService import and export of users and rooms. Rows are validated by handlers, service matches them with existing records
+type BulkService struct {
+	userRepository database.UserRepository
+	roomRepository database.RoomRepository
+	authService    AuthServiceInterface
+	roomService    RoomServiceInterface
+
+	// organization of the caller. repositories.SystemOrganizationId - not limited
+	organizationId int
+}
+
+func NewBulkService(userRepository database.UserRepository, roomRepository database.RoomRepository, authService AuthServiceInterface, roomService RoomServiceInterface, organizationId int) *BulkService {
+	return &BulkService{userRepository: userRepository, roomRepository: roomRepository, authService: authService, roomService: roomService, organizationId: organizationId}
+}
+
+// ImportUser updates user matched by email or username, otherwise creates a new one (username and password are required).
+// Roles of existing users are changed and roles other than the default one are given to new users only if importedBy is
+// an administrator, as by `/user/role`. In dry-run mode only returns the action which would be performed
+func (b *BulkService) ImportUser(ctx context.Context, user models.User, importedBy int, dryRun bool) (string, models.User, error) {
+	ctx, span := tracer.Start(ctx, "BulkService.ImportUser")
+	defer span.End()
+
+	existingUser, err := b.findUser(ctx, user)
+	if err != nil {
+		return models.ImportActionError, user, err
+	}
+
+	if user.RoleId != existingUser.RoleId {
+		if err := b.authService.CheckRoleIsAvailable(ctx, user.RoleId); err != nil {
+			return models.ImportActionError, user, err
+		}
+	}
+
+	if existingUser.UserId == 0 {
+		if user.UserName == "" || user.Password == "" {
+			return models.ImportActionError, user, errors.New("username and password are required to create a user")
+		}
+		if dryRun {
+			// role of the importer is checked by Create - dry run reports it the same way
+			if user.RoleId != DefaultRoleId {
+				if err := b.authService.CheckRoleChangeIsAllowed(ctx, importedBy, user.UserId); err != nil {
+					return models.ImportActionError, user, err
+				}
+			}
+			return models.ImportActionCreate, user, nil
+		}
+
+		// imported by administrator - email is trusted
+		user.EmailVerified = true
+		createdUser, err := b.authService.Create(ctx, importedBy, user)
+		if err != nil {
+			return models.ImportActionError, user, err
+		}
+
+		return models.ImportActionCreate, createdUser, nil
+	}
+
+	user.UserId = existingUser.UserId
+	roleId := user.RoleId
+	isRoleChanged := roleId != existingUser.RoleId
+	if isRoleChanged {
+		if err := b.authService.CheckRoleChangeIsAllowed(ctx, importedBy, user.UserId); err != nil {
+			return models.ImportActionError, user, err
+		}
+	}
+	if dryRun {
+		return models.ImportActionUpdate, user, nil
+	}
+
+	// role, username and password are changed only by their own methods
+	username, password := user.UserName, user.Password
+	user.RoleId, user.UserName, user.Password = 0, "", ""
+
+	updatedUser, err := b.userRepository.Update(ctx, user)
+	if err != nil {
+		return models.ImportActionError, user, err
+	}
+	updatedUser.RoleId = existingUser.RoleId
+	if isRoleChanged {
+		if _, err := b.authService.ChangeRole(ctx, importedBy, user.UserId, roleId); err != nil {
+			return models.ImportActionError, user, err
+		}
+		updatedUser.RoleId = roleId
+	}
+	if username != "" && username != existingUser.UserName {
+		if _, err := b.authService.UpdateUsername(ctx, user.UserId, username); err != nil {
+			return models.ImportActionError, user, err
+		}
+		updatedUser.UserName = username
+	}
+	if password != "" {
+		if _, err := b.authService.UpdatePassword(ctx, user.UserId, password); err != nil {
+			return models.ImportActionError, user, err
+		}
+	}
+
+	return models.ImportActionUpdate, updatedUser, nil
+}
+
+// findUser returns empty user if nobody has email or username of the imported user
+func (b *BulkService) findUser(ctx context.Context, user models.User) (models.User, error) {
+	userByEmail, err := b.userRepository.GetUserByEmail(ctx, user.Email)
+	if err != nil {
+		return models.User{}, err
+	}
+	if user.UserName == "" {
+		return userByEmail, nil
+	}
+
+	userByUsername, err := b.userRepository.GetUserByUsername(ctx, user.UserName)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if userByEmail.UserId != 0 && userByUsername.UserId != 0 && userByEmail.UserId != userByUsername.UserId {
+		return models.User{}, errors.New("email and username belong to different users")
+	}
+	if userByEmail.UserId != 0 {
+		return userByEmail, nil
+	}
+
+	return userByUsername, nil
+}
+
+// ImportRoom updates room of the organization with the same number, otherwise creates a new one
+func (b *BulkService) ImportRoom(ctx context.Context, room models.Room, dryRun bool) (string, models.Room, error) {
+	ctx, span := tracer.Start(ctx, "BulkService.ImportRoom")
+	defer span.End()
+
+	existingRoom, err := b.roomRepository.GetRoomByNumber(ctx, room.Number)
+	if err != nil {
+		return models.ImportActionError, room, err
+	}
+
+	if existingRoom.RoomId == 0 {
+		if dryRun {
+			return models.ImportActionCreate, room, nil
+		}
+
+		createdRoom, err := b.roomService.Create(ctx, room)
+		if err != nil {
+			return models.ImportActionError, room, err
+		}
+
+		return models.ImportActionCreate, createdRoom, nil
+	}
+
+	room.RoomId = existingRoom.RoomId
+	room.CreatedBy = 0 // author of the room is kept
+	if dryRun {
+		return models.ImportActionUpdate, room, nil
+	}
+
+	updatedRoom, err := b.roomService.Update(ctx, room)
+	if err != nil {
+		return models.ImportActionError, room, err
+	}
+
+	return models.ImportActionUpdate, updatedRoom, nil
+}
+
+// ExportUsers active users of the organization
+func (b *BulkService) ExportUsers(ctx context.Context) []models.User {
+	ctx, span := tracer.Start(ctx, "BulkService.ExportUsers")
+	defer span.End()
+
+	activeUsers := make([]models.User, 0)
+	for _, user := range b.userRepository.GetAll(ctx) {
+		if user.Active {
+			activeUsers = append(activeUsers, user)
+		}
+	}
+
+	return activeUsers
+}
+
+// ExportRooms active rooms owned by the organization - rooms shared with it are not exported
+func (b *BulkService) ExportRooms(ctx context.Context) []models.Room {
+	ctx, span := tracer.Start(ctx, "BulkService.ExportRooms")
+	defer span.End()
+
+	ownRooms := make([]models.Room, 0)
+	for _, room := range b.roomRepository.GetAll(ctx) {
+		if !room.Active {
+			continue
+		}
+		if b.organizationId != repositories.SystemOrganizationId && room.OrganizationId != b.organizationId {
+			continue
+		}
+		ownRooms = append(ownRooms, room)
+	}
+
+	return ownRooms
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/metrics"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DisplayDeviceRoleId  = 6 // restricted role: only `/display/...` routes
+	deviceTokenSize      = 32
+	DefaultBookNowPeriod = 30 * time.Minute
+	MinBookNowPeriod     = 10 * time.Minute
+)
+
+type DeviceService struct {
+	repository database.DeviceRepository
+
+	bookingService BookingServiceInterface
+	roomService    RoomServiceInterface
+}
+
+func NewDeviceService(repository database.DeviceRepository, bookingService BookingServiceInterface, roomService RoomServiceInterface) *DeviceService {
+	return &DeviceService{repository: repository, bookingService: bookingService, roomService: roomService}
+}
+
+// Register creates device for the room and returns its token. Token is shown only once - only its hash is stored
+func (d *DeviceService) Register(ctx context.Context, device models.Device) (models.Device, string, error) {
+	ctx, span := tracer.Start(ctx, "DeviceService.Register")
+	defer span.End()
+
+	if _, err := d.roomService.GetRoomById(ctx, device.RoomId); err != nil {
+		return models.Device{}, "", err
+	}
+
+	secret, err := pkg.GenerateRandomToken(deviceTokenSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "DeviceService.Register(): error occured during token generation")
+		return models.Device{}, "", err
+	}
+
+	if device.RoleId == 0 {
+		device.RoleId = DisplayDeviceRoleId
+	}
+	device.TokenHash = pkg.HashToken(secret)
+	device.Active = true
+
+	createdDevice, err := d.repository.Create(ctx, device)
+	if err != nil {
+		return models.Device{}, "", err
+	}
+
+	// device id is a part of token to find device without scanning all hashes
+	deviceToken := fmt.Sprintf("%d.%s", createdDevice.DeviceId, secret)
+
+	return createdDevice, deviceToken, nil
+}
+
+func (d *DeviceService) GetAll(ctx context.Context) []models.Device {
+	ctx, span := tracer.Start(ctx, "DeviceService.GetAll")
+	defer span.End()
+
+	return d.repository.GetAll(ctx)
+}
+
+func (d *DeviceService) GetDeviceById(ctx context.Context, deviceId int) (models.Device, error) {
+	ctx, span := tracer.Start(ctx, "DeviceService.GetDeviceById")
+	defer span.End()
+
+	return d.repository.GetDeviceById(ctx, deviceId)
+}
+
+func (d *DeviceService) Revoke(ctx context.Context, deviceId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "DeviceService.Revoke")
+	defer span.End()
+
+	return d.repository.Delete(ctx, deviceId)
+}
+
+// Authenticate checks device token in format `<device_id>.<secret>`
+func (d *DeviceService) Authenticate(ctx context.Context, deviceToken string) (models.Device, error) {
+	ctx, span := tracer.Start(ctx, "DeviceService.Authenticate")
+	defer span.End()
+
+	deviceIdString, secret, found := strings.Cut(deviceToken, ".")
+	if !found || secret == "" {
+		return models.Device{}, errors.New("wrong device token format")
+	}
+
+	deviceId, conversionError := strconv.Atoi(deviceIdString)
+	if conversionError != nil {
+		return models.Device{}, errors.New("wrong device token format")
+	}
+
+	foundDevice, err := d.repository.GetDeviceById(ctx, deviceId)
+	if err != nil {
+		slog.WarnContext(ctx, "DeviceService.Authenticate(): device not found", "passed_data", deviceId)
+		return models.Device{}, errors.New("invalid device token")
+	}
+
+	if !foundDevice.Active || !pkg.CompareTokenHash(secret, foundDevice.TokenHash) {
+		slog.WarnContext(ctx, "DeviceService.Authenticate(): device is revoked or token is wrong", "passed_data", deviceId)
+		return models.Device{}, errors.New("invalid device token")
+	}
+
+	return foundDevice, nil
+}
+
+func (d *DeviceService) GetRoomDisplay(ctx context.Context, roomId int, at time.Time) (models.RoomDisplay, error) {
+	ctx, span := tracer.Start(ctx, "DeviceService.GetRoomDisplay")
+	defer span.End()
+
+	room, err := d.roomService.GetRoomById(ctx, roomId)
+	if err != nil {
+		return models.RoomDisplay{}, err
+	}
+
+	current, next, err := d.bookingService.GetCurrentAndNextBookings(ctx, roomId, at)
+	if err != nil {
+		return models.RoomDisplay{}, err
+	}
+
+	display := models.RoomDisplay{
+		Room:   room,
+		Now:    current,
+		Next:   next,
+		IsFree: current == nil,
+		At:     at,
+	}
+	if display.IsFree && next != nil {
+		display.FreeUntil = &next.DateTimeStart
+	}
+
+	return display, nil
+}
+
+// BookNow books device's room starting from `at`. Booking is shortened if the next booking starts earlier
+func (d *DeviceService) BookNow(ctx context.Context, device models.Device, duration time.Duration, at time.Time) (models.Booking, error) {
+	ctx, span := tracer.Start(ctx, "DeviceService.BookNow")
+	defer span.End()
+
+	if duration <= 0 {
+		duration = DefaultBookNowPeriod
+	}
+	if duration < MinBookNowPeriod {
+		return models.Booking{}, fmt.Errorf("booking period should not be less than %s", MinBookNowPeriod)
+	}
+
+	current, next, err := d.bookingService.GetCurrentAndNextBookings(ctx, device.RoomId, at)
+	if err != nil {
+		return models.Booking{}, err
+	}
+	if current != nil {
+		metrics.BookingConflictsTotal.Inc()
+		return models.Booking{}, NewOverlappingBookingsError("Room is not available", []models.Booking{*current})
+	}
+
+	dateTimeStart := at.Truncate(time.Minute)
+	dateTimeEnd := dateTimeStart.Add(duration)
+	if next != nil && next.DateTimeStart.Before(dateTimeEnd) {
+		dateTimeEnd = next.DateTimeStart
+		if dateTimeEnd.Sub(dateTimeStart) < MinBookNowPeriod {
+			metrics.BookingConflictsTotal.Inc()
+			return models.Booking{}, NewOverlappingBookingsError("Room is free for less than minimal booking period", []models.Booking{*next})
+		}
+	}
+
+	// ad-hoc bookings are made on behalf of the user who registered the device
+	return d.bookingService.BookRoom(ctx, device.CreatedBy, device.RoomId, dateTimeStart, dateTimeEnd, device.CreatedBy)
+}
+
+func (d *DeviceService) CheckIn(ctx context.Context, device models.Device, at time.Time) (models.Booking, error) {
+	ctx, span := tracer.Start(ctx, "DeviceService.CheckIn")
+	defer span.End()
+
+	return d.bookingService.CheckIn(ctx, device.RoomId, at)
+}
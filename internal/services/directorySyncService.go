@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/directory"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/oidc"
+	"go-booking-system/pkg"
+	"log/slog"
+)
+
+// DirectoryChange change of one user planned by sync with the directory. User - existing user, empty for creation
+type DirectoryChange struct {
+	Action string
+	Entry  directory.Entry
+	User   models.User
+	RoleId int
+	Reason string
+}
+
+type DirectorySyncService struct {
+	client         *directory.Client
+	userRepository database.UserRepository
+	authService    AuthServiceInterface
+}
+
+// NewDirectorySyncService client is nil if LDAP is not enabled
+func NewDirectorySyncService(client *directory.Client, userRepository database.UserRepository, authService AuthServiceInterface) *DirectorySyncService {
+	return &DirectorySyncService{
+		client:         client,
+		userRepository: userRepository,
+		authService:    authService,
+	}
+}
+
+// OrganizationId organization users of the directory belong to. 0 - LDAP is not enabled
+func (d *DirectorySyncService) OrganizationId() int {
+	if d.client == nil {
+		return 0
+	}
+
+	return d.client.Config().OrganizationId
+}
+
+// Sync creates users of the directory, updates their name, contacts and role (by groups) and deactivates users whose
+// accounts are disabled or removed. The service should be built for organization of directory users
+func (d *DirectorySyncService) Sync(ctx context.Context) (models.DirectorySyncResult, error) {
+	ctx, span := tracer.Start(ctx, "DirectorySyncService.Sync")
+	defer span.End()
+
+	result := models.DirectorySyncResult{}
+	if d.client == nil {
+		return result, errors.New("LDAP is not enabled")
+	}
+	config := d.client.Config()
+
+	entries, err := d.client.Users(ctx)
+	if err != nil {
+		return result, err
+	}
+	users, err := d.userRepository.GetUsersByAuthProvider(ctx, AuthProviderLDAP)
+	if err != nil {
+		return result, err
+	}
+	// wrong filter or base DN should not deactivate everybody
+	if len(entries) == 0 && len(users) > 0 {
+		slog.ErrorContext(ctx, "DirectorySyncService.Sync(): directory returned no users - sync is skipped", "users", len(users))
+		return result, errors.New("directory returned no users - check base DN and user filter")
+	}
+
+	for _, change := range PlanDirectorySync(entries, users, config.GroupRoles, config.DefaultRoleId) {
+		action, err := d.apply(ctx, change)
+		if err != nil {
+			slog.ErrorContext(ctx, "DirectorySyncService.Sync(): error occured during sync of the user", "action", change.Action, "dn", change.Entry.DN, "user_id", change.User.UserId, "error", err)
+			action = models.DirectorySyncError
+		}
+		result.Add(action)
+	}
+	slog.InfoContext(ctx, "DirectorySyncService.Sync(): users are synchronized with directory", "entries", len(entries), "result", result)
+
+	return result, nil
+}
+
+// apply returns action which was performed - creation may be skipped because of a conflict with a local user
+func (d *DirectorySyncService) apply(ctx context.Context, change DirectoryChange) (string, error) {
+	switch change.Action {
+	case models.DirectorySyncCreate:
+		return d.create(ctx, change)
+	case models.DirectorySyncDeactivate:
+		_, err := d.userRepository.UpdateActive(ctx, change.User.UserId, false)
+		return change.Action, err
+	case models.DirectorySyncReactivate:
+		if _, err := d.userRepository.UpdateActive(ctx, change.User.UserId, true); err != nil {
+			return change.Action, err
+		}
+		return change.Action, d.update(ctx, change)
+	case models.DirectorySyncUpdate:
+		return change.Action, d.update(ctx, change)
+	case models.DirectorySyncSkip:
+		slog.WarnContext(ctx, "DirectorySyncService.apply(): entry of directory is skipped", "dn", change.Entry.DN, "reason", change.Reason)
+	}
+
+	return change.Action, nil
+}
+
+func (d *DirectorySyncService) create(ctx context.Context, change DirectoryChange) (string, error) {
+	existingUser, err := d.userRepository.GetUserByUsername(ctx, change.Entry.Username)
+	if err != nil {
+		return models.DirectorySyncError, err
+	}
+	// local account is not taken over by the directory
+	if existingUser.UserId != 0 {
+		slog.WarnContext(ctx, "DirectorySyncService.create(): username of directory entry is used by another user", "dn", change.Entry.DN, "user_id", existingUser.UserId)
+		return models.DirectorySyncSkip, nil
+	}
+
+	// nobody knows the password - it is checked by the directory
+	password, err := pkg.GenerateRandomToken(ssoPasswordSize)
+	if err != nil {
+		return models.DirectorySyncError, err
+	}
+
+	user, err := d.authService.Create(ctx, SystemUserId, models.User{
+		Name:         directoryName(change.Entry),
+		Email:        change.Entry.Email,
+		Telephone:    change.Entry.Telephone,
+		RoleId:       change.RoleId,
+		UserName:     change.Entry.Username,
+		Password:     password,
+		AuthProvider: AuthProviderLDAP,
+		DirectoryId:  change.Entry.Id,
+		// email is managed by the directory
+		EmailVerified: true,
+		Active:        true,
+	})
+	if err != nil {
+		return models.DirectorySyncError, err
+	}
+	slog.InfoContext(ctx, "DirectorySyncService.create(): user of directory is created", "user_id", user.UserId, "role_id", change.RoleId)
+
+	return models.DirectorySyncCreate, nil
+}
+
+// update writes attributes of the entry which differ from the user
+func (d *DirectorySyncService) update(ctx context.Context, change DirectoryChange) error {
+	user, entry := change.User, change.Entry
+
+	if contactsDiffer(user, entry) {
+		if _, err := d.userRepository.Update(ctx, models.User{UserId: user.UserId, Name: directoryName(entry), Email: entry.Email, Telephone: entry.Telephone}); err != nil {
+			return err
+		}
+	}
+	if user.UserName != entry.Username {
+		if _, err := d.authService.UpdateUsername(ctx, user.UserId, entry.Username); err != nil {
+			return err
+		}
+	}
+	if user.RoleId != change.RoleId {
+		if _, err := d.authService.UpdateRole(ctx, user.UserId, change.RoleId); err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "DirectorySyncService.update(): role is changed by groups of directory", "user_id", user.UserId, "old_role_id", user.RoleId, "role_id", change.RoleId)
+	}
+
+	return nil
+}
+
+// PlanDirectorySync compares entries of the directory with users linked to them. Users are matched by id of the entry, so
+// renamed accounts keep their bookings. Entry without mapped role is handled as disabled one
+func PlanDirectorySync(entries []directory.Entry, users []models.User, groupRoles []oidc.GroupRole, defaultRoleId int) []DirectoryChange {
+	usersByDirectoryId := make(map[string]models.User, len(users))
+	for _, user := range users {
+		usersByDirectoryId[user.DirectoryId] = user
+	}
+
+	var changes []DirectoryChange
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		seen[entry.Id] = true
+		user, exists := usersByDirectoryId[entry.Id]
+		roleId := MapGroupsToRole(entry.Groups, groupRoles, defaultRoleId)
+
+		switch {
+		case entry.Disabled || roleId == 0:
+			if exists && user.Active {
+				changes = append(changes, DirectoryChange{Action: models.DirectorySyncDeactivate, Entry: entry, User: user})
+			}
+		case !exists && entry.Email == "":
+			changes = append(changes, DirectoryChange{Action: models.DirectorySyncSkip, Entry: entry, Reason: "entry has no email"})
+		case !exists:
+			changes = append(changes, DirectoryChange{Action: models.DirectorySyncCreate, Entry: entry, RoleId: roleId})
+		case !user.Active:
+			changes = append(changes, DirectoryChange{Action: models.DirectorySyncReactivate, Entry: entry, User: user, RoleId: roleId})
+		case contactsDiffer(user, entry) || user.UserName != entry.Username || user.RoleId != roleId:
+			changes = append(changes, DirectoryChange{Action: models.DirectorySyncUpdate, Entry: entry, User: user, RoleId: roleId})
+		}
+	}
+
+	// account is removed from the directory or from the user filter
+	for _, user := range users {
+		if !seen[user.DirectoryId] && user.Active {
+			changes = append(changes, DirectoryChange{Action: models.DirectorySyncDeactivate, User: user})
+		}
+	}
+
+	return changes
+}
+
+// contactsDiffer empty email and telephone of the entry are not written - they are not cleared by update
+func contactsDiffer(user models.User, entry directory.Entry) bool {
+	return user.Name != directoryName(entry) ||
+		(entry.Email != "" && user.Email != entry.Email) ||
+		(entry.Telephone != "" && user.Telephone != entry.Telephone)
+}
+
+func directoryName(entry directory.Entry) string {
+	if entry.Name == "" {
+		return entry.Username
+	}
+
+	return entry.Name
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/models"
+)
+
+type FloorService struct {
+	repository database.FloorRepository
+}
+
+func NewFloorService(repository database.FloorRepository) *FloorService {
+	return &FloorService{repository: repository}
+}
+
+func (f *FloorService) Create(ctx context.Context, floor models.Floor) (models.Floor, error) {
+	ctx, span := tracer.Start(ctx, "FloorService.Create")
+	defer span.End()
+
+	return f.repository.Create(ctx, floor)
+}
+
+func (f *FloorService) GetAll(ctx context.Context) []models.Floor {
+	ctx, span := tracer.Start(ctx, "FloorService.GetAll")
+	defer span.End()
+
+	return f.repository.GetAll(ctx)
+}
+
+func (f *FloorService) GetFloorById(ctx context.Context, floorId int) (models.Floor, error) {
+	ctx, span := tracer.Start(ctx, "FloorService.GetFloorById")
+	defer span.End()
+
+	return f.repository.GetFloorById(ctx, floorId)
+}
+
+func (f *FloorService) GetFloorsByBuildingId(ctx context.Context, buildingId int) ([]models.Floor, error) {
+	ctx, span := tracer.Start(ctx, "FloorService.GetFloorsByBuildingId")
+	defer span.End()
+
+	return f.repository.GetFloorsByBuildingId(ctx, buildingId)
+}
+
+func (f *FloorService) Update(ctx context.Context, floor models.Floor) (models.Floor, error) {
+	ctx, span := tracer.Start(ctx, "FloorService.Update")
+	defer span.End()
+
+	return f.repository.Update(ctx, floor)
+}
+
+func (f *FloorService) Delete(ctx context.Context, floorId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "FloorService.Delete")
+	defer span.End()
+
+	return f.repository.Delete(ctx, floorId)
+}
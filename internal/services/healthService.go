@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+	"go-booking-system/internal/database"
+)
+
+// HealthService checks dependencies of the application for readiness probe
+type HealthService struct {
+	database *database.Database
+}
+
+func NewHealthService(database *database.Database) *HealthService {
+	return &HealthService{database: database}
+}
+
+func (h *HealthService) Ping(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "HealthService.Ping")
+	defer span.End()
+
+	return h.database.Ping(ctx)
+}
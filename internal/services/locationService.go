@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type LocationService struct {
+	repository             database.LocationRepository
+	openingHoursRepository database.OpeningHoursRepository
+}
+
+func NewLocationService(repository database.LocationRepository, openingHoursRepository database.OpeningHoursRepository) *LocationService {
+	return &LocationService{repository: repository, openingHoursRepository: openingHoursRepository}
+}
+
+func (l *LocationService) Create(ctx context.Context, location models.Location) (models.Location, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.Create")
+	defer span.End()
+
+	if _, err := time.LoadLocation(location.TimeZone); err != nil {
+		return models.Location{}, fmt.Errorf("unknown time zone. Passed data: '%s'", location.TimeZone)
+	}
+
+	return l.repository.Create(ctx, location)
+}
+
+func (l *LocationService) GetAll(ctx context.Context) []models.Location {
+	ctx, span := tracer.Start(ctx, "LocationService.GetAll")
+	defer span.End()
+
+	return l.repository.GetAll(ctx)
+}
+
+func (l *LocationService) GetLocationById(ctx context.Context, locationId int) (models.Location, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.GetLocationById")
+	defer span.End()
+
+	return l.repository.GetLocationById(ctx, locationId)
+}
+
+func (l *LocationService) GetLocationByRoomId(ctx context.Context, roomId int) (models.Location, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.GetLocationByRoomId")
+	defer span.End()
+
+	return l.repository.GetLocationByRoomId(ctx, roomId)
+}
+
+func (l *LocationService) Update(ctx context.Context, location models.Location) (models.Location, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.Update")
+	defer span.End()
+
+	if location.TimeZone != "" {
+		if _, err := time.LoadLocation(location.TimeZone); err != nil {
+			return models.Location{}, fmt.Errorf("unknown time zone. Passed data: '%s'", location.TimeZone)
+		}
+	}
+
+	return l.repository.Update(ctx, location)
+}
+
+func (l *LocationService) Delete(ctx context.Context, locationId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.Delete")
+	defer span.End()
+
+	return l.repository.Delete(ctx, locationId)
+}
+
+// GetTimeZoneByRoomId returns time zone of the location where room is. Rooms without location are in UTC
+func (l *LocationService) GetTimeZoneByRoomId(ctx context.Context, roomId int) (*time.Location, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.GetTimeZoneByRoomId")
+	defer span.End()
+
+	location, err := l.repository.GetLocationByRoomId(ctx, roomId)
+	if errors.Is(err, repositories.ErrRoomWithoutLocation) {
+		return time.UTC, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return time.LoadLocation(location.TimeZone)
+}
+
+func (l *LocationService) GetOpeningHours(ctx context.Context, locationId int) ([]models.OpeningHours, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.GetOpeningHours")
+	defer span.End()
+
+	return l.openingHoursRepository.GetOpeningHoursByLocationId(ctx, locationId)
+}
+
+// SetOpeningHours replaces all opening hours rules of the location
+func (l *LocationService) SetOpeningHours(ctx context.Context, locationId int, openingHours []models.OpeningHours) ([]models.OpeningHours, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.SetOpeningHours")
+	defer span.End()
+
+	for i := range openingHours {
+		openingHours[i].LocationId = locationId
+		if err := ValidateOpeningHours(openingHours[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return l.openingHoursRepository.ReplaceOpeningHours(ctx, locationId, openingHours)
+}
+
+// IsRoomOpen checks if [start; end] is within opening hours of room's location evaluated in location's time zone.
+// Rooms without location (not placed on any floor) have no opening hours - they are always open
+func (l *LocationService) IsRoomOpen(ctx context.Context, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) (bool, error) {
+	ctx, span := tracer.Start(ctx, "LocationService.IsRoomOpen")
+	defer span.End()
+
+	location, err := l.repository.GetLocationByRoomId(ctx, roomId)
+	if errors.Is(err, repositories.ErrRoomWithoutLocation) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	timeZone, err := time.LoadLocation(location.TimeZone)
+	if err != nil {
+		slog.WarnContext(ctx, "LocationService.IsRoomOpen(): wrong time zone of location", "location_id", location.LocationId, "time_zone", location.TimeZone)
+		return false, err
+	}
+
+	openingHours, err := l.openingHoursRepository.GetOpeningHoursByLocationId(ctx, location.LocationId)
+	if err != nil {
+		return false, err
+	}
+
+	return IsWithinOpeningHours(openingHours, timeZone, dateTimeStart, dateTimeEnd)
+}
+
+// IsWithinOpeningHours rules are evaluated in local time of `timeZone`, so DST transitions shift them in UTC.
+// No rules - location is always open. Booking should fit into one rule of the day it starts.
+func IsWithinOpeningHours(openingHours []models.OpeningHours, timeZone *time.Location, dateTimeStart time.Time, dateTimeEnd time.Time) (bool, error) {
+	if len(openingHours) == 0 {
+		return true, nil
+	}
+
+	localStart := dateTimeStart.In(timeZone)
+	year, month, day := localStart.Date()
+
+	for _, rule := range openingHours {
+		if rule.Weekday != int(localStart.Weekday()) {
+			continue
+		}
+
+		opensAtHour, opensAtMinute, err := parseClock(rule.OpensAt)
+		if err != nil {
+			return false, err
+		}
+		closesAtHour, closesAtMinute, err := parseClock(rule.ClosesAt)
+		if err != nil {
+			return false, err
+		}
+
+		// time.Date resolves wall clock of that very day - offset is taken for the date, not for `now`
+		opensAt := time.Date(year, month, day, opensAtHour, opensAtMinute, 0, 0, timeZone)
+		closesAt := time.Date(year, month, day, closesAtHour, closesAtMinute, 0, 0, timeZone)
+
+		if !dateTimeStart.Before(opensAt) && !dateTimeEnd.After(closesAt) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func ValidateOpeningHours(openingHours models.OpeningHours) error {
+	if openingHours.Weekday < int(time.Sunday) || openingHours.Weekday > int(time.Saturday) {
+		return fmt.Errorf("OpeningHours.Weekday: should be between 0 (Sunday) and 6 (Saturday). Passed data: %d", openingHours.Weekday)
+	}
+
+	opensAtHour, opensAtMinute, err := parseClock(openingHours.OpensAt)
+	if err != nil {
+		return err
+	}
+	closesAtHour, closesAtMinute, err := parseClock(openingHours.ClosesAt)
+	if err != nil {
+		return err
+	}
+
+	if opensAtHour*60+opensAtMinute >= closesAtHour*60+closesAtMinute {
+		return fmt.Errorf("OpeningHours: opens_at should be before closes_at. Passed data: %s - %s", openingHours.OpensAt, openingHours.ClosesAt)
+	}
+
+	return nil
+}
+
+// parseClock parses `HH:MM` clock. `24:00` is allowed as the end of the day
+func parseClock(clock string) (hour int, minute int, err error) {
+	hourString, minuteString, found := strings.Cut(clock, ":")
+	if !found {
+		return 0, 0, fmt.Errorf("wrong clock format, should be HH:MM. Passed data: '%s'", clock)
+	}
+
+	hour, hourError := strconv.Atoi(hourString)
+	minute, minuteError := strconv.Atoi(minuteString)
+	if hourError != nil || minuteError != nil || hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, 0, fmt.Errorf("wrong clock format, should be HH:MM. Passed data: '%s'", clock)
+	}
+
+	return hour, minute, nil
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// authentication methods of `amr` claim ref: https://www.rfc-editor.org/rfc/rfc8176.html
+const (
+	AuthMethodPassword = "pwd"
+	AuthMethodOTP      = "otp"
+	AuthMethodMFA      = "mfa"
+)
+
+const (
+	InvalidMFACodeError        = "invalid two-factor authentication code"
+	MFARequiredError           = "two-factor authentication is required"
+	MFAEnrollmentRequiredError = "two-factor authentication is required for the role - enroll by /auth/mfa/enroll"
+
+	// MFAChallengeType `typ` claim of token issued between password and TOTP code
+	MFAChallengeType = "mfa"
+
+	// recoveryCodesCount codes issued at enrollment - each one can be used once
+	recoveryCodesCount = 10
+	recoveryCodeSize   = 5 // bytes - 10 hex characters
+	// totpSkew codes of previous and next time steps are accepted - clock of the phone may drift
+	totpSkew = 1
+)
+
+// mfaEnrollmentRoutes are available to users who should use two-factor authentication but have not enrolled yet
+var mfaEnrollmentRoutes = []string{"/auth/mfa/enroll", "/auth/mfa/confirm"}
+
+// MFAChallengeClaims short-lived token proving that password of the user is correct - exchanged for tokens with TOTP code
+type MFAChallengeClaims struct {
+	Issuer              string                `json:"iss"`
+	Type                string                `json:"typ"`
+	IssuedAt            int                   `json:"iat"`
+	ExpirationTime      int                   `json:"exp"`
+	Subject             string                `json:"sub"`
+	Organization        string                `json:"org"`
+	OriginatingIdentity pkg.IPAddressIdentity `json:"orig"`
+}
+
+type MFAService struct {
+	userRepository         database.UserRepository
+	recoveryCodeRepository database.RecoveryCodeRepository
+	mfaPolicyRepository    database.MFAPolicyRepository
+	roleService            RoleServiceInterface
+	routeService           RouteServiceInterface
+	tokenBinding           TokenBinding
+
+	config AuthConfig
+}
+
+func NewMFAService(config AuthConfig, userRepository database.UserRepository, recoveryCodeRepository database.RecoveryCodeRepository, mfaPolicyRepository database.MFAPolicyRepository, roleService RoleServiceInterface, routeService RouteServiceInterface) *MFAService {
+	return &MFAService{
+		userRepository:         userRepository,
+		recoveryCodeRepository: recoveryCodeRepository,
+		mfaPolicyRepository:    mfaPolicyRepository,
+		roleService:            roleService,
+		routeService:           routeService,
+		tokenBinding:           NewTokenBinding(config.TokenBinding),
+		config:                 config,
+	}
+}
+
+// Enroll generates new TOTP secret. TOTP is enabled by Confirm with the first code, until then login does not ask for it
+func (m *MFAService) Enroll(ctx context.Context, userId int) (models.MFAEnrollment, error) {
+	ctx, span := tracer.Start(ctx, "MFAService.Enroll")
+	defer span.End()
+
+	user, err := m.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return models.MFAEnrollment{}, err
+	}
+	if user.TOTPEnabled {
+		slog.WarnContext(ctx, "MFAService.Enroll(): TOTP is already enabled", "user_id", userId)
+		return models.MFAEnrollment{}, errors.New("two-factor authentication is already enabled - disable it first")
+	}
+
+	secret, err := pkg.GenerateTOTPSecret()
+	if err != nil {
+		slog.ErrorContext(ctx, "MFAService.Enroll(): error occured during TOTP secret generation", "error", err)
+		return models.MFAEnrollment{}, err
+	}
+
+	user.TOTPSecret, user.TOTPEnabled, user.TOTPLastStep = secret, false, 0
+	if _, err := m.userRepository.UpdateTOTP(ctx, user); err != nil {
+		return models.MFAEnrollment{}, err
+	}
+
+	return models.MFAEnrollment{Secret: secret, URI: pkg.TOTPURI(issuer, user.UserName, secret)}, nil
+}
+
+// Confirm enables TOTP if the code matches the enrolled secret. Returns recovery codes - they are shown only once
+func (m *MFAService) Confirm(ctx context.Context, userId int, code string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "MFAService.Confirm")
+	defer span.End()
+
+	user, err := m.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPSecret == "" || user.TOTPEnabled {
+		slog.WarnContext(ctx, "MFAService.Confirm(): TOTP enrollment is not started", "user_id", userId)
+		return nil, errors.New("two-factor authentication enrollment is not started")
+	}
+
+	step, isValid := pkg.ValidateTOTP(user.TOTPSecret, code, time.Now(), totpSkew)
+	if !isValid {
+		slog.WarnContext(ctx, "MFAService.Confirm(): TOTP code is not valid", "user_id", userId)
+		return nil, errors.New(InvalidMFACodeError)
+	}
+
+	user.TOTPEnabled, user.TOTPLastStep = true, step
+	if _, err := m.userRepository.UpdateTOTP(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return m.replaceRecoveryCodes(ctx, userId)
+}
+
+// Disable turns TOTP off. Code (TOTP or recovery one) is required - stolen access token is not enough
+func (m *MFAService) Disable(ctx context.Context, userId int, code string) error {
+	ctx, span := tracer.Start(ctx, "MFAService.Disable")
+	defer span.End()
+
+	user, err := m.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if _, err := m.Verify(ctx, user, code); err != nil {
+		return err
+	}
+
+	return m.Reset(ctx, userId)
+}
+
+// Reset turns TOTP off and removes recovery codes without any code - used by administrators if the user has lost them
+func (m *MFAService) Reset(ctx context.Context, userId int) error {
+	ctx, span := tracer.Start(ctx, "MFAService.Reset")
+	defer span.End()
+
+	if _, err := m.userRepository.UpdateTOTP(ctx, models.User{UserId: userId}); err != nil {
+		return err
+	}
+
+	return m.recoveryCodeRepository.ReplaceRecoveryCodes(ctx, userId, nil)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user. Code (TOTP or recovery one) is required
+func (m *MFAService) RegenerateRecoveryCodes(ctx context.Context, userId int, code string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "MFAService.RegenerateRecoveryCodes")
+	defer span.End()
+
+	user, err := m.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.Verify(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	return m.replaceRecoveryCodes(ctx, userId)
+}
+
+// Verify checks TOTP code (6 digits) or recovery code of the user with enabled TOTP. Every code is accepted only once.
+// Returns `amr` claim of tokens
+func (m *MFAService) Verify(ctx context.Context, user models.User, code string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "MFAService.Verify")
+	defer span.End()
+
+	if !user.TOTPEnabled {
+		slog.WarnContext(ctx, "MFAService.Verify(): TOTP is not enabled", "user_id", user.UserId)
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == pkg.TOTPDigits {
+		step, isValid := pkg.ValidateTOTP(user.TOTPSecret, code, time.Now(), totpSkew)
+		if !isValid {
+			slog.WarnContext(ctx, "MFAService.Verify(): TOTP code is not valid", "user_id", user.UserId)
+			return nil, errors.New(InvalidMFACodeError)
+		}
+
+		isNewStep, err := m.userRepository.UpdateTOTPLastStep(ctx, user.UserId, step)
+		if err != nil {
+			return nil, err
+		}
+		if !isNewStep {
+			slog.WarnContext(ctx, "MFAService.Verify(): TOTP code is already used", "user_id", user.UserId)
+			return nil, errors.New(InvalidMFACodeError)
+		}
+
+		return []string{AuthMethodPassword, AuthMethodOTP, AuthMethodMFA}, nil
+	}
+
+	isUsed, err := m.recoveryCodeRepository.UseRecoveryCode(ctx, user.UserId, hashRecoveryCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if !isUsed {
+		slog.WarnContext(ctx, "MFAService.Verify(): recovery code is not valid", "user_id", user.UserId)
+		return nil, errors.New(InvalidMFACodeError)
+	}
+	slog.InfoContext(ctx, "MFAService.Verify(): recovery code is used", "user_id", user.UserId)
+
+	return []string{AuthMethodPassword, AuthMethodMFA}, nil
+}
+
+// IsMFARequired checks policy of the organization for the role
+func (m *MFAService) IsMFARequired(ctx context.Context, roleId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "MFAService.IsMFARequired")
+	defer span.End()
+
+	return m.mfaPolicyRepository.IsMFARequired(ctx, roleId)
+}
+
+// CheckMFARequirement denies tokens issued without second factor to routes marked `mfa_required` and to users whose role
+// requires two-factor authentication. The latter can use enrollment routes only
+func (m *MFAService) CheckMFARequirement(ctx context.Context, destination string, roleId int, authMethods []string) error {
+	ctx, span := tracer.Start(ctx, "MFAService.CheckMFARequirement")
+	defer span.End()
+
+	if slices.Contains(authMethods, AuthMethodMFA) {
+		return nil
+	}
+
+	route, err := m.routeService.GetRouteByURL(ctx, destination)
+	if err != nil {
+		return err
+	}
+	if route.MFARequired {
+		slog.WarnContext(ctx, "MFAService.CheckMFARequirement(): route requires two-factor authentication", "passed_data", destination)
+		return errors.New(MFARequiredError)
+	}
+
+	isRequired, err := m.mfaPolicyRepository.IsMFARequired(ctx, roleId)
+	if err != nil {
+		return err
+	}
+	if isRequired && !slices.Contains(mfaEnrollmentRoutes, destination) {
+		slog.WarnContext(ctx, "MFAService.CheckMFARequirement(): role requires two-factor authentication", "role_id", roleId, "passed_data", destination)
+		return errors.New(MFAEnrollmentRequiredError)
+	}
+
+	return nil
+}
+
+func (m *MFAService) GetMFAPolicies(ctx context.Context) ([]models.MFAPolicy, error) {
+	ctx, span := tracer.Start(ctx, "MFAService.GetMFAPolicies")
+	defer span.End()
+
+	return m.mfaPolicyRepository.GetMFAPolicies(ctx)
+}
+
+// ReplaceMFAPolicies sets roles of the organization which should use two-factor authentication. Roles should be
+// available in the organization
+func (m *MFAService) ReplaceMFAPolicies(ctx context.Context, roleIds []int, createdBy int) ([]models.MFAPolicy, error) {
+	ctx, span := tracer.Start(ctx, "MFAService.ReplaceMFAPolicies")
+	defer span.End()
+
+	slices.Sort(roleIds)
+	roleIds = slices.Compact(roleIds)
+
+	policies := make([]models.MFAPolicy, 0, len(roleIds))
+	for _, roleId := range roleIds {
+		role, err := m.roleService.GetRoleById(ctx, roleId)
+		if err != nil {
+			return nil, err
+		}
+		if role.RoleId == 0 {
+			slog.WarnContext(ctx, "MFAService.ReplaceMFAPolicies(): role is not found in organization", "passed_data", roleId)
+			return nil, fmt.Errorf("role is not found in organization. Passed data: role_id=%d", roleId)
+		}
+		policies = append(policies, models.MFAPolicy{RoleId: roleId, CreatedBy: createdBy})
+	}
+
+	return m.mfaPolicyRepository.ReplaceMFAPolicies(ctx, policies)
+}
+
+// IssueChallenge returns token the user exchanges for access and refresh tokens together with TOTP code
+func (m *MFAService) IssueChallenge(ctx context.Context, user models.User, identity pkg.IPAddressIdentity) (string, error) {
+	ctx, span := tracer.Start(ctx, "MFAService.IssueChallenge")
+	defer span.End()
+
+	now := time.Now()
+	claims := MFAChallengeClaims{
+		Issuer:              issuer,
+		Type:                MFAChallengeType,
+		IssuedAt:            int(now.Unix()),
+		ExpirationTime:      int(now.Add(m.config.MFAChallengeTTL).Unix()),
+		Subject:             strconv.Itoa(user.UserId),
+		Organization:        strconv.Itoa(user.OrganizationId),
+		OriginatingIdentity: identity,
+	}
+
+	challenge, err := signToken(claims, m.challengeKey())
+	if err != nil {
+		slog.ErrorContext(ctx, "MFAService.IssueChallenge(): error occured during encoding of claims", "error", err)
+		return "", err
+	}
+
+	return challenge, nil
+}
+
+// ValidateChallenge checks signature, expiration and sender of the challenge token (by token binding)
+func (m *MFAService) ValidateChallenge(ctx context.Context, challenge string, sentFrom pkg.IPAddressIdentity) (MFAChallengeClaims, error) {
+	ctx, span := tracer.Start(ctx, "MFAService.ValidateChallenge")
+	defer span.End()
+
+	var claims MFAChallengeClaims
+	if err := parseSignedToken(challenge, m.challengeKey(), &claims); err != nil || claims.Type != MFAChallengeType {
+		slog.WarnContext(ctx, "MFAService.ValidateChallenge(): challenge token is not valid", "error", err)
+		return MFAChallengeClaims{}, errors.New("MFA token is not valid")
+	}
+
+	if int64(claims.ExpirationTime) < time.Now().Unix() {
+		slog.WarnContext(ctx, "MFAService.ValidateChallenge(): challenge token is expired", "user_id", claims.Subject)
+		return MFAChallengeClaims{}, errors.New("MFA token is expired - log in again")
+	}
+	if !m.tokenBinding.Matches(claims.OriginatingIdentity, sentFrom) {
+		slog.WarnContext(ctx, "MFAService.ValidateChallenge(): challenge token is sent from another client", "user_id", claims.Subject, "ip_address", sentFrom.IP)
+		return MFAChallengeClaims{}, errors.New(SentNotFromOriginatingIdentityError)
+	}
+
+	return claims, nil
+}
+
+// challengeKey challenge cannot be used as access token and vice versa
+func (m *MFAService) challengeKey() string {
+	return derivedKey(m.config.AccessTokenKey, "mfa-challenge")
+}
+
+func (m *MFAService) replaceRecoveryCodes(ctx context.Context, userId int) ([]string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	recoveryCodes := make([]models.RecoveryCode, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		token, err := pkg.GenerateRandomToken(recoveryCodeSize)
+		if err != nil {
+			slog.ErrorContext(ctx, "MFAService.replaceRecoveryCodes(): error occured during recovery code generation", "error", err)
+			return nil, err
+		}
+		code := token[:len(token)/2] + "-" + token[len(token)/2:]
+		codes = append(codes, code)
+		recoveryCodes = append(recoveryCodes, models.RecoveryCode{UserId: userId, CodeHash: hashRecoveryCode(code)})
+	}
+
+	if err := m.recoveryCodeRepository.ReplaceRecoveryCodes(ctx, userId, recoveryCodes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// hashRecoveryCode codes are accepted with or without dash and in any case
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+
+	return pkg.HashToken(normalized)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+)
+
+// HostOrganizationId organization operating the deployment (business center) - only its users manage other organizations
+const HostOrganizationId = 1
+
+type OrganizationService struct {
+	repository database.OrganizationRepository
+
+	// organization of the caller. repositories.SystemOrganizationId - not limited
+	organizationId int
+}
+
+func NewOrganizationService(repository database.OrganizationRepository, organizationId int) *OrganizationService {
+	return &OrganizationService{repository: repository, organizationId: organizationId}
+}
+
+func (o *OrganizationService) Create(ctx context.Context, organization models.Organization) (models.Organization, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.Create")
+	defer span.End()
+
+	if !o.isHost() {
+		return models.Organization{}, errors.New("only host organization can create organizations")
+	}
+
+	return o.repository.Create(ctx, organization)
+}
+
+// GetAll returns all organizations for host organization and only caller's organization for others
+func (o *OrganizationService) GetAll(ctx context.Context) []models.Organization {
+	ctx, span := tracer.Start(ctx, "OrganizationService.GetAll")
+	defer span.End()
+
+	if o.isHost() {
+		return o.repository.GetAll(ctx)
+	}
+
+	organization, err := o.repository.GetOrganizationById(ctx, o.organizationId)
+	if err != nil {
+		return []models.Organization{}
+	}
+
+	return []models.Organization{organization}
+}
+
+func (o *OrganizationService) GetOrganizationById(ctx context.Context, organizationId int) (models.Organization, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.GetOrganizationById")
+	defer span.End()
+
+	if !o.isHost() && organizationId != o.organizationId {
+		return models.Organization{}, errors.New("no Organizations were found")
+	}
+
+	return o.repository.GetOrganizationById(ctx, organizationId)
+}
+
+func (o *OrganizationService) Update(ctx context.Context, organization models.Organization) (models.Organization, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.Update")
+	defer span.End()
+
+	if !o.isHost() && organization.OrganizationId != o.organizationId {
+		return models.Organization{}, errors.New("no Organizations were updated")
+	}
+
+	return o.repository.Update(ctx, organization)
+}
+
+func (o *OrganizationService) Delete(ctx context.Context, organizationId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "OrganizationService.Delete")
+	defer span.End()
+
+	if !o.isHost() {
+		return false, errors.New("only host organization can delete organizations")
+	}
+	if organizationId == HostOrganizationId {
+		return false, errors.New("host organization cannot be deleted")
+	}
+
+	return o.repository.Delete(ctx, organizationId)
+}
+
+func (o *OrganizationService) isHost() bool {
+	return o.organizationId == repositories.SystemOrganizationId || o.organizationId == HostOrganizationId
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"go-booking-system/internal/database"
 	"go-booking-system/internal/models"
 )
@@ -13,30 +14,51 @@ func NewPermissionService(repository database.PermissionRepository) *PermissionS
 	return &PermissionService{repository: repository}
 }
 
-func (r *PermissionService) Create(permission models.Permission) (models.Permission, error) {
-	return r.repository.Create(permission)
+func (r *PermissionService) Create(ctx context.Context, permission models.Permission) (models.Permission, error) {
+	ctx, span := tracer.Start(ctx, "PermissionService.Create")
+	defer span.End()
+
+	return r.repository.Create(ctx, permission)
 }
 
-func (r *PermissionService) GetAll() []models.Permission {
-	return r.repository.GetAll()
+func (r *PermissionService) GetAll(ctx context.Context) []models.Permission {
+	ctx, span := tracer.Start(ctx, "PermissionService.GetAll")
+	defer span.End()
+
+	return r.repository.GetAll(ctx)
 }
 
-func (r *PermissionService) GetPermissionsByRoleId(roleId int) ([]models.Permission, error) {
-	return r.repository.GetPermissionsByRoleId(roleId)
+func (r *PermissionService) GetPermissionsByRoleId(ctx context.Context, roleId int) ([]models.Permission, error) {
+	ctx, span := tracer.Start(ctx, "PermissionService.GetPermissionsByRoleId")
+	defer span.End()
+
+	return r.repository.GetPermissionsByRoleId(ctx, roleId)
 }
 
-func (r *PermissionService) GetPermissionsByRouteId(routeId int) ([]models.Permission, error) {
-	return r.repository.GetPermissionsByRouteId(routeId)
+func (r *PermissionService) GetPermissionsByRouteId(ctx context.Context, routeId int) ([]models.Permission, error) {
+	ctx, span := tracer.Start(ctx, "PermissionService.GetPermissionsByRouteId")
+	defer span.End()
+
+	return r.repository.GetPermissionsByRouteId(ctx, routeId)
 }
 
-func (r *PermissionService) GetPermissionsByRoleIdAndRouteId(roleId int, routeId int) ([]models.Permission, error) {
-	return r.repository.GetPermissionsByRoleIdAndRouteId(roleId, routeId)
+func (r *PermissionService) GetPermissionsByRoleIdAndRouteId(ctx context.Context, roleId int, routeId int) ([]models.Permission, error) {
+	ctx, span := tracer.Start(ctx, "PermissionService.GetPermissionsByRoleIdAndRouteId")
+	defer span.End()
+
+	return r.repository.GetPermissionsByRoleIdAndRouteId(ctx, roleId, routeId)
 }
 
-func (r *PermissionService) Update(permission models.Permission) (models.Permission, error) {
-	return r.repository.Update(permission)
+func (r *PermissionService) Update(ctx context.Context, permission models.Permission) (models.Permission, error) {
+	ctx, span := tracer.Start(ctx, "PermissionService.Update")
+	defer span.End()
+
+	return r.repository.Update(ctx, permission)
 }
 
-func (r *PermissionService) Delete(roleId int, routeId int) (bool, error) {
-	return r.repository.Delete(roleId, routeId)
+func (r *PermissionService) Delete(ctx context.Context, roleId int, routeId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "PermissionService.Delete")
+	defer span.End()
+
+	return r.repository.Delete(ctx, roleId, routeId)
 }
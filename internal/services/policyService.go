@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/models"
+	"log/slog"
+	"strings"
+)
+
+const (
+	SubjectAttributePrefix  = "subject."
+	ResourceAttributePrefix = "resource."
+	RequestAttributePrefix  = "request."
+
+	PolicyOperatorEq    = "eq"
+	PolicyOperatorNe    = "ne"
+	PolicyOperatorLt    = "lt"
+	PolicyOperatorLte   = "lte"
+	PolicyOperatorGt    = "gt"
+	PolicyOperatorGte   = "gte"
+	PolicyOperatorIn    = "in"
+	PolicyOperatorNotIn = "not_in"
+)
+
+// RequestAttributes attributes of the HTTP request policies are evaluated against, without `request.` prefix: method,
+// path, ip, hour, weekday and top-level fields of JSON body (e.g. `room_id` of the booking to create)
+type RequestAttributes map[string]any
+
+// RequestAttributesSource gives attributes of the request. They are taken only for routes having policies - access
+// checks of other routes do not read bodies of requests
+type RequestAttributesSource interface {
+	Attributes() RequestAttributes
+}
+
+func (r RequestAttributes) Attributes() RequestAttributes {
+	return r
+}
+
+// RequestAttributesFunc reads attributes of the request when they are needed
+type RequestAttributesFunc func() RequestAttributes
+
+func (f RequestAttributesFunc) Attributes() RequestAttributes {
+	return f()
+}
+
+// PolicyService declarative rules of access beyond ALL / OWNER scopes of permissions (e.g. "Event Planner may edit
+// bookings of rooms on their floor"). Rules are evaluated by AuthService.CheckPermissions
+type PolicyService struct {
+	policyRepository database.PolicyRepository
+	userRepository   database.UserRepository
+	authService      AuthServiceInterface
+	routeService     RouteServiceInterface
+}
+
+func NewPolicyService(policyRepository database.PolicyRepository, userRepository database.UserRepository, authService AuthServiceInterface, routeService RouteServiceInterface) *PolicyService {
+	return &PolicyService{
+		policyRepository: policyRepository,
+		userRepository:   userRepository,
+		authService:      authService,
+		routeService:     routeService,
+	}
+}
+
+func (p *PolicyService) CreatePolicy(ctx context.Context, policy models.Policy) (models.Policy, error) {
+	ctx, span := tracer.Start(ctx, "PolicyService.CreatePolicy")
+	defer span.End()
+
+	policy, err := p.resolvePolicy(ctx, policy)
+	if err != nil {
+		return models.Policy{}, err
+	}
+	policy.Active = true
+
+	createdPolicy, err := p.policyRepository.Create(ctx, policy)
+	if err != nil {
+		return models.Policy{}, err
+	}
+	slog.InfoContext(ctx, "PolicyService.CreatePolicy(): policy is created", "policy_id", createdPolicy.PolicyId, "route_id", createdPolicy.RouteId, "created_by", createdPolicy.CreatedBy)
+
+	return createdPolicy, nil
+}
+
+func (p *PolicyService) GetPolicies(ctx context.Context) []models.Policy {
+	ctx, span := tracer.Start(ctx, "PolicyService.GetPolicies")
+	defer span.End()
+
+	return p.policyRepository.GetAll(ctx)
+}
+
+func (p *PolicyService) UpdatePolicy(ctx context.Context, policy models.Policy) (models.Policy, error) {
+	ctx, span := tracer.Start(ctx, "PolicyService.UpdatePolicy")
+	defer span.End()
+
+	if _, err := p.policyRepository.GetPolicyById(ctx, policy.PolicyId); err != nil {
+		return models.Policy{}, err
+	}
+	policy, err := p.resolvePolicy(ctx, policy)
+	if err != nil {
+		return models.Policy{}, err
+	}
+
+	return p.policyRepository.Update(ctx, policy)
+}
+
+func (p *PolicyService) DeletePolicy(ctx context.Context, policyId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "PolicyService.DeletePolicy")
+	defer span.End()
+
+	return p.policyRepository.Delete(ctx, policyId)
+}
+
+// Explain dry run of access check of the user: shows permissions and policies deciding access to the route. Passed
+// policies are evaluated as if they were saved - rules are tried before they affect anybody. Role of the user is
+// checked unless another role is passed
+func (p *PolicyService) Explain(ctx context.Context, request models.AccessExplanationRequest) (models.AccessExplanation, error) {
+	ctx, span := tracer.Start(ctx, "PolicyService.Explain")
+	defer span.End()
+
+	user, err := p.userRepository.GetUserById(ctx, request.UserId)
+	if err != nil {
+		return models.AccessExplanation{}, err
+	}
+	roleId := user.RoleId
+	if request.RoleId != 0 {
+		roleId = request.RoleId
+	}
+
+	dryRunPolicies := make([]models.Policy, 0, len(request.Policies))
+	for _, policy := range request.Policies {
+		// unsaved policies are rules of the explained route
+		policy.RouteId, policy.URL = 0, request.URL
+		resolvedPolicy, err := p.resolvePolicy(ctx, policy)
+		if err != nil {
+			return models.AccessExplanation{}, err
+		}
+		dryRunPolicies = append(dryRunPolicies, resolvedPolicy)
+	}
+
+	recordType := request.RecordType
+	if recordType == "" {
+		recordType = strings.Split(strings.TrimPrefix(request.URL, "/"), "/")[0]
+	}
+
+	return p.authService.ExplainAccess(ctx, request.URL, recordType, request.RecordId, user.UserId, roleId, request.Request, dryRunPolicies)
+}
+
+// resolvePolicy checks the rule and finds route passed by URL
+func (p *PolicyService) resolvePolicy(ctx context.Context, policy models.Policy) (models.Policy, error) {
+	if strings.TrimSpace(policy.Name) == "" {
+		return models.Policy{}, errors.New("policy should have a name")
+	}
+	if policy.Effect != models.PolicyEffectAllow && policy.Effect != models.PolicyEffectDeny {
+		return models.Policy{}, fmt.Errorf("effect of policy should be %q or %q. Passed data: %q", models.PolicyEffectAllow, models.PolicyEffectDeny, policy.Effect)
+	}
+	for _, condition := range policy.Conditions {
+		if err := ValidatePolicyCondition(condition); err != nil {
+			return models.Policy{}, err
+		}
+	}
+
+	var route models.Route
+	var err error
+	if policy.RouteId != 0 {
+		route, err = p.routeService.GetRouteById(ctx, policy.RouteId)
+	} else {
+		route, err = p.routeService.GetRouteByURL(ctx, policy.URL)
+	}
+	if err != nil {
+		return models.Policy{}, err
+	}
+	if route.RouteId == 0 {
+		return models.Policy{}, fmt.Errorf("route is not found. Passed data: route_id=%d, url=%q", policy.RouteId, policy.URL)
+	}
+	policy.RouteId, policy.URL = route.RouteId, route.URL
+
+	if policy.RoleId != 0 {
+		if err := p.authService.CheckRoleIsAvailable(ctx, policy.RoleId); err != nil {
+			return models.Policy{}, err
+		}
+	}
+	if policy.Conditions == nil {
+		policy.Conditions = models.PolicyConditions{}
+	}
+
+	return policy, nil
+}
+
+// ValidatePolicyCondition attributes belong to subject, resource or request. Value is compared either with constant or
+// with another attribute
+func ValidatePolicyCondition(condition models.PolicyCondition) error {
+	if !isPolicyAttribute(condition.Attribute) {
+		return fmt.Errorf("attribute of condition should start with %q, %q or %q. Passed data: %q", SubjectAttributePrefix, ResourceAttributePrefix, RequestAttributePrefix, condition.Attribute)
+	}
+	if (condition.Value == nil) == (condition.ValueAttribute == "") {
+		return fmt.Errorf("condition of %q should have either value or value_attribute", condition.Attribute)
+	}
+	if condition.ValueAttribute != "" && !isPolicyAttribute(condition.ValueAttribute) {
+		return fmt.Errorf("value_attribute of condition should start with %q, %q or %q. Passed data: %q", SubjectAttributePrefix, ResourceAttributePrefix, RequestAttributePrefix, condition.ValueAttribute)
+	}
+
+	switch condition.Operator {
+	case PolicyOperatorEq, PolicyOperatorNe:
+	case PolicyOperatorLt, PolicyOperatorLte, PolicyOperatorGt, PolicyOperatorGte:
+		if condition.Value != nil && !isOrderedValue(condition.Value) {
+			return fmt.Errorf("operator %q of %q compares only numbers and strings", condition.Operator, condition.Attribute)
+		}
+	case PolicyOperatorIn, PolicyOperatorNotIn:
+		if _, ok := condition.Value.([]any); !ok && condition.ValueAttribute == "" {
+			return fmt.Errorf("operator %q of %q needs list of values", condition.Operator, condition.Attribute)
+		}
+	default:
+		return fmt.Errorf("operator of condition should be one of eq, ne, lt, lte, gt, gte, in, not_in. Passed data: %q", condition.Operator)
+	}
+
+	return nil
+}
+
+// EvaluatePolicy policy matches if all of its conditions hold. Condition over attribute the request does not have never
+// holds - e.g. rule about capacity of the room does not match requests without a room
+func EvaluatePolicy(policy models.Policy, attributes map[string]any) models.PolicyExplanation {
+	explanation := models.PolicyExplanation{
+		PolicyId:   policy.PolicyId,
+		Name:       policy.Name,
+		Effect:     policy.Effect,
+		Matched:    true,
+		Conditions: make([]models.ConditionExplanation, 0, len(policy.Conditions)),
+	}
+
+	for _, condition := range policy.Conditions {
+		actual, found := attributes[condition.Attribute]
+		expected, expectedFound := condition.Value, condition.Value != nil
+		if condition.ValueAttribute != "" {
+			expected, expectedFound = attributes[condition.ValueAttribute]
+		}
+
+		matched := found && expectedFound && compareAttribute(actual, condition.Operator, expected)
+		explanation.Matched = explanation.Matched && matched
+		explanation.Conditions = append(explanation.Conditions, models.ConditionExplanation{PolicyCondition: condition, Actual: actual, Matched: matched})
+	}
+
+	return explanation
+}
+
+func compareAttribute(actual any, operator string, expected any) bool {
+	switch operator {
+	case PolicyOperatorEq:
+		return equalAttributes(actual, expected)
+	case PolicyOperatorNe:
+		return !equalAttributes(actual, expected)
+	case PolicyOperatorLt, PolicyOperatorLte, PolicyOperatorGt, PolicyOperatorGte:
+		order, comparable := orderAttributes(actual, expected)
+		if !comparable {
+			return false
+		}
+		switch operator {
+		case PolicyOperatorLt:
+			return order < 0
+		case PolicyOperatorLte:
+			return order <= 0
+		case PolicyOperatorGt:
+			return order > 0
+		default:
+			return order >= 0
+		}
+	case PolicyOperatorIn, PolicyOperatorNotIn:
+		values, ok := expected.([]any)
+		if !ok {
+			return false
+		}
+		found := false
+		for _, value := range values {
+			if equalAttributes(actual, value) {
+				found = true
+				break
+			}
+		}
+		return found == (operator == PolicyOperatorIn)
+	}
+
+	return false
+}
+
+// equalAttributes numbers are compared by value - JSON numbers (float64) equal ids of the records (int)
+func equalAttributes(a any, b any) bool {
+	aNumber, aIsNumber := numberOf(a)
+	bNumber, bIsNumber := numberOf(b)
+	if aIsNumber || bIsNumber {
+		return aIsNumber && bIsNumber && aNumber == bNumber
+	}
+
+	switch aValue := a.(type) {
+	case string:
+		bValue, ok := b.(string)
+		return ok && aValue == bValue
+	case bool:
+		bValue, ok := b.(bool)
+		return ok && aValue == bValue
+	}
+
+	return false
+}
+
+// orderAttributes -1, 0 or 1. Only numbers and strings have order
+func orderAttributes(a any, b any) (int, bool) {
+	aNumber, aIsNumber := numberOf(a)
+	bNumber, bIsNumber := numberOf(b)
+	if aIsNumber && bIsNumber {
+		switch {
+		case aNumber < bNumber:
+			return -1, true
+		case aNumber > bNumber:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	aString, aIsString := a.(string)
+	bString, bIsString := b.(string)
+	if aIsString && bIsString {
+		return strings.Compare(aString, bString), true
+	}
+
+	return 0, false
+}
+
+func numberOf(value any) (float64, bool) {
+	switch number := value.(type) {
+	case int:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case float64:
+		return number, true
+	}
+
+	return 0, false
+}
+
+func isOrderedValue(value any) bool {
+	if _, ok := numberOf(value); ok {
+		return true
+	}
+	_, ok := value.(string)
+
+	return ok
+}
+
+func isPolicyAttribute(attribute string) bool {
+	for _, prefix := range []string{SubjectAttributePrefix, ResourceAttributePrefix, RequestAttributePrefix} {
+		if strings.HasPrefix(attribute, prefix) && len(attribute) > len(prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/database/repositories"
+)
+
+// recordResolver finds what access checks need to know about a record of the type: its owner (OWNER scope), attendees
+// (ATTENDEE scope), location (permissions of one location) and attributes of access policies. Nil - the record has none
+type recordResolver struct {
+	owner      func(a *AuthService, ctx context.Context, recordId int) (int, error)
+	isAttendee func(a *AuthService, ctx context.Context, recordId int, userId int) (bool, error)
+	location   func(a *AuthService, ctx context.Context, recordId int) (int, error)
+	// attributes adds resource.* attributes. recordId is 0 for records being created and lists of records
+	attributes func(a *AuthService, ctx context.Context, recordId int, request RequestAttributes, attributes map[string]any) error
+}
+
+// recordResolvers by record type - the first segment of the route. Records of other types have no owner and location
+var recordResolvers = map[string]recordResolver{
+	"room":     {owner: (*AuthService).roomOwner, location: (*AuthService).roomLocation, attributes: (*AuthService).roomAttributes},
+	"display":  {location: (*AuthService).roomLocation, attributes: (*AuthService).roomAttributes},
+	"report":   {location: (*AuthService).roomLocation, attributes: (*AuthService).roomAttributes},
+	"booking":  {owner: (*AuthService).bookingOwner, isAttendee: (*AuthService).isBookingAttendee, location: (*AuthService).bookingLocation, attributes: (*AuthService).bookingAttributes},
+	"user":     {owner: (*AuthService).userOwner, attributes: (*AuthService).userAttributes},
+	"location": {location: (*AuthService).locationLocation, attributes: (*AuthService).locationAttributes},
+	"building": {location: (*AuthService).buildingLocation, attributes: (*AuthService).buildingAttributes},
+	"floor":    {location: (*AuthService).floorLocation, attributes: (*AuthService).floorAttributes},
+}
+
+func (a *AuthService) roomOwner(ctx context.Context, roomId int) (int, error) {
+	foundRoom, err := a.roomService.GetRoomById(ctx, roomId)
+	if err != nil {
+		return 0, err
+	}
+
+	return foundRoom.CreatedBy, nil
+}
+
+// roomLocation rooms without location are out of any location - 0 is returned
+func (a *AuthService) roomLocation(ctx context.Context, roomId int) (int, error) {
+	foundLocation, err := a.locationService.GetLocationByRoomId(ctx, roomId)
+	if errors.Is(err, repositories.ErrRoomWithoutLocation) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return foundLocation.LocationId, nil
+}
+
+func (a *AuthService) roomAttributes(ctx context.Context, roomId int, request RequestAttributes, attributes map[string]any) error {
+	if roomId == 0 {
+		return nil
+	}
+
+	return a.addRoomAttributes(ctx, attributes, roomId)
+}
+
+func (a *AuthService) bookingOwner(ctx context.Context, bookingId int) (int, error) {
+	foundBooking, err := a.bookingService.GetBookingById(ctx, bookingId)
+	if err != nil {
+		return 0, err
+	}
+
+	return foundBooking.CreatedBy, nil
+}
+
+func (a *AuthService) isBookingAttendee(ctx context.Context, bookingId int, userId int) (bool, error) {
+	return a.attendeeService.IsAttendee(ctx, bookingId, userId)
+}
+
+func (a *AuthService) bookingLocation(ctx context.Context, bookingId int) (int, error) {
+	foundBooking, err := a.bookingService.GetBookingById(ctx, bookingId)
+	if err != nil {
+		return 0, err
+	}
+
+	return a.roomLocation(ctx, foundBooking.RoomId)
+}
+
+// bookingAttributes bookings being created have no id yet - their room is taken from `room_id` of the request
+func (a *AuthService) bookingAttributes(ctx context.Context, bookingId int, request RequestAttributes, attributes map[string]any) error {
+	if bookingId == 0 {
+		if requestRoomId, ok := numberOf(request["room_id"]); ok && requestRoomId != 0 {
+			return a.addRoomAttributes(ctx, attributes, int(requestRoomId))
+		}
+		return nil
+	}
+
+	foundBooking, err := a.bookingService.GetBookingById(ctx, bookingId)
+	if err != nil {
+		return err
+	}
+	if err := a.addRoomAttributes(ctx, attributes, foundBooking.RoomId); err != nil {
+		return err
+	}
+	attributes["resource.user_id"] = foundBooking.UserId
+	attributes["resource.created_by"] = foundBooking.CreatedBy
+
+	return nil
+}
+
+func (a *AuthService) userOwner(ctx context.Context, userId int) (int, error) {
+	foundUser, err := a.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	return foundUser.UserId, nil
+}
+
+func (a *AuthService) userAttributes(ctx context.Context, userId int, request RequestAttributes, attributes map[string]any) error {
+	if userId == 0 {
+		return nil
+	}
+
+	foundUser, err := a.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+	attributes["resource.user_id"] = foundUser.UserId
+	attributes["resource.role_id"] = foundUser.RoleId
+	if foundUser.FloorId != 0 {
+		attributes["resource.floor_id"] = foundUser.FloorId
+	}
+
+	return nil
+}
+
+func (a *AuthService) locationLocation(ctx context.Context, locationId int) (int, error) {
+	return locationId, nil
+}
+
+func (a *AuthService) locationAttributes(ctx context.Context, locationId int, request RequestAttributes, attributes map[string]any) error {
+	if locationId != 0 {
+		attributes["resource.location_id"] = locationId
+	}
+
+	return nil
+}
+
+func (a *AuthService) buildingLocation(ctx context.Context, buildingId int) (int, error) {
+	foundBuilding, err := a.buildingService.GetBuildingById(ctx, buildingId)
+	if err != nil {
+		return 0, err
+	}
+
+	return foundBuilding.LocationId, nil
+}
+
+func (a *AuthService) buildingAttributes(ctx context.Context, buildingId int, request RequestAttributes, attributes map[string]any) error {
+	if buildingId == 0 {
+		return nil
+	}
+
+	foundBuilding, err := a.buildingService.GetBuildingById(ctx, buildingId)
+	if err != nil {
+		return err
+	}
+	attributes["resource.building_id"] = foundBuilding.BuildingId
+	attributes["resource.location_id"] = foundBuilding.LocationId
+
+	return nil
+}
+
+func (a *AuthService) floorLocation(ctx context.Context, floorId int) (int, error) {
+	foundFloor, err := a.floorService.GetFloorById(ctx, floorId)
+	if err != nil {
+		return 0, err
+	}
+
+	return a.buildingLocation(ctx, foundFloor.BuildingId)
+}
+
+func (a *AuthService) floorAttributes(ctx context.Context, floorId int, request RequestAttributes, attributes map[string]any) error {
+	if floorId == 0 {
+		return nil
+	}
+
+	return a.addFloorAttributes(ctx, attributes, floorId)
+}
+
+// addRoomAttributes the room, its capacity, creator and place
+func (a *AuthService) addRoomAttributes(ctx context.Context, attributes map[string]any, roomId int) error {
+	foundRoom, err := a.roomService.GetRoomById(ctx, roomId)
+	if err != nil {
+		return err
+	}
+	attributes["resource.room_id"] = foundRoom.RoomId
+	attributes["resource.capacity"] = foundRoom.Capacity
+	attributes["resource.created_by"] = foundRoom.CreatedBy
+
+	return a.addFloorAttributes(ctx, attributes, foundRoom.FloorId)
+}
+
+// addFloorAttributes floor, building and location of the record
+func (a *AuthService) addFloorAttributes(ctx context.Context, attributes map[string]any, floorId int) error {
+	foundFloor, err := a.floorService.GetFloorById(ctx, floorId)
+	if err != nil {
+		return err
+	}
+	foundBuilding, err := a.buildingService.GetBuildingById(ctx, foundFloor.BuildingId)
+	if err != nil {
+		return err
+	}
+	attributes["resource.floor_id"] = foundFloor.FloorId
+	attributes["resource.building_id"] = foundBuilding.BuildingId
+	attributes["resource.location_id"] = foundBuilding.LocationId
+
+	return nil
+}
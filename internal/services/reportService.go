@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/models"
+	"math"
+	"time"
+)
+
+const (
+	// MaxReportRange reports are built per day in SQL - range is limited to keep queries cheap
+	MaxReportRange         = 366 * 24 * time.Hour
+	DefaultTopBookersLimit = 10
+	MaxTopBookersLimit     = 100
+)
+
+type ReportService struct {
+	repository database.ReportRepository
+}
+
+func NewReportService(repository database.ReportRepository) *ReportService {
+	return &ReportService{repository: repository}
+}
+
+// GetUtilization booked minutes of rooms to minutes within opening hours, per day, week or month
+func (r *ReportService) GetUtilization(ctx context.Context, filter models.ReportFilter) ([]models.RoomUtilization, error) {
+	ctx, span := tracer.Start(ctx, "ReportService.GetUtilization")
+	defer span.End()
+
+	if filter.Period == "" {
+		filter.Period = models.ReportPeriodDay
+	}
+	if filter.Period != models.ReportPeriodDay && filter.Period != models.ReportPeriodWeek && filter.Period != models.ReportPeriodMonth {
+		return nil, errors.New("period should be one of: day, week, month")
+	}
+	if err := validateReportFilter(filter); err != nil {
+		return nil, err
+	}
+
+	utilization, err := r.repository.GetUtilization(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range utilization {
+		utilization[i].UtilizationPct = percentage(utilization[i].BookedMinutes, utilization[i].OpenMinutes)
+	}
+
+	return utilization, nil
+}
+
+func (r *ReportService) GetPeakHours(ctx context.Context, filter models.ReportFilter) ([]models.PeakHour, error) {
+	ctx, span := tracer.Start(ctx, "ReportService.GetPeakHours")
+	defer span.End()
+
+	if err := validateReportFilter(filter); err != nil {
+		return nil, err
+	}
+
+	return r.repository.GetPeakHours(ctx, filter)
+}
+
+// GetBookingStats average duration, cancellation and no-show rates of bookings per room
+func (r *ReportService) GetBookingStats(ctx context.Context, filter models.ReportFilter) ([]models.RoomBookingStats, error) {
+	ctx, span := tracer.Start(ctx, "ReportService.GetBookingStats")
+	defer span.End()
+
+	if err := validateReportFilter(filter); err != nil {
+		return nil, err
+	}
+
+	bookingStats, err := r.repository.GetBookingStats(ctx, filter, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range bookingStats {
+		bookingStats[i].AverageDurationMinutes = math.Round(bookingStats[i].AverageDurationMinutes*100) / 100
+		bookingStats[i].CancellationRatePct = percentage(bookingStats[i].CancelledBookings, bookingStats[i].TotalBookings)
+		bookingStats[i].NoShowRatePct = percentage(bookingStats[i].NoShowBookings, bookingStats[i].TotalBookings-bookingStats[i].CancelledBookings)
+	}
+
+	return bookingStats, nil
+}
+
+func (r *ReportService) GetTopBookers(ctx context.Context, filter models.ReportFilter) ([]models.TopBooker, error) {
+	ctx, span := tracer.Start(ctx, "ReportService.GetTopBookers")
+	defer span.End()
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultTopBookersLimit
+	}
+	if filter.Limit < 0 || filter.Limit > MaxTopBookersLimit {
+		return nil, errors.New("limit should be between 1 and 100")
+	}
+	if err := validateReportFilter(filter); err != nil {
+		return nil, err
+	}
+
+	return r.repository.GetTopBookers(ctx, filter)
+}
+
+func validateReportFilter(filter models.ReportFilter) error {
+	if filter.DateTimeStart.IsZero() || filter.DateTimeEnd.IsZero() {
+		return errors.New("report range should be passed: datetime_start and datetime_end")
+	}
+	if !filter.DateTimeStart.Before(filter.DateTimeEnd) {
+		return errors.New("datetime_start should be before datetime_end")
+	}
+	if filter.DateTimeEnd.Sub(filter.DateTimeStart) > MaxReportRange {
+		return errors.New("report range should not be longer than 366 days")
+	}
+
+	return nil
+}
+
+// percentage rounded to 2 decimal places. Zero total - 0%
+func percentage(part int, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+
+	return math.Round(float64(part)*10000/float64(total)) / 100
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"go-booking-system/internal/database"
 	"go-booking-system/internal/models"
 )
@@ -13,22 +14,37 @@ func NewRoleService(repository database.RoleRepository) *RoleService {
 	return &RoleService{repository: repository}
 }
 
-func (r *RoleService) Create(role models.Role) (models.Role, error) {
-	return r.repository.Create(role)
+func (r *RoleService) Create(ctx context.Context, role models.Role) (models.Role, error) {
+	ctx, span := tracer.Start(ctx, "RoleService.Create")
+	defer span.End()
+
+	return r.repository.Create(ctx, role)
 }
 
-func (r *RoleService) GetAll() []models.Role {
-	return r.repository.GetAll()
+func (r *RoleService) GetAll(ctx context.Context) []models.Role {
+	ctx, span := tracer.Start(ctx, "RoleService.GetAll")
+	defer span.End()
+
+	return r.repository.GetAll(ctx)
 }
 
-func (r *RoleService) GetRoleById(roleId int) (models.Role, error) {
-	return r.repository.GetRoleById(roleId)
+func (r *RoleService) GetRoleById(ctx context.Context, roleId int) (models.Role, error) {
+	ctx, span := tracer.Start(ctx, "RoleService.GetRoleById")
+	defer span.End()
+
+	return r.repository.GetRoleById(ctx, roleId)
 }
 
-func (r *RoleService) Update(role models.Role) (models.Role, error) {
-	return r.repository.Update(role)
+func (r *RoleService) Update(ctx context.Context, role models.Role) (models.Role, error) {
+	ctx, span := tracer.Start(ctx, "RoleService.Update")
+	defer span.End()
+
+	return r.repository.Update(ctx, role)
 }
 
-func (r *RoleService) Delete(roleId int) (bool, error) {
-	return r.repository.Delete(roleId)
+func (r *RoleService) Delete(ctx context.Context, roleId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "RoleService.Delete")
+	defer span.End()
+
+	return r.repository.Delete(ctx, roleId)
 }
@@ -1,34 +1,126 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"go-booking-system/internal/database"
+	"go-booking-system/internal/database/repositories"
 	"go-booking-system/internal/models"
+	"time"
 )
 
 type RoomService struct {
-	repository database.RoomRepository
+	repository          database.RoomRepository
+	roomShareRepository database.RoomShareRepository
+
+	// organization of the caller. repositories.SystemOrganizationId - not limited
+	organizationId int
+}
+
+func NewRoomService(repository database.RoomRepository, roomShareRepository database.RoomShareRepository, organizationId int) *RoomService {
+	return &RoomService{repository: repository, roomShareRepository: roomShareRepository, organizationId: organizationId}
+}
+
+func (r *RoomService) Create(ctx context.Context, room models.Room) (models.Room, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.Create")
+	defer span.End()
+
+	return r.repository.Create(ctx, room)
+}
+
+func (r *RoomService) GetAll(ctx context.Context) []models.Room {
+	ctx, span := tracer.Start(ctx, "RoomService.GetAll")
+	defer span.End()
+
+	return r.repository.GetAll(ctx)
 }
 
-func NewRoomService(repository database.RoomRepository) *RoomService {
-	return &RoomService{repository: repository}
+func (r *RoomService) GetRoomById(ctx context.Context, roomId int) (models.Room, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.GetRoomById")
+	defer span.End()
+
+	return r.repository.GetRoomById(ctx, roomId)
 }
 
-func (r *RoomService) Create(room models.Room) (models.Room, error) {
-	return r.repository.Create(room)
+func (r *RoomService) GetRoomsByLocationId(ctx context.Context, locationId int) ([]models.Room, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.GetRoomsByLocationId")
+	defer span.End()
+
+	return r.repository.GetRoomsByLocationId(ctx, locationId)
 }
 
-func (r *RoomService) GetAll() []models.Room {
-	return r.repository.GetAll()
+func (r *RoomService) GetAvailableRoomsByLocationId(ctx context.Context, locationId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Room, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.GetAvailableRoomsByLocationId")
+	defer span.End()
+
+	return r.repository.GetAvailableRoomsByLocationId(ctx, locationId, dateTimeStart, dateTimeEnd)
 }
 
-func (r *RoomService) GetRoomById(roomId int) (models.Room, error) {
-	return r.repository.GetRoomById(roomId)
+func (r *RoomService) Update(ctx context.Context, room models.Room) (models.Room, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.Update")
+	defer span.End()
+
+	return r.repository.Update(ctx, room)
 }
 
-func (r *RoomService) Update(room models.Room) (models.Room, error) {
-	return r.repository.Update(room)
+func (r *RoomService) Delete(ctx context.Context, roomId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.Delete")
+	defer span.End()
+
+	return r.repository.Delete(ctx, roomId)
 }
 
-func (r *RoomService) Delete(roomId int) (bool, error) {
-	return r.repository.Delete(roomId)
+// ShareRoom lets another organization book the room within quota. Only owner of the room can share it
+func (r *RoomService) ShareRoom(ctx context.Context, roomShare models.RoomShare) (models.RoomShare, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.ShareRoom")
+	defer span.End()
+
+	room, err := r.getOwnRoom(ctx, roomShare.RoomId)
+	if err != nil {
+		return models.RoomShare{}, err
+	}
+	if roomShare.OrganizationId == room.OrganizationId {
+		return models.RoomShare{}, errors.New("room cannot be shared with its owner")
+	}
+	if roomShare.QuotaMinutes < 0 {
+		return models.RoomShare{}, errors.New("quota should not be negative")
+	}
+
+	roomShare.Active = true
+	return r.roomShareRepository.Create(ctx, roomShare)
+}
+
+func (r *RoomService) GetRoomShares(ctx context.Context, roomId int) ([]models.RoomShare, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.GetRoomShares")
+	defer span.End()
+
+	if _, err := r.getOwnRoom(ctx, roomId); err != nil {
+		return nil, err
+	}
+
+	return r.roomShareRepository.GetRoomSharesByRoomId(ctx, roomId)
+}
+
+func (r *RoomService) UnshareRoom(ctx context.Context, roomId int, organizationId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.UnshareRoom")
+	defer span.End()
+
+	if _, err := r.getOwnRoom(ctx, roomId); err != nil {
+		return false, err
+	}
+
+	return r.roomShareRepository.Delete(ctx, roomId, organizationId)
+}
+
+// getOwnRoom returns room if it belongs to caller's organization (shared rooms are visible but not owned)
+func (r *RoomService) getOwnRoom(ctx context.Context, roomId int) (models.Room, error) {
+	room, err := r.repository.GetRoomById(ctx, roomId)
+	if err != nil {
+		return models.Room{}, err
+	}
+	if r.organizationId != repositories.SystemOrganizationId && room.OrganizationId != r.organizationId {
+		return models.Room{}, errors.New("room belongs to another organization")
+	}
+
+	return room, nil
 }
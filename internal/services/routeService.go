@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"go-booking-system/internal/database"
 	"go-booking-system/internal/models"
 )
@@ -13,26 +14,44 @@ func NewRouteService(repository database.RouteRepository) *RouteService {
 	return &RouteService{repository: repository}
 }
 
-func (r *RouteService) Create(route models.Route) (models.Route, error) {
-	return r.repository.Create(route)
+func (r *RouteService) Create(ctx context.Context, route models.Route) (models.Route, error) {
+	ctx, span := tracer.Start(ctx, "RouteService.Create")
+	defer span.End()
+
+	return r.repository.Create(ctx, route)
 }
 
-func (r *RouteService) GetAll() []models.Route {
-	return r.repository.GetAll()
+func (r *RouteService) GetAll(ctx context.Context) []models.Route {
+	ctx, span := tracer.Start(ctx, "RouteService.GetAll")
+	defer span.End()
+
+	return r.repository.GetAll(ctx)
 }
 
-func (r *RouteService) GetRouteById(routeId int) (models.Route, error) {
-	return r.repository.GetRouteById(routeId)
+func (r *RouteService) GetRouteById(ctx context.Context, routeId int) (models.Route, error) {
+	ctx, span := tracer.Start(ctx, "RouteService.GetRouteById")
+	defer span.End()
+
+	return r.repository.GetRouteById(ctx, routeId)
 }
 
-func (r *RouteService) GetRouteByURL(url string) (models.Route, error) {
-	return r.repository.GetRouteByURL(url)
+func (r *RouteService) GetRouteByURL(ctx context.Context, url string) (models.Route, error) {
+	ctx, span := tracer.Start(ctx, "RouteService.GetRouteByURL")
+	defer span.End()
+
+	return r.repository.GetRouteByURL(ctx, url)
 }
 
-func (r *RouteService) Update(route models.Route) (models.Route, error) {
-	return r.repository.Update(route)
+func (r *RouteService) Update(ctx context.Context, route models.Route) (models.Route, error) {
+	ctx, span := tracer.Start(ctx, "RouteService.Update")
+	defer span.End()
+
+	return r.repository.Update(ctx, route)
 }
 
-func (r *RouteService) Delete(routeId int) (bool, error) {
-	return r.repository.Delete(routeId)
+func (r *RouteService) Delete(ctx context.Context, routeId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "RouteService.Delete")
+	defer span.End()
+
+	return r.repository.Delete(ctx, routeId)
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"go-booking-system/internal/database"
 	"go-booking-system/internal/models"
 )
@@ -13,22 +14,37 @@ func NewScopeService(repository database.ScopeRepository) *ScopeService {
 	return &ScopeService{repository: repository}
 }
 
-func (r *ScopeService) Create(scope models.Scope) (models.Scope, error) {
-	return r.repository.Create(scope)
+func (r *ScopeService) Create(ctx context.Context, scope models.Scope) (models.Scope, error) {
+	ctx, span := tracer.Start(ctx, "ScopeService.Create")
+	defer span.End()
+
+	return r.repository.Create(ctx, scope)
 }
 
-func (r *ScopeService) GetAll() []models.Scope {
-	return r.repository.GetAll()
+func (r *ScopeService) GetAll(ctx context.Context) []models.Scope {
+	ctx, span := tracer.Start(ctx, "ScopeService.GetAll")
+	defer span.End()
+
+	return r.repository.GetAll(ctx)
 }
 
-func (r *ScopeService) GetScopeById(scopeId int) (models.Scope, error) {
-	return r.repository.GetScopeById(scopeId)
+func (r *ScopeService) GetScopeById(ctx context.Context, scopeId int) (models.Scope, error) {
+	ctx, span := tracer.Start(ctx, "ScopeService.GetScopeById")
+	defer span.End()
+
+	return r.repository.GetScopeById(ctx, scopeId)
 }
 
-func (r *ScopeService) Update(scope models.Scope) (models.Scope, error) {
-	return r.repository.Update(scope)
+func (r *ScopeService) Update(ctx context.Context, scope models.Scope) (models.Scope, error) {
+	ctx, span := tracer.Start(ctx, "ScopeService.Update")
+	defer span.End()
+
+	return r.repository.Update(ctx, scope)
 }
 
-func (r *ScopeService) Delete(scopeId int) (bool, error) {
-	return r.repository.Delete(scopeId)
+func (r *ScopeService) Delete(ctx context.Context, scopeId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "ScopeService.Delete")
+	defer span.End()
+
+	return r.repository.Delete(ctx, scopeId)
 }
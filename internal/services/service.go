@@ -1,12 +1,26 @@
 package services
 
 import (
+	"context"
 	"go-booking-system/internal/database"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/directory"
 	"go-booking-system/internal/models"
+	"go-booking-system/internal/notify"
+	"go-booking-system/internal/oidc"
+	"go-booking-system/internal/tracing"
 	"go-booking-system/pkg"
+	"go.opentelemetry.io/otel"
+	"net/http"
 	"time"
 )
 
+// ssoRequestTimeout requests to identity provider are made during log in - user waits for them
+const ssoRequestTimeout = 10 * time.Second
+
+// tracer starts span for every call of service methods
+var tracer = otel.Tracer(tracing.ServiceName)
+
 type Service struct {
 	BookingService    BookingServiceInterface
 	RoomService       RoomServiceInterface
@@ -16,99 +30,322 @@ type Service struct {
 	RouteService      RouteServiceInterface
 	ScopeService      ScopeServiceInterface
 	PermissionService PermissionServiceInterface
+	DeviceService     DeviceServiceInterface
+	LocationService   LocationServiceInterface
+	BuildingService   BuildingServiceInterface
+	FloorService      FloorServiceInterface
+
+	OrganizationService   OrganizationServiceInterface
+	AttendeeService       AttendeeServiceInterface
+	ReportService         ReportServiceInterface
+	BulkService           BulkServiceInterface
+	HealthService         HealthServiceInterface
+	MFAService            MFAServiceInterface
+	SSOService            SSOServiceInterface
+	DirectorySyncService  DirectorySyncServiceInterface
+	AccountService        AccountServiceInterface
+	ServiceAccountService ServiceAccountServiceInterface
+	PolicyService         PolicyServiceInterface
+
+	database        *database.Database
+	authConfig      AuthConfig
+	ssoProvider     *oidc.Provider
+	directoryClient *directory.Client
+	notifier        notify.Notifier
+}
+
+// NewService returns services which are not limited to any organization (login, registration, device authentication).
+// notifier delivers links of password reset and email verification
+func NewService(db *database.Database, authConfig AuthConfig, notifier notify.Notifier) *Service {
+	// provider caches metadata and keys of identity provider - it is shared by services of all organizations
+	ssoProvider := oidc.NewProvider(authConfig.SSO, &http.Client{Timeout: ssoRequestTimeout})
+	var directoryClient *directory.Client
+	if authConfig.LDAP.Enabled {
+		directoryClient = directory.NewClient(authConfig.LDAP)
+	}
+
+	return newService(db, authConfig, repositories.SystemOrganizationId, ssoProvider, directoryClient, notifier)
+}
+
+// ForOrganization returns services working only with data of the organization (tenant) - built per request from token claims
+func (s *Service) ForOrganization(organizationId int) *Service {
+	return newService(s.database.ForOrganization(organizationId), s.authConfig, organizationId, s.ssoProvider, s.directoryClient, s.notifier)
 }
 
-func NewService(db *database.Database) *Service {
-	bookingService := NewBookingService(db.BookingRepository)
-	roomService := NewRoomService(db.RoomRepository)
+func newService(db *database.Database, authConfig AuthConfig, organizationId int, ssoProvider *oidc.Provider, directoryClient *directory.Client, notifier notify.Notifier) *Service {
+	locationService := NewLocationService(db.LocationRepository, db.OpeningHoursRepository)
+	buildingService := NewBuildingService(db.BuildingRepository)
+	floorService := NewFloorService(db.FloorRepository)
+
+	roomService := NewRoomService(db.RoomRepository, db.RoomShareRepository, organizationId)
+	bookingService := NewBookingService(db.BookingRepository, locationService, roomService, db.RoomShareRepository, db.AttendeeRepository, organizationId)
+	userService := NewUserService(db.UserRepository)
+	attendeeService := NewAttendeeService(db.AttendeeRepository, bookingService, userService)
 
 	roleService := NewRoleService(db.RoleRepository)
 	routeService := NewRouteService(db.RouteRepository)
 	scopeService := NewScopeService(db.ScopeRepository)
 	permissionService := NewPermissionService(db.PermissionRepository)
 
+	authService := NewAuthService(authConfig, db.UserRepository, roleService, routeService, scopeService, permissionService, bookingService, roomService, attendeeService, locationService, buildingService, floorService, db.PolicyRepository)
+	if directoryClient != nil {
+		authService.RegisterAuthenticationProvider(AuthProviderLDAP, NewLDAPAuthenticationProvider(directoryClient))
+	}
+
 	return &Service{
 		BookingService:    bookingService,
 		RoomService:       roomService,
-		UserService:       NewUserService(db.UserRepository),
-		AuthService:       NewAuthService(db.UserRepository, roleService, routeService, scopeService, permissionService, bookingService, roomService),
+		UserService:       userService,
+		AuthService:       authService,
 		RoleService:       roleService,
 		RouteService:      routeService,
 		ScopeService:      scopeService,
 		PermissionService: permissionService,
+		DeviceService:     NewDeviceService(db.DeviceRepository, bookingService, roomService),
+		LocationService:   locationService,
+		BuildingService:   buildingService,
+		FloorService:      floorService,
+
+		OrganizationService:   NewOrganizationService(db.OrganizationRepository, organizationId),
+		AttendeeService:       attendeeService,
+		ReportService:         NewReportService(db.ReportRepository),
+		BulkService:           NewBulkService(db.UserRepository, db.RoomRepository, authService, roomService, organizationId),
+		HealthService:         NewHealthService(db),
+		MFAService:            NewMFAService(authConfig, db.UserRepository, db.RecoveryCodeRepository, db.MFAPolicyRepository, roleService, routeService),
+		SSOService:            NewSSOService(authConfig, ssoProvider, db.UserRepository, authService),
+		DirectorySyncService:  NewDirectorySyncService(directoryClient, db.UserRepository, authService),
+		AccountService:        NewAccountService(authConfig, db.UserRepository, db.UserTokenRepository, authService, notifier),
+		ServiceAccountService: NewServiceAccountService(authConfig, db.UserRepository, db.APIKeyRepository, authService, routeService, scopeService, permissionService),
+		PolicyService:         NewPolicyService(db.PolicyRepository, db.UserRepository, authService, routeService),
+
+		database:        db,
+		authConfig:      authConfig,
+		ssoProvider:     ssoProvider,
+		directoryClient: directoryClient,
+		notifier:        notifier,
 	}
 }
 
 type AuthServiceInterface interface {
-	Create(user models.User) (models.User, error)
-	UpdatePassword(userId int, password string) (models.User, error)
-	UpdateUsername(userId int, username string) (models.User, error)
-	UpdateRole(userId int, roleId int) (models.User, error)
-	CheckIfUserExistsAndPasswordIsCorrect(username string, password string) (models.User, error)
-	CheckPermissions(destination string, recordType string, recordId string, subject string, roleString string) (bool, error)
-	GeneratePasswordHash(password string) string
-	GenerateTokens(user models.User, identity pkg.IPAddressIdentity) (accessToken pkg.JWTToken, refreshToken pkg.JWTToken)
-	ValidateAccessToken(encodedToken string, ipAddress string) *JWTTokenValidator
-	ValidateRefreshToken(encodedToken string, ipAddress string) *JWTTokenValidator
+	Create(ctx context.Context, createdBy int, user models.User) (models.User, error)
+	UpdatePassword(ctx context.Context, userId int, password string) (models.User, error)
+	UpdateUsername(ctx context.Context, userId int, username string) (models.User, error)
+	UpdateRole(ctx context.Context, userId int, roleId int) (models.User, error)
+	ChangeRole(ctx context.Context, changedBy int, userId int, roleId int) (models.User, error)
+	CheckRoleChangeIsAllowed(ctx context.Context, changedBy int, userId int) error
+	CheckRoleIsAvailable(ctx context.Context, roleId int) error
+	CheckIfUserExistsAndPasswordIsCorrect(ctx context.Context, username string, password string) (models.User, error)
+	CheckPermissions(ctx context.Context, destination string, recordType string, recordId string, subject string, roleString string, request RequestAttributesSource) (bool, error)
+	ExplainAccess(ctx context.Context, destination string, recordType string, recordString string, userId int, roleId int, request RequestAttributes, dryRunPolicies []models.Policy) (models.AccessExplanation, error)
+	CheckAPIKeyPermissions(ctx context.Context, apiKey models.APIKey, roleId int, destination string, recordType string, recordString string) (bool, error)
+	GeneratePasswordHash(ctx context.Context, password string) string
+	GenerateTokens(ctx context.Context, user models.User, identity pkg.IPAddressIdentity, authMethods []string) (accessToken pkg.JWTToken, refreshToken pkg.JWTToken)
+	ValidateAccessToken(ctx context.Context, encodedToken string, sentFrom pkg.IPAddressIdentity) *JWTTokenValidator
+	ValidateRefreshToken(ctx context.Context, encodedToken string, sentFrom pkg.IPAddressIdentity) *JWTTokenValidator
+}
+
+type MFAServiceInterface interface {
+	Enroll(ctx context.Context, userId int) (models.MFAEnrollment, error)
+	Confirm(ctx context.Context, userId int, code string) ([]string, error)
+	Disable(ctx context.Context, userId int, code string) error
+	Reset(ctx context.Context, userId int) error
+	RegenerateRecoveryCodes(ctx context.Context, userId int, code string) ([]string, error)
+	Verify(ctx context.Context, user models.User, code string) ([]string, error)
+	IsMFARequired(ctx context.Context, roleId int) (bool, error)
+	CheckMFARequirement(ctx context.Context, destination string, roleId int, authMethods []string) error
+	GetMFAPolicies(ctx context.Context) ([]models.MFAPolicy, error)
+	ReplaceMFAPolicies(ctx context.Context, roleIds []int, createdBy int) ([]models.MFAPolicy, error)
+	IssueChallenge(ctx context.Context, user models.User, identity pkg.IPAddressIdentity) (string, error)
+	ValidateChallenge(ctx context.Context, challenge string, sentFrom pkg.IPAddressIdentity) (MFAChallengeClaims, error)
+}
+
+type SSOServiceInterface interface {
+	OrganizationId() int
+	StartLogin(ctx context.Context, identity pkg.IPAddressIdentity) (string, string, error)
+	Authenticate(ctx context.Context, code string, state string, flowToken string, sentFrom pkg.IPAddressIdentity) (oidc.Claims, error)
+	Provision(ctx context.Context, claims oidc.Claims) (models.User, error)
+}
+
+type AccountServiceInterface interface {
+	Register(ctx context.Context, user models.User) (models.User, error)
+	ResendEmailVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) (models.User, error)
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, password string) error
+	GetProfile(ctx context.Context, userId int) (models.User, error)
+	UpdateProfile(ctx context.Context, user models.User) (models.User, error)
+	ChangePassword(ctx context.Context, userId int, currentPassword string, newPassword string) error
+	ChangeUsername(ctx context.Context, userId int, password string, username string) (models.User, error)
+}
+
+type ServiceAccountServiceInterface interface {
+	CreateServiceAccount(ctx context.Context, createdBy int, user models.User) (models.User, error)
+	GetServiceAccounts(ctx context.Context) ([]models.User, error)
+	CreateAPIKey(ctx context.Context, apiKey models.APIKey) (models.APIKey, string, error)
+	GetAPIKeys(ctx context.Context) []models.APIKey
+	RevokeAPIKey(ctx context.Context, apiKeyId int) (bool, error)
+	Authenticate(ctx context.Context, key string) (models.APIKey, models.User, error)
+}
+
+type PolicyServiceInterface interface {
+	CreatePolicy(ctx context.Context, policy models.Policy) (models.Policy, error)
+	GetPolicies(ctx context.Context) []models.Policy
+	UpdatePolicy(ctx context.Context, policy models.Policy) (models.Policy, error)
+	DeletePolicy(ctx context.Context, policyId int) (bool, error)
+	Explain(ctx context.Context, request models.AccessExplanationRequest) (models.AccessExplanation, error)
+}
+
+type DirectorySyncServiceInterface interface {
+	OrganizationId() int
+	Sync(ctx context.Context) (models.DirectorySyncResult, error)
 }
 
 type BookingServiceInterface interface {
-	CheckIfRoomAvailable(roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) (bool, error)
-	BookRoom(userId int, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time, createdBy int) (models.Booking, error)
-	GetAll() []models.Booking
-	GetBookingById(bookingId int) (models.Booking, error)
-	GetBookingsByRoomId(roomId int) ([]models.Booking, error)
-	GetBookingsByRoomIdAndBookingTime(roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Booking, error)
-	Update(booking models.Booking) (models.Booking, error)
-	Delete(bookingId int) (bool, error)
+	CheckIfRoomAvailable(ctx context.Context, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) (bool, error)
+	BookRoom(ctx context.Context, userId int, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time, createdBy int) (models.Booking, error)
+	GetAll(ctx context.Context) []models.Booking
+	GetBookingById(ctx context.Context, bookingId int) (models.Booking, error)
+	GetBookingsByRoomId(ctx context.Context, roomId int) ([]models.Booking, error)
+	GetBookingsByRoomIdAndBookingTime(ctx context.Context, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Booking, error)
+	GetCurrentAndNextBookings(ctx context.Context, roomId int, at time.Time) (current *models.Booking, next *models.Booking, err error)
+	Update(ctx context.Context, booking models.Booking) (models.Booking, error)
+	CheckIn(ctx context.Context, roomId int, at time.Time) (models.Booking, error)
+	CheckCapacity(ctx context.Context, roomId int, bookingId int, additionalAttendees int) error
+	Delete(ctx context.Context, bookingId int) (bool, error)
+	Purge(ctx context.Context, before time.Time) (int, error)
 }
 
 type RoomServiceInterface interface {
-	Create(room models.Room) (models.Room, error)
-	GetAll() []models.Room
-	GetRoomById(roomId int) (models.Room, error)
-	Update(room models.Room) (models.Room, error)
-	Delete(roomId int) (bool, error)
+	Create(ctx context.Context, room models.Room) (models.Room, error)
+	GetAll(ctx context.Context) []models.Room
+	GetRoomById(ctx context.Context, roomId int) (models.Room, error)
+	GetRoomsByLocationId(ctx context.Context, locationId int) ([]models.Room, error)
+	GetAvailableRoomsByLocationId(ctx context.Context, locationId int, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.Room, error)
+	Update(ctx context.Context, room models.Room) (models.Room, error)
+	Delete(ctx context.Context, roomId int) (bool, error)
+	ShareRoom(ctx context.Context, roomShare models.RoomShare) (models.RoomShare, error)
+	GetRoomShares(ctx context.Context, roomId int) ([]models.RoomShare, error)
+	UnshareRoom(ctx context.Context, roomId int, organizationId int) (bool, error)
 }
 
 type UserServiceInterface interface {
-	GetAll() []models.User
-	GetUserById(userId int) (models.User, error)
-	Update(user models.User) (models.User, error)
-	Delete(userId int) (bool, error)
+	GetAll(ctx context.Context) []models.User
+	GetUserById(ctx context.Context, userId int) (models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)
+	Update(ctx context.Context, user models.User) (models.User, error)
+	Delete(ctx context.Context, userId int) (bool, error)
 }
 
 type RoleServiceInterface interface {
-	Create(role models.Role) (models.Role, error)
-	GetAll() []models.Role
-	GetRoleById(roleId int) (models.Role, error)
-	Update(role models.Role) (models.Role, error)
-	Delete(roleId int) (bool, error)
+	Create(ctx context.Context, role models.Role) (models.Role, error)
+	GetAll(ctx context.Context) []models.Role
+	GetRoleById(ctx context.Context, roleId int) (models.Role, error)
+	Update(ctx context.Context, role models.Role) (models.Role, error)
+	Delete(ctx context.Context, roleId int) (bool, error)
 }
 
 type RouteServiceInterface interface {
-	Create(route models.Route) (models.Route, error)
-	GetAll() []models.Route
-	GetRouteById(routeId int) (models.Route, error)
-	GetRouteByURL(url string) (models.Route, error)
-	Update(route models.Route) (models.Route, error)
-	Delete(routeId int) (bool, error)
+	Create(ctx context.Context, route models.Route) (models.Route, error)
+	GetAll(ctx context.Context) []models.Route
+	GetRouteById(ctx context.Context, routeId int) (models.Route, error)
+	GetRouteByURL(ctx context.Context, url string) (models.Route, error)
+	Update(ctx context.Context, route models.Route) (models.Route, error)
+	Delete(ctx context.Context, routeId int) (bool, error)
 }
 
 type ScopeServiceInterface interface {
-	Create(scope models.Scope) (models.Scope, error)
-	GetAll() []models.Scope
-	GetScopeById(scopeId int) (models.Scope, error)
-	Update(scope models.Scope) (models.Scope, error)
-	Delete(scopeId int) (bool, error)
+	Create(ctx context.Context, scope models.Scope) (models.Scope, error)
+	GetAll(ctx context.Context) []models.Scope
+	GetScopeById(ctx context.Context, scopeId int) (models.Scope, error)
+	Update(ctx context.Context, scope models.Scope) (models.Scope, error)
+	Delete(ctx context.Context, scopeId int) (bool, error)
 }
 
 type PermissionServiceInterface interface {
-	Create(permission models.Permission) (models.Permission, error)
-	GetAll() []models.Permission
-	GetPermissionsByRoleId(roleId int) ([]models.Permission, error)
-	GetPermissionsByRouteId(routeId int) ([]models.Permission, error)
-	GetPermissionsByRoleIdAndRouteId(roleId int, routeId int) ([]models.Permission, error)
-	Update(permission models.Permission) (models.Permission, error)
-	Delete(roleId int, routeId int) (bool, error)
+	Create(ctx context.Context, permission models.Permission) (models.Permission, error)
+	GetAll(ctx context.Context) []models.Permission
+	GetPermissionsByRoleId(ctx context.Context, roleId int) ([]models.Permission, error)
+	GetPermissionsByRouteId(ctx context.Context, routeId int) ([]models.Permission, error)
+	GetPermissionsByRoleIdAndRouteId(ctx context.Context, roleId int, routeId int) ([]models.Permission, error)
+	Update(ctx context.Context, permission models.Permission) (models.Permission, error)
+	Delete(ctx context.Context, roleId int, routeId int) (bool, error)
+}
+
+type DeviceServiceInterface interface {
+	Register(ctx context.Context, device models.Device) (models.Device, string, error)
+	GetAll(ctx context.Context) []models.Device
+	GetDeviceById(ctx context.Context, deviceId int) (models.Device, error)
+	Revoke(ctx context.Context, deviceId int) (bool, error)
+	Authenticate(ctx context.Context, deviceToken string) (models.Device, error)
+	GetRoomDisplay(ctx context.Context, roomId int, at time.Time) (models.RoomDisplay, error)
+	BookNow(ctx context.Context, device models.Device, duration time.Duration, at time.Time) (models.Booking, error)
+	CheckIn(ctx context.Context, device models.Device, at time.Time) (models.Booking, error)
+}
+
+type LocationServiceInterface interface {
+	Create(ctx context.Context, location models.Location) (models.Location, error)
+	GetAll(ctx context.Context) []models.Location
+	GetLocationById(ctx context.Context, locationId int) (models.Location, error)
+	GetLocationByRoomId(ctx context.Context, roomId int) (models.Location, error)
+	GetTimeZoneByRoomId(ctx context.Context, roomId int) (*time.Location, error)
+	GetOpeningHours(ctx context.Context, locationId int) ([]models.OpeningHours, error)
+	SetOpeningHours(ctx context.Context, locationId int, openingHours []models.OpeningHours) ([]models.OpeningHours, error)
+	IsRoomOpen(ctx context.Context, roomId int, dateTimeStart time.Time, dateTimeEnd time.Time) (bool, error)
+	Update(ctx context.Context, location models.Location) (models.Location, error)
+	Delete(ctx context.Context, locationId int) (bool, error)
+}
+
+type BuildingServiceInterface interface {
+	Create(ctx context.Context, building models.Building) (models.Building, error)
+	GetAll(ctx context.Context) []models.Building
+	GetBuildingById(ctx context.Context, buildingId int) (models.Building, error)
+	GetBuildingsByLocationId(ctx context.Context, locationId int) ([]models.Building, error)
+	Update(ctx context.Context, building models.Building) (models.Building, error)
+	Delete(ctx context.Context, buildingId int) (bool, error)
+}
+
+type FloorServiceInterface interface {
+	Create(ctx context.Context, floor models.Floor) (models.Floor, error)
+	GetAll(ctx context.Context) []models.Floor
+	GetFloorById(ctx context.Context, floorId int) (models.Floor, error)
+	GetFloorsByBuildingId(ctx context.Context, buildingId int) ([]models.Floor, error)
+	Update(ctx context.Context, floor models.Floor) (models.Floor, error)
+	Delete(ctx context.Context, floorId int) (bool, error)
+}
+
+type OrganizationServiceInterface interface {
+	Create(ctx context.Context, organization models.Organization) (models.Organization, error)
+	GetAll(ctx context.Context) []models.Organization
+	GetOrganizationById(ctx context.Context, organizationId int) (models.Organization, error)
+	Update(ctx context.Context, organization models.Organization) (models.Organization, error)
+	Delete(ctx context.Context, organizationId int) (bool, error)
+}
+
+type AttendeeServiceInterface interface {
+	AddAttendees(ctx context.Context, bookingId int, attendees []models.Attendee, createdBy int) ([]models.Attendee, error)
+	GetAttendees(ctx context.Context, bookingId int) ([]models.Attendee, error)
+	RemoveAttendee(ctx context.Context, bookingId int, attendeeId int) (bool, error)
+	Respond(ctx context.Context, bookingId int, userId int, status string) (models.Attendee, error)
+	GetInvitations(ctx context.Context, userId int) ([]models.Booking, error)
+	IsAttendee(ctx context.Context, bookingId int, userId int) (bool, error)
+	GetVisitorPasses(ctx context.Context, dateTimeStart time.Time, dateTimeEnd time.Time) ([]models.VisitorPass, error)
+	GetVisitorPassByCode(ctx context.Context, passCode string) (models.VisitorPass, error)
+}
+
+type ReportServiceInterface interface {
+	GetUtilization(ctx context.Context, filter models.ReportFilter) ([]models.RoomUtilization, error)
+	GetPeakHours(ctx context.Context, filter models.ReportFilter) ([]models.PeakHour, error)
+	GetBookingStats(ctx context.Context, filter models.ReportFilter) ([]models.RoomBookingStats, error)
+	GetTopBookers(ctx context.Context, filter models.ReportFilter) ([]models.TopBooker, error)
+}
+
+type BulkServiceInterface interface {
+	ImportUser(ctx context.Context, user models.User, importedBy int, dryRun bool) (string, models.User, error)
+	ImportRoom(ctx context.Context, room models.Room, dryRun bool) (string, models.Room, error)
+	ExportUsers(ctx context.Context) []models.User
+	ExportRooms(ctx context.Context) []models.Room
+}
+
+type HealthServiceInterface interface {
+	Ping(ctx context.Context) error
 }
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// apiKeySize bytes of secret of API key - printed as hex
+	apiKeySize = 32
+	// apiKeyLastUsedPrecision last use of the key is written not more often - every request would be a write
+	apiKeyLastUsedPrecision = time.Minute
+	// serviceAccountEmailDomain placeholder email of service accounts created without one. `.invalid` is never delivered
+	serviceAccountEmailDomain = "service-account.invalid"
+
+	InvalidAPIKeyError     = "invalid API key"
+	NotServiceAccountError = "API keys are issued only to active service accounts"
+	// RouteNotAllowedToRoleError keys are checked against permissions of the role only - routes which access policies
+	// allow to the role are not available to keys
+	RouteNotAllowedToRoleError = "route is not allowed to role of the service account by permission"
+)
+
+// ServiceAccountService users of integrations (chat bot, dashboards). They have no password - they are authenticated by
+// API keys which allow only routes listed in the key
+type ServiceAccountService struct {
+	userRepository    database.UserRepository
+	apiKeyRepository  database.APIKeyRepository
+	authService       AuthServiceInterface
+	routeService      RouteServiceInterface
+	scopeService      ScopeServiceInterface
+	permissionService PermissionServiceInterface
+
+	config AuthConfig
+}
+
+func NewServiceAccountService(config AuthConfig, userRepository database.UserRepository, apiKeyRepository database.APIKeyRepository, authService AuthServiceInterface, routeService RouteServiceInterface, scopeService ScopeServiceInterface, permissionService PermissionServiceInterface) *ServiceAccountService {
+	return &ServiceAccountService{
+		userRepository:    userRepository,
+		apiKeyRepository:  apiKeyRepository,
+		authService:       authService,
+		routeService:      routeService,
+		scopeService:      scopeService,
+		permissionService: permissionService,
+		config:            config,
+	}
+}
+
+// CreateServiceAccount creates user which cannot log in by password. Email is optional - placeholder is used without it.
+// Role other than the default one is given only if createdBy is an administrator
+func (s *ServiceAccountService) CreateServiceAccount(ctx context.Context, createdBy int, user models.User) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountService.CreateServiceAccount")
+	defer span.End()
+
+	// nobody knows the password, and log in by password is rejected for the provider anyway
+	password, err := pkg.GenerateRandomToken(apiKeySize)
+	if err != nil {
+		slog.ErrorContext(ctx, "ServiceAccountService.CreateServiceAccount(): error occured during password generation", "error", err)
+		return models.User{}, err
+	}
+	if user.Email == "" {
+		user.Email = user.UserName + "@" + serviceAccountEmailDomain
+	}
+	user.Password = password
+	user.AuthProvider = AuthProviderServiceAccount
+	user.EmailVerified = true
+	user.Active = true
+
+	return s.authService.Create(ctx, createdBy, user)
+}
+
+func (s *ServiceAccountService) GetServiceAccounts(ctx context.Context) ([]models.User, error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountService.GetServiceAccounts")
+	defer span.End()
+
+	return s.userRepository.GetUsersByAuthProvider(ctx, AuthProviderServiceAccount)
+}
+
+// CreateAPIKey issues key of the service account and returns it. The key is shown only once - only its hash is stored
+func (s *ServiceAccountService) CreateAPIKey(ctx context.Context, apiKey models.APIKey) (models.APIKey, string, error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountService.CreateAPIKey")
+	defer span.End()
+
+	serviceAccount, err := s.userRepository.GetUserById(ctx, apiKey.UserId)
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	if serviceAccount.AuthProvider != AuthProviderServiceAccount || !serviceAccount.Active {
+		slog.WarnContext(ctx, "ServiceAccountService.CreateAPIKey(): the user is not an active service account", "user_id", apiKey.UserId)
+		return models.APIKey{}, "", errors.New(NotServiceAccountError)
+	}
+
+	permissions, err := s.resolvePermissions(ctx, serviceAccount.RoleId, apiKey.Permissions)
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	now := time.Now()
+	maxExpiresAt := now.Add(s.config.APIKeyMaxTTL)
+	if apiKey.ExpiresAt.IsZero() {
+		apiKey.ExpiresAt = maxExpiresAt
+	}
+	if !apiKey.ExpiresAt.After(now) || apiKey.ExpiresAt.After(maxExpiresAt) {
+		return models.APIKey{}, "", fmt.Errorf("expires_at should be in the future and not later than %s from now", s.config.APIKeyMaxTTL)
+	}
+
+	secret, err := pkg.GenerateRandomToken(apiKeySize)
+	if err != nil {
+		slog.ErrorContext(ctx, "ServiceAccountService.CreateAPIKey(): error occured during key generation", "error", err)
+		return models.APIKey{}, "", err
+	}
+	apiKey.Permissions = permissions
+	apiKey.KeyHash = pkg.HashToken(secret)
+	apiKey.LastUsedAt = nil
+	apiKey.Active = true
+
+	createdAPIKey, err := s.apiKeyRepository.Create(ctx, apiKey)
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	slog.InfoContext(ctx, "ServiceAccountService.CreateAPIKey(): API key is issued", "key_id", createdAPIKey.APIKeyId, "user_id", apiKey.UserId, "created_by", apiKey.CreatedBy)
+
+	// key id is a part of the key to find the key without scanning all hashes
+	return createdAPIKey, fmt.Sprintf("%d.%s", createdAPIKey.APIKeyId, secret), nil
+}
+
+func (s *ServiceAccountService) GetAPIKeys(ctx context.Context) []models.APIKey {
+	ctx, span := tracer.Start(ctx, "ServiceAccountService.GetAPIKeys")
+	defer span.End()
+
+	return s.apiKeyRepository.GetAll(ctx)
+}
+
+func (s *ServiceAccountService) RevokeAPIKey(ctx context.Context, apiKeyId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountService.RevokeAPIKey")
+	defer span.End()
+
+	return s.apiKeyRepository.Delete(ctx, apiKeyId)
+}
+
+// Authenticate checks API key in format `<api_key_id>.<secret>` and returns the key and its service account. The same
+// error is returned for unknown, revoked, expired and wrong keys
+func (s *ServiceAccountService) Authenticate(ctx context.Context, key string) (models.APIKey, models.User, error) {
+	ctx, span := tracer.Start(ctx, "ServiceAccountService.Authenticate")
+	defer span.End()
+
+	apiKeyIdString, secret, found := strings.Cut(key, ".")
+	apiKeyId, conversionError := strconv.Atoi(apiKeyIdString)
+	if !found || secret == "" || conversionError != nil {
+		slog.WarnContext(ctx, "ServiceAccountService.Authenticate(): wrong API key format")
+		return models.APIKey{}, models.User{}, errors.New(InvalidAPIKeyError)
+	}
+
+	apiKey, err := s.apiKeyRepository.GetAPIKeyById(ctx, apiKeyId)
+	if err != nil {
+		return models.APIKey{}, models.User{}, errors.New(InvalidAPIKeyError)
+	}
+	now := time.Now()
+	if !pkg.CompareTokenHash(secret, apiKey.KeyHash) || !apiKey.Active || !now.Before(apiKey.ExpiresAt) {
+		slog.WarnContext(ctx, "ServiceAccountService.Authenticate(): API key is wrong, revoked or expired", "key_id", apiKeyId)
+		return models.APIKey{}, models.User{}, errors.New(InvalidAPIKeyError)
+	}
+
+	serviceAccount, err := s.userRepository.GetUserById(ctx, apiKey.UserId)
+	if err != nil || !serviceAccount.Active || serviceAccount.AuthProvider != AuthProviderServiceAccount {
+		slog.WarnContext(ctx, "ServiceAccountService.Authenticate(): service account of the API key is not active", "key_id", apiKeyId, "user_id", apiKey.UserId)
+		return models.APIKey{}, models.User{}, errors.New(InvalidAPIKeyError)
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyLastUsedPrecision {
+		// the request is served even if last use is not written
+		if err := s.apiKeyRepository.UpdateLastUsed(ctx, apiKeyId, now); err == nil {
+			apiKey.LastUsedAt = &now
+		}
+	}
+
+	return apiKey, serviceAccount, nil
+}
+
+// resolvePermissions finds routes passed by URL and checks that routes and scopes exist. Route is listed only once and
+// only if role of the service account allows it - scope is narrowed to the widest scope of the role
+func (s *ServiceAccountService) resolvePermissions(ctx context.Context, roleId int, permissions []models.APIKeyPermission) ([]models.APIKeyPermission, error) {
+	if len(permissions) == 0 {
+		return nil, errors.New("API key should allow at least one route")
+	}
+
+	resolved := make([]models.APIKeyPermission, 0, len(permissions))
+	routeIds := make(map[int]bool)
+	for _, permission := range permissions {
+		var route models.Route
+		var err error
+		if permission.RouteId != 0 {
+			route, err = s.routeService.GetRouteById(ctx, permission.RouteId)
+		} else {
+			route, err = s.routeService.GetRouteByURL(ctx, permission.URL)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if route.RouteId == 0 {
+			return nil, fmt.Errorf("route is not found. Passed data: route_id=%d, url=%q", permission.RouteId, permission.URL)
+		}
+		if routeIds[route.RouteId] {
+			return nil, fmt.Errorf("route is listed more than once. Passed data: %s", route.URL)
+		}
+		routeIds[route.RouteId] = true
+
+		scope, err := s.scopeService.GetScopeById(ctx, permission.ScopeId)
+		if err != nil {
+			return nil, err
+		}
+		if scope.ScopeId == 0 {
+			return nil, fmt.Errorf("scope is not found. Passed data: scope_id=%d", permission.ScopeId)
+		}
+
+		rolePermissions, err := s.permissionService.GetPermissionsByRoleIdAndRouteId(ctx, roleId, route.RouteId)
+		if err != nil {
+			return nil, err
+		}
+		if len(rolePermissions) == 0 {
+			return nil, fmt.Errorf("%s. Passed data: %s", RouteNotAllowedToRoleError, route.URL)
+		}
+		roleScopeId := rolePermissions[0].ScopeId
+		for _, rolePermission := range rolePermissions[1:] {
+			if narrowerScope(roleScopeId, rolePermission.ScopeId) == roleScopeId {
+				roleScopeId = rolePermission.ScopeId
+			}
+		}
+
+		resolved = append(resolved, models.APIKeyPermission{RouteId: route.RouteId, URL: route.URL, ScopeId: narrowerScope(scope.ScopeId, roleScopeId)})
+	}
+
+	return resolved, nil
+}
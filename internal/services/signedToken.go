@@ -0,0 +1,56 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"go-booking-system/pkg"
+	"strings"
+)
+
+// signToken encodes claims of short-lived token (MFA challenge, SSO log in) as HS256 JWT
+func signToken(claims any, key string) (string, error) {
+	headerJSON, err := json.Marshal(pkg.JOSEHeader{Algorithm: HS256, Type: JWT})
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encodedHeader := base64.RawURLEncoding.EncodeToString(headerJSON)
+	encodedClaims := base64.RawURLEncoding.EncodeToString(claimsJSON)
+	return encodedHeader + "." + encodedClaims + "." + pkg.SignHeaderAndPayload(encodedHeader, encodedClaims, key), nil
+}
+
+// parseSignedToken checks signature of token issued by signToken and decodes its claims
+func parseSignedToken(token string, key string, claims any) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("token is malformed")
+	}
+
+	signature := pkg.SignHeaderAndPayload(parts[0], parts[1], key)
+	if !hmac.Equal([]byte(signature), []byte(parts[2])) {
+		return errors.New("signature of token is not valid")
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(claimsJSON, claims)
+}
+
+// derivedKey key of another token type is derived from the access token key - tokens of one type cannot be used as another one
+func derivedKey(key string, purpose string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(purpose))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
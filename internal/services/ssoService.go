@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/oidc"
+	"go-booking-system/pkg"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+const (
+	// AuthMethodSSO `amr` of tokens issued after log in at identity provider. Not registered in RFC 8176
+	AuthMethodSSO = "sso"
+
+	// SSOFlowType `typ` claim of token keeping state of log in at identity provider
+	SSOFlowType = "sso"
+
+	SSOLoginError  = "log in by identity provider failed"
+	SSONoRoleError = "no role is mapped to groups of the user"
+
+	// ssoPasswordSize random password of provisioned users - they log in only by identity provider
+	ssoPasswordSize = 32
+)
+
+// SSOFlowClaims state of log in at identity provider. The token is kept by the browser (cookie) between redirect to the
+// provider and callback, so any instance can finish the log in
+type SSOFlowClaims struct {
+	Issuer              string                `json:"iss"`
+	Type                string                `json:"typ"`
+	IssuedAt            int                   `json:"iat"`
+	ExpirationTime      int                   `json:"exp"`
+	State               string                `json:"state"`
+	Nonce               string                `json:"nonce"`
+	CodeVerifier        string                `json:"verifier"`
+	OriginatingIdentity pkg.IPAddressIdentity `json:"orig"`
+}
+
+type SSOService struct {
+	provider       *oidc.Provider
+	userRepository database.UserRepository
+	authService    AuthServiceInterface
+	tokenBinding   TokenBinding
+
+	config AuthConfig
+}
+
+func NewSSOService(config AuthConfig, provider *oidc.Provider, userRepository database.UserRepository, authService AuthServiceInterface) *SSOService {
+	return &SSOService{
+		provider:       provider,
+		userRepository: userRepository,
+		authService:    authService,
+		tokenBinding:   NewTokenBinding(config.TokenBinding),
+		config:         config,
+	}
+}
+
+// OrganizationId organization users of identity provider belong to
+func (s *SSOService) OrganizationId() int {
+	return s.config.SSO.OrganizationId
+}
+
+// StartLogin returns URL of identity provider's login page and token keeping state, nonce and PKCE verifier of the log in
+func (s *SSOService) StartLogin(ctx context.Context, identity pkg.IPAddressIdentity) (string, string, error) {
+	ctx, span := tracer.Start(ctx, "SSOService.StartLogin")
+	defer span.End()
+
+	state, stateError := pkg.GenerateRandomToken(16)
+	nonce, nonceError := pkg.GenerateRandomToken(16)
+	verifier, verifierError := oidc.GenerateCodeVerifier()
+	if err := errors.Join(stateError, nonceError, verifierError); err != nil {
+		slog.ErrorContext(ctx, "SSOService.StartLogin(): error occured during generation of log in secrets", "error", err)
+		return "", "", err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	flowToken, err := signToken(SSOFlowClaims{
+		Issuer:              issuer,
+		Type:                SSOFlowType,
+		IssuedAt:            int(now.Unix()),
+		ExpirationTime:      int(now.Add(s.config.SSO.FlowTTL).Unix()),
+		State:               state,
+		Nonce:               nonce,
+		CodeVerifier:        verifier,
+		OriginatingIdentity: identity,
+	}, s.flowKey())
+	if err != nil {
+		slog.ErrorContext(ctx, "SSOService.StartLogin(): error occured during encoding of claims", "error", err)
+		return "", "", err
+	}
+
+	return authURL, flowToken, nil
+}
+
+// Authenticate finishes log in at identity provider: state of the callback should match the flow token, code is exchanged
+// with PKCE verifier and ID token is verified
+func (s *SSOService) Authenticate(ctx context.Context, code string, state string, flowToken string, sentFrom pkg.IPAddressIdentity) (oidc.Claims, error) {
+	ctx, span := tracer.Start(ctx, "SSOService.Authenticate")
+	defer span.End()
+
+	var flow SSOFlowClaims
+	if err := parseSignedToken(flowToken, s.flowKey(), &flow); err != nil || flow.Type != SSOFlowType {
+		slog.WarnContext(ctx, "SSOService.Authenticate(): log in token is not valid", "error", err)
+		return oidc.Claims{}, errors.New("log in is not started or its token is not valid")
+	}
+	if int64(flow.ExpirationTime) < time.Now().Unix() {
+		slog.WarnContext(ctx, "SSOService.Authenticate(): log in token is expired")
+		return oidc.Claims{}, errors.New("log in is expired - start it again")
+	}
+	if !s.tokenBinding.Matches(flow.OriginatingIdentity, sentFrom) {
+		slog.WarnContext(ctx, "SSOService.Authenticate(): log in is finished from another client", "ip_address", sentFrom.IP)
+		return oidc.Claims{}, errors.New(SentNotFromOriginatingIdentityError)
+	}
+	// state protects from callback with code of another log in (CSRF)
+	if subtle.ConstantTimeCompare([]byte(flow.State), []byte(state)) != 1 {
+		slog.WarnContext(ctx, "SSOService.Authenticate(): state of callback does not match log in")
+		return oidc.Claims{}, errors.New("state does not match log in")
+	}
+
+	rawIDToken, err := s.provider.Exchange(ctx, code, flow.CodeVerifier)
+	if err != nil {
+		return oidc.Claims{}, err
+	}
+
+	return s.provider.VerifyIDToken(ctx, rawIDToken, flow.Nonce)
+}
+
+// Provision returns user linked to the user of identity provider - creates it at the first log in (just-in-time).
+// Role is taken from groups at every log in, so changes of groups at the provider are applied
+func (s *SSOService) Provision(ctx context.Context, claims oidc.Claims) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "SSOService.Provision")
+	defer span.End()
+
+	roleId := MapGroupsToRole(claims.Groups, s.config.SSO.GroupRoles, s.config.SSO.DefaultRoleId)
+	if roleId == 0 {
+		slog.WarnContext(ctx, "SSOService.Provision(): no role is mapped to groups of the user", "subject", claims.Subject, "groups", claims.Groups)
+		return models.User{}, errors.New(SSONoRoleError)
+	}
+
+	user, err := s.userRepository.GetUserBySSOSubject(ctx, claims.Issuer, claims.Subject)
+	if err != nil {
+		return models.User{}, err
+	}
+	if user.UserId == 0 && s.config.SSO.LinkByEmail && claims.Email != "" && claims.EmailVerified {
+		if user, err = s.link(ctx, claims); err != nil {
+			return models.User{}, err
+		}
+	}
+	if user.UserId == 0 {
+		return s.create(ctx, claims, roleId)
+	}
+
+	if !user.Active {
+		slog.WarnContext(ctx, "SSOService.Provision(): user is not active", "user_id", user.UserId)
+		return models.User{}, errors.New("user is not active")
+	}
+	if user.RoleId != roleId {
+		if _, err := s.authService.UpdateRole(ctx, user.UserId, roleId); err != nil {
+			slog.ErrorContext(ctx, "SSOService.Provision(): error occured during update of role by groups", "user_id", user.UserId, "role_id", roleId, "error", err)
+			return models.User{}, err
+		}
+		slog.InfoContext(ctx, "SSOService.Provision(): role is changed by groups of identity provider", "user_id", user.UserId, "old_role_id", user.RoleId, "role_id", roleId)
+		user.RoleId = roleId
+	}
+
+	return user, nil
+}
+
+// link existing user with the same verified email is linked to the user of identity provider
+func (s *SSOService) link(ctx context.Context, claims oidc.Claims) (models.User, error) {
+	user, err := s.userRepository.GetUserByEmail(ctx, claims.Email)
+	if err != nil || user.UserId == 0 {
+		return user, err
+	}
+	if user.SSOSubject != "" {
+		slog.WarnContext(ctx, "SSOService.link(): user with the email is linked to another user of identity provider", "user_id", user.UserId)
+		return models.User{}, errors.New("user with the email is linked to another user of identity provider")
+	}
+
+	user.SSOIssuer, user.SSOSubject = claims.Issuer, claims.Subject
+	if _, err := s.userRepository.UpdateSSOSubject(ctx, user); err != nil {
+		return models.User{}, err
+	}
+	slog.InfoContext(ctx, "SSOService.link(): user is linked to identity provider", "user_id", user.UserId, "subject", claims.Subject)
+
+	return user, nil
+}
+
+func (s *SSOService) create(ctx context.Context, claims oidc.Claims, roleId int) (models.User, error) {
+	if claims.Email == "" {
+		slog.WarnContext(ctx, "SSOService.create(): ID token has no email", "subject", claims.Subject)
+		return models.User{}, errors.New("email of the user is not provided by identity provider - `email` scope is required")
+	}
+
+	username := claims.PreferredUsername
+	if username == "" {
+		username = claims.Email
+	}
+	name := claims.Name
+	if name == "" {
+		name = username
+	}
+	// nobody knows the password - the user logs in only by identity provider
+	password, err := pkg.GenerateRandomToken(ssoPasswordSize)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user, err := s.authService.Create(ctx, SystemUserId, models.User{
+		Name:       name,
+		Email:      claims.Email,
+		RoleId:     roleId,
+		UserName:   username,
+		Password:   password,
+		SSOIssuer:  claims.Issuer,
+		SSOSubject: claims.Subject,
+		// the user logs in by identity provider - email is not used for log in
+		EmailVerified: true,
+		Active:        true,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "SSOService.create(): error occured during provisioning of the user", "subject", claims.Subject, "error", err)
+		return models.User{}, err
+	}
+	slog.InfoContext(ctx, "SSOService.create(): user of identity provider is provisioned", "user_id", user.UserId, "role_id", roleId)
+
+	return user, nil
+}
+
+// flowKey flow token cannot be used as access token or MFA challenge and vice versa
+func (s *SSOService) flowKey() string {
+	return derivedKey(s.config.AccessTokenKey, "sso-flow")
+}
+
+// MapGroupsToRole returns role of the first mapping whose group the user is member of, defaultRoleId if there is none
+func MapGroupsToRole(groups []string, groupRoles []oidc.GroupRole, defaultRoleId int) int {
+	for _, groupRole := range groupRoles {
+		if slices.Contains(groups, groupRole.Group) {
+			return groupRole.RoleId
+		}
+	}
+
+	return defaultRoleId
+}
+
+// SSOAuthMethods `amr` of tokens issued after log in at identity provider. Second factor passed at the provider is trusted
+func SSOAuthMethods(claims oidc.Claims) []string {
+	if slices.Contains(claims.AuthMethods, AuthMethodMFA) {
+		return []string{AuthMethodSSO, AuthMethodMFA}
+	}
+
+	return []string{AuthMethodSSO}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"crypto/subtle"
+	"go-booking-system/pkg"
+	"net/netip"
+)
+
+const (
+	// TokenBindingNone tokens work from any client
+	TokenBindingNone = "none"
+	// TokenBindingIP tokens work only from IP address they are issued to
+	TokenBindingIP = "ip"
+	// TokenBindingSubnet tokens work from network of the address they are issued to (e.g. /24 of IPv4, /64 of IPv6) -
+	// mobile clients changing address inside the network of the operator keep tokens
+	TokenBindingSubnet = "subnet"
+	// TokenBindingFingerprint tokens work only from the client (`User-Agent` and `X-Device-Fingerprint` headers) they are
+	// issued to, from any IP address
+	TokenBindingFingerprint = "fingerprint"
+)
+
+// TokenBindingConfig `auth.tokenBinding` section of config.yaml
+type TokenBindingConfig struct {
+	// Mode none, ip, subnet or fingerprint. Empty - ip
+	Mode string
+	// IPv4PrefixLength and IPv6PrefixLength size of networks of subnet binding
+	IPv4PrefixLength int
+	IPv6PrefixLength int
+}
+
+// TokenBinding decides whether token issued to originating identity may be used by the client which has sent it.
+// Access and refresh tokens, MFA challenges and SSO log in flows are checked by it
+type TokenBinding interface {
+	Matches(originating pkg.IPAddressIdentity, sentFrom pkg.IPAddressIdentity) bool
+}
+
+// NewTokenBinding unknown mode (it is rejected by config validation) gets the strictest binding - by IP address
+func NewTokenBinding(config TokenBindingConfig) TokenBinding {
+	switch config.Mode {
+	case TokenBindingNone:
+		return noTokenBinding{}
+	case TokenBindingSubnet:
+		return subnetTokenBinding{ipv4PrefixLength: config.IPv4PrefixLength, ipv6PrefixLength: config.IPv6PrefixLength}
+	case TokenBindingFingerprint:
+		return fingerprintTokenBinding{}
+	default:
+		return ipTokenBinding{}
+	}
+}
+
+type noTokenBinding struct{}
+
+func (noTokenBinding) Matches(pkg.IPAddressIdentity, pkg.IPAddressIdentity) bool {
+	return true
+}
+
+type ipTokenBinding struct{}
+
+// Matches compares parsed addresses - `::ffff:10.0.0.1` and `10.0.0.1` are the same client
+func (ipTokenBinding) Matches(originating pkg.IPAddressIdentity, sentFrom pkg.IPAddressIdentity) bool {
+	originatingAddress, originatingError := parseIdentityIP(originating)
+	sentFromAddress, sentFromError := parseIdentityIP(sentFrom)
+	if originatingError != nil || sentFromError != nil {
+		return false
+	}
+
+	return originatingAddress == sentFromAddress
+}
+
+type subnetTokenBinding struct {
+	ipv4PrefixLength int
+	ipv6PrefixLength int
+}
+
+// Matches addresses of different families never match - a client switching between IPv4 and IPv6 logs in again
+func (s subnetTokenBinding) Matches(originating pkg.IPAddressIdentity, sentFrom pkg.IPAddressIdentity) bool {
+	originatingAddress, originatingError := parseIdentityIP(originating)
+	sentFromAddress, sentFromError := parseIdentityIP(sentFrom)
+	if originatingError != nil || sentFromError != nil || originatingAddress.Is4() != sentFromAddress.Is4() {
+		return false
+	}
+
+	prefixLength := s.ipv6PrefixLength
+	if originatingAddress.Is4() {
+		prefixLength = s.ipv4PrefixLength
+	}
+	network, err := originatingAddress.Prefix(prefixLength)
+	if err != nil {
+		return false
+	}
+
+	return network.Contains(sentFromAddress)
+}
+
+type fingerprintTokenBinding struct{}
+
+// Matches tokens issued before the binding was enabled have no fingerprint - their users log in again
+func (fingerprintTokenBinding) Matches(originating pkg.IPAddressIdentity, sentFrom pkg.IPAddressIdentity) bool {
+	return originating.Fingerprint != "" &&
+		subtle.ConstantTimeCompare([]byte(originating.Fingerprint), []byte(sentFrom.Fingerprint)) == 1
+}
+
+func parseIdentityIP(identity pkg.IPAddressIdentity) (netip.Addr, error) {
+	address, err := netip.ParseAddr(identity.IP)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	return address.Unmap().WithZone(""), nil
+}
@@ -1,10 +1,16 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"go-booking-system/internal/database"
 	"go-booking-system/internal/models"
+	"log/slog"
 )
 
+// RoleChangeError role of users is changed only by administrators - `/user/role`
+const RoleChangeError = "role is changed only by /user/role"
+
 type UserService struct {
 	repository database.UserRepository
 }
@@ -13,18 +19,54 @@ func NewUserService(repository database.UserRepository) *UserService {
 	return &UserService{repository: repository}
 }
 
-func (u *UserService) GetAll() []models.User {
-	return u.repository.GetAll()
+func (u *UserService) GetAll(ctx context.Context) []models.User {
+	ctx, span := tracer.Start(ctx, "UserService.GetAll")
+	defer span.End()
+
+	return u.repository.GetAll(ctx)
+}
+
+func (u *UserService) GetUserById(ctx context.Context, userId int) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetUserById")
+	defer span.End()
+
+	return u.repository.GetUserById(ctx, userId)
 }
 
-func (u *UserService) GetUserById(userId int) (models.User, error) {
-	return u.repository.GetUserById(userId)
+func (u *UserService) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetUserByUsername")
+	defer span.End()
+
+	return u.repository.GetUserByUsername(ctx, username)
 }
 
-func (u *UserService) Update(user models.User) (models.User, error) {
-	return u.repository.Update(user)
+// Update changes profile of the user: name, email, telephone and time zone. Role, username and password have their own
+// methods - passed role should be the current one, passed username and password are ignored
+func (u *UserService) Update(ctx context.Context, user models.User) (models.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.Update")
+	defer span.End()
+
+	currentUser, err := u.repository.GetUserById(ctx, user.UserId)
+	if err != nil {
+		return models.User{}, err
+	}
+	if user.RoleId != 0 && user.RoleId != currentUser.RoleId {
+		slog.WarnContext(ctx, "UserService.Update(): role cannot be changed with profile", "user_id", user.UserId, "role_id", user.RoleId)
+		return models.User{}, errors.New(RoleChangeError)
+	}
+
+	// zero values are not written by repository
+	user.RoleId, user.UserName, user.Password = 0, "", ""
+	if _, err := u.repository.Update(ctx, user); err != nil {
+		return models.User{}, err
+	}
+
+	return u.repository.GetUserById(ctx, user.UserId)
 }
 
-func (u *UserService) Delete(userId int) (bool, error) {
-	return u.repository.Delete(userId)
+func (u *UserService) Delete(ctx context.Context, userId int) (bool, error) {
+	ctx, span := tracer.Start(ctx, "UserService.Delete")
+	defer span.End()
+
+	return u.repository.Delete(ctx, userId)
 }
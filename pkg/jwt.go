@@ -7,7 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 )
 
@@ -19,24 +19,34 @@ type JOSEHeader struct {
 }
 
 type AccessTokenClaims struct {
-	Issuer              string            `json:"iss"`   // who/what issued token - `go_booking`
-	IssuedAt            int               `json:"iat"`   // when token was issued
-	ExpirationTime      int               `json:"exp"`   // when token expires
-	Subject             string            `json:"sub"`   // who gets token UserID - SHOULD BE string ref:https://datatracker.ietf.org/doc/html/rfc7519#section-4.1.2
-	Role                string            `json:"roles"` // string of RoleId - compatible with OAuth2 ref:https://www.rfc-editor.org/rfc/rfc9068.html#name-roles
-	OriginatingIdentity IPAddressIdentity `json:"orig"`  // custom claim - this is crucial for preventing malicious users from using tokens on another machine or another program on the same machine
+	Issuer              string            `json:"iss"`           // who/what issued token - `go_booking`
+	IssuedAt            int               `json:"iat"`           // when token was issued
+	ExpirationTime      int               `json:"exp"`           // when token expires
+	Subject             string            `json:"sub"`           // who gets token UserID - SHOULD BE string ref:https://datatracker.ietf.org/doc/html/rfc7519#section-4.1.2
+	Role                string            `json:"roles"`         // string of RoleId - compatible with OAuth2 ref:https://www.rfc-editor.org/rfc/rfc9068.html#name-roles
+	Organization        string            `json:"org"`           // custom claim - string of OrganizationId (tenant) all requests of the token are scoped to
+	OriginatingIdentity IPAddressIdentity `json:"orig"`          // custom claim - this is crucial for preventing malicious users from using tokens on another machine or another program on the same machine
+	AuthMethods         []string          `json:"amr,omitempty"` // methods used at log in: `pwd`, `otp`, `mfa` ref:https://www.rfc-editor.org/rfc/rfc8176.html
 }
 
 type IPAddressIdentity struct {
-	IP string `json:"ip"` // has information about Remote Address. Example: `127.0.0.1`
+	IP          string `json:"ip"`           // has information about Remote Address. Example: `127.0.0.1`, `2001:db8::1`
+	Fingerprint string `json:"fp,omitempty"` // hash of headers describing the client program - checked by `fingerprint` token binding
+}
+
+// DeviceFingerprint hash of `User-Agent` and `X-Device-Fingerprint` headers of the client - tokens do not reveal them
+func DeviceFingerprint(userAgent string, deviceId string) string {
+	return HashToken(userAgent + "\n" + deviceId)
 }
 
 type RefreshTokenClaims struct {
-	Issuer              string            `json:"iss"`  // who issued token
-	IssuedAt            int               `json:"iat"`  // when token was issued
-	ExpirationTime      int               `json:"exp"`  // when token expires
-	Subject             string            `json:"sub"`  // who gets token UserID - SHOULD BE string ref:https://datatracker.ietf.org/doc/html/rfc7519#section-4.1.2
-	OriginatingIdentity IPAddressIdentity `json:"orig"` // custom claim - this is crucial for preventing malicious users from using tokens on another machine or another program on the same machine
+	Issuer              string            `json:"iss"`           // who issued token
+	IssuedAt            int               `json:"iat"`           // when token was issued
+	ExpirationTime      int               `json:"exp"`           // when token expires
+	Subject             string            `json:"sub"`           // who gets token UserID - SHOULD BE string ref:https://datatracker.ietf.org/doc/html/rfc7519#section-4.1.2
+	Organization        string            `json:"org"`           // custom claim - string of OrganizationId (tenant) of the user
+	OriginatingIdentity IPAddressIdentity `json:"orig"`          // custom claim - this is crucial for preventing malicious users from using tokens on another machine or another program on the same machine
+	AuthMethods         []string          `json:"amr,omitempty"` // methods used at log in - copied to refreshed access tokens
 }
 
 // GenerateJWTAccessToken ref: https://datatracker.ietf.org/doc/html/rfc7519#section-3.1
@@ -44,14 +54,14 @@ func GenerateJWTAccessToken(header JOSEHeader, claims AccessTokenClaims, key str
 	// 1. Convert JOSEHeader struct to byte array, where the octets represent the UTF-8 representation
 	headerJSON, JSONMarshallerError := json.Marshal(header)
 	if JSONMarshallerError != nil {
-		log.Println("pkg.JWT.GenerateJWTAccessToken(): error occured during converting JOSEHeader to JSON byte array. Details: ", JSONMarshallerError)
+		slog.Error("pkg.JWT.GenerateJWTAccessToken(): error occured during converting JOSEHeader to JSON byte array", "error", JSONMarshallerError)
 		return JWTToken(""), JSONMarshallerError
 	}
 
 	// 2. Convert AccessTokenClaims struct to byte array, where the octets represent the UTF-8 representation
 	claimsJSON, JSONMarshallerError := json.Marshal(claims)
 	if JSONMarshallerError != nil {
-		log.Println("pkg.JWT.GenerateJWTAccessToken(): error occured during converting AccessTokenClaims to JSON byte array. Details: ", JSONMarshallerError)
+		slog.Error("pkg.JWT.GenerateJWTAccessToken(): error occured during converting AccessTokenClaims to JSON byte array", "error", JSONMarshallerError)
 		return JWTToken(""), JSONMarshallerError
 	}
 
@@ -71,14 +81,14 @@ func GenerateJWTRefreshToken(header JOSEHeader, claims RefreshTokenClaims, key s
 	// 1. Convert JOSEHeader struct to byte array, where the octets represent the UTF-8 representation
 	headerJSON, JSONMarshallerError := json.Marshal(header)
 	if JSONMarshallerError != nil {
-		log.Println("pkg.JWT.GenerateJWTRefreshToken(): error occured during converting JOSEHeader to JSON byte array. Details: ", JSONMarshallerError)
+		slog.Error("pkg.JWT.GenerateJWTRefreshToken(): error occured during converting JOSEHeader to JSON byte array", "error", JSONMarshallerError)
 		return JWTToken(""), JSONMarshallerError
 	}
 
 	// 2. Convert RefreshTokenClaims struct to byte array, where the octets represent the UTF-8 representation
 	claimsJSON, JSONMarshallerError := json.Marshal(claims)
 	if JSONMarshallerError != nil {
-		log.Println("pkg.JWT.GenerateJWTRefreshToken(): error occured during converting RefreshTokenClaims to JSON byte array. Details: ", JSONMarshallerError)
+		slog.Error("pkg.JWT.GenerateJWTRefreshToken(): error occured during converting RefreshTokenClaims to JSON byte array", "error", JSONMarshallerError)
 		return JWTToken(""), JSONMarshallerError
 	}
 
@@ -98,7 +108,7 @@ func ExtractAccessTokenClaims(input string) (AccessTokenClaims, error) {
 	// 9.   Otherwise, base64url decode the Message
 	decodedData, decodingError := base64.RawURLEncoding.DecodeString(input)
 	if decodingError != nil {
-		log.Println("pkg.JWT.ExtractAccessTokenClaims(): error during decoding Base64RawURL second part of JWT token (AccessTokenClaims). Passed data: ", input)
+		slog.Warn("pkg.JWT.ExtractAccessTokenClaims(): error during decoding Base64RawURL second part of JWT token (AccessTokenClaims)", "passed_data", input)
 		return AccessTokenClaims{}, decodingError
 	}
 
@@ -106,7 +116,7 @@ func ExtractAccessTokenClaims(input string) (AccessTokenClaims, error) {
 	decodedDataStr := string(decodedData)
 	decodedDataAfterTrimming := strings.TrimSpace(decodedDataStr)
 	if decodedDataStr != decodedDataAfterTrimming {
-		log.Println("pkg.JWT.ExtractAccessTokenClaims(): second part of JWT token has line break, whitespace or tab character(s). Passed data: ", input)
+		slog.Warn("pkg.JWT.ExtractAccessTokenClaims(): second part of JWT token has line break, whitespace or tab character(s)", "passed_data", input)
 		return AccessTokenClaims{}, errors.New("second part of JWT token has line break, whitespace or tab character(s). Passed data: " + input)
 	}
 
@@ -116,7 +126,7 @@ func ExtractAccessTokenClaims(input string) (AccessTokenClaims, error) {
 	//        RFC 7159 [RFC7159]; let the JWT AccessTokenClaims Set be this JSON object.
 	unmarshallingError := json.Unmarshal(decodedData, &claims)
 	if unmarshallingError != nil {
-		log.Println("pkg.JWT.ExtractAccessTokenClaims(): error during unmarshalling Base64RawURL-decoded second part of JWT Token. Reasons: not AccessTokenClaims or has invalid data types. Passed data: ", decodedData)
+		slog.Warn("pkg.JWT.ExtractAccessTokenClaims(): error during unmarshalling Base64RawURL-decoded second part of JWT Token. Reasons: not AccessTokenClaims or has invalid data types", "passed_data", decodedData)
 		return AccessTokenClaims{}, unmarshallingError
 	}
 
@@ -127,7 +137,7 @@ func ExtractRefreshTokenClaims(input string) (RefreshTokenClaims, error) {
 	// 9.   Otherwise, base64url decode the Message
 	decodedData, decodingError := base64.RawURLEncoding.DecodeString(input)
 	if decodingError != nil {
-		log.Println("pkg.JWT.ExtractRefreshTokenClaims(): error during decoding Base64RawURL second part of JWT token (AccessTokenClaims). Passed data: ", input)
+		slog.Warn("pkg.JWT.ExtractRefreshTokenClaims(): error during decoding Base64RawURL second part of JWT token (AccessTokenClaims)", "passed_data", input)
 		return RefreshTokenClaims{}, decodingError
 	}
 
@@ -135,7 +145,7 @@ func ExtractRefreshTokenClaims(input string) (RefreshTokenClaims, error) {
 	decodedDataStr := string(decodedData)
 	decodedDataAfterTrimming := strings.TrimSpace(decodedDataStr)
 	if decodedDataStr != decodedDataAfterTrimming {
-		log.Println("pkg.JWT.ExtractRefreshTokenClaims(): second part of JWT token has line break, whitespace or tab character(s). Passed data: ", input)
+		slog.Warn("pkg.JWT.ExtractRefreshTokenClaims(): second part of JWT token has line break, whitespace or tab character(s)", "passed_data", input)
 		return RefreshTokenClaims{}, errors.New("second part of JWT token has line break, whitespace or tab character(s). Passed data: " + input)
 	}
 
@@ -145,7 +155,7 @@ func ExtractRefreshTokenClaims(input string) (RefreshTokenClaims, error) {
 	//        RFC 7159 [RFC7159]; let the JWT AccessTokenClaims Set be this JSON object.
 	unmarshallingError := json.Unmarshal(decodedData, &claims)
 	if unmarshallingError != nil {
-		log.Println("pkg.JWT.ExtractRefreshTokenClaims(): error during unmarshalling Base64RawURL-decoded second part of JWT Token. Reasons: not AccessTokenClaims or has invalid data types. Passed data: ", decodedData)
+		slog.Warn("pkg.JWT.ExtractRefreshTokenClaims(): error during unmarshalling Base64RawURL-decoded second part of JWT Token. Reasons: not AccessTokenClaims or has invalid data types", "passed_data", decodedData)
 		return RefreshTokenClaims{}, unmarshallingError
 	}
 
@@ -174,7 +184,7 @@ func ExtractJOSEHeader(input string) (JOSEHeader, error) {
 	// 3. Base64url decode the Encoded JOSE JOSEHeader
 	decodedData, decodingError := base64.RawURLEncoding.DecodeString(input)
 	if decodingError != nil {
-		log.Println("pkg.JWT.ExtractJOSEHeader(): error during decoding Base64RawURL first part of JWT token (JOSEHeader). Passed data: ", input)
+		slog.Warn("pkg.JWT.ExtractJOSEHeader(): error during decoding Base64RawURL first part of JWT token (JOSEHeader)", "passed_data", input)
 		return JOSEHeader{}, decodingError
 	}
 
@@ -182,7 +192,7 @@ func ExtractJOSEHeader(input string) (JOSEHeader, error) {
 	decodedDataStr := string(decodedData)
 	if strings.Contains(decodedDataStr, "\n") == true || strings.Contains(decodedDataStr, " ") == true ||
 		strings.Contains(decodedDataStr, "\t") == true {
-		log.Println("pkg.JWT.ExtractJOSEHeader(): first part of JWT token has line break, whitespace or tab character(s). Passed data: ", input)
+		slog.Warn("pkg.JWT.ExtractJOSEHeader(): first part of JWT token has line break, whitespace or tab character(s)", "passed_data", input)
 		return JOSEHeader{}, errors.New("first part of JWT token has line break, whitespace or tab character(s). Passed data: " + input)
 	}
 
@@ -200,7 +210,7 @@ func ExtractJOSEHeader(input string) (JOSEHeader, error) {
 	// Answer to 8: NO `cty` field is determined in my implementation of JWT-token
 	unmarshallingError := json.Unmarshal(decodedData, &joseHeader)
 	if unmarshallingError != nil {
-		log.Println("pkg.JWT.ExtractJOSEHeader(): error during unmarshalling Base64RawURL-decoded first part of JWT Token. Reasons: not JOSE JOSEHeader or has invalid data types. Passed data: ", decodedData)
+		slog.Warn("pkg.JWT.ExtractJOSEHeader(): error during unmarshalling Base64RawURL-decoded first part of JWT Token. Reasons: not JOSE JOSEHeader or has invalid data types", "passed_data", decodedData)
 		return JOSEHeader{}, unmarshallingError
 	}
 
@@ -210,7 +220,7 @@ func ExtractJOSEHeader(input string) (JOSEHeader, error) {
 	//        understood.
 	// TODO add more algorithms
 	if joseHeader.Algorithm != "HS256" || joseHeader.Type != "JWT" {
-		log.Println("pkg.JWT.ExtractJOSEHeader(): error in JOSE JOSEHeader - has unsupported values in parameters. JOSE JOSEHeader: ", joseHeader)
+		slog.Warn("pkg.JWT.ExtractJOSEHeader(): error in JOSE JOSEHeader - has unsupported values in parameters. JOSE JOSEHeader", "passed_data", joseHeader)
 		return JOSEHeader{}, fmt.Errorf("error in JOSE JOSEHeader - has unsupported values in parameters. JOSE JOSEHeader: %#v", joseHeader)
 	}
 
@@ -222,5 +232,5 @@ func (h JOSEHeader) String() string {
 }
 
 func (i IPAddressIdentity) String() string {
-	return fmt.Sprintf(`{"ip":"%s"}`, i.IP)
+	return fmt.Sprintf(`{"ip":"%s","fp":"%s"}`, i.IP, i.Fingerprint)
 }
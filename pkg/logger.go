@@ -0,0 +1,163 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+
+	// RedactedValue replaces values of sensitive attributes
+	RedactedValue = "[REDACTED]"
+)
+
+// sensitiveKeys attributes which names contain any of these words are never written to logs
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "pass_code", "passcode", "hash", "api_key", "apikey"}
+
+type requestIdContextKey struct{}
+
+// WithRequestId returns context carrying id of the request - it is added to every record logged with this context
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey{}, requestId)
+}
+
+// RequestIdFromContext returns empty string if context has no request id
+func RequestIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestId, _ := ctx.Value(requestIdContextKey{}).(string)
+
+	return requestId
+}
+
+// NewLogger level: debug, info, warn, error. format: text, json
+func NewLogger(writer io.Writer, level string, format string) (*slog.Logger, error) {
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("unknown log level. Passed data: %s", level)
+	}
+
+	options := &slog.HandlerOptions{Level: logLevel, ReplaceAttr: RedactAttr}
+
+	var handler slog.Handler
+	switch format {
+	case LogFormatText, "":
+		handler = slog.NewTextHandler(writer, options)
+	case LogFormatJSON:
+		handler = slog.NewJSONHandler(writer, options)
+	default:
+		return nil, fmt.Errorf("unknown log format. Passed data: %s", format)
+	}
+
+	return slog.New(requestIdHandler{handler}), nil
+}
+
+// requestIdHandler adds `request_id` and `trace_id` of the context to the records
+type requestIdHandler struct {
+	slog.Handler
+}
+
+func (h requestIdHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestId := RequestIdFromContext(ctx); requestId != "" {
+		record.AddAttrs(slog.String("request_id", requestId))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()))
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h requestIdHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIdHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestIdHandler) WithGroup(name string) slog.Handler {
+	return requestIdHandler{h.Handler.WithGroup(name)}
+}
+
+// RedactAttr hides values of sensitive attributes. Structs and slices are logged as groups of their fields,
+// so sensitive fields of models (e.g. User.Password) are hidden as well
+func RedactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if isSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, RedactedValue)
+	}
+	if attr.Value.Kind() != slog.KindAny {
+		return attr
+	}
+
+	return slog.Attr{Key: attr.Key, Value: expandValue(reflect.ValueOf(attr.Value.Any()))}
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitiveKey := range sensitiveKeys {
+		if strings.Contains(key, sensitiveKey) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expandValue turns structs and slices into groups. Errors, times and values implementing slog.LogValuer are kept as they are
+func expandValue(value reflect.Value) slog.Value {
+	if !value.IsValid() {
+		return slog.AnyValue(nil)
+	}
+
+	switch value.Interface().(type) {
+	case error, time.Time, slog.LogValuer, fmt.Stringer:
+		return slog.AnyValue(value.Interface())
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return slog.AnyValue(nil)
+		}
+		return expandValue(value.Elem())
+	case reflect.Struct:
+		attrs := make([]slog.Attr, 0, value.NumField())
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			attrs = append(attrs, RedactAttr(nil, slog.Any(field.Name, value.Field(i).Interface())))
+		}
+		return slog.GroupValue(attrs...)
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return slog.AnyValue(value.Interface())
+		}
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		attrs := make([]slog.Attr, 0, len(keys))
+		for _, key := range keys {
+			attrs = append(attrs, RedactAttr(nil, slog.Any(key.String(), value.MapIndex(key).Interface())))
+		}
+		return slog.GroupValue(attrs...)
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			return slog.AnyValue(value.Interface())
+		}
+		attrs := make([]slog.Attr, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			attrs = append(attrs, RedactAttr(nil, slog.Any(fmt.Sprint(i), value.Index(i).Interface())))
+		}
+		return slog.GroupValue(attrs...)
+	}
+
+	return slog.AnyValue(value.Interface())
+}
@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const (
+	TableFormatCSV  = "csv"
+	TableFormatXLSX = "xlsx"
+)
+
+// xlsxSignature XLSX file is a ZIP archive
+var xlsxSignature = []byte("PK\x03\x04")
+
+// utf8BOM is added by Excel to CSV files
+var utf8BOM = []byte("\xEF\xBB\xBF")
+
+// ReadTable reads rows of CSV or XLSX (first sheet) file. Format is detected by content
+func ReadTable(data []byte) ([][]string, error) {
+	if bytes.HasPrefix(data, xlsxSignature) {
+		return readXLSX(data)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, utf8BOM)))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	return reader.ReadAll()
+}
+
+func readXLSX(data []byte) ([][]string, error) {
+	file, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("XLSX file has no sheets")
+	}
+
+	return file.GetRows(sheets[0])
+}
+
+// TableResponse writes records as downloadable CSV (default) or XLSX file. First record is a header
+func TableResponse(writer http.ResponseWriter, format string, fileName string, records [][]string) {
+	if format != TableFormatXLSX {
+		CSVResponse(writer, fileName+".csv", records)
+		return
+	}
+
+	XLSXResponse(writer, fileName+".xlsx", records)
+}
+
+// XLSXResponse writes records to the first sheet of downloadable XLSX file. First record is a header
+func XLSXResponse(writer http.ResponseWriter, fileName string, records [][]string) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := file.GetSheetName(0)
+	for i, record := range records {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			slog.Error("XLSXResponse(): error occured during XLSX file creation", "error", err)
+			ErrorResponse(writer, http.StatusInternalServerError, "error occured during XLSX file creation", err.Error())
+			return
+		}
+
+		row := make([]interface{}, 0, len(record))
+		for _, value := range record {
+			row = append(row, value)
+		}
+		if err := file.SetSheetRow(sheet, cell, &row); err != nil {
+			slog.Error("XLSXResponse(): error occured during XLSX file creation", "error", err)
+			ErrorResponse(writer, http.StatusInternalServerError, "error occured during XLSX file creation", err.Error())
+			return
+		}
+	}
+
+	writer.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	writer.WriteHeader(http.StatusOK)
+
+	if err := file.Write(writer); err != nil {
+		slog.Error("XLSXResponse(): error occured during XLSX file writing", "error", err)
+		return
+	}
+}
+
+// TableHeader maps lower-cased column names of the header to their indexes
+func TableHeader(header []string) map[string]int {
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	return columns
+}
+
+// TableValue value of the column in the row. Missing column or cell - empty string
+func TableValue(row []string, columns map[string]int, column string) string {
+	i, ok := columns[column]
+	if !ok || i >= len(row) {
+		return ""
+	}
+
+	return strings.TrimSpace(row[i])
+}
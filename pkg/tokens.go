@@ -0,0 +1,30 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// GenerateRandomToken returns hex-encoded string of `size` cryptographically secure random bytes
+func GenerateRandomToken(size int) (string, error) {
+	randomBytes := make([]byte, size)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(randomBytes), nil
+}
+
+// HashToken is used for high-entropy secrets (device tokens, API keys etc.) - only hashes are stored in DB
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// CompareTokenHash compares token with stored hash in constant time
+func CompareTokenHash(token string, tokenHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashToken(token)), []byte(tokenHash)) == 1
+}
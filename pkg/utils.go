@@ -1,8 +1,10 @@
 package pkg
 
 import (
+	"encoding/csv"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 )
 
@@ -20,7 +22,7 @@ func ErrorResponse(writer http.ResponseWriter, statusCode int, message string, d
 	}
 
 	if err := json.NewEncoder(writer).Encode(response); err != nil {
-		log.Println(err)
+		slog.Error("ErrorResponse(): error occured during response encoding", "error", err)
 		return
 	}
 }
@@ -28,9 +30,21 @@ func ErrorResponse(writer http.ResponseWriter, statusCode int, message string, d
 func Response(writer http.ResponseWriter, data any) {
 	dataToWrite, err := json.Marshal(data)
 	if err != nil {
-		log.Println(err)
+		slog.Error("Response(): error occured during response encoding", "error", err)
 		return
 	}
 	writer.WriteHeader(http.StatusOK)
 	writer.Write(dataToWrite)
 }
+
+// CSVResponse writes records as downloadable CSV file. First record is a header
+func CSVResponse(writer http.ResponseWriter, fileName string, records [][]string) {
+	writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	writer.WriteHeader(http.StatusOK)
+
+	if err := csv.NewWriter(writer).WriteAll(records); err != nil {
+		slog.Error("CSVResponse(): error occured during CSV file writing", "error", err)
+		return
+	}
+}
@@ -0,0 +1,154 @@
+package configs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/configs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testConfig = `
+server:
+  Address: "localhost:9090"
+  ShutdownTimeout: "5s"
+db:
+  Host: "db.internal"
+  MaxOpenConns: 50
+cors:
+  AllowedOrigins: [ "https://booking.example.com" ]
+`
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	return path
+}
+
+func setTokenKeys(t *testing.T) {
+	t.Setenv("BOOKING_AUTH_ACCESSTOKENKEY", "access-key-access-key-access-key-1")
+	t.Setenv("BOOKING_AUTH_REFRESHTOKENKEY", "refresh-key-refresh-key-refresh-key")
+}
+
+func TestLoad_FileAndDefaults(t *testing.T) {
+	// 1. Assess
+	path := writeConfig(t, testConfig)
+	setTokenKeys(t)
+
+	// 2. Act
+	config, err := configs.Load(path, nil)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost:9090", config.Server.Address)
+	assert.Equal(t, 5*time.Second, config.Server.ShutdownTimeout)
+	assert.Equal(t, 10*time.Second, config.Server.ReadTimeout) // default
+	assert.Equal(t, "db.internal", config.DB.Host)
+	assert.Equal(t, uint16(5432), config.DB.Port) // default
+	assert.Equal(t, 50, config.DB.MaxOpenConns)
+	assert.Equal(t, []string{"https://booking.example.com"}, config.CORS.AllowedOrigins)
+	assert.Equal(t, time.Hour, config.Auth.AccessTokenTTL)
+	assert.Equal(t, true, config.Features.Swagger)
+}
+
+func TestLoad_EnvAndOverridesTakePriority(t *testing.T) {
+	// 1. Assess
+	path := writeConfig(t, testConfig)
+	setTokenKeys(t)
+	t.Setenv("BOOKING_SERVER_ADDRESS", ":8000")
+	t.Setenv("BOOKING_DB_HOST", "env-db")
+	t.Setenv("BOOKING_AUTH_ACCESSTOKENTTL", "15m")
+	t.Setenv("BOOKING_CORS_ALLOWEDORIGINS", "https://a.example.com,https://b.example.com")
+	t.Setenv("DB_PASSWORD", "legacy password") // variable used before unified config
+
+	// 2. Act
+	config, err := configs.Load(path, map[string]any{"db.host": "flag-db", "db.autoMigrate": "true"})
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, ":8000", config.Server.Address)
+	assert.Equal(t, "flag-db", config.DB.Host)
+	assert.Equal(t, true, config.DB.AutoMigrate)
+	assert.Equal(t, 15*time.Minute, config.Auth.AccessTokenTTL)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, config.CORS.AllowedOrigins)
+	assert.Equal(t, "legacy password", config.DB.Password)
+}
+
+func TestLoad_ValidationErrors(t *testing.T) {
+	// 1. Assess
+	path := writeConfig(t, `
+server:
+  Address: "no-port"
+  TrustedProxies: [ "10.0.0.0/8", "proxy.internal" ]
+auth:
+  TokenBinding: { Mode: "session" }
+db:
+  MaxOpenConns: 5
+  MaxIdleConns: 10
+cors:
+  AllowedOrigins: [ "https://booking.example.com/path" ]
+log:
+  Level: "verbose"
+`)
+
+	// 2. Act
+	_, err := configs.Load(path, nil)
+
+	// 3. Assert
+	assert.ErrorContains(t, err, "server.address should be host:port")
+	assert.ErrorContains(t, err, `server.trustedProxies should contain IP addresses or networks (CIDR). Passed data: "proxy.internal"`)
+	assert.ErrorContains(t, err, "auth.tokenBinding.mode should be none, ip, subnet or fingerprint")
+	assert.ErrorContains(t, err, "db.maxIdleConns should not be greater than db.maxOpenConns")
+	assert.ErrorContains(t, err, "auth.accessTokenKey should contain at least 32 characters")
+	assert.ErrorContains(t, err, "cors.allowedOrigins should contain scheme://host[:port] or *")
+	assert.ErrorContains(t, err, "log.level should be debug, info, warn or error")
+}
+
+func TestLoad_SSO(t *testing.T) {
+	// 1. Assess
+	path := writeConfig(t, testConfig+`
+auth:
+  SSO:
+    Enabled: true
+    Issuer: "https://login.example.com/realms/company"
+    ClientId: "booking"
+    GroupRoles: [ { Group: "Booking-Admins", RoleId: 1 }, { Group: "staff", RoleId: 5 } ]
+`)
+	setTokenKeys(t)
+	t.Setenv("BOOKING_AUTH_SSO_CLIENTSECRET", "client-secret")
+
+	// 2. Act
+	config, err := configs.Load(path, nil)
+	_, invalidError := configs.Load(writeConfig(t, testConfig+`
+auth:
+  SSO:
+    Enabled: true
+    Scopes: [ "profile" ]
+    GroupRoles: [ { Group: "staff" } ]
+`), nil)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "client-secret", config.Auth.SSO.ClientSecret)
+	// group names keep their case
+	assert.Equal(t, "Booking-Admins", config.Auth.SSO.GroupRoles[0].Group)
+	assert.Equal(t, 5, config.Auth.SSO.GroupRoles[1].RoleId)
+	assert.Equal(t, []string{"openid", "profile", "email"}, config.Auth.SSO.Scopes)
+	assert.Equal(t, 1, config.Auth.SSO.OrganizationId)
+	assert.ErrorContains(t, invalidError, "auth.sso.issuer should be URL of identity provider")
+	assert.ErrorContains(t, invalidError, "auth.sso.clientId should not be empty")
+	assert.ErrorContains(t, invalidError, "auth.sso.scopes should contain openid")
+	assert.ErrorContains(t, invalidError, "auth.sso.groupRoles should contain group and positive roleId")
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	// 2. Act
+	_, err := configs.Load(filepath.Join(t.TempDir(), "missing.yaml"), nil)
+
+	// 3. Assert
+	assert.ErrorContains(t, err, "cannot read config file")
+}
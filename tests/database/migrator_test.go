@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func setupMigrator(t *testing.T) (*database.Migrator, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %v", err)
+	}
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open gorm db: %v", err)
+	}
+
+	migrator, err := database.NewMigrator(gormDB)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+
+	return migrator, mock, func() { db.Close() }
+}
+
+func TestMigrator_EmbeddedMigrationsAreOrdered(t *testing.T) {
+	// 1. Assess
+	migrator, _, cleanup := setupMigrator(t)
+	defer cleanup()
+
+	// 2. Act
+	migrations := migrator.Migrations()
+
+	// 3. Assert
+	assert.NotEmpty(t, migrations)
+	for i, migration := range migrations {
+		assert.Equal(t, i+1, migration.Version)
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+	}
+}
+
+func TestLoadMigrations_MissingDownFile(t *testing.T) {
+	// 1. Assess
+	files := fstest.MapFS{
+		"migrations/0001_init.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+		"migrations/0001_init.down.sql": {Data: []byte("DROP TABLE a;")},
+		"migrations/0002_second.up.sql": {Data: []byte("CREATE TABLE b (id INT);")},
+	}
+
+	// 2. Act
+	_, err := database.LoadMigrations(files, "migrations")
+
+	// 3. Assert
+	assert.ErrorContains(t, err, "0002_second should have both up and down files")
+}
+
+func TestMigrator_UpAppliesOnlyPendingMigrations(t *testing.T) {
+	// 1. Assess
+	migrator, mock, cleanup := setupMigrator(t)
+	defer cleanup()
+
+	migrations := migrator.Migrations()
+	last := migrations[len(migrations)-1]
+
+	appliedRows := sqlmock.NewRows([]string{"version", "applied_at"})
+	for _, migration := range migrations[:len(migrations)-1] {
+		appliedRows.AddRow(migration.Version, time.Now())
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT version, applied_at FROM schema_migrations ORDER BY version`)).WillReturnRows(appliedRows)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`)).
+		WithArgs(last.Version).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta(last.Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`)).
+		WithArgs(last.Version, last.Name).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// 2. Act
+	applied, err := migrator.Up(context.Background())
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, last.Version, applied[0].Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_UpSkipsMigrationAppliedByAnotherInstance(t *testing.T) {
+	// 1. Assess
+	migrator, mock, cleanup := setupMigrator(t)
+	defer cleanup()
+
+	first, second := migrator.Migrations()[0], migrator.Migrations()[1]
+
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT version, applied_at FROM schema_migrations ORDER BY version`)).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+	// lock was held by another instance which has applied the migration meanwhile
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`)).
+		WithArgs(first.Version).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+	mock.MatchExpectationsInOrder(true)
+
+	// 2. Act
+	_, err := migrator.Up(context.Background())
+
+	// 3. Assert
+	// script of the first migration is not executed, the mock does not expect the second one
+	assert.ErrorContains(t, err, second.Name)
+	assert.NotContains(t, err.Error(), first.Name)
+}
+
+func TestMigrator_Status(t *testing.T) {
+	// 1. Assess
+	migrator, mock, cleanup := setupMigrator(t)
+	defer cleanup()
+
+	appliedAt := time.Date(2025, 4, 23, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT version, applied_at FROM schema_migrations ORDER BY version`)).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, appliedAt))
+
+	// 2. Act
+	statuses, err := migrator.Status(context.Background())
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Len(t, statuses, len(migrator.Migrations()))
+	assert.True(t, statuses[0].Applied)
+	assert.Equal(t, appliedAt, *statuses[0].AppliedAt)
+	assert.False(t, statuses[1].Applied)
+	assert.Nil(t, statuses[1].AppliedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
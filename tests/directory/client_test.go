@@ -0,0 +1,126 @@
+package directory
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/directory"
+	"testing"
+	"time"
+)
+
+const baseDN = "OU=Staff,DC=example,DC=com"
+
+// testEntries service account, two users of Active Directory (one is disabled) and an entry outside of base DN
+func testEntries() []directory.TestEntry {
+	return []directory.TestEntry{
+		{DN: "CN=booking-sync,OU=Service Accounts,DC=example,DC=com", Password: "sync-password"},
+		{DN: "CN=Jane Doe," + baseDN, Password: "jane-password", Attributes: map[string][]string{
+			"objectClass":     {"top", "person", "user"},
+			"objectGUID":      {"guid-jane"},
+			"sAMAccountName":  {"jane.doe"},
+			"displayName":     {"Jane Doe"},
+			"mail":            {"jane.doe@example.com"},
+			"telephoneNumber": {"+992900000001"},
+			"memberOf":        {"CN=Booking Admins,OU=Groups,DC=example,DC=com", "CN=Staff,OU=Groups,DC=example,DC=com"},
+		}},
+		{DN: "CN=John Roe," + baseDN, Password: "john-password", Attributes: map[string][]string{
+			"objectClass":        {"top", "person", "user"},
+			"objectGUID":         {"guid-john"},
+			"sAMAccountName":     {"john.roe"},
+			"mail":               {"john.roe@example.com"},
+			"userAccountControl": {"514"}, // NORMAL_ACCOUNT | ACCOUNTDISABLE
+		}},
+		{DN: "CN=Guest,OU=Contractors,DC=example,DC=com", Password: "guest-password", Attributes: map[string][]string{
+			"objectClass":    {"top", "person", "user"},
+			"objectGUID":     {"guid-guest"},
+			"sAMAccountName": {"guest"},
+		}},
+	}
+}
+
+func newTestClient(t *testing.T) (*directory.Client, *directory.TestServer) {
+	server, err := directory.NewTestServer(testEntries())
+	assert.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	return directory.NewClient(directory.Config{
+		URL:                server.URL(),
+		BindDN:             "CN=booking-sync,OU=Service Accounts,DC=example,DC=com",
+		BindPassword:       "sync-password",
+		BaseDN:             baseDN,
+		UserFilter:         "(&(objectClass=user)(sAMAccountName=*))",
+		IdAttribute:        "objectGUID",
+		UsernameAttribute:  "sAMAccountName",
+		NameAttribute:      "displayName",
+		EmailAttribute:     "mail",
+		TelephoneAttribute: "telephoneNumber",
+		GroupsAttribute:    "memberOf",
+		Timeout:            5 * time.Second,
+		PageSize:           100,
+	}), server
+}
+
+func TestClient_Authenticate(t *testing.T) {
+	// 1. Assess
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	// 2. Act
+	entry, err := client.Authenticate(ctx, "JANE.DOE", "jane-password")
+	_, wrongPasswordError := client.Authenticate(ctx, "jane.doe", "john-password")
+	_, emptyPasswordError := client.Authenticate(ctx, "jane.doe", "")
+	_, disabledError := client.Authenticate(ctx, "john.roe", "john-password")
+	_, outsideError := client.Authenticate(ctx, "guest", "guest-password")
+	_, injectionError := client.Authenticate(ctx, "*", "jane-password")
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "guid-jane", entry.Id)
+	assert.Equal(t, "Jane Doe", entry.Name)
+	assert.Contains(t, entry.Groups, "Booking Admins")
+	assert.ErrorIs(t, wrongPasswordError, directory.ErrInvalidCredentials)
+	assert.ErrorIs(t, emptyPasswordError, directory.ErrInvalidCredentials)
+	assert.ErrorIs(t, disabledError, directory.ErrInvalidCredentials)
+	assert.ErrorIs(t, outsideError, directory.ErrInvalidCredentials)
+	// username is escaped - `*` does not match everybody
+	assert.ErrorIs(t, injectionError, directory.ErrInvalidCredentials)
+}
+
+func TestClient_Users(t *testing.T) {
+	// 1. Assess
+	client, server := newTestClient(t)
+	ctx := context.Background()
+
+	// 2. Act
+	entries, err := client.Users(ctx)
+	server.SetEntries(testEntries()[:2])
+	entriesAfterRemoval, removalError := client.Users(ctx)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "jane.doe", entries[0].Username)
+	assert.Equal(t, "+992900000001", entries[0].Telephone)
+	assert.Equal(t, []string{"CN=Booking Admins,OU=Groups,DC=example,DC=com", "Booking Admins", "CN=Staff,OU=Groups,DC=example,DC=com", "Staff"}, entries[0].Groups)
+	assert.True(t, entries[1].Disabled)
+	assert.NoError(t, removalError)
+	assert.Len(t, entriesAfterRemoval, 1)
+}
+
+func TestClient_WrongServiceAccount(t *testing.T) {
+	// 1. Assess
+	server, err := directory.NewTestServer(testEntries())
+	assert.NoError(t, err)
+	defer server.Close()
+	client := directory.NewClient(directory.Config{URL: server.URL(), BindDN: "CN=booking-sync,OU=Service Accounts,DC=example,DC=com", BindPassword: "wrong",
+		BaseDN: baseDN, IdAttribute: "objectGUID", UsernameAttribute: "sAMAccountName", Timeout: time.Second, PageSize: 100})
+
+	// 2. Act
+	_, usersError := client.Users(context.Background())
+	_, authenticationError := client.Authenticate(context.Background(), "jane.doe", "jane-password")
+
+	// 3. Assert
+	// misconfiguration is not reported as wrong password of the user
+	assert.ErrorContains(t, usersError, "bind as service account failed")
+	assert.NotErrorIs(t, authenticationError, directory.ErrInvalidCredentials)
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/handlers"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupHandlers router of the application over sqlmock database. Returned mock expects no queries, returned service
+// issues tokens accepted by the router
+func setupHandlers(t *testing.T) (http.Handler, sqlmock.Sqlmock, *services.Service) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	assert.NoError(t, err)
+
+	service := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{
+		AccessTokenKey:  "access-token-key-of-at-least-32-bytes",
+		RefreshTokenKey: "refresh-token-key-of-at-least-32-bytes",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	}, nil)
+
+	return handlers.NewHandler(service, handlers.Config{}, nil).Init(), mock, service
+}
+
+func TestDeviceAuthorizationCheck_DeviceOfAnotherRoom(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		url    string
+	}{
+		{"room display", http.MethodGet, "/display/9"},
+		{"book now", http.MethodPost, "/display/9/book-now"},
+		{"check in", http.MethodPost, "/display/9/check-in"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// 1. Assess
+			router, mock, _ := setupHandlers(t)
+			request := httptest.NewRequest(testCase.method, testCase.url, nil)
+			request.Header.Set("Authorization", handlers.DeviceAuthorizationScheme+" 5.secret")
+			recorder := httptest.NewRecorder()
+			// the device is registered for room 4
+			mock.ExpectQuery(`SELECT \* FROM "devices"`).
+				WillReturnRows(sqlmock.NewRows([]string{"device_id", "room_id", "role_id", "token_hash", "active"}).AddRow(5, 4, 6, pkg.HashToken("secret"), true))
+
+			// 2. Act
+			router.ServeHTTP(recorder, request)
+
+			// 3. Assert
+			assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), "access denied")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// expectInactiveUser organization of the token is active, the user is deactivated
+func expectInactiveUser(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`SELECT \* FROM "organizations"`).
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "active"}).AddRow(1, true))
+	mock.ExpectQuery(`SELECT \* FROM "users"`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "organization_id", "role_id", "active"}).AddRow(3, 1, 5, false))
+}
+
+func TestAuthorizationCheck_InactiveUser(t *testing.T) {
+	// 1. Assess
+	router, mock, service := setupHandlers(t)
+	user := models.User{UserId: 3, OrganizationId: 1, RoleId: 5}
+	accessToken, _ := service.AuthService.GenerateTokens(context.Background(), user, pkg.IPAddressIdentity{IP: "192.0.2.1"}, nil)
+	request := httptest.NewRequest(http.MethodGet, "/room/all", nil)
+	request.Header.Set("Authorization", "Bearer "+string(accessToken))
+	recorder := httptest.NewRecorder()
+	expectInactiveUser(mock)
+
+	// 2. Act
+	router.ServeHTTP(recorder, request)
+
+	// 3. Assert
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "user is not active")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshToken_InactiveUser(t *testing.T) {
+	// 1. Assess
+	router, mock, service := setupHandlers(t)
+	user := models.User{UserId: 3, OrganizationId: 1, RoleId: 5}
+	accessToken, refreshToken := service.AuthService.GenerateTokens(context.Background(), user, pkg.IPAddressIdentity{IP: "192.0.2.1"}, nil)
+	request := httptest.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(`{"refresh_token": "`+string(refreshToken)+`"}`))
+	request.Header.Set("Authorization", "Bearer "+string(accessToken))
+	recorder := httptest.NewRecorder()
+	expectInactiveUser(mock)
+
+	// 2. Act
+	router.ServeHTTP(recorder, request)
+
+	// 3. Assert
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "user is not active")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshToken_TokensOfDifferentUsers(t *testing.T) {
+	// 1. Assess
+	router, mock, service := setupHandlers(t)
+	identity := pkg.IPAddressIdentity{IP: "192.0.2.1"}
+	accessToken, _ := service.AuthService.GenerateTokens(context.Background(), models.User{UserId: 3, OrganizationId: 1, RoleId: 5}, identity, nil)
+	_, refreshToken := service.AuthService.GenerateTokens(context.Background(), models.User{UserId: 4, OrganizationId: 1, RoleId: 5}, identity, nil)
+	request := httptest.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(`{"refresh_token": "`+string(refreshToken)+`"}`))
+	request.Header.Set("Authorization", "Bearer "+string(accessToken))
+	recorder := httptest.NewRecorder()
+
+	// 2. Act
+	router.ServeHTTP(recorder, request)
+
+	// 3. Assert
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "tokens are assigned to different users")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthorizationCheck_MalformedAuthorizationHeader(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header string
+	}{
+		{"no header", ""},
+		{"no token", "Bearer"},
+		{"empty token", "Bearer "},
+		{"no scheme", "eyJhbGciOiJIUzI1NiJ9"},
+		{"other scheme", "Basic dXNlcjpwYXNzd29yZA=="},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// 1. Assess
+			router, mock, _ := setupHandlers(t)
+			request := httptest.NewRequest(http.MethodGet, "/room/all", nil)
+			if testCase.header != "" {
+				request.Header.Set("Authorization", testCase.header)
+			}
+			recorder := httptest.NewRecorder()
+
+			// 2. Act
+			router.ServeHTTP(recorder, request)
+
+			// 3. Assert
+			assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), "access denied")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// expectInactiveUser organization of the token is active, the user is deactivated
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestImportUsers_InvalidRows(t *testing.T) {
+	// 1. Assess
+	router, mock, service := setupHandlers(t)
+	accessToken, _ := service.AuthService.GenerateTokens(context.Background(), models.User{UserId: 7, OrganizationId: 1, RoleId: 3}, pkg.IPAddressIdentity{IP: "192.0.2.1"}, nil)
+	table := "name,email,telephone,role_id\n" +
+		"John Doe,john.doe@example,,5\n" +
+		"Jane Doe,jane.doe@example.com,,admin\n" +
+		",,,\n"
+	request := httptest.NewRequest(http.MethodPost, "/user/import?dry_run=true", strings.NewReader(table))
+	request.Header.Set("Authorization", "Bearer "+string(accessToken))
+	recorder := httptest.NewRecorder()
+	// HR may import users
+	mock.ExpectQuery(`SELECT \* FROM "organizations"`).WillReturnRows(sqlmock.NewRows([]string{"organization_id", "active"}).AddRow(1, true))
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "organization_id", "role_id", "active"}).AddRow(7, 1, 3, true))
+	mock.ExpectQuery(`SELECT \* FROM "routes"`).WillReturnRows(sqlmock.NewRows([]string{"route_id", "url"}).AddRow(30, "/user/import"))
+	mock.ExpectQuery(`SELECT \* FROM "permissions"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "route_id", "scope_id"}).AddRow(3, 30, services.AllScopeId))
+	mock.ExpectQuery(`SELECT \* FROM "policies"`).WillReturnRows(sqlmock.NewRows([]string{"policy_id"}))
+	mock.ExpectQuery(`SELECT \* FROM "routes"`).WillReturnRows(sqlmock.NewRows([]string{"route_id", "url", "mfa_required"}).AddRow(30, "/user/import", false))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "mfa_policies"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. Act
+	router.ServeHTTP(recorder, request)
+
+	// 3. Assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var importResult models.ImportResult
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &importResult))
+	assert.True(t, importResult.DryRun)
+	// empty row is skipped
+	assert.Len(t, importResult.Rows, 2)
+	assert.Equal(t, models.ImportActionError, importResult.Rows[0].Action)
+	assert.Contains(t, importResult.Rows[0].Errors, "email_error")
+	assert.Equal(t, models.ImportActionError, importResult.Rows[1].Action)
+	assert.Contains(t, importResult.Rows[1].Errors, "role_id_error")
+	// invalid rows are not imported
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/handlers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// resolveClientIP passes the request through RealIP middleware and returns the client IP address seen by handlers
+func resolveClientIP(trustedProxies []string, remoteAddr string, headers map[string]string) string {
+	var clientIP string
+	handler := handlers.RealIP(trustedProxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientIP = handlers.ClientIP(r)
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/booking/all", nil)
+	request.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	return clientIP
+}
+
+func TestRealIP_IPv6RemoteAddr(t *testing.T) {
+	// 1. Assess
+	// 2. Act
+	ipv6 := resolveClientIP(nil, "[2001:db8::1]:51234", nil)
+	mapped := resolveClientIP(nil, "[::ffff:10.0.0.7]:51234", nil)
+	ipv4 := resolveClientIP(nil, "10.0.0.7:51234", nil)
+
+	// 3. Assert
+	assert.Equal(t, "2001:db8::1", ipv6)
+	assert.Equal(t, "10.0.0.7", mapped)
+	assert.Equal(t, "10.0.0.7", ipv4)
+}
+
+func TestRealIP_UntrustedPeerCannotSpoofAddress(t *testing.T) {
+	// 1. Assess
+	headers := map[string]string{"X-Forwarded-For": "1.1.1.1", "Forwarded": "for=1.1.1.1"}
+
+	// 2. Act
+	clientIP := resolveClientIP([]string{"10.0.0.0/8"}, "203.0.113.5:443", headers)
+
+	// 3. Assert
+	assert.Equal(t, "203.0.113.5", clientIP)
+}
+
+func TestRealIP_XForwardedForBehindTrustedProxies(t *testing.T) {
+	// 1. Assess
+	// client wrote a fake address to the header, the edge proxy appended the real one, the inner proxy - the edge proxy
+	headers := map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.20, 10.0.0.2"}
+
+	// 2. Act
+	clientIP := resolveClientIP([]string{"10.0.0.0/8"}, "10.0.0.1:443", headers)
+	allTrusted := resolveClientIP([]string{"10.0.0.0/8"}, "10.0.0.1:443", map[string]string{"X-Forwarded-For": "10.0.0.3"})
+
+	// 3. Assert
+	assert.Equal(t, "198.51.100.20", clientIP)
+	assert.Equal(t, "10.0.0.3", allTrusted)
+}
+
+func TestRealIP_ForwardedHeader(t *testing.T) {
+	// 1. Assess
+	ipv6 := map[string]string{"Forwarded": `for="[2001:db8:cafe::17]:4711";proto=https, for=fd00::2`, "X-Forwarded-For": "1.1.1.1"}
+	obfuscated := map[string]string{"Forwarded": "for=_hidden, for=fd00::2"}
+
+	// 2. Act
+	clientIP := resolveClientIP([]string{"fd00::/8"}, "[fd00::1]:443", ipv6)
+	obfuscatedClientIP := resolveClientIP([]string{"fd00::/8"}, "[fd00::1]:443", obfuscated)
+
+	// 3. Assert
+	// Forwarded takes priority over X-Forwarded-For
+	assert.Equal(t, "2001:db8:cafe::17", clientIP)
+	// the last trusted hop is the client if the next one is not IP address
+	assert.Equal(t, "fd00::2", obfuscatedClientIP)
+}
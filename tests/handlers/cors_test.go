@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/handlers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var corsConfig = handlers.CORSConfig{
+	AllowedOrigins: []string{"https://booking.example.com", "https://*.apps.example.com"},
+	AllowedHeaders: []string{"Authorization", "Content-Type"},
+	ExposedHeaders: []string{"X-Request-ID", "traceparent"},
+	MaxAge:         10 * time.Minute,
+}
+
+// setupRouter router with CORS middleware and one route. Status of handler is 200 with JSON body
+func setupRouter(config handlers.CORSConfig) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(handlers.CORS(config))
+	router.HandleFunc("/room/update", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}).Methods(http.MethodPost, http.MethodOptions)
+
+	return router
+}
+
+func TestCORS_PreflightOfAllowedOrigin(t *testing.T) {
+	// 1. Assess
+	router := setupRouter(corsConfig)
+	request := httptest.NewRequest(http.MethodOptions, "/room/update", nil)
+	request.Header.Set("Origin", "https://booking.example.com")
+	request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	request.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+	recorder := httptest.NewRecorder()
+
+	// 2. Act
+	router.ServeHTTP(recorder, request)
+
+	// 3. Assert
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "https://booking.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "POST", recorder.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type", recorder.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", recorder.Header().Get("Access-Control-Max-Age"))
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}, recorder.Header().Values("Vary"))
+}
+
+func TestCORS_PreflightIsRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+	}{
+		{name: "unknown origin", origin: "https://evil.example.com", method: http.MethodPost},
+		{name: "host of wildcard origin itself", origin: "https://apps.example.com", method: http.MethodPost},
+		{name: "other scheme", origin: "http://booking.example.com", method: http.MethodPost},
+		{name: "method of other route", origin: "https://booking.example.com", method: http.MethodDelete},
+		{name: "header is not allowed", origin: "https://booking.example.com", method: http.MethodPost, headers: "X-Custom"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// 1. Assess
+			router := setupRouter(corsConfig)
+			request := httptest.NewRequest(http.MethodOptions, "/room/update", nil)
+			request.Header.Set("Origin", test.origin)
+			request.Header.Set("Access-Control-Request-Method", test.method)
+			request.Header.Set("Access-Control-Request-Headers", test.headers)
+			recorder := httptest.NewRecorder()
+
+			// 2. Act
+			router.ServeHTTP(recorder, request)
+
+			// 3. Assert
+			assert.Equal(t, http.StatusNoContent, recorder.Code)
+			assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+			assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Methods"))
+		})
+	}
+}
+
+func TestCORS_RequestOfWildcardSubdomain(t *testing.T) {
+	// 1. Assess
+	router := setupRouter(corsConfig)
+	request := httptest.NewRequest(http.MethodPost, "/room/update", nil)
+	request.Header.Set("Origin", "https://admin.apps.example.com")
+	recorder := httptest.NewRecorder()
+
+	// 2. Act
+	router.ServeHTTP(recorder, request)
+
+	// 3. Assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "https://admin.apps.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Request-ID, traceparent", recorder.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "Origin", recorder.Header().Get("Vary"))
+	// content type of the handler is not overwritten
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+}
+
+func TestCORS_RequestOfNotAllowedOriginIsServedWithoutHeaders(t *testing.T) {
+	// 1. Assess
+	router := setupRouter(corsConfig)
+	request := httptest.NewRequest(http.MethodPost, "/room/update", nil)
+	request.Header.Set("Origin", "https://evil.example.com")
+	recorder := httptest.NewRecorder()
+
+	// 2. Act
+	router.ServeHTTP(recorder, request)
+
+	// 3. Assert
+	// the browser blocks reading of the response
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", recorder.Header().Get("Vary"))
+}
+
+func TestCORS_AnyOrigin(t *testing.T) {
+	// 1. Assess
+	router := setupRouter(handlers.CORSConfig{AllowedOrigins: []string{"*"}})
+	request := httptest.NewRequest(http.MethodPost, "/room/update", nil)
+	request.Header.Set("Origin", "https://any.example.org")
+	recorder := httptest.NewRecorder()
+
+	// 2. Act
+	router.ServeHTTP(recorder, request)
+
+	// 3. Assert
+	assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, recorder.Header().Get("Vary"))
+}
+
+func TestCORS_CredentialsAreAllowedForEchoedOrigin(t *testing.T) {
+	// 1. Assess
+	config := corsConfig
+	config.AllowCredentials = true
+	router := setupRouter(config)
+	request := httptest.NewRequest(http.MethodPost, "/room/update", nil)
+	request.Header.Set("Origin", "https://booking.example.com")
+	recorder := httptest.NewRecorder()
+
+	// 2. Act
+	router.ServeHTTP(recorder, request)
+
+	// 3. Assert
+	assert.Equal(t, "https://booking.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORS_PlainOptionsRequest(t *testing.T) {
+	// 1. Assess
+	router := setupRouter(corsConfig)
+	request := httptest.NewRequest(http.MethodOptions, "/room/update", nil)
+	recorder := httptest.NewRecorder()
+
+	// 2. Act
+	router.ServeHTTP(recorder, request)
+
+	// 3. Assert
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "POST", recorder.Header().Get("Allow"))
+	assert.Empty(t, recorder.Body.String())
+}
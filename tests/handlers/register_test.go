@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/handlers"
+	"go-booking-system/internal/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegister_OrganizationAndRoleOfRequestAreIgnored(t *testing.T) {
+	// 1. Assess
+	_, mock, service := setupHandlers(t)
+	router := handlers.NewHandler(service, handlers.Config{RegistrationEnabled: true}, ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{})).Init()
+	body := `{"organization_id": 2, "role_id": 1, "name": "John Doe", "email": "john.doe@example.com", "telephone": "+992900000000",
+		"username": "john.doe", "password": "Password123!"}`
+	request := httptest.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	mock.ExpectQuery(`SELECT \* FROM "roles"`).
+		WillReturnRows(sqlmock.NewRows([]string{"role_id", "name"}).AddRow(5, "User"))
+	mock.ExpectBegin()
+	// user of the host organization with role of regular user
+	mock.ExpectQuery(`INSERT INTO "users" \("organization_id","name","email","telephone","role_id"`).
+		WithArgs(1, "John Doe", "john.doe@example.com", "+992900000000", 5, "john.doe", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), false, true, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(11))
+	mock.ExpectCommit()
+
+	// 2. Act
+	router.ServeHTTP(recorder, request)
+
+	// 3. Assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
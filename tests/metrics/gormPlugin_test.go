@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/metrics"
+	"go-booking-system/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"regexp"
+	"testing"
+)
+
+func TestGormPlugin_ObservesQueryDuration(t *testing.T) {
+	// 1. Assess
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	assert.NoError(t, err)
+	assert.NoError(t, gormDB.Use(metrics.GormPlugin{}))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "rooms"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"room_id"}).AddRow(1))
+
+	// 2. Act
+	var rooms []models.Room
+	gormDB.Find(&rooms)
+
+	// 3. Assert
+	assert.NoError(t, mock.ExpectationsWereMet())
+	// one series: operation="query", table="rooms"
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.DBQueryDuration, "booking_db_query_duration_seconds"))
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/notify"
+	"testing"
+)
+
+func TestNewNotifier(t *testing.T) {
+	// 1. Assess
+	config := notify.Config{Provider: notify.ProviderLog}
+
+	// 2. Act
+	notifier, err := notify.NewNotifier(config)
+	_, unknownError := notify.NewNotifier(notify.Config{Provider: "pigeon"})
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.IsType(t, &notify.LogNotifier{}, notifier)
+	assert.ErrorContains(t, unknownError, "unknown notify provider")
+}
+
+func TestMemoryNotifier(t *testing.T) {
+	// 1. Assess
+	notifier := notify.NewMemoryNotifier()
+	ctx := context.Background()
+
+	// 2. Act
+	assert.NoError(t, notifier.Send(ctx, notify.Message{To: "ahmad@example.com", Subject: "first"}))
+	assert.NoError(t, notifier.Send(ctx, notify.Message{To: "john@example.com", Subject: "other"}))
+	assert.NoError(t, notifier.Send(ctx, notify.Message{To: "ahmad@example.com", Subject: "second"}))
+	message, ok := notifier.LastMessage("Ahmad@example.com")
+	_, unknownOk := notifier.LastMessage("nobody@example.com")
+
+	// 3. Assert
+	assert.Len(t, notifier.Messages(), 3)
+	assert.True(t, ok)
+	assert.Equal(t, "second", message.Subject)
+	assert.False(t, unknownOk)
+}
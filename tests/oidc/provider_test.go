@@ -0,0 +1,147 @@
+package oidc
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/oidc"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const clientId = "go-booking-system"
+
+var mockUser = oidc.MockUser{
+	Subject:           "248289761001",
+	Email:             "jane.doe@example.com",
+	Name:              "Jane Doe",
+	PreferredUsername: "jane.doe",
+	Groups:            []string{"staff", "booking-admins"},
+	AuthMethods:       []string{"pwd", "mfa"},
+}
+
+// startMockProvider returns provider client configured for mock provider served by test server
+func startMockProvider(t *testing.T) (*oidc.MockProvider, *oidc.Provider) {
+	mock, err := oidc.NewMockProvider(clientId, mockUser)
+	assert.NoError(t, err)
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+	mock.Issuer = server.URL
+
+	provider := oidc.NewProvider(oidc.Config{
+		Issuer:      server.URL,
+		ClientId:    clientId,
+		RedirectURL: "http://localhost:8080/auth/oidc/callback",
+		Scopes:      []string{"openid", "profile", "email"},
+		GroupsClaim: "groups",
+	}, server.Client())
+
+	return mock, provider
+}
+
+// authorize opens login page of the provider and returns query of the redirect back to the application
+func authorize(t *testing.T, authURL string) url.Values {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	response, err := client.Get(authURL)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+
+	location, err := url.Parse(response.Header.Get("Location"))
+	assert.NoError(t, err)
+	return location.Query()
+}
+
+func TestProvider_AuthorizationCodeFlowWithPKCE(t *testing.T) {
+	// 1. Assess
+	_, provider := startMockProvider(t)
+	ctx := context.Background()
+	verifier, err := oidc.GenerateCodeVerifier()
+	assert.NoError(t, err)
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", oidc.CodeChallenge(verifier))
+	assert.NoError(t, err)
+	callback := authorize(t, authURL)
+
+	// 2. Act
+	rawIDToken, exchangeError := provider.Exchange(ctx, callback.Get("code"), verifier)
+	claims, verifyError := provider.VerifyIDToken(ctx, rawIDToken, "nonce-1")
+
+	// 3. Assert
+	assert.Equal(t, "state-1", callback.Get("state"))
+	assert.NoError(t, exchangeError)
+	assert.NoError(t, verifyError)
+	assert.Equal(t, mockUser.Subject, claims.Subject)
+	assert.Equal(t, mockUser.Email, claims.Email)
+	assert.True(t, claims.EmailVerified)
+	assert.Equal(t, mockUser.PreferredUsername, claims.PreferredUsername)
+	assert.Equal(t, mockUser.Groups, claims.Groups)
+	assert.Equal(t, mockUser.AuthMethods, claims.AuthMethods)
+}
+
+func TestProvider_Exchange_RejectsWrongVerifierAndReusedCode(t *testing.T) {
+	// 1. Assess
+	_, provider := startMockProvider(t)
+	ctx := context.Background()
+	verifier, _ := oidc.GenerateCodeVerifier()
+	otherVerifier, _ := oidc.GenerateCodeVerifier()
+	authURL, _ := provider.AuthCodeURL(ctx, "state", "nonce", oidc.CodeChallenge(verifier))
+	code := authorize(t, authURL).Get("code")
+
+	// 2. Act
+	_, wrongVerifierError := provider.Exchange(ctx, code, otherVerifier)
+	_, reusedCodeError := provider.Exchange(ctx, code, verifier)
+
+	// 3. Assert
+	// intercepted code is useless without verifier, and the code is burnt by the failed attempt
+	assert.ErrorContains(t, wrongVerifierError, "invalid_grant")
+	assert.ErrorContains(t, reusedCodeError, "invalid_grant")
+}
+
+func TestProvider_VerifyIDToken_RejectsInvalidClaims(t *testing.T) {
+	// 1. Assess
+	mock, provider := startMockProvider(t)
+	ctx := context.Background()
+	now := time.Now()
+	validClaims := func() map[string]any {
+		return map[string]any{"iss": mock.Issuer, "sub": "1", "aud": clientId, "exp": now.Add(time.Minute).Unix(), "iat": now.Unix(), "nonce": "nonce"}
+	}
+	cases := map[string]func(claims map[string]any){
+		"another issuer":   func(claims map[string]any) { claims["iss"] = "https://evil.example.com" },
+		"another audience": func(claims map[string]any) { claims["aud"] = []string{"other-client"} },
+		"expired":          func(claims map[string]any) { claims["exp"] = now.Add(-time.Hour).Unix() },
+		"another nonce":    func(claims map[string]any) { claims["nonce"] = "replayed" },
+	}
+
+	for name, change := range cases {
+		claims := validClaims()
+		change(claims)
+		rawIDToken, err := mock.SignIDToken(claims)
+		assert.NoError(t, err)
+
+		// 2. Act
+		_, verifyError := provider.VerifyIDToken(ctx, rawIDToken, "nonce")
+
+		// 3. Assert
+		assert.Error(t, verifyError, name)
+	}
+	validToken, _ := mock.SignIDToken(validClaims())
+	_, validError := provider.VerifyIDToken(ctx, validToken, "nonce")
+	assert.NoError(t, validError)
+}
+
+func TestProvider_VerifyIDToken_RejectsForgedSignature(t *testing.T) {
+	// 1. Assess
+	mock, provider := startMockProvider(t)
+	otherMock, err := oidc.NewMockProvider(clientId, mockUser)
+	assert.NoError(t, err)
+	otherMock.Issuer = mock.Issuer
+	forgedToken, _ := otherMock.SignIDToken(map[string]any{"iss": mock.Issuer, "sub": "1", "aud": clientId, "exp": time.Now().Add(time.Minute).Unix(), "nonce": "nonce"})
+
+	// 2. Act
+	_, verifyError := provider.VerifyIDToken(context.Background(), forgedToken, "nonce")
+
+	// 3. Assert
+	assert.ErrorContains(t, verifyError, "signature")
+}
@@ -0,0 +1,56 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/models"
+	"go-booking-system/pkg"
+	"testing"
+)
+
+func TestNewLogger_RedactsSensitiveFields(t *testing.T) {
+	// 1. Assess
+	var output bytes.Buffer
+	logger, err := pkg.NewLogger(&output, "info", pkg.LogFormatJSON)
+	assert.NoError(t, err)
+	user := models.User{UserId: 7, UserName: "john", Password: "qwerty123"}
+
+	// 2. Act
+	logger.Info("user updated", "passed_data", user, "password", "qwerty123", "access_token", "eyJhbGciOi")
+
+	// 3. Assert
+	assert.NotContains(t, output.String(), "qwerty123")
+	assert.NotContains(t, output.String(), "eyJhbGciOi")
+	assert.Contains(t, output.String(), `"UserName":"john"`)
+	assert.Contains(t, output.String(), pkg.RedactedValue)
+}
+
+func TestNewLogger_AddsRequestId(t *testing.T) {
+	// 1. Assess
+	var output bytes.Buffer
+	logger, err := pkg.NewLogger(&output, "info", pkg.LogFormatJSON)
+	assert.NoError(t, err)
+	ctx := pkg.WithRequestId(context.Background(), "req-42")
+
+	// 2. Act
+	logger.InfoContext(ctx, "request processed")
+	logger.Debug("hidden by level")
+
+	// 3. Assert
+	assert.Contains(t, output.String(), `"request_id":"req-42"`)
+	assert.NotContains(t, output.String(), "hidden by level")
+}
+
+func TestNewLogger_UnknownLevelAndFormat(t *testing.T) {
+	// 1. Assess
+	var output bytes.Buffer
+
+	// 2. Act
+	_, levelErr := pkg.NewLogger(&output, "verbose", pkg.LogFormatText)
+	_, formatErr := pkg.NewLogger(&output, "info", "xml")
+
+	// 3. Assert
+	assert.Error(t, levelErr)
+	assert.Error(t, formatErr)
+}
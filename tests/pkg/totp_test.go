@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/pkg"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret ASCII "12345678901234567890" - SHA1 secret of RFC 6238 test vectors
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// 1. Assess
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unixTime, expected := range vectors {
+		// 2. Act
+		code, err := pkg.TOTPCode(rfc6238Secret, pkg.TOTPStep(time.Unix(unixTime, 0)))
+
+		// 3. Assert
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unixTime)
+	}
+}
+
+func TestValidateTOTP_AcceptsClockSkew(t *testing.T) {
+	// 1. Assess
+	now := time.Unix(1234567890, 0)
+	previousCode, _ := pkg.TOTPCode(rfc6238Secret, pkg.TOTPStep(now)-1)
+	oldCode, _ := pkg.TOTPCode(rfc6238Secret, pkg.TOTPStep(now)-2)
+
+	// 2. Act
+	step, isValid := pkg.ValidateTOTP(rfc6238Secret, previousCode, now, 1)
+	_, isOldValid := pkg.ValidateTOTP(rfc6238Secret, oldCode, now, 1)
+	_, isShortValid := pkg.ValidateTOTP(rfc6238Secret, "5924", now, 1)
+
+	// 3. Assert
+	assert.True(t, isValid)
+	assert.Equal(t, pkg.TOTPStep(now)-1, step)
+	assert.False(t, isOldValid)
+	assert.False(t, isShortValid)
+}
+
+func TestGenerateTOTPSecret_URI(t *testing.T) {
+	// 1. Assess
+	secret, err := pkg.GenerateTOTPSecret()
+	assert.NoError(t, err)
+
+	// 2. Act
+	uri, parseError := url.Parse(pkg.TOTPURI("Booking System", "john", secret))
+
+	// 3. Assert
+	assert.NoError(t, parseError)
+	assert.Len(t, secret, 32)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Booking System:john", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "Booking System", uri.Query().Get("issuer"))
+}
@@ -0,0 +1,161 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/ratelimit"
+	"testing"
+	"time"
+)
+
+var limiterConfig = ratelimit.Config{
+	Enabled:         true,
+	IP:              ratelimit.Limit{Burst: 3, Interval: time.Hour},
+	Username:        ratelimit.Limit{Burst: 2, Interval: time.Hour},
+	Registration:    ratelimit.Limit{Burst: 1, Interval: time.Hour},
+	MaxFailures:     3,
+	FailureWindow:   time.Hour,
+	LockoutDuration: time.Hour,
+	FailureDelay:    100 * time.Millisecond,
+	MaxFailureDelay: 300 * time.Millisecond,
+}
+
+func TestMemoryStore_TakeRefillsTokens(t *testing.T) {
+	// 1. Assess
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{Burst: 2, Interval: 50 * time.Millisecond}
+	ctx := context.Background()
+
+	// 2. Act
+	first, _, _ := store.Take(ctx, "key", limit)
+	second, _, _ := store.Take(ctx, "key", limit)
+	third, retryAfter, _ := store.Take(ctx, "key", limit)
+	time.Sleep(60 * time.Millisecond)
+	afterRefill, _, err := store.Take(ctx, "key", limit)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.True(t, first)
+	assert.True(t, second)
+	assert.False(t, third)
+	assert.Greater(t, retryAfter, time.Duration(0))
+	assert.LessOrEqual(t, retryAfter, limit.Interval)
+	assert.True(t, afterRefill)
+}
+
+func TestMemoryStore_FailuresAreForgottenAfterWindow(t *testing.T) {
+	// 1. Assess
+	store := ratelimit.NewMemoryStore()
+	ctx := context.Background()
+
+	// 2. Act
+	store.AddFailure(ctx, "key", 50*time.Millisecond)
+	inRow, _ := store.AddFailure(ctx, "key", 50*time.Millisecond)
+	time.Sleep(60 * time.Millisecond)
+	afterWindow, err := store.AddFailure(ctx, "key", 50*time.Millisecond)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, inRow)
+	assert.Equal(t, 1, afterWindow)
+}
+
+func TestLimiter_AllowLogin_LimitsUsernameFromAnyIP(t *testing.T) {
+	// 1. Assess
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), limiterConfig)
+	ctx := context.Background()
+
+	// 2. Act
+	first := limiter.AllowLogin(ctx, "10.0.0.1", "alice")
+	second := limiter.AllowLogin(ctx, "10.0.0.2", "Alice")
+	third := limiter.AllowLogin(ctx, "10.0.0.3", "ALICE")
+	otherUser := limiter.AllowLogin(ctx, "10.0.0.3", "bob")
+
+	// 3. Assert
+	assert.NoError(t, first)
+	assert.NoError(t, second)
+	var limitError *ratelimit.LimitError
+	assert.True(t, errors.As(third, &limitError))
+	assert.Greater(t, limitError.RetryAfter, time.Duration(0))
+	assert.NoError(t, otherUser)
+}
+
+func TestLimiter_AllowLogin_LimitsIP(t *testing.T) {
+	// 1. Assess
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), limiterConfig)
+	ctx := context.Background()
+	usernames := []string{"alice", "bob", "carol"}
+	for _, username := range usernames {
+		assert.NoError(t, limiter.AllowLogin(ctx, "10.0.0.1", username))
+	}
+
+	// 2. Act
+	err := limiter.AllowLogin(ctx, "10.0.0.1", "dave")
+
+	// 3. Assert
+	assert.Error(t, err)
+	assert.NoError(t, limiter.AllowLogin(ctx, "10.0.0.2", "dave"))
+}
+
+func TestLimiter_LoginFailed_DelaysAndLocksAccount(t *testing.T) {
+	// 1. Assess
+	config := limiterConfig
+	config.Username = ratelimit.Limit{}
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), config)
+	ctx := context.Background()
+
+	// 2. Act
+	firstDelay, firstLocked := limiter.LoginFailed(ctx, "ghost")
+	secondDelay, _ := limiter.LoginFailed(ctx, "ghost")
+	thirdDelay, thirdLocked := limiter.LoginFailed(ctx, "ghost")
+	err := limiter.AllowLogin(ctx, "10.0.0.1", "ghost")
+
+	// 3. Assert
+	// delays grow up to the max one
+	assert.Equal(t, 100*time.Millisecond, firstDelay)
+	assert.Equal(t, 200*time.Millisecond, secondDelay)
+	assert.Equal(t, 300*time.Millisecond, thirdDelay)
+	assert.False(t, firstLocked)
+	assert.True(t, thirdLocked)
+	// username which does not exist is locked the same way
+	var limitError *ratelimit.LimitError
+	assert.True(t, errors.As(err, &limitError))
+	assert.InDelta(t, time.Hour, limitError.RetryAfter, float64(time.Second))
+}
+
+func TestLimiter_LoginSucceeded_ResetsFailures(t *testing.T) {
+	// 1. Assess
+	config := limiterConfig
+	config.Username = ratelimit.Limit{}
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), config)
+	ctx := context.Background()
+	limiter.LoginFailed(ctx, "alice")
+	limiter.LoginFailed(ctx, "alice")
+
+	// 2. Act
+	limiter.LoginSucceeded(ctx, "alice")
+	delay, isLocked := limiter.LoginFailed(ctx, "alice")
+
+	// 3. Assert
+	assert.Equal(t, 100*time.Millisecond, delay)
+	assert.False(t, isLocked)
+	assert.NoError(t, limiter.AllowLogin(ctx, "10.0.0.1", "alice"))
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	// 1. Assess
+	config := limiterConfig
+	config.Enabled = false
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), config)
+	ctx := context.Background()
+
+	// 2. Act
+	for i := 0; i < 5; i++ {
+		limiter.LoginFailed(ctx, "alice")
+		assert.NoError(t, limiter.AllowRegistration(ctx, "10.0.0.1"))
+	}
+
+	// 3. Assert
+	assert.NoError(t, limiter.AllowLogin(ctx, "10.0.0.1", "alice"))
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+	"regexp"
+	"testing"
+)
+
+func TestAttendeeRepository_CreateAttendees(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewAttendeeRepositoryPostgres(db).ForOrganization(organizationId)
+
+	guest := models.Attendee{
+		BookingId: 1,
+		Name:      "Jane Guest",
+		Email:     "jane@example.com",
+		Status:    models.AttendeeStatusPending,
+		PassCode:  "A1B2C3D4",
+		Active:    true,
+		CreatedBy: 1,
+	}
+
+	rows := sqlmock.NewRows([]string{"pass_code", "attendee_id"}).AddRow(guest.PassCode, 7)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		// fields with default values (pass_code) go last
+		`INSERT INTO "attendees" ("organization_id","booking_id","name","email","status","active","created_by","created_at","pass_code") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`,
+	)).
+		WithArgs(organizationId, guest.BookingId, guest.Name, guest.Email, guest.Status, guest.Active, guest.CreatedBy, NotNullTimeArg(), guest.PassCode).
+		WillReturnRows(rows)
+	mock.ExpectCommit()
+
+	// 2. Act
+	createdAttendees, err := repo.CreateAttendees(context.Background(), []models.Attendee{guest})
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Len(t, createdAttendees, 1)
+	assert.Equal(t, 7, createdAttendees[0].AttendeeId)
+	assert.Equal(t, organizationId, createdAttendees[0].OrganizationId)
+	assert.Equal(t, true, createdAttendees[0].IsGuest())
+}
+
+func TestAttendeeRepository_CountAttendingByBookingId(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewAttendeeRepositoryPostgres(db)
+
+	bookingId := 1
+
+	// declined attendees don't take places in the room
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM "attendees" WHERE "active"=$1 AND "booking_id"=$2 AND "status"<>$3`,
+	)).
+		WithArgs(true, bookingId, models.AttendeeStatusDeclined).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	// 2. Act
+	attendingCount, err := repo.CountAttendingByBookingId(context.Background(), bookingId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attendingCount)
+}
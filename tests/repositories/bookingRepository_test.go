@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"go-booking-system/internal/database/repositories"
@@ -15,7 +16,8 @@ func TestBookingRepository_Create(t *testing.T) {
 	db, mock, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	repo := repositories.NewBookingRepositoryPostgres(db)
+	organizationId := 1
+	repo := repositories.NewBookingRepositoryPostgres(db).ForOrganization(organizationId)
 
 	roomId := 4
 	userId := 2
@@ -37,14 +39,14 @@ func TestBookingRepository_Create(t *testing.T) {
 
 	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(
-		`INSERT INTO "bookings" ("user_id","room_id","datetime_start","datetime_end","created_by","created_at") VALUES ($1,$2,$3,$4,$5,$6)`,
+		`INSERT INTO "bookings" ("organization_id","user_id","room_id","datetime_start","datetime_end","created_by","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7)`,
 	)).
-		WithArgs(bookingToCreate.UserId, bookingToCreate.RoomId, bookingToCreate.DateTimeStart, bookingToCreate.DateTimeEnd, bookingToCreate.CreatedBy, NotNullTimeArg()).
+		WithArgs(organizationId, bookingToCreate.UserId, bookingToCreate.RoomId, bookingToCreate.DateTimeStart, bookingToCreate.DateTimeEnd, bookingToCreate.CreatedBy, NotNullTimeArg()).
 		WillReturnRows(rows)
 	mock.ExpectCommit()
 
 	// 2. Act
-	createdBooking, err := repo.Create(bookingToCreate)
+	createdBooking, err := repo.Create(context.Background(), bookingToCreate)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -96,7 +98,7 @@ func TestBookingRepository_GetAll(t *testing.T) {
 		WillReturnRows(rows)
 
 	// 2. Act
-	allBookings := repo.GetAll()
+	allBookings := repo.GetAll(context.Background())
 
 	// 3. Assert
 	assert.Equal(t, 1, allBookings[0].BookingId)
@@ -146,7 +148,7 @@ func TestBookingRepository_GetBookingByID(t *testing.T) {
 		WillReturnRows(rows)
 
 	// 2. Act
-	actualBooking, err := repo.GetBookingById(expectedBooking.BookingId)
+	actualBooking, err := repo.GetBookingById(context.Background(), expectedBooking.BookingId)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -192,7 +194,7 @@ func TestBookingRepository_Update(t *testing.T) {
 	mock.ExpectCommit()
 
 	// 2. Act
-	updatedBooking, err := repo.Update(updateData)
+	updatedBooking, err := repo.Update(context.Background(), updateData)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -222,13 +224,45 @@ func TestBookingRepository_Delete(t *testing.T) {
 	mock.ExpectCommit()
 
 	// 2. Act
-	isDeleted, err := repo.Delete(bookingId)
+	isDeleted, err := repo.Delete(context.Background(), bookingId)
 
 	// 3. Assert
 	assert.NoError(t, err)
 	assert.Equal(t, true, isDeleted)
 }
 
+func TestBookingRepository_DeleteEndedBefore(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewBookingRepositoryPostgres(db).ForOrganization(organizationId)
+
+	before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM "attendees" WHERE booking_id IN (SELECT "booking_id" FROM "bookings" WHERE datetime_end < $1 AND bookings.organization_id = $2)`,
+	)).
+		WithArgs(before, organizationId).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM "bookings" WHERE datetime_end < $1 AND bookings.organization_id = $2`,
+	)).
+		WithArgs(before, organizationId).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	// 2. Act
+	purged, err := repo.DeleteEndedBefore(context.Background(), before)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestBookingRepository_GetBookingsByRoomId(t *testing.T) {
 	// 1. Assess
 	db, mock, cleanup := setupTestDB(t)
@@ -271,7 +305,7 @@ func TestBookingRepository_GetBookingsByRoomId(t *testing.T) {
 		WillReturnRows(rows)
 
 	// 2. Act
-	allBookings, err := repo.GetBookingsByRoomId(roomId)
+	allBookings, err := repo.GetBookingsByRoomId(context.Background(), roomId)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -345,7 +379,7 @@ func TestBookingRepository_GetBookingsByRoomIdAndBookingTime(t *testing.T) {
 		WillReturnRows(rows)
 
 	// 2. Act
-	overlappingBookings, err := repo.GetBookingsByRoomIdAndBookingTime(roomId, dateTimeStart, dateTimeEnd)
+	overlappingBookings, err := repo.GetBookingsByRoomIdAndBookingTime(context.Background(), roomId, dateTimeStart, dateTimeEnd)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -366,3 +400,90 @@ func TestBookingRepository_GetBookingsByRoomIdAndBookingTime(t *testing.T) {
 	assert.Equal(t, expectedBooking2.CreatedAt, overlappingBookings[1].CreatedAt)
 
 }
+
+func TestBookingRepository_GetUpcomingBookingsByRoomId(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewBookingRepositoryPostgres(db)
+
+	roomId := 4
+	from := time.Date(2025, 4, 3, 16, 40, 0, 0, time.UTC)
+	limit := 2
+
+	// booking which is going on (ends after `from`) and the next one, ordered by start
+	rows := sqlmock.NewRows([]string{"booking_id", "room_id", "datetime_start", "datetime_end", "active"}).
+		AddRow(7, roomId, from.Add(-10*time.Minute), from.Add(20*time.Minute), true).
+		AddRow(8, roomId, from.Add(time.Hour), from.Add(2*time.Hour), true)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "bookings" WHERE room_id = $1 AND active = true AND datetime_end > $2 ORDER BY datetime_start LIMIT $3`,
+	)).
+		WithArgs(roomId, from, limit).
+		WillReturnRows(rows)
+
+	// 2. Act
+	upcomingBookings, err := repo.GetUpcomingBookingsByRoomId(context.Background(), roomId, from, limit)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Len(t, upcomingBookings, 2)
+	assert.Equal(t, 7, upcomingBookings[0].BookingId)
+	assert.Equal(t, 8, upcomingBookings[1].BookingId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_CheckIn(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewBookingRepositoryPostgres(db)
+
+	bookingId := 7
+	checkedInAt := time.Date(2025, 4, 3, 16, 25, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "bookings" SET "checked_in_at"=$1,"updated_at"=$2 WHERE "active"=$3 AND "booking_id" = $4`,
+	)).
+		WithArgs(checkedInAt, NotNullTimeArg(), true, bookingId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "bookings" WHERE "booking_id" = $1`)).
+		WithArgs(bookingId).
+		WillReturnRows(sqlmock.NewRows([]string{"booking_id", "checked_in_at", "active"}).AddRow(bookingId, checkedInAt, true))
+
+	// 2. Act
+	checkedInBooking, err := repo.CheckIn(context.Background(), bookingId, checkedInAt)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, bookingId, checkedInBooking.BookingId)
+	assert.Equal(t, checkedInAt, checkedInBooking.CheckedInAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_CheckIn_CancelledBooking(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewBookingRepositoryPostgres(db)
+
+	bookingId := 7
+	checkedInAt := time.Date(2025, 4, 3, 16, 25, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "bookings" SET "checked_in_at"=$1`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	// 2. Act
+	_, err := repo.CheckIn(context.Background(), bookingId, checkedInAt)
+
+	// 3. Assert
+	assert.EqualError(t, err, "no Bookings were checked in")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+	"regexp"
+	"testing"
+)
+
+func TestBuildingRepository_Create(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewBuildingRepositoryPostgres(db)
+
+	buildingToCreate := models.Building{OrganizationId: 1, LocationId: 3, Name: "Annex", Address: "Khujand", CreatedBy: 5}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "buildings" ("organization_id","location_id","name","address","created_by","created_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "building_id"`,
+	)).
+		WithArgs(buildingToCreate.OrganizationId, buildingToCreate.LocationId, buildingToCreate.Name, buildingToCreate.Address, buildingToCreate.CreatedBy, NotNullTimeArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"building_id"}).AddRow(4))
+	mock.ExpectCommit()
+
+	// 2. Act
+	createdBuilding, err := repo.Create(context.Background(), buildingToCreate)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, createdBuilding.BuildingId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildingRepository_GetBuildingById(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewBuildingRepositoryPostgres(db)
+
+	buildingId := 4
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "buildings" WHERE "building_id" = $1`)).
+		WithArgs(buildingId).
+		WillReturnRows(sqlmock.NewRows([]string{"building_id", "location_id", "name"}).AddRow(4, 3, "Annex"))
+
+	// 2. Act
+	foundBuilding, err := repo.GetBuildingById(context.Background(), buildingId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, foundBuilding.LocationId)
+	assert.Equal(t, "Annex", foundBuilding.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildingRepository_GetBuildingsByLocationId(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewBuildingRepositoryPostgres(db)
+
+	locationId := 3
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "buildings" WHERE "location_id" = $1`)).
+		WithArgs(locationId).
+		WillReturnRows(sqlmock.NewRows([]string{"building_id", "location_id", "name"}).
+			AddRow(4, locationId, "Annex").
+			AddRow(5, locationId, "Main building"))
+
+	// 2. Act
+	foundBuildings, err := repo.GetBuildingsByLocationId(context.Background(), locationId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Len(t, foundBuildings, 2)
+	assert.Equal(t, "Main building", foundBuildings[1].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildingRepository_Delete(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewBuildingRepositoryPostgres(db)
+
+	buildingId := 4
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "buildings" SET "active"=$1,"deleted_at"=$2 WHERE "active"=$3 AND "building_id" = $4`,
+	)).
+		WithArgs(false, AnyTimeArg(), true, buildingId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// 2. Act
+	isDeleted, err := repo.Delete(context.Background(), buildingId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.True(t, isDeleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildingRepository_Create_LocationOfAnotherOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewBuildingRepositoryPostgres(db).ForOrganization(organizationId)
+
+	buildingToCreate := models.Building{LocationId: 1, Name: "Annex", CreatedBy: 5}
+
+	// location belongs to the host organization - building is not created
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM "locations" WHERE locations.location_id = $1 AND locations.organization_id = $2`,
+	)).
+		WithArgs(buildingToCreate.LocationId, organizationId).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. Act
+	_, err := repo.Create(context.Background(), buildingToCreate)
+
+	// 3. Assert
+	assert.EqualError(t, err, "no Locations were found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildingRepository_GetBuildingsByLocationId_ForOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewBuildingRepositoryPostgres(db).ForOrganization(organizationId)
+
+	locationId := 1
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "buildings" WHERE "location_id" = $1 AND ((buildings.organization_id = $2 OR EXISTS (`,
+	)).
+		WithArgs(locationId, organizationId, organizationId).
+		WillReturnRows(sqlmock.NewRows([]string{"building_id", "organization_id", "location_id", "name"}))
+
+	// 2. Act
+	foundBuildings, err := repo.GetBuildingsByLocationId(context.Background(), locationId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Empty(t, foundBuildings)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+	"regexp"
+	"testing"
+)
+
+func TestFloorRepository_Create(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewFloorRepositoryPostgres(db)
+
+	floorToCreate := models.Floor{OrganizationId: 1, BuildingId: 4, Name: "Ground floor", Level: 0, CreatedBy: 5}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "floors" ("organization_id","building_id","name","level","created_by","created_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "floor_id"`,
+	)).
+		WithArgs(floorToCreate.OrganizationId, floorToCreate.BuildingId, floorToCreate.Name, floorToCreate.Level, floorToCreate.CreatedBy, NotNullTimeArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"floor_id"}).AddRow(7))
+	mock.ExpectCommit()
+
+	// 2. Act
+	createdFloor, err := repo.Create(context.Background(), floorToCreate)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 7, createdFloor.FloorId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFloorRepository_GetFloorById(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewFloorRepositoryPostgres(db)
+
+	floorId := 7
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "floors" WHERE "floor_id" = $1`)).
+		WithArgs(floorId).
+		WillReturnRows(sqlmock.NewRows([]string{"floor_id", "building_id", "name"}).AddRow(7, 4, "Ground floor"))
+
+	// 2. Act
+	foundFloor, err := repo.GetFloorById(context.Background(), floorId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, foundFloor.BuildingId)
+	assert.Equal(t, "Ground floor", foundFloor.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFloorRepository_GetFloorsByBuildingId(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewFloorRepositoryPostgres(db)
+
+	buildingId := 4
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "floors" WHERE "building_id" = $1`)).
+		WithArgs(buildingId).
+		WillReturnRows(sqlmock.NewRows([]string{"floor_id", "building_id", "name", "level"}).
+			AddRow(6, buildingId, "Basement", -1).
+			AddRow(7, buildingId, "Ground floor", 0))
+
+	// 2. Act
+	foundFloors, err := repo.GetFloorsByBuildingId(context.Background(), buildingId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Len(t, foundFloors, 2)
+	assert.Equal(t, -1, foundFloors[0].Level)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFloorRepository_Update(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewFloorRepositoryPostgres(db)
+
+	floorToUpdate := models.Floor{FloorId: 7, Name: "Mezzanine"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "floors" SET "name"=$1,"updated_at"=$2 WHERE "floor_id" = $3`,
+	)).
+		WithArgs(floorToUpdate.Name, AnyTimeArg(), floorToUpdate.FloorId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// 2. Act
+	updatedFloor, err := repo.Update(context.Background(), floorToUpdate)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Mezzanine", updatedFloor.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFloorRepository_Update_NotFound(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewFloorRepositoryPostgres(db)
+
+	floorToUpdate := models.Floor{FloorId: 42, Name: "Mezzanine"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "floors" SET "name"=$1,"updated_at"=$2 WHERE "floor_id" = $3`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	// 2. Act
+	_, err := repo.Update(context.Background(), floorToUpdate)
+
+	// 3. Assert
+	assert.EqualError(t, err, "no Floors were updated")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFloorRepository_Create_ForOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewFloorRepositoryPostgres(db).ForOrganization(organizationId)
+
+	floorToCreate := models.Floor{BuildingId: 4, Name: "Second floor", Level: 2, CreatedBy: 5}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM "buildings" WHERE buildings.building_id = $1 AND buildings.organization_id = $2`,
+	)).
+		WithArgs(floorToCreate.BuildingId, organizationId).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "floors" ("organization_id","building_id","name","level","created_by","created_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "floor_id"`,
+	)).
+		WithArgs(organizationId, floorToCreate.BuildingId, floorToCreate.Name, floorToCreate.Level, floorToCreate.CreatedBy, NotNullTimeArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"floor_id"}).AddRow(7))
+	mock.ExpectCommit()
+
+	// 2. Act
+	createdFloor, err := repo.Create(context.Background(), floorToCreate)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 7, createdFloor.FloorId)
+	assert.Equal(t, organizationId, createdFloor.OrganizationId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFloorRepository_Delete_ForOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewFloorRepositoryPostgres(db).ForOrganization(organizationId)
+
+	floorId := 1
+
+	// floor of the host organization (e.g. with shared room) is not deleted
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "floors" SET "active"=$1,"deleted_at"=$2 WHERE "active"=$3 AND floors.organization_id = $4 AND "floor_id" = $5`,
+	)).
+		WithArgs(false, AnyTimeArg(), true, organizationId, floorId).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	// 2. Act
+	isDeleted, err := repo.Delete(context.Background(), floorId)
+
+	// 3. Assert
+	assert.EqualError(t, err, "no Floors were deleted")
+	assert.False(t, isDeleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,297 @@
+package repositories
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+	"regexp"
+	"testing"
+)
+
+func TestLocationRepository_Create(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewLocationRepositoryPostgres(db)
+
+	locationToCreate := models.Location{
+		OrganizationId: 1,
+		Name:           "Main office",
+		Address:        "Dushanbe",
+		TimeZone:       "Asia/Dushanbe",
+		CreatedBy:      5,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "locations" ("organization_id","name","address","time_zone","created_by","created_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "location_id"`,
+	)).
+		WithArgs(locationToCreate.OrganizationId, locationToCreate.Name, locationToCreate.Address, locationToCreate.TimeZone, locationToCreate.CreatedBy, NotNullTimeArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	// 2. Act
+	createdLocation, err := repo.Create(context.Background(), locationToCreate)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, createdLocation.LocationId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocationRepository_GetAll(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewLocationRepositoryPostgres(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "locations"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id", "name"}).
+			AddRow(1, "Main office").
+			AddRow(2, "Branch office"))
+
+	// 2. Act
+	allLocations := repo.GetAll(context.Background())
+
+	// 3. Assert
+	assert.Len(t, allLocations, 2)
+	assert.Equal(t, "Branch office", allLocations[1].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocationRepository_GetLocationById(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewLocationRepositoryPostgres(db)
+
+	locationId := 1
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "locations" WHERE "location_id" = $1`)).
+		WithArgs(locationId).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id", "name", "time_zone"}).AddRow(1, "Main office", "Asia/Dushanbe"))
+
+	// 2. Act
+	foundLocation, err := repo.GetLocationById(context.Background(), locationId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Main office", foundLocation.Name)
+	assert.Equal(t, "Asia/Dushanbe", foundLocation.TimeZone)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocationRepository_GetLocationById_NotFound(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewLocationRepositoryPostgres(db)
+
+	locationId := 42
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "locations" WHERE "location_id" = $1`)).
+		WithArgs(locationId).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id", "name"}))
+
+	// 2. Act
+	_, err := repo.GetLocationById(context.Background(), locationId)
+
+	// 3. Assert
+	assert.EqualError(t, err, "no Locations were found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocationRepository_GetLocationByRoomId(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewLocationRepositoryPostgres(db)
+
+	roomId := 7
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT locations.* FROM "locations" JOIN buildings ON buildings.location_id = locations.location_id JOIN floors ON floors.building_id = buildings.building_id JOIN rooms ON rooms.floor_id = floors.floor_id WHERE rooms.room_id = $1`,
+	)).
+		WithArgs(roomId).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id", "name", "time_zone"}).AddRow(1, "Main office", "Asia/Dushanbe"))
+
+	// 2. Act
+	foundLocation, err := repo.GetLocationByRoomId(context.Background(), roomId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, foundLocation.LocationId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocationRepository_Update(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewLocationRepositoryPostgres(db)
+
+	locationToUpdate := models.Location{LocationId: 1, Name: "Renamed office"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "locations" SET "name"=$1,"updated_at"=$2 WHERE "location_id" = $3`,
+	)).
+		WithArgs(locationToUpdate.Name, AnyTimeArg(), locationToUpdate.LocationId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// 2. Act
+	updatedLocation, err := repo.Update(context.Background(), locationToUpdate)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed office", updatedLocation.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocationRepository_Delete(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewLocationRepositoryPostgres(db)
+
+	locationId := 3
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "locations" SET "active"=$1,"deleted_at"=$2 WHERE "active"=$3 AND "location_id" = $4`,
+	)).
+		WithArgs(false, AnyTimeArg(), true, locationId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// 2. Act
+	isDeleted, err := repo.Delete(context.Background(), locationId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.True(t, isDeleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocationRepository_Create_ForOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewLocationRepositoryPostgres(db).ForOrganization(organizationId)
+
+	locationToCreate := models.Location{
+		OrganizationId: 1, // organization of the caller is written instead
+		Name:           "Sister company office",
+		Address:        "Khujand",
+		TimeZone:       "Asia/Dushanbe",
+		CreatedBy:      5,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "locations" ("organization_id","name","address","time_zone","created_by","created_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "location_id"`,
+	)).
+		WithArgs(organizationId, locationToCreate.Name, locationToCreate.Address, locationToCreate.TimeZone, locationToCreate.CreatedBy, NotNullTimeArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id"}).AddRow(3))
+	mock.ExpectCommit()
+
+	// 2. Act
+	createdLocation, err := repo.Create(context.Background(), locationToCreate)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, createdLocation.LocationId)
+	assert.Equal(t, organizationId, createdLocation.OrganizationId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocationRepository_GetLocationById_ForOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewLocationRepositoryPostgres(db).ForOrganization(organizationId)
+
+	locationId := 1
+
+	// location of another organization without rooms shared with the organization - nothing is returned
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "locations" WHERE "location_id" = $1 AND ((locations.organization_id = $2 OR EXISTS (`,
+	)).
+		WithArgs(locationId, organizationId, organizationId).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id", "organization_id", "name"}))
+
+	// 2. Act
+	_, err := repo.GetLocationById(context.Background(), locationId)
+
+	// 3. Assert
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocationRepository_GetLocationByRoomId_SharedRoom(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewLocationRepositoryPostgres(db).ForOrganization(organizationId)
+
+	roomId := 1
+
+	// room of the host organization shared with the organization - its location (time zone) is found
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT locations.* FROM "locations" JOIN buildings ON buildings.location_id = locations.location_id JOIN floors ON floors.building_id = buildings.building_id JOIN rooms ON rooms.floor_id = floors.floor_id WHERE rooms.room_id = $1 AND ((rooms.organization_id = $2 OR EXISTS (`,
+	)).
+		WithArgs(roomId, organizationId, organizationId).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id", "organization_id", "name", "time_zone"}).AddRow(1, 1, "Main office", "Asia/Dushanbe"))
+
+	// 2. Act
+	foundLocation, err := repo.GetLocationByRoomId(context.Background(), roomId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, foundLocation.LocationId)
+	assert.Equal(t, "Asia/Dushanbe", foundLocation.TimeZone)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocationRepository_Update_ForOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewLocationRepositoryPostgres(db).ForOrganization(organizationId)
+
+	locationToUpdate := models.Location{LocationId: 1, Name: "Renamed office"}
+
+	// location holding shared rooms is visible, but not updated by the organization
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "locations" SET "name"=$1,"updated_at"=$2 WHERE locations.organization_id = $3 AND "location_id" = $4`,
+	)).
+		WithArgs(locationToUpdate.Name, AnyTimeArg(), organizationId, locationToUpdate.LocationId).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	// 2. Act
+	_, err := repo.Update(context.Background(), locationToUpdate)
+
+	// 3. Assert
+	assert.EqualError(t, err, "no Locations were updated")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
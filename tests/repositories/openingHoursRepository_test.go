@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+	"regexp"
+	"testing"
+)
+
+func TestOpeningHoursRepository_GetOpeningHoursByLocationId_ForOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewOpeningHoursRepositoryPostgres(db).ForOrganization(organizationId)
+
+	locationId := 1
+
+	// only locations of the organization and locations holding rooms shared with it
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "opening_hours" WHERE opening_hours.location_id IN (SELECT "location_id" FROM "locations" WHERE (locations.organization_id = $1 OR EXISTS (`,
+	)).
+		WithArgs(organizationId, organizationId, locationId).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id", "weekday", "opens_at", "closes_at"}).AddRow(locationId, 1, "08:00", "20:00"))
+
+	// 2. Act
+	openingHours, err := repo.GetOpeningHoursByLocationId(context.Background(), locationId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Len(t, openingHours, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpeningHoursRepository_ReplaceOpeningHours_LocationOfAnotherOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewOpeningHoursRepositoryPostgres(db).ForOrganization(organizationId)
+
+	locationId := 1
+	openingHours := []models.OpeningHours{{LocationId: locationId, Weekday: 6, OpensAt: "00:00", ClosesAt: "23:59"}}
+
+	// opening hours of the host organization's location are neither deleted nor created
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM "locations" WHERE locations.location_id = $1 AND locations.organization_id = $2`,
+	)).
+		WithArgs(locationId, organizationId).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectRollback()
+
+	// 2. Act
+	_, err := repo.ReplaceOpeningHours(context.Background(), locationId, openingHours)
+
+	// 3. Assert
+	assert.EqualError(t, err, "no Locations were found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+	"regexp"
+	"testing"
+)
+
+func TestPermissionRepository_GetPermissionsByRoleIdAndRouteId_ForOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewPermissionRepositoryPostgres(db).ForOrganization(organizationId)
+
+	roleId, routeId := 8, 2
+
+	// permissions of system roles and roles of the organization
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "permissions" WHERE permissions.role_id IN (SELECT "role_id" FROM "roles" WHERE (roles.organization_id = $1 OR roles.organization_id IS NULL)) AND (role_id = $2 AND route_id = $3 AND active = true)`,
+	)).
+		WithArgs(organizationId, roleId, routeId).
+		WillReturnRows(sqlmock.NewRows([]string{"role_id", "route_id", "scope_id"}))
+
+	// 2. Act
+	permissions, err := repo.GetPermissionsByRoleIdAndRouteId(context.Background(), roleId, routeId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Empty(t, permissions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPermissionRepository_Create_SystemRole(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewPermissionRepositoryPostgres(db).ForOrganization(organizationId)
+
+	// permissions of system roles are shared by all organizations - USER gets nothing from one of them
+	permissionToCreate := models.Permission{RoleId: 5, RouteId: 2, ScopeId: 1, CreatedBy: 5}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM "roles" WHERE roles.role_id = $1 AND roles.organization_id = $2`,
+	)).
+		WithArgs(permissionToCreate.RoleId, organizationId).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. Act
+	_, err := repo.Create(context.Background(), permissionToCreate)
+
+	// 3. Assert
+	assert.EqualError(t, err, "role or location of the permission belongs to another organization")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPermissionRepository_Create_LocationOfAnotherOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewPermissionRepositoryPostgres(db).ForOrganization(organizationId)
+
+	permissionToCreate := models.Permission{RoleId: 8, RouteId: 2, ScopeId: 1, LocationId: 1, CreatedBy: 5}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM "roles" WHERE roles.role_id = $1 AND roles.organization_id = $2`,
+	)).
+		WithArgs(permissionToCreate.RoleId, organizationId).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM "locations" WHERE locations.location_id = $1 AND locations.organization_id = $2`,
+	)).
+		WithArgs(permissionToCreate.LocationId, organizationId).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. Act
+	_, err := repo.Create(context.Background(), permissionToCreate)
+
+	// 3. Assert
+	assert.EqualError(t, err, "role or location of the permission belongs to another organization")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+	"testing"
+	"time"
+)
+
+func TestReportRepository_GetTopBookers_ForOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewReportRepositoryPostgres(db).ForOrganization(organizationId)
+
+	filter := models.ReportFilter{
+		DateTimeStart: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		DateTimeEnd:   time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		RoleId:        5,
+		Limit:         3,
+	}
+
+	rows := sqlmock.NewRows([]string{"user_id", "name", "email", "bookings", "booked_minutes"}).
+		AddRow(4, "John Doe", "john@example.com", 12, 840).
+		AddRow(7, "Jane Doe", "jane@example.com", 5, 300)
+
+	// named parameters are passed in order of their appearance in the query
+	mock.ExpectQuery(`(?s)WITH report_rooms AS .* ORDER BY booked_minutes DESC, bookings DESC, users.user_id\s+LIMIT \$11`).
+		WithArgs(0, 0, organizationId, organizationId, filter.DateTimeStart, filter.DateTimeEnd,
+			filter.RoleId, filter.RoleId, organizationId, organizationId, filter.Limit).
+		WillReturnRows(rows)
+
+	// 2. Act
+	topBookers, err := repo.GetTopBookers(context.Background(), filter)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Len(t, topBookers, 2)
+	assert.Equal(t, models.TopBooker{UserId: 4, Name: "John Doe", Email: "john@example.com", Bookings: 12, BookedMinutes: 840}, topBookers[0])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_GetUtilization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewReportRepositoryPostgres(db)
+
+	filter := models.ReportFilter{
+		DateTimeStart: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		DateTimeEnd:   time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC),
+		RoomId:        1,
+		Period:        models.ReportPeriodWeek,
+	}
+
+	rows := sqlmock.NewRows([]string{"room_id", "room_number", "period_start", "open_minutes", "booked_minutes"}).
+		AddRow(1, "101", "2025-02-24", 960, 240).
+		AddRow(1, "101", "2025-03-03", 2400, 600)
+
+	mock.ExpectQuery(`(?s)WITH report_rooms AS .*date_trunc\(\$\d+, open_intervals.day::timestamp\)`).
+		WillReturnRows(rows)
+
+	// 2. Act
+	utilization, err := repo.GetUtilization(context.Background(), filter)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Len(t, utilization, 2)
+	assert.Equal(t, "2025-03-03", utilization[1].PeriodStart)
+	assert.Equal(t, 2400, utilization[1].OpenMinutes)
+	assert.Equal(t, 600, utilization[1].BookedMinutes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
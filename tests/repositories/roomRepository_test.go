@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"go-booking-system/internal/database/repositories"
@@ -15,12 +16,14 @@ func TestRoomRepository_Create(t *testing.T) {
 	db, mock, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	repo := repositories.NewRoomRepositoryPostgres(db)
+	organizationId := 1
+	repo := repositories.NewRoomRepositoryPostgres(db).ForOrganization(organizationId)
 
 	newRoomId := 1
 	roomToCreate := models.Room{
 		Number:    "Briefing Room #1",
 		Capacity:  20,
+		FloorId:   1,
 		CreatedBy: 1,
 	}
 
@@ -29,14 +32,14 @@ func TestRoomRepository_Create(t *testing.T) {
 
 	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(
-		`INSERT INTO "rooms" ("number","capacity","created_by","created_at") VALUES ($1,$2,$3,$4)`,
+		`INSERT INTO "rooms" ("organization_id","number","capacity","floor_id","created_by","created_at") VALUES ($1,$2,$3,$4,$5,$6)`,
 	)).
-		WithArgs(roomToCreate.Number, roomToCreate.Capacity, roomToCreate.CreatedBy, NotNullTimeArg()).
+		WithArgs(organizationId, roomToCreate.Number, roomToCreate.Capacity, roomToCreate.FloorId, roomToCreate.CreatedBy, NotNullTimeArg()).
 		WillReturnRows(rows)
 	mock.ExpectCommit()
 
 	// 2. Act
-	createdRoom, err := repo.Create(roomToCreate)
+	createdRoom, err := repo.Create(context.Background(), roomToCreate)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -44,6 +47,7 @@ func TestRoomRepository_Create(t *testing.T) {
 	assert.Equal(t, newRoomId, createdRoom.RoomId)
 	assert.Equal(t, roomToCreate.Number, createdRoom.Number)
 	assert.Equal(t, roomToCreate.Capacity, createdRoom.Capacity)
+	assert.Equal(t, organizationId, createdRoom.OrganizationId)
 	assert.Equal(t, true, createdRoom.Active)
 	assert.Equal(t, false, createdRoom.CreatedAt.IsZero())
 }
@@ -74,7 +78,7 @@ func TestRoomRepository_GetAll(t *testing.T) {
 		WillReturnRows(rows)
 
 	// 2. Act
-	allRooms := repo.GetAll()
+	allRooms := repo.GetAll(context.Background())
 
 	// 3. Assert
 	assert.Equal(t, expectedRoom1.RoomId, allRooms[0].RoomId)
@@ -108,7 +112,7 @@ func TestRoomRepository_GetUserByID(t *testing.T) {
 		WillReturnRows(rows)
 
 	// 2. Act
-	actualRoom, err := repo.GetRoomById(expectedRoom.RoomId)
+	actualRoom, err := repo.GetRoomById(context.Background(), expectedRoom.RoomId)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -118,6 +122,76 @@ func TestRoomRepository_GetUserByID(t *testing.T) {
 	assert.Equal(t, expectedRoom.Active, actualRoom.Active)
 }
 
+func TestRoomRepository_GetRoomById_CancelledContext(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := repositories.NewRoomRepositoryPostgres(db)
+
+	// client disconnected - query is not sent to DB
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// 2. Act
+	_, err := repo.GetRoomById(ctx, 2)
+
+	// 3. Assert
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoomRepository_GetRoomById_ForOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewRoomRepositoryPostgres(db).ForOrganization(organizationId)
+
+	roomId := 1
+
+	// room of another organization which is not shared - nothing is returned
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "rooms" WHERE "room_id" = $1 AND ((rooms.organization_id = $2 OR EXISTS (`,
+	)).
+		WithArgs(roomId, organizationId, organizationId).
+		WillReturnRows(sqlmock.NewRows([]string{"room_id", "organization_id", "number"}))
+
+	// 2. Act
+	_, err := repo.GetRoomById(context.Background(), roomId)
+
+	// 3. Assert
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoomRepository_GetRoomByNumber_ForOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewRoomRepositoryPostgres(db).ForOrganization(organizationId)
+
+	number := "Conference Room #1"
+
+	// shared rooms are not matched - only rooms owned by the organization
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "rooms" WHERE "active"=$1 AND "number" = $2 AND rooms.organization_id = $3`,
+	)).
+		WithArgs(true, number, organizationId).
+		WillReturnRows(sqlmock.NewRows([]string{"room_id", "organization_id", "number"}).AddRow(3, organizationId, number))
+
+	// 2. Act
+	foundRoom, err := repo.GetRoomByNumber(context.Background(), number)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, foundRoom.RoomId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestRoomRepository_Update(t *testing.T) {
 	// 1. Assess
 	db, mock, cleanup := setupTestDB(t)
@@ -150,7 +224,7 @@ func TestRoomRepository_Update(t *testing.T) {
 	mock.ExpectCommit()
 
 	// 2. Act
-	updatedRoom, err := repo.Update(updateData)
+	updatedRoom, err := repo.Update(context.Background(), updateData)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -185,7 +259,7 @@ func TestRoomRepository_Delete(t *testing.T) {
 	mock.ExpectCommit()
 
 	// 2. Act
-	isDeleted, err := repo.Delete(roomId)
+	isDeleted, err := repo.Delete(context.Background(), roomId)
 
 	// 3. Assert
 	assert.NoError(t, err)
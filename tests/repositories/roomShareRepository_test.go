@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+	"regexp"
+	"testing"
+)
+
+func TestRoomShareRepository_Create_RoomOfAnotherOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewRoomShareRepositoryPostgres(db).ForOrganization(organizationId)
+
+	// room of the host organization is shared with the organization - it cannot share it further
+	roomShareToCreate := models.RoomShare{RoomId: 1, OrganizationId: 3, CreatedBy: 5}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT count(*) FROM "rooms" WHERE rooms.room_id = $1 AND rooms.organization_id = $2`,
+	)).
+		WithArgs(roomShareToCreate.RoomId, organizationId).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. Act
+	_, err := repo.Create(context.Background(), roomShareToCreate)
+
+	// 3. Assert
+	assert.EqualError(t, err, "room belongs to another organization")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoomShareRepository_GetRoomSharesByRoomId_ForOrganization(t *testing.T) {
+	// 1. Assess
+	db, mock, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	organizationId := 2
+	repo := repositories.NewRoomShareRepositoryPostgres(db).ForOrganization(organizationId)
+
+	roomId := 1
+
+	// shares with the organization and shares of its rooms
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "room_shares" WHERE ((room_shares.organization_id = $1 OR EXISTS (`,
+	)).
+		WithArgs(organizationId, organizationId, true, roomId).
+		WillReturnRows(sqlmock.NewRows([]string{"room_id", "organization_id", "quota_minutes", "active"}).AddRow(roomId, organizationId, 600, true))
+
+	// 2. Act
+	roomShares, err := repo.GetRoomSharesByRoomId(context.Background(), roomId)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Len(t, roomShares, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"go-booking-system/internal/database/repositories"
@@ -15,7 +16,8 @@ func TestUserRepository_Create(t *testing.T) {
 	db, mock, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	repo := repositories.NewUserRepositoryPostgres(db)
+	organizationId := 1
+	repo := repositories.NewUserRepositoryPostgres(db).ForOrganization(organizationId)
 
 	userToCreate := models.User{
 		Name:      "Ahmad",
@@ -32,14 +34,14 @@ func TestUserRepository_Create(t *testing.T) {
 	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(
 		// column order is defined by struct's fields order
-		`INSERT INTO "users" ("name","email","telephone","role_id","username","password_hash","active","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+		`INSERT INTO "users" ("organization_id","name","email","telephone","role_id","username","password_hash","sso_issuer","sso_subject","auth_provider","directory_id","email_verified","active","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
 	)).
-		WithArgs(userToCreate.Name, userToCreate.Email, userToCreate.Telephone, userToCreate.RoleId, userToCreate.UserName, userToCreate.Password, userToCreate.Active, NotNullTimeArg()).
+		WithArgs(organizationId, userToCreate.Name, userToCreate.Email, userToCreate.Telephone, userToCreate.RoleId, userToCreate.UserName, userToCreate.Password, "", "", "", "", false, userToCreate.Active, NotNullTimeArg()).
 		WillReturnRows(rows)
 	mock.ExpectCommit()
 
 	// 2. Act
-	updatedUser, err := repo.Create(userToCreate)
+	updatedUser, err := repo.Create(context.Background(), userToCreate)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -84,7 +86,7 @@ func TestUserRepository_GetAll(t *testing.T) {
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 		WillReturnRows(rows)
 
-	allUsers := repo.GetAll()
+	allUsers := repo.GetAll(context.Background())
 
 	assert.Equal(t, expectedUser1.UserId, allUsers[0].UserId)
 	assert.Equal(t, expectedUser1.Name, allUsers[0].Name)
@@ -125,7 +127,7 @@ func TestUserRepository_GetUserByID(t *testing.T) {
 		WillReturnRows(rows)
 
 	// 2. Act
-	user, err := repo.GetUserById(1)
+	user, err := repo.GetUserById(context.Background(), 1)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -181,7 +183,7 @@ func TestUserRepository_Update(t *testing.T) {
 	mock.ExpectCommit()
 
 	// 2. Act
-	updatedUser, err := repo.Update(updateData)
+	updatedUser, err := repo.Update(context.Background(), updateData)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -226,7 +228,7 @@ func TestUserRepository_Delete(t *testing.T) {
 	mock.ExpectCommit()
 
 	// 2. Act
-	isDeleted, err := repo.Delete(userId)
+	isDeleted, err := repo.Delete(context.Background(), userId)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -256,7 +258,7 @@ func TestBookingRepository_UpdateUserPassword(t *testing.T) {
 	mock.ExpectCommit()
 
 	// 2. Act
-	updatedUser, err := repo.UpdatePassword(updateData)
+	updatedUser, err := repo.UpdatePassword(context.Background(), updateData)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -289,7 +291,7 @@ func TestBookingRepository_UpdateUserName(t *testing.T) {
 	mock.ExpectCommit()
 
 	// 2. Act
-	updatedUser, err := repo.UpdateUsername(updateData)
+	updatedUser, err := repo.UpdateUsername(context.Background(), updateData)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -322,7 +324,7 @@ func TestBookingRepository_UpdateUserRole(t *testing.T) {
 	mock.ExpectCommit()
 
 	// 2. Act
-	updatedUser, err := repo.UpdateUserRole(updateData)
+	updatedUser, err := repo.UpdateUserRole(context.Background(), updateData)
 
 	// 3. Assert
 	assert.NoError(t, err)
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/server"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func freeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find free port: %v", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+func TestServer_Shutdown_DrainsRequestsAndStopsWorkers(t *testing.T) {
+	// 1. Assess
+	address := freeAddress(t)
+	myServer := server.NewServer(server.Config{Address: address, ShutdownTimeout: 5 * time.Second})
+
+	requestStarted := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requestStarted)
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	workerStopped := false
+	myServer.RunWorker("test", func(ctx context.Context) {
+		<-ctx.Done()
+		workerStopped = true
+	})
+
+	serverErrors := make(chan error, 1)
+	go func() { serverErrors <- myServer.ServerRun(handler) }()
+	assert.Eventually(t, func() bool {
+		connection, err := net.Dial("tcp", address)
+		if err == nil {
+			connection.Close()
+		}
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	responses := make(chan int, 1)
+	go func() {
+		response, err := http.Get("http://" + address + "/")
+		if err != nil {
+			responses <- 0
+			return
+		}
+		response.Body.Close()
+		responses <- response.StatusCode
+	}()
+	<-requestStarted
+
+	// 2. Act
+	shutdownError := myServer.Shutdown()
+
+	// 3. Assert
+	assert.NoError(t, shutdownError)
+	assert.NoError(t, <-serverErrors)
+	assert.Equal(t, http.StatusOK, <-responses)
+	assert.True(t, workerStopped)
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/notify"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// capturedArg matches any value and keeps it - the value is checked after the call
+type capturedArg struct {
+	value driver.Value
+}
+
+func (c *capturedArg) Match(value driver.Value) bool {
+	c.value = value
+	return true
+}
+
+func newAccountService(t *testing.T) (*services.AccountService, *notify.MemoryNotifier, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	assert.NoError(t, err)
+
+	config := services.AuthConfig{PasswordSalt: "salt", PasswordResetTTL: time.Hour, EmailVerificationTTL: 48 * time.Hour, PublicURL: "https://booking.example.com/"}
+	userRepository := repositories.NewUserRepositoryPostgres(gormDB)
+	authService := services.NewAuthService(config, userRepository, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	notifier := notify.NewMemoryNotifier()
+
+	return services.NewAccountService(config, userRepository, repositories.NewUserTokenRepositoryPostgres(gormDB), authService, notifier), notifier, mock
+}
+
+func TestAccountService_ForgotPassword(t *testing.T) {
+	// 1. Assess
+	accountService, notifier, mock := newAccountService(t)
+	ctx := context.Background()
+	tokenHash := &capturedArg{}
+
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "email", "auth_provider", "active"}).
+		AddRow(1, "Ahmad", "ahmad@example.com", services.AuthProviderLocal, true))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "user_tokens"`).WithArgs(1, models.UserTokenPasswordReset).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "user_tokens"`).WithArgs(1, models.UserTokenPasswordReset, tokenHash, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"user_token_id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	// 2. Act
+	unknownError := accountService.ForgotPassword(ctx, "nobody@example.com")
+	err := accountService.ForgotPassword(ctx, "ahmad@example.com")
+
+	// 3. Assert
+	assert.NoError(t, unknownError)
+	assert.NoError(t, err)
+	assert.Len(t, notifier.Messages(), 1)
+	message, ok := notifier.LastMessage("ahmad@example.com")
+	assert.True(t, ok)
+
+	start := strings.Index(message.Body, "https://booking.example.com/reset-password?token=")
+	assert.GreaterOrEqual(t, start, 0)
+	link, err := url.Parse(strings.Fields(message.Body[start:])[0])
+	assert.NoError(t, err)
+	token := link.Query().Get("token")
+	assert.NotEmpty(t, token)
+	// only hash of the secret is stored
+	assert.Equal(t, pkg.HashToken(token), tokenHash.value)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountService_VerifyEmail_InvalidToken(t *testing.T) {
+	// 1. Assess
+	accountService, _, mock := newAccountService(t)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "user_tokens"`).
+		WithArgs(models.UserTokenEmailVerification, pkg.HashToken("expired-or-used"), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"user_token_id"}))
+	mock.ExpectCommit()
+
+	// 2. Act
+	_, emptyError := accountService.VerifyEmail(ctx, "")
+	_, err := accountService.VerifyEmail(ctx, "expired-or-used")
+
+	// 3. Assert
+	assert.EqualError(t, emptyError, services.InvalidUserTokenError)
+	assert.EqualError(t, err, services.InvalidUserTokenError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountService_ChangePassword_RequiresCurrentPassword(t *testing.T) {
+	// 1. Assess
+	accountService, _, mock := newAccountService(t)
+	ctx := context.Background()
+	passwordHash := services.NewAuthService(services.AuthConfig{PasswordSalt: "salt"}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+		GeneratePasswordHash(ctx, "current-password")
+	userRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"user_id", "username", "password_hash", "role_id", "auth_provider", "email_verified", "active"}).
+			AddRow(1, "ahmad.tee", passwordHash, 5, services.AuthProviderLocal, true, true)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(userRows())
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(userRows())
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(userRows())
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(userRows())
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(userRows())
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "users" SET "password_hash"=\$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// 2. Act
+	wrongPasswordError := accountService.ChangePassword(ctx, 1, "guessed-password", "new-password")
+	err := accountService.ChangePassword(ctx, 1, "current-password", "new-password")
+
+	// 3. Assert
+	assert.EqualError(t, wrongPasswordError, services.CurrentPasswordError)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserService_Update_RejectsRoleChange(t *testing.T) {
+	// 1. Assess
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	assert.NoError(t, err)
+	userService := services.NewUserService(repositories.NewUserRepositoryPostgres(gormDB))
+
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "role_id"}).AddRow(1, 5))
+
+	// 2. Act
+	_, err = userService.Update(context.Background(), models.User{UserId: 1, Name: "Ahmad", RoleId: 1})
+
+	// 3. Assert
+	assert.EqualError(t, err, services.RoleChangeError)
+	// nothing is updated
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthService_ChangeRole_OwnRole(t *testing.T) {
+	// 1. Assess
+	authService := services.NewAuthService(services.AuthConfig{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	// 2. Act
+	_, err := authService.ChangeRole(context.Background(), 1, 1, 5)
+
+	// 3. Assert
+	assert.EqualError(t, err, services.OwnRoleChangeError)
+}
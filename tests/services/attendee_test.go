@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/services"
+	"testing"
+)
+
+func TestAttendeeService_IsAttendee(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	attendeeService := services.NewAttendeeService(repositories.NewAttendeeRepositoryPostgres(gormDB), nil, nil)
+	ctx := context.Background()
+	mock.ExpectQuery(`SELECT \* FROM "attendees"`).WithArgs(true, 4, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"attendee_id", "booking_id", "user_id"}).AddRow(1, 4, 3))
+	mock.ExpectQuery(`SELECT \* FROM "attendees"`).WithArgs(true, 4, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"attendee_id", "booking_id", "user_id"}))
+	mock.ExpectQuery(`SELECT \* FROM "attendees"`).WithArgs(true, 4, 6).
+		WillReturnError(errors.New("connection refused"))
+
+	// 2. Act
+	isInvited, invitedError := attendeeService.IsAttendee(ctx, 4, 3)
+	isNotInvited, notInvitedError := attendeeService.IsAttendee(ctx, 4, 5)
+	isFailed, failedError := attendeeService.IsAttendee(ctx, 4, 6)
+
+	// 3. Assert
+	assert.NoError(t, invitedError)
+	assert.True(t, isInvited)
+	assert.NoError(t, notInvitedError)
+	assert.False(t, isNotInvited)
+	// access is not decided without knowing if the user is invited
+	assert.EqualError(t, failedError, "connection refused")
+	assert.False(t, isFailed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/services"
+	"testing"
+)
+
+func TestAuthService_CheckPermissions_PermissionOfLocation(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	authService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).AuthService
+	ctx := context.Background()
+	// the role may update floors of location 6 only
+	expectPermission := func() {
+		mock.ExpectQuery(`SELECT \* FROM "routes"`).WillReturnRows(sqlmock.NewRows([]string{"route_id", "url"}).AddRow(1, "/floor/update"))
+		mock.ExpectQuery(`SELECT \* FROM "permissions"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "route_id", "scope_id", "location_id"}).AddRow(4, 1, services.AllScopeId, 6))
+		mock.ExpectQuery(`SELECT \* FROM "policies"`).WillReturnRows(sqlmock.NewRows([]string{"policy_id"}))
+	}
+	expectPermissionOfLocation := func(floorId int, locationId int) {
+		expectPermission()
+		mock.ExpectQuery(`SELECT \* FROM "floors"`).WillReturnRows(sqlmock.NewRows([]string{"floor_id", "building_id"}).AddRow(floorId, 4))
+		mock.ExpectQuery(`SELECT \* FROM "buildings"`).WillReturnRows(sqlmock.NewRows([]string{"building_id", "location_id"}).AddRow(4, locationId))
+	}
+	expectPermissionOfLocation(2, 6)
+	expectPermissionOfLocation(3, 7)
+	expectPermission()
+
+	// 2. Act
+	insideGranted, insideError := authService.CheckPermissions(ctx, "/floor/update", "floor", "2", "3", "4", services.RequestAttributes{})
+	outsideGranted, outsideError := authService.CheckPermissions(ctx, "/floor/update", "floor", "3", "3", "4", services.RequestAttributes{})
+	listGranted, listError := authService.CheckPermissions(ctx, "/floor/update", "floor", "", "3", "4", services.RequestAttributes{})
+
+	// 3. Assert
+	assert.NoError(t, insideError)
+	assert.True(t, insideGranted)
+	assert.NoError(t, outsideError)
+	assert.False(t, outsideGranted)
+	// records without id are out of any location
+	assert.NoError(t, listError)
+	assert.False(t, listGranted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/services"
+	"testing"
+	"time"
+)
+
+var bookingColumns = []string{"booking_id", "user_id", "room_id", "datetime_start", "datetime_end", "checked_in_at", "active"}
+
+func TestBookingService_GetCurrentAndNextBookings(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	bookingService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).BookingService
+	ctx := context.Background()
+	at := time.Date(2025, 4, 3, 16, 40, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT \* FROM "bookings" WHERE room_id = \$1 AND active = true AND datetime_end > \$2 ORDER BY datetime_start LIMIT \$3`).
+		WithArgs(4, at, 2).
+		WillReturnRows(sqlmock.NewRows(bookingColumns).
+			AddRow(7, 2, 4, at.Add(-10*time.Minute), at.Add(20*time.Minute), nil, true).
+			AddRow(8, 3, 4, at.Add(time.Hour), at.Add(2*time.Hour), nil, true))
+	// room is free: next booking only
+	mock.ExpectQuery(`SELECT \* FROM "bookings"`).
+		WillReturnRows(sqlmock.NewRows(bookingColumns).
+			AddRow(8, 3, 4, at.Add(time.Hour), at.Add(2*time.Hour), nil, true))
+
+	// 2. Act
+	current, next, err := bookingService.GetCurrentAndNextBookings(ctx, 4, at)
+	freeCurrent, freeNext, freeError := bookingService.GetCurrentAndNextBookings(ctx, 4, at)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 7, current.BookingId)
+	assert.Equal(t, 8, next.BookingId)
+	assert.NoError(t, freeError)
+	assert.Nil(t, freeCurrent)
+	assert.Equal(t, 8, freeNext.BookingId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingService_CheckIn(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	bookingService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).BookingService
+	ctx := context.Background()
+	at := time.Date(2025, 4, 3, 16, 25, 0, 0, time.UTC)
+	// the booking starts within CheckInEarlyWindow
+	mock.ExpectQuery(`SELECT \* FROM "bookings"`).
+		WillReturnRows(sqlmock.NewRows(bookingColumns).
+			AddRow(8, 3, 4, at.Add(5*time.Minute), at.Add(time.Hour), nil, true))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "bookings" SET "checked_in_at"=\$1`).
+		WithArgs(at, sqlmock.AnyArg(), true, 8).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT \* FROM "bookings" WHERE "booking_id" = \$1`).
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows(bookingColumns).
+			AddRow(8, 3, 4, at.Add(5*time.Minute), at.Add(time.Hour), at, true))
+
+	// 2. Act
+	checkedInBooking, err := bookingService.CheckIn(ctx, 4, at)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 8, checkedInBooking.BookingId)
+	assert.Equal(t, at, checkedInBooking.CheckedInAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingService_CheckIn_OutsideOfTimeWindow(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	bookingService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).BookingService
+	ctx := context.Background()
+	at := time.Date(2025, 4, 3, 16, 0, 0, 0, time.UTC)
+	// the next booking starts later than CheckInEarlyWindow
+	mock.ExpectQuery(`SELECT \* FROM "bookings"`).
+		WillReturnRows(sqlmock.NewRows(bookingColumns).
+			AddRow(8, 3, 4, at.Add(services.CheckInEarlyWindow+time.Minute), at.Add(time.Hour), nil, true))
+	// the room has no bookings at all
+	mock.ExpectQuery(`SELECT \* FROM "bookings"`).
+		WillReturnRows(sqlmock.NewRows(bookingColumns))
+
+	// 2. Act
+	_, tooEarlyError := bookingService.CheckIn(ctx, 4, at)
+	_, noBookingError := bookingService.CheckIn(ctx, 4, at)
+
+	// 3. Assert
+	assert.ErrorContains(t, tooEarlyError, "no booking to check in")
+	assert.ErrorContains(t, noBookingError, "no booking to check in")
+	// nothing is updated
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingService_CheckIn_AlreadyCheckedIn(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	bookingService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).BookingService
+	ctx := context.Background()
+	at := time.Date(2025, 4, 3, 16, 25, 0, 0, time.UTC)
+	checkedInAt := at.Add(-15 * time.Minute)
+	mock.ExpectQuery(`SELECT \* FROM "bookings"`).
+		WillReturnRows(sqlmock.NewRows(bookingColumns).
+			AddRow(7, 2, 4, at.Add(-20*time.Minute), at.Add(10*time.Minute), checkedInAt, true))
+
+	// 2. Act
+	checkedInBooking, err := bookingService.CheckIn(ctx, 4, at)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	// time of the first check-in is kept
+	assert.Equal(t, checkedInAt, checkedInBooking.CheckedInAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
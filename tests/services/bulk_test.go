@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/services"
+	"testing"
+)
+
+func TestBulkService_ImportUser_Create(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	bulkService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).BulkService
+	ctx := context.Background()
+	importedUser := models.User{Name: "John Doe", Email: "john.doe@example.com", RoleId: 5, UserName: "john.doe", Password: "Secret-password-1"}
+	expectNewUser := func() {
+		mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mock.ExpectQuery(`SELECT \* FROM "roles"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "name"}).AddRow(5, "User"))
+	}
+	// dry run - nothing is written
+	expectNewUser()
+	expectNewUser()
+	mock.ExpectQuery(`SELECT \* FROM "roles"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "name"}).AddRow(5, "User"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(11))
+	mock.ExpectCommit()
+
+	// 2. Act
+	dryRunAction, _, dryRunError := bulkService.ImportUser(ctx, importedUser, 7, true)
+	action, createdUser, err := bulkService.ImportUser(ctx, importedUser, 7, false)
+
+	// 3. Assert
+	assert.NoError(t, dryRunError)
+	assert.Equal(t, models.ImportActionCreate, dryRunAction)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImportActionCreate, action)
+	assert.Equal(t, 11, createdUser.UserId)
+	// imported by administrator - email is trusted, password is hashed
+	assert.True(t, createdUser.EmailVerified)
+	assert.NotEqual(t, importedUser.Password, createdUser.Password)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkService_ImportUser_CreateWithRoleOfAnotherOrganization(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	bulkService := services.NewService(database.NewDatabase(gormDB).ForOrganization(2), services.AuthConfig{}, nil).BulkService
+	ctx := context.Background()
+	// role 9 belongs to another organization
+	importedUser := models.User{Name: "John Doe", Email: "john.doe@example.com", RoleId: 9, UserName: "john.doe", Password: "Secret-password-1"}
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectQuery(`SELECT \* FROM "roles"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "name"}))
+
+	// 2. Act
+	action, _, err := bulkService.ImportUser(ctx, importedUser, 7, false)
+
+	// 3. Assert
+	assert.Error(t, err)
+	assert.Equal(t, models.ImportActionError, action)
+	// nothing is written
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkService_ImportUser_CreateWithRoleByNotAdministrator(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	bulkService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).BulkService
+	ctx := context.Background()
+	// HR (user 7) imports new user with role of Super Admin
+	importedUser := models.User{Name: "John Doe", Email: "john.doe@example.com", RoleId: 1, UserName: "john.doe", Password: "Secret-password-1"}
+	expectNewUser := func() {
+		mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mock.ExpectQuery(`SELECT \* FROM "roles"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "name"}).AddRow(1, "Super Admin"))
+	}
+	expectNotAdministrator := func() {
+		mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "role_id", "active"}).AddRow(7, 3, true))
+		mock.ExpectQuery(`SELECT \* FROM "routes"`).WillReturnRows(sqlmock.NewRows([]string{"route_id", "url"}).AddRow(80, services.RoleChangeRoute))
+		mock.ExpectQuery(`SELECT \* FROM "permissions"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "route_id", "scope_id"}))
+	}
+	// dry run
+	expectNewUser()
+	expectNotAdministrator()
+	// import - role is checked by creation
+	expectNewUser()
+	mock.ExpectQuery(`SELECT \* FROM "roles"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "name"}).AddRow(1, "Super Admin"))
+	expectNotAdministrator()
+
+	// 2. Act
+	dryRunAction, _, dryRunError := bulkService.ImportUser(ctx, importedUser, 7, true)
+	action, _, err := bulkService.ImportUser(ctx, importedUser, 7, false)
+
+	// 3. Assert
+	assert.EqualError(t, dryRunError, services.RoleChangeForbiddenError)
+	assert.Equal(t, models.ImportActionError, dryRunAction)
+	assert.EqualError(t, err, services.RoleChangeForbiddenError)
+	assert.Equal(t, models.ImportActionError, action)
+	// user is not created
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkService_ImportUser_Update(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	bulkService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).BulkService
+	ctx := context.Background()
+	// the row has the same role - only contacts are updated
+	importedUser := models.User{Name: "John Doe", Email: "john.doe@example.com", Telephone: "+992000000000", RoleId: 5}
+	expectExistingUser := func() {
+		mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "email", "username", "role_id", "active"}).AddRow(9, "john.doe@example.com", "john.doe", 5, true))
+	}
+	// dry run - nothing is written
+	expectExistingUser()
+	expectExistingUser()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "users" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// 2. Act
+	dryRunAction, dryRunUser, dryRunError := bulkService.ImportUser(ctx, importedUser, 7, true)
+	action, updatedUser, err := bulkService.ImportUser(ctx, importedUser, 7, false)
+
+	// 3. Assert
+	assert.NoError(t, dryRunError)
+	assert.Equal(t, models.ImportActionUpdate, dryRunAction)
+	assert.Equal(t, 9, dryRunUser.UserId)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImportActionUpdate, action)
+	assert.Equal(t, 9, updatedUser.UserId)
+	assert.Equal(t, 5, updatedUser.RoleId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkService_ImportUser_InvalidRow(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	bulkService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).BulkService
+	ctx := context.Background()
+	// unknown role
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectQuery(`SELECT \* FROM "roles"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "name"}))
+	// new user without username and password
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectQuery(`SELECT \* FROM "roles"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "name"}).AddRow(5, "User"))
+	// email and username of different users
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "email", "role_id"}).AddRow(9, "john.doe@example.com", 5))
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "role_id"}).AddRow(10, "jane.doe", 5))
+
+	// 2. Act
+	unknownRoleAction, _, unknownRoleError := bulkService.ImportUser(ctx, models.User{Email: "john.doe@example.com", RoleId: 42}, 7, true)
+	noPasswordAction, _, noPasswordError := bulkService.ImportUser(ctx, models.User{Email: "john.doe@example.com", RoleId: 5}, 7, true)
+	differentUsersAction, _, differentUsersError := bulkService.ImportUser(ctx, models.User{Email: "john.doe@example.com", UserName: "jane.doe", RoleId: 5}, 7, true)
+
+	// 3. Assert
+	assert.Error(t, unknownRoleError)
+	assert.Equal(t, models.ImportActionError, unknownRoleAction)
+	assert.EqualError(t, noPasswordError, "username and password are required to create a user")
+	assert.Equal(t, models.ImportActionError, noPasswordAction)
+	assert.EqualError(t, differentUsersError, "email and username belong to different users")
+	assert.Equal(t, models.ImportActionError, differentUsersAction)
+	// nothing is written
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkService_ImportUser_RoleChangeByNotAdministrator(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	bulkService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).BulkService
+	ctx := context.Background()
+	// HR (user 7) imports row of user 9 with role of Super Admin
+	importedUser := models.User{Name: "John Doe", Email: "john.doe@example.com", RoleId: 1}
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "email", "role_id", "active"}).AddRow(9, "john.doe@example.com", 5, true))
+	mock.ExpectQuery(`SELECT \* FROM "roles"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "name"}).AddRow(1, "Super Admin"))
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "role_id", "active"}).AddRow(7, 3, true))
+	mock.ExpectQuery(`SELECT \* FROM "routes"`).WillReturnRows(sqlmock.NewRows([]string{"route_id", "url"}).AddRow(80, services.RoleChangeRoute))
+	mock.ExpectQuery(`SELECT \* FROM "permissions"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "route_id", "scope_id"}))
+
+	// 2. Act
+	action, _, err := bulkService.ImportUser(ctx, importedUser, 7, false)
+
+	// 3. Assert
+	assert.EqualError(t, err, services.RoleChangeForbiddenError)
+	assert.Equal(t, models.ImportActionError, action)
+	// user is not updated
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkService_ImportUser_OwnRoleChange(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	bulkService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).BulkService
+	ctx := context.Background()
+	importedUser := models.User{Name: "Jane Doe", Email: "jane.doe@example.com", RoleId: 1}
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "email", "role_id", "active"}).AddRow(7, "jane.doe@example.com", 3, true))
+	mock.ExpectQuery(`SELECT \* FROM "roles"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "name"}).AddRow(1, "Super Admin"))
+
+	// 2. Act
+	action, _, err := bulkService.ImportUser(ctx, importedUser, 7, true)
+
+	// 3. Assert
+	assert.EqualError(t, err, services.OwnRoleChangeError)
+	assert.Equal(t, models.ImportActionError, action)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
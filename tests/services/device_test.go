@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"testing"
+	"time"
+)
+
+func TestDeviceService_Authenticate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		token         string
+		active        bool
+		expectQuery   bool
+		expectedError string
+	}{
+		{"valid token", "5.secret", true, true, ""},
+		{"wrong secret", "5.other-secret", true, true, "invalid device token"},
+		{"revoked device", "5.secret", false, true, "invalid device token"},
+		{"no secret", "5.", true, false, "wrong device token format"},
+		{"no separator", "5secret", true, false, "wrong device token format"},
+		{"device id is not a number", "five.secret", true, false, "wrong device token format"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// 1. Assess
+			gormDB, mock := setupTestDB(t)
+			deviceService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).DeviceService
+			ctx := context.Background()
+			if testCase.expectQuery {
+				mock.ExpectQuery(`SELECT \* FROM "devices"`).
+					WillReturnRows(sqlmock.NewRows([]string{"device_id", "room_id", "token_hash", "active"}).AddRow(5, 4, pkg.HashToken("secret"), testCase.active))
+			}
+
+			// 2. Act
+			device, err := deviceService.Authenticate(ctx, testCase.token)
+
+			// 3. Assert
+			if testCase.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, 4, device.RoomId)
+			} else {
+				assert.EqualError(t, err, testCase.expectedError)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeviceService_BookNow_ShortenedByNextBooking(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	deviceService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).DeviceService
+	ctx := context.Background()
+	device := models.Device{DeviceId: 5, RoomId: 4, CreatedBy: 2}
+	at := time.Date(2025, 4, 3, 16, 0, 30, 0, time.UTC)
+	nextStart := time.Date(2025, 4, 3, 16, 20, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT \* FROM "bookings"`).
+		WillReturnRows(sqlmock.NewRows(bookingColumns).AddRow(8, 3, 4, nextStart, nextStart.Add(time.Hour), nil, true))
+	// room has no location - it is always open
+	mock.ExpectQuery(`SELECT locations\.\* FROM "locations"`).WillReturnRows(sqlmock.NewRows([]string{"location_id"}))
+	mock.ExpectQuery(`SELECT \* FROM "bookings"`).WillReturnRows(sqlmock.NewRows(bookingColumns))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "bookings"`).WillReturnRows(sqlmock.NewRows([]string{"booking_id"}).AddRow(9))
+	mock.ExpectCommit()
+
+	// 2. Act
+	createdBooking, err := deviceService.BookNow(ctx, device, 0, at)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 9, createdBooking.BookingId)
+	assert.Equal(t, 2, createdBooking.UserId)
+	assert.Equal(t, at.Truncate(time.Minute), createdBooking.DateTimeStart)
+	// default period is cut at the start of the next booking
+	assert.Equal(t, nextStart, createdBooking.DateTimeEnd)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeviceService_BookNow_RoomIsNotAvailable(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	deviceService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).DeviceService
+	ctx := context.Background()
+	device := models.Device{DeviceId: 5, RoomId: 4, CreatedBy: 2}
+	at := time.Date(2025, 4, 3, 16, 0, 0, 0, time.UTC)
+	// the room is occupied
+	mock.ExpectQuery(`SELECT \* FROM "bookings"`).
+		WillReturnRows(sqlmock.NewRows(bookingColumns).AddRow(7, 3, 4, at.Add(-time.Hour), at.Add(time.Hour), nil, true))
+	// the room is free for less than MinBookNowPeriod
+	mock.ExpectQuery(`SELECT \* FROM "bookings"`).
+		WillReturnRows(sqlmock.NewRows(bookingColumns).AddRow(8, 3, 4, at.Add(5*time.Minute), at.Add(time.Hour), nil, true))
+
+	// 2. Act
+	_, occupiedError := deviceService.BookNow(ctx, device, 0, at)
+	_, tooShortError := deviceService.BookNow(ctx, device, 0, at)
+	_, shortPeriodError := deviceService.BookNow(ctx, device, 5*time.Minute, at)
+
+	// 3. Assert
+	var overlappingBookingsError *services.OverlappingBookingsError
+	assert.True(t, errors.As(occupiedError, &overlappingBookingsError))
+	assert.Equal(t, 7, overlappingBookingsError.OverlappingBookings[0].BookingId)
+	assert.True(t, errors.As(tooShortError, &overlappingBookingsError))
+	assert.Equal(t, 8, overlappingBookingsError.OverlappingBookings[0].BookingId)
+	assert.ErrorContains(t, shortPeriodError, "booking period should not be less than")
+	// no booking is created
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
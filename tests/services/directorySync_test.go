@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/directory"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/oidc"
+	"go-booking-system/internal/services"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"testing"
+	"time"
+)
+
+func TestPlanDirectorySync(t *testing.T) {
+	// 1. Assess
+	groupRoles := []oidc.GroupRole{{Group: "Booking Admins", RoleId: 1}, {Group: "Staff", RoleId: 5}}
+	entries := []directory.Entry{
+		{Id: "guid-new", Username: "new.user", Email: "new.user@example.com", Groups: []string{"Staff"}},
+		{Id: "guid-no-email", Username: "no.email", Groups: []string{"Staff"}},
+		{Id: "guid-promoted", Username: "promoted", Name: "Promoted", Email: "promoted@example.com", Groups: []string{"Staff", "Booking Admins"}},
+		{Id: "guid-disabled", Username: "disabled", Email: "disabled@example.com", Groups: []string{"Staff"}, Disabled: true},
+		{Id: "guid-returned", Username: "returned", Name: "Returned", Email: "returned@example.com", Groups: []string{"Staff"}},
+		{Id: "guid-unchanged", Username: "unchanged", Name: "Unchanged", Email: "unchanged@example.com", Groups: []string{"Staff"}},
+		{Id: "guid-contractor", Username: "contractor", Email: "contractor@example.com", Groups: []string{"Contractors"}},
+	}
+	users := []models.User{
+		{UserId: 1, DirectoryId: "guid-promoted", UserName: "promoted", Name: "Promoted", Email: "promoted@example.com", RoleId: 5, Active: true},
+		{UserId: 2, DirectoryId: "guid-disabled", UserName: "disabled", Name: "disabled", Email: "disabled@example.com", RoleId: 5, Active: true},
+		{UserId: 3, DirectoryId: "guid-returned", UserName: "returned", Name: "Returned", Email: "returned@example.com", RoleId: 5, Active: false},
+		{UserId: 4, DirectoryId: "guid-unchanged", UserName: "unchanged", Name: "Unchanged", Email: "unchanged@example.com", RoleId: 5, Active: true},
+		{UserId: 5, DirectoryId: "guid-contractor", UserName: "contractor", Name: "contractor", Email: "contractor@example.com", RoleId: 5, Active: true},
+		{UserId: 6, DirectoryId: "guid-removed", UserName: "removed", RoleId: 5, Active: true},
+		{UserId: 7, DirectoryId: "guid-removed-before", UserName: "removed.before", RoleId: 5, Active: false},
+	}
+
+	// 2. Act
+	changes := services.PlanDirectorySync(entries, users, groupRoles, 0)
+
+	// 3. Assert
+	actions := make(map[string]string)
+	for _, change := range changes {
+		key := change.Entry.Id
+		if key == "" {
+			key = change.User.DirectoryId
+		}
+		actions[key] = change.Action
+	}
+	assert.Equal(t, map[string]string{
+		"guid-new":        models.DirectorySyncCreate,
+		"guid-no-email":   models.DirectorySyncSkip,
+		"guid-promoted":   models.DirectorySyncUpdate,
+		"guid-disabled":   models.DirectorySyncDeactivate,
+		"guid-returned":   models.DirectorySyncReactivate,
+		"guid-contractor": models.DirectorySyncDeactivate, // no mapped group and no default role
+		"guid-removed":    models.DirectorySyncDeactivate,
+	}, actions)
+	assert.Equal(t, 1, changes[2].RoleId)
+}
+
+func TestPlanDirectorySync_DefaultRole(t *testing.T) {
+	// 1. Assess
+	entries := []directory.Entry{{Id: "guid-contractor", Username: "contractor", Email: "contractor@example.com", Groups: []string{"Contractors"}}}
+
+	// 2. Act
+	changes := services.PlanDirectorySync(entries, nil, []oidc.GroupRole{{Group: "Staff", RoleId: 5}}, 4)
+
+	// 3. Assert
+	assert.Len(t, changes, 1)
+	assert.Equal(t, models.DirectorySyncCreate, changes[0].Action)
+	assert.Equal(t, 4, changes[0].RoleId)
+}
+
+// newLDAPAuthService returns AuthService whose users are read from sqlmock and LDAP users are authenticated by test server
+func newLDAPAuthService(t *testing.T) (*services.AuthService, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	assert.NoError(t, err)
+
+	server, err := directory.NewTestServer([]directory.TestEntry{
+		{DN: "cn=sync,dc=example,dc=com", Password: "sync-password"},
+		{DN: "uid=jane.doe,ou=people,dc=example,dc=com", Password: "directory-password", Attributes: map[string][]string{
+			"entryUUID": {"uuid-jane"},
+			"uid":       {"jane.doe"},
+		}},
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+	client := directory.NewClient(directory.Config{URL: server.URL(), BindDN: "cn=sync,dc=example,dc=com", BindPassword: "sync-password",
+		BaseDN: "ou=people,dc=example,dc=com", IdAttribute: "entryUUID", UsernameAttribute: "uid", Timeout: 5 * time.Second, PageSize: 100})
+
+	authService := services.NewAuthService(services.AuthConfig{PasswordSalt: "salt"}, repositories.NewUserRepositoryPostgres(gormDB),
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	authService.RegisterAuthenticationProvider(services.AuthProviderLDAP, services.NewLDAPAuthenticationProvider(client))
+
+	return authService, mock
+}
+
+func TestAuthService_CheckPassword_DelegatesToLDAP(t *testing.T) {
+	// 1. Assess
+	authService, mock := newLDAPAuthService(t)
+	ctx := context.Background()
+	userRows := func(directoryId string, active bool) *sqlmock.Rows {
+		// local password hash of directory user is never checked
+		return sqlmock.NewRows([]string{"user_id", "username", "password_hash", "role_id", "auth_provider", "directory_id", "active"}).
+			AddRow(1, "jane.doe", authService.GeneratePasswordHash(ctx, "local-password"), 5, services.AuthProviderLDAP, directoryId, active)
+	}
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(userRows("uuid-jane", true))
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(userRows("uuid-jane", true))
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(userRows("uuid-jane", true))
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(userRows("uuid-jane", false))
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(userRows("uuid-another-entry", true))
+
+	// 2. Act
+	user, err := authService.CheckIfUserExistsAndPasswordIsCorrect(ctx, "jane.doe", "directory-password")
+	_, wrongPasswordError := authService.CheckIfUserExistsAndPasswordIsCorrect(ctx, "jane.doe", "wrong-password")
+	_, localPasswordError := authService.CheckIfUserExistsAndPasswordIsCorrect(ctx, "jane.doe", "local-password")
+	_, inactiveError := authService.CheckIfUserExistsAndPasswordIsCorrect(ctx, "jane.doe", "directory-password")
+	_, relinkedError := authService.CheckIfUserExistsAndPasswordIsCorrect(ctx, "jane.doe", "directory-password")
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, user.UserId)
+	assert.EqualError(t, wrongPasswordError, services.InvalidCredentialsError)
+	assert.EqualError(t, localPasswordError, services.InvalidCredentialsError)
+	assert.EqualError(t, inactiveError, services.InvalidCredentialsError)
+	// entry with the username is not the one the user was synchronized from
+	assert.EqualError(t, relinkedError, services.InvalidCredentialsError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
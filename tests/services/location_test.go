@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/services"
+	"testing"
+	"time"
+)
+
+func TestLocationService_IsRoomOpen_RoomWithoutFloor(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	locationService := services.NewLocationService(repositories.NewLocationRepositoryPostgres(gormDB), repositories.NewOpeningHoursRepositoryPostgres(gormDB))
+	ctx := context.Background()
+	dateTimeStart := time.Date(2025, 4, 27, 3, 0, 0, 0, time.UTC) // Sunday night
+	// room with `floor_id IS NULL` is not joined to any location
+	mock.ExpectQuery(`SELECT locations.\* FROM "locations" JOIN buildings .* JOIN floors .* JOIN rooms .* WHERE rooms.room_id = \$1`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id", "time_zone"}))
+	mock.ExpectQuery(`SELECT locations.\* FROM "locations"`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id", "time_zone"}))
+
+	// 2. Act
+	isOpen, err := locationService.IsRoomOpen(ctx, 7, dateTimeStart, dateTimeStart.Add(time.Hour))
+	timeZone, timeZoneError := locationService.GetTimeZoneByRoomId(ctx, 7)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, true, isOpen)
+	assert.NoError(t, timeZoneError)
+	assert.Equal(t, time.UTC, timeZone)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocationService_IsRoomOpen_ClosedLocation(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	locationService := services.NewLocationService(repositories.NewLocationRepositoryPostgres(gormDB), repositories.NewOpeningHoursRepositoryPostgres(gormDB))
+	ctx := context.Background()
+	dateTimeStart := time.Date(2025, 4, 27, 3, 0, 0, 0, time.UTC) // Sunday
+	mock.ExpectQuery(`SELECT locations.\* FROM "locations"`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id", "time_zone"}).AddRow(1, "UTC"))
+	mock.ExpectQuery(`SELECT \* FROM "opening_hours"`).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id", "weekday", "opens_at", "closes_at"}).AddRow(1, 1, "08:00", "20:00"))
+
+	// 2. Act
+	isOpen, err := locationService.IsRoomOpen(ctx, 7, dateTimeStart, dateTimeStart.Add(time.Hour))
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, false, isOpen)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
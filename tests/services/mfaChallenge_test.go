@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"testing"
+	"time"
+)
+
+var challengeConfig = services.AuthConfig{AccessTokenKey: "access-token-key", MFAChallengeTTL: time.Minute}
+
+func TestMFAChallenge_RoundTrip(t *testing.T) {
+	// 1. Assess
+	mfaService := services.NewMFAService(challengeConfig, nil, nil, nil, nil, nil)
+	user := models.User{UserId: 7, OrganizationId: 2}
+	challenge, err := mfaService.IssueChallenge(context.Background(), user, pkg.IPAddressIdentity{IP: "10.0.0.1"})
+	assert.NoError(t, err)
+
+	// 2. Act
+	claims, validationError := mfaService.ValidateChallenge(context.Background(), challenge, pkg.IPAddressIdentity{IP: "10.0.0.1"})
+
+	// 3. Assert
+	assert.NoError(t, validationError)
+	assert.Equal(t, "7", claims.Subject)
+	assert.Equal(t, "2", claims.Organization)
+	assert.Equal(t, services.MFAChallengeType, claims.Type)
+}
+
+func TestMFAChallenge_RejectsOtherIPAndKey(t *testing.T) {
+	// 1. Assess
+	mfaService := services.NewMFAService(challengeConfig, nil, nil, nil, nil, nil)
+	otherConfig := challengeConfig
+	otherConfig.AccessTokenKey = "other-key"
+	otherService := services.NewMFAService(otherConfig, nil, nil, nil, nil, nil)
+	challenge, _ := mfaService.IssueChallenge(context.Background(), models.User{UserId: 7}, pkg.IPAddressIdentity{IP: "10.0.0.1"})
+
+	// 2. Act
+	_, otherIPError := mfaService.ValidateChallenge(context.Background(), challenge, pkg.IPAddressIdentity{IP: "10.0.0.2"})
+	_, otherKeyError := otherService.ValidateChallenge(context.Background(), challenge, pkg.IPAddressIdentity{IP: "10.0.0.1"})
+
+	// 3. Assert
+	assert.Error(t, otherIPError)
+	assert.Error(t, otherKeyError)
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/services"
+	"testing"
+	"time"
+)
+
+func weekdayRules(locationId int, opensAt string, closesAt string) []models.OpeningHours {
+	var rules []models.OpeningHours
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		rules = append(rules, models.OpeningHours{LocationId: locationId, Weekday: int(weekday), OpensAt: opensAt, ClosesAt: closesAt})
+	}
+
+	return rules
+}
+
+func TestIsWithinOpeningHours_NoRules(t *testing.T) {
+	// 1. Assess
+	dateTimeStart := time.Date(2025, 4, 23, 3, 0, 0, 0, time.UTC)
+	dateTimeEnd := dateTimeStart.Add(time.Hour)
+
+	// 2. Act
+	isOpen, err := services.IsWithinOpeningHours(nil, time.UTC, dateTimeStart, dateTimeEnd)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, true, isOpen)
+}
+
+func TestIsWithinOpeningHours_LocalTimeOfRoom(t *testing.T) {
+	// 1. Assess
+	dushanbe, _ := time.LoadLocation("Asia/Dushanbe") // UTC+05:00
+	rules := weekdayRules(1, "09:00", "18:00")
+
+	// 09:00-10:00 in Dushanbe
+	openStart := time.Date(2025, 4, 23, 4, 0, 0, 0, time.UTC)
+	// 08:00-09:00 in Dushanbe - but 09:00-10:00 in UTC+01:00
+	closedStart := time.Date(2025, 4, 23, 3, 0, 0, 0, time.UTC)
+
+	// 2. Act
+	isOpen, openErr := services.IsWithinOpeningHours(rules, dushanbe, openStart, openStart.Add(time.Hour))
+	isClosed, closedErr := services.IsWithinOpeningHours(rules, dushanbe, closedStart, closedStart.Add(time.Hour))
+
+	// 3. Assert
+	assert.NoError(t, openErr)
+	assert.NoError(t, closedErr)
+	assert.Equal(t, true, isOpen)
+	assert.Equal(t, false, isClosed)
+}
+
+func TestIsWithinOpeningHours_DSTSpringForward(t *testing.T) {
+	// 1. Assess
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	rules := weekdayRules(1, "09:00", "18:00")
+
+	// 2025-03-30 02:00 CET -> 03:00 CEST. 07:00 UTC is 09:00 CEST (UTC+02:00)
+	dateTimeStart := time.Date(2025, 3, 30, 7, 0, 0, 0, time.UTC)
+	// day before transition 07:00 UTC is 08:00 CET (UTC+01:00) - still closed
+	dayBeforeStart := time.Date(2025, 3, 29, 7, 0, 0, 0, time.UTC)
+	// 16:00 UTC is 18:00 CEST - booking till 17:00 UTC ends after closing
+	eveningStart := time.Date(2025, 3, 30, 15, 0, 0, 0, time.UTC)
+
+	// 2. Act
+	isOpen, err := services.IsWithinOpeningHours(rules, berlin, dateTimeStart, dateTimeStart.Add(time.Hour))
+	isOpenDayBefore, errDayBefore := services.IsWithinOpeningHours(rules, berlin, dayBeforeStart, dayBeforeStart.Add(time.Hour))
+	isOpenEvening, errEvening := services.IsWithinOpeningHours(rules, berlin, eveningStart, eveningStart.Add(2*time.Hour))
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.NoError(t, errDayBefore)
+	assert.NoError(t, errEvening)
+	assert.Equal(t, true, isOpen)
+	assert.Equal(t, false, isOpenDayBefore)
+	assert.Equal(t, false, isOpenEvening)
+}
+
+func TestIsWithinOpeningHours_DSTFallBack(t *testing.T) {
+	// 1. Assess
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	rules := weekdayRules(1, "09:00", "18:00")
+
+	// 2025-10-26 03:00 CEST -> 02:00 CET. 08:00 UTC is 09:00 CET (UTC+01:00)
+	dateTimeStart := time.Date(2025, 10, 26, 8, 0, 0, 0, time.UTC)
+	// 07:00 UTC was 09:00 the day before (CEST), but on the day of transition it is 08:00 CET
+	earlyStart := time.Date(2025, 10, 26, 7, 0, 0, 0, time.UTC)
+	// 17:00 UTC is 18:00 CET - booking ends exactly at closing time
+	lastHourStart := time.Date(2025, 10, 26, 16, 0, 0, 0, time.UTC)
+
+	// 2. Act
+	isOpen, err := services.IsWithinOpeningHours(rules, berlin, dateTimeStart, dateTimeStart.Add(time.Hour))
+	isOpenEarly, errEarly := services.IsWithinOpeningHours(rules, berlin, earlyStart, earlyStart.Add(time.Hour))
+	isOpenLastHour, errLastHour := services.IsWithinOpeningHours(rules, berlin, lastHourStart, lastHourStart.Add(time.Hour))
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.NoError(t, errEarly)
+	assert.NoError(t, errLastHour)
+	assert.Equal(t, true, isOpen)
+	assert.Equal(t, false, isOpenEarly)
+	assert.Equal(t, true, isOpenLastHour)
+}
+
+func TestIsWithinOpeningHours_DSTNightShift(t *testing.T) {
+	// 1. Assess
+	newYork, _ := time.LoadLocation("America/New_York")
+	// room is open 00:00-24:00 on Sunday only
+	rules := []models.OpeningHours{{LocationId: 1, Weekday: int(time.Sunday), OpensAt: "00:00", ClosesAt: "24:00"}}
+
+	// 2025-03-09 is 23 hours long in New York: 00:00 EST (05:00 UTC) - 24:00 EDT (04:00 UTC next day)
+	dayStart := time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC)
+	dayEnd := time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC)
+
+	// 2. Act
+	isOpenWholeDay, err := services.IsWithinOpeningHours(rules, newYork, dayStart, dayEnd)
+	isOpenHourLonger, errLonger := services.IsWithinOpeningHours(rules, newYork, dayStart, dayEnd.Add(time.Hour))
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.NoError(t, errLonger)
+	assert.Equal(t, true, isOpenWholeDay)
+	assert.Equal(t, false, isOpenHourLonger)
+}
+
+func TestIsWithinOpeningHours_ClosedWeekday(t *testing.T) {
+	// 1. Assess
+	dushanbe, _ := time.LoadLocation("Asia/Dushanbe")
+	rules := []models.OpeningHours{{LocationId: 1, Weekday: int(time.Monday), OpensAt: "09:00", ClosesAt: "18:00"}}
+
+	// Sunday 23:00 UTC is already Monday 04:00 in Dushanbe - before opening
+	sundayEvening := time.Date(2025, 4, 27, 23, 0, 0, 0, time.UTC)
+	// Monday 05:00 UTC is Monday 10:00 in Dushanbe
+	mondayMorning := time.Date(2025, 4, 28, 5, 0, 0, 0, time.UTC)
+
+	// 2. Act
+	isOpenSunday, errSunday := services.IsWithinOpeningHours(rules, dushanbe, sundayEvening, sundayEvening.Add(time.Hour))
+	isOpenMonday, errMonday := services.IsWithinOpeningHours(rules, dushanbe, mondayMorning, mondayMorning.Add(time.Hour))
+
+	// 3. Assert
+	assert.NoError(t, errSunday)
+	assert.NoError(t, errMonday)
+	assert.Equal(t, false, isOpenSunday)
+	assert.Equal(t, true, isOpenMonday)
+}
+
+func TestValidateOpeningHours(t *testing.T) {
+	assert.NoError(t, services.ValidateOpeningHours(models.OpeningHours{Weekday: 1, OpensAt: "09:00", ClosesAt: "24:00"}))
+	assert.Error(t, services.ValidateOpeningHours(models.OpeningHours{Weekday: 7, OpensAt: "09:00", ClosesAt: "18:00"}))
+	assert.Error(t, services.ValidateOpeningHours(models.OpeningHours{Weekday: 1, OpensAt: "18:00", ClosesAt: "09:00"}))
+	assert.Error(t, services.ValidateOpeningHours(models.OpeningHours{Weekday: 1, OpensAt: "9", ClosesAt: "18:00"}))
+	assert.Error(t, services.ValidateOpeningHours(models.OpeningHours{Weekday: 1, OpensAt: "09:00", ClosesAt: "24:30"}))
+}
+
+func TestBooking_In(t *testing.T) {
+	// 1. Assess
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	booking := models.Booking{
+		BookingId:     1,
+		DateTimeStart: time.Date(2025, 3, 30, 0, 30, 0, 0, time.UTC),
+		DateTimeEnd:   time.Date(2025, 3, 30, 1, 30, 0, 0, time.UTC),
+	}
+
+	// 2. Act
+	bookingInBerlin := booking.In(berlin)
+
+	// 3. Assert - same instants, local wall clock crosses DST transition
+	assert.True(t, booking.DateTimeStart.Equal(bookingInBerlin.DateTimeStart))
+	assert.Equal(t, "2025-03-30T01:30:00+01:00", bookingInBerlin.DateTimeStart.Format(time.RFC3339))
+	assert.Equal(t, "2025-03-30T03:30:00+02:00", bookingInBerlin.DateTimeEnd.Format(time.RFC3339))
+	assert.True(t, bookingInBerlin.CheckedInAt.IsZero())
+	assert.Equal(t, booking, booking.In(nil))
+}
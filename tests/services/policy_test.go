@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/services"
+	"testing"
+)
+
+func TestEvaluatePolicy(t *testing.T) {
+	// 1. Assess
+	smallRoomsOnly := models.Policy{Name: "small rooms only", Effect: models.PolicyEffectDeny, Conditions: models.PolicyConditions{
+		{Attribute: "resource.capacity", Operator: services.PolicyOperatorGt, Value: float64(10)},
+	}}
+	ownFloor := models.Policy{Name: "own floor", Effect: models.PolicyEffectAllow, Conditions: models.PolicyConditions{
+		{Attribute: "subject.role_id", Operator: services.PolicyOperatorEq, Value: float64(4)},
+		{Attribute: "resource.floor_id", Operator: services.PolicyOperatorEq, ValueAttribute: "subject.floor_id"},
+		{Attribute: "request.method", Operator: services.PolicyOperatorIn, Value: []any{"POST", "DELETE"}},
+	}}
+	plannerOnOwnFloor := map[string]any{"subject.role_id": 4, "subject.floor_id": 3, "resource.floor_id": 3, "resource.capacity": 8, "request.method": "POST"}
+	plannerOnOtherFloor := map[string]any{"subject.role_id": 4, "subject.floor_id": 3, "resource.floor_id": 5, "resource.capacity": 12, "request.method": "POST"}
+	plannerWithoutFloor := map[string]any{"subject.role_id": 4, "resource.floor_id": 3, "request.method": "POST"}
+
+	// 2. Act
+	smallRoom := services.EvaluatePolicy(smallRoomsOnly, plannerOnOwnFloor)
+	largeRoom := services.EvaluatePolicy(smallRoomsOnly, plannerOnOtherFloor)
+	noRoom := services.EvaluatePolicy(smallRoomsOnly, map[string]any{})
+	sameFloor := services.EvaluatePolicy(ownFloor, plannerOnOwnFloor)
+	otherFloor := services.EvaluatePolicy(ownFloor, plannerOnOtherFloor)
+	unknownFloor := services.EvaluatePolicy(ownFloor, plannerWithoutFloor)
+
+	// 3. Assert
+	assert.False(t, smallRoom.Matched)
+	assert.True(t, largeRoom.Matched)
+	assert.Equal(t, 12, largeRoom.Conditions[0].Actual)
+	assert.False(t, noRoom.Matched)
+	assert.Nil(t, noRoom.Conditions[0].Actual)
+	assert.True(t, sameFloor.Matched)
+	assert.False(t, otherFloor.Matched)
+	assert.Equal(t, []bool{true, false, true}, []bool{otherFloor.Conditions[0].Matched, otherFloor.Conditions[1].Matched, otherFloor.Conditions[2].Matched})
+	assert.False(t, unknownFloor.Matched)
+}
+
+func TestValidatePolicyCondition(t *testing.T) {
+	testCases := []struct {
+		name      string
+		condition models.PolicyCondition
+		valid     bool
+	}{
+		{"value", models.PolicyCondition{Attribute: "resource.capacity", Operator: "lte", Value: float64(10)}, true},
+		{"value attribute", models.PolicyCondition{Attribute: "resource.floor_id", Operator: "eq", ValueAttribute: "subject.floor_id"}, true},
+		{"list", models.PolicyCondition{Attribute: "request.weekday", Operator: "not_in", Value: []any{float64(0), float64(6)}}, true},
+		{"unknown namespace", models.PolicyCondition{Attribute: "room.capacity", Operator: "lte", Value: float64(10)}, false},
+		{"no attribute name", models.PolicyCondition{Attribute: "subject.", Operator: "eq", Value: "x"}, false},
+		{"unknown operator", models.PolicyCondition{Attribute: "resource.capacity", Operator: "between", Value: float64(10)}, false},
+		{"no value", models.PolicyCondition{Attribute: "resource.capacity", Operator: "lte"}, false},
+		{"value and value attribute", models.PolicyCondition{Attribute: "resource.floor_id", Operator: "eq", Value: float64(1), ValueAttribute: "subject.floor_id"}, false},
+		{"in without list", models.PolicyCondition{Attribute: "request.method", Operator: "in", Value: "POST"}, false},
+		{"order of bool", models.PolicyCondition{Attribute: "request.all_day", Operator: "gt", Value: true}, false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// 2. Act
+			err := services.ValidatePolicyCondition(testCase.condition)
+
+			// 3. Assert
+			assert.Equal(t, testCase.valid, err == nil, err)
+		})
+	}
+}
+
+func TestAuthService_CheckPermissions_Policies(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	authService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).AuthService
+	ctx := context.Background()
+	expectAccessCheck := func(roomId int, capacity int) {
+		mock.ExpectQuery(`SELECT \* FROM "routes"`).WillReturnRows(sqlmock.NewRows([]string{"route_id", "url"}).AddRow(1, "/booking/create"))
+		mock.ExpectQuery(`SELECT \* FROM "permissions"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "route_id", "scope_id"}).AddRow(5, 1, services.AllScopeId))
+		mock.ExpectQuery(`SELECT \* FROM "policies"`).WillReturnRows(sqlmock.NewRows([]string{"policy_id", "name", "route_id", "effect", "conditions", "active"}).
+			AddRow(1, "small rooms only", 1, models.PolicyEffectDeny, `[{"attribute": "resource.capacity", "operator": "gt", "value": 10}]`, true))
+		mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "role_id", "organization_id"}).AddRow(3, 5, 1))
+		mock.ExpectQuery(`SELECT \* FROM "rooms"`).WillReturnRows(sqlmock.NewRows([]string{"room_id", "capacity", "floor_id"}).AddRow(roomId, capacity, 2))
+		mock.ExpectQuery(`SELECT \* FROM "floors"`).WillReturnRows(sqlmock.NewRows([]string{"floor_id", "building_id"}).AddRow(2, 4))
+		mock.ExpectQuery(`SELECT \* FROM "buildings"`).WillReturnRows(sqlmock.NewRows([]string{"building_id", "location_id"}).AddRow(4, 6))
+	}
+	expectAccessCheck(12, 8)
+	expectAccessCheck(13, 20)
+
+	// 2. Act
+	smallRoomGranted, smallRoomError := authService.CheckPermissions(ctx, "/booking/create", "booking", "", "3", "5", services.RequestAttributes{"room_id": float64(12)})
+	largeRoomGranted, largeRoomError := authService.CheckPermissions(ctx, "/booking/create", "booking", "", "3", "5", services.RequestAttributes{"room_id": float64(13)})
+
+	// 3. Assert
+	assert.NoError(t, smallRoomError)
+	assert.True(t, smallRoomGranted)
+	assert.NoError(t, largeRoomError)
+	assert.False(t, largeRoomGranted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthService_CheckPermissions_RequestIsNotReadWithoutPolicies(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	authService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).AuthService
+	ctx := context.Background()
+	isRequestRead := false
+	request := services.RequestAttributesFunc(func() services.RequestAttributes {
+		isRequestRead = true
+		return services.RequestAttributes{}
+	})
+	mock.ExpectQuery(`SELECT \* FROM "routes"`).WillReturnRows(sqlmock.NewRows([]string{"route_id", "url"}).AddRow(1, "/booking/create"))
+	mock.ExpectQuery(`SELECT \* FROM "permissions"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "route_id", "scope_id"}).AddRow(5, 1, services.AllScopeId))
+	mock.ExpectQuery(`SELECT \* FROM "policies"`).WillReturnRows(sqlmock.NewRows([]string{"policy_id"}))
+
+	// 2. Act
+	isGranted, err := authService.CheckPermissions(ctx, "/booking/create", "booking", "", "3", "5", request)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.True(t, isGranted)
+	// body of the request is not buffered for routes without policies
+	assert.False(t, isRequestRead)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/database"
+	"go-booking-system/internal/database/repositories"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"testing"
+	"time"
+)
+
+func TestServiceAccountService_Authenticate(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	serviceAccountService := services.NewServiceAccountService(services.AuthConfig{APIKeyMaxTTL: time.Hour},
+		repositories.NewUserRepositoryPostgres(gormDB), repositories.NewAPIKeyRepositoryPostgres(gormDB), nil, nil, nil, nil)
+	ctx := context.Background()
+	secret := "integration-secret"
+	apiKeyRows := func(active bool, expiresAt time.Time) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"api_key_id", "organization_id", "user_id", "key_hash", "expires_at", "active"}).
+			AddRow(7, 1, 3, pkg.HashToken(secret), expiresAt, active)
+	}
+	permissionRows := sqlmock.NewRows([]string{"api_key_id", "route_id", "scope_id"}).AddRow(7, 2, 1)
+
+	mock.ExpectQuery(`SELECT \* FROM "api_keys"`).WillReturnRows(apiKeyRows(true, time.Now().Add(time.Hour)))
+	mock.ExpectQuery(`SELECT \* FROM "api_key_permissions"`).WillReturnRows(permissionRows)
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "role_id", "auth_provider", "active"}).
+		AddRow(3, 5, services.AuthProviderServiceAccount, true))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "api_keys" SET "last_used_at"=\$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	// wrong secret
+	mock.ExpectQuery(`SELECT \* FROM "api_keys"`).WillReturnRows(apiKeyRows(true, time.Now().Add(time.Hour)))
+	mock.ExpectQuery(`SELECT \* FROM "api_key_permissions"`).WillReturnRows(sqlmock.NewRows([]string{"api_key_id"}))
+	// expired
+	mock.ExpectQuery(`SELECT \* FROM "api_keys"`).WillReturnRows(apiKeyRows(true, time.Now().Add(-time.Second)))
+	mock.ExpectQuery(`SELECT \* FROM "api_key_permissions"`).WillReturnRows(sqlmock.NewRows([]string{"api_key_id"}))
+	// revoked
+	mock.ExpectQuery(`SELECT \* FROM "api_keys"`).WillReturnRows(apiKeyRows(false, time.Now().Add(time.Hour)))
+	mock.ExpectQuery(`SELECT \* FROM "api_key_permissions"`).WillReturnRows(sqlmock.NewRows([]string{"api_key_id"}))
+
+	// 2. Act
+	apiKey, serviceAccount, err := serviceAccountService.Authenticate(ctx, fmt.Sprintf("7.%s", secret))
+	_, _, wrongSecretError := serviceAccountService.Authenticate(ctx, "7.guessed-secret")
+	_, _, expiredError := serviceAccountService.Authenticate(ctx, fmt.Sprintf("7.%s", secret))
+	_, _, revokedError := serviceAccountService.Authenticate(ctx, fmt.Sprintf("7.%s", secret))
+	_, _, formatError := serviceAccountService.Authenticate(ctx, secret)
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, serviceAccount.UserId)
+	assert.Equal(t, []models.APIKeyPermission{{APIKeyId: 7, RouteId: 2, ScopeId: 1}}, apiKey.Permissions)
+	assert.NotNil(t, apiKey.LastUsedAt)
+	assert.EqualError(t, wrongSecretError, services.InvalidAPIKeyError)
+	assert.EqualError(t, expiredError, services.InvalidAPIKeyError)
+	assert.EqualError(t, revokedError, services.InvalidAPIKeyError)
+	assert.EqualError(t, formatError, services.InvalidAPIKeyError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthService_CheckAPIKeyPermissions(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	routeService := services.NewRouteService(repositories.NewRouteRepositoryPostgres(gormDB))
+	permissionService := services.NewPermissionService(repositories.NewPermissionRepositoryPostgres(gormDB))
+	authService := services.NewAuthService(services.AuthConfig{}, nil, nil, routeService, nil, permissionService, nil, nil, nil, nil, nil, nil, nil)
+	apiKey := models.APIKey{APIKeyId: 7, UserId: 3, Permissions: []models.APIKeyPermission{{RouteId: 2, ScopeId: services.AllScopeId}, {RouteId: 4, ScopeId: services.AllScopeId}}}
+
+	mock.ExpectQuery(`SELECT \* FROM "routes"`).WillReturnRows(sqlmock.NewRows([]string{"route_id", "url"}).AddRow(2, "/booking/all"))
+	mock.ExpectQuery(`SELECT \* FROM "permissions"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "route_id", "scope_id"}).AddRow(5, 2, services.AllScopeId))
+	mock.ExpectQuery(`SELECT \* FROM "routes"`).WillReturnRows(sqlmock.NewRows([]string{"route_id", "url"}).AddRow(5, "/booking/create"))
+	mock.ExpectQuery(`SELECT \* FROM "routes"`).WillReturnRows(sqlmock.NewRows([]string{"route_id", "url"}).AddRow(4, "/user/all"))
+	mock.ExpectQuery(`SELECT \* FROM "permissions"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "route_id", "scope_id"}))
+
+	// 2. Act
+	isAllowed, err := authService.CheckAPIKeyPermissions(context.Background(), apiKey, 5, "/booking/all", "booking", "")
+	isNotListedAllowed, notListedError := authService.CheckAPIKeyPermissions(context.Background(), apiKey, 5, "/booking/create", "booking", "")
+	isNotAllowedToRoleAllowed, notAllowedToRoleError := authService.CheckAPIKeyPermissions(context.Background(), apiKey, 5, "/user/all", "user", "")
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.True(t, isAllowed)
+	// role of the service account may allow the route, the key does not
+	assert.NoError(t, notListedError)
+	assert.False(t, isNotListedAllowed)
+	// the key lists the route, role of the service account does not allow it
+	assert.NoError(t, notAllowedToRoleError)
+	assert.False(t, isNotAllowedToRoleAllowed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthService_CheckAPIKeyPermissions_NarrowerScope(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	authService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{}, nil).AuthService
+	// the key allows all bookings, the role only own ones
+	apiKey := models.APIKey{APIKeyId: 7, UserId: 3, Permissions: []models.APIKeyPermission{{RouteId: 2, ScopeId: services.AllScopeId}}}
+
+	mock.ExpectQuery(`SELECT \* FROM "routes"`).WillReturnRows(sqlmock.NewRows([]string{"route_id", "url"}).AddRow(2, "/booking/update"))
+	mock.ExpectQuery(`SELECT \* FROM "permissions"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "route_id", "scope_id"}).AddRow(5, 2, services.OwnerScopeId))
+	mock.ExpectQuery(`SELECT \* FROM "bookings"`).WillReturnRows(sqlmock.NewRows([]string{"booking_id", "created_by"}).AddRow(11, 8))
+
+	// 2. Act
+	isAllowed, err := authService.CheckAPIKeyPermissions(context.Background(), apiKey, 5, "/booking/update", "booking", "11")
+
+	// 3. Assert
+	assert.NoError(t, err)
+	assert.False(t, isAllowed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestServiceAccountService_CreateAPIKey_RouteNotAllowedToRole(t *testing.T) {
+	// 1. Assess
+	gormDB, mock := setupTestDB(t)
+	serviceAccountService := services.NewService(database.NewDatabase(gormDB), services.AuthConfig{APIKeyMaxTTL: time.Hour}, nil).ServiceAccountService
+	apiKey := models.APIKey{UserId: 3, Name: "dashboard", Permissions: []models.APIKeyPermission{{URL: "/user/all", ScopeId: services.AllScopeId}}}
+
+	mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "role_id", "auth_provider", "active"}).
+		AddRow(3, 5, services.AuthProviderServiceAccount, true))
+	mock.ExpectQuery(`SELECT \* FROM "routes"`).WillReturnRows(sqlmock.NewRows([]string{"route_id", "url"}).AddRow(4, "/user/all"))
+	mock.ExpectQuery(`SELECT \* FROM "scopes"`).WillReturnRows(sqlmock.NewRows([]string{"scope_id", "name"}).AddRow(1, "ALL"))
+	mock.ExpectQuery(`SELECT \* FROM "permissions"`).WillReturnRows(sqlmock.NewRows([]string{"role_id", "route_id", "scope_id"}))
+
+	// 2. Act
+	_, key, err := serviceAccountService.CreateAPIKey(context.Background(), apiKey)
+
+	// 3. Assert
+	assert.ErrorContains(t, err, services.RouteNotAllowedToRoleError)
+	assert.Empty(t, key)
+	// the key is not created
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
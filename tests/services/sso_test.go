@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/oidc"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestMapGroupsToRole(t *testing.T) {
+	// 1. Assess
+	groupRoles := []oidc.GroupRole{{Group: "booking-admins", RoleId: 1}, {Group: "hr", RoleId: 3}, {Group: "staff", RoleId: 5}}
+
+	// 2. Act
+	admin := services.MapGroupsToRole([]string{"staff", "booking-admins"}, groupRoles, 0)
+	staff := services.MapGroupsToRole([]string{"staff"}, groupRoles, 0)
+	unmapped := services.MapGroupsToRole([]string{"contractors"}, groupRoles, 0)
+	withDefault := services.MapGroupsToRole(nil, groupRoles, 5)
+
+	// 3. Assert
+	// order of the mapping wins, not order of groups in the token
+	assert.Equal(t, 1, admin)
+	assert.Equal(t, 5, staff)
+	assert.Equal(t, 0, unmapped)
+	assert.Equal(t, 5, withDefault)
+}
+
+func TestSSOAuthMethods_TrustsMFAOfProvider(t *testing.T) {
+	assert.Equal(t, []string{services.AuthMethodSSO}, services.SSOAuthMethods(oidc.Claims{AuthMethods: []string{"pwd"}}))
+	assert.Equal(t, []string{services.AuthMethodSSO, services.AuthMethodMFA}, services.SSOAuthMethods(oidc.Claims{AuthMethods: []string{"pwd", "otp", "mfa"}}))
+}
+
+// newSSOService returns SSO service configured for mock provider served by test server
+func newSSOService(t *testing.T) *services.SSOService {
+	mock, err := oidc.NewMockProvider("go-booking-system", oidc.MockUser{Subject: "42", Email: "jane.doe@example.com", Groups: []string{"staff"}})
+	assert.NoError(t, err)
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+	mock.Issuer = server.URL
+
+	config := services.AuthConfig{AccessTokenKey: "access-token-key", SSO: oidc.Config{
+		Issuer:      server.URL,
+		ClientId:    "go-booking-system",
+		RedirectURL: "http://localhost:8080/auth/oidc/callback",
+		Scopes:      []string{"openid", "email"},
+		GroupsClaim: "groups",
+		FlowTTL:     time.Minute,
+	}}
+	return services.NewSSOService(config, oidc.NewProvider(config.SSO, server.Client()), nil, nil)
+}
+
+// loginAtProvider follows URL of login page and returns `code` and `state` of the callback
+func loginAtProvider(t *testing.T, authURL string) url.Values {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	response, err := client.Get(authURL)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	location, err := url.Parse(response.Header.Get("Location"))
+	assert.NoError(t, err)
+	return location.Query()
+}
+
+func TestSSOService_StartLoginAndAuthenticate(t *testing.T) {
+	// 1. Assess
+	ssoService := newSSOService(t)
+	ctx := context.Background()
+	authURL, flowToken, err := ssoService.StartLogin(ctx, pkg.IPAddressIdentity{IP: "10.0.0.1"})
+	assert.NoError(t, err)
+	callback := loginAtProvider(t, authURL)
+
+	// 2. Act
+	claims, authenticationError := ssoService.Authenticate(ctx, callback.Get("code"), callback.Get("state"), flowToken, pkg.IPAddressIdentity{IP: "10.0.0.1"})
+
+	// 3. Assert
+	assert.NoError(t, authenticationError)
+	assert.Equal(t, "42", claims.Subject)
+	assert.Equal(t, []string{"staff"}, claims.Groups)
+}
+
+func TestSSOService_Authenticate_RejectsForeignState(t *testing.T) {
+	// 1. Assess
+	ssoService := newSSOService(t)
+	ctx := context.Background()
+	authURL, _, _ := ssoService.StartLogin(ctx, pkg.IPAddressIdentity{IP: "10.0.0.1"})
+	callback := loginAtProvider(t, authURL)
+	// callback of the victim's log in is finished with the attacker's flow token (login CSRF)
+	_, attackerFlowToken, _ := ssoService.StartLogin(ctx, pkg.IPAddressIdentity{IP: "10.0.0.1"})
+
+	// 2. Act
+	_, stateError := ssoService.Authenticate(ctx, callback.Get("code"), callback.Get("state"), attackerFlowToken, pkg.IPAddressIdentity{IP: "10.0.0.1"})
+	_, malformedError := ssoService.Authenticate(ctx, callback.Get("code"), callback.Get("state"), "not-a-token", pkg.IPAddressIdentity{IP: "10.0.0.1"})
+
+	// 3. Assert
+	assert.ErrorContains(t, stateError, "state")
+	assert.Error(t, malformedError)
+}
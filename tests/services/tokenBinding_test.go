@@ -0,0 +1,64 @@
+package services
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/services"
+	"go-booking-system/pkg"
+	"testing"
+)
+
+func TestTokenBinding_Matches(t *testing.T) {
+	// 1. Assess
+	config := services.TokenBindingConfig{IPv4PrefixLength: 24, IPv6PrefixLength: 64}
+	bindings := map[string]services.TokenBinding{}
+	for _, mode := range []string{services.TokenBindingNone, services.TokenBindingIP, services.TokenBindingSubnet, services.TokenBindingFingerprint} {
+		config.Mode = mode
+		bindings[mode] = services.NewTokenBinding(config)
+	}
+	issuedTo := pkg.IPAddressIdentity{IP: "10.0.0.7", Fingerprint: "phone"}
+	issuedToIPv6 := pkg.IPAddressIdentity{IP: "2001:db8:1:2::10", Fingerprint: "phone"}
+	cases := []struct {
+		name     string
+		issuedTo pkg.IPAddressIdentity
+		sentFrom pkg.IPAddressIdentity
+		expected map[string]bool
+	}{
+		{"same client", issuedTo, pkg.IPAddressIdentity{IP: "10.0.0.7", Fingerprint: "phone"},
+			map[string]bool{services.TokenBindingNone: true, services.TokenBindingIP: true, services.TokenBindingSubnet: true, services.TokenBindingFingerprint: true}},
+		{"IPv4-mapped address", issuedTo, pkg.IPAddressIdentity{IP: "::ffff:10.0.0.7", Fingerprint: "phone"},
+			map[string]bool{services.TokenBindingNone: true, services.TokenBindingIP: true, services.TokenBindingSubnet: true, services.TokenBindingFingerprint: true}},
+		{"the same network", issuedTo, pkg.IPAddressIdentity{IP: "10.0.0.200", Fingerprint: "phone"},
+			map[string]bool{services.TokenBindingNone: true, services.TokenBindingIP: false, services.TokenBindingSubnet: true, services.TokenBindingFingerprint: true}},
+		{"the same IPv6 network", issuedToIPv6, pkg.IPAddressIdentity{IP: "2001:db8:1:2:abcd::1", Fingerprint: "phone"},
+			map[string]bool{services.TokenBindingNone: true, services.TokenBindingIP: false, services.TokenBindingSubnet: true, services.TokenBindingFingerprint: true}},
+		{"another network", issuedTo, pkg.IPAddressIdentity{IP: "10.0.1.7", Fingerprint: "phone"},
+			map[string]bool{services.TokenBindingNone: true, services.TokenBindingIP: false, services.TokenBindingSubnet: false, services.TokenBindingFingerprint: true}},
+		{"another family", issuedTo, pkg.IPAddressIdentity{IP: "2001:db8:1:2::10", Fingerprint: "phone"},
+			map[string]bool{services.TokenBindingNone: true, services.TokenBindingIP: false, services.TokenBindingSubnet: false, services.TokenBindingFingerprint: true}},
+		{"another program", issuedTo, pkg.IPAddressIdentity{IP: "10.0.0.7", Fingerprint: "script"},
+			map[string]bool{services.TokenBindingNone: true, services.TokenBindingIP: true, services.TokenBindingSubnet: true, services.TokenBindingFingerprint: false}},
+		{"token without fingerprint", pkg.IPAddressIdentity{IP: "10.0.0.7"}, pkg.IPAddressIdentity{IP: "10.0.0.7"},
+			map[string]bool{services.TokenBindingNone: true, services.TokenBindingIP: true, services.TokenBindingSubnet: true, services.TokenBindingFingerprint: false}},
+	}
+
+	for _, testCase := range cases {
+		for mode, expected := range testCase.expected {
+			// 2. Act
+			matches := bindings[mode].Matches(testCase.issuedTo, testCase.sentFrom)
+
+			// 3. Assert
+			assert.Equal(t, expected, matches, "%s: %s binding", testCase.name, mode)
+		}
+	}
+}
+
+func TestTokenBinding_UnknownModeIsIP(t *testing.T) {
+	// 1. Assess
+	binding := services.NewTokenBinding(services.TokenBindingConfig{})
+
+	// 2. Act
+	matches := binding.Matches(pkg.IPAddressIdentity{IP: "10.0.0.7"}, pkg.IPAddressIdentity{IP: "10.0.0.8"})
+
+	// 3. Assert
+	assert.False(t, matches)
+}
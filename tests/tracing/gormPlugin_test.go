@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go-booking-system/internal/models"
+	"go-booking-system/internal/tracing"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"regexp"
+	"testing"
+)
+
+func TestGormPlugin_StartsChildSpanOfQuery(t *testing.T) {
+	// 1. Assess
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, PreferSimpleProtocol: true}),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	assert.NoError(t, err)
+	assert.NoError(t, gormDB.Use(tracing.GormPlugin{}))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "rooms" WHERE "room_id" = $1`)).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"room_id"}).AddRow(7))
+
+	ctx, parentSpan := otel.Tracer("test").Start(context.Background(), "RoomService.GetRoomById")
+
+	// 2. Act
+	var room models.Room
+	gormDB.WithContext(ctx).Find(&room, "room_id", 7)
+	parentSpan.End()
+
+	// 3. Assert
+	assert.NoError(t, mock.ExpectationsWereMet())
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	querySpan := spans[0]
+	assert.Equal(t, "gorm.query", querySpan.Name())
+	assert.Equal(t, parentSpan.SpanContext().SpanID(), querySpan.Parent().SpanID())
+	assert.Equal(t, parentSpan.SpanContext().TraceID(), querySpan.SpanContext().TraceID())
+
+	attributes := map[string]string{}
+	for _, attribute := range querySpan.Attributes() {
+		attributes[string(attribute.Key)] = attribute.Value.Emit()
+	}
+	assert.Equal(t, "rooms", attributes["db.sql.table"])
+	assert.NotContains(t, attributes["db.statement"], "7")
+}